@@ -0,0 +1,5 @@
+package passwordv1
+
+// Regenerate the Go code after changing password.proto, with protoc-gen-go
+// and protoc-gen-go-grpc on the PATH
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative password/v1/password.proto
@@ -0,0 +1,199 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: password/v1/password.proto
+
+// The password API of config-service over gRPC. It runs the same checks as
+// the REST API under /api/v1/password, and reports results in the same
+// terms: strengths, rules, and patterns are the strings the REST API uses.
+
+package passwordv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PasswordService_PasswordCheck_FullMethodName = "/password.v1.PasswordService/PasswordCheck"
+	PasswordService_BreachCheck_FullMethodName   = "/password.v1.PasswordService/BreachCheck"
+	PasswordService_Generate_FullMethodName      = "/password.v1.PasswordService/Generate"
+)
+
+// PasswordServiceClient is the client API for PasswordService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PasswordServiceClient interface {
+	// PasswordCheck scores a password, after validating it against the
+	// default rules or a named policy. Passwords failing validation are
+	// rejected with INVALID_ARGUMENT, and a ValidationFailure detail when the
+	// policy or the explanation of the check is known.
+	PasswordCheck(ctx context.Context, in *PasswordCheckRequest, opts ...grpc.CallOption) (*PasswordCheckResponse, error)
+	// BreachCheck looks a password up in the breach database
+	BreachCheck(ctx context.Context, in *BreachCheckRequest, opts ...grpc.CallOption) (*BreachInfo, error)
+	// Generate generates a random password and scores it
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error)
+}
+
+type passwordServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPasswordServiceClient(cc grpc.ClientConnInterface) PasswordServiceClient {
+	return &passwordServiceClient{cc}
+}
+
+func (c *passwordServiceClient) PasswordCheck(ctx context.Context, in *PasswordCheckRequest, opts ...grpc.CallOption) (*PasswordCheckResponse, error) {
+	out := new(PasswordCheckResponse)
+	err := c.cc.Invoke(ctx, PasswordService_PasswordCheck_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *passwordServiceClient) BreachCheck(ctx context.Context, in *BreachCheckRequest, opts ...grpc.CallOption) (*BreachInfo, error) {
+	out := new(BreachInfo)
+	err := c.cc.Invoke(ctx, PasswordService_BreachCheck_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *passwordServiceClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*GenerateResponse, error) {
+	out := new(GenerateResponse)
+	err := c.cc.Invoke(ctx, PasswordService_Generate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PasswordServiceServer is the server API for PasswordService service.
+// All implementations must embed UnimplementedPasswordServiceServer
+// for forward compatibility
+type PasswordServiceServer interface {
+	// PasswordCheck scores a password, after validating it against the
+	// default rules or a named policy. Passwords failing validation are
+	// rejected with INVALID_ARGUMENT, and a ValidationFailure detail when the
+	// policy or the explanation of the check is known.
+	PasswordCheck(context.Context, *PasswordCheckRequest) (*PasswordCheckResponse, error)
+	// BreachCheck looks a password up in the breach database
+	BreachCheck(context.Context, *BreachCheckRequest) (*BreachInfo, error)
+	// Generate generates a random password and scores it
+	Generate(context.Context, *GenerateRequest) (*GenerateResponse, error)
+	mustEmbedUnimplementedPasswordServiceServer()
+}
+
+// UnimplementedPasswordServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPasswordServiceServer struct {
+}
+
+func (UnimplementedPasswordServiceServer) PasswordCheck(context.Context, *PasswordCheckRequest) (*PasswordCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PasswordCheck not implemented")
+}
+func (UnimplementedPasswordServiceServer) BreachCheck(context.Context, *BreachCheckRequest) (*BreachInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BreachCheck not implemented")
+}
+func (UnimplementedPasswordServiceServer) Generate(context.Context, *GenerateRequest) (*GenerateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedPasswordServiceServer) mustEmbedUnimplementedPasswordServiceServer() {}
+
+// UnsafePasswordServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PasswordServiceServer will
+// result in compilation errors.
+type UnsafePasswordServiceServer interface {
+	mustEmbedUnimplementedPasswordServiceServer()
+}
+
+func RegisterPasswordServiceServer(s grpc.ServiceRegistrar, srv PasswordServiceServer) {
+	s.RegisterService(&PasswordService_ServiceDesc, srv)
+}
+
+func _PasswordService_PasswordCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PasswordCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PasswordServiceServer).PasswordCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PasswordService_PasswordCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PasswordServiceServer).PasswordCheck(ctx, req.(*PasswordCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PasswordService_BreachCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BreachCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PasswordServiceServer).BreachCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PasswordService_BreachCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PasswordServiceServer).BreachCheck(ctx, req.(*BreachCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PasswordService_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PasswordServiceServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PasswordService_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PasswordServiceServer).Generate(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PasswordService_ServiceDesc is the grpc.ServiceDesc for PasswordService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PasswordService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "password.v1.PasswordService",
+	HandlerType: (*PasswordServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PasswordCheck",
+			Handler:    _PasswordService_PasswordCheck_Handler,
+		},
+		{
+			MethodName: "BreachCheck",
+			Handler:    _PasswordService_BreachCheck_Handler,
+		},
+		{
+			MethodName: "Generate",
+			Handler:    _PasswordService_Generate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "password/v1/password.proto",
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"config-service/internal/services"
+)
+
+// newCalibrateCommand builds the command that scores a labeled corpus of
+// cracked and uncracked passwords and reports how well the scores separate them
+func newCalibrateCommand() *cobra.Command {
+	var output string
+	var examples int
+	cmd := &cobra.Command{
+		Use:   "calibrate <corpus>",
+		Short: "Report how well scores separate cracked from uncracked passwords",
+		Long: "Score a labeled corpus with the configured engine, including password\n" +
+			"dictionaries and rule plugins, and write a JSON calibration report: the ROC\n" +
+			"curve and its AUC, the cracked rate of each strength, and examples of cracked\n" +
+			"passwords rated strong and uncracked passwords rated weak.\n\n" +
+			"Each line of the corpus holds a label, cracked or uncracked, a tab, and the\n" +
+			"password. The report contains corpus passwords; protect it like the corpus.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if examples < 0 {
+				return errors.New("--examples must not be negative")
+			}
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			samples, err := services.LoadCalibrationCorpus(args[0])
+			if err != nil {
+				return err
+			}
+
+			passwordService, closeDictionaries, err := newScoringService(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDictionaries()
+
+			report, err := passwordService.Calibrate(cmd.Context(), samples, examples)
+			if err != nil {
+				return err
+			}
+			return writeJSON(cmd, output, report)
+		},
+	}
+	addConfigFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the report to (default stdout)")
+	cmd.Flags().IntVar(&examples, "examples", 20, "misclassified passwords of each label to list")
+	return cmd
+}
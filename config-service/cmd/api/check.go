@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"config-service/internal/app"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// Exit codes of the check command. Any non-zero status makes pam_exec fail
+// the PAM stack; the specific codes let other callers tell the reasons apart.
+const (
+	checkExitAccepted = 0
+	checkExitError    = 1
+	checkExitPolicy   = 2
+	checkExitWeak     = 3
+	checkExitBreached = 4
+)
+
+// maxCandidateBytes bounds how much of stdin is read as the candidate password
+const maxCandidateBytes = 4096
+
+// checkResult is the machine-readable outcome written to stderr as one JSON line.
+// It never contains the password.
+type checkResult struct {
+	Result   string                  `json:"result"`
+	Reason   string                  `json:"reason,omitempty"`
+	Strength models.PasswordStrength `json:"strength,omitempty"`
+	Score    int                     `json:"score,omitempty"`
+	Warning  string                  `json:"warning,omitempty"`
+}
+
+// exitCodeError makes the process exit with a specific status
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// newCheckCommand builds the command that checks a single password read from
+// stdin, for pam_exec and chpasswd hooks
+func newCheckCommand() *cobra.Command {
+	var minStrength string
+	var noBreach bool
+	var failClosed bool
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check a password read from stdin and exit with its verdict",
+		Long: "Read a candidate password from stdin, check it against the password policy,\n" +
+			"the minimum strength, and, if breach.enabled is set, known breaches.\n" +
+			"The verdict is written to stderr as one JSON line and reflected in the exit status:\n" +
+			"0 accepted, 1 error, 2 policy violation, 3 too weak, 4 breached.\n\n" +
+			"The password ends at the first newline or NUL byte, so it can be used with\n" +
+			"pam_exec's expose_authtok option:\n" +
+			"  password requisite pam_exec.so expose_authtok quiet /usr/local/bin/config-service check",
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stderr := cmd.ErrOrStderr()
+			finish := func(code int, result checkResult) error {
+				json.NewEncoder(stderr).Encode(result)
+				if code == checkExitAccepted {
+					return nil
+				}
+				return &exitCodeError{code: code}
+			}
+
+			minimum := models.PasswordStrength(minStrength)
+			if !minimum.Valid() {
+				return finish(checkExitError, checkResult{Result: "error", Reason: fmt.Sprintf("invalid --min-strength %q", minStrength)})
+			}
+
+			password, err := readCandidate(cmd.InOrStdin())
+			if err != nil {
+				return finish(checkExitError, checkResult{Result: "error", Reason: err.Error()})
+			}
+
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return finish(checkExitError, checkResult{Result: "error", Reason: "failed to load configuration: " + err.Error()})
+			}
+
+			// Logs would interleave with the verdict on stderr
+			logger := logging.New(io.Discard)
+			passwordService := services.NewPasswordService(logger,
+				services.WithBannedList(services.NewBannedListService(logger)),
+				services.WithRulePlugins(app.NewRulePlugins(cfg, metrics.Noop{})...),
+				services.WithMaxLength(cfg.Password.MaxLength))
+
+			if err := passwordService.ValidatePassword(password); err != nil {
+				return finish(checkExitPolicy, checkResult{Result: "policy", Reason: err.Error()})
+			}
+
+			response := passwordService.ScorePasswordContext(cmd.Context(), password)
+			result := checkResult{Strength: response.Strength, Score: response.Score}
+			if !response.Strength.AtLeast(minimum) {
+				result.Result = "weak"
+				result.Reason = fmt.Sprintf("password is %s, below the required %s", response.Strength, minimum)
+				return finish(checkExitWeak, result)
+			}
+
+			if cfg.Breach.Enabled && !noBreach {
+				breachService := services.NewBreachService(logger,
+					services.WithAPIEndpoint(cfg.Breach.APIEndpoint),
+					services.WithTimeout(cfg.Breach.Timeout),
+					services.WithRetries(cfg.Breach.MaxRetries, time.Duration(cfg.Breach.RetryBackoff)*time.Millisecond),
+				)
+				ctx, cancel := context.WithTimeout(cmd.Context(), time.Duration(cfg.Breach.Timeout+1)*time.Second)
+				info, err := breachService.CheckPasswordBreachContext(ctx, password)
+				cancel()
+				switch {
+				case err != nil && failClosed:
+					result.Result = "error"
+					result.Reason = "breach check failed: " + err.Error()
+					return finish(checkExitError, result)
+				case err != nil:
+					result.Warning = "breach check failed: " + err.Error()
+				case info.Found:
+					result.Result = "breached"
+					result.Reason = fmt.Sprintf("password appears in %d known breaches", info.BreachCount)
+					return finish(checkExitBreached, result)
+				}
+			}
+
+			result.Result = "accepted"
+			return finish(checkExitAccepted, result)
+		},
+	}
+	addConfigFlags(cmd.Flags())
+	cmd.Flags().StringVar(&minStrength, "min-strength", string(models.StrengthMedium), "weakest strength accepted: weak, medium, strong, or very_strong")
+	cmd.Flags().BoolVar(&noBreach, "no-breach", false, "skip the breach check")
+	cmd.Flags().BoolVar(&failClosed, "fail-closed", false, "reject the password when the breach check fails instead of accepting it with a warning")
+	return cmd
+}
+
+// readCandidate reads the password from r, ending at the first newline or NUL
+// byte; pam_exec terminates the token with a NUL, chpasswd-style callers with a newline
+func readCandidate(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxCandidateBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	if i := bytes.IndexAny(data, "\n\x00"); i >= 0 {
+		data = data[:i]
+	} else if len(data) > maxCandidateBytes {
+		return "", fmt.Errorf("password exceeds %d bytes", maxCandidateBytes)
+	}
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	if len(data) == 0 {
+		return "", errors.New("no password on stdin")
+	}
+	return string(data), nil
+}
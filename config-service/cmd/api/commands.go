@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"config-service/internal/config"
+	"config-service/internal/logging"
+	"config-service/internal/services"
+	"config-service/internal/version"
+)
+
+// newConfigCommand builds the command group for configuration tasks
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and validate configuration",
+	}
+	cmd.AddCommand(newConfigValidateCommand(), newConfigSchemaCommand())
+	return cmd
+}
+
+// newConfigValidateCommand builds the command that loads and validates the
+// configuration without starting the server, optionally probing the backends
+// it refers to
+func newConfigValidateCommand() *cobra.Command {
+	var probe bool
+	var probeTimeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration and exit",
+		Long: "Validate the configuration and exit non-zero if it is invalid.\n" +
+			"With --probe, also check that every configured backend is reachable.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				if problems := configProblems(err); len(problems) > 0 {
+					for _, problem := range problems {
+						fmt.Fprintf(out, "FAIL %s\n", problem)
+					}
+					return fmt.Errorf("configuration has %d problem(s)", len(problems))
+				}
+				return err
+			}
+			fmt.Fprintln(out, "configuration is valid")
+			if !probe {
+				return nil
+			}
+
+			failed := 0
+			for _, result := range runProbes(cmd.Context(), cfg, probeTimeout) {
+				if result.err != nil {
+					failed++
+					fmt.Fprintf(out, "FAIL %s: %v\n", result.name, result.err)
+					continue
+				}
+				fmt.Fprintf(out, "ok   %s\n", result.name)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d backend probe(s) failed", failed)
+			}
+			return nil
+		},
+	}
+	addConfigFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&probe, "probe", false, "check that configured backends are reachable")
+	cmd.Flags().DurationVar(&probeTimeout, "probe-timeout", 5*time.Second, "timeout for each backend probe")
+	return cmd
+}
+
+// configProblems returns the individual problems of a validation error
+func configProblems(err error) []string {
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Problems
+	}
+	var strictErr *config.StrictError
+	if errors.As(err, &strictErr) {
+		return strictErr.Problems
+	}
+	return nil
+}
+
+// newConfigSchemaCommand builds the command that writes the JSON Schema of the configuration
+func newConfigSchemaCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Export the configuration JSON Schema",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := config.Schema()
+			if err != nil {
+				return err
+			}
+			return writeJSON(cmd, output, schema)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the schema to (default stdout)")
+	return cmd
+}
+
+// newVersionCommand builds the command that prints build information
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print build information",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info := version.Get(nil)
+			fmt.Fprintf(cmd.OutOrStdout(), "config-service %s (commit %s, built %s, %s)\n",
+				info.Version, info.GitCommit, info.BuildTime, info.GoVersion)
+			return nil
+		},
+	}
+}
+
+// newPolicyCommand builds the command group for password policy tasks
+func newPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Work with password policies",
+	}
+	cmd.AddCommand(newPolicyExportCommand())
+	return cmd
+}
+
+// newPolicyExportCommand builds the command that writes the password policies as JSON
+func newPolicyExportCommand() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the password policies as JSON",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			logger := logging.New(io.Discard)
+			return writeJSON(cmd, output, services.NewPolicyService(logger, services.WithDefaultPolicyMaxLength(cfg.Password.MaxLength), services.WithPolicies(cfg.Policies())).List())
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the policies to (default stdout)")
+	return cmd
+}
+
+// writeJSON writes indented JSON to the output file, or to stdout when the
+// output is empty or "-"
+func writeJSON(cmd *cobra.Command, output string, value interface{}) error {
+	out := cmd.OutOrStdout()
+	if output != "" && output != "-" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(value)
+}
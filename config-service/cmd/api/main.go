@@ -1,69 +1,81 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"os"
 
-	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"config-service/internal/config"
-	"config-service/internal/handlers"
-	"config-service/internal/services"
+	"config-service/internal/logging"
 )
 
 func main() {
-	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
-	logger.SetOutput(os.Stdout)
-	logger.SetLevel(logrus.InfoLevel)
-
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Fatalf("Failed to load configuration: %v", err)
+	if err := newRootCommand().Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		os.Exit(1)
 	}
+}
 
-	// Initialize services
-	passwordService := services.NewPasswordService(logger)
-	
-	// Initialize breach service with configuration
-	breachService := services.NewBreachService(
-		logger,
-		services.WithEnabled(cfg.Breach.Enabled),
-		services.WithAPIEndpoint(cfg.Breach.APIEndpoint),
-		services.WithTimeout(cfg.Breach.Timeout),
-		services.WithCacheDuration(cfg.Breach.CacheDuration),
-	)
-
-	// Set Gin mode
-	if cfg.Server.Env == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	} else {
-		gin.SetMode(gin.DebugMode)
+// newRootCommand builds the CLI. Running it without a subcommand starts the
+// server, as before the CLI existed.
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "config-service",
+		Short:        "Password strength and breach detection service",
+		SilenceUsage: true,
 	}
+	root.PersistentFlags().String("config", "", "path to a YAML, JSON, or TOML configuration file (env "+config.ConfigFileEnv+")")
 
-	// Create router
-	r := gin.Default()
+	serve := newServeCommand()
+	root.RunE = serve.RunE
+	addConfigFlags(root.Flags())
 
-	// Add middleware
-	r.Use(handlers.CORSMiddleware())
-	r.Use(handlers.LoggingMiddleware(logger))
-	r.Use(handlers.ErrorHandlingMiddleware(logger))
+	root.AddCommand(serve, newConfigCommand(), newVersionCommand(), newPolicyCommand(), newCheckCommand(), newSelfTestCommand(), newCalibrateCommand())
+	return root
+}
 
-	// Health check endpoint
-	r.GET("/api/v1/health", handlers.HealthCheckHandler)
+// newServeCommand builds the command that starts the HTTP server
+func newServeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Initialize logger with sensitive-data scrubbing
+			logger := logging.New(os.Stdout)
 
-	// Password strength check endpoint (now with breach detection)
-	r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, breachService))
-	
-	// Password breach check endpoint
-	r.POST("/api/v1/password/breach-check", handlers.BreachCheckHandler(breachService))
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				logger.Fatalf("Failed to load configuration: %v", err)
+			}
 
-	// Start server
-	logger.Infof("Starting server on port %d", cfg.Server.Port)
-	if err := r.Run(fmt.Sprintf(":%d", cfg.Server.Port)); err != nil {
-		logger.Fatalf("Failed to start server: %v", err)
+			runServer(logger, cfg, func() (*config.Config, error) {
+				return loadConfig(cmd)
+			})
+			return nil
+		},
 	}
-}
\ No newline at end of file
+	addConfigFlags(cmd.Flags())
+	return cmd
+}
+
+// addConfigFlags adds a flag for every configuration key, e.g. --breach.timeout.
+// Flags override environment variables and the configuration file.
+func addConfigFlags(flags *pflag.FlagSet) {
+	for _, key := range config.Keys() {
+		flags.String(key, "", "sets "+key+" (env "+config.EnvVar(key)+")")
+	}
+	flags.SortFlags = false
+}
+
+// loadConfig loads the configuration using the command's --config and
+// configuration key flags
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	configFile, _ := cmd.Flags().GetString("config")
+	return config.Load(config.WithConfigFile(configFile), config.WithFlags(cmd.Flags()))
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"config-service/internal/auth"
+	"config-service/internal/config"
+	"config-service/internal/errorreport"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+	"config-service/internal/tlsutil"
+)
+
+// probeResult is the outcome of a single reachability check
+type probeResult struct {
+	name string
+	err  error
+}
+
+// probe is a reachability check for a configured backend
+type probe struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// runProbes checks that the backends the configuration refers to are
+// reachable, running every probe even when an earlier one fails
+func runProbes(ctx context.Context, cfg *config.Config, timeout time.Duration) []probeResult {
+	results := make([]probeResult, 0)
+	for _, p := range configProbes(cfg, timeout) {
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		results = append(results, probeResult{name: p.name, err: p.check(probeCtx)})
+		cancel()
+	}
+	return results
+}
+
+// configProbes returns the probes for the backends enabled in the configuration
+func configProbes(cfg *config.Config, timeout time.Duration) []probe {
+	logger := logging.New(io.Discard)
+	client := &http.Client{Timeout: timeout}
+	var probes []probe
+
+	if cfg.Breach.Enabled {
+		breach := services.NewBreachService(logger,
+			services.WithAPIEndpoint(cfg.Breach.APIEndpoint),
+			services.WithTimeout(int(timeout/time.Second)+1),
+		)
+		probes = append(probes, probe{"breach.api_endpoint", breach.Ping})
+	}
+
+	if cfg.TLS.Enabled {
+		probes = append(probes, probe{"tls.cert_file", func(ctx context.Context) error {
+			_, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+			return err
+		}})
+		if cfg.TLS.ClientCAFile != "" {
+			probes = append(probes, probe{"tls.client_ca_file", func(ctx context.Context) error {
+				_, err := tlsutil.LoadCertPool(cfg.TLS.ClientCAFile)
+				return err
+			}})
+		}
+	}
+
+	if cfg.Auth.JWT.Enabled {
+		validator := auth.NewJWTValidator(logger, cfg.Auth.JWT.Issuer, cfg.Auth.JWT.Audience,
+			auth.WithJWKSURL(cfg.Auth.JWT.JWKSURL),
+			auth.WithHTTPClient(client),
+		)
+		probes = append(probes, probe{"auth.jwt", func(ctx context.Context) error {
+			return validator.Refresh()
+		}})
+	}
+
+	if cfg.Throttle.Enabled && cfg.Throttle.CaptchaURL != "" {
+		probes = append(probes, httpProbe("throttle.captcha_verify_url", client, cfg.Throttle.CaptchaURL))
+	}
+
+	if cfg.Recovery.WebhookURL != "" {
+		probes = append(probes, httpProbe("recovery.webhook_url", client, cfg.Recovery.WebhookURL))
+	}
+
+	switch cfg.ErrorReporting.Backend {
+	case errorreport.BackendSentry:
+		if storeURL, _, err := errorreport.ParseDSN(cfg.ErrorReporting.DSN); err == nil {
+			probes = append(probes, httpProbe("error_reporting.dsn", client, storeURL))
+		}
+	case errorreport.BackendWebhook:
+		probes = append(probes, httpProbe("error_reporting.webhook_url", client, cfg.ErrorReporting.WebhookURL))
+	}
+
+	if cfg.Metrics.Backend == metrics.BackendStatsD {
+		probes = append(probes, probe{"metrics.statsd_address", func(ctx context.Context) error {
+			_, err := net.ResolveUDPAddr("udp", cfg.Metrics.StatsDAddress)
+			return err
+		}})
+	}
+
+	if cfg.Audit.Enabled && cfg.Audit.Sink == "file" {
+		probes = append(probes, directoryProbe("audit.path", cfg.Audit.Path))
+	}
+
+	return probes
+}
+
+// httpProbe checks that a server answers at the URL. Client error responses
+// count as reachable, since endpoints such as webhooks reject a bare GET.
+func httpProbe(name string, client *http.Client, rawURL string) probe {
+	return probe{name, func(ctx context.Context) error {
+		if _, err := url.ParseRequestURI(rawURL); err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("server returned status %d", resp.StatusCode)
+		}
+		return nil
+	}}
+}
+
+// directoryProbe checks that the directory a file will be created in exists
+func directoryProbe(name, path string) probe {
+	return probe{name, func(ctx context.Context) error {
+		dir := filepath.Dir(path)
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil
+	}}
+}
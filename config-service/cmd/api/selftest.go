@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+)
+
+// newSelfTestCommand builds the command that checks the scoring engine against
+// the pinned strengths of the reference passwords, or pins them
+func newSelfTestCommand() *cobra.Command {
+	var write bool
+	var output string
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Check the scoring engine against pinned reference strengths",
+		Long: "Score the built-in reference passwords with the configured engine, including\n" +
+			"password dictionaries and rule plugins, and compare their strengths with the\n" +
+			"expectations in password.self_test_expectations. Exits non-zero on drift.\n\n" +
+			"With --write, pin the current strengths instead, to the expectations file,\n" +
+			"the --output file, or stdout.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			passwordService, closeDictionaries, err := newScoringService(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeDictionaries()
+
+			if write {
+				if output == "" {
+					output = cfg.Password.SelfTestExpectations
+				}
+				return writeJSON(cmd, output, passwordService.SelfTestStrengths(cmd.Context(), services.SelfTestCorpus))
+			}
+
+			if cfg.Password.SelfTestExpectations == "" {
+				return errors.New("password.self_test_expectations is not set; pin the strengths with --write first")
+			}
+			expectations, err := services.LoadSelfTestExpectations(cfg.Password.SelfTestExpectations)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			drifts := passwordService.SelfTest(cmd.Context(), expectations)
+			for _, drift := range drifts {
+				fmt.Fprintf(out, "FAIL %q: expected %s, got %s (score %d)\n", drift.Password, drift.Expected, drift.Actual, drift.Score)
+			}
+			if len(drifts) > 0 {
+				return fmt.Errorf("%d of %d reference passwords drifted", len(drifts), len(expectations))
+			}
+			fmt.Fprintf(out, "all %d reference passwords match their expected strength\n", len(expectations))
+			return nil
+		},
+	}
+	addConfigFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&write, "write", false, "pin the current strengths instead of checking them")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the strengths to with --write (default password.self_test_expectations, else stdout)")
+	return cmd
+}
+
+// newScoringService builds a password service scoring as the server would,
+// with its password dictionaries and rule plugins, and returns a function
+// closing the dictionaries
+func newScoringService(cfg *config.Config) (*services.PasswordService, func(), error) {
+	logger := logging.New(io.Discard)
+	passwordOptions := []services.PasswordServiceOption{
+		services.WithBannedList(services.NewBannedListService(logger)),
+		services.WithRulePlugins(app.NewRulePlugins(cfg, metrics.Noop{})...),
+		services.WithMaxLength(cfg.Password.MaxLength),
+	}
+	var dictionaries []*dataset.File
+	closeDictionaries := func() {
+		for _, dictionary := range dictionaries {
+			dictionary.Close()
+		}
+	}
+	for _, path := range cfg.Password.Dictionaries {
+		dictionary, err := dataset.Open(path)
+		if err != nil {
+			closeDictionaries()
+			return nil, nil, fmt.Errorf("failed to load password dictionary: %w", err)
+		}
+		dictionaries = append(dictionaries, dictionary)
+		passwordOptions = append(passwordOptions, services.WithDictionaries(dictionary))
+	}
+	return services.NewPasswordService(logger, passwordOptions...), closeDictionaries, nil
+}
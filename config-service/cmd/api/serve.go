@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+	"config-service/internal/logging"
+	"config-service/internal/server"
+	"config-service/internal/tlsutil"
+	"config-service/internal/version"
+)
+
+// runServer starts the HTTP server with the loaded configuration and blocks until it stops;
+// load reads the configuration again when secrets are rotated.
+func runServer(logger *logrus.Logger, cfg *config.Config, load func() (*config.Config, error)) {
+	// Optionally write logs to a rotating file, for deployments without a log shipper
+	if cfg.Logging.File.Path != "" {
+		logFile, err := logging.NewRotatingFile(cfg.Logging.File.Path,
+			logging.WithMaxSize(int64(cfg.Logging.File.MaxSizeMB)<<20),
+			logging.WithRotationInterval(time.Duration(cfg.Logging.File.RotateInterval)*time.Hour),
+			logging.WithMaxBackups(cfg.Logging.File.MaxBackups),
+			logging.WithCompression(cfg.Logging.File.Compress),
+		)
+		if err != nil {
+			logger.Fatalf("Failed to open log file: %v", err)
+		}
+		defer logFile.Close()
+
+		if cfg.Logging.File.Stdout {
+			logger.SetOutput(io.MultiWriter(os.Stdout, logFile))
+		} else {
+			logger.SetOutput(logFile)
+		}
+	}
+
+	// Size the Go runtime to the container before loading anything large
+	if _, err := app.TuneRuntime(logger, cfg); err != nil {
+		logger.Fatalf("Failed to apply runtime settings: %v", err)
+	}
+
+	// Build the API; the handler owns background work such as secret rotation
+	handler, err := app.NewHandler(logger, cfg, load)
+	if err != nil {
+		logger.Fatalf("Failed to initialize the service: %v", err)
+	}
+	defer handler.Close()
+
+	// Bound every phase of a connection so slow clients cannot exhaust resources
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           handler,
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	// Serve HTTPS natively, reloading renewed certificates without a restart
+	if cfg.TLS.Enabled {
+		reloader, err := tlsutil.NewCertReloader(logger, cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			logger.Fatalf("Failed to initialize TLS: %v", err)
+		}
+		httpServer.TLSConfig, err = tlsutil.NewServerConfig(reloader, cfg.TLS.MinVersion, cfg.TLS.CipherSuites)
+		if err != nil {
+			logger.Fatalf("Failed to initialize TLS: %v", err)
+		}
+		if err := tlsutil.ConfigureClientAuth(httpServer.TLSConfig, cfg.TLS.ClientAuth, cfg.TLS.ClientCAFile, cfg.TLS.ClientAllowedNames); err != nil {
+			logger.Fatalf("Failed to initialize client certificate authentication: %v", err)
+		}
+		if cfg.TLS.AutoReload {
+			if err := reloader.Watch(context.Background()); err != nil {
+				logger.Fatalf("Failed to watch TLS certificate: %v", err)
+			}
+		}
+	}
+
+	// Listeners are taken over from the process being replaced by an upgrade
+	upgrader, err := server.NewUpgrader(logger,
+		server.WithUpgradeTimeout(time.Duration(cfg.Server.UpgradeTimeout)*time.Second),
+		server.WithPIDFile(cfg.Server.PIDFile),
+	)
+	if err != nil {
+		logger.Fatalf("Failed to take over listeners: %v", err)
+	}
+	handedOver := false
+
+	// Start listeners: TCP (HTTPS when TLS is enabled) and/or a Unix socket for
+	// same-host sidecars, and the internal listener when configured
+	conns := server.NewConnTracker()
+	httpServer.ConnState = conns.ConnState
+	servers := []*http.Server{httpServer}
+	serveErrors := make(chan error, 4)
+	if cfg.Server.TCPEnabled {
+		listener, err := upgrader.Listen("tcp:"+httpServer.Addr, func() (net.Listener, error) {
+			return net.Listen("tcp", httpServer.Addr)
+		})
+		if err != nil {
+			logger.Fatalf("Failed to start server: %v", err)
+		}
+
+		go func() {
+			logger.Infof("Starting server version %s (commit %s, built %s) on port %d (TLS: %t)",
+				version.Version, version.GitCommit, version.BuildTime, cfg.Server.Port, cfg.TLS.Enabled)
+			if cfg.TLS.Enabled {
+				serveErrors <- httpServer.ServeTLS(listener, "", "")
+			} else {
+				serveErrors <- httpServer.Serve(listener)
+			}
+		}()
+	}
+	if cfg.Server.UnixSocket != "" {
+		listener, err := upgrader.Listen("unix:"+cfg.Server.UnixSocket, func() (net.Listener, error) {
+			return server.ListenUnix(cfg.Server.UnixSocket, cfg.Server.UnixSocketMode)
+		})
+		if err != nil {
+			logger.Fatalf("Failed to start server: %v", err)
+		}
+		defer func() {
+			// After an upgrade the new process serves on the socket
+			if !handedOver {
+				os.Remove(cfg.Server.UnixSocket)
+			}
+		}()
+
+		go func() {
+			logger.Infof("Starting server version %s on unix socket %s", version.Version, cfg.Server.UnixSocket)
+			serveErrors <- httpServer.Serve(listener)
+		}()
+	}
+
+	if handler.Admin != nil {
+		adminServer := &http.Server{
+			Addr:              fmt.Sprintf("%s:%d", cfg.Server.AdminHost, cfg.Server.AdminPort),
+			Handler:           handler.Admin,
+			ReadTimeout:       httpServer.ReadTimeout,
+			ReadHeaderTimeout: httpServer.ReadHeaderTimeout,
+			WriteTimeout:      httpServer.WriteTimeout,
+			IdleTimeout:       httpServer.IdleTimeout,
+			MaxHeaderBytes:    httpServer.MaxHeaderBytes,
+			ConnState:         conns.ConnState,
+		}
+		if httpServer.TLSConfig != nil {
+			adminServer.TLSConfig = httpServer.TLSConfig.Clone()
+		}
+		listener, err := upgrader.Listen("tcp:"+adminServer.Addr, func() (net.Listener, error) {
+			return net.Listen("tcp", adminServer.Addr)
+		})
+		if err != nil {
+			logger.Fatalf("Failed to start server: %v", err)
+		}
+		servers = append(servers, adminServer)
+
+		go func() {
+			logger.Infof("Serving the admin API and metrics on %s (TLS: %t)", adminServer.Addr, cfg.TLS.Enabled)
+			if cfg.TLS.Enabled {
+				serveErrors <- adminServer.ServeTLS(listener, "", "")
+			} else {
+				serveErrors <- adminServer.Serve(listener)
+			}
+		}()
+	}
+
+	if handler.GRPC != nil {
+		addr := fmt.Sprintf(":%d", cfg.GRPC.Port)
+		listener, err := upgrader.Listen("tcp:"+addr, func() (net.Listener, error) {
+			return net.Listen("tcp", addr)
+		})
+		if err != nil {
+			logger.Fatalf("Failed to start server: %v", err)
+		}
+		if httpServer.TLSConfig != nil {
+			// gRPC runs over HTTP/2 only, with the certificates and client
+			// authentication of the HTTPS listener
+			tlsConfig := httpServer.TLSConfig.Clone()
+			tlsConfig.NextProtos = []string{"h2"}
+			listener = tls.NewListener(listener, tlsConfig)
+		}
+
+		go func() {
+			logger.Infof("Serving the gRPC API on port %d (TLS: %t)", cfg.GRPC.Port, cfg.TLS.Enabled)
+			serveErrors <- handler.GRPC.Serve(listener)
+		}()
+	}
+
+	// Serving: let the process being replaced, if any, shut down
+	if err := upgrader.Ready(); err != nil {
+		logger.Fatalf("Failed to start server: %v", err)
+	}
+	if cfg.Server.PIDFile != "" {
+		defer func() {
+			// The new process has rewritten the PID file after an upgrade
+			if !handedOver {
+				os.Remove(cfg.Server.PIDFile)
+			}
+		}()
+	}
+	if cfg.Server.Upgrades {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		upgrader.WatchSignals(ctx)
+	}
+
+	// Serve until stopped or replaced, then finish the requests in flight
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-serveErrors:
+		logger.Fatalf("Failed to start server: %v", err)
+	case sig := <-stop:
+		logger.WithField("signal", sig.String()).Info("Shutting down")
+	case <-upgrader.Done():
+		handedOver = true
+		logger.Info("Handed the listeners over to the new process; shutting down")
+	}
+	signal.Stop(stop)
+
+	// Stop accepting connections, and let those accepted meanwhile finish
+	// their request before shutting down, which would drop requests read after
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	upgrader.Close()
+	for _, httpServer := range servers {
+		httpServer.SetKeepAlivesEnabled(false)
+	}
+	if err := conns.Wait(ctx); err != nil {
+		logger.WithError(err).Warn("Requests were still in flight when the shutdown timeout expired")
+	}
+	for _, httpServer := range servers {
+		httpServer.Shutdown(ctx)
+	}
+	if handler.GRPC != nil {
+		stopGRPC(ctx, handler)
+	}
+}
+
+// stopGRPC lets the gRPC calls in flight finish, then stops the gRPC server,
+// cancelling the calls still running when the context is done
+func stopGRPC(ctx context.Context, handler *app.Handler) {
+	stopped := make(chan struct{})
+	go func() {
+		handler.GRPC.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		handler.GRPC.Stop()
+	}
+}
@@ -0,0 +1,47 @@
+// Command lambda runs the service as an AWS Lambda function behind an API
+// Gateway proxy integration (REST API or HTTP API). Build it as "bootstrap"
+// for the provided.al2023 runtime. Configuration comes from environment
+// variables and CONFIG_SERVICE_CONFIG_FILE, as for the server.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	awslambda "github.com/aws/aws-lambda-go/lambda"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+	"config-service/internal/lambda"
+	"config-service/internal/logging"
+)
+
+func main() {
+	// Lambda forwards stdout to CloudWatch Logs; a failed start exits, which
+	// Lambda reports as an initialization error
+	logger := logging.New(os.Stdout)
+
+	load := func() (*config.Config, error) {
+		return config.Load()
+	}
+	cfg, err := load()
+	if err != nil {
+		logger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if _, err := app.TuneRuntime(logger, cfg); err != nil {
+		logger.Fatalf("Failed to apply runtime settings: %v", err)
+	}
+
+	handler, err := app.NewHandler(logger, cfg, load)
+	if err != nil {
+		logger.Fatalf("Failed to initialize the service: %v", err)
+	}
+	defer handler.Close()
+
+	// Start never returns; outside Lambda it exits with an error
+	awslambda.Start(func(ctx context.Context, event json.RawMessage) (json.RawMessage, error) {
+		return lambda.Serve(ctx, handler, event)
+	})
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/services"
+	"config-service/pkg/strength"
+)
+
+// Entry kinds of an audited file
+const (
+	kindPassword = "password"
+	kindSHA1     = "sha1"
+	kindNTLM     = "ntlm"
+)
+
+// auditEntry is one credential read from the audited file
+type auditEntry struct {
+	seq   int
+	line  int
+	id    string
+	value string
+}
+
+// auditResult is the report row of one entry. It never contains the password
+// or hash itself.
+type auditResult struct {
+	seq         int
+	Line        int               `json:"line"`
+	ID          string            `json:"id,omitempty"`
+	Kind        string            `json:"kind"`
+	Strength    strength.Strength `json:"strength,omitempty"`
+	Score       *int              `json:"score,omitempty"`
+	Breached    bool              `json:"breached"`
+	BreachCount int               `json:"breach_count"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// auditSummary aggregates the results of an audit
+type auditSummary struct {
+	Total      int                       `json:"total"`
+	Passwords  int                       `json:"passwords"`
+	Hashes     int                       `json:"hashes"`
+	Breached   int                       `json:"breached"`
+	Errors     int                       `json:"errors"`
+	ByStrength map[strength.Strength]int `json:"by_strength"`
+	Duration   string                    `json:"duration"`
+}
+
+// add counts a result in the summary
+func (s *auditSummary) add(result auditResult) {
+	s.Total++
+	if result.Kind == kindPassword {
+		s.Passwords++
+	} else {
+		s.Hashes++
+	}
+	if result.Breached {
+		s.Breached++
+	}
+	if result.Error != "" {
+		s.Errors++
+	}
+	if result.Strength != "" {
+		s.ByStrength[result.Strength]++
+	}
+}
+
+// auditOptions configures an audit
+type auditOptions struct {
+	format   string
+	field    string
+	idField  string
+	kind     string
+	workers  int
+	report   string
+	output   string
+	noBreach bool
+	timeout  time.Duration
+}
+
+// newAuditCommand builds the command that audits a file of credentials
+func newAuditCommand() *cobra.Command {
+	opts := auditOptions{}
+	cmd := &cobra.Command{
+		Use:   "audit FILE",
+		Short: "Audit a file of passwords or SHA-1/NTLM hashes",
+		Long: "Audit a CSV or NDJSON file of passwords or SHA-1/NTLM hashes, e.g. a\n" +
+			"credential dump. Entries are scored and checked for breaches concurrently,\n" +
+			"and a CSV or JSON report with summary statistics is written. The report\n" +
+			"identifies entries by line and --id-field, never by the credential itself.\n" +
+			"Auditing always runs locally; breach checks query the HIBP range API with\n" +
+			"5-character hash prefixes unless --no-breach is set. Use - to read stdin.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.timeout, _ = cmd.Flags().GetDuration("timeout")
+			return runAudit(cmd, args[0], opts)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.format, "format", "auto", "input format: csv, ndjson, or auto to use the file extension")
+	flags.StringVar(&opts.field, "field", "", "CSV column or NDJSON key holding the credential; a CSV file with --field has a header row (default: first CSV column, NDJSON \"password\" or \"hash\")")
+	flags.StringVar(&opts.idField, "id-field", "", "CSV column or NDJSON key copied into the report to identify entries, e.g. a username")
+	flags.StringVar(&opts.kind, "type", "auto", "credential type: password, sha1, ntlm, or auto to detect hashes by length")
+	flags.IntVar(&opts.workers, "workers", 8, "concurrent checks")
+	flags.StringVar(&opts.report, "report", "json", "report format: json or csv")
+	flags.StringVarP(&opts.output, "output", "o", "-", "report file; - writes to stdout")
+	flags.BoolVar(&opts.noBreach, "no-breach", false, "skip breach checks and stay fully offline")
+	return cmd
+}
+
+// runAudit reads the file, checks its entries with a worker pool, and writes
+// the report in file order
+func runAudit(cmd *cobra.Command, path string, opts auditOptions) error {
+	switch opts.kind {
+	case "auto", kindPassword, kindSHA1, kindNTLM:
+	default:
+		return fmt.Errorf("invalid --type %q (must be auto, password, sha1, or ntlm)", opts.kind)
+	}
+	if opts.workers < 1 {
+		return fmt.Errorf("--workers must be positive")
+	}
+
+	in := cmd.InOrStdin()
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	format := opts.format
+	if format == "auto" {
+		format = "csv"
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".ndjson" || ext == ".jsonl" {
+			format = "ndjson"
+		}
+	}
+	var read func(io.Reader, auditOptions, chan<- auditEntry) error
+	switch format {
+	case "csv":
+		read = readCSVEntries
+	case "ndjson":
+		read = readNDJSONEntries
+	default:
+		return fmt.Errorf("invalid --format %q (must be csv, ndjson, or auto)", opts.format)
+	}
+
+	out := cmd.OutOrStdout()
+	if opts.output != "-" {
+		file, err := os.Create(opts.output)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	writer, err := newReportWriter(out, opts.report)
+	if err != nil {
+		return err
+	}
+
+	auditor := newAuditor(opts)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
+
+	lines := make(chan auditEntry, opts.workers)
+	entries := make(chan auditEntry, opts.workers)
+	results := make(chan auditResult, opts.workers)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- read(in, opts, lines)
+		close(lines)
+	}()
+	go func() {
+		// Number the entries so the report can be written in file order
+		seq := 0
+		for entry := range lines {
+			entry.seq = seq
+			seq++
+			entries <- entry
+		}
+		close(entries)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range entries {
+				results <- auditor.check(ctx, entry)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results arrive out of order; buffer them to write the report in file order
+	summary := auditSummary{ByStrength: make(map[strength.Strength]int)}
+	pending := make(map[int]auditResult)
+	next := 0
+	var writeErr error
+	for result := range results {
+		pending[result.seq] = result
+		for {
+			ready, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			summary.add(ready)
+			if writeErr == nil {
+				writeErr = writer.write(ready)
+			}
+		}
+	}
+	if err := <-readErr; err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return fmt.Errorf("failed to write report: %w", writeErr)
+	}
+
+	summary.Duration = time.Since(start).Round(time.Millisecond).String()
+	if err := writer.close(summary); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "audited %d entries: %d breached, %d weak, %d errors\n",
+		summary.Total, summary.Breached, summary.ByStrength[strength.Weak], summary.Errors)
+	return nil
+}
+
+// auditor checks single entries
+type auditor struct {
+	kind    string
+	checker *strength.Checker
+	breach  *services.BreachService
+
+	// results are reused between entries, since only the score is kept
+	results sync.Pool
+}
+
+// newAuditor creates an auditor; without breach checks it makes no requests
+func newAuditor(opts auditOptions) *auditor {
+	a := &auditor{kind: opts.kind, checker: strength.NewChecker()}
+	a.results.New = func() interface{} { return new(strength.Result) }
+	if !opts.noBreach {
+		a.breach = services.NewBreachService(logging.New(io.Discard),
+			services.WithTimeout(int(opts.timeout/time.Second)),
+			services.WithCacheDuration(24*60))
+	}
+	return a
+}
+
+// check scores a password or looks up a hash
+func (a *auditor) check(ctx context.Context, entry auditEntry) auditResult {
+	result := auditResult{seq: entry.seq, Line: entry.line, ID: entry.id, Kind: a.kindOf(entry.value)}
+
+	if result.Kind == kindPassword {
+		scored := a.results.Get().(*strength.Result)
+		a.checker.CheckInto(entry.value, scored)
+		score := scored.Score
+		result.Strength, result.Score = scored.Strength, &score
+		a.results.Put(scored)
+		if a.breach != nil {
+			info, err := a.breach.CheckPasswordBreachContext(ctx, entry.value)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Breached, result.BreachCount = info.Found, info.BreachCount
+			}
+		}
+		return result
+	}
+
+	if a.breach == nil {
+		return result
+	}
+	var checked []models.BreachAuditResult
+	if result.Kind == kindNTLM {
+		checked = a.breach.CheckNTLMHashesBreach(ctx, []string{entry.value})
+	} else {
+		checked = a.breach.CheckHashesBreach(ctx, []string{entry.value})
+	}
+	result.Breached, result.BreachCount, result.Error = checked[0].Found, checked[0].BreachCount, checked[0].Error
+	return result
+}
+
+// kindOf returns the entry kind, detecting SHA-1 and NTLM hashes by length
+// unless --type forces one
+func (a *auditor) kindOf(value string) string {
+	if a.kind != "auto" {
+		return a.kind
+	}
+	if _, err := hex.DecodeString(value); err == nil {
+		switch len(value) {
+		case 40:
+			return kindSHA1
+		case 32:
+			return kindNTLM
+		}
+	}
+	return kindPassword
+}
+
+// readCSVEntries reads entries from CSV. With --field the first row is a
+// header naming the columns; otherwise the credential is the first column.
+func readCSVEntries(in io.Reader, opts auditOptions, entries chan<- auditEntry) error {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	column, idColumn := 0, -1
+	line := 0
+	if opts.field != "" {
+		header, err := reader.Read()
+		if err != nil {
+			return fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		if column = indexOf(header, opts.field); column < 0 {
+			return fmt.Errorf("CSV header has no column %q", opts.field)
+		}
+		if opts.idField != "" {
+			if idColumn = indexOf(header, opts.idField); idColumn < 0 {
+				return fmt.Errorf("CSV header has no column %q", opts.idField)
+			}
+		}
+		line++
+	} else if opts.idField != "" {
+		return fmt.Errorf("--id-field needs --field for CSV files")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line++
+		if column >= len(record) || record[column] == "" {
+			continue
+		}
+		entry := auditEntry{line: line, value: record[column]}
+		if idColumn >= 0 && idColumn < len(record) {
+			entry.id = record[idColumn]
+		}
+		entries <- entry
+	}
+}
+
+// readNDJSONEntries reads one JSON object per line
+func readNDJSONEntries(in io.Reader, opts auditOptions, entries chan<- auditEntry) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return fmt.Errorf("line %d: invalid JSON: %w", line, err)
+		}
+		value := stringField(record, opts.field)
+		if opts.field == "" {
+			if value = stringField(record, "password"); value == "" {
+				value = stringField(record, "hash")
+			}
+		}
+		if value == "" {
+			continue
+		}
+		entries <- auditEntry{line: line, id: stringField(record, opts.idField), value: value}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read NDJSON: %w", err)
+	}
+	return nil
+}
+
+// stringField returns a field of a JSON object as a string
+func stringField(record map[string]interface{}, name string) string {
+	if name == "" {
+		return ""
+	}
+	switch value := record[name].(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// indexOf returns the index of a column name in a header, or -1
+func indexOf(header []string, name string) int {
+	for i, column := range header {
+		if strings.TrimSpace(column) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// reportWriter writes the audit report incrementally
+type reportWriter struct {
+	write func(auditResult) error
+	close func(auditSummary) error
+}
+
+// newReportWriter creates a JSON or CSV report writer. The JSON report holds
+// the results and the summary; the CSV report holds the results only, as the
+// summary is printed to stderr.
+func newReportWriter(out io.Writer, format string) (*reportWriter, error) {
+	switch format {
+	case "json":
+		first := true
+		fmt.Fprint(out, "{\"results\": [")
+		return &reportWriter{
+			write: func(result auditResult) error {
+				encoded, err := json.Marshal(result)
+				if err != nil {
+					return err
+				}
+				separator := ",\n  "
+				if first {
+					separator, first = "\n  ", false
+				}
+				_, err = fmt.Fprintf(out, "%s%s", separator, encoded)
+				return err
+			},
+			close: func(summary auditSummary) error {
+				encoded, err := json.MarshalIndent(summary, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintf(out, "\n],\n\"summary\": %s}\n", encoded)
+				return err
+			},
+		}, nil
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write([]string{"line", "id", "kind", "strength", "score", "breached", "breach_count", "error"}); err != nil {
+			return nil, err
+		}
+		return &reportWriter{
+			write: func(result auditResult) error {
+				score := ""
+				if result.Score != nil {
+					score = strconv.Itoa(*result.Score)
+				}
+				return w.Write([]string{
+					strconv.Itoa(result.Line), result.ID, result.Kind, string(result.Strength), score,
+					strconv.FormatBool(result.Breached), strconv.Itoa(result.BreachCount), result.Error,
+				})
+			},
+			close: func(auditSummary) error {
+				w.Flush()
+				return w.Error()
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid --report %q (must be json or csv)", format)
+	}
+}
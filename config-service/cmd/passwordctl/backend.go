@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// backend checks passwords either through the service or locally
+type backend interface {
+	Check(ctx context.Context, password string) (*models.PasswordResponse, error)
+	Breach(ctx context.Context, password string) (*models.BreachInfo, error)
+	Policies(ctx context.Context) ([]models.PasswordPolicy, error)
+}
+
+// remoteBackend calls the HTTP API of a running service
+type remoteBackend struct {
+	baseURL    string
+	apiKey     string
+	adminToken string
+	httpClient *http.Client
+}
+
+// newRemoteBackend creates a client for the service at baseURL
+func newRemoteBackend(baseURL, apiKey, adminToken string, timeout time.Duration) *remoteBackend {
+	return &remoteBackend{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		adminToken: adminToken,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Check scores a password, including breach data when the service has it enabled
+func (b *remoteBackend) Check(ctx context.Context, password string) (*models.PasswordResponse, error) {
+	var response models.PasswordResponse
+	err := b.do(ctx, http.MethodPost, "/api/v1/password/check", b.apiKey, models.PasswordRequest{Password: password}, &response)
+	return &response, err
+}
+
+// Breach checks a password against the breach corpus
+func (b *remoteBackend) Breach(ctx context.Context, password string) (*models.BreachInfo, error) {
+	var response models.BreachInfo
+	err := b.do(ctx, http.MethodPost, "/api/v1/password/breach-check", b.apiKey, models.PasswordRequest{Password: password}, &response)
+	return &response, err
+}
+
+// Policies lists the password policies; it requires the admin token
+func (b *remoteBackend) Policies(ctx context.Context) ([]models.PasswordPolicy, error) {
+	var response struct {
+		Policies []models.PasswordPolicy `json:"policies"`
+	}
+	err := b.do(ctx, http.MethodGet, "/api/v1/admin/policies?limit=100", b.adminToken, nil, &response)
+	return response.Policies, err
+}
+
+// do sends a JSON request and decodes the JSON response, turning error
+// responses into errors carrying the service's message
+func (b *remoteBackend) do(ctx context.Context, method, path, token string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", b.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s: %s", apiErr.Error, apiErr.Message)
+		}
+		return fmt.Errorf("service returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// localBackend runs the scoring library in-process. Breach checks still query
+// the HIBP range API, which only ever receives a 5-character hash prefix.
+type localBackend struct {
+	passwords *services.PasswordService
+	breaches  *services.BreachService
+	policies  *services.PolicyService
+}
+
+// newLocalBackend creates a backend that needs no running service
+func newLocalBackend(timeout time.Duration) *localBackend {
+	logger := logging.New(io.Discard)
+	return &localBackend{
+		passwords: services.NewPasswordService(logger),
+		breaches:  services.NewBreachService(logger, services.WithTimeout(int(timeout/time.Second))),
+		policies:  services.NewPolicyService(logger),
+	}
+}
+
+// Check validates and scores a password without breach data
+func (b *localBackend) Check(ctx context.Context, password string) (*models.PasswordResponse, error) {
+	return b.passwords.CheckPasswordStrengthContext(ctx, password)
+}
+
+// Breach checks a password against the HIBP range API
+func (b *localBackend) Breach(ctx context.Context, password string) (*models.BreachInfo, error) {
+	return b.breaches.CheckPasswordBreachContext(ctx, password)
+}
+
+// Policies returns the built-in password policies
+func (b *localBackend) Policies(ctx context.Context) ([]models.PasswordPolicy, error) {
+	return b.policies.List(), nil
+}
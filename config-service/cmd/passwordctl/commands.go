@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"config-service/internal/models"
+)
+
+// strengthRank orders the strength levels for --min-strength
+var strengthRank = map[models.PasswordStrength]int{
+	models.StrengthWeak:       0,
+	models.StrengthMedium:     1,
+	models.StrengthStrong:     2,
+	models.StrengthVeryStrong: 3,
+}
+
+// newCheckCommand builds the command that scores passwords
+func newCheckCommand() *cobra.Command {
+	var minStrength string
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Score passwords read from stdin",
+		Long: "Score passwords read from stdin and print one JSON result per line.\n" +
+			"With --min-strength, exit non-zero if any password is weaker.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			minimum, ok := strengthRank[models.PasswordStrength(minStrength)]
+			if minStrength != "" && !ok {
+				return fmt.Errorf("invalid --min-strength %q (must be weak, medium, strong, or very_strong)", minStrength)
+			}
+
+			passwords, err := readPasswords(cmd)
+			if err != nil {
+				return err
+			}
+
+			b := backendFor(cmd)
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			failed, tooWeak := 0, 0
+			for _, password := range passwords {
+				response, err := b.Check(cmd.Context(), password)
+				if err != nil {
+					failed++
+					encoder.Encode(map[string]string{"error": err.Error()})
+					continue
+				}
+				if minStrength != "" && strengthRank[response.Strength] < minimum {
+					tooWeak++
+				}
+				encoder.Encode(response)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d password(s) could not be checked", failed)
+			}
+			if tooWeak > 0 {
+				return fmt.Errorf("%d password(s) weaker than %s", tooWeak, minStrength)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&minStrength, "min-strength", "", "fail if a password is weaker: weak, medium, strong, or very_strong")
+	return cmd
+}
+
+// newBreachCommand builds the command that checks passwords against the breach corpus
+func newBreachCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "breach",
+		Short: "Check passwords read from stdin against known breaches",
+		Long: "Check passwords read from stdin against known breaches and print one\n" +
+			"JSON result per line. Exits non-zero if any password was breached.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passwords, err := readPasswords(cmd)
+			if err != nil {
+				return err
+			}
+
+			b := backendFor(cmd)
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			failed, breached := 0, 0
+			for _, password := range passwords {
+				info, err := b.Breach(cmd.Context(), password)
+				if err != nil {
+					failed++
+					encoder.Encode(map[string]string{"error": err.Error()})
+					continue
+				}
+				if info.Found {
+					breached++
+				}
+				encoder.Encode(info)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d password(s) could not be checked", failed)
+			}
+			if breached > 0 {
+				return fmt.Errorf("%d password(s) found in breaches", breached)
+			}
+			return nil
+		},
+	}
+}
+
+// newGenerateCommand builds the command that generates random passwords
+func newGenerateCommand() *cobra.Command {
+	var length, count int
+	var noSymbols bool
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate random passwords",
+		Long: "Generate random passwords with a cryptographically secure generator,\n" +
+			"one per line. Generation always runs locally.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if count < 1 {
+				return fmt.Errorf("--count must be positive")
+			}
+			generator := newGenerator()
+			for i := 0; i < count; i++ {
+				password, err := generatePassword(generator, length, !noSymbols)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), password)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&length, "length", 20, "password length")
+	cmd.Flags().IntVar(&count, "count", 1, "number of passwords")
+	cmd.Flags().BoolVar(&noSymbols, "no-symbols", false, "use only letters and digits")
+	return cmd
+}
+
+// newPolicyCommand builds the command that prints the password policies
+func newPolicyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "policy",
+		Short: "Print the password policies as JSON",
+		Long: "Print the password policies as JSON. Against a service this needs the\n" +
+			"admin token in " + adminTokenEnv + ".",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policies, err := backendFor(cmd).Policies(cmd.Context())
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(policies)
+		},
+	}
+}
@@ -0,0 +1,11 @@
+//go:build darwin || freebsd || netbsd || openbsd
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// Terminal attribute requests on BSD-derived systems
+const (
+	getTermios = unix.TIOCGETA
+	setTermios = unix.TIOCSETA
+)
@@ -0,0 +1,9 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// Terminal attribute requests on Linux
+const (
+	getTermios = unix.TCGETS
+	setTermios = unix.TCSETS
+)
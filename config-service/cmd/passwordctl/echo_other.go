@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// disableEcho is unsupported on this platform; pipe the password to stdin instead
+func disableEcho(file *os.File) (func(), error) {
+	return nil, errors.New("hiding terminal input is not supported on this platform; pipe the password to stdin")
+}
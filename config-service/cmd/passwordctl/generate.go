@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// passwordClasses are the character classes of generated passwords, symbols
+// last so that --no-symbols can leave them out
+var passwordClasses = []string{
+	models.CharacterClassUppercase,
+	models.CharacterClassLowercase,
+	models.CharacterClassDigits,
+	models.CharacterClassSymbols,
+}
+
+// newGenerator returns the generator behind the service's generation
+// endpoint, without the service's limit on length
+func newGenerator() *services.GeneratorService {
+	return services.NewGeneratorService(services.NewPasswordStrengthChecker(), services.WithGeneratedMaxLength(math.MaxInt32))
+}
+
+// generatePassword returns a random password of the given length with at
+// least one character of every class. Look-alike characters such as O and 0
+// are left out.
+func generatePassword(generator *services.GeneratorService, length int, symbols bool) (string, error) {
+	classes := passwordClasses
+	if !symbols {
+		classes = classes[:len(classes)-1]
+	}
+	if length < len(classes) {
+		return "", fmt.Errorf("--length must be at least %d", len(classes))
+	}
+
+	generated, err := generator.Generate(models.GenerateRequest{Length: length, Classes: classes})
+	if err != nil {
+		return "", err
+	}
+	return generated.Password, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// readPasswords reads the passwords to check from stdin, one per line. On a
+// terminal a single password is prompted for with echo turned off.
+func readPasswords(cmd *cobra.Command) ([]string, error) {
+	in := cmd.InOrStdin()
+	if file, ok := in.(*os.File); ok && isTerminal(file) {
+		fmt.Fprint(cmd.ErrOrStderr(), "Password: ")
+		restore, err := disableEcho(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to turn off terminal echo: %w", err)
+		}
+		line, readErr := bufio.NewReader(file).ReadString('\n')
+		restore()
+		fmt.Fprintln(cmd.ErrOrStderr())
+		if readErr != nil && readErr != io.EOF {
+			return nil, readErr
+		}
+		return []string{strings.TrimRight(line, "\r\n")}, nil
+	}
+
+	var passwords []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			passwords = append(passwords, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read passwords: %w", err)
+	}
+	if len(passwords) == 0 {
+		return nil, fmt.Errorf("no password on stdin")
+	}
+	return passwords, nil
+}
+
+// isTerminal reports whether the file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
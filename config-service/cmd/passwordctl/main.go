@@ -0,0 +1,65 @@
+// Command passwordctl checks, breach-checks, and generates passwords from the
+// command line, either against a running config-service or locally with the
+// scoring library. Passwords are read from stdin, never from arguments, so
+// they do not end up in shell history or process listings.
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Environment variables configuring the connection to the service. Credentials
+// are only read from the environment, never from flags.
+const (
+	serverEnv     = "PASSWORDCTL_SERVER"
+	apiKeyEnv     = "PASSWORDCTL_API_KEY"
+	adminTokenEnv = "PASSWORDCTL_ADMIN_TOKEN"
+)
+
+// defaultServer is the service URL used when neither --server nor
+// PASSWORDCTL_SERVER is set
+const defaultServer = "http://localhost:8080"
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the CLI
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "passwordctl",
+		Short: "Check and generate passwords with config-service",
+		Long: "Check and generate passwords with config-service.\n\n" +
+			"Passwords are read from stdin, one per line; on a terminal they are\n" +
+			"prompted for without echo. The API key and admin token are read from\n" +
+			apiKeyEnv + " and " + adminTokenEnv + ".",
+		SilenceUsage: true,
+	}
+
+	server := os.Getenv(serverEnv)
+	if server == "" {
+		server = defaultServer
+	}
+	flags := root.PersistentFlags()
+	flags.String("server", server, "config-service base URL (env "+serverEnv+")")
+	flags.Bool("offline", false, "run the scoring library locally instead of calling the service")
+	flags.Duration("timeout", 10*time.Second, "request timeout")
+
+	root.AddCommand(newCheckCommand(), newBreachCommand(), newGenerateCommand(), newPolicyCommand(), newAuditCommand())
+	return root
+}
+
+// backendFor returns the service client, or the local backend with --offline
+func backendFor(cmd *cobra.Command) backend {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if offline, _ := cmd.Flags().GetBool("offline"); offline {
+		return newLocalBackend(timeout)
+	}
+	server, _ := cmd.Flags().GetString("server")
+	return newRemoteBackend(server, os.Getenv(apiKeyEnv), os.Getenv(adminTokenEnv), timeout)
+}
@@ -0,0 +1,723 @@
+package app
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"config-service/internal/audit"
+	"config-service/internal/auth"
+	"config-service/internal/clientip"
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+	"config-service/internal/errorreport"
+	"config-service/internal/grpcapi"
+	"config-service/internal/handlers"
+	"config-service/internal/health"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/queue"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+	"config-service/internal/version"
+	"config-service/internal/workpool"
+)
+
+// Handler serves the complete password API. It holds the background work
+// started for it, such as secret rotation and audit retention, until closed.
+type Handler struct {
+	http.Handler
+
+	// Admin serves the admin API, metrics, and deep health checks when they
+	// are configured to have a listener of their own, and is nil otherwise
+	Admin http.Handler
+
+	// GRPC serves the password API over gRPC when it is enabled, and is nil
+	// otherwise; it is not listening until served
+	GRPC *grpc.Server
+
+	cancel  context.CancelFunc
+	closers []func()
+}
+
+// Close stops the background work and releases the resources of the handler
+func (h *Handler) Close() {
+	h.cancel()
+	for i := len(h.closers) - 1; i >= 0; i-- {
+		h.closers[i]()
+	}
+	h.closers = nil
+}
+
+// NewHandler builds the services, middleware, and routes described by the
+// configuration, without listening on any address, so the API can be served
+// by an http.Server or by another runtime such as AWS Lambda. load reads the
+// configuration again when secrets are rotated.
+func NewHandler(logger *logrus.Logger, cfg *config.Config, load func() (*config.Config, error)) (_ *Handler, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Handler{cancel: cancel}
+	defer func() {
+		if err != nil {
+			h.Close()
+		}
+	}()
+
+	// Apply the configured log level and format; both can be changed at runtime
+	logController, err := logging.NewController(logger, cfg.Logging.Level, cfg.Logging.Format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure logging: %w", err)
+	}
+	logController.WatchSignals(ctx)
+
+	// Log the resolved configuration, with secrets redacted, to make misconfiguration easy to spot
+	logger.WithField("config", cfg.Redacted()).Info("Resolved configuration")
+
+	// Access logs may use their own format, e.g. logfmt, to reduce volume
+	accessLogger := logger
+	if cfg.Logging.AccessFormat != "" && cfg.Logging.AccessFormat != cfg.Logging.Format {
+		accessLogger = logging.New(logger.Out)
+		formatter, _ := logging.NewFormatter(cfg.Logging.AccessFormat)
+		accessLogger.SetFormatter(formatter)
+		logController.Attach(accessLogger)
+	}
+
+	// Initialize the metrics backend
+	var recorder metrics.Recorder = metrics.Noop{}
+	var prometheus *metrics.Prometheus
+	switch cfg.Metrics.Backend {
+	case metrics.BackendPrometheus:
+		prometheus = metrics.NewPrometheus(cfg.Metrics.Namespace)
+		recorder = prometheus
+	case metrics.BackendStatsD:
+		statsd, err := metrics.NewStatsD(cfg.Metrics.StatsDAddress, cfg.Metrics.Namespace, cfg.Metrics.DogStatsDTags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize metrics: %w", err)
+		}
+		h.closers = append(h.closers, func() { statsd.Close() })
+		recorder = statsd
+	}
+
+	// Wordlists and datasets are verified against their checksums, and
+	// signatures when a signing key is configured, before they are used
+	var signingKey ed25519.PublicKey
+	if cfg.Datasets.SigningKey != "" {
+		if signingKey, err = dataset.LoadVerificationKey(cfg.Datasets.SigningKey); err != nil {
+			return nil, err
+		}
+	}
+	verifier := dataset.NewVerifier(cfg.Datasets.Checksums, signingKey)
+
+	// Large wordlists are memory-mapped instead of loaded into the heap
+	loadDataset := func(path string, options ...dataset.Option) (*dataset.File, error) {
+		file, err := dataset.Open(path, options...)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifier.VerifyFile(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+		logger.WithFields(logrus.Fields{"path": path, "entries": file.Len()}).Info("Loaded dataset")
+		return file, nil
+	}
+	openDataset := func(path string, options ...dataset.Option) (*dataset.File, error) {
+		file, err := loadDataset(path, options...)
+		if err != nil {
+			return nil, err
+		}
+		h.closers = append(h.closers, func() { file.Close() })
+		return file, nil
+	}
+	var dictionaries []*dataset.File
+	for _, path := range cfg.Password.Dictionaries {
+		dictionary, err := openDataset(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load password dictionary: %w", err)
+		}
+		dictionaries = append(dictionaries, dictionary)
+	}
+	var offlineSHA1, offlineNTLM *dataset.File
+	if cfg.Breach.OfflineSHA1Path != "" {
+		if offlineSHA1, err = openDataset(cfg.Breach.OfflineSHA1Path, dataset.WithSeparator(':')); err != nil {
+			return nil, fmt.Errorf("failed to load offline breach dataset: %w", err)
+		}
+	}
+	if cfg.Breach.OfflineNTLMPath != "" {
+		if offlineNTLM, err = openDataset(cfg.Breach.OfflineNTLMPath, dataset.WithSeparator(':')); err != nil {
+			return nil, fmt.Errorf("failed to load offline breach dataset: %w", err)
+		}
+	}
+
+	// Signed webhook deliveries of breach, policy, quota, and decoy events
+	var webhookService *services.WebhookService
+	if cfg.Webhooks.Enabled {
+		webhookService = services.NewWebhookService(logger,
+			services.WithWebhookTimeout(time.Duration(cfg.Webhooks.Timeout)*time.Second),
+			services.WithWebhookRetries(cfg.Webhooks.MaxAttempts, time.Duration(cfg.Webhooks.RetryBackoff)*time.Millisecond),
+			services.WithWebhookWorkers(cfg.Webhooks.Workers),
+			services.WithDeadLetterLimit(cfg.Webhooks.DeadLetterLimit),
+			services.WithWebhookCircuitBreaker(cfg.Webhooks.CircuitThreshold, time.Duration(cfg.Webhooks.CircuitCooldown)*time.Second),
+			services.WithDeliveryLogLimit(cfg.Webhooks.DeliveryLogLimit),
+			services.WithWebhookMetrics(recorder),
+		)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			webhookService.Run(ctx)
+		}()
+		h.closers = append(h.closers, func() { <-done })
+	}
+
+	// The same events published to a streaming platform
+	var eventPublishers services.EventPublishers
+	if webhookService != nil {
+		eventPublishers = append(eventPublishers, webhookService)
+	}
+	if cfg.Events.Enabled {
+		if !queue.HasPublisher(cfg.Events.Driver) {
+			return nil, fmt.Errorf("unknown events driver %q (available: %v)", cfg.Events.Driver, queue.PublisherDrivers())
+		}
+		publisherOptions := queue.PublisherOptions{
+			Name:      "config-service",
+			Token:     cfg.Events.Token,
+			Mechanism: cfg.Events.SASLMechanism,
+			Delivery:  queue.Delivery(cfg.Events.Delivery),
+			Timeout:   time.Duration(cfg.Events.AckTimeout) * time.Second,
+		}
+		eventStream := services.NewEventStream(logger,
+			func() (queue.Publisher, error) {
+				return queue.OpenPublisher(cfg.Events.Driver, cfg.Events.URL, publisherOptions)
+			},
+			services.WithEventTopic(cfg.Events.Topic),
+			services.WithEventTypes(cfg.Events.Types),
+			services.WithEventDelivery(queue.Delivery(cfg.Events.Delivery)),
+			services.WithEventBuffer(cfg.Events.BufferSize),
+			services.WithEventRetryBackoff(time.Duration(cfg.Events.RetryBackoff)*time.Millisecond),
+			services.WithEventMetrics(recorder),
+		)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			eventStream.Run(ctx)
+		}()
+		h.closers = append(h.closers, func() { <-done })
+		eventPublishers = append(eventPublishers, eventStream)
+	}
+	var events services.EventPublisher
+	if len(eventPublishers) > 0 {
+		events = eventPublishers
+	}
+
+	// Initialize services
+	bannedListService := services.NewBannedListService(logger, services.WithDeletedRetention(time.Duration(cfg.Admin.DeletedRetentionDays)*24*time.Hour))
+	apiKeyService := services.NewAPIKeyService(logger)
+	passwordOptions := []services.PasswordServiceOption{
+		services.WithBannedList(bannedListService),
+		services.WithRulePlugins(NewRulePlugins(cfg, recorder)...),
+		services.WithDictionaries(dictionaries...),
+		services.WithMaxLength(cfg.Password.MaxLength),
+	}
+	if cfg.Password.Decoys != "" {
+		decoys, err := services.LoadDecoys(cfg.Password.Decoys)
+		if err != nil {
+			return nil, err
+		}
+		decoyService := services.NewDecoyService(logger, decoys,
+			services.WithDecoyEvents(events),
+			services.WithDecoyMetrics(recorder),
+		)
+		logger.WithField("count", decoyService.Len()).Info("Loaded decoy passwords")
+		passwordOptions = append(passwordOptions, services.WithDecoys(decoyService))
+	}
+	var memo *services.StrengthMemo
+	if cfg.Password.MemoEnabled {
+		memo = services.NewStrengthMemo(time.Duration(cfg.Password.MemoTTL)*time.Second, cfg.Password.MemoMaxEntries)
+		passwordOptions = append(passwordOptions, services.WithStrengthMemo(memo))
+	}
+	passwordService := services.NewPasswordService(logger, passwordOptions...)
+	generatorService := services.NewGeneratorService(services.NewPasswordStrengthChecker(),
+		services.WithGeneratedLength(cfg.Password.GenerateLength),
+		services.WithGeneratedMaxLength(cfg.Password.MaxLength),
+		services.WithPassphraseWords(cfg.Password.PassphraseWords),
+	)
+
+	// The scoring self-test catches weight and configuration mistakes before
+	// any traffic is served
+	if cfg.Password.SelfTest {
+		if err := runSelfTest(ctx, logger, cfg, passwordService); err != nil {
+			return nil, err
+		}
+	}
+
+	// Bulk operations share one bounded worker pool
+	bulkPool := workpool.New("bulk",
+		workpool.WithWorkers(cfg.Bulk.Workers),
+		workpool.WithQueueSize(cfg.Bulk.QueueSize),
+		workpool.WithItemTimeout(time.Duration(cfg.Bulk.ItemTimeout)*time.Millisecond),
+		workpool.WithMaxPerRun(cfg.Bulk.MaxPerRequest),
+		workpool.WithMetrics(recorder),
+	)
+	h.closers = append(h.closers, bulkPool.Close)
+
+	// Initialize breach service with configuration
+	breachOptions := []services.BreachServiceOption{
+		services.WithEnabled(cfg.Breach.Enabled),
+		services.WithAPIEndpoint(cfg.Breach.APIEndpoint),
+		services.WithTimeout(cfg.Breach.Timeout),
+		services.WithCacheDuration(cfg.Breach.CacheDuration),
+		services.WithRetries(cfg.Breach.MaxRetries, time.Duration(cfg.Breach.RetryBackoff)*time.Millisecond),
+		services.WithCircuitBreaker(cfg.Breach.CircuitThreshold, time.Duration(cfg.Breach.CircuitCooldown)*time.Second),
+		services.WithTransport(services.TransportSettings{
+			MaxIdleConnsPerHost: cfg.Breach.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     cfg.Breach.MaxConnsPerHost,
+			IdleConnTimeout:     time.Duration(cfg.Breach.IdleConnTimeout) * time.Second,
+			KeepAlive:           time.Duration(cfg.Breach.KeepAlive) * time.Second,
+			TLSSessionCacheSize: cfg.Breach.TLSSessionCache,
+		}),
+		services.WithWorkPool(bulkPool),
+		services.WithOfflineDatasets(offlineSHA1, offlineNTLM),
+		services.WithMetrics(recorder),
+	}
+	if chaos := cfg.Breach.Chaos; chaos.Enabled {
+		logger.WithFields(logrus.Fields{
+			"timeout_rate":    chaos.TimeoutRate,
+			"rate_limit_rate": chaos.RateLimitRate,
+			"malformed_rate":  chaos.MalformedRate,
+			"slow_rate":       chaos.SlowRate,
+		}).Warn("HIBP fault injection is enabled")
+		breachOptions = append(breachOptions, services.WithFaultInjection(services.FaultSettings{
+			Timeout:   chaos.TimeoutRate,
+			RateLimit: chaos.RateLimitRate,
+			Malformed: chaos.MalformedRate,
+			Slow:      chaos.SlowRate,
+			SlowDelay: time.Duration(chaos.SlowDelay) * time.Millisecond,
+		}))
+	}
+	breachService := services.NewBreachService(logger, breachOptions...)
+	policyService := services.NewPolicyService(logger,
+		services.WithDefaultPolicyMaxLength(cfg.Password.MaxLength),
+		services.WithPolicies(cfg.Policies()),
+		services.WithPolicyBreaches(breachService),
+		services.WithDeletedPolicyRetention(time.Duration(cfg.Admin.DeletedRetentionDays)*24*time.Hour),
+	)
+	if memo != nil {
+		// Remembered results never outlive the policies they were checked under
+		memo.PolicyChanged(policyService.Version())
+		policyService.OnChange(memo.PolicyChanged)
+	}
+
+	// Dictionaries, offline datasets, and banned word lists can be reloaded
+	// from disk through the admin API; the versions swapped in are closed
+	// with the slots holding them
+	datasetReloader := newDatasetReloader(ctx, logger, cfg, loadDataset, jobServices{
+		banned:    bannedListService,
+		breaches:  breachService,
+		passwords: passwordService,
+		verifier:  verifier,
+	})
+	offlineSHA1Slot, offlineNTLMSlot := breachService.OfflineDatasets()
+	for _, slot := range append(passwordService.Dictionaries(), offlineSHA1Slot, offlineNTLMSlot) {
+		if slot := slot; slot != nil {
+			h.closers = append(h.closers, func() { slot.Close() })
+		}
+	}
+
+	// Deep health checks; the HIBP probe is short and cached so frequent
+	// probes neither hit the upstream API nor wait on it
+	healthChecker := health.NewChecker(version.Version, health.WithDefaultTimeout(time.Duration(cfg.Health.TimeoutMS)*time.Millisecond))
+	healthChecker.Register("hibp", false, time.Duration(cfg.Health.HIBPCacheTTL)*time.Second, health.BreachAPICheck(breachService),
+		health.WithTimeout(time.Duration(cfg.Health.HIBPTimeoutMS)*time.Millisecond))
+	healthChecker.Register("cache", false, 0, health.CacheCheck(breachService))
+	healthChecker.Register("policies", true, 0, health.PolicyCheck(policyService))
+	healthChecker.Register("datasets", false, 0, health.DatasetCheck(bannedListService))
+	healthChecker.Register("dataset_integrity", false, 0, health.IntegrityCheck(verifier))
+	healthChecker.Register("config", true, 0, health.ConfigCheck(cfg))
+
+	// Initialize bearer token validation against the identity provider
+	var jwtValidator *auth.JWTValidator
+	if cfg.Auth.JWT.Enabled {
+		scopes := map[string]string{models.ScopeCheck: cfg.Auth.JWT.CheckScope}
+		if cfg.Auth.JWT.AdminScope != "" {
+			scopes[models.ScopeAdmin] = cfg.Auth.JWT.AdminScope
+		}
+		jwtValidator = auth.NewJWTValidator(
+			logger,
+			cfg.Auth.JWT.Issuer,
+			cfg.Auth.JWT.Audience,
+			auth.WithJWKSURL(cfg.Auth.JWT.JWKSURL),
+			auth.WithClockSkew(time.Duration(cfg.Auth.JWT.ClockSkew)*time.Second),
+			auth.WithJWKSRefreshInterval(time.Duration(cfg.Auth.JWT.JWKSRefreshInterval)*time.Minute),
+			auth.WithTenantClaim(cfg.Auth.JWT.TenantClaim),
+			auth.WithScopeMapping(scopes),
+		)
+	}
+
+	// Initialize the audit log
+	var auditLogger *audit.Logger
+	if cfg.Audit.Enabled {
+		var sink audit.Sink
+		var sinkErr error
+		if cfg.Audit.Sink == "syslog" {
+			sink, sinkErr = audit.NewSyslogSink(cfg.Audit.SyslogTag)
+		} else {
+			sink, sinkErr = audit.NewFileSink(cfg.Audit.Path)
+		}
+		if sinkErr != nil {
+			return nil, fmt.Errorf("failed to initialize audit log: %w", sinkErr)
+		}
+		auditLogger = audit.NewLogger(logger, sink,
+			audit.WithRetention(time.Duration(cfg.Audit.RetentionDays)*24*time.Hour))
+		auditLogger.StartRetention(ctx, time.Hour)
+		h.closers = append(h.closers, func() { auditLogger.Close() })
+	}
+
+	// Initialize HMAC request signature verification for partner systems
+	var hmacVerifier *auth.HMACVerifier
+	if cfg.Auth.HMAC.Enabled {
+		keys, err := auth.ParseHMACKeys(cfg.Auth.HMAC.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize HMAC request signing: %w", err)
+		}
+		hmacOptions := []auth.HMACVerifierOption{
+			auth.WithSignatureWindow(time.Duration(cfg.Auth.HMAC.Window) * time.Second),
+			auth.WithReplayCacheSize(cfg.Auth.HMAC.ReplayCacheSize),
+			auth.WithSignatureMetrics(recorder),
+		}
+		if cfg.Auth.HMAC.RequireNonce {
+			hmacOptions = append(hmacOptions, auth.WithRequiredNonce())
+		}
+		hmacVerifier = auth.NewHMACVerifier(keys, hmacOptions...)
+	}
+
+	// Initialize signing of strength attestation tokens
+	var attester *auth.Attester
+	if cfg.Attestation.Enabled {
+		key, err := auth.LoadAttestationKey(cfg.Attestation.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize attestation tokens: %w", err)
+		}
+		attester, err = auth.NewAttester(cfg.Attestation.Issuer, key,
+			auth.WithAttestationAudience(cfg.Attestation.Audience),
+			auth.WithAttestationTTL(time.Duration(cfg.Attestation.TTL)*time.Second),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize attestation tokens: %w", err)
+		}
+		logger.WithField("kid", attester.KeyID()).Info("Attestation tokens enabled")
+	}
+
+	// Initialize brute-force throttling of the password oracle endpoints
+	var oracleThrottle gin.HandlerFunc = func(c *gin.Context) { c.Next() }
+	var siteCaptcha *services.SiteVerifyCaptcha
+	if cfg.Throttle.Enabled {
+		var captcha services.CaptchaVerifier
+		if cfg.Throttle.CaptchaURL != "" {
+			siteCaptcha = services.NewSiteVerifyCaptcha(cfg.Throttle.CaptchaURL, cfg.Throttle.CaptchaSecret,
+				time.Duration(cfg.Throttle.CaptchaTimeout)*time.Second)
+			captcha = siteCaptcha
+		}
+		throttleService := services.NewThrottleService(
+			services.WithThrottleThreshold(cfg.Throttle.Threshold),
+			services.WithThrottleDelays(
+				time.Duration(cfg.Throttle.BaseDelay)*time.Millisecond,
+				time.Duration(cfg.Throttle.MaxDelay)*time.Millisecond),
+			services.WithThrottleMaxClients(cfg.Throttle.MaxClients),
+		)
+		oracleThrottle = handlers.BruteForceThrottleMiddleware(logger, throttleService, captcha)
+	}
+
+	// Initialize per-tenant usage tracking and monthly quotas
+	var usageService *services.UsageService
+	if cfg.Usage.Enabled {
+		tenantQuotas, _ := services.ParseTenantQuotas(cfg.Usage.TenantQuotas)
+		usageService = services.NewUsageService(
+			services.WithMonthlyQuota(cfg.Usage.MonthlyQuota),
+			services.WithTenantQuotas(tenantQuotas),
+		)
+	}
+
+	// Scheduled jobs: dataset refreshes, cache snapshots, hash re-checks, and usage reports
+	if cfg.Scheduler.Enabled {
+		restoreCacheSnapshots(logger, cfg, breachService)
+		jobs, err := newScheduler(logger, cfg, recorder, jobServices{
+			banned:    bannedListService,
+			breaches:  breachService,
+			passwords: passwordService,
+			usage:     usageService,
+			verifier:  verifier,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize scheduler: %w", err)
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			jobs.Run(ctx)
+		}()
+		h.closers = append(h.closers, func() { <-done })
+	}
+
+	// Set Gin mode
+	if cfg.Server.Env == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	} else {
+		gin.SetMode(gin.DebugMode)
+	}
+
+	// Panic notifications
+	var panicHooks []handlers.PanicHook
+	if cfg.Recovery.WebhookURL != "" {
+		panicHooks = append(panicHooks, handlers.NewPanicWebhookHook(
+			logger, cfg.Recovery.WebhookURL, time.Duration(cfg.Recovery.WebhookTimeout)*time.Second))
+	}
+
+	// Error reporting for panics and 5xx responses
+	var reporter errorreport.Reporter
+	environment := cfg.ErrorReporting.Environment
+	if environment == "" {
+		environment = cfg.Server.Env
+	}
+	reportTimeout := time.Duration(cfg.ErrorReporting.Timeout) * time.Second
+	switch cfg.ErrorReporting.Backend {
+	case errorreport.BackendSentry:
+		reporter, err = errorreport.NewSentryReporter(logger, cfg.ErrorReporting.DSN, environment, version.Version, reportTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize error reporting: %w", err)
+		}
+	case errorreport.BackendWebhook:
+		reporter = errorreport.NewWebhookReporter(logger, cfg.ErrorReporting.WebhookURL, reportTimeout)
+	}
+	if reporter != nil {
+		panicHooks = append(panicHooks, handlers.NewErrorReportPanicHook(reporter))
+	}
+
+	configSchema, err := config.Schema()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build configuration schema: %w", err)
+	}
+
+	// Rotate the admin token, HMAC keys, and CAPTCHA secret without a restart.
+	// Replaced admin tokens and HMAC keys stay valid for the grace period.
+	adminToken := secrets.NewValue(cfg.Admin.Token)
+	rotator := config.NewSecretRotator(logger, cfg, load)
+	rotator.OnRotate(func(next *config.Config) {
+		adminToken.Rotate(next.Admin.Token, rotator.Grace())
+		if hmacVerifier != nil {
+			keys, err := auth.ParseHMACKeys(next.Auth.HMAC.Keys)
+			if err != nil {
+				logger.WithError(err).Error("Failed to rotate HMAC keys; keeping the current ones")
+			} else {
+				hmacVerifier.SetKeys(keys, rotator.Grace())
+			}
+		}
+		if siteCaptcha != nil {
+			siteCaptcha.SetSecret(next.Throttle.CaptchaSecret)
+		}
+	})
+	if err := rotator.Watch(ctx); err != nil {
+		return nil, fmt.Errorf("failed to watch secrets: %w", err)
+	}
+
+	// Kubernetes validating admission webhook for Secrets, which validation
+	// only allows behind client certificates or on the internal listener
+	var admissionService *services.SecretAdmissionService
+	if cfg.Admission.Enabled {
+		if !cfg.TLS.Enabled {
+			logger.Warn("Admission webhook is served on the internal listener without TLS; the Kubernetes API server only calls HTTPS webhooks")
+		}
+		admissionService = services.NewSecretAdmissionService(logger, passwordService, breachService,
+			services.WithMinStrength(models.PasswordStrength(cfg.Admission.MinStrength)),
+			services.WithRejectBreached(cfg.Admission.RejectBreached),
+		)
+	}
+
+	// Password validation webhooks for identity providers
+	var policyEvaluator *services.PolicyEvaluator
+	if cfg.IdP.Enabled {
+		policyEvaluator = services.NewPolicyEvaluator(logger, passwordService, breachService,
+			services.WithPolicyMinStrength(models.PasswordStrength(cfg.IdP.MinStrength)),
+			services.WithPolicyRejectBreached(cfg.IdP.RejectBreached),
+			services.WithPolicyRequireGenerated(cfg.IdP.RequireGenerated),
+			services.WithPolicyBannedList(bannedListService),
+		)
+	}
+
+	// Verdicts for Active Directory password filters
+	var adFilterService *services.ADFilterService
+	if cfg.ADFilter.Enabled {
+		adFilterService = services.NewADFilterService(logger, breachService,
+			services.WithADBannedList(bannedListService),
+			services.WithADLatencyBudget(time.Duration(cfg.ADFilter.LatencyBudgetMS)*time.Millisecond),
+			services.WithADFailOpen(cfg.ADFilter.FailOpen),
+			services.WithADMaxLength(cfg.Password.MaxLength),
+			services.WithADRejectBreached(cfg.ADFilter.RejectBreached),
+			services.WithADMaxBannedVariants(cfg.ADFilter.MaxBannedVariants),
+		)
+	}
+
+	// Asynchronous password checks read from a message queue
+	if cfg.Queue.Enabled {
+		if !queue.HasDriver(cfg.Queue.Driver) {
+			return nil, fmt.Errorf("unknown queue driver %q (available: %v)", cfg.Queue.Driver, queue.Drivers())
+		}
+		checks := services.NewQueueCheckService(logger, passwordService, breachService)
+		consumer := queue.NewConsumer(logger,
+			func() (queue.Broker, error) {
+				return queue.Open(cfg.Queue.Driver, cfg.Queue.URL, queue.Options{Token: cfg.Queue.Token, Name: "config-service"})
+			},
+			checks.Handle,
+			queue.WithTopics(cfg.Queue.RequestTopic, cfg.Queue.ReplyTopic),
+			queue.WithGroup(cfg.Queue.Group),
+			queue.WithWorkers(cfg.Queue.Workers),
+		)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			consumer.Run(ctx)
+		}()
+		h.closers = append(h.closers, func() { <-done })
+	}
+
+	// Aggregate analytics of password check outcomes
+	var analyticsService *services.AnalyticsService
+	if cfg.Analytics.Enabled {
+		analyticsService = services.NewAnalyticsService(
+			services.WithAnalyticsRetention(cfg.Analytics.RetentionDays),
+			services.WithAnalyticsMinChecks(int64(cfg.Analytics.MinChecks)),
+		)
+	}
+
+	// Password reuse detection across the accounts of each tenant
+	var reuseService *services.ReuseService
+	if cfg.Reuse.Enabled {
+		reuseService = services.NewReuseService(services.WithReuseMaxAccounts(cfg.Reuse.MaxAccounts))
+	}
+
+	// Client IPs are only taken from headers set by trusted proxies
+	clientIP, err := clientip.New(cfg.Server.TrustedProxies, cfg.Server.ClientIPHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure client IP extraction: %w", err)
+	}
+
+	// Mount the middleware and routes
+	routes := handlers.Options{
+		Logger: logger,
+		AccessLog: []handlers.AccessLogOption{
+			handlers.WithAccessLogger(accessLogger),
+			handlers.WithAccessLogFields(cfg.Logging.AccessFields...),
+			handlers.WithAccessLogSampling(cfg.Logging.AccessSampleSuccess, cfg.Logging.AccessSampleClient, cfg.Logging.AccessSampleServer),
+		},
+		SlowRequestThreshold: time.Duration(cfg.Logging.SlowRequestMS) * time.Millisecond,
+		Metrics:              recorder,
+		PanicHooks:           panicHooks,
+		ErrorReporter:        reporter,
+		MaxInFlight:          cfg.Server.MaxInFlight,
+		BreachMaxInFlight:    cfg.Breach.MaxInFlight,
+		BulkMaxBodyBytes:     cfg.Bulk.MaxBodyBytes,
+		ShedRetryAfter:       time.Duration(cfg.Server.ShedRetryAfter) * time.Second,
+		ClientIP:             clientIP,
+		DefaultLocale:        cfg.I18n.DefaultLocale,
+		StrictSchema:         cfg.Server.StrictSchema,
+		ValidateResponses:    cfg.Server.StrictSchema && cfg.Server.Env != "production",
+
+		PasswordService:   passwordService,
+		BreachService:     breachService,
+		GeneratorService:  generatorService,
+		BannedListService: bannedListService,
+		BannedImports:     services.NewBannedImportService(logger, bannedListService, services.WithImportDir(cfg.Admin.BannedImportDir)),
+		PolicyService:     policyService,
+		UsageService:      usageService,
+		AdmissionService:  admissionService,
+		PolicyEvaluator:   policyEvaluator,
+		ADFilterService:   adFilterService,
+		HealthChecker:     healthChecker,
+		AuditLogger:       auditLogger,
+		WebhookService:    webhookService,
+		Events:            events,
+		ReuseService:      reuseService,
+		AnalyticsService:  analyticsService,
+		FeedbackService:   services.NewFeedbackOverrideService(logger),
+		Features: map[string]bool{
+			"breach_detection": cfg.Breach.Enabled,
+			"admin_api":        cfg.Admin.Token != "",
+			"api_keys":         cfg.Auth.APIKeysEnabled,
+			"jwt_auth":         cfg.Auth.JWT.Enabled,
+			"hmac_signing":     cfg.Auth.HMAC.Enabled,
+			"audit_log":        cfg.Audit.Enabled,
+			"queue_consumer":   cfg.Queue.Enabled,
+			"scheduler":        cfg.Scheduler.Enabled,
+			"webhooks":         cfg.Webhooks.Enabled,
+			"event_stream":     cfg.Events.Enabled,
+			"attestation":      cfg.Attestation.Enabled,
+			"password_reuse":   cfg.Reuse.Enabled,
+			"grpc":             cfg.GRPC.Enabled,
+		},
+
+		APIKeyService:    apiKeyService,
+		APIKeysEnabled:   cfg.Auth.APIKeysEnabled,
+		JWTValidator:     jwtValidator,
+		HMACVerifier:     hmacVerifier,
+		Attester:         attester,
+		HMACMaxBodyBytes: cfg.Auth.HMAC.MaxBodyBytes,
+		OracleThrottle:   oracleThrottle,
+
+		AdminToken:       adminToken,
+		Config:           cfg,
+		ConfigSchema:     configSchema,
+		SecretRotator:    rotator,
+		LogController:    logController,
+		DatasetReloader:  datasetReloader,
+		IdempotencyStore: services.NewIdempotencyStore(services.WithIdempotencyTTL(time.Duration(cfg.Admin.IdempotencyTTL) * time.Second)),
+		ImportMaxBytes:   cfg.Admin.BannedImportMaxBytes,
+
+		SeparateAdmin: cfg.Server.AdminPort != 0,
+		Pprof:         cfg.Server.Pprof,
+	}
+	if cfg.Logging.RequestBodies {
+		routes.RequestBodyMaxBytes = cfg.Logging.RequestBodyMaxBytes
+	}
+	if prometheus != nil {
+		routes.MetricsPath = cfg.Metrics.PrometheusPath
+		routes.MetricsHandler = prometheus.Handler()
+	}
+
+	// Create router; recovery and request logging are provided by our own middleware
+	r := gin.New()
+	handlers.Register(&r.RouterGroup, routes)
+	h.Handler = r
+
+	// Internal endpoints on their own router, to be kept off the internet
+	if routes.SeparateAdmin {
+		admin := gin.New()
+		handlers.RegisterAdmin(&admin.RouterGroup, routes)
+		h.Admin = admin
+	}
+
+	// The gRPC API is backed by the same services as the routes
+	if cfg.GRPC.Enabled {
+		h.GRPC = grpcapi.NewServer(grpcapi.Options{
+			Logger:           logger,
+			Metrics:          recorder,
+			PasswordService:  passwordService,
+			BreachService:    breachService,
+			GeneratorService: generatorService,
+			PolicyService:    policyService,
+			APIKeyService:    apiKeyService,
+			APIKeysEnabled:   cfg.Auth.APIKeysEnabled,
+			JWTValidator:     jwtValidator,
+		}, grpc.MaxRecvMsgSize(cfg.GRPC.MaxMessageBytes))
+		if cfg.GRPC.Reflection {
+			reflection.Register(h.GRPC)
+		}
+		h.closers = append(h.closers, h.GRPC.Stop)
+	}
+	return h, nil
+}
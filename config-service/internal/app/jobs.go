@@ -0,0 +1,305 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/scheduler"
+	"config-service/internal/services"
+)
+
+// calibrationExamples is the number of misclassified passwords of each label
+// listed in a scheduled calibration report
+const calibrationExamples = 20
+
+// hashRecheckBatch is the number of stored hashes checked per batch, between
+// which a re-check can be cancelled
+const hashRecheckBatch = 1000
+
+// jobServices are the services the scheduled jobs work on
+type jobServices struct {
+	banned    *services.BannedListService
+	breaches  *services.BreachService
+	passwords *services.PasswordService
+	usage     *services.UsageService
+	verifier  *dataset.Verifier
+}
+
+// newScheduler builds a scheduler running the configured jobs
+func newScheduler(logger *logrus.Logger, cfg *config.Config, recorder metrics.Recorder, deps jobServices) (*scheduler.Scheduler, error) {
+	s := scheduler.New(logger, scheduler.WithMetrics(recorder))
+	for _, job := range cfg.Scheduler.Jobs {
+		schedule, err := scheduler.Parse(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled job %s: %w", job.Name, err)
+		}
+		run, err := scheduledJob(logger, job, deps)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled job %s: %w", job.Name, err)
+		}
+		err = s.Add(scheduler.Job{
+			Name:       job.Name,
+			Schedule:   schedule,
+			Jitter:     time.Duration(job.Jitter) * time.Second,
+			RunOnStart: job.RunOnStart,
+			Run:        run,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// scheduledJob returns the work of a configured job
+func scheduledJob(logger *logrus.Logger, job config.ScheduledJob, deps jobServices) (scheduler.JobFunc, error) {
+	switch job.Type {
+	case scheduler.JobDatasetRefresh:
+		return func(ctx context.Context) error {
+			return refreshBannedWords(deps.banned, deps.verifier, job.Path)
+		}, nil
+	case scheduler.JobCacheSnapshot:
+		return func(ctx context.Context) error {
+			return writeFileAtomic(job.Path, func(w io.Writer) error {
+				entries, err := deps.breaches.SaveCache(w)
+				logger.WithField("count", entries).Debug("Saved breach cache snapshot")
+				return err
+			})
+		}, nil
+	case scheduler.JobHashRecheck:
+		return func(ctx context.Context) error {
+			return recheckStoredHashes(ctx, deps.breaches, job.Path, job.Output)
+		}, nil
+	case scheduler.JobUsageReport:
+		if deps.usage == nil {
+			return nil, errors.New("usage reports require usage.enabled")
+		}
+		return func(ctx context.Context) error {
+			return writeUsageReport(deps.usage, job.Output, time.Now().UTC())
+		}, nil
+	case scheduler.JobCalibrationReport:
+		return func(ctx context.Context) error {
+			return writeCalibrationReport(ctx, deps.passwords, job.Path, job.Output)
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid type %q", job.Type)
+	}
+}
+
+// restoreCacheSnapshots loads the snapshots written by cache_snapshot jobs
+// into the breach cache; a missing snapshot is not an error
+func restoreCacheSnapshots(logger *logrus.Logger, cfg *config.Config, breaches *services.BreachService) {
+	for _, job := range cfg.Scheduler.Jobs {
+		if job.Type != scheduler.JobCacheSnapshot {
+			continue
+		}
+		file, err := os.Open(job.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).Warn("Failed to open breach cache snapshot")
+			continue
+		}
+		loaded, err := breaches.LoadCache(file)
+		file.Close()
+		if err != nil {
+			logger.WithError(err).Warn("Failed to load breach cache snapshot")
+			continue
+		}
+		logger.WithField("count", loaded).Info("Loaded breach cache snapshot")
+	}
+}
+
+// refreshBannedWords syncs the banned words loaded from a word list file, one
+// word per line with # starting a comment. A file failing verification is
+// refused and the words loaded before stay in use.
+func refreshBannedWords(banned *services.BannedListService, verifier *dataset.Verifier, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to open word list: %w", err)
+	}
+	if verifier != nil {
+		if err := verifier.VerifyData(path, data); err != nil {
+			return err
+		}
+	}
+
+	var words []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read word list: %w", err)
+	}
+	banned.Sync(path, words)
+	return nil
+}
+
+// storedHash is an entry of a stored hash file
+type storedHash struct {
+	id   string
+	hash string
+}
+
+// recheckStoredHashes checks a file of stored password hashes against known
+// breaches and writes a report. Each line holds a SHA-1 or NTLM hash in hex,
+// optionally preceded by an ID and a colon; entries without an ID are
+// identified by their line number.
+func recheckStoredHashes(ctx context.Context, breaches *services.BreachService, path, output string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open hash file: %w", err)
+	}
+	defer file.Close()
+
+	var sha1Hashes, ntlmHashes []storedHash
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		entry := storedHash{id: "line " + strconv.Itoa(line), hash: text}
+		if i := strings.LastIndex(text, ":"); i >= 0 {
+			entry.id, entry.hash = text[:i], text[i+1:]
+		}
+		if len(entry.hash) == 32 {
+			ntlmHashes = append(ntlmHashes, entry)
+		} else {
+			sha1Hashes = append(sha1Hashes, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read hash file: %w", err)
+	}
+
+	report := models.HashRecheckReport{CheckedAt: time.Now().UTC(), Breaches: []models.HashRecheckResult{}}
+	check := func(entries []storedHash, checkHashes func(context.Context, []string) []models.BreachAuditResult) error {
+		for start := 0; start < len(entries); start += hashRecheckBatch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := start + hashRecheckBatch
+			if end > len(entries) {
+				end = len(entries)
+			}
+			batch := entries[start:end]
+			hashes := make([]string, len(batch))
+			for i, entry := range batch {
+				hashes[i] = entry.hash
+			}
+			for i, result := range checkHashes(ctx, hashes) {
+				report.Checked++
+				switch {
+				case result.Error != "":
+					report.Failed++
+					report.Errors = append(report.Errors, models.HashRecheckResult{ID: batch[i].id, Error: result.Error})
+				case result.Found:
+					report.Breached++
+					report.Breaches = append(report.Breaches, models.HashRecheckResult{ID: batch[i].id, BreachCount: result.BreachCount})
+				}
+			}
+		}
+		return nil
+	}
+	if err := check(sha1Hashes, breaches.CheckHashesBreach); err != nil {
+		return err
+	}
+	if err := check(ntlmHashes, breaches.CheckNTLMHashesBreach); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(output, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+}
+
+// writeUsageReport writes the usage report of the month before now to
+// usage-YYYY-MM.json in dir
+func writeUsageReport(usage *services.UsageService, dir string, now time.Time) error {
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+	report, err := usage.Report(period)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(dir, "usage-"+period+".json"), func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	})
+}
+
+// writeCalibrationReport scores the labeled corpus at path and writes the
+// calibration report to output
+func writeCalibrationReport(ctx context.Context, passwords *services.PasswordService, path, output string) error {
+	samples, err := services.LoadCalibrationCorpus(path)
+	if err != nil {
+		return err
+	}
+	report, err := passwords.Calibrate(ctx, samples, calibrationExamples)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(output, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(report)
+	})
+}
+
+// writeFileAtomic writes a file readable only by its owner through a
+// temporary file, so readers never see a partial file
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(0o600); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := write(temp); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,16 @@
+//go:build go1.19
+
+package app
+
+import "runtime/debug"
+
+// setMemoryLimit sets the soft memory limit of the runtime
+func setMemoryLimit(limit int64) error {
+	debug.SetMemoryLimit(limit)
+	return nil
+}
+
+// memoryLimit returns the soft memory limit of the runtime
+func memoryLimit() int64 {
+	return debug.SetMemoryLimit(-1)
+}
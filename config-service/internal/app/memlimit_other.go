@@ -0,0 +1,18 @@
+//go:build !go1.19
+
+package app
+
+import (
+	"errors"
+	"math"
+)
+
+// setMemoryLimit fails, as soft memory limits need Go 1.19 or later
+func setMemoryLimit(limit int64) error {
+	return errors.New("runtime.memory_limit_mb requires a build with Go 1.19 or later")
+}
+
+// memoryLimit reports that there is no memory limit
+func memoryLimit() int64 {
+	return math.MaxInt64
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"time"
+
+	"config-service/internal/config"
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+)
+
+// NewRulePlugins creates the external rule plugins listed in plugins.rules
+func NewRulePlugins(cfg *config.Config, recorder metrics.Recorder) []services.RulePlugin {
+	var plugins []services.RulePlugin
+	for _, plugin := range cfg.Plugins.Rules {
+		plugins = append(plugins, services.NewHTTPRulePlugin(plugin.Name, plugin.URL,
+			services.WithPluginToken(plugin.Token),
+			services.WithPluginTimeout(time.Duration(plugin.Timeout)*time.Millisecond),
+			services.WithPluginFailClosed(plugin.FailClosed),
+			services.WithPluginMetrics(recorder),
+		))
+	}
+	return plugins
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+	"config-service/internal/scheduler"
+	"config-service/internal/services"
+)
+
+// Names of the datasets reloadable through the admin API
+const (
+	reloadDictionaries = "dictionaries"
+	reloadBreachSHA1   = "breach_sha1"
+	reloadBreachNTLM   = "breach_ntlm"
+	reloadBannedWords  = "banned_words"
+)
+
+// newDatasetReloader makes the configured datasets reloadable from their
+// paths: the common password dictionaries, the offline breach datasets, and
+// the banned word lists of dataset_refresh jobs. When the scoring self-test
+// is enabled, a reload drifting from its expectations is rolled back, as it
+// would have failed startup.
+func newDatasetReloader(ctx context.Context, logger *logrus.Logger, cfg *config.Config, open func(string, ...dataset.Option) (*dataset.File, error), deps jobServices) *services.DatasetReloader {
+	var options []services.DatasetReloaderOption
+	if cfg.Password.SelfTest {
+		options = append(options, services.WithReloadCheck(func(context.Context) error {
+			return runSelfTest(ctx, logger, cfg, deps.passwords)
+		}))
+	}
+	reloader := services.NewDatasetReloader(logger, options...)
+
+	if dictionaries := deps.passwords.Dictionaries(); len(dictionaries) > 0 {
+		reloader.Register(reloadDictionaries, services.SlotDataset(func(path string) (*dataset.File, error) {
+			return open(path)
+		}, dictionaries...))
+	}
+	openOffline := func(path string) (*dataset.File, error) {
+		return open(path, dataset.WithSeparator(':'))
+	}
+	offlineSHA1, offlineNTLM := deps.breaches.OfflineDatasets()
+	if offlineSHA1 != nil {
+		reloader.Register(reloadBreachSHA1, services.SlotDataset(openOffline, offlineSHA1))
+	}
+	if offlineNTLM != nil {
+		reloader.Register(reloadBreachNTLM, services.SlotDataset(openOffline, offlineNTLM))
+	}
+
+	var bannedPaths []string
+	seen := make(map[string]bool)
+	for _, job := range cfg.Scheduler.Jobs {
+		if job.Type == scheduler.JobDatasetRefresh && !seen[job.Path] {
+			seen[job.Path] = true
+			bannedPaths = append(bannedPaths, job.Path)
+		}
+	}
+	if len(bannedPaths) > 0 {
+		reloader.Register(reloadBannedWords, bannedWordsDataset(deps.banned, deps.verifier, bannedPaths))
+	}
+	return reloader
+}
+
+// bannedWordsDataset reloads banned word lists. Each list is switched to at
+// once, and rolling back syncs every list to the words it had before.
+func bannedWordsDataset(banned *services.BannedListService, verifier *dataset.Verifier, paths []string) services.ReloadableDataset {
+	return func() (func(), func(), error) {
+		previous := make(map[string][]string, len(paths))
+		rollback := func() {
+			for path, words := range previous {
+				banned.Sync(path, words)
+			}
+		}
+		for _, path := range paths {
+			previous[path] = banned.SourceWords(path)
+			if err := refreshBannedWords(banned, verifier, path); err != nil {
+				rollback()
+				return nil, nil, err
+			}
+		}
+		return func() {}, rollback, nil
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+	"config-service/internal/reproducible"
+)
+
+// RuntimeSettings are the effective runtime settings of the process
+type RuntimeSettings struct {
+	// MemoryLimit is the soft memory limit in bytes, math.MaxInt64 if none
+	MemoryLimit int64
+	// GCPercent is the heap growth that triggers a collection, negative if off
+	GCPercent int
+	// MaxMappedDatasets is how many datasets may be mapped at once, 0 if unlimited
+	MaxMappedDatasets int
+	// Deterministic reports whether time and randomness are reproducible
+	Deterministic bool
+}
+
+// TuneRuntime applies the runtime section of the configuration and logs the
+// effective settings, which include GOMEMLIMIT and GOGC when the configuration
+// leaves them alone. The settings are process-wide, so programs embedding the
+// handler call this only if the service should manage them.
+func TuneRuntime(logger *logrus.Logger, cfg *config.Config) (RuntimeSettings, error) {
+	if cfg.Runtime.MemoryLimitMB > 0 {
+		if err := setMemoryLimit(int64(cfg.Runtime.MemoryLimitMB) << 20); err != nil {
+			return RuntimeSettings{}, err
+		}
+	}
+
+	settings := RuntimeSettings{
+		MemoryLimit:       memoryLimit(),
+		GCPercent:         gcPercent(),
+		MaxMappedDatasets: cfg.Runtime.MaxMappedDatasets,
+	}
+	if cfg.Runtime.GCPercent != 0 {
+		if cfg.Runtime.GCPercent < 0 && settings.MemoryLimit == math.MaxInt64 {
+			return settings, fmt.Errorf("runtime.gc_percent of -1 requires a memory limit, or the heap grows without bound")
+		}
+		debug.SetGCPercent(cfg.Runtime.GCPercent)
+		settings.GCPercent = cfg.Runtime.GCPercent
+	}
+	dataset.SetMaxOpen(settings.MaxMappedDatasets)
+	if cfg.Runtime.Deterministic {
+		reproducible.UseDeterministic(cfg.Runtime.Seed)
+	}
+	settings.Deterministic = reproducible.Deterministic()
+
+	fields := logrus.Fields{
+		"memory_limit":        "unlimited",
+		"gc_percent":          "off",
+		"max_mapped_datasets": "unlimited",
+		"gomaxprocs":          runtime.GOMAXPROCS(0),
+		"deterministic":       settings.Deterministic,
+	}
+	if settings.MemoryLimit != math.MaxInt64 {
+		fields["memory_limit"] = fmt.Sprintf("%dMiB", settings.MemoryLimit>>20)
+	}
+	if settings.GCPercent >= 0 {
+		fields["gc_percent"] = settings.GCPercent
+	}
+	if settings.MaxMappedDatasets > 0 {
+		fields["max_mapped_datasets"] = settings.MaxMappedDatasets
+	}
+	logger.WithFields(fields).Info("Runtime settings")
+	if settings.Deterministic {
+		logger.Warn("Deterministic mode is enabled: request IDs and generated passwords are predictable")
+	}
+	return settings, nil
+}
+
+// gcPercent returns the current GC percent, which the runtime only reports
+// when it is changed
+func gcPercent() int {
+	percent := debug.SetGCPercent(100)
+	debug.SetGCPercent(percent)
+	return percent
+}
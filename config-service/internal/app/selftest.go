@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/config"
+	"config-service/internal/services"
+)
+
+// runSelfTest scores the pinned reference passwords and logs each whose
+// strength drifted, which points at a mistake in weights, penalties, or the
+// datasets in use. A drift fails startup unless the configuration only asks
+// for it to be logged.
+func runSelfTest(ctx context.Context, logger *logrus.Logger, cfg *config.Config, passwords *services.PasswordService) error {
+	expectations, err := services.LoadSelfTestExpectations(cfg.Password.SelfTestExpectations)
+	if err != nil {
+		return err
+	}
+
+	drifts := passwords.SelfTest(ctx, expectations)
+	for _, drift := range drifts {
+		logger.WithFields(logrus.Fields{
+			"reference": drift.Password,
+			"expected":  drift.Expected,
+			"actual":    drift.Actual,
+			"score":     drift.Score,
+		}).Error("Scoring self-test: strength drifted from its expectation")
+	}
+	if len(drifts) == 0 {
+		logger.WithField("references", len(expectations)).Info("Scoring self-test passed")
+		return nil
+	}
+	if cfg.Password.SelfTestFailOnDrift {
+		return fmt.Errorf("scoring self-test failed: %d of %d reference passwords drifted", len(drifts), len(expectations))
+	}
+	logger.Errorf("Scoring self-test failed: %d of %d reference passwords drifted; serving anyway", len(drifts), len(expectations))
+	return nil
+}
@@ -0,0 +1,209 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Result classifies the outcome of an audited operation
+type Result string
+
+const (
+	// ResultPass means the password passed every check
+	ResultPass Result = "pass"
+	// ResultWeak means the password was rated weak
+	ResultWeak Result = "weak"
+	// ResultBreached means the password or hash appeared in a breach
+	ResultBreached Result = "breached"
+	// ResultReused means the password is shared with other accounts
+	ResultReused Result = "reused"
+	// ResultSuccess means a non-password operation completed
+	ResultSuccess Result = "success"
+	// ResultRejected means the request was rejected as invalid
+	ResultRejected Result = "rejected"
+	// ResultDenied means the caller was not authenticated or authorized
+	ResultDenied Result = "denied"
+	// ResultError means the operation failed on the server side
+	ResultError Result = "error"
+)
+
+// Event is a single audit record. It identifies who performed which operation
+// and how it ended, and deliberately has no field that could hold a password,
+// hash, or request body.
+type Event struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Actor     string    `json:"actor"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Operation string    `json:"operation"`
+	Target    string    `json:"target,omitempty"` // what an admin operation acted on, such as a deleted word
+	Status    int       `json:"status"`
+	Result    Result    `json:"result"`
+}
+
+// Filter selects audit events for export
+type Filter struct {
+	Since  time.Time
+	Until  time.Time
+	Tenant string
+}
+
+// Matches reports whether the event falls within the filter
+func (f Filter) Matches(event Event) bool {
+	if !f.Since.IsZero() && event.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !event.Time.Before(f.Until) {
+		return false
+	}
+	if f.Tenant != "" && event.Tenant != f.Tenant {
+		return false
+	}
+	return true
+}
+
+// Sink persists audit events
+type Sink interface {
+	Write(event Event) error
+	Close() error
+}
+
+// Reader is implemented by sinks whose events can be read back for export
+type Reader interface {
+	Events(filter Filter) ([]Event, error)
+}
+
+// Pruner is implemented by sinks that can enforce a retention period
+type Pruner interface {
+	Prune(before time.Time) (int, error)
+}
+
+// ErrExportUnsupported is returned when the configured sink cannot be read back
+var ErrExportUnsupported = errors.New("audit sink does not support export")
+
+// Export formats
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// Logger records audit events to a sink
+type Logger struct {
+	logger    *logrus.Logger
+	sink      Sink
+	retention time.Duration
+}
+
+// LoggerOption defines a function type for configuring the audit logger
+type LoggerOption func(*Logger)
+
+// WithRetention sets how long audit events are kept; zero keeps them forever
+func WithRetention(retention time.Duration) LoggerOption {
+	return func(l *Logger) {
+		l.retention = retention
+	}
+}
+
+// NewLogger creates a new audit logger writing to the sink
+func NewLogger(logger *logrus.Logger, sink Sink, options ...LoggerOption) *Logger {
+	l := &Logger{
+		logger: logger,
+		sink:   sink,
+	}
+
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+// Record writes an audit event. Failures are logged rather than returned so
+// that auditing never changes the outcome of the audited request.
+func (l *Logger) Record(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	if err := l.sink.Write(event); err != nil {
+		l.logger.WithFields(logrus.Fields{
+			"request_id":  event.RequestID,
+			"error_class": "audit_write",
+		}).WithError(err).Error("Failed to write audit event")
+	}
+}
+
+// Export writes the events matching the filter in the given format
+func (l *Logger) Export(w io.Writer, format string, filter Filter) error {
+	reader, ok := l.sink.(Reader)
+	if !ok {
+		return ErrExportUnsupported
+	}
+
+	events, err := reader.Events(filter)
+	if err != nil {
+		return fmt.Errorf("failed to read audit events: %w", err)
+	}
+
+	switch format {
+	case FormatJSONL, "":
+		return writeJSONL(w, events)
+	case FormatCSV:
+		return writeCSV(w, events)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// Prune removes events older than the retention period
+func (l *Logger) Prune() (int, error) {
+	pruner, ok := l.sink.(Pruner)
+	if !ok || l.retention <= 0 {
+		return 0, nil
+	}
+	return pruner.Prune(time.Now().Add(-l.retention))
+}
+
+// StartRetention prunes expired events every interval until the context is done
+func (l *Logger) StartRetention(ctx context.Context, interval time.Duration) {
+	if l.retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			l.prune()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// prune runs a retention pass and logs its outcome
+func (l *Logger) prune() {
+	removed, err := l.Prune()
+	if err != nil {
+		l.logger.WithError(err).Error("Failed to prune audit events")
+		return
+	}
+	if removed > 0 {
+		l.logger.WithField("count", removed).Info("Pruned expired audit events")
+	}
+}
+
+// Close closes the underlying sink
+func (l *Logger) Close() error {
+	return l.sink.Close()
+}
@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader lists the CSV export columns; new columns go last so that
+// consumers reading them by position keep working
+var csvHeader = []string{"time", "request_id", "actor", "tenant", "operation", "status", "result", "target"}
+
+// writeJSONL writes events as newline-delimited JSON
+func writeJSONL(w io.Writer, events []Event) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes events as CSV with a header row
+func writeCSV(w io.Writer, events []Event) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		record := []string{
+			event.Time.UTC().Format(time.RFC3339Nano),
+			event.RequestID,
+			event.Actor,
+			event.Tenant,
+			event.Operation,
+			strconv.Itoa(event.Status),
+			string(event.Result),
+			event.Target,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileSink appends audit events to a file as newline-delimited JSON
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) the audit file at path
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := openAuditFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+// openAuditFile opens the audit file for appending, readable only by the owner
+func openAuditFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return file, nil
+}
+
+// Write appends an event to the file
+func (s *FileSink) Write(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(line)
+	return err
+}
+
+// Events returns the events in the file that match the filter
+func (s *FileSink) Events(filter Filter) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	err := s.scan(func(event Event, _ []byte) {
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	})
+	return events, err
+}
+
+// Prune rewrites the file without events older than before
+func (s *FileSink) Prune(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".audit-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary audit file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	removed := 0
+	writer := bufio.NewWriter(tmp)
+	err = s.scan(func(event Event, line []byte) {
+		if event.Time.Before(before) {
+			removed++
+			return
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	})
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || removed == 0 {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return 0, fmt.Errorf("failed to replace audit file: %w", err)
+	}
+
+	// Reopen so that new events go to the pruned file
+	s.file.Close()
+	file, err := openAuditFile(s.path)
+	if err != nil {
+		return removed, err
+	}
+	s.file = file
+
+	return removed, nil
+}
+
+// scan calls fn for every well-formed event in the file
+func (s *FileSink) scan(fn func(event Event, line []byte)) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		fn(event, scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
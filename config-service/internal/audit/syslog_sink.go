@@ -0,0 +1,37 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogSink sends audit events to the local syslog daemon as JSON messages
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the local syslog daemon using the auth facility
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+// Write sends an event to syslog
+func (s *SyslogSink) Write(event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.writer.Info(string(message))
+}
+
+// Close closes the syslog connection
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
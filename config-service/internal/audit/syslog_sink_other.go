@@ -0,0 +1,23 @@
+//go:build windows || plan9
+
+package audit
+
+import "errors"
+
+// SyslogSink is unavailable on this platform
+type SyslogSink struct{}
+
+// NewSyslogSink reports that syslog is not supported on this platform
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	return nil, errors.New("syslog audit sink is not supported on this platform")
+}
+
+// Write is never called because NewSyslogSink always fails
+func (s *SyslogSink) Write(event Event) error {
+	return nil
+}
+
+// Close is never called because NewSyslogSink always fails
+func (s *SyslogSink) Close() error {
+	return nil
+}
@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"config-service/internal/reproducible"
+)
+
+// defaultAttestationTTL is how long attestation tokens are valid by default
+const defaultAttestationTTL = 5 * time.Minute
+
+// attestationType is the typ header of attestation tokens, which keeps them
+// from being mistaken for access tokens
+const attestationType = "password-attestation+jwt"
+
+// Attestation is what an attestation token states about a password. Subject
+// is the account or nonce the caller checked the password for, which binds
+// the token to it.
+type Attestation struct {
+	ID        string
+	Subject   string
+	Score     int
+	Strength  string
+	Policy    string
+	Breached  *bool // nil when the breach check was skipped or failed
+	Tenant    string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// attestationClaims are the claims of an attestation token
+type attestationClaims struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud,omitempty"`
+	Subject   string `json:"sub"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	Score     int    `json:"score"`
+	Strength  string `json:"strength"`
+	Policy    string `json:"policy"`
+	Breached  *bool  `json:"breached,omitempty"`
+	Tenant    string `json:"tenant,omitempty"`
+}
+
+// Attester issues signed tokens attesting how a password scored, which other
+// services verify offline with the published key instead of checking the
+// password again
+type Attester struct {
+	issuer   string
+	audience string
+	ttl      time.Duration
+	key      crypto.Signer
+	alg      string
+	kid      string
+	jwk      jsonWebKey
+	now      func() time.Time
+}
+
+// AttesterOption defines functional options for configuring the Attester
+type AttesterOption func(*Attester)
+
+// WithAttestationAudience sets the aud claim, naming the services meant to
+// accept the tokens
+func WithAttestationAudience(audience string) AttesterOption {
+	return func(a *Attester) {
+		a.audience = audience
+	}
+}
+
+// WithAttestationTTL sets how long tokens are valid
+func WithAttestationTTL(ttl time.Duration) AttesterOption {
+	return func(a *Attester) {
+		if ttl > 0 {
+			a.ttl = ttl
+		}
+	}
+}
+
+// WithAttestationClock sets the clock tokens are issued and verified by
+func WithAttestationClock(now func() time.Time) AttesterOption {
+	return func(a *Attester) {
+		a.now = now
+	}
+}
+
+// NewAttester creates an attester signing with an ECDSA P-256 or P-384 key
+// (ES256, ES384) or an RSA key (RS256)
+func NewAttester(issuer string, key crypto.Signer, options ...AttesterOption) (*Attester, error) {
+	a := &Attester{
+		issuer: issuer,
+		ttl:    defaultAttestationTTL,
+		key:    key,
+		now:    reproducible.Now,
+	}
+
+	switch publicKey := key.Public().(type) {
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		a.jwk = jsonWebKey{
+			Kty: "EC",
+			Crv: publicKey.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size))),
+		}
+		switch publicKey.Curve {
+		case elliptic.P256():
+			a.alg = "ES256"
+		case elliptic.P384():
+			a.alg = "ES384"
+		default:
+			return nil, fmt.Errorf("unsupported attestation key curve: %s", a.jwk.Crv)
+		}
+	case *rsa.PublicKey:
+		if publicKey.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("attestation RSA key must have at least 2048 bits")
+		}
+		a.alg = "RS256"
+		a.jwk = jsonWebKey{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported attestation key type %T", publicKey)
+	}
+
+	a.kid = thumbprint(a.jwk)
+	a.jwk.Kid, a.jwk.Use, a.jwk.Alg = a.kid, "sig", a.alg
+
+	for _, option := range options {
+		option(a)
+	}
+	return a, nil
+}
+
+// LoadAttestationKey reads a PEM-encoded PKCS #8, SEC 1 (EC), or PKCS #1 (RSA)
+// private key
+func LoadAttestationKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("attestation key %s is not PEM-encoded", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse attestation key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("attestation key %s cannot sign", path)
+	}
+	return signer, nil
+}
+
+// KeyID returns the key ID tokens are signed with, the RFC 7638 thumbprint of
+// the public key
+func (a *Attester) KeyID() string {
+	return a.kid
+}
+
+// JWKS returns the JSON Web Key Set publishing the public key
+func (a *Attester) JWKS() interface{} {
+	return jwksDocument{Keys: []jsonWebKey{a.jwk}}
+}
+
+// Attest issues a token stating the attestation's score, strength, policy,
+// breach status, and tenant for its subject, which is required: without it a
+// token for one password could be presented for any other. The ID and times
+// are set by the attester. The token says nothing about the password itself:
+// it is signed, not encrypted, and any digest of the password in it could be
+// guessed offline by whoever sees the token.
+func (a *Attester) Attest(attestation Attestation) (string, *Attestation, error) {
+	if attestation.Subject == "" {
+		return "", nil, fmt.Errorf("attestation subject is required")
+	}
+
+	var id [16]byte
+	reproducible.Read(id[:])
+	attestation.ID = hex.EncodeToString(id[:])
+	attestation.IssuedAt = a.now().Truncate(time.Second)
+	attestation.ExpiresAt = attestation.IssuedAt.Add(a.ttl)
+
+	claims := attestationClaims{
+		Issuer:    a.issuer,
+		Audience:  a.audience,
+		Subject:   attestation.Subject,
+		ID:        attestation.ID,
+		IssuedAt:  attestation.IssuedAt.Unix(),
+		ExpiresAt: attestation.ExpiresAt.Unix(),
+		Score:     attestation.Score,
+		Strength:  attestation.Strength,
+		Policy:    attestation.Policy,
+		Breached:  attestation.Breached,
+		Tenant:    attestation.Tenant,
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: a.alg, Kid: a.kid, Typ: attestationType})
+	if err != nil {
+		return "", nil, err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := a.sign(signingInput)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign attestation: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), &attestation, nil
+}
+
+// Verify checks a token issued by this attester for subject, as a service
+// holding the public key would, and returns what it attests
+func (a *Attester) Verify(token, subject string) (*Attestation, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	if header.Typ != attestationType || header.Kid != a.kid {
+		return nil, fmt.Errorf("not an attestation token of this issuer")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	if err := verifySignature(header.Alg, a.key.Public(), parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims attestationClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if claims.Issuer != a.issuer || claims.Audience != a.audience {
+		return nil, fmt.Errorf("token is for another issuer or audience")
+	}
+	if claims.Subject == "" || claims.Subject != subject {
+		return nil, fmt.Errorf("token attests another subject")
+	}
+	if !a.now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, fmt.Errorf("token has expired")
+	}
+
+	return &Attestation{
+		ID:        claims.ID,
+		Subject:   claims.Subject,
+		Score:     claims.Score,
+		Strength:  claims.Strength,
+		Policy:    claims.Policy,
+		Breached:  claims.Breached,
+		Tenant:    claims.Tenant,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+// sign signs the input with the attester's key, in the JWS encoding of the
+// algorithm
+func (a *Attester) sign(signingInput string) ([]byte, error) {
+	var hashed []byte
+	hashType := crypto.SHA256
+	if a.alg == "ES384" {
+		digest := sha512.Sum384([]byte(signingInput))
+		hashed, hashType = digest[:], crypto.SHA384
+	} else {
+		digest := sha256.Sum256([]byte(signingInput))
+		hashed = digest[:]
+	}
+
+	key, ok := a.key.(*ecdsa.PrivateKey)
+	if !ok {
+		return a.key.Sign(rand.Reader, hashed, hashType)
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, key, hashed)
+	if err != nil {
+		return nil, err
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
+	return signature, nil
+}
+
+// thumbprint returns the RFC 7638 thumbprint of a public key
+func thumbprint(jwk jsonWebKey) string {
+	var members string
+	if jwk.Kty == "EC" {
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, jwk.Crv, jwk.X, jwk.Y)
+	} else {
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	}
+	digest := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
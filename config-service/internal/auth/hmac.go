@@ -0,0 +1,379 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"config-service/internal/metrics"
+)
+
+// Request signature headers
+const (
+	SignatureKeyIDHeader     = "X-Signature-Key-Id"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+	SignatureHeader          = "X-Signature"
+)
+
+// defaultSignatureWindow is how far a request timestamp may be from the server clock
+const defaultSignatureWindow = 5 * time.Minute
+
+// defaultReplayCacheSize is how many signatures are remembered to reject
+// replays unless configured
+const defaultReplayCacheSize = 100000
+
+// maxNonceLength is the longest nonce accepted, in characters
+const maxNonceLength = 128
+
+var (
+	// ErrSignatureMissing is returned when a request carries no signature headers
+	ErrSignatureMissing = errors.New("request signature is missing")
+	// ErrSignatureInvalid is returned when a signature does not verify
+	ErrSignatureInvalid = errors.New("request signature is invalid")
+	// ErrSignatureExpired is returned when the signed timestamp is outside the window
+	ErrSignatureExpired = errors.New("request signature timestamp is outside the allowed window")
+	// ErrSignatureReplayed is returned when a signature or nonce has already been used
+	ErrSignatureReplayed = errors.New("request signature has already been used")
+	// ErrSignatureNonceRequired is returned when a signature without a nonce
+	// is presented to a verifier configured with WithRequiredNonce
+	ErrSignatureNonceRequired = errors.New("request signature nonce is required")
+	// ErrReplayCacheFull is returned when every signature remembered to reject
+	// replays is still within the window, so a new one cannot be checked
+	ErrReplayCacheFull = errors.New("too many signed requests to check for replays; retry later")
+)
+
+// rejectionReasons tag the signed_request_rejections metric by error
+var rejectionReasons = map[error]string{
+	ErrSignatureInvalid:       "invalid",
+	ErrSignatureExpired:       "expired",
+	ErrSignatureReplayed:      "replayed",
+	ErrSignatureNonceRequired: "nonce_required",
+	ErrReplayCacheFull:        "replay_cache_full",
+}
+
+// HMACKey is a shared secret issued to a partner system
+type HMACKey struct {
+	ID     string
+	Tenant string
+	Secret []byte
+}
+
+// ParseHMACKeys parses key definitions of the form "id:tenant:secret"; the
+// tenant may be empty ("id::secret")
+func ParseHMACKeys(definitions []string) ([]HMACKey, error) {
+	keys := make([]HMACKey, 0, len(definitions))
+	for _, definition := range definitions {
+		parts := strings.SplitN(strings.TrimSpace(definition), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid HMAC key definition: expected id:tenant:secret")
+		}
+		keys = append(keys, HMACKey{ID: parts[0], Tenant: parts[1], Secret: []byte(parts[2])})
+	}
+	return keys, nil
+}
+
+// SignedRequest holds the parts of a request covered by its signature
+type SignedRequest struct {
+	Method    string
+	URI       string
+	Body      []byte
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+}
+
+// CanonicalString returns the string that is signed: the method, request URI
+// (path and query), timestamp, and hex SHA-256 of the body, separated by newlines
+func CanonicalString(method, uri, timestamp string, body []byte) string {
+	return NonceCanonicalString(method, uri, timestamp, "", body)
+}
+
+// NonceCanonicalString returns the string that is signed for a request with
+// a nonce, which follows the timestamp on a line of its own. Without a nonce
+// it is the canonical string of CanonicalString.
+func NonceCanonicalString(method, uri, timestamp, nonce string, body []byte) string {
+	digest := sha256.Sum256(body)
+	parts := []string{method, uri, timestamp}
+	if nonce != "" {
+		parts = append(parts, nonce)
+	}
+	return strings.Join(append(parts, hex.EncodeToString(digest[:])), "\n")
+}
+
+// Sign returns the hex HMAC-SHA256 signature of the request parts
+func Sign(secret []byte, method, uri, timestamp string, body []byte) string {
+	return SignWithNonce(secret, method, uri, timestamp, "", body)
+}
+
+// SignWithNonce returns the hex HMAC-SHA256 signature of the request parts
+// including a nonce
+func SignWithNonce(secret []byte, method, uri, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(NonceCanonicalString(method, uri, timestamp, nonce, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HMACVerifier verifies HMAC request signatures from partner systems and
+// rejects replays. A signature is accepted once, or with a nonce, the nonce
+// is, for as long as the timestamp check could pass. The signatures and
+// nonces remembered are bounded: when all of them are still within the
+// window, new requests are rejected rather than risk accepting a replay.
+type HMACVerifier struct {
+	window       time.Duration
+	cacheSize    int
+	requireNonce bool
+	recorder     metrics.Recorder
+	now          func() time.Time
+
+	keysMu  sync.RWMutex
+	keys    map[string]HMACKey
+	retired map[string]retiredHMACKey
+
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	queue []seenSignature
+}
+
+// seenSignature is a remembered signature or nonce, queued in the order
+// they expire
+type seenSignature struct {
+	id     string
+	expiry time.Time
+}
+
+// retiredHMACKey is a replaced key that is still accepted until a deadline
+type retiredHMACKey struct {
+	key   HMACKey
+	until time.Time
+}
+
+// HMACVerifierOption defines functional options for configuring the HMACVerifier
+type HMACVerifierOption func(*HMACVerifier)
+
+// WithSignatureWindow sets how far a request timestamp may be from the server clock
+func WithSignatureWindow(window time.Duration) HMACVerifierOption {
+	return func(v *HMACVerifier) {
+		if window > 0 {
+			v.window = window
+		}
+	}
+}
+
+// WithReplayCacheSize sets how many signatures and nonces are remembered to
+// reject replays, which bounds the signed requests accepted per window
+func WithReplayCacheSize(size int) HMACVerifierOption {
+	return func(v *HMACVerifier) {
+		if size > 0 {
+			v.cacheSize = size
+		}
+	}
+}
+
+// WithRequiredNonce rejects signatures without a nonce
+func WithRequiredNonce() HMACVerifierOption {
+	return func(v *HMACVerifier) {
+		v.requireNonce = true
+	}
+}
+
+// WithSignatureMetrics records rejected signatures by reason, and the
+// entries of the replay cache
+func WithSignatureMetrics(recorder metrics.Recorder) HMACVerifierOption {
+	return func(v *HMACVerifier) {
+		v.recorder = recorder
+	}
+}
+
+// NewHMACVerifier creates a verifier for the keys
+func NewHMACVerifier(keys []HMACKey, options ...HMACVerifierOption) *HMACVerifier {
+	v := &HMACVerifier{
+		keys:      make(map[string]HMACKey, len(keys)),
+		retired:   make(map[string]retiredHMACKey),
+		window:    defaultSignatureWindow,
+		cacheSize: defaultReplayCacheSize,
+		recorder:  metrics.Noop{},
+		now:       time.Now,
+		seen:      make(map[string]struct{}),
+	}
+
+	for _, key := range keys {
+		v.keys[key.ID] = key
+	}
+
+	for _, option := range options {
+		option(v)
+	}
+
+	return v
+}
+
+// Verify checks the request signature and returns the signing key
+func (v *HMACVerifier) Verify(request SignedRequest) (*HMACKey, error) {
+	key, err := v.verify(request)
+	if reason, ok := rejectionReasons[err]; ok {
+		v.recorder.Count("signed_request_rejections", 1, metrics.Tags{"reason": reason})
+	}
+	return key, err
+}
+
+// verify checks the request signature and returns the signing key
+func (v *HMACVerifier) verify(request SignedRequest) (*HMACKey, error) {
+	if request.KeyID == "" && request.Signature == "" {
+		return nil, ErrSignatureMissing
+	}
+	if !validNonce(request.Nonce) {
+		return nil, ErrSignatureInvalid
+	}
+
+	candidates := v.candidateKeys(request.KeyID)
+	if len(candidates) == 0 {
+		return nil, ErrSignatureInvalid
+	}
+
+	seconds, err := strconv.ParseInt(request.Timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrSignatureInvalid
+	}
+	now := v.now()
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return nil, ErrSignatureExpired
+	}
+
+	for _, key := range candidates {
+		expected := SignWithNonce(key.Secret, request.Method, request.URI, request.Timestamp, request.Nonce, request.Body)
+		if !hmac.Equal([]byte(expected), []byte(strings.ToLower(request.Signature))) {
+			continue
+		}
+		if request.Nonce == "" && v.requireNonce {
+			return nil, ErrSignatureNonceRequired
+		}
+
+		// A nonce is used once whatever it signs; without one, the signature
+		// itself is remembered, so an identical request cannot be replayed
+		// within the window
+		id := key.ID + ":signature:" + expected
+		if request.Nonce != "" {
+			id = key.ID + ":nonce:" + request.Nonce
+		}
+		if err := v.remember(id); err != nil {
+			return nil, err
+		}
+
+		key := key
+		return &key, nil
+	}
+	return nil, ErrSignatureInvalid
+}
+
+// validNonce reports whether a nonce, if any, is at most maxNonceLength
+// visible ASCII characters, so that it cannot split lines of the canonical string
+func validNonce(nonce string) bool {
+	if len(nonce) > maxNonceLength {
+		return false
+	}
+	for i := 0; i < len(nonce); i++ {
+		if nonce[i] <= ' ' || nonce[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// candidateKeys returns the current key with an ID and the retired key with
+// the same ID while its grace period lasts
+func (v *HMACVerifier) candidateKeys(id string) []HMACKey {
+	v.keysMu.RLock()
+	defer v.keysMu.RUnlock()
+
+	var candidates []HMACKey
+	if key, ok := v.keys[id]; ok {
+		candidates = append(candidates, key)
+	}
+	if retired, ok := v.retired[id]; ok && v.now().Before(retired.until) {
+		candidates = append(candidates, retired.key)
+	}
+	return candidates
+}
+
+// SetKeys replaces the signing keys. Keys that were changed or removed are
+// still accepted for the grace period, so partners can switch to new secrets
+// without failed requests. It returns the IDs of the keys that changed.
+func (v *HMACVerifier) SetKeys(keys []HMACKey, grace time.Duration) []string {
+	v.keysMu.Lock()
+	defer v.keysMu.Unlock()
+
+	now := v.now()
+	next := make(map[string]HMACKey, len(keys))
+	for _, key := range keys {
+		next[key.ID] = key
+	}
+
+	var changed []string
+	for id, old := range v.keys {
+		key, ok := next[id]
+		if ok && hmac.Equal(key.Secret, old.Secret) && key.Tenant == old.Tenant {
+			continue
+		}
+		changed = append(changed, id)
+		if grace > 0 {
+			v.retired[id] = retiredHMACKey{key: old, until: now.Add(grace)}
+		}
+	}
+	for id := range next {
+		if _, ok := v.keys[id]; !ok {
+			changed = append(changed, id)
+		}
+	}
+	for id, retired := range v.retired {
+		if !now.Before(retired.until) {
+			delete(v.retired, id)
+		}
+	}
+
+	v.keys = next
+	sort.Strings(changed)
+	return changed
+}
+
+// remember records a signature or nonce until it can no longer pass the
+// timestamp check, which is at most twice the window from now. It returns
+// ErrSignatureReplayed if it was already recorded.
+func (v *HMACVerifier) remember(id string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Entries expire in the order they were queued, as they all expire a
+	// fixed time after being recorded
+	now := v.now()
+	expired := 0
+	for expired < len(v.queue) && !now.Before(v.queue[expired].expiry) {
+		delete(v.seen, v.queue[expired].id)
+		expired++
+	}
+	v.queue = v.queue[expired:]
+	defer func() {
+		v.recorder.Gauge("signature_replay_cache_entries", float64(len(v.seen)), nil)
+	}()
+
+	if _, ok := v.seen[id]; ok {
+		return ErrSignatureReplayed
+	}
+	if len(v.seen) >= v.cacheSize {
+		return ErrReplayCacheFull
+	}
+
+	expiry := now.Add(2 * v.window)
+	v.seen[id] = struct{}{}
+	v.queue = append(v.queue, seenSignature{id: id, expiry: expiry})
+	return nil
+}
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// minJWKSRefreshInterval limits how often an unknown key ID can trigger a refetch
+const minJWKSRefreshInterval = 30 * time.Second
+
+// jsonWebKey is a single key from a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// jwksDocument is a JSON Web Key Set
+type jwksDocument struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// openIDConfiguration is the subset of the OIDC discovery document we need
+type openIDConfiguration struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// keySet fetches and caches the signing keys published by an identity provider
+type keySet struct {
+	logger          *logrus.Logger
+	httpClient      *http.Client
+	issuer          string
+	jwksURL         string
+	refreshInterval time.Duration
+
+	mutex       sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastFetched time.Time
+}
+
+// newKeySet creates a key set for the JWKS URL, discovering it from the issuer if empty
+func newKeySet(logger *logrus.Logger, httpClient *http.Client, issuer, jwksURL string, refreshInterval time.Duration) *keySet {
+	return &keySet{
+		logger:          logger,
+		httpClient:      httpClient,
+		issuer:          issuer,
+		jwksURL:         jwksURL,
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+
+// key returns the public key with the key ID, refreshing the set when it is stale
+// or the key ID is unknown
+func (ks *keySet) key(kid string) (crypto.PublicKey, error) {
+	ks.mutex.RLock()
+	key, ok := ks.keys[kid]
+	stale := time.Since(ks.lastFetched) > ks.refreshInterval
+	recentlyFetched := time.Since(ks.lastFetched) < minJWKSRefreshInterval
+	ks.mutex.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if !ok && recentlyFetched {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+
+	if err := ks.refresh(); err != nil {
+		if ok {
+			// Keep serving the cached key if the identity provider is unreachable
+			ks.logger.Warnf("JWKS refresh failed, using cached keys: %v", err)
+			return key, nil
+		}
+		return nil, err
+	}
+
+	ks.mutex.RLock()
+	defer ks.mutex.RUnlock()
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %q", kid)
+}
+
+// refresh fetches the JWKS document and replaces the cached keys
+func (ks *keySet) refresh() error {
+	jwksURL, err := ks.resolveJWKSURL()
+	if err != nil {
+		return err
+	}
+
+	var document jwksDocument
+	if err := ks.getJSON(jwksURL, &document); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			ks.logger.Warnf("Skipping JWKS key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	ks.mutex.Lock()
+	ks.keys = keys
+	ks.lastFetched = time.Now()
+	ks.mutex.Unlock()
+
+	ks.logger.Debugf("JWKS refreshed: %d signing keys", len(keys))
+	return nil
+}
+
+// resolveJWKSURL returns the configured JWKS URL or discovers it from the issuer
+func (ks *keySet) resolveJWKSURL() (string, error) {
+	ks.mutex.RLock()
+	jwksURL := ks.jwksURL
+	ks.mutex.RUnlock()
+	if jwksURL != "" {
+		return jwksURL, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(ks.issuer, "/") + "/.well-known/openid-configuration"
+	var configuration openIDConfiguration
+	if err := ks.getJSON(discoveryURL, &configuration); err != nil {
+		return "", fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if configuration.JWKSURI == "" {
+		return "", fmt.Errorf("OIDC discovery document has no jwks_uri")
+	}
+
+	ks.mutex.Lock()
+	ks.jwksURL = configuration.JWKSURI
+	ks.mutex.Unlock()
+	return configuration.JWKSURI, nil
+}
+
+// getJSON fetches a URL and decodes the JSON response body
+func (ks *keySet) getJSON(url string, target interface{}) error {
+	resp, err := ks.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// publicKey converts the JWK into a Go public key
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", jwk.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
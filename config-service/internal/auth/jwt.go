@@ -0,0 +1,352 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultClockSkew is the tolerance applied to exp, nbf, and iat checks
+	defaultClockSkew = 60 * time.Second
+
+	// defaultJWKSRefreshInterval is how often signing keys are refetched
+	defaultJWKSRefreshInterval = 15 * time.Minute
+)
+
+// Claims holds the validated claims of a bearer token
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	Scopes    []string
+	Tenant    string
+	Raw       map[string]interface{}
+}
+
+// HasScope reports whether the token grants the scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range c.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTValidator validates bearer tokens issued by an OIDC identity provider
+type JWTValidator struct {
+	logger      *logrus.Logger
+	issuer      string
+	audience    string
+	clockSkew   time.Duration
+	tenantClaim string
+	scopes      map[string]string
+	keys        *keySet
+	now         func() time.Time
+}
+
+// JWTValidatorOption defines functional options for configuring the JWTValidator
+type JWTValidatorOption func(*jwtValidatorSettings)
+
+// jwtValidatorSettings collects option values before the validator is built
+type jwtValidatorSettings struct {
+	jwksURL         string
+	clockSkew       time.Duration
+	refreshInterval time.Duration
+	tenantClaim     string
+	scopes          map[string]string
+	httpClient      *http.Client
+}
+
+// WithJWKSURL sets the JWKS URL instead of discovering it from the issuer
+func WithJWKSURL(url string) JWTValidatorOption {
+	return func(s *jwtValidatorSettings) {
+		s.jwksURL = url
+	}
+}
+
+// WithClockSkew sets the tolerance applied to time-based claims
+func WithClockSkew(skew time.Duration) JWTValidatorOption {
+	return func(s *jwtValidatorSettings) {
+		s.clockSkew = skew
+	}
+}
+
+// WithJWKSRefreshInterval sets how often signing keys are refetched
+func WithJWKSRefreshInterval(interval time.Duration) JWTValidatorOption {
+	return func(s *jwtValidatorSettings) {
+		s.refreshInterval = interval
+	}
+}
+
+// WithTenantClaim sets the claim the caller's tenant is read from
+func WithTenantClaim(claim string) JWTValidatorOption {
+	return func(s *jwtValidatorSettings) {
+		s.tenantClaim = claim
+	}
+}
+
+// WithScopeMapping maps service scopes (e.g. "check", "admin") to the token scopes
+// that grant them. A service scope mapped to an empty string is granted to every
+// valid token; an unmapped service scope is never granted.
+func WithScopeMapping(scopes map[string]string) JWTValidatorOption {
+	return func(s *jwtValidatorSettings) {
+		s.scopes = scopes
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for discovery and JWKS requests
+func WithHTTPClient(client *http.Client) JWTValidatorOption {
+	return func(s *jwtValidatorSettings) {
+		s.httpClient = client
+	}
+}
+
+// NewJWTValidator creates a validator for tokens from the issuer intended for the audience
+func NewJWTValidator(logger *logrus.Logger, issuer, audience string, options ...JWTValidatorOption) *JWTValidator {
+	settings := &jwtValidatorSettings{
+		clockSkew:       defaultClockSkew,
+		refreshInterval: defaultJWKSRefreshInterval,
+		tenantClaim:     "tenant",
+		scopes:          map[string]string{},
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(settings)
+	}
+
+	return &JWTValidator{
+		logger:      logger,
+		issuer:      issuer,
+		audience:    audience,
+		clockSkew:   settings.clockSkew,
+		tenantClaim: settings.tenantClaim,
+		scopes:      settings.scopes,
+		keys:        newKeySet(logger, settings.httpClient, issuer, settings.jwksURL, settings.refreshInterval),
+		now:         time.Now,
+	}
+}
+
+// Refresh fetches the signing keys from the identity provider
+func (v *JWTValidator) Refresh() error {
+	return v.keys.refresh()
+}
+
+// Grants reports whether the validated claims grant the service scope
+func (v *JWTValidator) Grants(claims *Claims, scope string) bool {
+	tokenScope, ok := v.scopes[scope]
+	if !ok {
+		return false
+	}
+	return tokenScope == "" || claims.HasScope(tokenScope)
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Validate verifies the token signature and claims and returns the claims
+func (v *JWTValidator) Validate(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+
+	key, err := v.keys.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+
+	claims := v.parseClaims(raw)
+	if err := v.validateClaims(claims, raw); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// parseClaims extracts the registered and scope claims
+func (v *JWTValidator) parseClaims(raw map[string]interface{}) *Claims {
+	claims := &Claims{Raw: raw}
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Tenant, _ = raw[v.tenantClaim].(string)
+	claims.Audience = stringOrList(raw["aud"])
+
+	if scope, ok := raw["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	} else {
+		claims.Scopes = stringOrList(raw["scp"])
+	}
+
+	if exp, ok := numericDate(raw["exp"]); ok {
+		claims.ExpiresAt = exp
+	}
+	return claims
+}
+
+// validateClaims checks the issuer, audience, and time-based claims
+func (v *JWTValidator) validateClaims(claims *Claims, raw map[string]interface{}) error {
+	now := v.now()
+
+	if claims.Issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer: %q", claims.Issuer)
+	}
+
+	if v.audience != "" {
+		matched := false
+		for _, audience := range claims.Audience {
+			if audience == v.audience {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("token is not intended for audience %q", v.audience)
+		}
+	}
+
+	if claims.ExpiresAt.IsZero() {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(claims.ExpiresAt.Add(v.clockSkew)) {
+		return fmt.Errorf("token has expired")
+	}
+	if nbf, ok := numericDate(raw["nbf"]); ok && now.Add(v.clockSkew).Before(nbf) {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if iat, ok := numericDate(raw["iat"]); ok && now.Add(v.clockSkew).Before(iat) {
+		return fmt.Errorf("token was issued in the future")
+	}
+
+	return nil
+}
+
+// verifySignature verifies the signature over the signing input
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	var hasher hash.Hash
+	var hashType crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hasher, hashType = sha256.New(), crypto.SHA256
+	case "RS384", "ES384":
+		hasher, hashType = sha512.New384(), crypto.SHA384
+	case "RS512", "ES512":
+		hasher, hashType = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm: %q", alg)
+	}
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, hashType, digest, signature); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match EC key", alg)
+		}
+		// Each ES algorithm is defined for a single curve
+		if publicKey.Curve != ecAlgorithmCurves[alg]() {
+			return fmt.Errorf("algorithm %s does not match the %s curve of the key", alg, publicKey.Curve.Params().Name)
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing key")
+	}
+}
+
+// ecAlgorithmCurves maps the ES signing algorithms to the curve of their keys
+var ecAlgorithmCurves = map[string]func() elliptic.Curve{
+	"ES256": elliptic.P256,
+	"ES384": elliptic.P384,
+	"ES512": elliptic.P521,
+}
+
+// decodeSegment decodes a base64url-encoded JSON token segment
+func decodeSegment(segment string, target interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, target)
+}
+
+// stringOrList reads a claim that may be a single string or a list of strings
+func stringOrList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// numericDate reads a NumericDate claim
+func numericDate(value interface{}) (time.Time, bool) {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
@@ -0,0 +1,151 @@
+// Package clientip derives the address of the client of a request that may
+// have passed through proxies
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Headers the client IP can be derived from
+const (
+	HeaderXForwardedFor = "x-forwarded-for"
+	HeaderXRealIP       = "x-real-ip"
+	HeaderForwarded     = "forwarded"
+	HeaderNone          = "none"
+)
+
+// Headers lists the supported client IP headers
+var Headers = []string{HeaderXForwardedFor, HeaderXRealIP, HeaderForwarded, HeaderNone}
+
+// Resolver derives the address of the client from a request. The header
+// naming the client is only believed when the request comes from a trusted
+// proxy, and only as far back as the chain of trusted proxies reaches, so
+// clients cannot spoof their address.
+type Resolver struct {
+	trusted []*net.IPNet
+	header  string
+}
+
+// New creates a resolver trusting proxies in the given CIDRs (or single
+// addresses) to report the client in the given header
+func New(trustedProxies []string, header string) (*Resolver, error) {
+	r := &Resolver{header: strings.ToLower(header)}
+	switch r.header {
+	case HeaderXForwardedFor, HeaderXRealIP, HeaderForwarded, HeaderNone:
+	case "":
+		r.header = HeaderNone
+	default:
+		return nil, fmt.Errorf("unsupported client IP header %q (supported: %s)", header, strings.Join(Headers, ", "))
+	}
+
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: expected an IP address or CIDR", proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		r.trusted = append(r.trusted, network)
+	}
+	return r, nil
+}
+
+// Resolve returns the client IP of a request. Walking the addresses in the
+// header from the nearest hop, the first address that is not a trusted proxy
+// is the client. Peers that are not IP addresses, such as those of a Unix
+// domain socket, are local and trusted.
+func (r *Resolver) Resolve(req *http.Request) string {
+	remote := remoteIP(req.RemoteAddr)
+	if parsed := net.ParseIP(remote); parsed != nil && !r.isTrusted(parsed) {
+		return remote
+	}
+
+	hops := r.hops(req.Header)
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// An unknown or obfuscated hop ends what can be believed
+			break
+		}
+		client = ip.String()
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// hops returns the addresses in the configured header, the farthest first
+func (r *Resolver) hops(header http.Header) []string {
+	var hops []string
+	switch r.header {
+	case HeaderXForwardedFor:
+		for _, value := range header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(value, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+	case HeaderXRealIP:
+		if value := strings.TrimSpace(header.Get("X-Real-IP")); value != "" {
+			hops = append(hops, value)
+		}
+	case HeaderForwarded:
+		for _, value := range header.Values("Forwarded") {
+			for _, element := range strings.Split(value, ",") {
+				hops = append(hops, forwardedFor(element))
+			}
+		}
+	}
+	return hops
+}
+
+// isTrusted reports whether an address belongs to a trusted proxy
+func (r *Resolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the address of the for parameter of an RFC 7239
+// Forwarded element, without quotes, brackets, or port
+func forwardedFor(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || !strings.EqualFold(name, "for") {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if strings.HasPrefix(value, "[") {
+			if end := strings.Index(value, "]"); end > 0 {
+				return value[1:end]
+			}
+		}
+		return remoteIP(value)
+	}
+	return ""
+}
+
+// remoteIP returns the host of an address that may carry a port
+func remoteIP(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// BuilderOption sets configuration values on a Builder. Any func(*Config) can
+// be used for settings without a dedicated option.
+type BuilderOption func(*Config)
+
+// Builder constructs a validated configuration in code, for applications that
+// embed the services instead of running the binary. It starts from the same
+// defaults as Load but never reads files, environment variables, or flags.
+type Builder struct {
+	options []BuilderOption
+}
+
+// NewBuilder creates a builder with the given options
+func NewBuilder(options ...BuilderOption) *Builder {
+	return &Builder{options: options}
+}
+
+// With adds options, applied after the ones already given
+func (b *Builder) With(options ...BuilderOption) *Builder {
+	b.options = append(b.options, options...)
+	return b
+}
+
+// Build applies the options to the defaults and validates the result,
+// including strict validation when WithStrict is set
+func (b *Builder) Build() (*Config, error) {
+	cfg, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range b.options {
+		option(cfg)
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.Strict {
+		if err := validateStrict(cfg, nil); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Default returns the default configuration, as Load returns it when nothing
+// is configured
+func Default() (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal defaults: %w", err)
+	}
+	return &cfg, nil
+}
+
+// WithPort sets the TCP port to listen on
+func WithPort(port int) BuilderOption {
+	return func(c *Config) {
+		c.Server.Port = port
+	}
+}
+
+// WithEnvironment sets the deployment environment name
+func WithEnvironment(env string) BuilderOption {
+	return func(c *Config) {
+		c.Server.Env = env
+	}
+}
+
+// WithLogLevel sets the log level, e.g. "debug"
+func WithLogLevel(level string) BuilderOption {
+	return func(c *Config) {
+		c.Logging.Level = level
+	}
+}
+
+// WithBreachDetection enables or disables HIBP breach checks
+func WithBreachDetection(enabled bool) BuilderOption {
+	return func(c *Config) {
+		c.Breach.Enabled = enabled
+	}
+}
+
+// WithBreachEndpoint sets the HIBP range API endpoint
+func WithBreachEndpoint(endpoint string) BuilderOption {
+	return func(c *Config) {
+		c.Breach.APIEndpoint = endpoint
+	}
+}
+
+// WithBreachTimeout sets the HIBP request timeout, rounded down to whole seconds
+func WithBreachTimeout(timeout time.Duration) BuilderOption {
+	return func(c *Config) {
+		c.Breach.Timeout = int(timeout / time.Second)
+	}
+}
+
+// WithBreachCacheDuration sets how long breach results are cached, rounded down to whole minutes
+func WithBreachCacheDuration(duration time.Duration) BuilderOption {
+	return func(c *Config) {
+		c.Breach.CacheDuration = int(duration / time.Minute)
+	}
+}
+
+// WithMaxPasswordLength sets the longest password accepted
+func WithMaxPasswordLength(length int) BuilderOption {
+	return func(c *Config) {
+		c.Password.MaxLength = length
+	}
+}
+
+// WithThrottle enables brute-force throttling with the given threshold and delays
+func WithThrottle(threshold int, baseDelay, maxDelay time.Duration) BuilderOption {
+	return func(c *Config) {
+		c.Throttle.Enabled = true
+		c.Throttle.Threshold = threshold
+		c.Throttle.BaseDelay = int(baseDelay / time.Millisecond)
+		c.Throttle.MaxDelay = int(maxDelay / time.Millisecond)
+	}
+}
+
+// WithMonthlyQuota enables usage tracking with a default monthly request quota per tenant
+func WithMonthlyQuota(quota int64) BuilderOption {
+	return func(c *Config) {
+		c.Usage.Enabled = true
+		c.Usage.MonthlyQuota = quota
+	}
+}
+
+// WithTLS enables TLS with the certificate and key files
+func WithTLS(certFile, keyFile string) BuilderOption {
+	return func(c *Config) {
+		c.TLS.Enabled = true
+		c.TLS.CertFile = certFile
+		c.TLS.KeyFile = keyFile
+	}
+}
+
+// WithAdminToken sets the token that authorizes the admin API
+func WithAdminToken(token string) BuilderOption {
+	return func(c *Config) {
+		c.Admin.Token = token
+	}
+}
+
+// WithJWT enables bearer token authentication against an issuer
+func WithJWT(issuer, audience string) BuilderOption {
+	return func(c *Config) {
+		c.Auth.JWT.Enabled = true
+		c.Auth.JWT.Issuer = issuer
+		c.Auth.JWT.Audience = audience
+	}
+}
+
+// WithHMACKeys enables HMAC request signing with keys in "id:tenant:secret" form
+func WithHMACKeys(keys ...string) BuilderOption {
+	return func(c *Config) {
+		c.Auth.HMAC.Enabled = true
+		c.Auth.HMAC.Keys = keys
+	}
+}
+
+// WithDefaultLocale sets the locale used when a request does not ask for one
+func WithDefaultLocale(locale string) BuilderOption {
+	return func(c *Config) {
+		c.I18n.DefaultLocale = locale
+	}
+}
+
+// WithStrict turns on strict validation of out-of-range and contradicting settings
+func WithStrict(strict bool) BuilderOption {
+	return func(c *Config) {
+		c.Strict = strict
+	}
+}
@@ -1,77 +1,1289 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"config-service/internal/auth"
+	"config-service/internal/clientip"
+	"config-service/internal/dataset"
+	"config-service/internal/errorreport"
+	"config-service/internal/i18n"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/queue"
+	"config-service/internal/server"
+	"config-service/internal/services"
+	"config-service/internal/tlsutil"
+	"config-service/pkg/strength"
 )
 
 // Config represents the application configuration
 type Config struct {
+	// Files lists the configuration files that were read, base file first
+	Files []string `mapstructure:"-" json:"files,omitempty"`
+
+	// Strict fails loading on unknown keys, out-of-range values, and
+	// contradicting settings instead of running with them
+	Strict bool `mapstructure:"strict" json:"strict"`
+
 	Server struct {
-		Port int    `mapstructure:"port"`
-		Env  string `mapstructure:"env"`
-	} `mapstructure:"server"`
+		Port           int    `mapstructure:"port" json:"port"`
+		Env            string `mapstructure:"env" json:"env"`
+		MaxInFlight    int    `mapstructure:"max_in_flight" json:"max_in_flight"`
+		ShedRetryAfter int    `mapstructure:"shed_retry_after" json:"shed_retry_after"`
+		TCPEnabled     bool   `mapstructure:"tcp_enabled" json:"tcp_enabled"`
+		UnixSocket     string `mapstructure:"unix_socket" json:"unix_socket"`
+		UnixSocketMode string `mapstructure:"unix_socket_mode" json:"unix_socket_mode"`
+		AdminHost      string `mapstructure:"admin_host" json:"admin_host"`
+		AdminPort      int    `mapstructure:"admin_port" json:"admin_port"`
+		Pprof          bool   `mapstructure:"pprof" json:"pprof"`
+
+		Upgrades        bool   `mapstructure:"upgrades" json:"upgrades"`
+		UpgradeTimeout  int    `mapstructure:"upgrade_timeout" json:"upgrade_timeout"`
+		ShutdownTimeout int    `mapstructure:"shutdown_timeout" json:"shutdown_timeout"`
+		PIDFile         string `mapstructure:"pid_file" json:"pid_file"`
+
+		ReadTimeout       int `mapstructure:"read_timeout" json:"read_timeout"`
+		ReadHeaderTimeout int `mapstructure:"read_header_timeout" json:"read_header_timeout"`
+		WriteTimeout      int `mapstructure:"write_timeout" json:"write_timeout"`
+		IdleTimeout       int `mapstructure:"idle_timeout" json:"idle_timeout"`
+		MaxHeaderBytes    int `mapstructure:"max_header_bytes" json:"max_header_bytes"`
+
+		TrustedProxies []string `mapstructure:"trusted_proxies" json:"trusted_proxies"`
+		ClientIPHeader string   `mapstructure:"client_ip_header" json:"client_ip_header"`
+
+		StrictSchema bool `mapstructure:"strict_schema" json:"strict_schema"`
+	} `mapstructure:"server" json:"server"`
+	TLS struct {
+		Enabled            bool     `mapstructure:"enabled" json:"enabled"`
+		CertFile           string   `mapstructure:"cert_file" json:"cert_file"`
+		KeyFile            string   `mapstructure:"key_file" json:"key_file"`
+		MinVersion         string   `mapstructure:"min_version" json:"min_version"`
+		CipherSuites       []string `mapstructure:"cipher_suites" json:"cipher_suites"`
+		AutoReload         bool     `mapstructure:"auto_reload" json:"auto_reload"`
+		ClientAuth         string   `mapstructure:"client_auth" json:"client_auth"`
+		ClientCAFile       string   `mapstructure:"client_ca_file" json:"client_ca_file"`
+		ClientAllowedNames []string `mapstructure:"client_allowed_names" json:"client_allowed_names"`
+	} `mapstructure:"tls" json:"tls"`
 	Logging struct {
-		Level string `mapstructure:"level"`
-	} `mapstructure:"logging"`
+		Level               string   `mapstructure:"level" json:"level"`
+		Format              string   `mapstructure:"format" json:"format"`
+		AccessFormat        string   `mapstructure:"access_format" json:"access_format"`
+		AccessFields        []string `mapstructure:"access_fields" json:"access_fields"`
+		AccessSampleSuccess float64  `mapstructure:"access_sample_success" json:"access_sample_success"`
+		AccessSampleClient  float64  `mapstructure:"access_sample_client_error" json:"access_sample_client_error"`
+		AccessSampleServer  float64  `mapstructure:"access_sample_server_error" json:"access_sample_server_error"`
+		RequestBodies       bool     `mapstructure:"request_bodies" json:"request_bodies"`
+		RequestBodyMaxBytes int      `mapstructure:"request_body_max_bytes" json:"request_body_max_bytes"`
+		SlowRequestMS       int      `mapstructure:"slow_request_ms" json:"slow_request_ms"`
+		File                struct {
+			Path           string `mapstructure:"path" json:"path"`
+			MaxSizeMB      int    `mapstructure:"max_size_mb" json:"max_size_mb"`
+			RotateInterval int    `mapstructure:"rotate_interval" json:"rotate_interval"`
+			MaxBackups     int    `mapstructure:"max_backups" json:"max_backups"`
+			Compress       bool   `mapstructure:"compress" json:"compress"`
+			Stdout         bool   `mapstructure:"stdout" json:"stdout"`
+		} `mapstructure:"file" json:"file"`
+	} `mapstructure:"logging" json:"logging"`
+	Metrics struct {
+		Backend        string `mapstructure:"backend" json:"backend"`
+		Namespace      string `mapstructure:"namespace" json:"namespace"`
+		PrometheusPath string `mapstructure:"prometheus_path" json:"prometheus_path"`
+		StatsDAddress  string `mapstructure:"statsd_address" json:"statsd_address"`
+		DogStatsDTags  bool   `mapstructure:"dogstatsd_tags" json:"dogstatsd_tags"`
+	} `mapstructure:"metrics" json:"metrics"`
+	Health struct {
+		TimeoutMS     int `mapstructure:"timeout_ms" json:"timeout_ms"`
+		HIBPTimeoutMS int `mapstructure:"hibp_timeout_ms" json:"hibp_timeout_ms"`
+		HIBPCacheTTL  int `mapstructure:"hibp_cache_ttl" json:"hibp_cache_ttl"`
+	} `mapstructure:"health" json:"health"`
 	Password struct {
-		MaxLength int `mapstructure:"max_length"`
-	} `mapstructure:"password"`
+		MaxLength       int `mapstructure:"max_length" json:"max_length"`
+		GenerateLength  int `mapstructure:"generate_length" json:"generate_length"`
+		PassphraseWords int `mapstructure:"passphrase_words" json:"passphrase_words"`
+
+		MemoEnabled    bool `mapstructure:"memo_enabled" json:"memo_enabled"`
+		MemoTTL        int  `mapstructure:"memo_ttl" json:"memo_ttl"`
+		MemoMaxEntries int  `mapstructure:"memo_max_entries" json:"memo_max_entries"`
+
+		Dictionaries []string `mapstructure:"dictionaries" json:"dictionaries"`
+
+		SelfTest             bool   `mapstructure:"self_test" json:"self_test"`
+		SelfTestExpectations string `mapstructure:"self_test_expectations" json:"self_test_expectations"`
+		SelfTestFailOnDrift  bool   `mapstructure:"self_test_fail_on_drift" json:"self_test_fail_on_drift"`
+
+		Decoys string `mapstructure:"decoys" json:"decoys"`
+
+		Policies []NamedPolicy `mapstructure:"policies" json:"policies"`
+	} `mapstructure:"password" json:"password"`
 	Breach struct {
-		Enabled       bool   `mapstructure:"enabled"`
-		APIEndpoint   string `mapstructure:"api_endpoint"`
-		Timeout       int    `mapstructure:"timeout"`
-		CacheDuration int    `mapstructure:"cache_duration"`
-	} `mapstructure:"breach"`
-}
-
-// Load loads the configuration from environment variables and default values
-func Load() (*Config, error) {
-	// Set configuration defaults
-	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.env", "development")
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("password.max_length", 128)
-	viper.SetDefault("breach.enabled", true)
-	viper.SetDefault("breach.api_endpoint", "https://api.pwnedpasswords.com/range")
-	viper.SetDefault("breach.timeout", 10)
-	viper.SetDefault("breach.cache_duration", 60)
-
-	// Set environment variable prefix
-	viper.SetEnvPrefix("CONFIG_SERVICE")
-	viper.AutomaticEnv()
+		Enabled       bool   `mapstructure:"enabled" json:"enabled"`
+		APIEndpoint   string `mapstructure:"api_endpoint" json:"api_endpoint"`
+		Timeout       int    `mapstructure:"timeout" json:"timeout"`
+		CacheDuration int    `mapstructure:"cache_duration" json:"cache_duration"`
+		MaxInFlight   int    `mapstructure:"max_in_flight" json:"max_in_flight"`
+
+		MaxRetries       int `mapstructure:"max_retries" json:"max_retries"`
+		RetryBackoff     int `mapstructure:"retry_backoff_ms" json:"retry_backoff_ms"`
+		CircuitThreshold int `mapstructure:"circuit_threshold" json:"circuit_threshold"`
+		CircuitCooldown  int `mapstructure:"circuit_cooldown" json:"circuit_cooldown"`
+
+		MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host" json:"max_idle_conns_per_host"`
+		MaxConnsPerHost     int `mapstructure:"max_conns_per_host" json:"max_conns_per_host"`
+		IdleConnTimeout     int `mapstructure:"idle_conn_timeout" json:"idle_conn_timeout"`
+		KeepAlive           int `mapstructure:"keep_alive" json:"keep_alive"`
+		TLSSessionCache     int `mapstructure:"tls_session_cache" json:"tls_session_cache"`
+
+		OfflineSHA1Path string `mapstructure:"offline_sha1_path" json:"offline_sha1_path"`
+		OfflineNTLMPath string `mapstructure:"offline_ntlm_path" json:"offline_ntlm_path"`
+
+		Chaos struct {
+			Enabled       bool    `mapstructure:"enabled" json:"enabled"`
+			TimeoutRate   float64 `mapstructure:"timeout_rate" json:"timeout_rate"`
+			RateLimitRate float64 `mapstructure:"rate_limit_rate" json:"rate_limit_rate"`
+			MalformedRate float64 `mapstructure:"malformed_rate" json:"malformed_rate"`
+			SlowRate      float64 `mapstructure:"slow_rate" json:"slow_rate"`
+			SlowDelay     int     `mapstructure:"slow_delay_ms" json:"slow_delay_ms"`
+		} `mapstructure:"chaos" json:"chaos"`
+	} `mapstructure:"breach" json:"breach"`
+	Throttle struct {
+		Enabled        bool   `mapstructure:"enabled" json:"enabled"`
+		Threshold      int    `mapstructure:"threshold" json:"threshold"`
+		BaseDelay      int    `mapstructure:"base_delay_ms" json:"base_delay_ms"`
+		MaxDelay       int    `mapstructure:"max_delay_ms" json:"max_delay_ms"`
+		CaptchaURL     string `mapstructure:"captcha_verify_url" json:"captcha_verify_url"`
+		CaptchaSecret  string `mapstructure:"captcha_secret" json:"captcha_secret"`
+		CaptchaTimeout int    `mapstructure:"captcha_timeout" json:"captcha_timeout"`
+		MaxClients     int    `mapstructure:"max_clients" json:"max_clients"`
+	} `mapstructure:"throttle" json:"throttle"`
+	Usage struct {
+		Enabled      bool     `mapstructure:"enabled" json:"enabled"`
+		MonthlyQuota int64    `mapstructure:"monthly_quota" json:"monthly_quota"`
+		TenantQuotas []string `mapstructure:"tenant_quotas" json:"tenant_quotas"`
+	} `mapstructure:"usage" json:"usage"`
+	Analytics struct {
+		Enabled       bool `mapstructure:"enabled" json:"enabled"`
+		RetentionDays int  `mapstructure:"retention_days" json:"retention_days"`
+		MinChecks     int  `mapstructure:"min_checks" json:"min_checks"`
+	} `mapstructure:"analytics" json:"analytics"`
+	Bulk struct {
+		Workers     int `mapstructure:"workers" json:"workers"`
+		QueueSize   int `mapstructure:"queue_size" json:"queue_size"`
+		ItemTimeout int `mapstructure:"item_timeout_ms" json:"item_timeout_ms"`
+
+		MaxPerRequest int   `mapstructure:"max_per_request" json:"max_per_request"`
+		MaxBodyBytes  int64 `mapstructure:"max_body_bytes" json:"max_body_bytes"`
+	} `mapstructure:"bulk" json:"bulk"`
+	Runtime struct {
+		MemoryLimitMB     int `mapstructure:"memory_limit_mb" json:"memory_limit_mb"`
+		GCPercent         int `mapstructure:"gc_percent" json:"gc_percent"`
+		MaxMappedDatasets int `mapstructure:"max_mapped_datasets" json:"max_mapped_datasets"`
+
+		Deterministic bool  `mapstructure:"deterministic" json:"deterministic"`
+		Seed          int64 `mapstructure:"seed" json:"seed"`
+	} `mapstructure:"runtime" json:"runtime"`
+	Datasets struct {
+		Checksums  string `mapstructure:"checksums" json:"checksums"`
+		SigningKey string `mapstructure:"signing_key" json:"signing_key"`
+	} `mapstructure:"datasets" json:"datasets"`
+	Recovery struct {
+		WebhookURL     string `mapstructure:"webhook_url" json:"webhook_url"`
+		WebhookTimeout int    `mapstructure:"webhook_timeout" json:"webhook_timeout"`
+	} `mapstructure:"recovery" json:"recovery"`
+	ErrorReporting struct {
+		Backend     string `mapstructure:"backend" json:"backend"`
+		DSN         string `mapstructure:"dsn" json:"dsn"`
+		WebhookURL  string `mapstructure:"webhook_url" json:"webhook_url"`
+		Environment string `mapstructure:"environment" json:"environment"`
+		Timeout     int    `mapstructure:"timeout" json:"timeout"`
+	} `mapstructure:"error_reporting" json:"error_reporting"`
+	Admin struct {
+		Token                string `mapstructure:"token" json:"token"`
+		IdempotencyTTL       int    `mapstructure:"idempotency_ttl" json:"idempotency_ttl"`
+		DeletedRetentionDays int    `mapstructure:"deleted_retention_days" json:"deleted_retention_days"`
+		BannedImportMaxBytes int64  `mapstructure:"banned_import_max_bytes" json:"banned_import_max_bytes"`
+		BannedImportDir      string `mapstructure:"banned_import_dir" json:"banned_import_dir"`
+	} `mapstructure:"admin" json:"admin"`
+	Auth struct {
+		APIKeysEnabled bool `mapstructure:"api_keys_enabled" json:"api_keys_enabled"`
+		JWT            struct {
+			Enabled             bool   `mapstructure:"enabled" json:"enabled"`
+			Issuer              string `mapstructure:"issuer" json:"issuer"`
+			Audience            string `mapstructure:"audience" json:"audience"`
+			JWKSURL             string `mapstructure:"jwks_url" json:"jwks_url"`
+			ClockSkew           int    `mapstructure:"clock_skew" json:"clock_skew"`
+			JWKSRefreshInterval int    `mapstructure:"jwks_refresh_interval" json:"jwks_refresh_interval"`
+			TenantClaim         string `mapstructure:"tenant_claim" json:"tenant_claim"`
+			CheckScope          string `mapstructure:"check_scope" json:"check_scope"`
+			AdminScope          string `mapstructure:"admin_scope" json:"admin_scope"`
+		} `mapstructure:"jwt" json:"jwt"`
+		HMAC struct {
+			Enabled         bool     `mapstructure:"enabled" json:"enabled"`
+			Keys            []string `mapstructure:"keys" json:"keys"`
+			Window          int      `mapstructure:"window" json:"window"`
+			MaxBodyBytes    int64    `mapstructure:"max_body_bytes" json:"max_body_bytes"`
+			ReplayCacheSize int      `mapstructure:"replay_cache_size" json:"replay_cache_size"`
+			RequireNonce    bool     `mapstructure:"require_nonce" json:"require_nonce"`
+		} `mapstructure:"hmac" json:"hmac"`
+	} `mapstructure:"auth" json:"auth"`
+	Attestation struct {
+		Enabled  bool   `mapstructure:"enabled" json:"enabled"`
+		KeyFile  string `mapstructure:"key_file" json:"key_file"`
+		Issuer   string `mapstructure:"issuer" json:"issuer"`
+		Audience string `mapstructure:"audience" json:"audience"`
+		TTL      int    `mapstructure:"ttl" json:"ttl"`
+	} `mapstructure:"attestation" json:"attestation"`
+	Reuse struct {
+		Enabled     bool `mapstructure:"enabled" json:"enabled"`
+		MaxAccounts int  `mapstructure:"max_accounts" json:"max_accounts"`
+	} `mapstructure:"reuse" json:"reuse"`
+	Audit struct {
+		Enabled       bool   `mapstructure:"enabled" json:"enabled"`
+		Sink          string `mapstructure:"sink" json:"sink"`
+		Path          string `mapstructure:"path" json:"path"`
+		SyslogTag     string `mapstructure:"syslog_tag" json:"syslog_tag"`
+		RetentionDays int    `mapstructure:"retention_days" json:"retention_days"`
+	} `mapstructure:"audit" json:"audit"`
+	I18n struct {
+		DefaultLocale string `mapstructure:"default_locale" json:"default_locale"`
+	} `mapstructure:"i18n" json:"i18n"`
+	Admission struct {
+		Enabled        bool   `mapstructure:"enabled" json:"enabled"`
+		MinStrength    string `mapstructure:"min_strength" json:"min_strength"`
+		RejectBreached bool   `mapstructure:"reject_breached" json:"reject_breached"`
+	} `mapstructure:"admission" json:"admission"`
+	IdP struct {
+		Enabled        bool   `mapstructure:"enabled" json:"enabled"`
+		MinStrength    string `mapstructure:"min_strength" json:"min_strength"`
+		RejectBreached bool   `mapstructure:"reject_breached" json:"reject_breached"`
+
+		RequireGenerated bool `mapstructure:"require_generated" json:"require_generated"`
+	} `mapstructure:"idp" json:"idp"`
+	ADFilter struct {
+		Enabled           bool `mapstructure:"enabled" json:"enabled"`
+		LatencyBudgetMS   int  `mapstructure:"latency_budget_ms" json:"latency_budget_ms"`
+		FailOpen          bool `mapstructure:"fail_open" json:"fail_open"`
+		RejectBreached    bool `mapstructure:"reject_breached" json:"reject_breached"`
+		MaxBannedVariants int  `mapstructure:"max_banned_variants" json:"max_banned_variants"`
+	} `mapstructure:"ad_filter" json:"ad_filter"`
+	Queue struct {
+		Enabled      bool   `mapstructure:"enabled" json:"enabled"`
+		Driver       string `mapstructure:"driver" json:"driver"`
+		URL          string `mapstructure:"url" json:"url"`
+		Token        string `mapstructure:"token" json:"token"`
+		RequestTopic string `mapstructure:"request_topic" json:"request_topic"`
+		ReplyTopic   string `mapstructure:"reply_topic" json:"reply_topic"`
+		Group        string `mapstructure:"group" json:"group"`
+		Workers      int    `mapstructure:"workers" json:"workers"`
+	} `mapstructure:"queue" json:"queue"`
+	Webhooks struct {
+		Enabled          bool `mapstructure:"enabled" json:"enabled"`
+		Timeout          int  `mapstructure:"timeout" json:"timeout"`
+		MaxAttempts      int  `mapstructure:"max_attempts" json:"max_attempts"`
+		RetryBackoff     int  `mapstructure:"retry_backoff_ms" json:"retry_backoff_ms"`
+		Workers          int  `mapstructure:"workers" json:"workers"`
+		DeadLetterLimit  int  `mapstructure:"dead_letter_limit" json:"dead_letter_limit"`
+		CircuitThreshold int  `mapstructure:"circuit_threshold" json:"circuit_threshold"`
+		CircuitCooldown  int  `mapstructure:"circuit_cooldown" json:"circuit_cooldown"`
+		DeliveryLogLimit int  `mapstructure:"delivery_log_limit" json:"delivery_log_limit"`
+	} `mapstructure:"webhooks" json:"webhooks"`
+	Events struct {
+		Enabled       bool     `mapstructure:"enabled" json:"enabled"`
+		Driver        string   `mapstructure:"driver" json:"driver"`
+		URL           string   `mapstructure:"url" json:"url"`
+		Token         string   `mapstructure:"token" json:"token"`
+		SASLMechanism string   `mapstructure:"sasl_mechanism" json:"sasl_mechanism"`
+		Topic         string   `mapstructure:"topic" json:"topic"`
+		Types         []string `mapstructure:"types" json:"types"`
+		Delivery      string   `mapstructure:"delivery" json:"delivery"`
+		AckTimeout    int      `mapstructure:"ack_timeout" json:"ack_timeout"`
+		BufferSize    int      `mapstructure:"buffer_size" json:"buffer_size"`
+		RetryBackoff  int      `mapstructure:"retry_backoff_ms" json:"retry_backoff_ms"`
+	} `mapstructure:"events" json:"events"`
+	GRPC struct {
+		Enabled         bool `mapstructure:"enabled" json:"enabled"`
+		Port            int  `mapstructure:"port" json:"port"`
+		MaxMessageBytes int  `mapstructure:"max_message_bytes" json:"max_message_bytes"`
+		Reflection      bool `mapstructure:"reflection" json:"reflection"`
+	} `mapstructure:"grpc" json:"grpc"`
+	Scheduler struct {
+		Enabled bool           `mapstructure:"enabled" json:"enabled"`
+		Jobs    []ScheduledJob `mapstructure:"jobs" json:"jobs"`
+	} `mapstructure:"scheduler" json:"scheduler"`
+	Plugins struct {
+		Rules []RulePlugin `mapstructure:"rules" json:"rules"`
+	} `mapstructure:"plugins" json:"plugins"`
+	Secrets struct {
+		CacheTTL        int  `mapstructure:"cache_ttl" json:"cache_ttl"`
+		Timeout         int  `mapstructure:"timeout" json:"timeout"`
+		RotationGrace   int  `mapstructure:"rotation_grace" json:"rotation_grace"`
+		RefreshInterval int  `mapstructure:"refresh_interval" json:"refresh_interval"`
+		WatchFiles      bool `mapstructure:"watch_files" json:"watch_files"`
+		Vault           struct {
+			Address   string `mapstructure:"address" json:"address"`
+			Token     string `mapstructure:"token" json:"token"`
+			Namespace string `mapstructure:"namespace" json:"namespace"`
+		} `mapstructure:"vault" json:"vault"`
+		AWS struct {
+			Region   string `mapstructure:"region" json:"region"`
+			Endpoint string `mapstructure:"endpoint" json:"endpoint"`
+		} `mapstructure:"aws" json:"aws"`
+	} `mapstructure:"secrets" json:"secrets"`
+}
+
+// redactedValue replaces secret values in redacted configuration output
+const redactedValue = "[REDACTED]"
+
+// ConfigFileEnv is the environment variable naming a configuration file
+const ConfigFileEnv = "CONFIG_SERVICE_CONFIG_FILE"
+
+// loadOptions configures Load
+type loadOptions struct {
+	file  string
+	flags *pflag.FlagSet
+}
+
+// LoadOption defines functional options for Load
+type LoadOption func(*loadOptions)
+
+// WithConfigFile reads configuration from a YAML, JSON, or TOML file, taking
+// precedence over ConfigFileEnv. An empty path is ignored.
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		if path != "" {
+			o.file = path
+		}
+	}
+}
+
+// WithFlags takes values from command-line flags named after configuration
+// keys (e.g. --breach.timeout), which take precedence over all other sources
+func WithFlags(flags *pflag.FlagSet) LoadOption {
+	return func(o *loadOptions) {
+		o.flags = flags
+	}
+}
+
+// Load loads the configuration. Values are taken, in order of precedence, from
+// command-line flags, environment variables, the configuration file, and defaults.
+func Load(options ...LoadOption) (*Config, error) {
+	opts := loadOptions{file: os.Getenv(ConfigFileEnv)}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	setSecretStoreDefaults(v)
+
+	// Bind every key to its environment variable, e.g. breach.api_endpoint to
+	// CONFIG_SERVICE_BREACH_API_ENDPOINT
+	v.SetEnvPrefix("CONFIG_SERVICE")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	for _, key := range Keys() {
+		if err := v.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("failed to bind environment variable for %s: %w", key, err)
+		}
+	}
+
+	// Bind command-line flags; only flags that were set override other sources
+	if opts.flags != nil {
+		for _, key := range Keys() {
+			if flag := opts.flags.Lookup(key); flag != nil {
+				if err := v.BindPFlag(key, flag); err != nil {
+					return nil, fmt.Errorf("failed to bind flag for %s: %w", key, err)
+				}
+			}
+		}
+	}
+
+	// Read the configuration file, if any, and merge the overlay for the
+	// environment on top of it
+	var files []string
+	if opts.file != "" {
+		if err := readConfigFile(v, opts.file, false); err != nil {
+			return nil, err
+		}
+		files = append(files, opts.file)
+
+		if overlay := OverlayFile(opts.file, v.GetString("server.env")); overlay != "" {
+			if _, err := os.Stat(overlay); err == nil {
+				if err := readConfigFile(v, overlay, true); err != nil {
+					return nil, err
+				}
+				files = append(files, overlay)
+			}
+		}
+	}
 
 	// Create config instance
 	var cfg Config
 
 	// Unmarshal configuration
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	cfg.Files = files
+
+	// Replace secret references with the values they point at
+	if err := resolveSecrets(&cfg); err != nil {
+		return nil, err
+	}
 
 	// Validate configuration
 	if err := validateConfig(&cfg); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	if cfg.Strict {
+		if err := validateStrict(&cfg, unknownSettings(v)); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+
 	return &cfg, nil
 }
 
-// validateConfig validates the configuration values
+// Keys returns the dotted name of every configuration key, derived from the
+// mapstructure tags of Config
+func Keys() []string {
+	return structKeys(reflect.TypeOf(Config{}), "")
+}
+
+// structKeys returns the dotted keys of the fields of a struct type
+func structKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, structKeys(field.Type, prefix+name+".")...)
+			continue
+		}
+		keys = append(keys, prefix+name)
+	}
+	return keys
+}
+
+// EnvVar returns the environment variable that sets a configuration key
+func EnvVar(key string) string {
+	return "CONFIG_SERVICE_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// setDefaults sets the default for every configuration key
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("strict", false)
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.env", "development")
+	v.SetDefault("server.max_in_flight", 0)
+	v.SetDefault("server.shed_retry_after", 1)
+	v.SetDefault("server.tcp_enabled", true)
+	v.SetDefault("server.unix_socket", "")
+	v.SetDefault("server.unix_socket_mode", "0660")
+	v.SetDefault("server.admin_host", "")
+	v.SetDefault("server.admin_port", 0)
+	v.SetDefault("server.pprof", false)
+	v.SetDefault("server.upgrades", false)
+	v.SetDefault("server.upgrade_timeout", 60)
+	v.SetDefault("server.shutdown_timeout", 30)
+	v.SetDefault("server.pid_file", "")
+	v.SetDefault("server.read_timeout", 15)
+	v.SetDefault("server.read_header_timeout", 5)
+	v.SetDefault("server.write_timeout", 30)
+	v.SetDefault("server.idle_timeout", 60)
+	v.SetDefault("server.max_header_bytes", 1<<20)
+	v.SetDefault("server.trusted_proxies", []string{})
+	v.SetDefault("server.client_ip_header", clientip.HeaderXForwardedFor)
+	v.SetDefault("server.strict_schema", false)
+	v.SetDefault("tls.enabled", false)
+	v.SetDefault("tls.cert_file", "")
+	v.SetDefault("tls.key_file", "")
+	v.SetDefault("tls.min_version", "1.2")
+	v.SetDefault("tls.cipher_suites", []string{})
+	v.SetDefault("tls.auto_reload", true)
+	v.SetDefault("tls.client_auth", tlsutil.ClientAuthNone)
+	v.SetDefault("tls.client_ca_file", "")
+	v.SetDefault("tls.client_allowed_names", []string{})
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", logging.FormatJSON)
+	v.SetDefault("logging.access_format", "")
+	v.SetDefault("logging.access_fields", logging.AccessLogFields)
+	v.SetDefault("logging.access_sample_success", 1.0)
+	v.SetDefault("logging.access_sample_client_error", 1.0)
+	v.SetDefault("logging.access_sample_server_error", 1.0)
+	v.SetDefault("logging.request_bodies", false)
+	v.SetDefault("logging.request_body_max_bytes", 4096)
+	v.SetDefault("logging.slow_request_ms", 1000)
+	v.SetDefault("logging.file.path", "")
+	v.SetDefault("logging.file.max_size_mb", 100)
+	v.SetDefault("logging.file.rotate_interval", 24)
+	v.SetDefault("logging.file.max_backups", 7)
+	v.SetDefault("logging.file.compress", true)
+	v.SetDefault("logging.file.stdout", true)
+	v.SetDefault("metrics.backend", metrics.BackendNone)
+	v.SetDefault("metrics.namespace", "config_service")
+	v.SetDefault("metrics.prometheus_path", "/metrics")
+	v.SetDefault("metrics.statsd_address", "127.0.0.1:8125")
+	v.SetDefault("metrics.dogstatsd_tags", true)
+	v.SetDefault("health.timeout_ms", 2000)
+	v.SetDefault("health.hibp_timeout_ms", 1000)
+	v.SetDefault("health.hibp_cache_ttl", 30)
+	v.SetDefault("password.max_length", 128)
+	v.SetDefault("password.generate_length", 20)
+	v.SetDefault("password.passphrase_words", 6)
+	v.SetDefault("password.memo_enabled", false)
+	v.SetDefault("password.memo_ttl", 10)
+	v.SetDefault("password.memo_max_entries", 10000)
+	v.SetDefault("password.dictionaries", []string{})
+	v.SetDefault("password.self_test", false)
+	v.SetDefault("password.self_test_expectations", "")
+	v.SetDefault("password.self_test_fail_on_drift", true)
+	v.SetDefault("password.decoys", "")
+	v.SetDefault("password.policies", []NamedPolicy{})
+	v.SetDefault("breach.enabled", true)
+	v.SetDefault("breach.api_endpoint", "https://api.pwnedpasswords.com/range")
+	v.SetDefault("breach.timeout", 10)
+	v.SetDefault("breach.cache_duration", 60)
+	v.SetDefault("breach.max_in_flight", 0)
+	v.SetDefault("breach.max_retries", 1)
+	v.SetDefault("breach.retry_backoff_ms", 100)
+	v.SetDefault("breach.circuit_threshold", 5)
+	v.SetDefault("breach.circuit_cooldown", 30)
+	v.SetDefault("breach.max_idle_conns_per_host", 64)
+	v.SetDefault("breach.max_conns_per_host", 0)
+	v.SetDefault("breach.idle_conn_timeout", 90)
+	v.SetDefault("breach.keep_alive", 30)
+	v.SetDefault("breach.tls_session_cache", 64)
+	v.SetDefault("breach.offline_sha1_path", "")
+	v.SetDefault("breach.offline_ntlm_path", "")
+	v.SetDefault("breach.chaos.enabled", false)
+	v.SetDefault("breach.chaos.timeout_rate", 0)
+	v.SetDefault("breach.chaos.rate_limit_rate", 0)
+	v.SetDefault("breach.chaos.malformed_rate", 0)
+	v.SetDefault("breach.chaos.slow_rate", 0)
+	v.SetDefault("breach.chaos.slow_delay_ms", 2000)
+	v.SetDefault("throttle.enabled", false)
+	v.SetDefault("throttle.threshold", 30)
+	v.SetDefault("throttle.base_delay_ms", 250)
+	v.SetDefault("throttle.max_delay_ms", 8000)
+	v.SetDefault("throttle.captcha_verify_url", "")
+	v.SetDefault("throttle.captcha_secret", "")
+	v.SetDefault("throttle.captcha_timeout", 5)
+	v.SetDefault("throttle.max_clients", 100000)
+	v.SetDefault("usage.enabled", false)
+	v.SetDefault("usage.monthly_quota", 0)
+	v.SetDefault("usage.tenant_quotas", []string{})
+	v.SetDefault("analytics.enabled", true)
+	v.SetDefault("analytics.retention_days", 90)
+	v.SetDefault("analytics.min_checks", 10)
+	v.SetDefault("bulk.workers", 8)
+	v.SetDefault("bulk.queue_size", 100)
+	v.SetDefault("bulk.item_timeout_ms", 0)
+	v.SetDefault("bulk.max_per_request", 4)
+	v.SetDefault("bulk.max_body_bytes", 1<<20)
+	v.SetDefault("runtime.memory_limit_mb", 0)
+	v.SetDefault("runtime.gc_percent", 0)
+	v.SetDefault("runtime.max_mapped_datasets", 0)
+	v.SetDefault("datasets.checksums", dataset.ChecksumsOptional)
+	v.SetDefault("datasets.signing_key", "")
+	v.SetDefault("runtime.deterministic", false)
+	v.SetDefault("runtime.seed", 1)
+	v.SetDefault("recovery.webhook_url", "")
+	v.SetDefault("recovery.webhook_timeout", 5)
+	v.SetDefault("error_reporting.backend", errorreport.BackendNone)
+	v.SetDefault("error_reporting.dsn", "")
+	v.SetDefault("error_reporting.webhook_url", "")
+	v.SetDefault("error_reporting.environment", "")
+	v.SetDefault("error_reporting.timeout", 5)
+	v.SetDefault("admin.token", "")
+	v.SetDefault("admin.idempotency_ttl", 86400)
+	v.SetDefault("admin.deleted_retention_days", 30)
+	v.SetDefault("admin.banned_import_max_bytes", 536870912)
+	v.SetDefault("admin.banned_import_dir", "")
+	v.SetDefault("auth.api_keys_enabled", false)
+	v.SetDefault("auth.jwt.enabled", false)
+	v.SetDefault("auth.jwt.issuer", "")
+	v.SetDefault("auth.jwt.audience", "")
+	v.SetDefault("auth.jwt.jwks_url", "")
+	v.SetDefault("auth.jwt.clock_skew", 60)
+	v.SetDefault("auth.jwt.jwks_refresh_interval", 15)
+	v.SetDefault("auth.jwt.tenant_claim", "tenant")
+	v.SetDefault("auth.jwt.check_scope", "")
+	v.SetDefault("auth.jwt.admin_scope", "")
+	v.SetDefault("audit.enabled", false)
+	v.SetDefault("audit.sink", "file")
+	v.SetDefault("audit.path", "audit.log")
+	v.SetDefault("audit.syslog_tag", "config-service")
+	v.SetDefault("audit.retention_days", 90)
+	v.SetDefault("auth.hmac.enabled", false)
+	v.SetDefault("auth.hmac.keys", []string{})
+	v.SetDefault("auth.hmac.window", 300)
+	v.SetDefault("auth.hmac.max_body_bytes", 1<<20)
+	v.SetDefault("auth.hmac.replay_cache_size", 100000)
+	v.SetDefault("auth.hmac.require_nonce", false)
+	v.SetDefault("attestation.enabled", false)
+	v.SetDefault("attestation.key_file", "")
+	v.SetDefault("attestation.issuer", "config-service")
+	v.SetDefault("attestation.audience", "")
+	v.SetDefault("attestation.ttl", 300)
+	v.SetDefault("reuse.enabled", false)
+	v.SetDefault("reuse.max_accounts", 100000)
+	v.SetDefault("i18n.default_locale", i18n.DefaultLocale)
+	v.SetDefault("admission.enabled", false)
+	v.SetDefault("admission.min_strength", string(strength.Strong))
+	v.SetDefault("admission.reject_breached", true)
+	v.SetDefault("idp.enabled", false)
+	v.SetDefault("idp.min_strength", string(strength.Medium))
+	v.SetDefault("idp.reject_breached", true)
+	v.SetDefault("idp.require_generated", false)
+	v.SetDefault("ad_filter.enabled", false)
+	v.SetDefault("ad_filter.latency_budget_ms", 500)
+	v.SetDefault("ad_filter.fail_open", true)
+	v.SetDefault("ad_filter.reject_breached", true)
+	v.SetDefault("ad_filter.max_banned_variants", 500000)
+	v.SetDefault("queue.enabled", false)
+	v.SetDefault("queue.driver", "nats")
+	v.SetDefault("queue.url", "nats://127.0.0.1:4222")
+	v.SetDefault("queue.token", "")
+	v.SetDefault("queue.request_topic", "passwords.check")
+	v.SetDefault("queue.reply_topic", "passwords.results")
+	v.SetDefault("queue.group", "config-service")
+	v.SetDefault("queue.workers", 4)
+	v.SetDefault("webhooks.enabled", false)
+	v.SetDefault("webhooks.timeout", 5)
+	v.SetDefault("webhooks.max_attempts", 5)
+	v.SetDefault("webhooks.retry_backoff_ms", 1000)
+	v.SetDefault("webhooks.workers", 2)
+	v.SetDefault("webhooks.dead_letter_limit", 1000)
+	v.SetDefault("webhooks.circuit_threshold", 5)
+	v.SetDefault("webhooks.circuit_cooldown", 30)
+	v.SetDefault("webhooks.delivery_log_limit", 100)
+	v.SetDefault("events.enabled", false)
+	v.SetDefault("events.driver", "nats")
+	v.SetDefault("events.url", "nats://127.0.0.1:4222")
+	v.SetDefault("events.token", "")
+	v.SetDefault("events.sasl_mechanism", queue.SASLPlain)
+	v.SetDefault("events.topic", "passwords.events")
+	v.SetDefault("events.types", []string{})
+	v.SetDefault("events.delivery", string(queue.AtLeastOnce))
+	v.SetDefault("events.ack_timeout", 5)
+	v.SetDefault("events.buffer_size", 1000)
+	v.SetDefault("events.retry_backoff_ms", 1000)
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.port", 9090)
+	v.SetDefault("grpc.max_message_bytes", 1<<20)
+	v.SetDefault("grpc.reflection", false)
+	v.SetDefault("scheduler.enabled", false)
+	v.SetDefault("scheduler.jobs", []ScheduledJob{})
+	v.SetDefault("plugins.rules", []RulePlugin{})
+	v.SetDefault("secrets.cache_ttl", 300)
+	v.SetDefault("secrets.timeout", 5)
+	v.SetDefault("secrets.rotation_grace", 300)
+	v.SetDefault("secrets.refresh_interval", 0)
+	v.SetDefault("secrets.watch_files", true)
+	v.SetDefault("secrets.vault.address", "")
+	v.SetDefault("secrets.vault.token", "")
+	v.SetDefault("secrets.vault.namespace", "")
+	v.SetDefault("secrets.aws.region", "")
+	v.SetDefault("secrets.aws.endpoint", "")
+}
+
+// OverlayFile returns the overlay for a configuration file in an environment,
+// e.g. config.production.yaml for config.yaml in production, or "" when the
+// environment is empty
+func OverlayFile(path, env string) string {
+	if env == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// setSecretStoreDefaults takes the secret store defaults from the standard
+// Vault and AWS environment variables
+func setSecretStoreDefaults(v *viper.Viper) {
+	v.SetDefault("secrets.vault.address", os.Getenv("VAULT_ADDR"))
+	v.SetDefault("secrets.vault.token", os.Getenv("VAULT_TOKEN"))
+	v.SetDefault("secrets.vault.namespace", os.Getenv("VAULT_NAMESPACE"))
+	v.SetDefault("secrets.aws.region", os.Getenv("AWS_REGION"))
+}
+
+// readConfigFile reads a configuration file into v, or merges it into the
+// values already read, with errors that name the file and explain what is
+// wrong with it
+func readConfigFile(v *viper.Viper, path string, merge bool) error {
+	switch ext := strings.TrimPrefix(filepath.Ext(path), "."); ext {
+	case "yaml", "yml", "json", "toml":
+	default:
+		return fmt.Errorf("unsupported config file format %q for %s: use .yaml, .yml, .json, or .toml", ext, path)
+	}
+
+	read := v.ReadInConfig
+	if merge {
+		read = v.MergeInConfig
+	}
+
+	v.SetConfigFile(path)
+	if err := read(); err != nil {
+		var parseErr viper.ConfigParseError
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("config file %s not found", path)
+		case errors.As(err, &parseErr):
+			return fmt.Errorf("malformed config file %s: %v", path, errors.Unwrap(parseErr))
+		default:
+			return fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the configuration values
+func (c *Config) Validate() error {
+	return validateConfig(c)
+}
+
+// maxBreachCacheDuration is the longest breach result cache duration in
+// minutes; HIBP adds breaches often enough that a week-old answer is stale
+const maxBreachCacheDuration = 7 * 24 * 60
+
+// ValidationError lists every problem found while validating a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns all problems on one line
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// validateEndpoint checks that an endpoint is an absolute HTTP or HTTPS URL
+func validateEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http or https URL", endpoint)
+	}
+	return nil
+}
+
+// validateConfig validates the configuration values, reporting every problem
+// rather than only the first
 func validateConfig(cfg *Config) error {
+	var problems ValidationError
+	add := func(err error) {
+		problems.Problems = append(problems.Problems, err.Error())
+	}
+
 	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
-		return fmt.Errorf("invalid port: %d", cfg.Server.Port)
+		add(fmt.Errorf("invalid port: %d", cfg.Server.Port))
+	}
+
+	if cfg.Server.AdminPort < 0 || cfg.Server.AdminPort > 65535 {
+		add(fmt.Errorf("invalid admin port: %d", cfg.Server.AdminPort))
+	} else if cfg.Server.AdminPort != 0 && cfg.Server.AdminPort == cfg.Server.Port {
+		add(fmt.Errorf("server.admin_port must differ from server.port"))
+	}
+
+	if cfg.Server.Pprof && cfg.Server.AdminPort == 0 {
+		add(fmt.Errorf("server.pprof requires server.admin_port, so profiles are never served on the public listener"))
+	}
+
+	if cfg.Server.UpgradeTimeout <= 0 || cfg.Server.ShutdownTimeout <= 0 {
+		add(fmt.Errorf("server.upgrade_timeout and server.shutdown_timeout must be positive"))
+	}
+
+	if !cfg.Server.TCPEnabled && cfg.Server.UnixSocket == "" {
+		add(fmt.Errorf("server.unix_socket is required when the TCP listener is disabled"))
+	}
+
+	if cfg.Server.UnixSocket != "" {
+		if _, err := server.ParseSocketMode(cfg.Server.UnixSocketMode); err != nil {
+			add(err)
+		}
+	}
+
+	if _, err := clientip.New(cfg.Server.TrustedProxies, cfg.Server.ClientIPHeader); err != nil {
+		add(err)
+	}
+
+	if cfg.Server.ReadTimeout < 0 || cfg.Server.ReadHeaderTimeout < 0 || cfg.Server.WriteTimeout < 0 || cfg.Server.IdleTimeout < 0 {
+		add(fmt.Errorf("server timeouts must not be negative"))
+	}
+
+	if cfg.Server.MaxHeaderBytes <= 0 {
+		add(fmt.Errorf("invalid max header bytes: %d", cfg.Server.MaxHeaderBytes))
+	}
+
+	if cfg.Server.MaxInFlight < 0 || cfg.Breach.MaxInFlight < 0 {
+		add(fmt.Errorf("max in-flight limits must not be negative"))
+	}
+
+	if cfg.TLS.Enabled {
+		if cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "" {
+			add(fmt.Errorf("tls.cert_file and tls.key_file are required when TLS is enabled"))
+		}
+		if _, err := tlsutil.ParseMinVersion(cfg.TLS.MinVersion); err != nil {
+			add(err)
+		}
+		if _, err := tlsutil.ParseCipherSuites(cfg.TLS.CipherSuites); err != nil {
+			add(err)
+		}
+		if _, err := tlsutil.ParseClientAuth(cfg.TLS.ClientAuth); err != nil {
+			add(err)
+		}
+		if cfg.TLS.ClientAuth != tlsutil.ClientAuthNone && cfg.TLS.ClientAuth != "" && cfg.TLS.ClientCAFile == "" {
+			add(fmt.Errorf("tls.client_ca_file is required when client authentication is enabled"))
+		}
+	}
+
+	if _, err := logrus.ParseLevel(cfg.Logging.Level); err != nil {
+		add(fmt.Errorf("invalid log level: %s", cfg.Logging.Level))
+	}
+
+	if _, err := logging.NewFormatter(cfg.Logging.Format); err != nil {
+		add(err)
+	}
+
+	if _, err := logging.NewFormatter(cfg.Logging.AccessFormat); err != nil {
+		add(err)
+	}
+
+	if err := logging.ValidateAccessLogFields(cfg.Logging.AccessFields); err != nil {
+		add(err)
+	}
+
+	for _, rate := range []float64{cfg.Logging.AccessSampleSuccess, cfg.Logging.AccessSampleClient, cfg.Logging.AccessSampleServer} {
+		if err := logging.ValidateSampleRate(rate); err != nil {
+			add(err)
+		}
+	}
+
+	if cfg.Logging.File.MaxSizeMB < 0 || cfg.Logging.File.RotateInterval < 0 || cfg.Logging.File.MaxBackups < 0 {
+		add(fmt.Errorf("logging.file rotation settings must not be negative"))
+	}
+
+	if cfg.Logging.SlowRequestMS < 0 {
+		add(fmt.Errorf("logging.slow_request_ms must not be negative"))
+	}
+
+	if cfg.Health.TimeoutMS <= 0 || cfg.Health.HIBPTimeoutMS <= 0 {
+		add(fmt.Errorf("health check timeouts must be positive"))
+	}
+
+	if cfg.Health.HIBPCacheTTL < 0 {
+		add(fmt.Errorf("health.hibp_cache_ttl must not be negative"))
+	}
+
+	if err := metrics.ValidateBackend(cfg.Metrics.Backend); err != nil {
+		add(err)
+	}
+
+	if err := errorreport.ValidateBackend(cfg.ErrorReporting.Backend); err != nil {
+		add(err)
+	}
+
+	if cfg.ErrorReporting.Backend == errorreport.BackendSentry {
+		if _, _, err := errorreport.ParseDSN(cfg.ErrorReporting.DSN); err != nil {
+			add(err)
+		}
+	}
+
+	if cfg.ErrorReporting.Backend == errorreport.BackendWebhook && cfg.ErrorReporting.WebhookURL == "" {
+		add(fmt.Errorf("error_reporting.webhook_url is required for the webhook backend"))
+	}
+
+	if cfg.Password.MaxLength < strength.DefaultMinLength {
+		add(fmt.Errorf("invalid max password length: %d (must be at least %d)", cfg.Password.MaxLength, strength.DefaultMinLength))
+	}
+
+	if cfg.Password.GenerateLength < strength.DefaultMinLength || cfg.Password.GenerateLength > cfg.Password.MaxLength {
+		add(fmt.Errorf("password.generate_length must be between %d and password.max_length, got %d", strength.DefaultMinLength, cfg.Password.GenerateLength))
+	}
+
+	if cfg.Password.PassphraseWords < services.MinPassphraseWords || cfg.Password.PassphraseWords > services.MaxPassphraseWords {
+		add(fmt.Errorf("password.passphrase_words must be between %d and %d, got %d", services.MinPassphraseWords, services.MaxPassphraseWords, cfg.Password.PassphraseWords))
+	} else if longest := services.LongestPassphrase(cfg.Password.PassphraseWords, "-"); longest > cfg.Password.MaxLength {
+		add(fmt.Errorf("password.passphrase_words: %d words can make passphrases of %d characters, longer than password.max_length", cfg.Password.PassphraseWords, longest))
+	}
+
+	if cfg.Password.SelfTest && cfg.Password.SelfTestExpectations == "" {
+		add(fmt.Errorf("password.self_test_expectations is required when the scoring self-test is enabled"))
+	}
+
+	if cfg.Password.MemoEnabled && (cfg.Password.MemoTTL < 1 || cfg.Password.MemoTTL > 300 || cfg.Password.MemoMaxEntries <= 0) {
+		add(fmt.Errorf("password.memo_ttl must be between 1 and 300 seconds and password.memo_max_entries positive"))
+	}
+
+	if cfg.Throttle.Enabled && (cfg.Throttle.Threshold <= 0 || cfg.Throttle.BaseDelay <= 0 || cfg.Throttle.MaxDelay < cfg.Throttle.BaseDelay) {
+		add(fmt.Errorf("throttle.threshold and throttle.base_delay_ms must be positive and throttle.max_delay_ms at least the base delay"))
+	}
+	if cfg.Throttle.Enabled && cfg.Throttle.MaxClients < 1 {
+		add(fmt.Errorf("throttle.max_clients must be at least 1"))
+	}
+
+	if cfg.Breach.Enabled {
+		if err := validateEndpoint(cfg.Breach.APIEndpoint); err != nil {
+			add(fmt.Errorf("invalid breach.api_endpoint: %w", err))
+		}
+	}
+
+	if cfg.Breach.Timeout <= 0 {
+		add(fmt.Errorf("breach.timeout must be positive, got %d", cfg.Breach.Timeout))
+	}
+
+	if cfg.Breach.CacheDuration < 0 || cfg.Breach.CacheDuration > maxBreachCacheDuration {
+		add(fmt.Errorf("breach.cache_duration must be between 0 and %d minutes, got %d", maxBreachCacheDuration, cfg.Breach.CacheDuration))
+	}
+
+	if cfg.Breach.MaxRetries < 0 || cfg.Breach.RetryBackoff < 0 || cfg.Breach.CircuitThreshold < 0 || cfg.Breach.CircuitCooldown < 0 {
+		add(fmt.Errorf("breach retry and circuit breaker settings must not be negative"))
+	}
+
+	if cfg.Breach.MaxIdleConnsPerHost < 0 || cfg.Breach.MaxConnsPerHost < 0 || cfg.Breach.IdleConnTimeout < 0 || cfg.Breach.KeepAlive < 0 || cfg.Breach.TLSSessionCache < 0 {
+		add(fmt.Errorf("breach connection pool settings must not be negative"))
+	}
+
+	if chaos := cfg.Breach.Chaos; chaos.Enabled {
+		rates := []float64{chaos.TimeoutRate, chaos.RateLimitRate, chaos.MalformedRate, chaos.SlowRate}
+		total := 0.0
+		for _, rate := range rates {
+			if rate < 0 || rate > 1 {
+				add(fmt.Errorf("breach.chaos rates must be between 0 and 1"))
+				break
+			}
+			total += rate
+		}
+		if total > 1 {
+			add(fmt.Errorf("breach.chaos rates must add up to at most 1, got %g", total))
+		}
+		if chaos.SlowDelay < 0 {
+			add(fmt.Errorf("breach.chaos.slow_delay_ms must not be negative"))
+		}
+		if cfg.Server.Env == "production" {
+			add(fmt.Errorf("breach.chaos must not be enabled in production"))
+		}
+	}
+
+	if cfg.Usage.MonthlyQuota < 0 {
+		add(fmt.Errorf("usage.monthly_quota must not be negative"))
+	}
+
+	if cfg.Analytics.RetentionDays <= 0 || cfg.Analytics.MinChecks < 0 {
+		add(fmt.Errorf("analytics.retention_days must be positive and analytics.min_checks must not be negative"))
+	}
+
+	if cfg.Admin.IdempotencyTTL <= 0 || cfg.Admin.DeletedRetentionDays <= 0 {
+		add(fmt.Errorf("admin.idempotency_ttl and admin.deleted_retention_days must be positive"))
+	}
+
+	if cfg.Admin.BannedImportMaxBytes < 0 {
+		add(fmt.Errorf("admin.banned_import_max_bytes must not be negative"))
+	}
+
+	if cfg.Bulk.Workers <= 0 || cfg.Bulk.QueueSize < 0 || cfg.Bulk.ItemTimeout < 0 {
+		add(fmt.Errorf("bulk.workers must be positive and bulk.queue_size and bulk.item_timeout_ms must not be negative"))
+	}
+
+	if cfg.Bulk.MaxPerRequest < 0 || cfg.Bulk.MaxBodyBytes < 0 {
+		add(fmt.Errorf("bulk.max_per_request and bulk.max_body_bytes must not be negative"))
+	}
+
+	if cfg.Runtime.MemoryLimitMB < 0 || cfg.Runtime.GCPercent < -1 || cfg.Runtime.MaxMappedDatasets < 0 {
+		add(fmt.Errorf("runtime.memory_limit_mb and runtime.max_mapped_datasets must not be negative and runtime.gc_percent must be at least -1"))
+	}
+
+	if cfg.Runtime.Deterministic && cfg.Server.Env == "production" {
+		add(fmt.Errorf("runtime.deterministic must not be enabled in production"))
+	}
+
+	if err := dataset.ValidateChecksumMode(cfg.Datasets.Checksums); err != nil {
+		add(err)
+	}
+
+	mapped := len(cfg.Password.Dictionaries)
+	for _, path := range []string{cfg.Breach.OfflineSHA1Path, cfg.Breach.OfflineNTLMPath} {
+		if path != "" {
+			mapped++
+		}
+	}
+	if cfg.Runtime.MaxMappedDatasets > 0 && mapped > cfg.Runtime.MaxMappedDatasets {
+		add(fmt.Errorf("%d datasets are configured but runtime.max_mapped_datasets is %d", mapped, cfg.Runtime.MaxMappedDatasets))
+	}
+
+	if _, err := services.ParseTenantQuotas(cfg.Usage.TenantQuotas); err != nil {
+		add(err)
 	}
 
-	if cfg.Password.MaxLength <= 0 {
-		return fmt.Errorf("invalid max password length: %d", cfg.Password.MaxLength)
+	if cfg.Auth.JWT.Enabled && cfg.Auth.JWT.Issuer == "" {
+		add(fmt.Errorf("auth.jwt.issuer is required when JWT authentication is enabled"))
+	}
+	if cfg.Auth.JWT.Enabled && cfg.Auth.JWT.Audience == "" {
+		add(fmt.Errorf("auth.jwt.audience is required when JWT authentication is enabled"))
+	}
+
+	if cfg.Auth.HMAC.Enabled {
+		if keys, err := auth.ParseHMACKeys(cfg.Auth.HMAC.Keys); err != nil {
+			add(err)
+		} else if len(keys) == 0 {
+			add(fmt.Errorf("auth.hmac.keys is required when HMAC request signing is enabled"))
+		}
+		if cfg.Auth.HMAC.Window <= 0 || cfg.Auth.HMAC.MaxBodyBytes <= 0 || cfg.Auth.HMAC.ReplayCacheSize <= 0 {
+			add(fmt.Errorf("auth.hmac.window, auth.hmac.max_body_bytes, and auth.hmac.replay_cache_size must be positive"))
+		}
+	}
+
+	if cfg.Attestation.Enabled {
+		if cfg.Attestation.KeyFile == "" || cfg.Attestation.Issuer == "" {
+			add(fmt.Errorf("attestation.key_file and attestation.issuer are required when attestation tokens are enabled"))
+		}
+		if cfg.Attestation.TTL < 10 || cfg.Attestation.TTL > 3600 {
+			add(fmt.Errorf("attestation.ttl must be between 10 and 3600 seconds"))
+		}
+	}
+
+	if cfg.Reuse.Enabled && cfg.Reuse.MaxAccounts <= 0 {
+		add(fmt.Errorf("reuse.max_accounts must be positive"))
+	}
+
+	if cfg.Audit.Enabled && cfg.Audit.Sink != "file" && cfg.Audit.Sink != "syslog" {
+		add(fmt.Errorf("invalid audit sink: %s (must be \"file\" or \"syslog\")", cfg.Audit.Sink))
+	}
+
+	if cfg.Audit.RetentionDays < 0 {
+		add(fmt.Errorf("audit retention days must not be negative"))
+	}
+
+	if cfg.Secrets.CacheTTL < 0 || cfg.Secrets.Timeout <= 0 {
+		add(fmt.Errorf("secrets.cache_ttl must not be negative and secrets.timeout must be positive"))
+	}
+
+	if cfg.Secrets.RotationGrace < 0 || cfg.Secrets.RefreshInterval < 0 {
+		add(fmt.Errorf("secrets.rotation_grace and secrets.refresh_interval must not be negative"))
 	}
 
+	if !strength.Strength(cfg.Admission.MinStrength).Valid() {
+		add(fmt.Errorf("invalid admission min strength: %s (must be weak, medium, strong, or very_strong)", cfg.Admission.MinStrength))
+	}
+	// The webhook has no API key authentication, so the API server must
+	// present a client certificate or the webhook is kept off the public listener
+	clientCertRequired := cfg.TLS.Enabled && cfg.TLS.ClientAuth == tlsutil.ClientAuthRequired
+	if cfg.Admission.Enabled && !clientCertRequired && cfg.Server.AdminPort == 0 {
+		add(fmt.Errorf("admission.enabled requires tls.client_auth required, or server.admin_port to serve the webhook on the internal listener"))
+	}
+	if !strength.Strength(cfg.IdP.MinStrength).Valid() {
+		add(fmt.Errorf("invalid idp min strength: %s (must be weak, medium, strong, or very_strong)", cfg.IdP.MinStrength))
+	}
+
+	if cfg.ADFilter.Enabled && (cfg.ADFilter.LatencyBudgetMS < 10 || cfg.ADFilter.LatencyBudgetMS > 10000) {
+		add(fmt.Errorf("ad_filter.latency_budget_ms must be between 10 and 10000"))
+	}
+	if cfg.ADFilter.Enabled && cfg.ADFilter.MaxBannedVariants < 1 {
+		add(fmt.Errorf("ad_filter.max_banned_variants must be at least 1"))
+	}
+
+	if cfg.Queue.Enabled {
+		if cfg.Queue.Driver == "" || cfg.Queue.URL == "" {
+			add(fmt.Errorf("queue.driver and queue.url are required when the queue consumer is enabled"))
+		}
+		if cfg.Queue.RequestTopic == "" {
+			add(fmt.Errorf("queue.request_topic is required when the queue consumer is enabled"))
+		}
+		if cfg.Queue.Workers < 1 {
+			add(fmt.Errorf("queue.workers must be at least 1"))
+		}
+
+		// Kafka is only supported for publishing events, not for reading
+		// check requests
+		if cfg.Queue.Driver != "" && !queue.HasDriver(cfg.Queue.Driver) {
+			if queue.HasPublisher(cfg.Queue.Driver) {
+				add(fmt.Errorf("queue.driver %s can only publish events.driver events; the queue consumer supports %v", cfg.Queue.Driver, queue.Drivers()))
+			} else {
+				add(fmt.Errorf("unknown queue.driver %q (available: %v)", cfg.Queue.Driver, queue.Drivers()))
+			}
+		} else if parsed, err := url.Parse(cfg.Queue.URL); err == nil && (parsed.Scheme == "kafka" || parsed.Scheme == "kafka+tls") && !queue.HasDriver("kafka") {
+			add(fmt.Errorf("queue.url is a Kafka URL, but the queue consumer reads from NATS only; Kafka is supported for events.url"))
+		}
+	}
+
+	if cfg.Webhooks.Enabled {
+		if cfg.Webhooks.Timeout < 1 {
+			add(fmt.Errorf("webhooks.timeout must be at least 1 second"))
+		}
+		if cfg.Webhooks.MaxAttempts < 1 {
+			add(fmt.Errorf("webhooks.max_attempts must be at least 1"))
+		}
+		if cfg.Webhooks.RetryBackoff < 0 {
+			add(fmt.Errorf("webhooks.retry_backoff_ms must not be negative"))
+		}
+		if cfg.Webhooks.Workers < 1 {
+			add(fmt.Errorf("webhooks.workers must be at least 1"))
+		}
+		if cfg.Webhooks.DeadLetterLimit < 1 {
+			add(fmt.Errorf("webhooks.dead_letter_limit must be at least 1"))
+		}
+		if cfg.Webhooks.CircuitThreshold < 0 || cfg.Webhooks.CircuitCooldown < 0 {
+			add(fmt.Errorf("webhooks circuit breaker settings must not be negative"))
+		}
+		if cfg.Webhooks.DeliveryLogLimit < 1 {
+			add(fmt.Errorf("webhooks.delivery_log_limit must be at least 1"))
+		}
+	}
+
+	if cfg.Events.Enabled {
+		if cfg.Events.Driver == "" || cfg.Events.URL == "" {
+			add(fmt.Errorf("events.driver and events.url are required when the event stream is enabled"))
+		}
+		if cfg.Events.Topic == "" {
+			add(fmt.Errorf("events.topic is required when the event stream is enabled"))
+		}
+		if err := services.ValidateEventTypes(cfg.Events.Types); err != nil {
+			add(fmt.Errorf("invalid events.types: %w", err))
+		}
+		if delivery := queue.Delivery(cfg.Events.Delivery); delivery != queue.AtLeastOnce && delivery != queue.AtMostOnce {
+			add(fmt.Errorf("invalid events.delivery: %s (must be at_least_once or at_most_once)", cfg.Events.Delivery))
+		}
+		if cfg.Events.SASLMechanism != "" {
+			if err := queue.ValidateSASLMechanism(cfg.Events.SASLMechanism); err != nil {
+				add(fmt.Errorf("invalid events.sasl_mechanism: %w", err))
+			}
+		}
+		if cfg.Events.AckTimeout < 1 {
+			add(fmt.Errorf("events.ack_timeout must be at least 1 second"))
+		}
+		if cfg.Events.BufferSize < 1 {
+			add(fmt.Errorf("events.buffer_size must be at least 1"))
+		}
+		if cfg.Events.RetryBackoff < 1 || cfg.Events.RetryBackoff > 60000 {
+			add(fmt.Errorf("events.retry_backoff_ms must be between 1 and 60000, got %d", cfg.Events.RetryBackoff))
+		}
+	}
+
+	if cfg.GRPC.Enabled {
+		if cfg.GRPC.Port < 1 || cfg.GRPC.Port > 65535 {
+			add(fmt.Errorf("invalid grpc.port: %d", cfg.GRPC.Port))
+		} else if cfg.GRPC.Port == cfg.Server.Port || cfg.GRPC.Port == cfg.Server.AdminPort {
+			add(fmt.Errorf("grpc.port must differ from server.port and server.admin_port"))
+		}
+		if cfg.GRPC.MaxMessageBytes < 1 {
+			add(fmt.Errorf("grpc.max_message_bytes must be at least 1"))
+		}
+	}
+
+	if cfg.Scheduler.Enabled {
+		for _, problem := range validateScheduledJobs(cfg) {
+			add(problem)
+		}
+	}
+
+	for _, problem := range validateRulePlugins(cfg) {
+		add(problem)
+	}
+
+	for _, problem := range validatePolicies(cfg) {
+		add(problem)
+	}
+
+	if !i18n.IsSupported(cfg.I18n.DefaultLocale) {
+		add(fmt.Errorf("unsupported default locale: %s (supported: %v)", cfg.I18n.DefaultLocale, i18n.SupportedLocales()))
+	}
+
+	if len(problems.Problems) > 0 {
+		return &problems
+	}
 	return nil
 }
 
+// Redacted returns a copy of the configuration with secret values masked
+func (c *Config) Redacted() Config {
+	redacted := *c
+	if redacted.Admin.Token != "" {
+		redacted.Admin.Token = redactedValue
+	}
+	if redacted.Recovery.WebhookURL != "" {
+		redacted.Recovery.WebhookURL = redactedValue
+	}
+	if redacted.ErrorReporting.DSN != "" {
+		redacted.ErrorReporting.DSN = redactedValue
+	}
+	if redacted.ErrorReporting.WebhookURL != "" {
+		redacted.ErrorReporting.WebhookURL = redactedValue
+	}
+	if redacted.Throttle.CaptchaSecret != "" {
+		redacted.Throttle.CaptchaSecret = redactedValue
+	}
+	if redacted.Queue.URL != "" {
+		redacted.Queue.URL = redactURLCredentials(redacted.Queue.URL)
+	}
+	if redacted.Queue.Token != "" {
+		redacted.Queue.Token = redactedValue
+	}
+	if redacted.Events.URL != "" {
+		redacted.Events.URL = redactURLCredentials(redacted.Events.URL)
+	}
+	if redacted.Events.Token != "" {
+		redacted.Events.Token = redactedValue
+	}
+	if len(redacted.Plugins.Rules) > 0 {
+		plugins := make([]RulePlugin, len(redacted.Plugins.Rules))
+		copy(plugins, redacted.Plugins.Rules)
+		for i := range plugins {
+			if plugins[i].Token != "" {
+				plugins[i].Token = redactedValue
+			}
+		}
+		redacted.Plugins.Rules = plugins
+	}
+	if redacted.Secrets.Vault.Token != "" {
+		redacted.Secrets.Vault.Token = redactedValue
+	}
+	if len(redacted.Auth.HMAC.Keys) > 0 {
+		keys := make([]string, len(redacted.Auth.HMAC.Keys))
+		for i := range keys {
+			keys[i] = redactedValue
+		}
+		redacted.Auth.HMAC.Keys = keys
+	}
+	return redacted
+}
+
+// redactURLCredentials replaces the password of a URL, keeping the rest of it
+// readable
+func redactURLCredentials(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return redactedValue
+	}
+	if parsed.User == nil {
+		return rawURL
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), redactedValue)
+	} else {
+		parsed.User = url.User(redactedValue)
+	}
+	return parsed.String()
+}
+
 // GetEnv returns the current environment
 func GetEnv() string {
 	env := os.Getenv("CONFIG_SERVICE_ENV")
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// maxRulePluginTimeout bounds the timeout of a rule plugin, which every
+// password check waits for
+const maxRulePluginTimeout = 10000
+
+// RulePlugin configures an external rule plugin called over HTTP
+type RulePlugin struct {
+	// Name identifies the plugin in logs and metrics
+	Name string `mapstructure:"name" json:"name"`
+	// URL receives a POST for every password check
+	URL string `mapstructure:"url" json:"url"`
+	// Token is sent as a bearer token
+	Token string `mapstructure:"token" json:"token"`
+	// Timeout is the time the plugin has to answer in milliseconds
+	Timeout int `mapstructure:"timeout_ms" json:"timeout_ms"`
+	// FailClosed caps passwords at weak when the plugin fails instead of
+	// skipping it
+	FailClosed bool `mapstructure:"fail_closed" json:"fail_closed"`
+}
+
+// validateRulePlugins returns the problems of the configured rule plugins
+func validateRulePlugins(cfg *Config) []error {
+	var problems []error
+	names := make(map[string]bool)
+	for i, plugin := range cfg.Plugins.Rules {
+		label := fmt.Sprintf("plugins.rules[%d]", i)
+		if plugin.Name == "" {
+			problems = append(problems, fmt.Errorf("%s: name is required", label))
+		} else if names[plugin.Name] {
+			problems = append(problems, fmt.Errorf("%s: duplicate plugin name %q", label, plugin.Name))
+		}
+		names[plugin.Name] = true
+
+		if parsed, err := url.Parse(plugin.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Errorf("%s: url must be an absolute http or https URL", label))
+		}
+		if plugin.Timeout < 0 || plugin.Timeout > maxRulePluginTimeout {
+			problems = append(problems, fmt.Errorf("%s: timeout_ms must be between 0 and %d, got %d", label, maxRulePluginTimeout, plugin.Timeout))
+		}
+	}
+	return problems
+}
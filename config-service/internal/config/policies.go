@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// NamedPolicy configures a password policy that strength checks can select
+// by its ID
+type NamedPolicy struct {
+	// ID is the policy_id of strength checks
+	ID string `mapstructure:"id" json:"id"`
+	// Name describes the policy, by default its ID
+	Name string `mapstructure:"name" json:"name"`
+	// MinLength and MaxLength bound the length in characters; zero is 8
+	// and password.max_length respectively
+	MinLength        int  `mapstructure:"min_length" json:"min_length"`
+	MaxLength        int  `mapstructure:"max_length" json:"max_length"`
+	RequireUppercase bool `mapstructure:"require_uppercase" json:"require_uppercase"`
+	RequireLowercase bool `mapstructure:"require_lowercase" json:"require_lowercase"`
+	RequireNumbers   bool `mapstructure:"require_numbers" json:"require_numbers"`
+	RequireSpecial   bool `mapstructure:"require_special" json:"require_special"`
+	RequireGenerated bool `mapstructure:"require_generated" json:"require_generated"`
+	// BannedWords are words passwords must not contain, ignoring case
+	BannedWords []string `mapstructure:"banned_words" json:"banned_words"`
+	// MaxBreachCount is how often a password may have been seen in known
+	// breaches; breaches are not checked when it is not set
+	MaxBreachCount *int `mapstructure:"max_breach_count" json:"max_breach_count"`
+	// MinEntropyBits is the fewest bits of entropy a password must have
+	MinEntropyBits int `mapstructure:"min_entropy_bits" json:"min_entropy_bits"`
+}
+
+// Policy returns the password policy p configures
+func (p NamedPolicy) Policy() models.PasswordPolicy {
+	return models.PasswordPolicy{
+		ID:               p.ID,
+		Name:             p.Name,
+		MinLength:        p.MinLength,
+		MaxLength:        p.MaxLength,
+		RequireUppercase: p.RequireUppercase,
+		RequireLowercase: p.RequireLowercase,
+		RequireNumbers:   p.RequireNumbers,
+		RequireSpecial:   p.RequireSpecial,
+		RequireGenerated: p.RequireGenerated,
+		BannedWords:      p.BannedWords,
+		MaxBreachCount:   p.MaxBreachCount,
+		MinEntropyBits:   p.MinEntropyBits,
+	}
+}
+
+// Policies returns the configured named policies
+func (c *Config) Policies() []models.PasswordPolicy {
+	policies := make([]models.PasswordPolicy, len(c.Password.Policies))
+	for i, policy := range c.Password.Policies {
+		policies[i] = policy.Policy()
+	}
+	return policies
+}
+
+// validatePolicies returns the problems of the configured named policies
+func validatePolicies(cfg *Config) []error {
+	var problems []error
+	ids := make(map[string]bool)
+	for i, policy := range cfg.Password.Policies {
+		label := fmt.Sprintf("password.policies[%d]", i)
+		if ids[policy.ID] {
+			problems = append(problems, fmt.Errorf("%s: duplicate policy id %q", label, policy.ID))
+		}
+		ids[policy.ID] = true
+
+		for _, problem := range services.PolicyProblems(policy.Policy(), cfg.Password.MaxLength) {
+			problems = append(problems, fmt.Errorf("%s.%s", label, problem.Message))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// rotationDebounce groups the burst of file events produced by a config update
+const rotationDebounce = 500 * time.Millisecond
+
+// SecretRotator reloads the configuration and hands changed secrets to the
+// components using them, without a restart. Rotations are triggered by the
+// admin API, changes to the configuration files, or a refresh interval that
+// re-fetches referenced secrets from the secret stores.
+type SecretRotator struct {
+	logger *logrus.Logger
+	load   func() (*Config, error)
+
+	// rotating serializes rotations; mu guards the fields below it
+	rotating  sync.Mutex
+	mu        sync.Mutex
+	current   *Config
+	listeners []func(*Config)
+}
+
+// NewSecretRotator creates a rotator starting from the current configuration.
+// load is called for every rotation and must read the configuration the same
+// way it was read at startup.
+func NewSecretRotator(logger *logrus.Logger, current *Config, load func() (*Config, error)) *SecretRotator {
+	return &SecretRotator{
+		logger:  logger,
+		load:    load,
+		current: current,
+	}
+}
+
+// OnRotate registers a function called with the new configuration whenever
+// a rotation changes at least one secret
+func (r *SecretRotator) OnRotate(listener func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Grace returns how long replaced secrets stay valid after a rotation
+func (r *SecretRotator) Grace() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Duration(r.current.Secrets.RotationGrace) * time.Second
+}
+
+// Rotate reloads the configuration and, when secrets changed, notifies the
+// listeners. It returns the keys of the changed secrets; a configuration that
+// fails to load leaves the current secrets in place.
+func (r *SecretRotator) Rotate(trigger string) ([]string, error) {
+	r.rotating.Lock()
+	defer r.rotating.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		r.logger.WithError(err).WithField("trigger", trigger).Error("Failed to reload secrets; keeping the current ones")
+		return nil, err
+	}
+
+	r.mu.Lock()
+	changed := changedSecrets(r.current, next)
+	if len(changed) > 0 {
+		r.current = next
+	}
+	listeners := r.listeners
+	r.mu.Unlock()
+
+	if len(changed) == 0 {
+		return changed, nil
+	}
+	for _, listener := range listeners {
+		listener(next)
+	}
+	r.logger.WithFields(logrus.Fields{
+		"keys":    strings.Join(changed, ","),
+		"trigger": trigger,
+	}).Info("Rotated secrets")
+	return changed, nil
+}
+
+// rotatableSecrets returns the secrets that can be replaced at runtime, keyed
+// by configuration key
+func rotatableSecrets(cfg *Config) map[string]string {
+	return map[string]string{
+		"admin.token":             cfg.Admin.Token,
+		"throttle.captcha_secret": cfg.Throttle.CaptchaSecret,
+		"auth.hmac.keys":          strings.Join(cfg.Auth.HMAC.Keys, ","),
+	}
+}
+
+// changedSecrets returns the keys of the rotatable secrets that differ
+func changedSecrets(current, next *Config) []string {
+	before := rotatableSecrets(current)
+	changed := make([]string, 0)
+	for key, value := range rotatableSecrets(next) {
+		if before[key] != value {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Watch rotates secrets when the configuration files change, if
+// secrets.watch_files is set, and every secrets.refresh_interval seconds,
+// until the context is done. The parent directories are watched so that
+// atomic renames and Kubernetes ConfigMap symlink swaps are detected.
+func (r *SecretRotator) Watch(ctx context.Context) error {
+	r.mu.Lock()
+	files := r.current.Files
+	watchFiles := r.current.Secrets.WatchFiles
+	interval := time.Duration(r.current.Secrets.RefreshInterval) * time.Second
+	r.mu.Unlock()
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	var watcher *fsnotify.Watcher
+	if watchFiles && len(files) > 0 {
+		var err error
+		if watcher, err = fsnotify.NewWatcher(); err != nil {
+			return fmt.Errorf("failed to create configuration watcher: %w", err)
+		}
+		dirs := make(map[string]bool)
+		for _, file := range files {
+			dirs[filepath.Dir(file)] = true
+		}
+		for dir := range dirs {
+			if err := watcher.Add(dir); err != nil {
+				watcher.Close()
+				return fmt.Errorf("failed to watch %s: %w", dir, err)
+			}
+		}
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	var refresh <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		refresh = ticker.C
+		go func() {
+			<-ctx.Done()
+			ticker.Stop()
+		}()
+	}
+
+	if events == nil && refresh == nil {
+		return nil
+	}
+
+	go func() {
+		if watcher != nil {
+			defer watcher.Close()
+		}
+
+		debounce := time.NewTimer(rotationDebounce)
+		debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if affectsFiles(event, files) {
+					debounce.Reset(rotationDebounce)
+				}
+			case <-debounce.C:
+				r.Rotate("file change")
+			case <-refresh:
+				r.Rotate("refresh")
+			case err, ok := <-watchErrors:
+				if !ok {
+					return
+				}
+				r.logger.WithError(err).Warn("Configuration watcher error")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// affectsFiles reports whether a file event may have changed one of the files
+func affectsFiles(event fsnotify.Event, files []string) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	// Kubernetes volumes swap a ..data symlink rather than the files
+	if filepath.Base(event.Name) == "..data" {
+		return true
+	}
+	for _, file := range files {
+		if filepath.Clean(event.Name) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
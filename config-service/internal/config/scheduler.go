@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+
+	"config-service/internal/scheduler"
+)
+
+// ScheduledJob configures a job of the built-in scheduler
+type ScheduledJob struct {
+	// Name identifies the job in logs and metrics
+	Name string `mapstructure:"name" json:"name"`
+	// Type is the work the job does, one of scheduler.JobTypes
+	Type string `mapstructure:"type" json:"type"`
+	// Schedule is a cron expression, a macro such as @daily, or "@every 1h"
+	Schedule string `mapstructure:"schedule" json:"schedule"`
+	// Jitter is the maximum random delay of each run in seconds
+	Jitter int `mapstructure:"jitter" json:"jitter"`
+	// RunOnStart also runs the job when the service starts
+	RunOnStart bool `mapstructure:"run_on_start" json:"run_on_start"`
+	// Path is the file the job reads or, for snapshots, writes
+	Path string `mapstructure:"path" json:"path"`
+	// Output is the file or directory the job writes its report to
+	Output string `mapstructure:"output" json:"output"`
+}
+
+// validateScheduledJobs returns the problems of the configured jobs
+func validateScheduledJobs(cfg *Config) []error {
+	var problems []error
+	names := make(map[string]bool)
+	for i, job := range cfg.Scheduler.Jobs {
+		label := fmt.Sprintf("scheduler.jobs[%d]", i)
+		if job.Name == "" {
+			problems = append(problems, fmt.Errorf("%s: name is required", label))
+		} else if names[job.Name] {
+			problems = append(problems, fmt.Errorf("%s: duplicate job name %q", label, job.Name))
+		}
+		names[job.Name] = true
+
+		if _, err := scheduler.Parse(job.Schedule); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %v", label, err))
+		}
+		if job.Jitter < 0 {
+			problems = append(problems, fmt.Errorf("%s: jitter must not be negative", label))
+		}
+
+		switch job.Type {
+		case scheduler.JobDatasetRefresh, scheduler.JobCacheSnapshot:
+			if job.Path == "" {
+				problems = append(problems, fmt.Errorf("%s: path is required for %s jobs", label, job.Type))
+			}
+		case scheduler.JobHashRecheck, scheduler.JobCalibrationReport:
+			if job.Path == "" || job.Output == "" {
+				problems = append(problems, fmt.Errorf("%s: path and output are required for %s jobs", label, job.Type))
+			}
+		case scheduler.JobUsageReport:
+			if job.Output == "" {
+				problems = append(problems, fmt.Errorf("%s: output is required for %s jobs", label, job.Type))
+			}
+			if !cfg.Usage.Enabled {
+				problems = append(problems, fmt.Errorf("%s: %s jobs require usage.enabled", label, job.Type))
+			}
+		default:
+			problems = append(problems, fmt.Errorf("%s: invalid type %q (must be one of %v)", label, job.Type, scheduler.JobTypes))
+		}
+	}
+	return problems
+}
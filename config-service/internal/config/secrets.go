@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"config-service/internal/secrets"
+)
+
+// NewSecretResolver creates a resolver for secret references using the Vault
+// and AWS Secrets Manager settings. Providers without settings are left out,
+// so references to them fail with an error naming the missing provider.
+func NewSecretResolver(ctx context.Context, cfg *Config) (*secrets.Resolver, error) {
+	timeout := time.Duration(cfg.Secrets.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	options := []secrets.ResolverOption{
+		secrets.WithCacheTTL(time.Duration(cfg.Secrets.CacheTTL) * time.Second),
+	}
+	if cfg.Secrets.Vault.Address != "" {
+		options = append(options, secrets.WithProvider(secrets.SchemeVault, secrets.NewVaultProvider(
+			cfg.Secrets.Vault.Address,
+			cfg.Secrets.Vault.Token,
+			secrets.WithVaultNamespace(cfg.Secrets.Vault.Namespace),
+			secrets.WithVaultTimeout(timeout),
+		)))
+	}
+	if cfg.Secrets.AWS.Region != "" {
+		aws, err := secrets.NewAWSSecretsManagerProvider(ctx,
+			cfg.Secrets.AWS.Region,
+			secrets.WithAWSEndpoint(cfg.Secrets.AWS.Endpoint),
+			secrets.WithAWSTimeout(timeout),
+		)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, secrets.WithProvider(secrets.SchemeAWS, aws))
+	}
+	return secrets.NewResolver(options...), nil
+}
+
+// secretValues returns the configuration values that may hold secret
+// references, keyed by configuration key
+func secretValues(cfg *Config) map[string]*string {
+	values := map[string]*string{
+		"admin.token":                 &cfg.Admin.Token,
+		"throttle.captcha_secret":     &cfg.Throttle.CaptchaSecret,
+		"error_reporting.dsn":         &cfg.ErrorReporting.DSN,
+		"error_reporting.webhook_url": &cfg.ErrorReporting.WebhookURL,
+		"recovery.webhook_url":        &cfg.Recovery.WebhookURL,
+		"queue.url":                   &cfg.Queue.URL,
+		"queue.token":                 &cfg.Queue.Token,
+		"events.url":                  &cfg.Events.URL,
+		"events.token":                &cfg.Events.Token,
+	}
+	for i := range cfg.Auth.HMAC.Keys {
+		values[fmt.Sprintf("auth.hmac.keys[%d]", i)] = &cfg.Auth.HMAC.Keys[i]
+	}
+	for i := range cfg.Plugins.Rules {
+		values[fmt.Sprintf("plugins.rules[%d].token", i)] = &cfg.Plugins.Rules[i].Token
+	}
+	return values
+}
+
+// secretFiles returns the configuration values naming files whose content may
+// instead come from a secret reference, keyed by configuration key
+func secretFiles(cfg *Config) map[string]*string {
+	return map[string]*string{
+		"tls.cert_file": &cfg.TLS.CertFile,
+		"tls.key_file":  &cfg.TLS.KeyFile,
+	}
+}
+
+// hasSecretReferences reports whether any secret-capable value is a reference
+func hasSecretReferences(cfg *Config) bool {
+	for _, group := range []map[string]*string{secretValues(cfg), secretFiles(cfg)} {
+		for _, value := range group {
+			if secrets.IsReference(*value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveSecrets replaces secret references with the values they point at.
+// File settings such as tls.key_file are pointed at a private temporary file
+// holding the secret.
+func resolveSecrets(cfg *Config) error {
+	if !hasSecretReferences(cfg) {
+		return nil
+	}
+	ctx := context.Background()
+	resolver, err := NewSecretResolver(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return ResolveSecrets(ctx, cfg, resolver)
+}
+
+// ResolveSecrets replaces the secret references in the configuration using resolver
+func ResolveSecrets(ctx context.Context, cfg *Config, resolver *secrets.Resolver) error {
+	for key, value := range secretValues(cfg) {
+		resolved, err := resolver.Resolve(ctx, *value)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", key, err)
+		}
+		*value = resolved
+	}
+
+	var dir string
+	for key, value := range secretFiles(cfg) {
+		if !secrets.IsReference(*value) {
+			continue
+		}
+		content, err := resolver.Resolve(ctx, *value)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", key, err)
+		}
+		if dir == "" {
+			if dir, err = os.MkdirTemp("", "config-service-secrets-"); err != nil {
+				return fmt.Errorf("failed to create secrets directory: %w", err)
+			}
+		}
+		path := filepath.Join(dir, filepath.Base(key))
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		*value = path
+	}
+	return nil
+}
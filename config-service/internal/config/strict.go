@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"config-service/internal/errorreport"
+	"config-service/internal/tlsutil"
+)
+
+// StrictError lists every problem found by strict validation
+type StrictError struct {
+	Problems []string
+}
+
+// Error returns all problems on one line
+func (e *StrictError) Error() string {
+	return "strict mode: " + strings.Join(e.Problems, "; ")
+}
+
+// unknownKeys returns the keys set in configuration files that do not exist
+func unknownKeys(v *viper.Viper) []string {
+	known := make(map[string]bool)
+	for _, key := range Keys() {
+		known[key] = true
+	}
+
+	var unknown []string
+	for _, key := range v.AllKeys() {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownEnvVars returns the CONFIG_SERVICE_ environment variables that do not
+// set any configuration key, e.g. because of a typo
+func unknownEnvVars() []string {
+	known := map[string]bool{ConfigFileEnv: true, "CONFIG_SERVICE_ENV": true}
+	for _, key := range Keys() {
+		known[EnvVar(key)] = true
+	}
+
+	var unknown []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, "CONFIG_SERVICE_") && !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownSettings describes the unknown keys and environment variables
+func unknownSettings(v *viper.Viper) []string {
+	var problems []string
+	for _, key := range unknownKeys(v) {
+		problems = append(problems, "unknown key "+key)
+	}
+	for _, name := range unknownEnvVars() {
+		problems = append(problems, "unknown environment variable "+name)
+	}
+	return problems
+}
+
+// validateStrict reports out-of-range values and settings that contradict
+// each other, after the given problems found while loading, all at once
+func validateStrict(cfg *Config, problems []string) error {
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// Values the service accepts but treats as "off" or clamps silently
+	positive := map[string]int{
+		"throttle.captcha_timeout":       cfg.Throttle.CaptchaTimeout,
+		"recovery.webhook_timeout":       cfg.Recovery.WebhookTimeout,
+		"error_reporting.timeout":        cfg.ErrorReporting.Timeout,
+		"auth.jwt.jwks_refresh_interval": cfg.Auth.JWT.JWKSRefreshInterval,
+	}
+	nonNegative := map[string]int{
+		"server.shed_retry_after":        cfg.Server.ShedRetryAfter,
+		"logging.request_body_max_bytes": cfg.Logging.RequestBodyMaxBytes,
+		"auth.jwt.clock_skew":            cfg.Auth.JWT.ClockSkew,
+	}
+	for _, key := range sortedIntKeys(positive) {
+		if positive[key] <= 0 {
+			add("%s must be positive, got %d", key, positive[key])
+		}
+	}
+	for _, key := range sortedIntKeys(nonNegative) {
+		if nonNegative[key] < 0 {
+			add("%s must not be negative, got %d", key, nonNegative[key])
+		}
+	}
+
+	// Settings that only take effect together with another setting
+	if !cfg.TLS.Enabled && (cfg.TLS.CertFile != "" || cfg.TLS.KeyFile != "") {
+		add("tls.cert_file and tls.key_file are set but TLS is disabled")
+	}
+	if cfg.TLS.ClientAuth != tlsutil.ClientAuthNone && cfg.TLS.ClientAuth != "" && !cfg.TLS.Enabled {
+		add("tls.client_auth is set but TLS is disabled")
+	}
+	if cfg.Throttle.CaptchaURL != "" && cfg.Throttle.CaptchaSecret == "" {
+		add("throttle.captcha_verify_url is set without throttle.captcha_secret")
+	}
+	if cfg.ErrorReporting.Backend != errorreport.BackendSentry && cfg.ErrorReporting.DSN != "" {
+		add("error_reporting.dsn is set but the error reporting backend is %q", cfg.ErrorReporting.Backend)
+	}
+	if !cfg.Usage.Enabled && (cfg.Usage.MonthlyQuota > 0 || len(cfg.Usage.TenantQuotas) > 0) {
+		add("usage quotas are set but usage tracking is disabled")
+	}
+	if !cfg.Auth.HMAC.Enabled && len(cfg.Auth.HMAC.Keys) > 0 {
+		add("auth.hmac.keys is set but HMAC request signing is disabled")
+	}
+	if !cfg.Attestation.Enabled && cfg.Attestation.KeyFile != "" {
+		add("attestation.key_file is set but attestation tokens are disabled")
+	}
+	if !cfg.Password.SelfTest && cfg.Password.SelfTestExpectations != "" {
+		add("password.self_test_expectations is set but the scoring self-test is disabled")
+	}
+	if cfg.Password.Decoys != "" && !cfg.Webhooks.Enabled && !cfg.Events.Enabled {
+		add("password.decoys is set but webhooks and event streams are disabled, so decoy matches are only logged")
+	}
+	if !cfg.Server.TCPEnabled && cfg.TLS.Enabled {
+		add("TLS is enabled but the TCP listener is disabled")
+	}
+
+	if len(problems) > 0 {
+		return &StrictError{Problems: problems}
+	}
+	return nil
+}
+
+// sortedIntKeys returns the keys of a map in order
+func sortedIntKeys(values map[string]int) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,243 @@
+// Package dataset looks up entries of large sorted text files, such as common
+// password dictionaries and offline breach datasets, without parsing them into
+// Go maps. Files are memory-mapped where the platform supports it: their pages
+// belong to the page cache, are shared between processes, and can be
+// reclaimed under memory pressure, so enabling several large lists does not
+// grow the heap. Lookups binary-search the lines in place.
+package dataset
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrTooManyOpen is returned by Open when the limit set with SetMaxOpen is reached
+var ErrTooManyOpen = errors.New("too many datasets open")
+
+// open counts the datasets open in the process, against maxOpen if positive
+var (
+	openMutex sync.Mutex
+	open      int
+	maxOpen   int
+)
+
+// SetMaxOpen limits how many datasets may be open at once in the process,
+// bounding the address space and page cache they may take; 0 is unlimited
+func SetMaxOpen(n int) {
+	openMutex.Lock()
+	defer openMutex.Unlock()
+	maxOpen = n
+}
+
+// OpenCount returns how many datasets are open in the process
+func OpenCount() int {
+	openMutex.Lock()
+	defer openMutex.Unlock()
+	return open
+}
+
+// acquire reserves one of the open datasets allowed
+func acquire() bool {
+	openMutex.Lock()
+	defer openMutex.Unlock()
+	if maxOpen > 0 && open >= maxOpen {
+		return false
+	}
+	open++
+	return true
+}
+
+// releaseOpen gives back a reservation taken by acquire
+func releaseOpen() {
+	openMutex.Lock()
+	defer openMutex.Unlock()
+	open--
+}
+
+// File is an open dataset: one entry per line, sorted bytewise by key, as
+// produced by `LC_ALL=C sort`. With a separator, each line is a key and a
+// value, such as the HASH:COUNT lines of HIBP downloads.
+type File struct {
+	path      string
+	separator byte
+	data      []byte
+	entries   int
+	mapped    bool
+	release   func() error
+	closed    bool
+
+	digestOnce sync.Once
+	digest     string
+}
+
+// Option configures how a dataset is opened
+type Option func(*File)
+
+// WithSeparator splits each line into a key and a value at the first sep
+func WithSeparator(sep byte) Option {
+	return func(f *File) {
+		f.separator = sep
+	}
+}
+
+// Open maps a dataset and checks that its keys are sorted, which lookups
+// depend on
+func Open(path string, options ...Option) (*File, error) {
+	f := &File{path: path}
+	for _, option := range options {
+		option(f)
+	}
+
+	if !acquire() {
+		return nil, fmt.Errorf("failed to open dataset %s: %w", path, ErrTooManyOpen)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		releaseOpen()
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		releaseOpen()
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	f.data, f.mapped, f.release, err = mapFile(file, info.Size())
+	if err != nil {
+		releaseOpen()
+		return nil, fmt.Errorf("failed to map dataset %s: %w", path, err)
+	}
+
+	if err := f.index(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// index counts the entries and verifies they are sorted
+func (f *File) index() error {
+	var previous []byte
+	line := 0
+	for start := 0; start < len(f.data); {
+		end := f.lineEnd(start)
+		line++
+		key, _ := f.split(f.data[start:end])
+		if len(key) == 0 {
+			return fmt.Errorf("dataset %s: line %d is empty", f.path, line)
+		}
+		if previous != nil && bytes.Compare(previous, key) > 0 {
+			return fmt.Errorf("dataset %s: line %d is out of order; sort the file with LC_ALL=C sort", f.path, line)
+		}
+		previous = key
+		f.entries++
+		start = end + 1
+	}
+	return nil
+}
+
+// Lookup returns the value of the entry with the given key, if present
+func (f *File) Lookup(key string) (string, bool) {
+	target := []byte(key)
+	lo, hi := 0, len(f.data)
+	for lo < hi {
+		// Search the line around the middle; lo and hi stay at line starts
+		mid := lo + (hi-lo)/2
+		start := lo + bytes.LastIndexByte(f.data[lo:mid], '\n') + 1
+		end := f.lineEnd(start)
+		lineKey, value := f.split(f.data[start:end])
+
+		switch bytes.Compare(lineKey, target) {
+		case 0:
+			return string(value), true
+		case -1:
+			lo = end + 1
+		default:
+			hi = start
+		}
+	}
+	return "", false
+}
+
+// Contains reports whether the dataset has an entry with the given key
+func (f *File) Contains(key string) bool {
+	_, found := f.Lookup(key)
+	return found
+}
+
+// Len returns the number of entries
+func (f *File) Len() int {
+	return f.entries
+}
+
+// Path returns the file the dataset was opened from
+func (f *File) Path() string {
+	return f.path
+}
+
+// Size returns the size of the file in bytes
+func (f *File) Size() int64 {
+	return int64(len(f.data))
+}
+
+// NewReader returns a reader of the file's contents, such as for serving it
+func (f *File) NewReader() *bytes.Reader {
+	return bytes.NewReader(f.data)
+}
+
+// Digest returns the hex SHA-256 of the file's contents, computed on first use
+func (f *File) Digest() string {
+	f.digestOnce.Do(func() {
+		sum := sha256.Sum256(f.data)
+		f.digest = hex.EncodeToString(sum[:])
+	})
+	return f.digest
+}
+
+// Mapped reports whether the dataset is memory-mapped rather than read into
+// memory
+func (f *File) Mapped() bool {
+	return f.mapped
+}
+
+// Close unmaps the dataset; lookups must not be made afterwards
+func (f *File) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	releaseOpen()
+
+	release := f.release
+	f.data, f.release = nil, nil
+	if release == nil {
+		return nil
+	}
+	return release()
+}
+
+// lineEnd returns the index of the newline ending the line at start, or the
+// end of the data
+func (f *File) lineEnd(start int) int {
+	if i := bytes.IndexByte(f.data[start:], '\n'); i >= 0 {
+		return start + i
+	}
+	return len(f.data)
+}
+
+// split returns the key and value of a line, without a trailing carriage return
+func (f *File) split(line []byte) ([]byte, []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if f.separator != 0 {
+		if i := bytes.IndexByte(line, f.separator); i >= 0 {
+			return line[:i], line[i+1:]
+		}
+	}
+	return line, nil
+}
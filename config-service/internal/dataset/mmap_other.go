@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package dataset
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads a file into memory on platforms without mmap support
+func mapFile(file *os.File, size int64) ([]byte, bool, func() error, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return data, false, nil, nil
+}
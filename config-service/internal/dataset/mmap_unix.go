@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package dataset
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps a file read-only; empty files need no mapping
+func mapFile(file *os.File, size int64) ([]byte, bool, func() error, error) {
+	if size == 0 {
+		return nil, true, nil, nil
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return data, true, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,104 @@
+package dataset
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Slot holds the version of a dataset lookups use, so that a newer version
+// can be switched to while lookups are running. A version swapped out stays
+// open until it is retired and the lookups that acquired it have finished.
+type Slot struct {
+	mutex    sync.Mutex
+	current  *version
+	versions map[*File]*version
+}
+
+// version is a file in a slot with the lookups using it
+type version struct {
+	file    *File
+	refs    int64
+	retired int32
+	close   sync.Once
+}
+
+// NewSlot creates a slot using f
+func NewSlot(f *File) *Slot {
+	current := &version{file: f}
+	return &Slot{
+		current:  current,
+		versions: map[*File]*version{f: current},
+	}
+}
+
+// Acquire returns the current version for lookups and a function releasing
+// it, which must be called once the lookups are done
+func (s *Slot) Acquire() (*File, func()) {
+	s.mutex.Lock()
+	v := s.current
+	atomic.AddInt64(&v.refs, 1)
+	s.mutex.Unlock()
+
+	return v.file, func() {
+		if atomic.AddInt64(&v.refs, -1) == 0 && atomic.LoadInt32(&v.retired) == 1 {
+			v.close.Do(func() { v.file.Close() })
+		}
+	}
+}
+
+// Path returns the file the current version was opened from
+func (s *Slot) Path() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.current.file.Path()
+}
+
+// Swap makes next the current version and returns the previous one, which
+// stays open so that it can be swapped back until it is retired
+func (s *Slot) Swap(next *File) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.current.file
+	v, ok := s.versions[next]
+	if !ok {
+		v = &version{file: next}
+		s.versions[next] = v
+	}
+	s.current = v
+	return previous
+}
+
+// Retire closes a version swapped out once the lookups using it finish. The
+// current version cannot be retired.
+func (s *Slot) Retire(f *File) {
+	s.mutex.Lock()
+	v, ok := s.versions[f]
+	if !ok || v == s.current {
+		s.mutex.Unlock()
+		return
+	}
+	delete(s.versions, f)
+	s.mutex.Unlock()
+
+	atomic.StoreInt32(&v.retired, 1)
+	if atomic.LoadInt64(&v.refs) == 0 {
+		v.close.Do(func() { v.file.Close() })
+	}
+}
+
+// Close closes every version of the slot; lookups must not be made afterwards
+func (s *Slot) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var err error
+	for _, v := range s.versions {
+		v.close.Do(func() {
+			if closeErr := v.file.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		})
+	}
+	return err
+}
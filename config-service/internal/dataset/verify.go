@@ -0,0 +1,236 @@
+package dataset
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checksum modes, deciding which files must come with a checksum
+const (
+	// ChecksumsOff skips verification unless a signing key is configured
+	ChecksumsOff = "off"
+	// ChecksumsOptional verifies files that come with a checksum file
+	ChecksumsOptional = "optional"
+	// ChecksumsRequired refuses files without a checksum file
+	ChecksumsRequired = "required"
+)
+
+// ChecksumModes lists the valid checksum modes
+var ChecksumModes = []string{ChecksumsOff, ChecksumsOptional, ChecksumsRequired}
+
+// ValidateChecksumMode returns an error for an unknown checksum mode
+func ValidateChecksumMode(mode string) error {
+	for _, valid := range ChecksumModes {
+		if mode == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid datasets.checksums %q: must be one of %s", mode, strings.Join(ChecksumModes, ", "))
+}
+
+// Verification statuses
+const (
+	// StatusVerified means the file matched its checksum and, with a signing
+	// key, its signature
+	StatusVerified = "verified"
+	// StatusUnverified means the file had no checksum to verify it against
+	StatusUnverified = "unverified"
+	// StatusFailed means the file was refused
+	StatusFailed = "failed"
+)
+
+// ErrIntegrity is returned when a file does not match its checksum or signature
+var ErrIntegrity = errors.New("dataset integrity check failed")
+
+// Verification is the outcome of the latest verification of a file
+type Verification struct {
+	Path      string    `json:"path"`
+	Status    string    `json:"status"`
+	SHA256    string    `json:"sha256,omitempty"`
+	Signed    bool      `json:"signed"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Verifier checks wordlists and datasets against the SHA-256 checksum in a
+// file next to them, named after the file with ".sha256" appended, in the
+// format of sha256sum or as a bare hex digest. With a signing key, the
+// checksum must also carry a signature in a ".sig" file: the Ed25519
+// signature of the raw 32-byte digest, raw or base64-encoded. It keeps the
+// outcome of each file's latest verification for health checks.
+type Verifier struct {
+	mode string
+	key  ed25519.PublicKey
+	now  func() time.Time
+
+	mu      sync.Mutex
+	results map[string]Verification
+}
+
+// NewVerifier creates a verifier in the given checksum mode; a key, if not
+// nil, makes signatures required
+func NewVerifier(mode string, key ed25519.PublicKey) *Verifier {
+	return &Verifier{
+		mode:    mode,
+		key:     key,
+		now:     time.Now,
+		results: make(map[string]Verification),
+	}
+}
+
+// LoadVerificationKey reads a PEM-encoded Ed25519 public key
+func LoadVerificationKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("dataset signing key %s is not PEM-encoded", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dataset signing key: %w", err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("dataset signing key %s is not an Ed25519 key", path)
+	}
+	return publicKey, nil
+}
+
+// Enabled reports whether files are verified at all
+func (v *Verifier) Enabled() bool {
+	return v.mode != ChecksumsOff || v.key != nil
+}
+
+// VerifyFile verifies an open dataset. Its mapped contents are hashed, so
+// what is verified is what lookups will read.
+func (v *Verifier) VerifyFile(f *File) error {
+	return v.verify(f.Path(), f.Digest)
+}
+
+// VerifyData verifies contents read from path
+func (v *Verifier) VerifyData(path string, data []byte) error {
+	return v.verify(path, func() string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	})
+}
+
+// Results returns the latest verification of each file, sorted by path
+func (v *Verifier) Results() []Verification {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	results := make([]Verification, 0, len(v.results))
+	for _, result := range v.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+	return results
+}
+
+// verify checks the digest of a file against its checksum and signature
+// files and records the outcome
+func (v *Verifier) verify(path string, digest func() string) error {
+	if !v.Enabled() {
+		return nil
+	}
+
+	result := Verification{Path: path, Status: StatusVerified, CheckedAt: v.now().UTC()}
+	err := v.check(path, digest, &result)
+	if err != nil {
+		result.Status, result.Error = StatusFailed, err.Error()
+	}
+
+	v.mu.Lock()
+	v.results[path] = result
+	v.mu.Unlock()
+
+	return err
+}
+
+// check compares the digest with the checksum and signature files of path
+func (v *Verifier) check(path string, digest func() string, result *Verification) error {
+	expected, err := readChecksum(path + ".sha256")
+	switch {
+	case errors.Is(err, os.ErrNotExist) && v.mode != ChecksumsRequired && v.key == nil:
+		result.Status = StatusUnverified
+		return nil
+	case errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("%w: %s has no checksum file %s.sha256", ErrIntegrity, path, path)
+	case err != nil:
+		return fmt.Errorf("%w: %s: %v", ErrIntegrity, path, err)
+	}
+
+	result.SHA256 = digest()
+	if subtle.ConstantTimeCompare([]byte(result.SHA256), []byte(expected)) != 1 {
+		return fmt.Errorf("%w: %s has SHA-256 %s, but its checksum file lists %s", ErrIntegrity, path, result.SHA256, expected)
+	}
+	if v.key == nil {
+		return nil
+	}
+
+	signature, err := readSignature(path + ".sig")
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrIntegrity, path, err)
+	}
+	raw, _ := hex.DecodeString(result.SHA256)
+	if !ed25519.Verify(v.key, raw, signature) {
+		return fmt.Errorf("%w: %s: signature does not match the signing key", ErrIntegrity, path)
+	}
+	result.Signed = true
+	return nil
+}
+
+// readChecksum returns the hex SHA-256 digest listed first in a checksum file
+func readChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum file %s is empty", path)
+	}
+	checksum := strings.ToLower(fields[0])
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("checksum file %s does not start with a SHA-256 digest", path)
+	}
+	return checksum, nil
+}
+
+// readSignature reads a raw or base64-encoded Ed25519 signature
+func readSignature(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("signature file %s is missing", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == ed25519.SignatureSize {
+		return data, nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("signature file %s does not hold an Ed25519 signature", path)
+	}
+	return signature, nil
+}
@@ -0,0 +1,93 @@
+package errorreport
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Backends selectable in configuration
+const (
+	BackendNone    = "none"
+	BackendSentry  = "sentry"
+	BackendWebhook = "webhook"
+)
+
+// Event levels
+const (
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// defaultQueueSize bounds the number of events waiting for delivery
+const defaultQueueSize = 100
+
+// Event is an error report. It carries only sanitized request context: never
+// request bodies, passwords, or credentials.
+type Event struct {
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	RequestID string            `json:"request_id,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Route     string            `json:"route,omitempty"`
+	Status    int               `json:"status,omitempty"`
+	Stack     string            `json:"stack,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+// Reporter sends error events to an error-reporting service
+type Reporter interface {
+	Report(event Event)
+}
+
+// ValidateBackend reports whether the backend name is supported
+func ValidateBackend(backend string) error {
+	switch backend {
+	case BackendNone, BackendSentry, BackendWebhook:
+		return nil
+	default:
+		return fmt.Errorf("unsupported error reporting backend: %s (must be none, sentry, or webhook)", backend)
+	}
+}
+
+// dispatcher delivers events in the background so reporting never delays a
+// response. When the queue is full, events are dropped and logged.
+type dispatcher struct {
+	logger *logrus.Logger
+	queue  chan Event
+	send   func(Event) error
+}
+
+// newDispatcher starts a delivery worker for the send function
+func newDispatcher(logger *logrus.Logger, send func(Event) error) *dispatcher {
+	d := &dispatcher{
+		logger: logger,
+		queue:  make(chan Event, defaultQueueSize),
+		send:   send,
+	}
+
+	go func() {
+		for event := range d.queue {
+			if err := d.send(event); err != nil {
+				d.logger.WithField("request_id", event.RequestID).WithError(err).Warn("Failed to deliver error report")
+			}
+		}
+	}()
+
+	return d
+}
+
+// enqueue queues an event for delivery
+func (d *dispatcher) enqueue(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		d.logger.WithField("request_id", event.RequestID).Warn("Error report queue full; dropping event")
+	}
+}
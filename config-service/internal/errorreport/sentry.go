@@ -0,0 +1,141 @@
+package errorreport
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SentryReporter sends events to Sentry's store API, configured by a DSN of the
+// form https://<public key>@<host>/<project id>
+type SentryReporter struct {
+	*dispatcher
+	storeURL    string
+	auth        string
+	environment string
+	release     string
+	httpClient  *http.Client
+}
+
+// NewSentryReporter creates a reporter for the DSN
+func NewSentryReporter(logger *logrus.Logger, dsn, environment, release string, timeout time.Duration) (*SentryReporter, error) {
+	storeURL, key, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SentryReporter{
+		storeURL:    storeURL,
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=config-service/%s, sentry_key=%s", release, key),
+		environment: environment,
+		release:     release,
+		httpClient:  &http.Client{Timeout: timeout},
+	}
+	r.dispatcher = newDispatcher(logger, r.send)
+
+	return r, nil
+}
+
+// ParseDSN returns the store endpoint and public key of a Sentry DSN
+func ParseDSN(dsn string) (storeURL, key string, err error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil || parsed.User == nil || parsed.Host == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN")
+	}
+
+	path := strings.Trim(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectID := path[slash+1:]
+	if projectID == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: missing project ID")
+	}
+
+	prefix := ""
+	if slash > 0 {
+		prefix = "/" + path[:slash]
+	}
+
+	storeURL = fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, prefix, projectID)
+	return storeURL, parsed.User.Username(), nil
+}
+
+// Report queues an event for delivery to Sentry
+func (r *SentryReporter) Report(event Event) {
+	r.enqueue(event)
+}
+
+// send posts a single event to Sentry
+func (r *SentryReporter) send(event Event) error {
+	tags := map[string]string{}
+	for key, value := range event.Tags {
+		tags[key] = value
+	}
+	if event.RequestID != "" {
+		tags["request_id"] = event.RequestID
+	}
+	if event.Route != "" {
+		tags["route"] = event.Route
+	}
+	if event.Method != "" {
+		tags["method"] = event.Method
+	}
+	if event.Status != 0 {
+		tags["status"] = fmt.Sprint(event.Status)
+	}
+
+	payload := map[string]interface{}{
+		"event_id":    newEventID(),
+		"timestamp":   event.Time.UTC().Format(time.RFC3339),
+		"level":       event.Level,
+		"platform":    "go",
+		"logger":      "config-service",
+		"message":     event.Message,
+		"environment": r.environment,
+		"release":     r.release,
+		"tags":        tags,
+	}
+	if event.Route != "" {
+		payload["transaction"] = event.Method + " " + event.Route
+	}
+	if event.Stack != "" {
+		payload["extra"] = map[string]string{"stack": event.Stack}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newEventID returns a random 32-character hex event ID
+func newEventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
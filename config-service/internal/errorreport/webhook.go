@@ -0,0 +1,52 @@
+package errorreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookReporter posts events as JSON to a generic error sink
+type WebhookReporter struct {
+	*dispatcher
+	url        string
+	httpClient *http.Client
+}
+
+// NewWebhookReporter creates a reporter that posts to the URL
+func NewWebhookReporter(logger *logrus.Logger, url string, timeout time.Duration) *WebhookReporter {
+	r := &WebhookReporter{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+	r.dispatcher = newDispatcher(logger, r.send)
+	return r
+}
+
+// Report queues an event for delivery to the webhook
+func (r *WebhookReporter) Report(event Event) {
+	r.enqueue(event)
+}
+
+// send posts a single event
+func (r *WebhookReporter) send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.httpClient.Post(r.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error sink returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -47,4 +47,9 @@ func ErrBreachTimeout(cause error) *BreachServiceError {
 // ErrBreachInvalidResponse indicates the breach API returned an invalid response
 func ErrBreachInvalidResponse(cause error) *BreachServiceError {
 	return NewBreachServiceError("breach API returned invalid response", cause)
-}
\ No newline at end of file
+}
+
+// ErrBreachCircuitOpen indicates breach API calls are suspended after repeated failures
+func ErrBreachCircuitOpen() *BreachServiceError {
+	return NewBreachServiceError("breach API circuit breaker is open", nil)
+}
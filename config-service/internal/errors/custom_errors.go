@@ -15,6 +15,8 @@ const (
 	ErrorCodeInvalidFormat    ErrorCode = "INVALID_FORMAT"
 	ErrorCodePasswordTooShort ErrorCode = "PASSWORD_TOO_SHORT"
 	ErrorCodePasswordTooLong  ErrorCode = "PASSWORD_TOO_LONG"
+	ErrorCodeTooShort         ErrorCode = "TOO_SHORT"
+	ErrorCodeTooLong          ErrorCode = "TOO_LONG"
 
 	// Business logic errors
 	ErrorCodePasswordWeak        ErrorCode = "PASSWORD_TOO_WEAK"
@@ -47,7 +49,7 @@ func (e *APIError) Error() string {
 func (e *APIError) HTTPStatus() int {
 	switch e.Code {
 	case ErrorCodeInvalidInput, ErrorCodeMissingField, ErrorCodeInvalidFormat,
-		ErrorCodePasswordTooShort, ErrorCodePasswordTooLong:
+		ErrorCodePasswordTooShort, ErrorCodePasswordTooLong, ErrorCodeTooShort, ErrorCodeTooLong:
 		return http.StatusBadRequest
 	case ErrorCodePasswordWeak, ErrorCodePasswordCommon,
 		ErrorCodePasswordSequential, ErrorCodePasswordRepeated:
@@ -72,10 +74,12 @@ func NewAPIError(code ErrorCode, message string, details ...string) *APIError {
 	}
 }
 
-// ValidationError represents a validation error
+// ValidationError represents a validation error. Field is the JSON path of
+// the offending field, empty when the error concerns the whole body.
 type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
+	Field   string    `json:"field"`
+	Code    ErrorCode `json:"code,omitempty"`
+	Message string    `json:"message"`
 }
 
 // ValidationErrors represents a collection of validation errors
@@ -107,6 +111,15 @@ func NewValidationError(field, message string) ValidationError {
 	}
 }
 
+// NewValidationErrorWithCode creates a new validation error with an error code
+func NewValidationErrorWithCode(field string, code ErrorCode, message string) ValidationError {
+	return ValidationError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	}
+}
+
 // NewValidationErrors creates a new collection of validation errors
 func NewValidationErrors(errors []ValidationError) *ValidationErrors {
 	return &ValidationErrors{
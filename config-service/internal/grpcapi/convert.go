@@ -0,0 +1,179 @@
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	passwordv1 "config-service/api/proto/password/v1"
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// invalidArgument is the INVALID_ARGUMENT error of a request with invalid
+// fields, listing each as a BadRequest field violation
+func invalidArgument(validationErrors *apperrors.ValidationErrors) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, validationError := range validationErrors.Errors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       validationError.Field,
+			Description: validationError.Message,
+		})
+	}
+	return withDetails(status.New(codes.InvalidArgument, validationErrors.Error()), badRequest)
+}
+
+// validationFailed is the INVALID_ARGUMENT error of a password that failed
+// validation, detailing the policy it failed and the explanation of the check
+// when they are known
+func validationFailed(message string, policy *models.PolicyResult, explanation *services.Explanation) error {
+	failed := status.New(codes.InvalidArgument, message)
+	if policy == nil && explanation == nil {
+		return failed.Err()
+	}
+
+	failure := &passwordv1.ValidationFailure{Policy: policyResult(policy)}
+	if explanation != nil {
+		failure.Explain = explainSteps(explanation.Steps())
+	}
+	return withDetails(failed, failure)
+}
+
+// withDetails returns the error of a status with a detail attached, or
+// without it if it cannot be encoded
+func withDetails(s *status.Status, detail protoiface.MessageV1) error {
+	if detailed, err := s.WithDetails(detail); err == nil {
+		return detailed.Err()
+	}
+	return s.Err()
+}
+
+// passwordCheckResponse converts the result of a strength check. The result
+// is copied, so that the caller can release it to its pool.
+func passwordCheckResponse(response *models.PasswordResponse) *passwordv1.PasswordCheckResponse {
+	estimate := &passwordv1.Estimate{
+		Guesses:      response.Estimate.Guesses,
+		GuessesLog10: response.Estimate.GuessesLog10,
+		Score:        int32(response.Estimate.Score),
+	}
+	for _, crackTime := range response.Estimate.CrackTimes {
+		estimate.CrackTimes = append(estimate.CrackTimes, &passwordv1.CrackTime{
+			Attack:           string(crackTime.Attack),
+			GuessesPerSecond: crackTime.GuessesPerSecond,
+			Seconds:          crackTime.Seconds,
+			Display:          crackTime.Display,
+		})
+	}
+	for _, match := range response.Estimate.Sequence {
+		estimate.Sequence = append(estimate.Sequence, &passwordv1.PatternMatch{
+			Pattern:    string(match.Pattern),
+			Start:      int32(match.Start),
+			End:        int32(match.End),
+			Guesses:    match.Guesses,
+			Dictionary: match.Dictionary,
+			Reversed:   match.Reversed,
+			L33T:       match.L33t,
+			Keyboard:   match.Keyboard,
+		})
+	}
+
+	return &passwordv1.PasswordCheckResponse{
+		Strength: string(response.Strength),
+		Score:    int32(response.Score),
+		Feedback: &passwordv1.Feedback{
+			Warnings:    append([]string(nil), response.Feedback.Warnings...),
+			Suggestions: append([]string(nil), response.Feedback.Suggestions...),
+		},
+		Requirements: &passwordv1.Requirements{
+			Length:       response.Requirements.Length,
+			Uppercase:    response.Requirements.Uppercase,
+			Lowercase:    response.Requirements.Lowercase,
+			Numbers:      response.Requirements.Numbers,
+			SpecialChars: response.Requirements.SpecialChars,
+		},
+		Randomness: &passwordv1.Randomness{
+			BitsPerChar: response.Randomness.BitsPerChar,
+			Generated:   response.Randomness.Generated,
+		},
+		Estimate:   estimate,
+		BreachData: breachInfo(response.BreachData),
+		Policy:     policyResult(response.Policy),
+		Explain:    explainSteps(response.Explain),
+	}
+}
+
+// breachInfo converts the breach data of a password, which may be nil
+func breachInfo(info *models.BreachInfo) *passwordv1.BreachInfo {
+	if info == nil {
+		return nil
+	}
+	return &passwordv1.BreachInfo{
+		Found:        info.Found,
+		BreachCount:  int32(info.BreachCount),
+		LastBreached: info.LastBreached,
+	}
+}
+
+// policyResult converts the result of a named policy, which may be nil
+func policyResult(result *models.PolicyResult) *passwordv1.PolicyResult {
+	if result == nil {
+		return nil
+	}
+	converted := &passwordv1.PolicyResult{
+		PolicyId:    result.PolicyID,
+		Valid:       result.Valid,
+		EntropyBits: result.EntropyBits,
+		Warnings:    result.Warnings,
+	}
+	for _, check := range result.Checks {
+		converted.Checks = append(converted.Checks, &passwordv1.PolicyCheck{
+			Rule:    string(check.Rule),
+			Passed:  check.Passed,
+			Limit:   int32(check.Limit),
+			Message: check.Message,
+		})
+	}
+	return converted
+}
+
+// explainSteps converts the steps of an explanation. Inputs and outputs are
+// converted through their JSON encoding, so that they read the same as in
+// REST responses.
+func explainSteps(steps []models.ExplainStep) []*passwordv1.ExplainStep {
+	converted := make([]*passwordv1.ExplainStep, 0, len(steps))
+	for _, step := range steps {
+		converted = append(converted, &passwordv1.ExplainStep{
+			Step:       step.Step,
+			Input:      jsonStruct(step.Input),
+			Output:     jsonStruct(step.Output),
+			DurationNs: step.DurationNS,
+		})
+	}
+	return converted
+}
+
+// jsonStruct converts a JSON object to a protobuf Struct, or returns nil when
+// it is empty or cannot be converted
+func jsonStruct(object map[string]interface{}) *structpb.Struct {
+	if len(object) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil
+	}
+	converted, err := structpb.NewStruct(decoded)
+	if err != nil {
+		return nil
+	}
+	return converted
+}
@@ -0,0 +1,154 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"config-service/internal/auth"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/requestid"
+	"config-service/internal/services"
+)
+
+// Metadata keys read from calls, the gRPC counterparts of the REST headers
+const (
+	requestIDKey     = "x-request-id"
+	apiKeyKey        = "x-api-key"
+	authorizationKey = "authorization"
+)
+
+// recoveryInterceptor turns a panic in an RPC into an INTERNAL error, so that
+// one bad call does not take the server down
+func recoveryInterceptor(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.FromContext(ctx, logger).WithFields(logrus.Fields{
+					"method": info.FullMethod,
+					"stack":  string(debug.Stack()),
+				}).Errorf("Panic recovered: %v", recovered)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, request)
+	}
+}
+
+// requestInterceptor assigns each call a request ID, reusing a well-formed
+// x-request-id from the caller's metadata and returning it in the header,
+// then logs the call and records its count and duration tagged with the
+// method and status code
+func requestInterceptor(logger *logrus.Logger, recorder metrics.Recorder) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		requestID := firstMetadata(ctx, requestIDKey)
+		if !requestid.IsValid(requestID) {
+			requestID = requestid.Generate()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+		ctx = requestid.WithContext(ctx, requestID)
+		ctx = logging.WithFields(ctx, logrus.Fields{
+			"request_id": requestID,
+			"method":     info.FullMethod,
+		})
+
+		response, err := handler(ctx, request)
+
+		code := status.Code(err)
+		tags := metrics.Tags{
+			"method": path.Base(info.FullMethod),
+			"code":   code.String(),
+		}
+		recorder.Count("grpc_requests", 1, tags)
+		recorder.Timing("grpc_request_duration", time.Since(start), tags)
+
+		entry := logging.FromContext(ctx, logger).WithFields(logrus.Fields{
+			"code":     code.String(),
+			"duration": time.Since(start),
+		})
+		switch code {
+		case codes.OK:
+			entry.Info("gRPC call completed successfully")
+		case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+			entry.Error("gRPC call completed with server error")
+		default:
+			entry.Warn("gRPC call completed with client error")
+		}
+		return response, err
+	}
+}
+
+// authInterceptor requires callers to present an API key or a bearer token
+// granting the scope, in the x-api-key or authorization metadata as in the
+// headers of REST requests. Either verifier may be nil to disable that
+// credential type.
+func authInterceptor(apiKeyService *services.APIKeyService, jwtValidator *auth.JWTValidator, scope string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		tenant, err := authenticate(ctx, apiKeyService, jwtValidator, scope)
+		if err != nil {
+			return nil, err
+		}
+		if tenant != "" {
+			ctx = logging.WithFields(ctx, logrus.Fields{"tenant": tenant})
+		}
+		return handler(ctx, request)
+	}
+}
+
+// authenticate verifies the credentials of a call and returns the caller's
+// tenant, or an UNAUTHENTICATED or PERMISSION_DENIED error
+func authenticate(ctx context.Context, apiKeyService *services.APIKeyService, jwtValidator *auth.JWTValidator, scope string) (string, error) {
+	authorization := firstMetadata(ctx, authorizationKey)
+	bearer := strings.TrimPrefix(authorization, "Bearer ")
+	isBearer := bearer != "" && bearer != authorization
+
+	secret := firstMetadata(ctx, apiKeyKey)
+	if secret == "" && isBearer && strings.HasPrefix(bearer, "csk_") {
+		secret = bearer
+	}
+	if secret != "" && apiKeyService != nil {
+		key, err := apiKeyService.Authenticate(secret)
+		if err != nil {
+			return "", status.Error(codes.Unauthenticated, err.Error())
+		}
+		if !key.HasScope(scope) {
+			return "", status.Errorf(codes.PermissionDenied, "API key does not grant the %s scope", scope)
+		}
+		return key.Tenant, nil
+	}
+
+	if isBearer && jwtValidator != nil {
+		claims, err := jwtValidator.Validate(bearer)
+		if err != nil {
+			return "", status.Error(codes.Unauthenticated, fmt.Sprintf("invalid bearer token: %v", err))
+		}
+		if !jwtValidator.Grants(claims, scope) {
+			return "", status.Errorf(codes.PermissionDenied, "bearer token does not grant the %s scope", scope)
+		}
+		return claims.Tenant, nil
+	}
+
+	return "", status.Error(codes.Unauthenticated, "an API key or bearer token is required")
+}
+
+// firstMetadata returns the first value of a metadata key of the incoming
+// call, or ""
+func firstMetadata(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
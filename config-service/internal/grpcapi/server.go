@@ -0,0 +1,225 @@
+// Package grpcapi serves the password API over gRPC, for internal services
+// that cannot consume the REST API. Its RPCs are backed by the same services
+// as the REST endpoints, so both report the same results for a password.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	passwordv1 "config-service/api/proto/password/v1"
+	"config-service/internal/auth"
+	apperrors "config-service/internal/errors"
+	"config-service/internal/handlers"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// Options holds the services the gRPC API is backed by and how callers are
+// authenticated
+type Options struct {
+	Logger *logrus.Logger
+	// Metrics records the count and duration of every call, nil to record none
+	Metrics metrics.Recorder
+
+	PasswordService  *services.PasswordService
+	BreachService    *services.BreachService
+	GeneratorService *services.GeneratorService
+	// PolicyService validates passwords against the policy named in a check,
+	// nil to reject every policy_id
+	PolicyService *services.PolicyService
+
+	// Callers present an API key or a bearer token granting the check scope,
+	// as for the REST API. Calls are unauthenticated when neither API keys
+	// nor a JWT validator are configured.
+	APIKeyService  *services.APIKeyService
+	APIKeysEnabled bool
+	JWTValidator   *auth.JWTValidator
+}
+
+// NewServer creates a gRPC server serving the password API. serverOptions
+// configure the server further, such as with transport credentials.
+func NewServer(opts Options, serverOptions ...grpc.ServerOption) *grpc.Server {
+	if opts.Metrics == nil {
+		opts.Metrics = metrics.Noop{}
+	}
+
+	interceptors := []grpc.UnaryServerInterceptor{
+		recoveryInterceptor(opts.Logger),
+		requestInterceptor(opts.Logger, opts.Metrics),
+	}
+	apiKeys := opts.APIKeyService
+	if !opts.APIKeysEnabled {
+		apiKeys = nil
+	}
+	if apiKeys != nil || opts.JWTValidator != nil {
+		interceptors = append(interceptors, authInterceptor(apiKeys, opts.JWTValidator, models.ScopeCheck))
+	} else {
+		opts.Logger.Warn("Authentication is disabled: gRPC password RPCs are unauthenticated")
+	}
+
+	server := grpc.NewServer(append(serverOptions, grpc.ChainUnaryInterceptor(interceptors...))...)
+	passwordv1.RegisterPasswordServiceServer(server, &passwordServer{opts: opts})
+	return server
+}
+
+// passwordServer implements the PasswordService RPCs
+type passwordServer struct {
+	passwordv1.UnimplementedPasswordServiceServer
+	opts Options
+}
+
+// PasswordCheck validates a password against the default rules or a named
+// policy, and scores it while it is looked up in the breach database
+func (s *passwordServer) PasswordCheck(ctx context.Context, request *passwordv1.PasswordCheckRequest) (*passwordv1.PasswordCheckResponse, error) {
+	check := models.PasswordRequest{
+		Password:       request.GetPassword(),
+		KeyboardLayout: request.GetKeyboardLayout(),
+		PolicyID:       request.GetPolicyId(),
+		Explain:        request.GetExplain(),
+	}
+	if validationErrors := handlers.ValidateStruct(&check); validationErrors != nil {
+		return nil, invalidArgument(validationErrors)
+	}
+
+	if check.KeyboardLayout != "" {
+		layout, err := models.ParseKeyboardLayout(check.KeyboardLayout)
+		if err != nil {
+			return nil, invalidArgument(apperrors.NewValidationErrors([]apperrors.ValidationError{
+				apperrors.NewValidationErrorWithCode("keyboard_layout", apperrors.ErrorCodeInvalidInput, err.Error()),
+			}))
+		}
+		ctx = services.ContextWithKeyboardLayout(ctx, layout)
+	}
+
+	// In explain mode, the services record the rules they run
+	var explanation *services.Explanation
+	if check.Explain {
+		explanation = &services.Explanation{}
+		ctx = services.ContextWithExplanation(ctx, explanation)
+	}
+
+	// Validate the password against the named policy, or the default rules
+	var policy *models.PolicyResult
+	if check.PolicyID != "" {
+		var err error
+		if policy, err = s.validatePolicy(ctx, check.PolicyID, check.Password, explanation); err != nil {
+			return nil, err
+		}
+	} else if err := s.opts.PasswordService.ValidatePasswordContext(ctx, check.Password); err != nil {
+		return nil, validationFailed("password validation failed: "+err.Error(), nil, explanation)
+	}
+
+	// Check for breaches while the strength is scored, unless the policy
+	// already looked it up
+	type breachLookup struct {
+		info *models.BreachInfo
+		err  error
+	}
+	var breach chan breachLookup
+	if s.opts.BreachService != nil {
+		breach = make(chan breachLookup, 1)
+		if policy != nil && policy.Breach != nil {
+			breach <- breachLookup{info: policy.Breach}
+		} else {
+			go func() {
+				info, err := s.opts.BreachService.CheckPasswordBreachContext(ctx, check.Password)
+				breach <- breachLookup{info: info, err: err}
+			}()
+		}
+	}
+
+	response := s.opts.PasswordService.ScorePasswordContext(ctx, check.Password)
+	defer services.ReleaseResponse(response)
+	if breach != nil {
+		if lookup := <-breach; lookup.err == nil {
+			handlers.AddBreachInfoToPasswordResponse(response, lookup.info)
+		}
+	}
+	response.Policy = policy
+	if explanation != nil {
+		response.Explain = explanation.Steps()
+	}
+
+	return passwordCheckResponse(response), nil
+}
+
+// validatePolicy validates a password against a named policy, returning an
+// INVALID_ARGUMENT error when the policy does not exist or the password
+// fails it
+func (s *passwordServer) validatePolicy(ctx context.Context, policyID, password string, explanation *services.Explanation) (*models.PolicyResult, error) {
+	var result *models.PolicyResult
+	err := services.ErrPolicyNotFound
+	if s.opts.PolicyService != nil {
+		result, err = s.opts.PolicyService.Evaluate(ctx, policyID, password)
+	}
+	if err != nil {
+		return nil, invalidArgument(apperrors.NewValidationErrors([]apperrors.ValidationError{
+			apperrors.NewValidationErrorWithCode("policy_id", apperrors.ErrorCodeInvalidInput, fmt.Sprintf("unknown policy %q", policyID)),
+		}))
+	}
+
+	if failed := result.Failed(); len(failed) > 0 {
+		messages := make([]string, len(failed))
+		for i, check := range failed {
+			messages[i] = check.Message
+		}
+		return nil, validationFailed("password validation failed: "+strings.Join(messages, "; "), result, explanation)
+	}
+	return result, nil
+}
+
+// BreachCheck looks a password up in the breach database
+func (s *passwordServer) BreachCheck(ctx context.Context, request *passwordv1.BreachCheckRequest) (*passwordv1.BreachInfo, error) {
+	check := models.PasswordRequest{Password: request.GetPassword()}
+	if validationErrors := handlers.ValidateStruct(&check); validationErrors != nil {
+		return nil, invalidArgument(validationErrors)
+	}
+	if s.opts.BreachService == nil {
+		return nil, status.Error(codes.Unimplemented, "breach checks are not available")
+	}
+
+	info, err := s.opts.BreachService.CheckPasswordBreachContext(ctx, check.Password)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, "breach check failed: "+err.Error())
+	}
+	return breachInfo(info), nil
+}
+
+// Generate generates a random password and scores it
+func (s *passwordServer) Generate(ctx context.Context, request *passwordv1.GenerateRequest) (*passwordv1.GenerateResponse, error) {
+	generate := models.GenerateRequest{
+		Length:         int(request.GetLength()),
+		Classes:        request.GetClasses(),
+		AllowAmbiguous: request.GetAllowAmbiguous(),
+		Pronounceable:  request.GetPronounceable(),
+	}
+	if validationErrors := handlers.ValidateStruct(&generate); validationErrors != nil {
+		return nil, invalidArgument(validationErrors)
+	}
+	if s.opts.GeneratorService == nil {
+		return nil, status.Error(codes.Unimplemented, "password generation is not available")
+	}
+
+	response, err := s.opts.GeneratorService.Generate(generate)
+	if err != nil {
+		var validationErrors *apperrors.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			return nil, invalidArgument(validationErrors)
+		}
+		return nil, status.Error(codes.Internal, "password generation failed: "+err.Error())
+	}
+	return &passwordv1.GenerateResponse{
+		Password: response.Password,
+		Strength: string(response.Strength),
+		Score:    int32(response.Score),
+	}, nil
+}
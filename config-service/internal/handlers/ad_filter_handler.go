@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/audit"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// ADFilterHandler answers the companion of an Active Directory password filter
+// DLL with an allow or deny verdict for the NTLM hash of a candidate password.
+// The verdict is always returned with 200 so the filter only has to read it.
+func ADFilterHandler(filter *services.ADFilterService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.ADFilterRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		evaluateDone := TrackStage(c, "evaluate")
+		verdict := filter.Evaluate(c.Request.Context(), request)
+		evaluateDone()
+
+		result := audit.ResultPass
+		for _, check := range verdict.Checks {
+			if check.Passed {
+				continue
+			}
+			if check.Rule == models.RuleNotBreached {
+				result = audit.ResultBreached
+				break
+			}
+			result = audit.ResultWeak
+		}
+		setAuditResult(c, result)
+		setCheckViolations(c, failedRules(verdict.Checks))
+
+		respondJSON(c, http.StatusOK, verdict)
+	}
+}
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/audit"
+	"config-service/internal/config"
+	apperrors "config-service/internal/errors"
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// AdminCacheStatsHandler returns statistics about the breach result cache
+func AdminCacheStatsHandler(breachService *services.BreachService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, breachService.CacheStats())
+	}
+}
+
+// AdminCacheFlushHandler removes all entries from the breach result cache
+func AdminCacheFlushHandler(breachService *services.BreachService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		removed := breachService.FlushCache()
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"flushed": removed,
+		})
+	}
+}
+
+// AdminConfigHandler returns the effective configuration with secrets redacted
+func AdminConfigHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, cfg.Redacted())
+	}
+}
+
+// AdminConfigSchemaHandler returns the JSON Schema of the configuration
+func AdminConfigSchemaHandler(schema *config.JSONSchema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, schema)
+	}
+}
+
+// AdminRotateSecretsHandler reloads the configuration and applies changed
+// secrets, returning the keys that were rotated
+func AdminRotateSecretsHandler(rotator *config.SecretRotator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rotated, err := rotator.Rotate("admin api")
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Secret rotation failed", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"rotated": rotated,
+		})
+	}
+}
+
+// AdminListBannedWordsHandler returns a page of the banned word list
+func AdminListBannedWordsHandler(bannedList *services.BannedListService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseListOptions(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		words, page, err := bannedList.ListPage(opts)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"words": words,
+			"page":  page,
+		})
+	}
+}
+
+// AdminAddBannedWordsHandler adds words to the banned word list
+func AdminAddBannedWordsHandler(bannedList *services.BannedListService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.BannedWordsRequest
+
+		// Bind JSON request
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		added := bannedList.Add(request.Words...)
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"added": added,
+			"total": bannedList.Count(),
+		})
+	}
+}
+
+// AdminDeleteBannedWordHandler removes a word from the banned word list; the
+// word can be restored until the retention passes
+func AdminDeleteBannedWordHandler(bannedList *services.BannedListService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		word := c.Param("word")
+		setAuditTarget(c, "banned-word:"+word)
+
+		if !bannedList.Remove(word, GetActor(c)) {
+			respondError(c, http.StatusNotFound, "Banned word not found", word)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AdminListDeletedBannedWordsHandler returns the removed banned words that can
+// still be restored, with who removed them
+func AdminListDeletedBannedWordsHandler(bannedList *services.BannedListService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"words": bannedList.Deleted(),
+		})
+	}
+}
+
+// AdminRestoreBannedWordHandler puts back a removed banned word
+func AdminRestoreBannedWordHandler(bannedList *services.BannedListService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		word := c.Param("word")
+		setAuditTarget(c, "banned-word:"+word)
+
+		restored, err := bannedList.Restore(word)
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Banned word not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, restored)
+	}
+}
+
+// AdminListPoliciesHandler returns a page of the password policies currently in effect
+func AdminListPoliciesHandler(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseListOptions(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		policies, page, err := policyService.ListPage(opts)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"policies": policies,
+			"page":     page,
+		})
+	}
+}
+
+// AdminGetPolicyHandler returns a password policy
+func AdminGetPolicyHandler(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, err := policyService.Get(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Policy not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, policy)
+	}
+}
+
+// AdminPutPolicyHandler defines or replaces a named password policy
+func AdminPutPolicyHandler(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		setAuditTarget(c, "policy:"+id)
+
+		var request models.PasswordPolicyRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		policy, err := policyService.Put(request.Policy(id))
+		if err != nil {
+			var validationErrors *apperrors.ValidationErrors
+			if errors.As(err, &validationErrors) {
+				respondValidationErrors(c, validationErrors)
+				return
+			}
+			respondError(c, http.StatusConflict, "Policy is read-only", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, policy)
+	}
+}
+
+// AdminDeletePolicyHandler removes a password policy defined through the
+// admin API; the policy can be restored until the retention passes
+func AdminDeletePolicyHandler(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		setAuditTarget(c, "policy:"+id)
+
+		if err := policyService.Delete(id, GetActor(c)); err != nil {
+			if errors.Is(err, services.ErrPolicyReadOnly) {
+				respondError(c, http.StatusConflict, "Policy is read-only", err.Error())
+				return
+			}
+			respondError(c, http.StatusNotFound, "Policy not found", err.Error())
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AdminListDeletedPoliciesHandler returns the deleted password policies that
+// can still be restored, with who deleted them
+func AdminListDeletedPoliciesHandler(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"policies": policyService.Deleted(),
+		})
+	}
+}
+
+// AdminRestorePolicyHandler puts back a deleted password policy
+func AdminRestorePolicyHandler(policyService *services.PolicyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		setAuditTarget(c, "policy:"+id)
+
+		restored, err := policyService.Restore(id)
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Policy not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, restored)
+	}
+}
+
+// AdminIssueAPIKeyHandler issues a new API key
+func AdminIssueAPIKeyHandler(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.APIKeyRequest
+
+		// Bind JSON request
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		key, err := apiKeyService.Issue(request.Name, request.Tenant, request.Scopes)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "API key issue failed", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusCreated, key)
+	}
+}
+
+// AdminListAPIKeysHandler returns a page of issued API keys
+func AdminListAPIKeysHandler(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseListOptions(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		keys, page, err := apiKeyService.ListPage(opts)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"api_keys": keys,
+			"page":     page,
+		})
+	}
+}
+
+// AdminRotateAPIKeyHandler replaces the secret of an API key
+func AdminRotateAPIKeyHandler(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := apiKeyService.Rotate(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "API key not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, key)
+	}
+}
+
+// AdminRevokeAPIKeyHandler revokes an API key
+func AdminRevokeAPIKeyHandler(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := apiKeyService.Revoke(c.Param("id")); err != nil {
+			respondError(c, http.StatusNotFound, "API key not found", err.Error())
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AdminAuditExportHandler exports audit events as JSON lines or CSV. Query
+// parameters: format (jsonl|csv), since and until (RFC 3339), and tenant.
+func AdminAuditExportHandler(auditLogger *audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := audit.Filter{Tenant: c.Query("tenant")}
+		for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+			if value := c.Query(param); value != "" {
+				parsed, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					respondError(c, http.StatusBadRequest, "Invalid query parameters", param+" must be an RFC 3339 timestamp")
+					return
+				}
+				*target = parsed
+			}
+		}
+
+		format := c.DefaultQuery("format", audit.FormatJSONL)
+		contentType := "application/x-ndjson"
+		switch format {
+		case audit.FormatJSONL:
+		case audit.FormatCSV:
+			contentType = "text/csv"
+		default:
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", "format must be \"jsonl\" or \"csv\"")
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := auditLogger.Export(&buf, format, filter); err != nil {
+			if errors.Is(err, audit.ErrExportUnsupported) {
+				respondError(c, http.StatusNotImplemented, "Audit export unsupported", err.Error())
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "Audit export failed", err.Error())
+			return
+		}
+
+		writeResponseMetadata(c)
+		c.Header("Content-Disposition", "attachment; filename=audit."+format)
+		c.Data(http.StatusOK, contentType, buf.Bytes())
+	}
+}
+
+// AdminGetLoggingHandler returns the current log level and format
+func AdminGetLoggingHandler(controller *logging.Controller) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, models.LoggingSettings{
+			Level:  controller.Level(),
+			Format: controller.Format(),
+		})
+	}
+}
+
+// AdminUpdateLoggingHandler changes the log level and/or format without a restart
+func AdminUpdateLoggingHandler(controller *logging.Controller) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.LoggingSettings
+
+		// Bind JSON request
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		if request.Level != "" {
+			if err := controller.SetLevel(request.Level); err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid request format", err.Error())
+				return
+			}
+		}
+		if request.Format != "" {
+			if err := controller.SetFormat(request.Format); err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid request format", err.Error())
+				return
+			}
+		}
+
+		respondJSON(c, http.StatusOK, models.LoggingSettings{
+			Level:  controller.Level(),
+			Format: controller.Format(),
+		})
+	}
+}
+
+// AdminUsageHandler returns per-tenant and per-key request counts for the
+// current month, or for the month given by the period query parameter
+func AdminUsageHandler(usage *services.UsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := usage.Report(c.Query("period"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, report)
+	}
+}
+
+// AdminUsageExportHandler exports the per-tenant usage of a month, with check
+// outcomes and the average score, for chargeback. Query parameters: period
+// (YYYY-MM, default: current month) and format (csv|json).
+func AdminUsageExportHandler(usage *services.UsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := usage.Report(c.Query("period"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		format := c.DefaultQuery("format", services.UsageFormatCSV)
+		contentType := "text/csv"
+		switch format {
+		case services.UsageFormatCSV:
+		case services.UsageFormatJSON:
+			contentType = "application/json"
+		default:
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", "format must be \"csv\" or \"json\"")
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := services.ExportUsage(&buf, report, format); err != nil {
+			respondError(c, http.StatusInternalServerError, "Usage export failed", err.Error())
+			return
+		}
+
+		writeResponseMetadata(c)
+		c.Header("Content-Disposition", "attachment; filename=usage-"+report.Period+"."+format)
+		c.Data(http.StatusOK, contentType, buf.Bytes())
+	}
+}
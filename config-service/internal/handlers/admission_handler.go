@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// AdmissionWebhookHandler serves a Kubernetes ValidatingAdmissionWebhook that
+// rejects Secrets containing weak or breached passwords
+func AdmissionWebhookHandler(admissionService *services.SecretAdmissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var review models.AdmissionReview
+		if err := bindJSON(c, &review); err != nil {
+			respondBindError(c, err)
+			return
+		}
+		if review.Request == nil {
+			respondError(c, http.StatusBadRequest, "Invalid request format", "AdmissionReview has no request")
+			return
+		}
+
+		reviewDone := TrackStage(c, "admission")
+		response := admissionService.Review(c.Request.Context(), review.Request)
+		reviewDone()
+
+		respondJSON(c, http.StatusOK, models.AdmissionReview{
+			APIVersion: models.AdmissionAPIVersion,
+			Kind:       models.AdmissionKind,
+			Response:   response,
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// AnalyticsMiddleware counts the outcome of every password check for the
+// aggregate analytics. Nothing identifying the password or caller is passed on.
+func AnalyticsMiddleware(analytics *services.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if outcome, ok := checkOutcome(c); ok {
+			analytics.Record(outcome)
+		}
+	}
+}
+
+// AdminAnalyticsHandler reports aggregate statistics of the password checks
+// made in a range of days. Query parameters: from and to (YYYY-MM-DD, default:
+// the last 30 days).
+func AdminAnalyticsHandler(analytics *services.AnalyticsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := analytics.Report(c.Query("from"), c.Query("to"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, report)
+	}
+}
+
+// setCheckViolations records the policy rules a checked password failed for
+// usage reports and analytics
+func setCheckViolations(c *gin.Context, violations []models.PolicyRule) {
+	c.Set(checkViolationsKey, violations)
+}
+
+// failedRules returns the rules of the failed checks
+func failedRules(checks []models.PolicyCheck) []models.PolicyRule {
+	var rules []models.PolicyRule
+	for _, check := range checks {
+		if !check.Passed {
+			rules = append(rules, check.Rule)
+		}
+	}
+	return rules
+}
+
+// passwordViolations returns the policy rules a strength check failed: the
+// unmet requirements, a strength below strong, and a breach
+func passwordViolations(response *models.PasswordResponse) []models.PolicyRule {
+	var rules []models.PolicyRule
+	for _, requirement := range []struct {
+		met  bool
+		rule models.PolicyRule
+	}{
+		{response.Requirements.Length, models.RuleMinLength},
+		{response.Requirements.Uppercase, models.RuleUppercase},
+		{response.Requirements.Lowercase, models.RuleLowercase},
+		{response.Requirements.Numbers, models.RuleNumbers},
+		{response.Requirements.SpecialChars, models.RuleSpecial},
+		{response.Strength == models.StrengthStrong || response.Strength == models.StrengthVeryStrong, models.RuleMinStrength},
+		{response.BreachData == nil || !response.BreachData.Found, models.RuleNotBreached},
+	} {
+		if !requirement.met {
+			rules = append(rules, requirement.rule)
+		}
+	}
+	return rules
+}
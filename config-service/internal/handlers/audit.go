@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/audit"
+	"config-service/internal/models"
+)
+
+// AuditMiddleware records an audit event for every request once it completes:
+// the caller, tenant, route, status, and result class. Request bodies are never
+// recorded.
+func AuditMiddleware(auditLogger *audit.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		operation := c.FullPath()
+		if operation == "" {
+			operation = "unmatched"
+		}
+
+		auditLogger.Record(audit.Event{
+			RequestID: GetRequestID(c),
+			Actor:     GetActor(c),
+			Tenant:    GetTenant(c),
+			Operation: c.Request.Method + " " + operation,
+			Target:    c.GetString(auditTargetKey),
+			Status:    c.Writer.Status(),
+			Result:    auditResult(c),
+		})
+	}
+}
+
+// setAuditResult records the result class of the operation for the audit log
+func setAuditResult(c *gin.Context, result audit.Result) {
+	c.Set(auditResultKey, result)
+}
+
+// setAuditTarget records what the operation acted on for the audit log
+func setAuditTarget(c *gin.Context, target string) {
+	c.Set(auditTargetKey, target)
+}
+
+// passwordAuditResult classifies a password check for the audit log; anything
+// rated below strong counts as weak
+func passwordAuditResult(response *models.PasswordResponse) audit.Result {
+	switch {
+	case response.BreachData != nil && response.BreachData.Found:
+		return audit.ResultBreached
+	case response.Strength != models.StrengthStrong && response.Strength != models.StrengthVeryStrong:
+		return audit.ResultWeak
+	default:
+		return audit.ResultPass
+	}
+}
+
+// auditResult returns the result class set by the handler, or derives one from
+// the response status
+func auditResult(c *gin.Context) audit.Result {
+	if value, exists := c.Get(auditResultKey); exists {
+		if result, ok := value.(audit.Result); ok {
+			return result
+		}
+	}
+
+	status := c.Writer.Status()
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return audit.ResultDenied
+	case status >= http.StatusInternalServerError:
+		return audit.ResultError
+	case status >= http.StatusBadRequest:
+		return audit.ResultRejected
+	default:
+		return audit.ResultSuccess
+	}
+}
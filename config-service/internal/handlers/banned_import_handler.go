@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// defaultImportSource is the source of imported words when none is given
+const defaultImportSource = "import"
+
+// AdminImportBannedWordsHandler starts importing a banned list uploaded as
+// plain text, NDJSON, or a multipart form with a "file" part. The upload is
+// streamed to disk rather than decoded in memory; the words are added in the
+// background and the response is the job reporting their progress.
+func AdminImportBannedWordsHandler(imports *services.BannedImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source := c.DefaultQuery("source", defaultImportSource)
+		if source == "" || len(source) > 100 {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", "source must be 1-100 characters")
+			return
+		}
+		replace := false
+		if value := c.Query("replace"); value != "" {
+			var err error
+			if replace, err = strconv.ParseBool(value); err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid query parameters", "replace must be true or false")
+				return
+			}
+		}
+
+		body, format, err := importUpload(c)
+		if err != nil {
+			respondError(c, http.StatusUnsupportedMediaType, "Unsupported media type", err.Error())
+			return
+		}
+
+		job, err := imports.Start(source, format, replace, body)
+		switch {
+		case errors.Is(err, services.ErrBannedImportInProgress):
+			respondError(c, http.StatusConflict, "Import in progress", err.Error())
+			return
+		case errors.Is(err, ErrBodyTooLarge):
+			respondError(c, http.StatusRequestEntityTooLarge, "Request too large", err.Error())
+			return
+		case errors.Is(err, services.ErrBannedImportStorage):
+			respondError(c, http.StatusInternalServerError, "Import failed", err.Error())
+			return
+		case err != nil:
+			respondError(c, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+
+		setAuditTarget(c, "banned-import:"+job.ID)
+		c.Header("Location", c.FullPath()+"/"+job.ID)
+		respondJSON(c, http.StatusAccepted, job)
+	}
+}
+
+// importUpload returns the banned list in the request body and its format,
+// from the media type of the body or, for multipart forms, of the file part
+// or its file name
+func importUpload(c *gin.Context) (io.Reader, string, error) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil {
+		return nil, "", fmt.Errorf("a Content-Type is required")
+	}
+	if mediaType != "multipart/form-data" {
+		format, ok := importFormat(mediaType)
+		if !ok {
+			return nil, "", fmt.Errorf("banned lists are uploaded as text/plain, application/x-ndjson, or multipart/form-data, not %s", mediaType)
+		}
+		return c.Request.Body, format, nil
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, "", fmt.Errorf("the form has no file part")
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if format, ok := importFormat(partType); ok {
+			return part, format, nil
+		}
+		switch filepath.Ext(part.FileName()) {
+		case ".ndjson", ".jsonl":
+			return part, models.BannedImportNDJSON, nil
+		default:
+			return part, models.BannedImportText, nil
+		}
+	}
+}
+
+// importFormat returns the import format of a media type
+func importFormat(mediaType string) (string, bool) {
+	switch mediaType {
+	case "text/plain":
+		return models.BannedImportText, true
+	case "application/x-ndjson", "application/jsonl":
+		return models.BannedImportNDJSON, true
+	}
+	return "", false
+}
+
+// AdminListBannedImportsHandler lists the banned list imports in progress and
+// the latest finished ones
+func AdminListBannedImportsHandler(imports *services.BannedImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"imports": imports.List(),
+		})
+	}
+}
+
+// AdminGetBannedImportHandler reports the progress of a banned list import
+func AdminGetBannedImportHandler(imports *services.BannedImportService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := imports.Get(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Import not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, job)
+	}
+}
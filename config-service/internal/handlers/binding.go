@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	apperrors "config-service/internal/errors"
+)
+
+func init() {
+	// Report fields by their JSON names, as clients send them
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName returns the JSON name of a struct field
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// respondBindError rejects a request whose body could not be bound, listing
+// each invalid field with an error code instead of the binding's raw error
+func respondBindError(c *gin.Context, err error) {
+	respondValidationErrors(c, bindingErrors(err))
+}
+
+// respondValidationErrors rejects a request with invalid fields, listing each
+// under "errors" with its JSON path, error code, and message
+func respondValidationErrors(c *gin.Context, validationErrors *apperrors.ValidationErrors) {
+	body := errorBody(c, "Invalid request format", validationErrors.Error())
+	body["errors"] = validationErrors.Errors
+	respondJSON(c, http.StatusBadRequest, body)
+}
+
+// ValidateStruct checks the binding tags of a request model decoded other
+// than from a JSON body, such as from a gRPC message, and returns its invalid
+// fields as validation errors, or nil when it is valid
+func ValidateStruct(request interface{}) *apperrors.ValidationErrors {
+	if err := binding.Validator.ValidateStruct(request); err != nil {
+		return bindingErrors(err)
+	}
+	return nil
+}
+
+// bindingErrors translates an error of bindJSON into validation errors: one
+// per failed binding tag, or one for a body that is not valid JSON, has a
+// value of the wrong type, or has a field the request model does not have
+func bindingErrors(err error) *apperrors.ValidationErrors {
+	result := apperrors.NewValidationErrors(nil)
+
+	var fieldErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &fieldErrors):
+		for _, fieldErr := range fieldErrors {
+			result.Errors = append(result.Errors, fieldError(fieldErr))
+		}
+	case errors.As(err, &typeErr):
+		result.Errors = append(result.Errors, apperrors.NewValidationErrorWithCode(typeErr.Field, apperrors.ErrorCodeInvalidFormat,
+			"must be "+jsonTypeName(typeErr.Type)))
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, errTrailingData):
+		result.Errors = append(result.Errors, apperrors.NewValidationErrorWithCode("", apperrors.ErrorCodeInvalidFormat,
+			"request body is not valid JSON"))
+	case errors.Is(err, io.EOF):
+		result.Errors = append(result.Errors, apperrors.NewValidationErrorWithCode("", apperrors.ErrorCodeMissingField,
+			"request body is empty"))
+	default:
+		if field, ok := unknownFieldName(err); ok {
+			result.Errors = append(result.Errors, apperrors.NewValidationErrorWithCode(field, apperrors.ErrorCodeInvalidInput, "is not a known field"))
+			break
+		}
+		result.Errors = append(result.Errors, apperrors.NewValidationErrorWithCode("", apperrors.ErrorCodeInvalidInput, err.Error()))
+	}
+	return result
+}
+
+// fieldError describes a failed binding tag of a field
+func fieldError(fieldErr validator.FieldError) apperrors.ValidationError {
+	// The namespace starts with the name of the request type
+	field := fieldErr.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	bound, unit := "must be", ""
+	switch fieldErr.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		bound, unit = "must have", " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required", "required_if":
+		return apperrors.NewValidationErrorWithCode(field, apperrors.ErrorCodeMissingField, "is required")
+	case "min":
+		code := apperrors.ErrorCodeTooShort
+		if field == "password" {
+			code = apperrors.ErrorCodePasswordTooShort
+		}
+		return apperrors.NewValidationErrorWithCode(field, code, bound+" at least "+fieldErr.Param()+unit)
+	case "max":
+		code := apperrors.ErrorCodeTooLong
+		if field == "password" {
+			code = apperrors.ErrorCodePasswordTooLong
+		}
+		return apperrors.NewValidationErrorWithCode(field, code, bound+" at most "+fieldErr.Param()+unit)
+	case "oneof":
+		return apperrors.NewValidationErrorWithCode(field, apperrors.ErrorCodeInvalidInput, "must be one of "+fieldErr.Param())
+	default:
+		return apperrors.NewValidationErrorWithCode(field, apperrors.ErrorCodeInvalidInput, "failed the "+fieldErr.Tag()+" check")
+	}
+}
+
+// jsonTypeName names the JSON type a Go type is decoded from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
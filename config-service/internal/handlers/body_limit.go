@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrBodyTooLarge is returned when a handler reads a request body past the
+// limit set by BodyLimitMiddleware
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// BodyLimitMiddleware bounds the bytes a request body may take, so that a
+// single oversized bulk request cannot exhaust memory while it is decoded.
+// Requests declaring a larger Content-Length are rejected up front; otherwise
+// reading past the limit fails with ErrBodyTooLarge.
+func BodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			respondBodyTooLarge(c, maxBytes)
+			c.Abort()
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = &limitedBody{ReadCloser: c.Request.Body, remaining: maxBytes}
+		}
+		c.Next()
+	}
+}
+
+// respondBodyTooLarge rejects a request whose body exceeds maxBytes
+func respondBodyTooLarge(c *gin.Context, maxBytes int64) {
+	respondError(c, http.StatusRequestEntityTooLarge, "Request too large", fmt.Sprintf("request body exceeds %d bytes", maxBytes))
+}
+
+// limitedBody is a request body that fails once more than remaining bytes are read
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads up to one byte past the limit, to tell a body of exactly the
+// limit from a larger one
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, ErrBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"config-service/internal/audit"
 	"config-service/internal/models"
 	"config-service/internal/services"
 )
@@ -15,26 +17,81 @@ func BreachCheckHandler(breachService *services.BreachService) gin.HandlerFunc {
 		var request models.PasswordRequest
 
 		// Bind JSON request
-		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request format",
-				"message": err.Error(),
-			})
+		bindDone := TrackStage(c, "bind")
+		if err := bindJSON(c, &request); err != nil {
+			bindDone()
+			respondBindError(c, err)
 			return
 		}
+		bindDone()
 
 		// Check if password is breached
-		breachInfo, err := breachService.CheckPasswordBreach(request.Password)
+		breachDone := TrackStage(c, "breach")
+		breachInfo, err := breachService.CheckPasswordBreachContext(c.Request.Context(), request.Password)
+		breachDone()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Breach check failed",
-				"message": err.Error(),
-			})
+			respondError(c, http.StatusInternalServerError, "Breach check failed", localizeError(c, err))
 			return
 		}
 
+		if breachInfo.Found {
+			setAuditResult(c, audit.ResultBreached)
+		} else {
+			setAuditResult(c, audit.ResultPass)
+		}
+
 		// Return breach information
-		c.JSON(http.StatusOK, breachInfo)
+		respondJSON(c, http.StatusOK, breachInfo)
+	}
+}
+
+// BreachAuditHandler handles bulk breach audits of SHA-1 password hashes
+func BreachAuditHandler(breachService *services.BreachService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.BreachAuditRequest
+
+		// Bind JSON request
+		bindDone := TrackStage(c, "bind")
+		if err := bindJSON(c, &request); err != nil {
+			bindDone()
+			if errors.Is(err, ErrBodyTooLarge) {
+				respondError(c, http.StatusRequestEntityTooLarge, "Request too large", err.Error())
+				return
+			}
+			respondBindError(c, err)
+			return
+		}
+		bindDone()
+
+		if !breachService.IsEnabled() {
+			respondError(c, http.StatusServiceUnavailable, "Breach detection disabled", "breach detection is not enabled on this service")
+			return
+		}
+
+		// Audit the hashes
+		breachDone := TrackStage(c, "breach")
+		results := breachService.CheckHashesBreach(c.Request.Context(), request.Hashes)
+		breachDone()
+
+		response := models.BreachAuditResponse{
+			Results: results,
+			Summary: models.BreachAuditSummary{Total: len(results)},
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				response.Summary.Errors++
+			} else if result.Found {
+				response.Summary.Breached++
+			}
+		}
+
+		if response.Summary.Breached > 0 {
+			setAuditResult(c, audit.ResultBreached)
+		} else {
+			setAuditResult(c, audit.ResultPass)
+		}
+
+		respondJSON(c, http.StatusOK, response)
 	}
 }
 
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/clientip"
+)
+
+// ClientIPMiddleware records the client IP derived by the resolver for
+// logging and rate limiting
+func ClientIPMiddleware(resolver *clientip.Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(clientIPKey, resolver.Resolve(c.Request))
+		c.Next()
+	}
+}
+
+// GetClientIP returns the client IP recorded by ClientIPMiddleware, or the one
+// Gin derives when the middleware is not in use
+func GetClientIP(c *gin.Context) string {
+	if ip := c.GetString(clientIPKey); ip != "" {
+		return ip
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/services"
+)
+
+// AdminListDatasetsHandler lists the datasets that can be reloaded
+func AdminListDatasetsHandler(reloader *services.DatasetReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"datasets": reloader.Datasets(),
+		})
+	}
+}
+
+// AdminReloadDatasetHandler starts reloading a dataset from disk. The new
+// version is loaded in the background and switched to once verified; the
+// response is the reload reporting the outcome.
+func AdminReloadDatasetHandler(reloader *services.DatasetReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		setAuditTarget(c, "dataset:"+name)
+
+		reload, err := reloader.Start(name)
+		switch {
+		case errors.Is(err, services.ErrDatasetNotFound):
+			respondError(c, http.StatusNotFound, "Dataset not found", "no reloadable dataset named "+name)
+			return
+		case errors.Is(err, services.ErrDatasetReloadInProgress):
+			respondError(c, http.StatusConflict, "Reload in progress", err.Error())
+			return
+		case err != nil:
+			respondError(c, http.StatusInternalServerError, "Reload failed", err.Error())
+			return
+		}
+
+		c.Header("Location", strings.TrimSuffix(c.FullPath(), ":name/reload")+"reloads/"+reload.ID)
+		respondJSON(c, http.StatusAccepted, reload)
+	}
+}
+
+// AdminListDatasetReloadsHandler lists the reloads in progress and the latest finished ones
+func AdminListDatasetReloadsHandler(reloader *services.DatasetReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"reloads": reloader.List(),
+		})
+	}
+}
+
+// AdminGetDatasetReloadHandler reports the outcome of a dataset reload
+func AdminGetDatasetReloadHandler(reloader *services.DatasetReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reload, err := reloader.Get(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Reload not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, reload)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/errorreport"
+	"config-service/internal/logging"
+)
+
+// ErrorReportingMiddleware reports requests that end in a 5xx response to the
+// error-reporting service. Only sanitized context is sent: request ID, method,
+// route, status, tenant, and the scrubbed handler errors. Register it after
+// RecoveryMiddleware; panics are reported by NewErrorReportPanicHook instead.
+func ErrorReportingMiddleware(reporter errorreport.Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError {
+			return
+		}
+
+		message := fmt.Sprintf("HTTP %d %s", status, http.StatusText(status))
+		if len(c.Errors) > 0 {
+			message = logging.ScrubString(strings.Join(c.Errors.Errors(), "; "))
+		}
+
+		event := errorreport.Event{
+			Level:     errorreport.LevelError,
+			Message:   message,
+			RequestID: GetRequestID(c),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Status:    status,
+		}
+		if tenant := GetTenant(c); tenant != "" {
+			event.Tags = map[string]string{"tenant": tenant}
+		}
+
+		reporter.Report(event)
+	}
+}
+
+// NewErrorReportPanicHook returns a panic hook that reports recovered panics
+// with their stack trace to the error-reporting service
+func NewErrorReportPanicHook(reporter errorreport.Reporter) PanicHook {
+	return func(event PanicEvent) {
+		reporter.Report(errorreport.Event{
+			Level:     errorreport.LevelFatal,
+			Message:   "panic: " + logging.ScrubString(event.Panic),
+			RequestID: event.RequestID,
+			Method:    event.Method,
+			Route:     event.Route,
+			Status:    http.StatusInternalServerError,
+			Stack:     event.Stack,
+			Time:      event.Time,
+		})
+	}
+}
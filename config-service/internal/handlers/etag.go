@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalGetMiddleware tags successful GET responses with a strong ETag
+// derived from the body and answers requests whose If-None-Match lists it
+// with 304 Not Modified and no body, so clients syncing configuration can
+// poll cheaply. The response is buffered to hash it, so the middleware
+// belongs on routes with small bodies such as policy documents and lists.
+func ConditionalGetMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.status == http.StatusOK && original.Header().Get("ETag") == "" {
+			sum := sha256.Sum256(buffered.body.Bytes())
+			original.Header().Set("ETag", `"`+base64.RawURLEncoding.EncodeToString(sum[:18])+`"`)
+		}
+		if buffered.status == http.StatusOK && etagMatches(c.GetHeader("If-None-Match"), original.Header().Get("ETag")) {
+			original.Header().Del("Content-Type")
+			original.Header().Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+
+		original.WriteHeader(buffered.status)
+		original.Write(buffered.body.Bytes())
+	}
+}
+
+// bufferedWriter holds back a response until the handlers are done with it
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) { w.status = code }
+func (w *bufferedWriter) WriteHeaderNow()      {}
+func (w *bufferedWriter) Status() int          { return w.status }
+func (w *bufferedWriter) Size() int            { return w.body.Len() }
+func (w *bufferedWriter) Written() bool        { return w.body.Len() > 0 }
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, using
+// the weak comparison that conditional GETs call for
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// AdminListFeedbackCodesHandler returns the codes of the feedback warnings
+// and suggestions that tenants can override, with their English messages
+func AdminListFeedbackCodesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{
+			"codes": models.FeedbackMessages(),
+		})
+	}
+}
+
+// AdminGetFeedbackOverridesHandler returns the feedback overrides of a tenant
+func AdminGetFeedbackOverridesHandler(feedback *services.FeedbackOverrideService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overrides, err := feedback.Get(c.Param("tenant"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Feedback overrides not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, overrides)
+	}
+}
+
+// AdminSetFeedbackOverridesHandler replaces the feedback overrides of a tenant
+func AdminSetFeedbackOverridesHandler(feedback *services.FeedbackOverrideService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := c.Param("tenant")
+		setAuditTarget(c, "feedback-overrides:"+tenant)
+
+		var request models.FeedbackOverridesRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		overrides, err := feedback.Set(tenant, request.Overrides)
+		if err != nil {
+			var validationErrors *apperrors.ValidationErrors
+			if errors.As(err, &validationErrors) {
+				respondValidationErrors(c, validationErrors)
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "Feedback overrides not saved", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, overrides)
+	}
+}
+
+// AdminDeleteFeedbackOverridesHandler removes the feedback overrides of a
+// tenant, restoring the default copy
+func AdminDeleteFeedbackOverridesHandler(feedback *services.FeedbackOverrideService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := c.Param("tenant")
+		setAuditTarget(c, "feedback-overrides:"+tenant)
+
+		if !feedback.Delete(tenant) {
+			respondError(c, http.StatusNotFound, "Feedback overrides not found", services.ErrFeedbackOverridesNotFound.Error())
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/services"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyMiddleware lets clients retry mutations safely by sending an
+// Idempotency-Key header: the first request with a key is processed and its
+// response kept, and retries with the same key and request are answered with
+// that response, marked by an Idempotent-Replayed header, instead of being
+// applied again. Keys are scoped to the caller. Requests without the header
+// are processed as usual, and server errors are not kept so that they can be
+// retried.
+func IdempotencyMiddleware(store *services.IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondError(c, http.StatusBadRequest, "Invalid idempotency key", "Idempotency-Key must be at most 255 characters")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid request", "Failed to read request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		digest := sha256.Sum256(body)
+		fingerprint := c.Request.Method + " " + c.Request.URL.Path + " " + hex.EncodeToString(digest[:])
+		scoped := GetActor(c) + "\x00" + key
+
+		state, replay := store.Begin(scoped, fingerprint)
+		switch state {
+		case services.IdempotencyReplay:
+			for name, values := range replay.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Status(replay.Status)
+			c.Writer.Write(replay.Body)
+			c.Abort()
+			return
+		case services.IdempotencyInProgress:
+			respondError(c, http.StatusConflict, "Request in progress", "A request with this Idempotency-Key is still being processed")
+			c.Abort()
+			return
+		case services.IdempotencyMismatch:
+			respondError(c, http.StatusUnprocessableEntity, "Idempotency key reused", "Idempotency-Key was already used for a different request")
+			c.Abort()
+			return
+		}
+
+		recording := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recording
+		c.Next()
+		c.Writer = recording.ResponseWriter
+
+		status := recording.Status()
+		if status >= http.StatusInternalServerError {
+			store.Abandon(scoped)
+			return
+		}
+		header := make(http.Header)
+		if contentType := recording.Header().Get("Content-Type"); contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		store.Complete(scoped, &services.IdempotentResponse{
+			Status: status,
+			Header: header,
+			Body:   recording.body.Bytes(),
+		})
+	}
+}
+
+// recordingWriter keeps a copy of a response as it is written
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/audit"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// oktaRegistrationEvent is the event type of Okta registration inline hooks
+const oktaRegistrationEvent = "com.okta.user.pre-registration"
+
+// keycloakMessages maps policy rules to Keycloak theme message keys; other
+// rules are reported with their message text
+var keycloakMessages = map[models.PolicyRule]string{
+	models.RuleMinLength:   "invalidPasswordMinLengthMessage",
+	models.RuleMaxLength:   "invalidPasswordMaxLengthMessage",
+	models.RuleUppercase:   "invalidPasswordMinUpperCaseCharsMessage",
+	models.RuleLowercase:   "invalidPasswordMinLowerCaseCharsMessage",
+	models.RuleNumbers:     "invalidPasswordMinDigitsMessage",
+	models.RuleSpecial:     "invalidPasswordMinSpecialCharsMessage",
+	models.RuleNotUsername: "invalidPasswordNotUsernameMessage",
+	models.RuleBannedWord:  "invalidPasswordBlacklistedMessage",
+}
+
+// auth0CharacterClasses are the character class rules, in the order and with
+// the texts of Auth0's password policy description
+var auth0CharacterClasses = []struct {
+	rule    models.PolicyRule
+	code    string
+	message string
+}{
+	{models.RuleLowercase, "lowerCase", "lower case letters (a-z)"},
+	{models.RuleUppercase, "upperCase", "upper case letters (A-Z)"},
+	{models.RuleNumbers, "numbers", "numbers (i.e. 0-9)"},
+	{models.RuleSpecial, "specialCharacters", "special characters (e.g. !@#$%^&*)"},
+}
+
+// auth0Codes maps the remaining policy rules to Auth0-style rule codes
+var auth0Codes = map[models.PolicyRule]string{
+	models.RuleNotUsername: "notUsername",
+	models.RuleBannedWord:  "notBanned",
+	models.RuleMinStrength: "minStrength",
+	models.RuleNotBreached: "notBreached",
+	models.RuleGenerated:   "generated",
+}
+
+// KeycloakPolicyHandler validates a password for a Keycloak password policy
+// provider. Violations are returned as Keycloak PolicyErrors, using the message
+// keys of Keycloak's built-in policies where one exists.
+func KeycloakPolicyHandler(evaluator *services.PolicyEvaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.KeycloakValidationRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		evaluation := evaluatePolicy(c, evaluator, request.Password, request.Username)
+
+		response := models.KeycloakValidationResponse{Valid: evaluation.Valid}
+		for _, check := range evaluation.Failed() {
+			policyError := models.KeycloakPolicyError{Message: check.Message, Parameters: []interface{}{}}
+			if key, ok := keycloakMessages[check.Rule]; ok {
+				policyError.Message = key
+				switch check.Rule {
+				case models.RuleMinLength, models.RuleMaxLength:
+					policyError.Parameters = []interface{}{check.Limit}
+				case models.RuleUppercase, models.RuleLowercase, models.RuleNumbers, models.RuleSpecial:
+					policyError.Parameters = []interface{}{1}
+				}
+			}
+			response.Errors = append(response.Errors, policyError)
+		}
+
+		respondJSON(c, http.StatusOK, response)
+	}
+}
+
+// Auth0PolicyHandler validates the user object of an Auth0 custom database
+// script. A rejected password gets a 400 response shaped like Auth0's
+// PasswordStrengthError, describing every rule as Auth0's password policy does.
+func Auth0PolicyHandler(evaluator *services.PolicyEvaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.Auth0ValidationRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		evaluation := evaluatePolicy(c, evaluator, request.Password, request.Username, request.Email)
+
+		response := models.Auth0ValidationResponse{
+			Verified:    evaluation.Valid,
+			Description: auth0Description(evaluation),
+		}
+		if evaluation.Valid {
+			respondJSON(c, http.StatusOK, response)
+			return
+		}
+		response.Name = "PasswordStrengthError"
+		response.Message = "Password is too weak"
+		response.Code = "invalid_password"
+		respondJSON(c, http.StatusBadRequest, response)
+	}
+}
+
+// OktaRegistrationHookHandler serves an Okta registration inline hook,
+// allowing or denying the registration based on the password in the user
+// profile. Violations become error causes shown on the registration form.
+func OktaRegistrationHookHandler(evaluator *services.PolicyEvaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.OktaInlineHookRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+		if request.EventType != oktaRegistrationEvent {
+			respondError(c, http.StatusBadRequest, "Invalid request format",
+				fmt.Sprintf("unsupported event type %q, expected %s", request.EventType, oktaRegistrationEvent))
+			return
+		}
+		password, _ := request.Data.UserProfile["password"].(string)
+		if password == "" {
+			respondError(c, http.StatusBadRequest, "Invalid request format", "data.userProfile.password is required")
+			return
+		}
+		login, _ := request.Data.UserProfile["login"].(string)
+		email, _ := request.Data.UserProfile["email"].(string)
+
+		evaluation := evaluatePolicy(c, evaluator, password, login, email)
+
+		decision := "ALLOW"
+		var hookError *models.OktaError
+		if !evaluation.Valid {
+			decision = "DENY"
+			hookError = &models.OktaError{ErrorSummary: "Password does not meet the password requirements"}
+			for _, check := range evaluation.Failed() {
+				hookError.ErrorCauses = append(hookError.ErrorCauses, models.OktaErrorCause{
+					ErrorSummary: check.Message,
+					Reason:       "INVALID_PASSWORD",
+					LocationType: "body",
+					Location:     "data.userProfile.password",
+					Domain:       "end-user",
+				})
+			}
+		}
+
+		// Okta only accepts a 200 response, also for denials
+		respondJSON(c, http.StatusOK, models.OktaInlineHookResponse{
+			Commands: []models.OktaCommand{{
+				Type:  "com.okta.action.update",
+				Value: map[string]string{"registration": decision},
+			}},
+			Error: hookError,
+		})
+	}
+}
+
+// evaluatePolicy evaluates a password and records the result for the audit log
+func evaluatePolicy(c *gin.Context, evaluator *services.PolicyEvaluator, password string, identities ...string) *models.PolicyEvaluation {
+	evaluateDone := TrackStage(c, "evaluate")
+	evaluation := evaluator.Evaluate(c.Request.Context(), password, identities...)
+	evaluateDone()
+
+	result := audit.ResultPass
+	for _, check := range evaluation.Failed() {
+		if check.Rule == models.RuleNotBreached {
+			result = audit.ResultBreached
+			break
+		}
+		result = audit.ResultWeak
+	}
+	setAuditResult(c, result)
+	setCheckScore(c, evaluation.Score)
+	setCheckViolations(c, failedRules(evaluation.Checks))
+	return evaluation
+}
+
+// auth0Description describes the evaluated rules in Auth0's format
+func auth0Description(evaluation *models.PolicyEvaluation) models.Auth0PasswordDescription {
+	checks := make(map[models.PolicyRule]models.PolicyCheck, len(evaluation.Checks))
+	for _, check := range evaluation.Checks {
+		checks[check.Rule] = check
+	}
+
+	description := models.Auth0PasswordDescription{Verified: evaluation.Valid}
+	if check, ok := checks[models.RuleMinLength]; ok {
+		description.Rules = append(description.Rules, models.Auth0PasswordRule{
+			Message: "At least %d characters in length", Format: []interface{}{check.Limit}, Code: "lengthAtLeast", Verified: check.Passed,
+		})
+	}
+	if check, ok := checks[models.RuleMaxLength]; ok {
+		description.Rules = append(description.Rules, models.Auth0PasswordRule{
+			Message: "At most %d characters in length", Format: []interface{}{check.Limit}, Code: "lengthAtMost", Verified: check.Passed,
+		})
+	}
+
+	classes := models.Auth0PasswordRule{Message: "Should contain:", Code: "shouldContain", Verified: true}
+	for _, class := range auth0CharacterClasses {
+		if check, ok := checks[class.rule]; ok {
+			classes.Items = append(classes.Items, models.Auth0PasswordRule{Message: class.message, Code: class.code, Verified: check.Passed})
+			classes.Verified = classes.Verified && check.Passed
+		}
+	}
+	if len(classes.Items) > 0 {
+		description.Rules = append(description.Rules, classes)
+	}
+
+	for _, check := range evaluation.Checks {
+		if code, ok := auth0Codes[check.Rule]; ok {
+			description.Rules = append(description.Rules, models.Auth0PasswordRule{Message: check.Message, Code: code, Verified: check.Passed})
+		}
+	}
+	return description
+}
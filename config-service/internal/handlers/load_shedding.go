@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ConcurrencyLimitMiddleware caps the number of requests processed at once. When
+// the limit is reached, further requests are shed immediately with a 503 and a
+// Retry-After header instead of queueing behind slow requests. A limit of zero
+// or less disables the middleware.
+func ConcurrencyLimitMiddleware(logger *logrus.Logger, name string, limit int, retryAfter time.Duration) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+	retryAfterSeconds := strconv.Itoa(int(retryAfter.Seconds()))
+
+	return func(c *gin.Context) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			c.Next()
+		default:
+			RequestLogger(c, logger).WithFields(logrus.Fields{
+				"limiter": name,
+				"limit":   limit,
+				"path":    c.Request.URL.Path,
+			}).Warn("Request shed: concurrency limit reached")
+
+			c.Header("Retry-After", retryAfterSeconds)
+			respondError(c, http.StatusServiceUnavailable, "Service overloaded", "too many requests in flight, retry later")
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/metrics"
+)
+
+// MetricsMiddleware records the count and duration of every request, tagged
+// with method, route template, and status code
+func MetricsMiddleware(recorder metrics.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		tags := metrics.Tags{
+			"method": c.Request.Method,
+			"route":  route,
+			"status": strconv.Itoa(c.Writer.Status()),
+		}
+
+		recorder.Count("http_requests", 1, tags)
+		recorder.Timing("http_request_duration", time.Since(start), tags)
+	}
+}
@@ -1,16 +1,83 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+
+	"config-service/internal/auth"
+	"config-service/internal/i18n"
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/reproducible"
+	"config-service/internal/requestid"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+	"config-service/internal/version"
 )
 
-// LoggingMiddleware logs HTTP requests and responses
-func LoggingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
+// accessLogSettings configures LoggingMiddleware
+type accessLogSettings struct {
+	output      *logrus.Logger
+	fields      []string
+	successRate float64
+	clientRate  float64
+	serverRate  float64
+	sample      func() float64
+}
+
+// AccessLogOption defines functional options for configuring LoggingMiddleware
+type AccessLogOption func(*accessLogSettings)
+
+// WithAccessLogger writes access logs to a dedicated logger, e.g. one with a
+// different output format
+func WithAccessLogger(output *logrus.Logger) AccessLogOption {
+	return func(s *accessLogSettings) {
+		s.output = output
+	}
+}
+
+// WithAccessLogFields selects the fields included in each access log line
+func WithAccessLogFields(fields ...string) AccessLogOption {
+	return func(s *accessLogSettings) {
+		s.fields = fields
+	}
+}
+
+// WithAccessLogSampling sets the fraction (0 to 1) of requests logged for
+// 2xx/3xx, 4xx, and 5xx responses respectively
+func WithAccessLogSampling(success, clientError, serverError float64) AccessLogOption {
+	return func(s *accessLogSettings) {
+		s.successRate = success
+		s.clientRate = clientError
+		s.serverRate = serverError
+	}
+}
+
+// LoggingMiddleware logs HTTP requests and responses. By default every request
+// is logged with all access log fields; options allow sampling by status class,
+// field selection, and a dedicated output logger.
+func LoggingMiddleware(logger *logrus.Logger, options ...AccessLogOption) gin.HandlerFunc {
+	settings := &accessLogSettings{
+		output:      logger,
+		fields:      logging.AccessLogFields,
+		successRate: 1,
+		clientRate:  1,
+		serverRate:  1,
+		sample:      reproducible.Float64,
+	}
+	for _, option := range options {
+		option(settings)
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 
@@ -20,15 +87,33 @@ func LoggingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		// Log request details
 		duration := time.Since(start)
 		statusCode := c.Writer.Status()
-		
-		entry := logger.WithFields(logrus.Fields{
-			"method":     c.Request.Method,
-			"path":       c.Request.URL.Path,
-			"status":     statusCode,
-			"duration":   duration,
-			"client_ip":  c.ClientIP(),
-			"user_agent": c.Request.UserAgent(),
-		})
+
+		rate := settings.successRate
+		switch {
+		case statusCode >= 500:
+			rate = settings.serverRate
+		case statusCode >= 400:
+			rate = settings.clientRate
+		}
+		if rate < 1 && settings.sample() >= rate {
+			return
+		}
+
+		// Only the selected fields are logged, plus the IDs needed for correlation
+		fields := make(logrus.Fields, len(settings.fields)+3)
+		if requestID := GetRequestID(c); requestID != "" {
+			fields["request_id"] = requestID
+		}
+		if traceID := GetTraceID(c); traceID != "" {
+			fields["trace_id"] = traceID
+		}
+		for _, field := range settings.fields {
+			fields[field] = accessLogField(c, field, statusCode, duration)
+		}
+		if rate < 1 {
+			fields["sample_rate"] = rate
+		}
+		entry := settings.output.WithFields(fields)
 
 		switch {
 		case statusCode >= 500:
@@ -41,6 +126,60 @@ func LoggingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 	}
 }
 
+// accessLogField returns the value of an access log field for the request
+func accessLogField(c *gin.Context, field string, status int, duration time.Duration) interface{} {
+	switch field {
+	case "method":
+		return c.Request.Method
+	case "path":
+		return c.Request.URL.Path
+	case "route":
+		return c.FullPath()
+	case "status":
+		return status
+	case "duration":
+		return duration
+	case "client_ip":
+		return GetClientIP(c)
+	case "user_agent":
+		return c.Request.UserAgent()
+	case "tenant":
+		return GetTenant(c)
+	default:
+		return nil
+	}
+}
+
+// RequestBodyLoggingMiddleware logs request bodies at debug level for debugging.
+// Bodies of sensitive routes (password checks, credential management) are never
+// logged, regardless of configuration.
+func RequestBodyLoggingMiddleware(logger *logrus.Logger, maxBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if logging.IsSensitivePath(c.Request.URL.Path) || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxBytes)+1))
+		if err != nil {
+			c.Next()
+			return
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+		if len(body) > maxBytes {
+			body = body[:maxBytes]
+		}
+		RequestLogger(c, logger).WithFields(logrus.Fields{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"body":   string(body),
+		}).Debug("HTTP request body")
+
+		c.Next()
+	}
+}
+
 // ErrorHandlingMiddleware handles errors and returns appropriate HTTP responses
 func ErrorHandlingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -49,34 +188,26 @@ func ErrorHandlingMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		// Check if there are any errors
 		if len(c.Errors) > 0 {
 			for _, err := range c.Errors {
-				logger.Errorf("Error occurred: %v", err)
+				RequestLogger(c, logger).Errorf("Error occurred: %v", err)
 			}
 
 			// Return the first error
 			c.JSON(500, gin.H{
-				"error": "Internal server error",
+				"error":      "Internal server error",
+				"message":    "the request could not be completed",
+				"request_id": GetRequestID(c),
 			})
 		}
 	}
 }
 
-// RecoveryMiddleware recovers from panics and logs them
-func RecoveryMiddleware(logger *logrus.Logger) gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		logger.Errorf("Panic recovered: %v", recovered)
-		
-		c.JSON(500, gin.H{
-			"error": "Internal server error",
-		})
-	})
-}
-
 // CORSMiddleware adds CORS headers to responses
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Header("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Accept, Accept-Language, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Request-ID, X-Captcha-Token")
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Service-Version, Server-Timing, Content-Language")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -87,17 +218,238 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RequestIDMiddleware adds a unique request ID to each request
+// AdminAuthMiddleware restricts access to the admin API to callers presenting the
+// admin token, an API key with the admin scope, or a bearer token granting it
+func AdminAuthMiddleware(token string, apiKeyService *services.APIKeyService, jwtValidator *auth.JWTValidator) gin.HandlerFunc {
+	return RotatingAdminAuthMiddleware(secrets.NewValue(token), apiKeyService, jwtValidator)
+}
+
+// RotatingAdminAuthMiddleware is AdminAuthMiddleware with an admin token that
+// can be rotated at runtime; the previous token keeps working for its grace period
+func RotatingAdminAuthMiddleware(token *secrets.Value, apiKeyService *services.APIKeyService, jwtValidator *auth.JWTValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token.Matches(provided) {
+			c.Set(actorKey, "admin_token")
+			c.Next()
+			return
+		}
+
+		if token.Get() == "" && apiKeyService == nil && jwtValidator == nil {
+			respondError(c, http.StatusForbidden, "Admin API disabled", "no admin token is configured")
+			c.Abort()
+			return
+		}
+
+		if status, err := authenticate(c, apiKeyService, jwtValidator, models.ScopeAdmin); err != nil {
+			if status == http.StatusForbidden {
+				status = http.StatusUnauthorized
+			}
+			respondError(c, status, "Unauthorized", "a valid admin token or admin credential is required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// APIKeyAuthMiddleware requires callers to present a valid API key granting the scope
+func APIKeyAuthMiddleware(apiKeyService *services.APIKeyService, scope string) gin.HandlerFunc {
+	return AuthMiddleware(apiKeyService, nil, scope)
+}
+
+// AuthMiddleware requires callers to present an API key or a bearer token granting
+// the scope. Either verifier may be nil to disable that credential type.
+func AuthMiddleware(apiKeyService *services.APIKeyService, jwtValidator *auth.JWTValidator, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(signedRequestKey) {
+			c.Next()
+			return
+		}
+
+		if status, err := authenticate(c, apiKeyService, jwtValidator, scope); err != nil {
+			errorType := "Unauthorized"
+			if status == http.StatusForbidden {
+				errorType = "Forbidden"
+			}
+			respondError(c, status, errorType, err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SignatureAuthMiddleware authenticates partner systems by HMAC request
+// signature on the given routes. Requests without signature headers pass
+// through to the other authentication middleware; a signature on any other
+// route is rejected.
+func SignatureAuthMiddleware(verifier *auth.HMACVerifier, maxBodyBytes int64, routes ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		allowed[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if c.GetHeader(auth.SignatureHeader) == "" && c.GetHeader(auth.SignatureKeyIDHeader) == "" {
+			c.Next()
+			return
+		}
+
+		if !allowed[c.FullPath()] {
+			respondError(c, http.StatusForbidden, "Forbidden", "signed requests are not accepted on this endpoint")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodyBytes+1))
+		if err != nil || int64(len(body)) > maxBodyBytes {
+			respondError(c, http.StatusRequestEntityTooLarge, "Request too large", "signed request body is too large")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := verifier.Verify(auth.SignedRequest{
+			Method:    c.Request.Method,
+			URI:       c.Request.URL.RequestURI(),
+			Body:      body,
+			KeyID:     c.GetHeader(auth.SignatureKeyIDHeader),
+			Timestamp: c.GetHeader(auth.SignatureTimestampHeader),
+			Nonce:     c.GetHeader(auth.SignatureNonceHeader),
+			Signature: c.GetHeader(auth.SignatureHeader),
+		})
+		switch {
+		case errors.Is(err, auth.ErrReplayCacheFull):
+			respondError(c, http.StatusServiceUnavailable, "Service overloaded", err.Error())
+			c.Abort()
+			return
+		case err != nil:
+			respondError(c, http.StatusUnauthorized, "Unauthorized", err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set(signedRequestKey, true)
+		c.Set(actorKey, "hmac:"+key.ID)
+		setTenant(c, key.Tenant)
+		c.Next()
+	}
+}
+
+// authenticate verifies the request credentials and stores the caller identity on
+// the request, returning the HTTP status to use when authentication fails
+func authenticate(c *gin.Context, apiKeyService *services.APIKeyService, jwtValidator *auth.JWTValidator, scope string) (int, error) {
+	if secret := extractAPIKey(c); secret != "" && apiKeyService != nil {
+		key, err := apiKeyService.Authenticate(secret)
+		if err != nil {
+			return http.StatusUnauthorized, err
+		}
+		if !key.HasScope(scope) {
+			return http.StatusForbidden, fmt.Errorf("API key does not grant the %s scope", scope)
+		}
+		setAPIKeyContext(c, key)
+		return http.StatusOK, nil
+	}
+
+	bearer := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if bearer != "" && bearer != c.GetHeader("Authorization") && jwtValidator != nil {
+		claims, err := jwtValidator.Validate(bearer)
+		if err != nil {
+			return http.StatusUnauthorized, fmt.Errorf("invalid bearer token: %w", err)
+		}
+		if !jwtValidator.Grants(claims, scope) {
+			return http.StatusForbidden, fmt.Errorf("bearer token does not grant the %s scope", scope)
+		}
+		c.Set(claimsKey, claims)
+		setTenant(c, claims.Tenant)
+		return http.StatusOK, nil
+	}
+
+	return http.StatusUnauthorized, fmt.Errorf("an API key or bearer token is required")
+}
+
+// extractAPIKey returns the API key from the X-API-Key header or a bearer token
+func extractAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+
+	bearer := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if strings.HasPrefix(bearer, "csk_") {
+		return bearer
+	}
+	return ""
+}
+
+// setAPIKeyContext stores the authenticated API key and its tenant on the request
+func setAPIKeyContext(c *gin.Context, key *models.APIKey) {
+	c.Set(apiKeyKey, key)
+	setTenant(c, key.Tenant)
+}
+
+// LocaleMiddleware negotiates the response locale from the Accept-Language header
+func LocaleMiddleware(defaultLocale string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"), defaultLocale)
+		c.Set(localeKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// RequestIDMiddleware assigns each request an ID, the start time, and the service
+// version so responses can be correlated with server logs. A well-formed inbound
+// X-Request-ID is reused; otherwise a new UUID is generated. The ID is carried on
+// the request context so services can log it and propagate it upstream.
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestID := generateRequestID()
-		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
+		requestID := c.GetHeader(requestid.Header)
+		if !requestid.IsValid(requestID) {
+			requestID = requestid.Generate()
+		}
+
+		traceID := parseTraceID(c.GetHeader(traceparentHeader))
+
+		c.Set(requestIDKey, requestID)
+		c.Set(traceIDKey, traceID)
+		c.Set(requestStartKey, time.Now())
+
+		ctx := requestid.WithContext(c.Request.Context(), requestID)
+		ctx = logging.WithFields(ctx, logrus.Fields{
+			"request_id": requestID,
+			"trace_id":   traceID,
+			"route":      c.FullPath(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(requestid.Header, requestID)
+		c.Header("X-Service-Version", version.Version)
 		c.Next()
 	}
 }
 
-// generateRequestID generates a unique request ID
-func generateRequestID() string {
-	return fmt.Sprintf("req_%d_%d", time.Now().UnixNano(), rand.Int63())
-}
\ No newline at end of file
+// RequestLogger returns a logger entry pre-populated with the request ID, trace
+// ID, route, and, once authenticated, tenant of the request. Services obtain the
+// same fields from the request context through logging.FromContext.
+func RequestLogger(c *gin.Context, logger *logrus.Logger) *logrus.Entry {
+	return logging.FromContext(c.Request.Context(), logger)
+}
+
+// traceparentHeader is the W3C Trace Context header
+const traceparentHeader = "traceparent"
+
+// parseTraceID returns the trace ID of a W3C traceparent header
+// ("00-<32 hex trace ID>-<16 hex span ID>-<2 hex flags>"), or "" if it is malformed
+func parseTraceID(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(traceID); err != nil || traceID == strings.Repeat("0", 32) {
+		return ""
+	}
+	return traceID
+}
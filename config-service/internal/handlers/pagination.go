@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/models"
+)
+
+// parseListOptions reads pagination, sorting, and filter query parameters:
+// limit, cursor, sort (name|updated_at), order (asc|desc), name_contains,
+// and updated_since (RFC 3339)
+func parseListOptions(c *gin.Context) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		Limit:        models.DefaultPageLimit,
+		Cursor:       c.Query("cursor"),
+		SortBy:       c.DefaultQuery("sort", models.SortByName),
+		NameContains: c.Query("name_contains"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 || value > models.MaxPageLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %d", models.MaxPageLimit)
+		}
+		opts.Limit = value
+	}
+
+	if opts.SortBy != models.SortByName && opts.SortBy != models.SortByUpdatedAt {
+		return opts, fmt.Errorf("sort must be %q or %q", models.SortByName, models.SortByUpdatedAt)
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, fmt.Errorf("order must be \"asc\" or \"desc\"")
+	}
+
+	if updatedSince := c.Query("updated_since"); updatedSince != "" {
+		value, err := time.Parse(time.RFC3339, updatedSince)
+		if err != nil {
+			return opts, fmt.Errorf("updated_since must be an RFC 3339 timestamp")
+		}
+		opts.UpdatedSince = value
+	}
+
+	return opts, nil
+}
@@ -1,81 +1,421 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"config-service/internal/auth"
+	apperrors "config-service/internal/errors"
+	"config-service/internal/health"
 	"config-service/internal/models"
 	"config-service/internal/services"
+	"config-service/internal/version"
 )
 
 // PasswordCheckHandler handles the password strength check endpoint
-func PasswordCheckHandler(passwordService *services.PasswordService, breachService *services.BreachService) gin.HandlerFunc {
+func PasswordCheckHandler(passwordService *services.PasswordService, breachService *services.BreachService, options ...PasswordCheckOption) gin.HandlerFunc {
+	settings := &passwordCheckSettings{}
+	for _, option := range options {
+		option(settings)
+	}
+
 	return func(c *gin.Context) {
 		var request models.PasswordRequest
 		
 		// Bind JSON request
-		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request format",
-				"message": err.Error(),
-			})
+		bindDone := TrackStage(c, "bind")
+		if err := bindJSON(c, &request); err != nil {
+			bindDone()
+			respondBindError(c, err)
 			return
 		}
+		bindDone()
 
-		// Check password strength
-		response, err := passwordService.CheckPasswordStrength(request.Password)
-		if err != nil {
-			c.JSON(http.StatusUnprocessableEntity, gin.H{
-				"error":   "Password validation failed",
-				"message": err.Error(),
-			})
+		if request.Attest && settings.attester == nil {
+			respondError(c, http.StatusBadRequest, "Attestation unavailable", "attestation tokens are not enabled on this service")
 			return
 		}
 
-		// Check for breaches if breach service is provided
+		ctx, ok := keyboardLayoutContext(c, request.KeyboardLayout)
+		if !ok {
+			return
+		}
+
+		// In explain mode, the services record the rules they run
+		var explanation *services.Explanation
+		if request.Explain {
+			explanation = &services.Explanation{}
+			ctx = services.ContextWithExplanation(ctx, explanation)
+		}
+
+		// Validate the password against the named policy, or the default rules
+		var policy *models.PolicyResult
+		if request.PolicyID != "" {
+			if policy, ok = validatePolicy(c, ctx, settings.policies, request.PolicyID, request.Password, explanation); !ok {
+				return
+			}
+		} else {
+			validateDone := TrackStage(c, "validate")
+			err := passwordService.ValidatePasswordContext(ctx, request.Password)
+			validateDone()
+			if err != nil {
+				body := errorBody(c, "Password validation failed", localizeError(c, err))
+				if explanation != nil {
+					body["explain"] = explanation.Steps()
+				}
+				respondJSON(c, http.StatusUnprocessableEntity, body)
+				return
+			}
+		}
+
+		// Check for breaches if breach service is provided, while the
+		// strength is scored, unless the policy already looked it up
+		var breach <-chan breachLookup
 		if breachService != nil {
-			breachInfo, breachErr := breachService.CheckPasswordBreach(request.Password)
-			if breachErr == nil {
+			if policy != nil && policy.Breach != nil {
+				breach = breachLookedUp(policy.Breach)
+			} else {
+				breach = lookupBreach(ctx, breachService, request.Password)
+			}
+		}
+
+		// Check password strength
+		strengthDone := TrackStage(c, "strength")
+		response := passwordService.ScorePasswordContext(ctx, request.Password)
+		strengthDone()
+
+		if breach != nil {
+			lookup := <-breach
+			GetStageTimings(c).Record("breach", lookup.duration)
+			if lookup.err == nil {
 				// Add breach information to response
-				AddBreachInfoToPasswordResponse(response, breachInfo)
+				AddBreachInfoToPasswordResponse(response, lookup.info)
+			}
+		}
+		response.Policy = policy
+		if explanation != nil {
+			response.Explain = explanation.Steps()
+		}
+
+		if request.Attest {
+			attestation, err := attest(c, settings.attester, request.AttestSubject, response)
+			if err != nil {
+				respondError(c, http.StatusInternalServerError, "Attestation failed", err.Error())
+				return
+			}
+			response.Attestation = attestation
+		}
+
+		setAuditResult(c, passwordAuditResult(response))
+		setCheckScore(c, response.Score)
+		setCheckViolations(c, passwordViolations(response))
+
+		// Return success response in the requested language
+		localizeFeedback(c, &response.Feedback, settings.feedback)
+		respondJSON(c, http.StatusOK, response)
+	}
+}
+
+// validatePolicy validates a password against a named policy, or rejects
+// the request and returns false when the policy does not exist or the
+// password fails it. The failed checks, and the explanation if the check is
+// explained, are returned with the rejection.
+func validatePolicy(c *gin.Context, ctx context.Context, policies *services.PolicyService, policyID, password string, explanation *services.Explanation) (*models.PolicyResult, bool) {
+	var result *models.PolicyResult
+	err := services.ErrPolicyNotFound
+	if policies != nil {
+		validateDone := TrackStage(c, "validate")
+		result, err = policies.Evaluate(ctx, policyID, password)
+		validateDone()
+	}
+	if err != nil {
+		respondValidationErrors(c, apperrors.NewValidationErrors([]apperrors.ValidationError{
+			apperrors.NewValidationErrorWithCode("policy_id", apperrors.ErrorCodeInvalidInput, fmt.Sprintf("unknown policy %q", policyID)),
+		}))
+		return nil, false
+	}
+
+	if failed := result.Failed(); len(failed) > 0 {
+		messages := make([]string, len(failed))
+		for i, check := range failed {
+			messages[i] = check.Message
+		}
+		body := errorBody(c, "Password validation failed", strings.Join(messages, "; "))
+		body["policy"] = result
+		if explanation != nil {
+			body["explain"] = explanation.Steps()
+		}
+		respondJSON(c, http.StatusUnprocessableEntity, body)
+		return nil, false
+	}
+	return result, true
+}
+
+// PasswordCompositionHandler handles the composition endpoint, which counts
+// the characters of a candidate password by class and locates its weak parts
+// so that a UI can highlight them as the user types
+func PasswordCompositionHandler(passwordService *services.PasswordService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.CompositionRequest
+
+		// Bind JSON request
+		bindDone := TrackStage(c, "bind")
+		if err := bindJSON(c, &request); err != nil {
+			bindDone()
+			respondBindError(c, err)
+			return
+		}
+		bindDone()
+
+		ctx, ok := keyboardLayoutContext(c, request.KeyboardLayout)
+		if !ok {
+			return
+		}
+
+		composeDone := TrackStage(c, "composition")
+		composition, err := passwordService.ComposePasswordContext(ctx, request.Password)
+		composeDone()
+		if err != nil {
+			respondError(c, http.StatusUnprocessableEntity, "Password validation failed", localizeError(c, err))
+			return
+		}
+
+		respondJSON(c, http.StatusOK, composition)
+	}
+}
+
+// PasswordGenerateHandler handles the password generation endpoint. The
+// password is never cached or logged, and is returned with its strength.
+func PasswordGenerateHandler(generator *services.GeneratorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.GenerateRequest
+
+		// Bind JSON request; an empty body generates with the defaults
+		if c.Request.ContentLength != 0 {
+			if err := bindJSON(c, &request); err != nil {
+				respondBindError(c, err)
+				return
 			}
 		}
 
-		// Return success response
-		c.JSON(http.StatusOK, response)
+		generateDone := TrackStage(c, "generate")
+		response, err := generator.Generate(request)
+		generateDone()
+		if err != nil {
+			var validationErrors *apperrors.ValidationErrors
+			if errors.As(err, &validationErrors) {
+				respondValidationErrors(c, validationErrors)
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "Password generation failed", err.Error())
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		respondJSON(c, http.StatusOK, response)
 	}
 }
 
+// PassphraseGenerateHandler handles the passphrase generation endpoint. Like
+// generated passwords, passphrases are never cached or logged.
+func PassphraseGenerateHandler(generator *services.GeneratorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.PassphraseRequest
+
+		// Bind JSON request; an empty body generates with the defaults
+		if c.Request.ContentLength != 0 {
+			if err := bindJSON(c, &request); err != nil {
+				respondBindError(c, err)
+				return
+			}
+		}
+
+		generateDone := TrackStage(c, "generate")
+		response, err := generator.GeneratePassphrase(request)
+		generateDone()
+		if err != nil {
+			var validationErrors *apperrors.ValidationErrors
+			if errors.As(err, &validationErrors) {
+				respondValidationErrors(c, validationErrors)
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "Passphrase generation failed", err.Error())
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		respondJSON(c, http.StatusOK, response)
+	}
+}
+
+// keyboardLayoutContext returns the request context carrying the keyboard
+// layout hinted by a request body, or rejects the request and returns false
+// when the hint is neither a layout nor a locale
+func keyboardLayoutContext(c *gin.Context, hint string) (context.Context, bool) {
+	ctx := c.Request.Context()
+	if hint == "" {
+		return ctx, true
+	}
+
+	layout, err := models.ParseKeyboardLayout(hint)
+	if err != nil {
+		respondValidationErrors(c, apperrors.NewValidationErrors([]apperrors.ValidationError{
+			apperrors.NewValidationErrorWithCode("keyboard_layout", apperrors.ErrorCodeInvalidInput, err.Error()),
+		}))
+		return nil, false
+	}
+	return services.ContextWithKeyboardLayout(ctx, layout), true
+}
+
+// PasswordCheckOption defines functional options for configuring PasswordCheckHandler
+type PasswordCheckOption func(*passwordCheckSettings)
+
+// passwordCheckSettings configures PasswordCheckHandler
+type passwordCheckSettings struct {
+	attester *auth.Attester
+	feedback *services.FeedbackOverrideService
+	policies *services.PolicyService
+}
+
+// WithAttester lets callers ask for a token attesting the result of the check
+func WithAttester(attester *auth.Attester) PasswordCheckOption {
+	return func(s *passwordCheckSettings) {
+		s.attester = attester
+	}
+}
+
+// WithFeedbackOverrides replaces feedback messages with the copy the
+// caller's tenant configured for them
+func WithFeedbackOverrides(feedback *services.FeedbackOverrideService) PasswordCheckOption {
+	return func(s *passwordCheckSettings) {
+		s.feedback = feedback
+	}
+}
+
+// WithPolicyService lets callers validate passwords against a named policy
+// by its policy_id
+func WithPolicyService(policies *services.PolicyService) PasswordCheckOption {
+	return func(s *passwordCheckSettings) {
+		s.policies = policies
+	}
+}
+
+// attest issues a token attesting the checked password's score and strength
+// under the policy it was validated against, and whether it was found in a
+// breach if that is known
+func attest(c *gin.Context, attester *auth.Attester, subject string, response *models.PasswordResponse) (*models.AttestationToken, error) {
+	statement := auth.Attestation{
+		Subject:  subject,
+		Score:    response.Score,
+		Strength: string(response.Strength),
+		Policy:   models.DefaultPolicyID,
+		Tenant:   GetTenant(c),
+	}
+	if response.Policy != nil {
+		statement.Policy = response.Policy.PolicyID
+	}
+	if response.BreachData != nil {
+		breached := response.BreachData.Found
+		statement.Breached = &breached
+	}
+
+	token, attestation, err := attester.Attest(statement)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AttestationToken{Token: token, KeyID: attester.KeyID(), ExpiresAt: attestation.ExpiresAt}, nil
+}
+
+// breachLookup is the outcome of a breach check run alongside strength scoring
+type breachLookup struct {
+	info     *models.BreachInfo
+	err      error
+	duration time.Duration
+}
+
+// lookupBreach checks a password for breaches in the background and delivers
+// the outcome on the returned channel. The gin context is not used off the
+// request goroutine, so the caller records the stage timing.
+func lookupBreach(ctx context.Context, breachService *services.BreachService, password string) <-chan breachLookup {
+	result := make(chan breachLookup, 1)
+	go func() {
+		start := time.Now()
+		info, err := breachService.CheckPasswordBreachContext(ctx, password)
+		result <- breachLookup{info: info, err: err, duration: time.Since(start)}
+	}()
+	return result
+}
+
+// breachLookedUp delivers the outcome of a breach lookup already made
+func breachLookedUp(info *models.BreachInfo) <-chan breachLookup {
+	result := make(chan breachLookup, 1)
+	result <- breachLookup{info: info}
+	return result
+}
+
 // HealthCheckHandler handles the health check endpoint
 func HealthCheckHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	respondJSON(c, http.StatusOK, gin.H{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"version":   version.Version,
 	})
 }
 
+// DeepHealthCheckHandler reports the status of each dependency and an overall
+// status. It responds 503 only when the service is unhealthy, so degraded
+// instances stay in rotation.
+func DeepHealthCheckHandler(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Run(c.Request.Context())
+
+		status := http.StatusOK
+		if report.Status == health.StatusUnhealthy {
+			status = http.StatusServiceUnavailable
+		}
+
+		respondJSON(c, status, report)
+	}
+}
+
+// VersionHandler handles the version and build information endpoint
+func VersionHandler(features map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, version.Get(features))
+	}
+}
+
+// AttestationJWKSHandler publishes the key attestation tokens are signed with,
+// as a JSON Web Key Set
+func AttestationJWKSHandler(attester *auth.Attester) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		respondJSON(c, http.StatusOK, attester.JWKS())
+	}
+}
+
 // GetPasswordRequirementsHandler handles getting password requirements for a given password
 func GetPasswordRequirementsHandler(passwordService *services.PasswordService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request models.PasswordRequest
 		
 		// Bind JSON request
-		if err := c.ShouldBindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request format",
-				"message": err.Error(),
-			})
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
 			return
 		}
 
 		// Get requirements
 		requirements := passwordService.GetPasswordRequirements(request.Password)
 
-		c.JSON(http.StatusOK, gin.H{
+		respondJSON(c, http.StatusOK, gin.H{
 			"requirements": requirements,
 		})
 	}
-}
\ No newline at end of file
+}
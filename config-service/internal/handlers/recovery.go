@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PanicEvent describes a recovered panic
+type PanicEvent struct {
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	Panic     string    `json:"panic"`
+	Stack     string    `json:"stack"`
+	Time      time.Time `json:"time"`
+}
+
+// PanicHook is notified of every recovered panic, e.g. to forward it to an
+// error-reporting service
+type PanicHook func(event PanicEvent)
+
+// RecoveryMiddleware recovers from panics, logs them with their stack trace and
+// request context, notifies the hooks, and returns a 500 response
+func RecoveryMiddleware(logger *logrus.Logger, hooks ...PanicHook) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			event := PanicEvent{
+				RequestID: GetRequestID(c),
+				Method:    c.Request.Method,
+				Route:     c.FullPath(),
+				Path:      c.Request.URL.Path,
+				Panic:     fmt.Sprint(recovered),
+				Stack:     string(debug.Stack()),
+				Time:      time.Now().UTC(),
+			}
+
+			RequestLogger(c, logger).WithFields(logrus.Fields{
+				"method": event.Method,
+				"route":  event.Route,
+				"path":   event.Path,
+				"stack":  event.Stack,
+			}).Errorf("Panic recovered: %v", recovered)
+
+			for _, hook := range hooks {
+				hook(event)
+			}
+
+			if recovered == http.ErrAbortHandler {
+				// The handler deliberately aborted the response; nothing can be written
+				c.Abort()
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":      "Internal server error",
+				"message":    "the request could not be completed",
+				"request_id": event.RequestID,
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+// NewPanicWebhookHook returns a hook that posts panic events as JSON to a webhook URL.
+// Deliveries run in the background so they never delay the error response.
+func NewPanicWebhookHook(logger *logrus.Logger, url string, timeout time.Duration) PanicHook {
+	client := &http.Client{Timeout: timeout}
+
+	return func(event PanicEvent) {
+		go func() {
+			body, err := json.Marshal(event)
+			if err != nil {
+				logger.Errorf("Failed to encode panic event: %v", err)
+				return
+			}
+
+			resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				logger.Errorf("Failed to deliver panic notification: %v", err)
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode >= 300 {
+				logger.Errorf("Panic notification webhook returned status %d", resp.StatusCode)
+			}
+		}()
+	}
+}
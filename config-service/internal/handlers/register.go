@@ -0,0 +1,448 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/audit"
+	"config-service/internal/auth"
+	"config-service/internal/clientip"
+	"config-service/internal/config"
+	"config-service/internal/errorreport"
+	"config-service/internal/health"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+// Options configures the routes and middleware mounted by Register. Only
+// Logger and PasswordService are required; a nil service leaves out the
+// routes and middleware that need it.
+type Options struct {
+	Logger *logrus.Logger
+
+	// Request handling
+	AccessLog            []AccessLogOption
+	RequestBodyMaxBytes  int // request bodies are logged when positive
+	SlowRequestThreshold time.Duration
+	Metrics              metrics.Recorder
+	MetricsPath          string
+	MetricsHandler       http.Handler
+	PanicHooks           []PanicHook
+	ErrorReporter        errorreport.Reporter
+	MaxInFlight          int
+	BreachMaxInFlight    int
+	BulkMaxBodyBytes     int64 // bulk request bodies are unbounded unless positive
+	ShedRetryAfter       time.Duration
+	ClientIP             *clientip.Resolver // Gin's client IP is used when nil
+	DefaultLocale        string
+	StrictSchema         bool // reject request bodies with fields their models lack
+	ValidateResponses    bool // log responses that do not match their models
+
+	// Services
+	PasswordService   *services.PasswordService
+	BreachService     *services.BreachService
+	GeneratorService  *services.GeneratorService
+	BannedListService *services.BannedListService
+	BannedImports     *services.BannedImportService
+	DatasetReloader   *services.DatasetReloader
+	PolicyService     *services.PolicyService
+	UsageService      *services.UsageService
+	AdmissionService  *services.SecretAdmissionService
+	PolicyEvaluator   *services.PolicyEvaluator
+	ADFilterService   *services.ADFilterService
+	HealthChecker     *health.Checker
+	AuditLogger       *audit.Logger
+	WebhookService    *services.WebhookService
+	Events            services.EventPublisher // the webhook service when nil
+	ReuseService      *services.ReuseService
+	AnalyticsService  *services.AnalyticsService
+	FeedbackService   *services.FeedbackOverrideService
+	Features          map[string]bool
+
+	// Authentication of the password endpoints
+	APIKeyService    *services.APIKeyService
+	APIKeysEnabled   bool
+	JWTValidator     *auth.JWTValidator
+	HMACVerifier     *auth.HMACVerifier
+	Attester         *auth.Attester
+	HMACMaxBodyBytes int64
+	OracleThrottle   gin.HandlerFunc
+
+	// Admin API
+	AdminToken       *secrets.Value
+	Config           *config.Config
+	ConfigSchema     *config.JSONSchema
+	SecretRotator    *config.SecretRotator
+	LogController    *logging.Controller
+	IdempotencyStore *services.IdempotencyStore // a default store is used when nil
+	ImportMaxBytes   int64                      // banned list uploads are unbounded unless positive
+
+	// Internal endpoints
+	SeparateAdmin bool // leave the admin API, metrics, deep health checks, and admission webhook to RegisterAdmin
+	Pprof         bool // serve runtime profiles from RegisterAdmin under /debug/pprof
+}
+
+// Register mounts the password API and its middleware onto group, so it can
+// be served by the service's own router or embedded in another Gin
+// application. Routes are registered under /api/v1 relative to the group,
+// and the middleware only applies to them.
+func Register(group *gin.RouterGroup, opts Options) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.New(os.Stdout)
+	}
+	recorder := opts.Metrics
+	if recorder == nil {
+		recorder = metrics.Noop{}
+	}
+	passThrough := func(c *gin.Context) { c.Next() }
+	oracleThrottle := opts.OracleThrottle
+	if oracleThrottle == nil {
+		oracleThrottle = passThrough
+	}
+
+	// Add middleware
+	group.Use(RequestIDMiddleware())
+	if opts.ClientIP != nil {
+		group.Use(ClientIPMiddleware(opts.ClientIP))
+	}
+	group.Use(MetricsMiddleware(recorder))
+	group.Use(RecoveryMiddleware(logger, opts.PanicHooks...))
+	if opts.ErrorReporter != nil {
+		group.Use(ErrorReportingMiddleware(opts.ErrorReporter))
+	}
+	group.Use(ConcurrencyLimitMiddleware(logger, "server", opts.MaxInFlight, opts.ShedRetryAfter))
+	group.Use(CORSMiddleware())
+	group.Use(LocaleMiddleware(opts.DefaultLocale))
+	group.Use(LoggingMiddleware(logger, opts.AccessLog...))
+	if opts.RequestBodyMaxBytes > 0 {
+		group.Use(RequestBodyLoggingMiddleware(logger, opts.RequestBodyMaxBytes))
+	}
+	if opts.SlowRequestThreshold > 0 {
+		group.Use(SlowRequestMiddleware(logger, recorder, opts.SlowRequestThreshold))
+	}
+	if opts.ValidateResponses {
+		group.Use(ResponseSchemaMiddleware(logger, group.BasePath()))
+	}
+	group.Use(ErrorHandlingMiddleware(logger))
+	if opts.StrictSchema {
+		group.Use(StrictSchemaMiddleware())
+	}
+
+	// Health check endpoint for load balancers; the internal endpoints are
+	// left out when they have a listener of their own
+	group.GET("/api/v1/health", HealthCheckHandler)
+	if !opts.SeparateAdmin {
+		registerObservability(group, opts)
+	}
+
+	// Version and build information endpoint
+	group.GET("/api/v1/version", VersionHandler(opts.Features))
+
+	// Key set verifying attestation tokens, public like the tokens themselves
+	if opts.Attester != nil {
+		group.GET("/api/v1/attestation/jwks", AttestationJWKSHandler(opts.Attester))
+	}
+
+	// Password endpoints, optionally protected by API key and/or bearer token authentication
+	password := group.Group("/api/v1/password")
+	if opts.AuditLogger != nil {
+		password.Use(AuditMiddleware(opts.AuditLogger))
+	}
+	if opts.Events != nil {
+		password.Use(WebhookEventsMiddleware(opts.Events))
+	} else if opts.WebhookService != nil {
+		password.Use(WebhookEventsMiddleware(opts.WebhookService))
+	}
+	breachLimit := ConcurrencyLimitMiddleware(logger, "breach", opts.BreachMaxInFlight, opts.ShedRetryAfter)
+	bulkBodyLimit := passThrough
+	if opts.BulkMaxBodyBytes > 0 {
+		bulkBodyLimit = BodyLimitMiddleware(opts.BulkMaxBodyBytes)
+	}
+	if opts.HMACVerifier != nil {
+		// Partner bulk audits, and webhooks from senders that can sign them
+		signedRoutes := []string{"/breach-audit", "/idp/keycloak", "/idp/auth0", "/idp/okta", "/ad-filter"}
+		for i, route := range signedRoutes {
+			signedRoutes[i] = password.BasePath() + route
+		}
+		password.Use(SignatureAuthMiddleware(opts.HMACVerifier, opts.HMACMaxBodyBytes, signedRoutes...))
+	}
+	switch {
+	case opts.APIKeysEnabled && opts.JWTValidator != nil:
+		password.Use(AuthMiddleware(opts.APIKeyService, opts.JWTValidator, models.ScopeCheck))
+	case opts.APIKeysEnabled:
+		password.Use(APIKeyAuthMiddleware(opts.APIKeyService, models.ScopeCheck))
+	case opts.JWTValidator != nil:
+		password.Use(AuthMiddleware(nil, opts.JWTValidator, models.ScopeCheck))
+	default:
+		logger.Warn("Authentication is disabled: password endpoints are unauthenticated")
+	}
+	if opts.UsageService != nil {
+		password.Use(UsageQuotaMiddleware(logger, opts.UsageService, recorder))
+	}
+	if opts.AnalyticsService != nil {
+		password.Use(AnalyticsMiddleware(opts.AnalyticsService))
+	}
+	var checkOptions []PasswordCheckOption
+	if opts.Attester != nil {
+		checkOptions = append(checkOptions, WithAttester(opts.Attester))
+	}
+	if opts.FeedbackService != nil {
+		checkOptions = append(checkOptions, WithFeedbackOverrides(opts.FeedbackService))
+	}
+	if opts.PolicyService != nil {
+		checkOptions = append(checkOptions, WithPolicyService(opts.PolicyService))
+	}
+	{
+		// Password strength check endpoint (now with breach detection)
+		password.POST("/check", oracleThrottle, breachLimit, PasswordCheckHandler(opts.PasswordService, opts.BreachService, checkOptions...))
+
+		// Character classes and weak parts of a candidate, for inline highlighting
+		password.POST("/composition", oracleThrottle, PasswordCompositionHandler(opts.PasswordService))
+
+		// Random passwords and passphrases, scored like checked ones
+		if opts.GeneratorService != nil {
+			password.POST("/generate", PasswordGenerateHandler(opts.GeneratorService))
+			password.POST("/generate-passphrase", PassphraseGenerateHandler(opts.GeneratorService))
+		}
+
+		if opts.BreachService != nil {
+			// Password breach check endpoint
+			password.POST("/breach-check", oracleThrottle, breachLimit, BreachCheckHandler(opts.BreachService))
+
+			// Bulk SHA-1 breach audit endpoint (hashes only, never plaintext)
+			password.POST("/breach-audit", bulkBodyLimit, breachLimit, BreachAuditHandler(opts.BreachService))
+		}
+
+		// Password validation webhooks in the formats of identity providers,
+		// which call from a few addresses and so are not throttled per client
+		if opts.PolicyEvaluator != nil {
+			password.POST("/idp/keycloak", breachLimit, KeycloakPolicyHandler(opts.PolicyEvaluator))
+			password.POST("/idp/auth0", breachLimit, Auth0PolicyHandler(opts.PolicyEvaluator))
+			password.POST("/idp/okta", breachLimit, OktaRegistrationHookHandler(opts.PolicyEvaluator))
+		}
+
+		// Password reuse across the accounts of a tenant, from keyed digests
+		if opts.ReuseService != nil {
+			password.POST("/reuse", PasswordReuseHandler(opts.ReuseService))
+			password.DELETE("/reuse/:account", ForgetPasswordReuseHandler(opts.ReuseService))
+		}
+
+		// Verdicts for Active Directory password filters, which send NTLM hashes
+		if opts.ADFilterService != nil {
+			password.POST("/ad-filter", breachLimit, ADFilterHandler(opts.ADFilterService))
+		}
+	}
+
+	if !opts.SeparateAdmin {
+		registerAdmission(group, breachLimit, opts)
+		registerAdmin(group, logger, opts)
+	}
+}
+
+// RegisterAdmin mounts the internal endpoints onto group: the admin API, the
+// Prometheus scrape endpoint, the deep health check, the admission webhook,
+// and, with Pprof, the runtime profiles. Used with SeparateAdmin, it serves
+// them on a listener apart from the public API under middleware of their own,
+// without CORS, since browsers are not clients, and without load shedding, so
+// the service stays observable and manageable while the public API is
+// overloaded.
+func RegisterAdmin(group *gin.RouterGroup, opts Options) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.New(os.Stdout)
+	}
+	recorder := opts.Metrics
+	if recorder == nil {
+		recorder = metrics.Noop{}
+	}
+
+	// Add middleware
+	group.Use(RequestIDMiddleware())
+	if opts.ClientIP != nil {
+		group.Use(ClientIPMiddleware(opts.ClientIP))
+	}
+	group.Use(MetricsMiddleware(recorder))
+	group.Use(RecoveryMiddleware(logger, opts.PanicHooks...))
+	if opts.ErrorReporter != nil {
+		group.Use(ErrorReportingMiddleware(opts.ErrorReporter))
+	}
+	group.Use(LoggingMiddleware(logger, opts.AccessLog...))
+	if opts.ValidateResponses {
+		group.Use(ResponseSchemaMiddleware(logger, group.BasePath()))
+	}
+	group.Use(ErrorHandlingMiddleware(logger))
+	if opts.StrictSchema {
+		group.Use(StrictSchemaMiddleware())
+	}
+
+	group.GET("/api/v1/health", HealthCheckHandler)
+	registerObservability(group, opts)
+	if opts.Pprof {
+		registerProfiles(group)
+	}
+	registerAdmission(group, ConcurrencyLimitMiddleware(logger, "breach", opts.BreachMaxInFlight, opts.ShedRetryAfter), opts)
+	registerAdmin(group, logger, opts)
+}
+
+// registerAdmission mounts the Kubernetes validating admission webhook for
+// Secrets. The API server authenticates to it with a client certificate, not
+// API keys, so without SeparateAdmin the listener must require one.
+func registerAdmission(group *gin.RouterGroup, breachLimit gin.HandlerFunc, opts Options) {
+	if opts.AdmissionService != nil {
+		group.POST("/api/v1/admission/secrets", breachLimit, AdmissionWebhookHandler(opts.AdmissionService))
+	}
+}
+
+// registerObservability mounts the Prometheus scrape endpoint and the deep
+// health check, which report on dependencies
+func registerObservability(group *gin.RouterGroup, opts Options) {
+	if opts.MetricsHandler != nil {
+		group.GET(opts.MetricsPath, gin.WrapH(opts.MetricsHandler))
+	}
+	if opts.HealthChecker != nil {
+		group.GET("/api/v1/health/deep", DeepHealthCheckHandler(opts.HealthChecker))
+	}
+}
+
+// registerProfiles mounts the net/http/pprof endpoints. They are not
+// authenticated, so they are only served on the internal listener.
+func registerProfiles(group *gin.RouterGroup) {
+	profiles := group.Group("/debug/pprof")
+	profiles.GET("/", gin.WrapF(pprof.Index))
+	profiles.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	profiles.GET("/profile", gin.WrapF(pprof.Profile))
+	profiles.GET("/symbol", gin.WrapF(pprof.Symbol))
+	profiles.POST("/symbol", gin.WrapF(pprof.Symbol))
+	profiles.GET("/trace", gin.WrapF(pprof.Trace))
+	profiles.GET("/:name", func(c *gin.Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// registerAdmin mounts the admin API when admin authentication is configured
+func registerAdmin(group *gin.RouterGroup, logger *logrus.Logger, opts Options) {
+	passThrough := func(c *gin.Context) { c.Next() }
+	if opts.AdminToken == nil && opts.APIKeyService == nil && opts.JWTValidator == nil {
+		return
+	}
+	adminToken := opts.AdminToken
+	if adminToken == nil {
+		adminToken = secrets.NewValue("")
+	}
+
+	// Admin API, separated from the public API by admin token or admin API key
+	admin := group.Group("/api/v1/admin")
+	if opts.AuditLogger != nil {
+		admin.Use(AuditMiddleware(opts.AuditLogger))
+	}
+	admin.Use(RotatingAdminAuthMiddleware(adminToken, opts.APIKeyService, opts.JWTValidator))
+
+	// Configuration that clients sync answers conditional GETs
+	conditionalGet := ConditionalGetMiddleware()
+
+	// Mutations that automation retries are replayed by Idempotency-Key
+	idempotencyStore := opts.IdempotencyStore
+	if idempotencyStore == nil {
+		idempotencyStore = services.NewIdempotencyStore()
+	}
+	idempotent := IdempotencyMiddleware(idempotencyStore)
+	{
+		if opts.BreachService != nil {
+			admin.GET("/cache/stats", AdminCacheStatsHandler(opts.BreachService))
+			admin.POST("/cache/flush", AdminCacheFlushHandler(opts.BreachService))
+		}
+		if opts.Config != nil {
+			admin.GET("/config", AdminConfigHandler(opts.Config))
+		}
+		if opts.ConfigSchema != nil {
+			admin.GET("/config/schema", AdminConfigSchemaHandler(opts.ConfigSchema))
+		}
+		if opts.SecretRotator != nil {
+			admin.POST("/secrets/rotate", AdminRotateSecretsHandler(opts.SecretRotator))
+		}
+		if opts.LogController != nil {
+			admin.GET("/logging", AdminGetLoggingHandler(opts.LogController))
+			admin.PUT("/logging", AdminUpdateLoggingHandler(opts.LogController))
+		}
+		if opts.BannedListService != nil {
+			admin.GET("/banned-words", conditionalGet, AdminListBannedWordsHandler(opts.BannedListService))
+			admin.POST("/banned-words", idempotent, AdminAddBannedWordsHandler(opts.BannedListService))
+			admin.DELETE("/banned-words/:word", idempotent, AdminDeleteBannedWordHandler(opts.BannedListService))
+			admin.GET("/banned-words/deleted", AdminListDeletedBannedWordsHandler(opts.BannedListService))
+			admin.POST("/banned-words/:word/restore", idempotent, AdminRestoreBannedWordHandler(opts.BannedListService))
+		}
+		if opts.BannedImports != nil {
+			// Uploads are streamed rather than buffered for Idempotency-Key
+			// replays; importing the same list twice changes nothing anyway
+			importBodyLimit := passThrough
+			if opts.ImportMaxBytes > 0 {
+				importBodyLimit = BodyLimitMiddleware(opts.ImportMaxBytes)
+			}
+			admin.POST("/banned-words/imports", importBodyLimit, AdminImportBannedWordsHandler(opts.BannedImports))
+			admin.GET("/banned-words/imports", AdminListBannedImportsHandler(opts.BannedImports))
+			admin.GET("/banned-words/imports/:id", AdminGetBannedImportHandler(opts.BannedImports))
+		}
+		if opts.DatasetReloader != nil {
+			admin.GET("/datasets", AdminListDatasetsHandler(opts.DatasetReloader))
+			admin.POST("/datasets/:name/reload", AdminReloadDatasetHandler(opts.DatasetReloader))
+			admin.GET("/datasets/reloads", AdminListDatasetReloadsHandler(opts.DatasetReloader))
+			admin.GET("/datasets/reloads/:id", AdminGetDatasetReloadHandler(opts.DatasetReloader))
+		}
+		if opts.PolicyService != nil {
+			admin.GET("/policies", conditionalGet, AdminListPoliciesHandler(opts.PolicyService))
+			admin.GET("/policies/:id", AdminGetPolicyHandler(opts.PolicyService))
+			admin.PUT("/policies/:id", idempotent, AdminPutPolicyHandler(opts.PolicyService))
+			admin.DELETE("/policies/:id", idempotent, AdminDeletePolicyHandler(opts.PolicyService))
+			admin.GET("/policies/deleted", AdminListDeletedPoliciesHandler(opts.PolicyService))
+			admin.POST("/policies/:id/restore", idempotent, AdminRestorePolicyHandler(opts.PolicyService))
+		}
+		if opts.FeedbackService != nil {
+			admin.GET("/feedback-codes", conditionalGet, AdminListFeedbackCodesHandler())
+			admin.GET("/feedback-overrides/:tenant", conditionalGet, AdminGetFeedbackOverridesHandler(opts.FeedbackService))
+			admin.PUT("/feedback-overrides/:tenant", idempotent, AdminSetFeedbackOverridesHandler(opts.FeedbackService))
+			admin.DELETE("/feedback-overrides/:tenant", idempotent, AdminDeleteFeedbackOverridesHandler(opts.FeedbackService))
+		}
+		if dictionaries := opts.PasswordService.Dictionaries(); len(dictionaries) > 0 {
+			admin.GET("/wordlists", conditionalGet, AdminListWordlistsHandler(dictionaries))
+			admin.GET("/wordlists/:name", AdminGetWordlistHandler(dictionaries))
+		}
+		if opts.APIKeyService != nil {
+			admin.GET("/api-keys", AdminListAPIKeysHandler(opts.APIKeyService))
+			admin.POST("/api-keys", AdminIssueAPIKeyHandler(opts.APIKeyService))
+			admin.POST("/api-keys/:id/rotate", AdminRotateAPIKeyHandler(opts.APIKeyService))
+			admin.DELETE("/api-keys/:id", AdminRevokeAPIKeyHandler(opts.APIKeyService))
+		}
+		if opts.UsageService != nil {
+			admin.GET("/usage", AdminUsageHandler(opts.UsageService))
+			admin.GET("/usage/export", AdminUsageExportHandler(opts.UsageService))
+		}
+		if opts.AnalyticsService != nil {
+			admin.GET("/analytics", AdminAnalyticsHandler(opts.AnalyticsService))
+		}
+		if opts.WebhookService != nil {
+			admin.GET("/webhooks", AdminListWebhooksHandler(opts.WebhookService))
+			admin.POST("/webhooks", idempotent, AdminCreateWebhookHandler(opts.WebhookService))
+			admin.GET("/webhooks/dead-letters", AdminListDeadLettersHandler(opts.WebhookService))
+			admin.POST("/webhooks/dead-letters/:id/retry", idempotent, AdminRetryDeadLetterHandler(opts.WebhookService))
+			admin.DELETE("/webhooks/dead-letters/:id", idempotent, AdminDeleteDeadLetterHandler(opts.WebhookService))
+			admin.GET("/webhooks/:id", AdminGetWebhookHandler(opts.WebhookService))
+			admin.GET("/webhooks/:id/deliveries", AdminListWebhookDeliveriesHandler(opts.WebhookService))
+			admin.DELETE("/webhooks/:id", idempotent, AdminDeleteWebhookHandler(opts.WebhookService))
+		}
+		if opts.AuditLogger != nil {
+			admin.GET("/audit/export", AdminAuditExportHandler(opts.AuditLogger))
+		}
+	}
+	if adminToken.Get() == "" {
+		logger.Warn("No admin token configured: admin API is only reachable with admin API keys")
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/auth"
+	"config-service/internal/i18n"
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+const (
+	// requestIDKey is the context key holding the request ID
+	requestIDKey = "request_id"
+
+	// traceIDKey is the context key holding the W3C trace ID of the request, if any
+	traceIDKey = "trace_id"
+
+	// requestStartKey is the context key holding the time the request started
+	requestStartKey = "request_start"
+
+	// stageTimingsKey is the context key holding the per-stage durations
+	stageTimingsKey = "stage_timings"
+
+	// localeKey is the context key holding the negotiated response locale
+	localeKey = "locale"
+
+	// apiKeyKey is the context key holding the authenticated API key
+	apiKeyKey = "api_key"
+
+	// tenantKey is the context key holding the tenant of the authenticated caller
+	tenantKey = "tenant"
+
+	// claimsKey is the context key holding validated bearer token claims
+	claimsKey = "jwt_claims"
+
+	// actorKey is the context key holding an explicit caller identity, such as the admin token
+	actorKey = "actor"
+
+	// signedRequestKey is the context key marking a request authenticated by an HMAC signature
+	signedRequestKey = "signed_request"
+
+	// auditResultKey is the context key holding the audit result class set by a handler
+	auditResultKey = "audit_result"
+
+	// auditTargetKey is the context key holding what an admin operation acted on
+	auditTargetKey = "audit_target"
+
+	// checkScoreKey is the context key holding the strength score of a password check
+	checkScoreKey = "check_score"
+
+	// checkViolationsKey is the context key holding the policy rules a checked password failed
+	checkViolationsKey = "check_violations"
+
+	// clientIPKey is the context key holding the client IP derived by ClientIPMiddleware
+	clientIPKey = "client_ip"
+
+	// quotaExceededKey is the context key marking a request rejected over its monthly quota
+	quotaExceededKey = "quota_exceeded"
+
+	// strictSchemaKey is the context key marking a request whose body is bound strictly
+	strictSchemaKey = "strict_schema"
+)
+
+// stageTiming records how long a single processing stage took
+type stageTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// StageTimings collects per-stage durations for a single request
+type StageTimings struct {
+	stages []stageTiming
+}
+
+// Record adds a stage duration
+func (t *StageTimings) Record(name string, duration time.Duration) {
+	t.stages = append(t.stages, stageTiming{name: name, duration: duration})
+}
+
+// Durations returns the recorded stage durations keyed by stage name
+func (t *StageTimings) Durations() map[string]time.Duration {
+	durations := make(map[string]time.Duration, len(t.stages))
+	for _, stage := range t.stages {
+		durations[stage.name] += stage.duration
+	}
+	return durations
+}
+
+// ServerTiming formats the recorded stages as a Server-Timing header value
+func (t *StageTimings) ServerTiming(total time.Duration) string {
+	parts := make([]string, 0, len(t.stages)+1)
+	for _, stage := range t.stages {
+		parts = append(parts, formatServerTiming(stage.name, stage.duration))
+	}
+	parts = append(parts, formatServerTiming("total", total))
+	return strings.Join(parts, ", ")
+}
+
+// formatServerTiming formats a single Server-Timing metric in milliseconds
+func formatServerTiming(name string, duration time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(duration)/float64(time.Millisecond))
+}
+
+// GetStageTimings returns the stage timings for the request, creating them if needed
+func GetStageTimings(c *gin.Context) *StageTimings {
+	if value, exists := c.Get(stageTimingsKey); exists {
+		if timings, ok := value.(*StageTimings); ok {
+			return timings
+		}
+	}
+
+	timings := &StageTimings{}
+	c.Set(stageTimingsKey, timings)
+	return timings
+}
+
+// TrackStage starts timing a stage and returns a function that records it when called
+func TrackStage(c *gin.Context, name string) func() {
+	start := time.Now()
+	return func() {
+		GetStageTimings(c).Record(name, time.Since(start))
+	}
+}
+
+// GetRequestID returns the request ID assigned by RequestIDMiddleware
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// GetTraceID returns the W3C trace ID propagated with the request, if any
+func GetTraceID(c *gin.Context) string {
+	return c.GetString(traceIDKey)
+}
+
+// GetAPIKey returns the API key the request was authenticated with, if any
+func GetAPIKey(c *gin.Context) *models.APIKey {
+	if value, exists := c.Get(apiKeyKey); exists {
+		if key, ok := value.(*models.APIKey); ok {
+			return key
+		}
+	}
+	return nil
+}
+
+// GetClaims returns the bearer token claims the request was authenticated with, if any
+func GetClaims(c *gin.Context) *auth.Claims {
+	if value, exists := c.Get(claimsKey); exists {
+		if claims, ok := value.(*auth.Claims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// GetTenant returns the tenant of the authenticated caller, if any
+func GetTenant(c *gin.Context) string {
+	return c.GetString(tenantKey)
+}
+
+// setTenant records the tenant of the authenticated caller, including on the
+// request-scoped logger
+func setTenant(c *gin.Context, tenant string) {
+	c.Set(tenantKey, tenant)
+	c.Request = c.Request.WithContext(logging.WithFields(c.Request.Context(), logrus.Fields{"tenant": tenant}))
+}
+
+// GetActor returns an identifier for the authenticated caller that is safe to
+// record: the API key ID, the bearer token subject, the client certificate
+// common name, or "anonymous"
+func GetActor(c *gin.Context) string {
+	if actor := c.GetString(actorKey); actor != "" {
+		return actor
+	}
+	if key := GetAPIKey(c); key != nil {
+		return key.ID
+	}
+	if claims := GetClaims(c); claims != nil && claims.Subject != "" {
+		return "jwt:" + claims.Subject
+	}
+	if tls := c.Request.TLS; tls != nil && len(tls.VerifiedChains) > 0 {
+		return "mtls:" + tls.VerifiedChains[0][0].Subject.CommonName
+	}
+	return "anonymous"
+}
+
+// GetLocale returns the locale negotiated by LocaleMiddleware
+func GetLocale(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
+
+// localizeFeedback translates feedback warnings and suggestions into the
+// request locale, using the copy of the caller's tenant for the messages it
+// overrides when overrides are given
+func localizeFeedback(c *gin.Context, feedback *models.PasswordFeedback, overrides *services.FeedbackOverrideService) {
+	locale := GetLocale(c)
+	tenant := GetTenant(c)
+	if overrides == nil || tenant == "" {
+		feedback.Warnings = i18n.TranslateAll(locale, feedback.Warnings)
+		feedback.Suggestions = i18n.TranslateAll(locale, feedback.Suggestions)
+		return
+	}
+
+	localize := func(messages []string) []string {
+		localized := make([]string, len(messages))
+		for i, message := range messages {
+			if override, ok := overrides.Message(tenant, locale, message); ok {
+				localized[i] = override
+			} else {
+				localized[i] = i18n.Translate(locale, message)
+			}
+		}
+		return localized
+	}
+	feedback.Warnings = localize(feedback.Warnings)
+	feedback.Suggestions = localize(feedback.Suggestions)
+}
+
+// localizeError returns the error message in the request locale, translating the
+// most specific wrapped error that has a translation
+func localizeError(c *gin.Context, err error) string {
+	locale := GetLocale(c)
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if i18n.HasTranslation(locale, current.Error()) {
+			return i18n.Translate(locale, current.Error())
+		}
+	}
+	return err.Error()
+}
+
+// writeResponseMetadata sets the timing header before the response body is written
+func writeResponseMetadata(c *gin.Context) {
+	var total time.Duration
+	if start, ok := c.Get(requestStartKey); ok {
+		if startTime, ok := start.(time.Time); ok {
+			total = time.Since(startTime)
+		}
+	}
+
+	c.Header("Server-Timing", GetStageTimings(c).ServerTiming(total))
+}
+
+// respondJSON writes a JSON response together with the request metadata headers
+func respondJSON(c *gin.Context, status int, body interface{}) {
+	writeResponseMetadata(c)
+	c.JSON(status, body)
+}
+
+// respondError writes a JSON error response that carries the request ID
+func respondError(c *gin.Context, status int, errorType string, message string) {
+	respondJSON(c, status, errorBody(c, errorType, message))
+}
+
+// errorBody returns the body of an error response, with the error type in the
+// request locale and the request ID
+func errorBody(c *gin.Context, errorType string, message string) gin.H {
+	body := gin.H{
+		"error":   i18n.Translate(GetLocale(c), errorType),
+		"message": message,
+	}
+	if requestID := GetRequestID(c); requestID != "" {
+		body["request_id"] = requestID
+	}
+	return body
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/audit"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// PasswordReuseHandler records the password digest of an account in the
+// caller's tenant and reports whether other accounts of the tenant share it
+func PasswordReuseHandler(reuse *services.ReuseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.ReuseRequest
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		result, err := reuse.Record(GetTenant(c), request.Account, request.Digest)
+		if errors.Is(err, services.ErrReuseCapacity) {
+			respondError(c, http.StatusInsufficientStorage, "Reuse tracking full", err.Error())
+			return
+		}
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid request format", err.Error())
+			return
+		}
+
+		if result.Reused {
+			setAuditResult(c, audit.ResultReused)
+		} else {
+			setAuditResult(c, audit.ResultPass)
+		}
+		respondJSON(c, http.StatusOK, result)
+	}
+}
+
+// ForgetPasswordReuseHandler stops tracking an account of the caller's tenant
+func ForgetPasswordReuseHandler(reuse *services.ReuseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !reuse.Forget(GetTenant(c), c.Param("account")) {
+			respondError(c, http.StatusNotFound, "Account not found", "the account is not tracked")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
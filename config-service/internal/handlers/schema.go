@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sirupsen/logrus"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+	"config-service/internal/version"
+)
+
+// errTrailingData rejects a strictly bound body with more after its JSON value
+var errTrailingData = errors.New("request body has data after the JSON value")
+
+// StrictSchemaMiddleware marks requests so that their bodies are bound
+// strictly: a field the request model does not have, or data after the JSON
+// value, is rejected instead of ignored
+func StrictSchemaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictSchemaKey, true)
+		c.Next()
+	}
+}
+
+// bindJSON binds the request body to obj like ShouldBindJSON, strictly when
+// StrictSchemaMiddleware marked the request
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if !c.GetBool(strictSchemaKey) {
+		return c.ShouldBindJSON(obj)
+	}
+	if c.Request == nil || c.Request.Body == nil {
+		return io.EOF
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errTrailingData
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// unknownFieldName returns the field named by the decoder's error for a field
+// the request model does not have
+func unknownFieldName(err error) (string, bool) {
+	const prefix = "json: unknown field "
+	if !strings.HasPrefix(err.Error(), prefix) {
+		return "", false
+	}
+	return strings.Trim(strings.TrimPrefix(err.Error(), prefix), `"`), true
+}
+
+// responseSchema is the documented model of a route's JSON responses
+type responseSchema struct {
+	model reflect.Type
+	// errors reports whether error responses have the model too, instead of
+	// the error envelope every other route shares
+	errors bool
+}
+
+// errorResponse is the error envelope: the body of every error response of
+// routes whose model does not cover errors
+type errorResponse struct {
+	Error     string                      `json:"error"`
+	Message   string                      `json:"message"`
+	RequestID string                      `json:"request_id,omitempty"`
+	Errors    []apperrors.ValidationError `json:"errors,omitempty"`
+	Quota     *models.QuotaStatus         `json:"quota,omitempty"`
+	Policy    *models.PolicyResult        `json:"policy,omitempty"`
+}
+
+// responseSchemas are the models of the routes answering with one, by method
+// and path relative to the group Register mounts the API on. Routes answering
+// with ad hoc objects only have their error responses checked.
+var responseSchemas = map[string]responseSchema{
+	"GET /api/v1/version":                          {model: reflect.TypeOf(version.Info{})},
+	"POST /api/v1/password/check":                  {model: reflect.TypeOf(models.PasswordResponse{})},
+	"POST /api/v1/password/composition":            {model: reflect.TypeOf(models.PasswordComposition{})},
+	"POST /api/v1/password/generate":               {model: reflect.TypeOf(models.GenerateResponse{})},
+	"POST /api/v1/password/generate-passphrase":    {model: reflect.TypeOf(models.PassphraseResponse{})},
+	"POST /api/v1/password/breach-check":           {model: reflect.TypeOf(models.BreachInfo{})},
+	"POST /api/v1/password/breach-audit":           {model: reflect.TypeOf(models.BreachAuditResponse{})},
+	"POST /api/v1/password/idp/keycloak":           {model: reflect.TypeOf(models.KeycloakValidationResponse{})},
+	"POST /api/v1/password/idp/auth0":              {model: reflect.TypeOf(models.Auth0ValidationResponse{}), errors: true},
+	"POST /api/v1/password/idp/okta":               {model: reflect.TypeOf(models.OktaInlineHookResponse{})},
+	"POST /api/v1/password/reuse":                  {model: reflect.TypeOf(models.ReuseResult{})},
+	"POST /api/v1/password/ad-filter":              {model: reflect.TypeOf(models.ADFilterVerdict{})},
+	"POST /api/v1/admission/secrets":               {model: reflect.TypeOf(models.AdmissionReview{})},
+	"GET /api/v1/admin/cache/stats":                {model: reflect.TypeOf(models.CacheStats{})},
+	"POST /api/v1/admin/banned-words/imports":      {model: reflect.TypeOf(models.BannedImportJob{})},
+	"GET /api/v1/admin/banned-words/imports/:id":   {model: reflect.TypeOf(models.BannedImportJob{})},
+	"POST /api/v1/admin/datasets/:name/reload":     {model: reflect.TypeOf(models.DatasetReload{})},
+	"GET /api/v1/admin/datasets/reloads/:id":       {model: reflect.TypeOf(models.DatasetReload{})},
+	"GET /api/v1/admin/logging":                    {model: reflect.TypeOf(models.LoggingSettings{})},
+	"PUT /api/v1/admin/logging":                    {model: reflect.TypeOf(models.LoggingSettings{})},
+	"GET /api/v1/admin/policies/:id":               {model: reflect.TypeOf(models.PasswordPolicy{})},
+	"PUT /api/v1/admin/policies/:id":               {model: reflect.TypeOf(models.PasswordPolicy{})},
+	"POST /api/v1/admin/policies/:id/restore":      {model: reflect.TypeOf(models.PasswordPolicy{})},
+	"GET /api/v1/admin/feedback-overrides/:tenant": {model: reflect.TypeOf(models.FeedbackOverrides{})},
+	"PUT /api/v1/admin/feedback-overrides/:tenant": {model: reflect.TypeOf(models.FeedbackOverrides{})},
+	"GET /api/v1/admin/analytics":                  {model: reflect.TypeOf(models.AnalyticsReport{})},
+	"POST /api/v1/admin/api-keys/:id/rotate":       {model: reflect.TypeOf(models.APIKeySecretResponse{})},
+}
+
+// ResponseSchemaMiddleware checks the JSON responses of the routes under
+// basePath against their documented models, and error responses against the
+// error envelope, logging every mismatch. It catches handlers drifting from
+// the models clients are written against; responses are sent unchanged, so
+// it is meant for development and staging rather than production.
+func ResponseSchemaMiddleware(logger *logrus.Logger, basePath string) gin.HandlerFunc {
+	basePath = strings.TrimSuffix(basePath, "/")
+	return func(c *gin.Context) {
+		recording := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recording
+		c.Next()
+		c.Writer = recording.ResponseWriter
+
+		if !strings.HasPrefix(recording.Header().Get("Content-Type"), "application/json") || recording.body.Len() == 0 {
+			return
+		}
+
+		route := c.Request.Method + " " + strings.TrimPrefix(c.FullPath(), basePath)
+		schema, documented := responseSchemas[route]
+		var model reflect.Type
+		switch status := recording.Status(); {
+		case status >= http.StatusBadRequest && !schema.errors:
+			model = reflect.TypeOf(errorResponse{})
+		case documented:
+			model = schema.model
+		default:
+			return
+		}
+
+		var body interface{}
+		if err := json.Unmarshal(recording.body.Bytes(), &body); err != nil {
+			RequestLogger(c, logger).WithError(err).Error("Response is not valid JSON")
+			return
+		}
+		if problems := schemaProblems("", body, model); len(problems) > 0 {
+			RequestLogger(c, logger).WithFields(logrus.Fields{
+				"route":    route,
+				"status":   recording.Status(),
+				"model":    model.String(),
+				"problems": problems,
+			}).Error("Response does not match its schema")
+		}
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaProblems compares a decoded JSON value with the encoding of a Go
+// type, returning a problem for every missing, unknown, or mistyped field.
+// Types that encode themselves, such as times, accept any value.
+func schemaProblems(path string, value interface{}, t reflect.Type) []string {
+	for _, marshaler := range []reflect.Type{jsonMarshalerType, textMarshalerType} {
+		if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+			return nil
+		}
+	}
+	mistyped := func(want string) []string {
+		return []string{fmt.Sprintf("%s: must be %s", schemaPath(path), want)}
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return nil
+	case reflect.Pointer:
+		if value == nil {
+			return nil
+		}
+		return schemaProblems(path, value, t.Elem())
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mistyped("an object")
+		}
+		var problems []string
+		fields := schemaFields(t)
+		for _, name := range sortedKeys(fields) {
+			field := fields[name]
+			fieldValue, present := object[name]
+			switch {
+			case present:
+				problems = append(problems, schemaProblems(joinPath(path, name), fieldValue, field.typ)...)
+			case !field.omitEmpty:
+				problems = append(problems, joinPath(path, name)+": missing")
+			}
+		}
+		for _, name := range sortedKeys(object) {
+			if _, known := fields[name]; !known {
+				problems = append(problems, joinPath(path, name)+": unknown field")
+			}
+		}
+		return problems
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && value == nil {
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := value.(string); !ok {
+				return mistyped("a string")
+			}
+			return nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return mistyped("an array")
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, schemaProblems(fmt.Sprintf("%s[%d]", path, i), item, t.Elem())...)
+		}
+		return problems
+	case reflect.Map:
+		if value == nil {
+			return nil
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mistyped("an object")
+		}
+		var problems []string
+		for _, key := range sortedKeys(object) {
+			problems = append(problems, schemaProblems(joinPath(path, key), object[key], t.Elem())...)
+		}
+		return problems
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return mistyped("a string")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return mistyped("a boolean")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			return mistyped("a number")
+		}
+	}
+	return nil
+}
+
+// schemaField is a field of a JSON object encoded from a struct
+type schemaField struct {
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+// schemaFields returns the fields a struct encodes to by JSON name, including
+// those promoted from embedded structs
+func schemaFields(t reflect.Type) map[string]schemaField {
+	fields := make(map[string]schemaField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			for promoted, promotedField := range schemaFields(embedded) {
+				if _, shadowed := fields[promoted]; !shadowed {
+					fields[promoted] = promotedField
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		typ := field.Type
+		if strings.Contains(","+options+",", ",string,") {
+			typ = reflect.TypeOf("")
+		}
+		fields[name] = schemaField{typ: typ, omitEmpty: strings.Contains(","+options+",", ",omitempty,")}
+	}
+	return fields
+}
+
+// joinPath appends a field name to a JSON path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// schemaPath names a JSON path in a problem, the whole body when empty
+func schemaPath(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/metrics"
+)
+
+// SlowRequestMiddleware logs requests that take at least the threshold together
+// with their per-stage timing breakdown, and counts them as slow_requests tagged
+// with the route and the slowest stage. This shows whether a latency spike comes
+// from the breach API or from our own processing.
+func SlowRequestMiddleware(logger *logrus.Logger, recorder metrics.Recorder, threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		duration := time.Since(start)
+		if duration < threshold {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		stages := make(map[string]float64)
+		slowest := "none"
+		var slowestDuration time.Duration
+		for name, stageDuration := range GetStageTimings(c).Durations() {
+			stages[name] = float64(stageDuration) / float64(time.Millisecond)
+			if stageDuration > slowestDuration {
+				slowest, slowestDuration = name, stageDuration
+			}
+		}
+
+		recorder.Count("slow_requests", 1, metrics.Tags{"route": route, "stage": slowest})
+
+		RequestLogger(c, logger).WithFields(logrus.Fields{
+			"method":        c.Request.Method,
+			"route":         route,
+			"status":        c.Writer.Status(),
+			"duration":      duration,
+			"threshold":     threshold,
+			"stages_ms":     stages,
+			"slowest_stage": slowest,
+		}).Warn("Slow request")
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/services"
+)
+
+// CaptchaTokenHeader carries a CAPTCHA response token that lifts throttling
+const CaptchaTokenHeader = "X-Captcha-Token"
+
+// BruteForceThrottleMiddleware slows down clients that call password oracle
+// endpoints too often, keyed by client IP and, when authenticated, API key or
+// tenant. Requests over the threshold are delayed exponentially; once the delay
+// would exceed the maximum they are rejected with 429. A client that presents a
+// valid CAPTCHA token has its counts reset. The captcha verifier may be nil.
+func BruteForceThrottleMiddleware(logger *logrus.Logger, throttle *services.ThrottleService, captcha services.CaptchaVerifier) gin.HandlerFunc {
+	retryAfter := strconv.Itoa(int(throttle.RetryAfter().Seconds()))
+
+	return func(c *gin.Context) {
+		keys := []string{"ip:" + GetClientIP(c)}
+		if key := GetAPIKey(c); key != nil {
+			keys = append(keys, "key:"+key.ID)
+		} else if tenant := GetTenant(c); tenant != "" {
+			keys = append(keys, "tenant:"+tenant)
+		}
+
+		if token := c.GetHeader(CaptchaTokenHeader); token != "" && captcha != nil {
+			valid, err := captcha.VerifyCaptcha(c.Request.Context(), token, GetClientIP(c))
+			if err != nil {
+				RequestLogger(c, logger).WithError(err).Warn("CAPTCHA verification failed")
+			}
+			if valid {
+				throttle.Reset(keys...)
+			}
+		}
+
+		delay, ok := throttle.Record(keys...)
+		if !ok {
+			RequestLogger(c, logger).WithFields(logrus.Fields{
+				"limiter": "brute_force",
+				"path":    c.Request.URL.Path,
+			}).Warn("Request rejected: brute-force throttle")
+
+			c.Header("Retry-After", retryAfter)
+			respondError(c, http.StatusTooManyRequests, "Too many requests", "too many password checks, slow down or solve a CAPTCHA")
+			c.Abort()
+			return
+		}
+
+		if delay > 0 {
+			done := TrackStage(c, "throttle")
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+				done()
+				c.Abort()
+				return
+			}
+			done()
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/audit"
+	"config-service/internal/i18n"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// Quota response headers
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+)
+
+// UsageQuotaMiddleware counts requests per tenant and API key and rejects
+// requests from tenants over their monthly quota with 429 and the quota status.
+// It must run after authentication; unauthenticated requests are not counted.
+func UsageQuotaMiddleware(logger *logrus.Logger, usage *services.UsageService, recorder metrics.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := GetTenant(c)
+		var keyID string
+		if key := GetAPIKey(c); key != nil {
+			keyID = key.ID
+		}
+		if tenant == "" && keyID == "" {
+			c.Next()
+			return
+		}
+
+		status, ok := usage.Record(tenant, keyID)
+		if status.Limit > 0 {
+			c.Header(QuotaLimitHeader, strconv.FormatInt(status.Limit, 10))
+			c.Header(QuotaRemainingHeader, strconv.FormatInt(status.Remaining, 10))
+			c.Header(QuotaResetHeader, strconv.FormatInt(status.ResetsAt.Unix(), 10))
+		}
+
+		tags := metrics.Tags{"tenant": tenant, "api_key": keyID}
+		if !ok {
+			recorder.Count("quota_rejections", 1, tags)
+			c.Set(quotaExceededKey, true)
+			RequestLogger(c, logger).WithFields(logrus.Fields{
+				"limiter": "quota",
+				"tenant":  tenant,
+			}).Warn("Request rejected: monthly quota exceeded")
+
+			retryAfter := int(time.Until(status.ResetsAt).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+			body := gin.H{
+				"error":   i18n.Translate(GetLocale(c), "Quota exceeded"),
+				"message": "monthly request quota exceeded",
+				"quota":   status,
+			}
+			if requestID := GetRequestID(c); requestID != "" {
+				body["request_id"] = requestID
+			}
+			respondJSON(c, http.StatusTooManyRequests, body)
+			c.Abort()
+			return
+		}
+
+		recorder.Count("tenant_requests", 1, tags)
+		c.Next()
+
+		if outcome, ok := checkOutcome(c); ok {
+			usage.RecordCheck(tenant, outcome)
+		}
+	}
+}
+
+// setCheckScore records the strength score of a password check for usage reports
+func setCheckScore(c *gin.Context, score int) {
+	c.Set(checkScoreKey, score)
+}
+
+// checkOutcome returns the outcome of the password check a handler performed,
+// if it performed one
+func checkOutcome(c *gin.Context) (models.CheckOutcome, bool) {
+	value, exists := c.Get(auditResultKey)
+	if !exists {
+		return models.CheckOutcome{}, false
+	}
+	var outcome models.CheckOutcome
+	switch value {
+	case audit.ResultPass:
+	case audit.ResultBreached:
+		outcome.Breached = true
+	case audit.ResultWeak:
+		outcome.PolicyFailure = true
+	default:
+		return models.CheckOutcome{}, false
+	}
+	if score, exists := c.Get(checkScoreKey); exists {
+		outcome.Score, outcome.Scored = score.(int)
+	}
+	if violations, exists := c.Get(checkViolationsKey); exists {
+		outcome.Violations, _ = violations.([]models.PolicyRule)
+	}
+	return outcome, true
+}
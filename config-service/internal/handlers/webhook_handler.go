@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/audit"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// WebhookEventsMiddleware publishes events for completed requests to webhooks
+// and event streams: breach-found when a checked password was breached,
+// policy-violation when it was too weak or rejected by a policy, and
+// quota-exceeded when the request was over the monthly quota. Events carry the
+// route, tenant, and request ID, never the password.
+func WebhookEventsMiddleware(events services.EventPublisher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		var eventType string
+		switch {
+		case c.GetBool(quotaExceededKey):
+			eventType = models.WebhookEventQuotaExceeded
+		case auditResult(c) == audit.ResultBreached:
+			eventType = models.WebhookEventBreachFound
+		case auditResult(c) == audit.ResultWeak:
+			eventType = models.WebhookEventPolicyViolation
+		default:
+			return
+		}
+
+		events.Publish(models.WebhookEvent{
+			Type:      eventType,
+			Tenant:    GetTenant(c),
+			RequestID: GetRequestID(c),
+			Route:     c.Request.Method + " " + c.FullPath(),
+		})
+	}
+}
+
+// AdminCreateWebhookHandler registers a webhook and returns it with its signing secret
+func AdminCreateWebhookHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.WebhookSubscriptionRequest
+
+		// Bind JSON request
+		if err := bindJSON(c, &request); err != nil {
+			respondBindError(c, err)
+			return
+		}
+
+		subscription, err := webhooks.Create(request)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid request format", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusCreated, subscription)
+	}
+}
+
+// AdminListWebhooksHandler returns a page of webhook subscriptions
+func AdminListWebhooksHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseListOptions(c)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		subscriptions, page, err := webhooks.ListPage(opts)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"webhooks": subscriptions,
+			"page":     page,
+		})
+	}
+}
+
+// AdminGetWebhookHandler returns a webhook subscription
+func AdminGetWebhookHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription, err := webhooks.Get(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Webhook not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, subscription)
+	}
+}
+
+// AdminDeleteWebhookHandler removes a webhook subscription
+func AdminDeleteWebhookHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := webhooks.Delete(c.Param("id")); err != nil {
+			respondError(c, http.StatusNotFound, "Webhook not found", err.Error())
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AdminListWebhookDeliveriesHandler returns the latest delivery attempts to a
+// webhook, newest first
+func AdminListWebhookDeliveriesHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		attempts, err := webhooks.Deliveries(c.Param("id"))
+		if err != nil {
+			respondError(c, http.StatusNotFound, "Webhook not found", err.Error())
+			return
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"deliveries": attempts,
+			"total":      len(attempts),
+		})
+	}
+}
+
+// AdminListDeadLettersHandler returns the webhook deliveries that failed after
+// all attempts, newest first, optionally filtered by the webhook query parameter
+func AdminListDeadLettersHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		letters := webhooks.DeadLetters(c.Query("webhook"))
+
+		respondJSON(c, http.StatusOK, gin.H{
+			"dead_letters": letters,
+			"total":        len(letters),
+		})
+	}
+}
+
+// AdminRetryDeadLetterHandler queues a dead-lettered delivery again
+func AdminRetryDeadLetterHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := webhooks.RetryDeadLetter(c.Param("id")); err != nil {
+			message := "Dead letter not found"
+			if errors.Is(err, services.ErrWebhookNotFound) {
+				message = "Webhook not found"
+			}
+			respondError(c, http.StatusNotFound, message, err.Error())
+			return
+		}
+
+		c.Status(http.StatusAccepted)
+	}
+}
+
+// AdminDeleteDeadLetterHandler discards a dead-lettered delivery
+func AdminDeleteDeadLetterHandler(webhooks *services.WebhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := webhooks.DeleteDeadLetter(c.Param("id")); err != nil {
+			respondError(c, http.StatusNotFound, "Dead letter not found", err.Error())
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
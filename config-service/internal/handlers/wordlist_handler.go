@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"config-service/internal/dataset"
+	"config-service/internal/models"
+)
+
+// AdminListWordlistsHandler lists the common password dictionaries in use,
+// with the ETag each is served with
+func AdminListWordlistsHandler(dictionaries []*dataset.Slot) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wordlists := make([]models.WordlistInfo, 0, len(dictionaries))
+		for _, slot := range dictionaries {
+			dictionary, release := slot.Acquire()
+			wordlists = append(wordlists, models.WordlistInfo{
+				Name:    filepath.Base(dictionary.Path()),
+				Entries: dictionary.Len(),
+				Size:    dictionary.Size(),
+				ETag:    wordlistETag(dictionary),
+			})
+			release()
+		}
+
+		respondJSON(c, http.StatusOK, gin.H{"wordlists": wordlists})
+	}
+}
+
+// AdminGetWordlistHandler serves a common password dictionary by its file
+// name. Requests with a matching If-None-Match are answered with 304, and
+// byte ranges are supported for resuming large downloads. A download keeps
+// serving the version it started with when the dictionary is reloaded.
+func AdminGetWordlistHandler(dictionaries []*dataset.Slot) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		for _, slot := range dictionaries {
+			if filepath.Base(slot.Path()) != name {
+				continue
+			}
+			dictionary, release := slot.Acquire()
+			defer release()
+			c.Header("ETag", wordlistETag(dictionary))
+			c.Header("Content-Type", "text/plain; charset=utf-8")
+			http.ServeContent(c.Writer, c.Request, name, time.Time{}, dictionary.NewReader())
+			return
+		}
+
+		respondError(c, http.StatusNotFound, "Wordlist not found", "no wordlist named "+name)
+	}
+}
+
+// wordlistETag returns the strong ETag of a dictionary, from the digest of its contents
+func wordlistETag(dictionary *dataset.File) string {
+	return `"` + dictionary.Digest()[:32] + `"`
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+	"config-service/internal/services"
+)
+
+// BreachAPICheck probes the HIBP range API. It reports degraded rather than
+// unhealthy when the API is unreachable, because password checks still work
+// without breach detection.
+func BreachAPICheck(breach *services.BreachService) CheckFunc {
+	return func(ctx context.Context) (Status, string, map[string]interface{}) {
+		if !breach.IsEnabled() {
+			return StatusDisabled, "breach detection is disabled", nil
+		}
+		err := breach.Ping(ctx)
+		details := map[string]interface{}{"circuit": breach.CircuitState()}
+		if err != nil {
+			return StatusDegraded, err.Error(), details
+		}
+		return StatusHealthy, "", details
+	}
+}
+
+// CacheCheck reports the breach result cache statistics
+func CacheCheck(breach *services.BreachService) CheckFunc {
+	return func(ctx context.Context) (Status, string, map[string]interface{}) {
+		stats := breach.CacheStats()
+		return StatusHealthy, "", map[string]interface{}{
+			"backend":          "memory",
+			"entries":          stats.Entries,
+			"hits":             stats.Hits,
+			"misses":           stats.Misses,
+			"duration_seconds": stats.DurationSeconds,
+		}
+	}
+}
+
+// PolicyCheck verifies that password policies are loaded
+func PolicyCheck(policies *services.PolicyService) CheckFunc {
+	return func(ctx context.Context) (Status, string, map[string]interface{}) {
+		count := len(policies.List())
+		details := map[string]interface{}{"policies": count}
+		if count == 0 {
+			return StatusUnhealthy, "no password policies are loaded", details
+		}
+		return StatusHealthy, "", details
+	}
+}
+
+// DatasetCheck reports the size and age of the banned word list
+func DatasetCheck(banned *services.BannedListService) CheckFunc {
+	return func(ctx context.Context) (Status, string, map[string]interface{}) {
+		updatedAt := banned.UpdatedAt()
+		return StatusHealthy, "", map[string]interface{}{
+			"banned_words":        banned.Count(),
+			"banned_list_updated": updatedAt,
+			"banned_list_age":     time.Since(updatedAt).Round(time.Second).String(),
+		}
+	}
+}
+
+// IntegrityCheck reports the checksum and signature verification of
+// wordlists and datasets. A refused file leaves the one loaded before it in
+// use, so failures degrade the service rather than make it unhealthy.
+func IntegrityCheck(verifier *dataset.Verifier) CheckFunc {
+	return func(ctx context.Context) (Status, string, map[string]interface{}) {
+		if !verifier.Enabled() {
+			return StatusDisabled, "dataset verification is disabled", nil
+		}
+		results := verifier.Results()
+		failed := 0
+		for _, result := range results {
+			if result.Status == dataset.StatusFailed {
+				failed++
+			}
+		}
+		details := map[string]interface{}{"files": results}
+		if failed > 0 {
+			return StatusDegraded, fmt.Sprintf("%d of %d files failed verification", failed, len(results)), details
+		}
+		return StatusHealthy, "", details
+	}
+}
+
+// ConfigCheck validates the loaded configuration
+func ConfigCheck(cfg *config.Config) CheckFunc {
+	return func(ctx context.Context) (Status, string, map[string]interface{}) {
+		if err := cfg.Validate(); err != nil {
+			return StatusUnhealthy, err.Error(), nil
+		}
+		return StatusHealthy, "", nil
+	}
+}
@@ -0,0 +1,213 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the health of a single dependency or of the service as a whole
+type Status string
+
+const (
+	// StatusHealthy means the dependency works as expected
+	StatusHealthy Status = "healthy"
+	// StatusDegraded means the service works with reduced functionality
+	StatusDegraded Status = "degraded"
+	// StatusUnhealthy means the service cannot serve requests correctly
+	StatusUnhealthy Status = "unhealthy"
+	// StatusDisabled means the dependency is switched off by configuration
+	StatusDisabled Status = "disabled"
+)
+
+// defaultCheckTimeout bounds how long a single check may run
+const defaultCheckTimeout = 5 * time.Second
+
+// Result is the outcome of a single dependency check
+type Result struct {
+	Status   Status                 `json:"status"`
+	Critical bool                   `json:"critical"`
+	Message  string                 `json:"message,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	Duration string                 `json:"duration"`
+	CachedAt *time.Time             `json:"cached_at,omitempty"`
+}
+
+// Report is the breakdown of all dependency checks
+type Report struct {
+	Status    Status            `json:"status"`
+	Timestamp time.Time         `json:"timestamp"`
+	Version   string            `json:"version"`
+	Checks    map[string]Result `json:"checks"`
+}
+
+// CheckFunc checks a dependency. It returns the status, an optional message,
+// and optional details.
+type CheckFunc func(ctx context.Context) (Status, string, map[string]interface{})
+
+// check is a registered dependency check
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+	ttl      time.Duration
+	timeout  time.Duration
+
+	mu         sync.Mutex
+	cached     *Result
+	cachedAt   time.Time
+	refreshing bool
+}
+
+// CheckOption defines functional options for configuring a single check
+type CheckOption func(*check)
+
+// WithTimeout bounds how long the check may run, overriding the timeout of
+// the checker, e.g. to keep a slow upstream probe short
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// Checker runs dependency checks and aggregates them into an overall status:
+// a failing critical check makes the service unhealthy, any other failure
+// makes it degraded
+type Checker struct {
+	version string
+	timeout time.Duration
+	checks  []*check
+}
+
+// CheckerOption defines functional options for configuring the Checker
+type CheckerOption func(*Checker)
+
+// WithDefaultTimeout bounds how long each check may run unless it has a
+// timeout of its own
+func WithDefaultTimeout(timeout time.Duration) CheckerOption {
+	return func(h *Checker) {
+		h.timeout = timeout
+	}
+}
+
+// NewChecker creates a checker reporting the service version
+func NewChecker(version string, options ...CheckerOption) *Checker {
+	h := &Checker{
+		version: version,
+		timeout: defaultCheckTimeout,
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// Register adds a dependency check. Critical checks make the service unhealthy
+// when they fail. A positive ttl caches the result, which keeps expensive probes
+// such as upstream API calls from running on every request.
+func (h *Checker) Register(name string, critical bool, ttl time.Duration, fn CheckFunc, options ...CheckOption) {
+	c := &check{name: name, critical: critical, fn: fn, ttl: ttl, timeout: h.timeout}
+	for _, option := range options {
+		option(c)
+	}
+	h.checks = append(h.checks, c)
+}
+
+// Run executes all checks concurrently and returns the report
+func (h *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusHealthy,
+		Timestamp: time.Now().UTC(),
+		Version:   h.version,
+		Checks:    make(map[string]Result, len(h.checks)),
+	}
+
+	results := make([]Result, len(h.checks))
+	var wg sync.WaitGroup
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range h.checks {
+		result := results[i]
+		report.Checks[c.name] = result
+
+		switch result.Status {
+		case StatusUnhealthy:
+			if c.critical {
+				report.Status = StatusUnhealthy
+			} else if report.Status == StatusHealthy {
+				report.Status = StatusDegraded
+			}
+		case StatusDegraded:
+			if report.Status == StatusHealthy {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+
+	return report
+}
+
+// run executes a single check, using the cached result when it is fresh.
+// While one request refreshes an expired result, the others are answered
+// with the expired one rather than waiting for the dependency too.
+func (h *Checker) run(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	if c.cached != nil && (c.refreshing || time.Since(c.cachedAt) < c.ttl) {
+		result := *c.cached
+		cachedAt := c.cachedAt
+		result.CachedAt = &cachedAt
+		c.mu.Unlock()
+		return result
+	}
+	c.refreshing = c.ttl > 0
+	c.mu.Unlock()
+
+	result := c.probe(ctx)
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		c.cached = &result
+		c.cachedAt = time.Now().UTC()
+		c.refreshing = false
+		c.mu.Unlock()
+	}
+
+	return result
+}
+
+// probe runs the check function within the timeout. A function that ignores
+// its context is left to finish in the background, and the check fails as
+// if the dependency were down: unhealthy when critical, otherwise degraded.
+func (c *check) probe(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		status, message, details := c.fn(ctx)
+		done <- Result{Status: status, Critical: c.critical, Message: message, Details: details}
+	}()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Result{Status: StatusDegraded, Critical: c.critical, Message: fmt.Sprintf("check timed out after %s", c.timeout)}
+		if c.critical {
+			result.Status = StatusUnhealthy
+		}
+	}
+	result.Duration = time.Since(start).String()
+	return result
+}
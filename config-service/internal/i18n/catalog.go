@@ -0,0 +1,103 @@
+package i18n
+
+// catalog maps locales to translations of the service's English messages.
+// English is the source language, so it has no entry of its own. Numbers in
+// messages are written %d where they are configurable.
+var catalog = map[string]map[string]string{
+	"es": {
+		// Feedback warnings
+		"Password contains common patterns":       "La contraseña contiene patrones comunes",
+		"Password contains sequential characters": "La contraseña contiene caracteres secuenciales",
+		"Password contains repeated patterns":     "La contraseña contiene patrones repetidos",
+		"Password contains a banned word":         "La contraseña contiene una palabra prohibida",
+		"Password has appeared in data breaches":  "La contraseña ha aparecido en filtraciones de datos",
+		"Password is easy to guess":               "La contraseña es fácil de adivinar",
+
+		// Feedback suggestions
+		"Use a more unique combination of characters":       "Utilice una combinación de caracteres más única",
+		"Avoid keyboard patterns and sequential characters": "Evite patrones de teclado y caracteres secuenciales",
+		"Avoid repeating character sequences":               "Evite repetir secuencias de caracteres",
+		"Avoid predictable words, dates, and substitutions": "Evite palabras, fechas y sustituciones predecibles",
+		"Add uppercase letters":                             "Añada letras mayúsculas",
+		"Add lowercase letters":                             "Añada letras minúsculas",
+		"Add numbers":                                       "Añada números",
+		"Add special characters":                            "Añada caracteres especiales",
+		"Use a longer password (12+ characters)":            "Utilice una contraseña más larga (12 o más caracteres)",
+		"Consider using a passphrase with multiple words":   "Considere usar una frase de contraseña con varias palabras",
+		"Mix different character types more thoroughly":     "Combine mejor los distintos tipos de caracteres",
+		"Avoid words from the organization's banned list":   "Evite las palabras de la lista prohibida de la organización",
+		"Choose a password that hasn't been compromised":    "Elija una contraseña que no haya sido comprometida",
+
+		// Errors
+		"Invalid request format":                       "Formato de solicitud no válido",
+		"Password validation failed":                   "La validación de la contraseña falló",
+		"Breach check failed":                          "La comprobación de filtraciones falló",
+		"password must be at least %d characters long": "la contraseña debe tener al menos %d caracteres",
+		"password must not exceed %d characters":       "la contraseña no debe superar los %d caracteres",
+		"password must contain at least one uppercase letter, one lowercase letter, one number, and one special character": "la contraseña debe contener al menos una letra mayúscula, una letra minúscula, un número y un carácter especial",
+	},
+	"fr": {
+		// Feedback warnings
+		"Password contains common patterns":       "Le mot de passe contient des motifs courants",
+		"Password contains sequential characters": "Le mot de passe contient des caractères séquentiels",
+		"Password contains repeated patterns":     "Le mot de passe contient des motifs répétés",
+		"Password contains a banned word":         "Le mot de passe contient un mot interdit",
+		"Password has appeared in data breaches":  "Le mot de passe est apparu dans des fuites de données",
+		"Password is easy to guess":               "Le mot de passe est facile à deviner",
+
+		// Feedback suggestions
+		"Use a more unique combination of characters":       "Utilisez une combinaison de caractères plus originale",
+		"Avoid keyboard patterns and sequential characters": "Évitez les motifs de clavier et les caractères séquentiels",
+		"Avoid repeating character sequences":               "Évitez de répéter des séquences de caractères",
+		"Avoid predictable words, dates, and substitutions": "Évitez les mots, dates et substitutions prévisibles",
+		"Add uppercase letters":                             "Ajoutez des lettres majuscules",
+		"Add lowercase letters":                             "Ajoutez des lettres minuscules",
+		"Add numbers":                                       "Ajoutez des chiffres",
+		"Add special characters":                            "Ajoutez des caractères spéciaux",
+		"Use a longer password (12+ characters)":            "Utilisez un mot de passe plus long (12 caractères ou plus)",
+		"Consider using a passphrase with multiple words":   "Envisagez une phrase de passe composée de plusieurs mots",
+		"Mix different character types more thoroughly":     "Mélangez davantage les différents types de caractères",
+		"Avoid words from the organization's banned list":   "Évitez les mots de la liste interdite de l'organisation",
+		"Choose a password that hasn't been compromised":    "Choisissez un mot de passe qui n'a pas été compromis",
+
+		// Errors
+		"Invalid request format":                       "Format de requête invalide",
+		"Password validation failed":                   "La validation du mot de passe a échoué",
+		"Breach check failed":                          "La vérification des fuites a échoué",
+		"password must be at least %d characters long": "le mot de passe doit contenir au moins %d caractères",
+		"password must not exceed %d characters":       "le mot de passe ne doit pas dépasser %d caractères",
+		"password must contain at least one uppercase letter, one lowercase letter, one number, and one special character": "le mot de passe doit contenir au moins une majuscule, une minuscule, un chiffre et un caractère spécial",
+	},
+	"de": {
+		// Feedback warnings
+		"Password contains common patterns":       "Das Passwort enthält gängige Muster",
+		"Password contains sequential characters": "Das Passwort enthält aufeinanderfolgende Zeichen",
+		"Password contains repeated patterns":     "Das Passwort enthält wiederholte Muster",
+		"Password contains a banned word":         "Das Passwort enthält ein gesperrtes Wort",
+		"Password has appeared in data breaches":  "Das Passwort ist in Datenlecks aufgetaucht",
+		"Password is easy to guess":               "Das Passwort ist leicht zu erraten",
+
+		// Feedback suggestions
+		"Use a more unique combination of characters":       "Verwenden Sie eine eindeutigere Zeichenkombination",
+		"Avoid keyboard patterns and sequential characters": "Vermeiden Sie Tastaturmuster und aufeinanderfolgende Zeichen",
+		"Avoid repeating character sequences":               "Vermeiden Sie sich wiederholende Zeichenfolgen",
+		"Avoid predictable words, dates, and substitutions": "Vermeiden Sie vorhersehbare Wörter, Daten und Ersetzungen",
+		"Add uppercase letters":                             "Fügen Sie Großbuchstaben hinzu",
+		"Add lowercase letters":                             "Fügen Sie Kleinbuchstaben hinzu",
+		"Add numbers":                                       "Fügen Sie Zahlen hinzu",
+		"Add special characters":                            "Fügen Sie Sonderzeichen hinzu",
+		"Use a longer password (12+ characters)":            "Verwenden Sie ein längeres Passwort (mindestens 12 Zeichen)",
+		"Consider using a passphrase with multiple words":   "Erwägen Sie eine Passphrase aus mehreren Wörtern",
+		"Mix different character types more thoroughly":     "Mischen Sie verschiedene Zeichentypen gründlicher",
+		"Avoid words from the organization's banned list":   "Vermeiden Sie Wörter aus der Sperrliste der Organisation",
+		"Choose a password that hasn't been compromised":    "Wählen Sie ein Passwort, das nicht kompromittiert wurde",
+
+		// Errors
+		"Invalid request format":                       "Ungültiges Anfrageformat",
+		"Password validation failed":                   "Passwortprüfung fehlgeschlagen",
+		"Breach check failed":                          "Prüfung auf Datenlecks fehlgeschlagen",
+		"password must be at least %d characters long": "das Passwort muss mindestens %d Zeichen lang sein",
+		"password must not exceed %d characters":       "das Passwort darf %d Zeichen nicht überschreiten",
+		"password must contain at least one uppercase letter, one lowercase letter, one number, and one special character": "das Passwort muss mindestens einen Großbuchstaben, einen Kleinbuchstaben, eine Zahl und ein Sonderzeichen enthalten",
+	},
+}
@@ -0,0 +1,147 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale used when no supported locale is requested
+const DefaultLocale = "en"
+
+// IsSupported reports whether translations are available for the locale
+func IsSupported(locale string) bool {
+	if locale == DefaultLocale {
+		return true
+	}
+	_, ok := catalog[locale]
+	return ok
+}
+
+// SupportedLocales returns all locales with translations, including the default
+func SupportedLocales() []string {
+	locales := []string{DefaultLocale}
+	for locale := range catalog {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales[1:])
+	return locales
+}
+
+// Translate returns the message translated into the locale, or the message itself
+// when no translation exists
+func Translate(locale, message string) string {
+	if translated, ok := lookup(locale, message); ok {
+		return translated
+	}
+	return message
+}
+
+// HasTranslation reports whether the message has a translation for the locale
+func HasTranslation(locale, message string) bool {
+	_, ok := lookup(locale, message)
+	return ok
+}
+
+// lookup translates a message, or else the message with its numbers written
+// %d, filling the numbers into the translation in order
+func lookup(locale, message string) (string, bool) {
+	messages := catalog[locale]
+	if translated, ok := messages[message]; ok {
+		return translated, true
+	}
+
+	var template strings.Builder
+	var numbers []string
+	for i := 0; i < len(message); {
+		j := i
+		for j < len(message) && message[j] >= '0' && message[j] <= '9' {
+			j++
+		}
+		if j > i {
+			numbers = append(numbers, message[i:j])
+			template.WriteString("%d")
+			i = j
+			continue
+		}
+		template.WriteByte(message[i])
+		i++
+	}
+	if len(numbers) == 0 {
+		return "", false
+	}
+	translated, ok := messages[template.String()]
+	if !ok {
+		return "", false
+	}
+	for _, number := range numbers {
+		translated = strings.Replace(translated, "%d", number, 1)
+	}
+	return translated, true
+}
+
+// TranslateAll translates every message in the slice into the locale
+func TranslateAll(locale string, messages []string) []string {
+	translated := make([]string, len(messages))
+	for i, message := range messages {
+		translated[i] = Translate(locale, message)
+	}
+	return translated
+}
+
+// languageRange is a single entry from an Accept-Language header
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// Negotiate selects the best supported locale from an Accept-Language header,
+// returning fallback when none of the requested languages is supported
+func Negotiate(acceptLanguage, fallback string) string {
+	ranges := parseAcceptLanguage(acceptLanguage)
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return fallback
+		}
+		if IsSupported(r.tag) {
+			return r.tag
+		}
+		// Fall back from a regional tag (e.g. "fr-CA") to its base language
+		if base := strings.SplitN(r.tag, "-", 2)[0]; IsSupported(base) {
+			return base
+		}
+	}
+	return fallback
+}
+
+// parseAcceptLanguage parses an Accept-Language header into ranges ordered by quality
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, languageRange{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	return ranges
+}
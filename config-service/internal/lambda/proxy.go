@@ -0,0 +1,207 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Serve handles an API Gateway proxy event with handler and returns the proxy
+// response. REST API and HTTP API payload format 1.0 events are
+// events.APIGatewayProxyRequest, payload format 2.0 events
+// events.APIGatewayV2HTTPRequest; an error is only returned for events that
+// are not proxy events.
+func Serve(ctx context.Context, handler http.Handler, event []byte) ([]byte, error) {
+	var format struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(event, &format); err != nil {
+		return nil, fmt.Errorf("invalid API Gateway proxy event: %w", err)
+	}
+
+	var request *http.Request
+	var err error
+	v2 := format.Version == "2.0"
+	if v2 {
+		var proxy events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(event, &proxy); err != nil {
+			return nil, fmt.Errorf("invalid API Gateway proxy event: %w", err)
+		}
+		request, err = fromV2(ctx, &proxy)
+	} else {
+		var proxy events.APIGatewayProxyRequest
+		if err := json.Unmarshal(event, &proxy); err != nil {
+			return nil, fmt.Errorf("invalid API Gateway proxy event: %w", err)
+		}
+		request, err = fromV1(ctx, &proxy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := newResponseRecorder()
+	handler.ServeHTTP(recorder, request)
+	if v2 {
+		return json.Marshal(recorder.toV2())
+	}
+	return json.Marshal(recorder.toV1())
+}
+
+// fromV1 converts a payload format 1.0 event to the request it represents
+func fromV1(ctx context.Context, p *events.APIGatewayProxyRequest) (*http.Request, error) {
+	header := make(http.Header)
+	for name, value := range p.Headers {
+		header.Set(name, value)
+	}
+	for name, values := range p.MultiValueHeaders {
+		header.Del(name)
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+	query := make(url.Values)
+	for name, value := range p.QueryStringParameters {
+		query.Set(name, value)
+	}
+	for name, values := range p.MultiValueQueryStringParameters {
+		query[name] = values
+	}
+	return newRequest(ctx, p.HTTPMethod, p.Path, query.Encode(), header, p.Body, p.IsBase64Encoded,
+		p.RequestContext.Identity.SourceIP, p.RequestContext.RequestID)
+}
+
+// fromV2 converts a payload format 2.0 event to the request it represents
+func fromV2(ctx context.Context, p *events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	// Named stages are part of the raw path; the routes are not
+	path := p.RawPath
+	if stage := p.RequestContext.Stage; stage != "" && stage != "$default" {
+		if trimmed := strings.TrimPrefix(path, "/"+stage); trimmed != path && (trimmed == "" || trimmed[0] == '/') {
+			path = trimmed
+		}
+	}
+	header := make(http.Header)
+	for name, value := range p.Headers {
+		header.Set(name, value)
+	}
+	if len(p.Cookies) > 0 {
+		header.Set("Cookie", strings.Join(p.Cookies, "; "))
+	}
+	query, err := url.ParseQuery(p.RawQueryString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query string: %w", err)
+	}
+	return newRequest(ctx, p.RequestContext.HTTP.Method, path, query.Encode(), header, p.Body, p.IsBase64Encoded,
+		p.RequestContext.HTTP.SourceIP, p.RequestContext.RequestID)
+}
+
+// newRequest builds the request of a proxy event from its parts
+func newRequest(ctx context.Context, method, path, rawQuery string, header http.Header, body string, base64Body bool, sourceIP, requestID string) (*http.Request, error) {
+	content := []byte(body)
+	if base64Body {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 request body: %w", err)
+		}
+		content = decoded
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	target := &url.URL{Path: path, RawQuery: rawQuery}
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request in proxy event: %w", err)
+	}
+	request.Header = header
+	request.Host = header.Get("Host")
+	request.RemoteAddr = net.JoinHostPort(sourceIP, "0")
+	if header.Get("X-Request-ID") == "" && requestID != "" {
+		request.Header.Set("X-Request-ID", requestID)
+	}
+	return request, nil
+}
+
+// responseRecorder collects the response written by the handler
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header)}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.body.Write(data)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// encodedBody returns the recorded body for a proxy response. Bodies that are not
+// valid UTF-8 are base64-encoded.
+func (r *responseRecorder) encodedBody() (string, bool) {
+	if !utf8.Valid(r.body.Bytes()) {
+		return base64.StdEncoding.EncodeToString(r.body.Bytes()), true
+	}
+	return r.body.String(), false
+}
+
+// statusCode returns the recorded status, 200 when none was written
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// toV1 converts the recorded response to a payload format 1.0 response
+func (r *responseRecorder) toV1() *events.APIGatewayProxyResponse {
+	body, encoded := r.encodedBody()
+	return &events.APIGatewayProxyResponse{
+		StatusCode:        r.statusCode(),
+		MultiValueHeaders: r.header,
+		Body:              body,
+		IsBase64Encoded:   encoded,
+	}
+}
+
+// toV2 converts the recorded response to a payload format 2.0 response
+func (r *responseRecorder) toV2() *events.APIGatewayV2HTTPResponse {
+	body, encoded := r.encodedBody()
+	response := &events.APIGatewayV2HTTPResponse{
+		StatusCode:      r.statusCode(),
+		Headers:         make(map[string]string, len(r.header)),
+		Body:            body,
+		IsBase64Encoded: encoded,
+	}
+	for name, values := range r.header {
+		if name == "Set-Cookie" {
+			response.Cookies = values
+			continue
+		}
+		response.Headers[name] = strings.Join(values, ",")
+	}
+	return response
+}
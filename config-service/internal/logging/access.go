@@ -0,0 +1,30 @@
+package logging
+
+import "fmt"
+
+// AccessLogFields lists the fields an access log line can include; the request
+// ID is always included
+var AccessLogFields = []string{"method", "path", "route", "status", "duration", "client_ip", "user_agent", "tenant"}
+
+// ValidateAccessLogFields reports an error for unknown access log fields
+func ValidateAccessLogFields(fields []string) error {
+	known := make(map[string]bool, len(AccessLogFields))
+	for _, field := range AccessLogFields {
+		known[field] = true
+	}
+
+	for _, field := range fields {
+		if !known[field] {
+			return fmt.Errorf("unknown access log field: %s (supported: %v)", field, AccessLogFields)
+		}
+	}
+	return nil
+}
+
+// ValidateSampleRate reports an error for sample rates outside [0, 1]
+func ValidateSampleRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("invalid sample rate: %v (must be between 0 and 1)", rate)
+	}
+	return nil
+}
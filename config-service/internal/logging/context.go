@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/requestid"
+)
+
+// fieldsKey is the type of the context key holding request-scoped log fields
+type fieldsKey struct{}
+
+// WithFields returns a copy of the context carrying the log fields in addition
+// to any it already carries. Empty values are skipped.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	merged := make(logrus.Fields, len(existing)+len(fields))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		if value != "" && value != nil {
+			merged[key] = value
+		}
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns a logger entry pre-populated with the request-scoped
+// fields carried by the context, such as the request ID, trace ID, tenant, and
+// route. A context without fields still yields the request ID when it carries one.
+func FromContext(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	if ctx == nil {
+		return logrus.NewEntry(logger)
+	}
+	if fields, ok := ctx.Value(fieldsKey{}).(logrus.Fields); ok {
+		return logger.WithFields(fields)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		return logger.WithField("request_id", id)
+	}
+	return logrus.NewEntry(logger)
+}
@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log output formats
+const (
+	FormatJSON   = "json"
+	FormatText   = "text"
+	FormatLogfmt = "logfmt"
+)
+
+// NewFormatter returns the logrus formatter for a log format
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case FormatJSON, "":
+		return &logrus.JSONFormatter{}, nil
+	case FormatText:
+		return &logrus.TextFormatter{FullTimestamp: true, DisableColors: true}, nil
+	case FormatLogfmt:
+		return &logrus.TextFormatter{FullTimestamp: true, DisableColors: true, QuoteEmptyFields: true, DisableSorting: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s (must be json, text, or logfmt)", format)
+	}
+}
+
+// Controller changes the level and format of a logger at runtime
+type Controller struct {
+	logger       *logrus.Logger
+	defaultLevel logrus.Level
+	attached     []*logrus.Logger
+
+	mu     sync.Mutex
+	format string
+}
+
+// NewController applies the configured level and format to the logger and
+// returns a controller for changing them later
+func NewController(logger *logrus.Logger, level, format string) (*Controller, error) {
+	c := &Controller{logger: logger}
+
+	if err := c.SetLevel(level); err != nil {
+		return nil, err
+	}
+	if err := c.SetFormat(format); err != nil {
+		return nil, err
+	}
+	c.defaultLevel = logger.GetLevel()
+
+	return c, nil
+}
+
+// Attach makes level changes also apply to additional loggers, such as a
+// dedicated access logger with its own format. It must be called before the
+// controller is used concurrently.
+func (c *Controller) Attach(loggers ...*logrus.Logger) {
+	for _, logger := range loggers {
+		logger.SetLevel(c.logger.GetLevel())
+	}
+	c.attached = append(c.attached, loggers...)
+}
+
+// Level returns the current log level
+func (c *Controller) Level() string {
+	return c.logger.GetLevel().String()
+}
+
+// SetLevel changes the log level
+func (c *Controller) SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("unsupported log level: %s", level)
+	}
+	c.applyLevel(parsed)
+	return nil
+}
+
+// applyLevel sets the level on the logger and all attached loggers
+func (c *Controller) applyLevel(level logrus.Level) {
+	c.logger.SetLevel(level)
+	for _, logger := range c.attached {
+		logger.SetLevel(level)
+	}
+}
+
+// Format returns the current log format
+func (c *Controller) Format() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.format
+}
+
+// SetFormat changes the log format
+func (c *Controller) SetFormat(format string) error {
+	formatter, err := NewFormatter(format)
+	if err != nil {
+		return err
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logger.SetFormatter(formatter)
+	c.format = format
+	return nil
+}
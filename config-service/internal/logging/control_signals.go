@@ -0,0 +1,37 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WatchSignals adjusts the level on signals until the context is done: SIGUSR1
+// makes logging one level more verbose and SIGUSR2 restores the configured level
+func (c *Controller) WatchSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-signals:
+				level := c.defaultLevel
+				if sig == syscall.SIGUSR1 && c.logger.GetLevel() < logrus.TraceLevel {
+					level = c.logger.GetLevel() + 1
+				}
+				c.applyLevel(level)
+				c.logger.Infof("Log level changed to %s by signal %s", level, sig)
+			}
+		}
+	}()
+}
@@ -0,0 +1,9 @@
+//go:build windows || plan9
+
+package logging
+
+import "context"
+
+// WatchSignals is a no-op on platforms without SIGUSR1 and SIGUSR2; use the
+// admin API to change the log level instead
+func (c *Controller) WatchSignals(ctx context.Context) {}
@@ -0,0 +1,17 @@
+package logging
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// New creates a JSON logger writing to the output with sensitive-data scrubbing installed
+func New(output io.Writer) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetOutput(output)
+	logger.SetLevel(logrus.InfoLevel)
+	logger.AddHook(&ScrubHook{})
+	return logger
+}
@@ -0,0 +1,250 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout is the timestamp embedded in rotated file names
+const backupTimeLayout = "20060102T150405.000000000"
+
+// RotatingFile is an io.WriteCloser that writes to a file and rotates it once
+// it exceeds a size or age. Rotated files are renamed with a timestamp
+// (app.log becomes app-20261016T120000.000000000.log), optionally gzipped, and
+// pruned to a maximum number of backups.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	compress   bool
+	now        func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	pending  sync.WaitGroup
+}
+
+// RotatingFileOption defines functional options for configuring a RotatingFile
+type RotatingFileOption func(*RotatingFile)
+
+// WithMaxSize rotates the file before it grows beyond the size in bytes; zero disables size-based rotation
+func WithMaxSize(bytes int64) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.maxSize = bytes
+	}
+}
+
+// WithRotationInterval rotates the file once it is older than the interval; zero disables time-based rotation
+func WithRotationInterval(interval time.Duration) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.interval = interval
+	}
+}
+
+// WithMaxBackups keeps at most this many rotated files; zero keeps all
+func WithMaxBackups(backups int) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.maxBackups = backups
+	}
+}
+
+// WithCompression gzips rotated files
+func WithCompression(compress bool) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.compress = compress
+	}
+}
+
+// NewRotatingFile opens the log file for appending, creating it and its directory if needed
+func NewRotatingFile(path string, options ...RotatingFileOption) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path: path,
+		now:  time.Now,
+	}
+	for _, option := range options {
+		option(f)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes to the current file, rotating it first when the write would
+// exceed the maximum size or the file is older than the rotation interval
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	tooLarge := f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize
+	tooOld := f.interval > 0 && f.now().Sub(f.openedAt) >= f.interval
+	if tooLarge || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file and starts a new one
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// Close closes the file and waits for pending compression to finish
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.pending.Wait()
+	return err
+}
+
+// open opens the log file for appending and records its size and age
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	return nil
+}
+
+// rotate renames the current file to a timestamped backup and opens a new one
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		f.file = nil
+	}
+
+	backup := f.backupName(f.now())
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		if f.compress {
+			compressFile(backup)
+		}
+		f.prune()
+	}()
+
+	return nil
+}
+
+// backupName returns an unused path for a file rotated at t
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+	for {
+		name := fmt.Sprintf("%s-%s%s", base, t.UTC().Format(backupTimeLayout), ext)
+		if !fileExists(name) && !fileExists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Nanosecond)
+	}
+}
+
+// fileExists reports whether a file exists at the path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// prune removes the oldest rotated files beyond the maximum number of backups
+func (f *RotatingFile) prune() {
+	if f.maxBackups <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+	matches, _ := filepath.Glob(base + "-*" + ext + "*")
+
+	// A backup being compressed exists both plain and gzipped; count it once
+	seen := make(map[string]bool, len(matches))
+	backups := make([]string, 0, len(matches))
+	for _, match := range matches {
+		name := strings.TrimSuffix(match, ".gz")
+		if !seen[name] {
+			seen[name] = true
+			backups = append(backups, name)
+		}
+	}
+
+	// Timestamps sort lexically, so the oldest backups come first
+	sort.Strings(backups)
+	for len(backups) > f.maxBackups {
+		os.Remove(backups[0])
+		os.Remove(backups[0] + ".gz")
+		backups = backups[1:]
+	}
+}
+
+// compressFile gzips a file and removes the original
+func compressFile(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, copyErr := io.Copy(gz, src)
+	closeErr := gz.Close()
+	if err := dst.Close(); copyErr != nil || closeErr != nil || err != nil {
+		os.Remove(path + ".gz")
+		return
+	}
+
+	src.Close()
+	os.Remove(path)
+}
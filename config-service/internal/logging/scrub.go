@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces sensitive values in log output
+const Redacted = "[REDACTED]"
+
+// allowedFields lists the log fields that may carry values. Any other field is
+// redacted, so a new field must be added here deliberately before it can leak
+// anything into the logs.
+var allowedFields = map[string]bool{
+	"api_key_id":    true,
+	"attempt":       true,
+	"body":          true,
+	"bytes":         true,
+	"client_ip":     true,
+	"component":     true,
+	"config":        true,
+	"count":         true,
+	"dataset":       true,
+	"decoy":         true,
+	"duration":      true,
+	"error_class":   true,
+	"event":         true,
+	"from_state":    true,
+	"job":           true,
+	"keys":          true,
+	"limit":         true,
+	"limiter":       true,
+	"listener":      true,
+	"method":        true,
+	"model":         true,
+	"path":          true,
+	"pid":           true,
+	"problems":      true,
+	"reason":        true,
+	"sample_rate":   true,
+	"request_id":    true,
+	"route":         true,
+	"score":         true,
+	"signal":        true,
+	"slowest_stage": true,
+	"stack":         true,
+	"stages_ms":     true,
+	"status":        true,
+	"strength":      true,
+	"tenant":        true,
+	"threshold":     true,
+	"to_state":      true,
+	"topic":         true,
+	"trace_id":      true,
+	"trigger":       true,
+	"user_agent":    true,
+	"webhook":       true,
+}
+
+// verbatimFields are allowed fields whose values are validated identifiers that
+// would otherwise be mistaken for hashes, e.g. 32-hex-digit W3C trace IDs
+var verbatimFields = map[string]bool{
+	"trace_id": true,
+}
+
+// sensitivePatterns match values that must never appear in log messages
+var sensitivePatterns = []*regexp.Regexp{
+	// Email addresses
+	regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	// JSON Web Tokens
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	// API key secrets issued by this service
+	regexp.MustCompile(`csk_[A-Za-z0-9_-]+`),
+	// Full password hashes (NTLM, SHA-1, SHA-256, ...)
+	regexp.MustCompile(`\b[0-9A-Fa-f]{32,}\b`),
+	// HIBP range URLs, whose path carries a password hash prefix
+	regexp.MustCompile(`range/[0-9A-Fa-f]{5}\b`),
+}
+
+// AllowField adds a field to the allowlist. It is intended for package
+// initialization only and must not be called concurrently with logging.
+func AllowField(name string) {
+	allowedFields[name] = true
+}
+
+// IsAllowedField reports whether the field may carry a value in log output
+func IsAllowedField(name string) bool {
+	return allowedFields[name]
+}
+
+// ScrubString redacts sensitive values from a string
+func ScrubString(value string) string {
+	for _, pattern := range sensitivePatterns {
+		value = pattern.ReplaceAllString(value, Redacted)
+	}
+	return value
+}
+
+// ScrubHook is a logrus hook that redacts sensitive data from every entry before
+// it is written: fields outside the allowlist are replaced, and emails, hashes,
+// and credentials are removed from messages and allowed string fields.
+type ScrubHook struct{}
+
+// Levels returns the levels the hook applies to
+func (h *ScrubHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire scrubs the entry
+func (h *ScrubHook) Fire(entry *logrus.Entry) error {
+	entry.Message = ScrubString(entry.Message)
+
+	for key, value := range entry.Data {
+		if !allowedFields[key] && key != logrus.ErrorKey {
+			entry.Data[key] = Redacted
+			continue
+		}
+		if verbatimFields[key] {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = ScrubString(v)
+		case error:
+			entry.Data[key] = ScrubString(v.Error())
+		case fmt.Stringer:
+			entry.Data[key] = ScrubString(v.String())
+		}
+	}
+
+	return nil
+}
+
+// sensitiveRoutePrefixes lists routes whose request bodies carry passwords,
+// hashes, or credentials and therefore must never be logged
+var sensitiveRoutePrefixes = []string{
+	"/api/v1/password",
+	"/api/v1/admin/api-keys",
+	"/api/v1/admission",
+}
+
+// IsSensitivePath reports whether request bodies for the path must never be
+// logged. The routes may be mounted under a prefix by an embedding application.
+func IsSensitivePath(path string) bool {
+	for _, prefix := range sensitiveRoutePrefixes {
+		if strings.Contains(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
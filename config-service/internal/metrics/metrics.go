@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tags are dimensions attached to a metric, such as route or status
+type Tags map[string]string
+
+// Recorder records service metrics. Implementations must be safe for concurrent use.
+type Recorder interface {
+	// Count adds value to a counter
+	Count(name string, value int64, tags Tags)
+	// Timing records a duration observation
+	Timing(name string, duration time.Duration, tags Tags)
+	// Gauge sets a gauge to value
+	Gauge(name string, value float64, tags Tags)
+}
+
+// Backends selectable in configuration
+const (
+	BackendNone       = "none"
+	BackendPrometheus = "prometheus"
+	BackendStatsD     = "statsd"
+)
+
+// Noop discards all metrics
+type Noop struct{}
+
+// Count discards the counter
+func (Noop) Count(string, int64, Tags) {}
+
+// Timing discards the observation
+func (Noop) Timing(string, time.Duration, Tags) {}
+
+// Gauge discards the gauge
+func (Noop) Gauge(string, float64, Tags) {}
+
+// ValidateBackend reports whether the backend name is supported
+func ValidateBackend(backend string) error {
+	switch backend {
+	case BackendNone, BackendPrometheus, BackendStatsD:
+		return nil
+	default:
+		return fmt.Errorf("unsupported metrics backend: %s (must be none, prometheus, or statsd)", backend)
+	}
+}
+
+// sortedKeys returns the tag names in a stable order
+func (t Tags) sortedKeys() []string {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sanitizeName replaces characters that are not valid in metric names
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
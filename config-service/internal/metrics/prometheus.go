@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// labelEscaper escapes label values for the text exposition format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// defaultBuckets are the histogram upper bounds in seconds
+var defaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram accumulates observations for one series
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// family holds all series of one metric
+type family struct {
+	kind       string
+	series     map[string]float64
+	histograms map[string]*histogram
+}
+
+// Prometheus keeps metrics in memory and serves them in the Prometheus text
+// exposition format
+type Prometheus struct {
+	namespace string
+
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewPrometheus creates a Prometheus recorder; metric names are prefixed with
+// the namespace
+func NewPrometheus(namespace string) *Prometheus {
+	return &Prometheus{
+		namespace: namespace,
+		families:  make(map[string]*family),
+	}
+}
+
+// Count adds value to the counter <namespace>_<name>_total
+func (p *Prometheus) Count(name string, value int64, tags Tags) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f := p.family(name+"_total", "counter")
+	f.series[formatLabels(tags)] += float64(value)
+}
+
+// Timing observes the duration in the histogram <namespace>_<name>_seconds
+func (p *Prometheus) Timing(name string, duration time.Duration, tags Tags) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f := p.family(name+"_seconds", "histogram")
+	labels := formatLabels(tags)
+	h, ok := f.histograms[labels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(defaultBuckets))}
+		f.histograms[labels] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range defaultBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Gauge sets the gauge <namespace>_<name>
+func (p *Prometheus) Gauge(name string, value float64, tags Tags) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f := p.family(name, "gauge")
+	f.series[formatLabels(tags)] = value
+}
+
+// family returns the metric family, creating it if needed
+func (p *Prometheus) family(name, kind string) *family {
+	fullName := sanitizeName(name)
+	if p.namespace != "" {
+		fullName = sanitizeName(p.namespace) + "_" + fullName
+	}
+
+	f, ok := p.families[fullName]
+	if !ok {
+		f = &family{
+			kind:       kind,
+			series:     make(map[string]float64),
+			histograms: make(map[string]*histogram),
+		}
+		p.families[fullName] = f
+	}
+	return f
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	names := make([]string, 0, len(p.families))
+	for name := range p.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := p.families[name]
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, f.kind)
+
+		if f.kind == "histogram" {
+			for _, labels := range sortedSeries(f.histograms) {
+				h := f.histograms[labels]
+				for i, bound := range defaultBuckets {
+					fmt.Fprintf(&b, "%s_bucket%s %d\n", name, withLabel(labels, "le", strconv.FormatFloat(bound, 'g', -1, 64)), h.counts[i])
+				}
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), h.count)
+				fmt.Fprintf(&b, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+				fmt.Fprintf(&b, "%s_count%s %d\n", name, labels, h.count)
+			}
+			continue
+		}
+
+		for _, labels := range sortedSeries(f.series) {
+			fmt.Fprintf(&b, "%s%s %s\n", name, labels, strconv.FormatFloat(f.series[labels], 'g', -1, 64))
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the metrics for scraping
+func (p *Prometheus) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		p.WriteTo(w)
+	})
+}
+
+// sortedSeries returns the label sets of a family in a stable order
+func sortedSeries[V any](series map[string]V) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels renders tags as a Prometheus label set
+func formatLabels(tags Tags) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(tags))
+	for _, key := range tags.sortedKeys() {
+		parts = append(parts, sanitizeName(key)+`="`+labelEscaper.Replace(tags[key])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// withLabel adds a label to a rendered label set
+func withLabel(labels, key, value string) string {
+	label := key + `="` + value + `"`
+	if labels == "" {
+		return "{" + label + "}"
+	}
+	return labels[:len(labels)-1] + "," + label + "}"
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsD emits metrics over UDP to a StatsD or DogStatsD agent. Sends are
+// fire-and-forget: a missing agent never slows down or fails requests.
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+	tags   bool
+}
+
+// NewStatsD connects to the agent at address. Metric names are prefixed with
+// prefix; tags are sent in DogStatsD format when dogStatsDTags is set and are
+// dropped otherwise, since plain StatsD has no tag support.
+func NewStatsD(address, prefix string, dogStatsDTags bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd agent: %w", err)
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	return &StatsD{conn: conn, prefix: prefix, tags: dogStatsDTags}, nil
+}
+
+// Count sends a counter increment
+func (s *StatsD) Count(name string, value int64, tags Tags) {
+	s.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Timing sends a timer in milliseconds
+func (s *StatsD) Timing(name string, duration time.Duration, tags Tags) {
+	s.send(name, strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', 3, 64), "ms", tags)
+}
+
+// Gauge sends a gauge value
+func (s *StatsD) Gauge(name string, value float64, tags Tags) {
+	s.send(name, strconv.FormatFloat(value, 'g', -1, 64), "g", tags)
+}
+
+// send writes a single metric datagram
+func (s *StatsD) send(name, value, kind string, tags Tags) {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+
+	if s.tags && len(tags) > 0 {
+		b.WriteString("|#")
+		for i, key := range tags.sortedKeys() {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(key)
+			b.WriteByte(':')
+			b.WriteString(strings.NewReplacer(",", "_", "|", "_", "#", "_").Replace(tags[key]))
+		}
+	}
+
+	s.conn.Write([]byte(b.String()))
+}
+
+// Close closes the connection to the agent
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
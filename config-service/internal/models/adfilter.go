@@ -0,0 +1,40 @@
+package models
+
+// AD filter verdicts
+const (
+	ADFilterAllow = "allow"
+	ADFilterDeny  = "deny"
+)
+
+// ADFilterRequest is sent by the companion of an Active Directory password
+// filter DLL when a password is set or changed. The filter hashes the
+// candidate password itself, so the plaintext never leaves the domain
+// controller. Length and Classes describe the plaintext for the policy rules
+// and are optional; rules they would answer are skipped when they are absent.
+type ADFilterRequest struct {
+	Username string `json:"username" binding:"required,max=256"`
+	FullName string `json:"full_name" binding:"max=256"`
+	// NTLMHash is the hex MD4 digest of the UTF-16LE encoded password
+	NTLMHash string `json:"ntlm_hash" binding:"required,len=32,hexadecimal"`
+	Length   int    `json:"length" binding:"min=0,max=1024"`
+	// Classes lists the character classes the password contains
+	Classes []string `json:"classes" binding:"omitempty,dive,oneof=upper lower digit special"`
+	// Operation is "set" for an administrative reset or "change" for a user change
+	Operation string `json:"operation" binding:"omitempty,oneof=set change"`
+}
+
+// ADFilterVerdict is the answer to an AD password filter. Skipped lists the
+// rules that could not be evaluated, because the request lacked the input or
+// the latency budget ran out; Degraded is set when the budget ran out.
+type ADFilterVerdict struct {
+	Verdict   string        `json:"verdict"`
+	Checks    []PolicyCheck `json:"checks"`
+	Skipped   []PolicyRule  `json:"skipped,omitempty"`
+	Degraded  bool          `json:"degraded,omitempty"`
+	ElapsedMS int64         `json:"elapsed_ms"`
+}
+
+// Allowed reports whether the password may be set
+func (v *ADFilterVerdict) Allowed() bool {
+	return v.Verdict == ADFilterAllow
+}
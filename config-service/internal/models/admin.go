@@ -0,0 +1,204 @@
+package models
+
+import "time"
+
+// CacheStats represents statistics about the breach result cache
+type CacheStats struct {
+	Entries         int    `json:"entries"`
+	Hits            uint64 `json:"hits"`
+	Misses          uint64 `json:"misses"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// BannedWord represents an entry in the banned word list
+type BannedWord struct {
+	Word    string    `json:"word"`
+	AddedAt time.Time `json:"added_at"`
+	// Source is the word list file the word was loaded from; empty for words
+	// added through the admin API
+	Source string `json:"source,omitempty"`
+}
+
+// DeletedBannedWord is a banned word removed through the admin API, which can
+// be restored until it is purged
+type DeletedBannedWord struct {
+	BannedWord
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// BannedWordsRequest represents the request body for adding banned words
+type BannedWordsRequest struct {
+	Words []string `json:"words" binding:"required,min=1"`
+}
+
+// Statuses of a banned list import
+const (
+	// BannedImportReceiving is an import whose upload is still being received
+	BannedImportReceiving = "receiving"
+	// BannedImportProcessing is an import whose words are being normalized and deduplicated
+	BannedImportProcessing = "processing"
+	// BannedImportCompleted is an import whose words are in the banned list
+	BannedImportCompleted = "completed"
+	// BannedImportFailed is an import that left the banned list unchanged
+	BannedImportFailed = "failed"
+)
+
+// Formats of a banned list import
+const (
+	// BannedImportText is one word per line, with # starting a comment
+	BannedImportText = "text"
+	// BannedImportNDJSON is one JSON string, or object with a "word", per line
+	BannedImportNDJSON = "ndjson"
+)
+
+// BannedImportJob reports the progress of a bulk banned list import. The
+// imported words only take effect, all at once, when it completes.
+type BannedImportJob struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Source  string `json:"source"`
+	Format  string `json:"format"`
+	Replace bool   `json:"replace"`
+	// BytesTotal counts the bytes received and BytesProcessed those read back
+	// while importing
+	BytesTotal     int64 `json:"bytes_total"`
+	BytesProcessed int64 `json:"bytes_processed"`
+	Lines          int   `json:"lines"`
+	// Words counts the distinct words after normalization, Duplicates the
+	// lines repeating one, Skipped the blank and comment lines, and Invalid
+	// the lines that could not be decoded
+	Words      int `json:"words"`
+	Duplicates int `json:"duplicates"`
+	Skipped    int `json:"skipped"`
+	Invalid    int `json:"invalid"`
+	// Added and Removed are the changes made to the banned list on completion
+	Added       int        `json:"added"`
+	Removed     int        `json:"removed"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Statuses of a dataset reload
+const (
+	// DatasetReloadLoading is a reload whose new version is being loaded and checked
+	DatasetReloadLoading = "loading"
+	// DatasetReloadCompleted is a reload whose new version is in use
+	DatasetReloadCompleted = "completed"
+	// DatasetReloadFailed is a reload whose new version could not be loaded,
+	// leaving the previous version in use
+	DatasetReloadFailed = "failed"
+	// DatasetReloadRolledBack is a reload whose new version was switched to but
+	// failed the check after it, so the previous version was switched back to
+	DatasetReloadRolledBack = "rolled_back"
+)
+
+// DatasetReload reports a reload of a dataset from disk
+type DatasetReload struct {
+	ID          string     `json:"id"`
+	Dataset     string     `json:"dataset"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// LoggingSettings represents the runtime log level and format
+type LoggingSettings struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// QuotaStatus represents a tenant's monthly quota and how much of it is used.
+// A zero limit means unlimited.
+type QuotaStatus struct {
+	Tenant    string    `json:"tenant,omitempty"`
+	Period    string    `json:"period"`
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// TenantUsage represents the number of requests a tenant made in a period and
+// the outcomes of its password checks. A zero quota means unlimited, and the
+// average score covers the checks that produced a strength score.
+type TenantUsage struct {
+	Tenant         string  `json:"tenant"`
+	Requests       int64   `json:"requests"`
+	Quota          int64   `json:"quota"`
+	Remaining      int64   `json:"remaining"`
+	Checks         int64   `json:"checks"`
+	BreachHits     int64   `json:"breach_hits"`
+	PolicyFailures int64   `json:"policy_failures"`
+	AverageScore   float64 `json:"average_score"`
+}
+
+// CheckOutcome is the result of one password check counted in usage reports
+// and analytics
+type CheckOutcome struct {
+	Breached      bool
+	PolicyFailure bool
+	// Score is the strength score, if the check produced one
+	Score  int
+	Scored bool
+	// Violations lists the policy rules the password failed
+	Violations []PolicyRule
+}
+
+// KeyUsage represents the number of requests made with an API key in a period
+type KeyUsage struct {
+	KeyID    string `json:"key_id"`
+	Tenant   string `json:"tenant,omitempty"`
+	Requests int64  `json:"requests"`
+}
+
+// WordlistInfo describes a common password dictionary served by the admin API
+type WordlistInfo struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	Size    int64  `json:"size"`
+	ETag    string `json:"etag"`
+}
+
+// AnalyticsReport holds aggregate statistics of the password checks made in a
+// range of days (UTC). Nothing in it identifies a password, caller, or tenant.
+// When fewer checks than the reporting minimum were made, only the count is
+// reported and Suppressed is set.
+type AnalyticsReport struct {
+	From                 string           `json:"from"`
+	To                   string           `json:"to"`
+	Checks               int64            `json:"checks"`
+	Suppressed           bool             `json:"suppressed,omitempty"`
+	Breached             int64            `json:"breached"`
+	BreachedPercent      float64          `json:"breached_percent"`
+	PolicyFailures       int64            `json:"policy_failures"`
+	PolicyFailurePercent float64          `json:"policy_failure_percent"`
+	AverageScore         float64          `json:"average_score"`
+	ScoreDistribution    []ScoreBucket    `json:"score_distribution"`
+	TopViolations        []RuleViolations `json:"top_violations"`
+}
+
+// ScoreBucket counts the checks that scored from Min to Max, inclusive
+type ScoreBucket struct {
+	Min   int   `json:"min"`
+	Max   int   `json:"max"`
+	Count int64 `json:"count"`
+}
+
+// RuleViolations counts the checks that failed a policy rule
+type RuleViolations struct {
+	Rule    PolicyRule `json:"rule"`
+	Count   int64      `json:"count"`
+	Percent float64    `json:"percent"`
+}
+
+// UsageReport represents per-tenant and per-key request counts for a month
+type UsageReport struct {
+	Period   string        `json:"period"`
+	ResetsAt time.Time     `json:"resets_at"`
+	Tenants  []TenantUsage `json:"tenants"`
+	Keys     []KeyUsage    `json:"keys"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "encoding/json"
+
+// Kubernetes admission API identifiers
+const (
+	AdmissionAPIVersion = "admission.k8s.io/v1"
+	AdmissionKind       = "AdmissionReview"
+)
+
+// Annotations that opt a Secret into password checks
+const (
+	// AnnotationCheckPasswords set to "true" opts a Secret into password checks
+	AnnotationCheckPasswords = "passwords.config-service.io/check"
+
+	// AnnotationPasswordKeys lists the comma-separated data keys holding
+	// passwords; without it every key is checked
+	AnnotationPasswordKeys = "passwords.config-service.io/keys"
+)
+
+// AdmissionReview is the request and response body of a Kubernetes
+// validating admission webhook, reduced to the fields the webhook uses
+type AdmissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the operation being admitted
+type AdmissionRequest struct {
+	UID       string           `json:"uid"`
+	Kind      GroupVersionKind `json:"kind"`
+	Namespace string           `json:"namespace,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Operation string           `json:"operation"`
+	Object    json.RawMessage  `json:"object,omitempty"`
+}
+
+// GroupVersionKind identifies the type of an admitted object
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// AdmissionResponse allows or denies the operation
+type AdmissionResponse struct {
+	UID      string           `json:"uid"`
+	Allowed  bool             `json:"allowed"`
+	Status   *AdmissionStatus `json:"status,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// AdmissionStatus explains a denied operation
+type AdmissionStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// KubernetesSecret is a Kubernetes Secret, reduced to the fields the webhook uses
+type KubernetesSecret struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	// Data holds base64-encoded values, decoded by encoding/json
+	Data       map[string][]byte `json:"data"`
+	StringData map[string]string `json:"stringData"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// API key scopes
+const (
+	// ScopeCheck allows calling the password check and breach endpoints
+	ScopeCheck = "check"
+
+	// ScopeAdmin allows calling the admin API, and implies ScopeCheck
+	ScopeAdmin = "admin"
+)
+
+// APIKey represents an issued API key. Only a hash of the secret is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Tenant     string     `json:"tenant"`
+	Scopes     []string   `json:"scopes"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants the scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRevoked reports whether the key has been revoked
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// APIKeyRequest represents the request body for issuing an API key
+type APIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Tenant string   `json:"tenant" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=check admin"`
+}
+
+// APIKeySecretResponse represents an API key together with its plaintext secret,
+// which is only ever returned when the key is issued or rotated
+type APIKeySecretResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// CalibrationReport measures how well password scores separate a labeled
+// corpus of cracked and uncracked passwords. Cracked passwords are the
+// positive class: a well-calibrated scorer gives them lower scores.
+type CalibrationReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Samples     int       `json:"samples"`
+	Cracked     int       `json:"cracked"`
+	Uncracked   int       `json:"uncracked"`
+	// AUC is the area under the ROC curve: the probability that a cracked
+	// password scores lower than an uncracked one, with ties counting half
+	AUC       float64               `json:"auc"`
+	ROC       []ROCPoint            `json:"roc"`
+	Strengths []StrengthCalibration `json:"strengths"`
+	// CrackedRatedStrong counts cracked passwords rated strong or better
+	CrackedRatedStrong int `json:"cracked_rated_strong"`
+	// UncrackedRatedWeak counts uncracked passwords rated weak
+	UncrackedRatedWeak int                  `json:"uncracked_rated_weak"`
+	Misclassified      []CalibrationExample `json:"misclassified"`
+}
+
+// ROCPoint is a point of the ROC curve: the rates at which cracked and
+// uncracked passwords score at or below a threshold
+type ROCPoint struct {
+	Score             int     `json:"score"`
+	TruePositiveRate  float64 `json:"true_positive_rate"`
+	FalsePositiveRate float64 `json:"false_positive_rate"`
+}
+
+// StrengthCalibration counts the corpus passwords rated at a strength
+type StrengthCalibration struct {
+	Strength    PasswordStrength `json:"strength"`
+	Cracked     int              `json:"cracked"`
+	Uncracked   int              `json:"uncracked"`
+	CrackedRate float64          `json:"cracked_rate"`
+}
+
+// CalibrationExample is a cracked password rated strong or better, or an
+// uncracked password rated weak
+type CalibrationExample struct {
+	Password string           `json:"password"`
+	Cracked  bool             `json:"cracked"`
+	Strength PasswordStrength `json:"strength"`
+	Score    int              `json:"score"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
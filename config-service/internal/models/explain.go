@@ -0,0 +1,11 @@
+package models
+
+// ExplainStep is a rule or matcher run by a check in explain mode. Input and
+// Output describe the password only by properties such as its length, never
+// by its characters, so that traces can be attached to support tickets.
+type ExplainStep struct {
+	Step       string                 `json:"step"`
+	Input      map[string]interface{} `json:"input,omitempty"`
+	Output     map[string]interface{} `json:"output"`
+	DurationNS int64                  `json:"duration_ns"`
+}
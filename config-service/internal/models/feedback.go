@@ -0,0 +1,132 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// FeedbackCode identifies a feedback warning or suggestion independently of
+// its wording, so that tenants can replace the copy shown to their users
+type FeedbackCode string
+
+// Codes of the feedback warnings
+const (
+	FeedbackCommonPattern        FeedbackCode = "common_pattern"
+	FeedbackSequentialCharacters FeedbackCode = "sequential_characters"
+	FeedbackRepeatedPattern      FeedbackCode = "repeated_pattern"
+	FeedbackCommonPassword       FeedbackCode = "common_password"
+	FeedbackBannedWord           FeedbackCode = "banned_word"
+	FeedbackBreached             FeedbackCode = "breached"
+	FeedbackRulesUnchecked       FeedbackCode = "rules_unchecked"
+	FeedbackEasyToGuess          FeedbackCode = "easy_to_guess"
+)
+
+// Codes of the feedback suggestions
+const (
+	FeedbackUniqueCombination    FeedbackCode = "unique_combination"
+	FeedbackAvoidSequences       FeedbackCode = "avoid_sequences"
+	FeedbackAvoidRepetition      FeedbackCode = "avoid_repetition"
+	FeedbackAddUppercase         FeedbackCode = "add_uppercase"
+	FeedbackAddLowercase         FeedbackCode = "add_lowercase"
+	FeedbackAddNumbers           FeedbackCode = "add_numbers"
+	FeedbackAddSpecial           FeedbackCode = "add_special"
+	FeedbackLongerPassword       FeedbackCode = "longer_password"
+	FeedbackUsePassphrase        FeedbackCode = "use_passphrase"
+	FeedbackMixCharacterTypes    FeedbackCode = "mix_character_types"
+	FeedbackAvoidCommonPasswords FeedbackCode = "avoid_common_passwords"
+	FeedbackAvoidBannedWords     FeedbackCode = "avoid_banned_words"
+	FeedbackChooseUncompromised  FeedbackCode = "choose_uncompromised"
+	FeedbackAvoidPredictable     FeedbackCode = "avoid_predictable"
+)
+
+// feedbackMessages are the English messages of the feedback codes, as the
+// strength checks write them
+var feedbackMessages = map[FeedbackCode]string{
+	FeedbackCommonPattern:        "Password contains common patterns",
+	FeedbackSequentialCharacters: "Password contains sequential characters",
+	FeedbackRepeatedPattern:      "Password contains repeated patterns",
+	FeedbackCommonPassword:       "Password is a commonly used password",
+	FeedbackBannedWord:           "Password contains a banned word",
+	FeedbackBreached:             "Password has appeared in data breaches",
+	FeedbackRulesUnchecked:       "Password could not be checked against all rules",
+	FeedbackEasyToGuess:          "Password is easy to guess",
+
+	FeedbackUniqueCombination:    "Use a more unique combination of characters",
+	FeedbackAvoidSequences:       "Avoid keyboard patterns and sequential characters",
+	FeedbackAvoidRepetition:      "Avoid repeating character sequences",
+	FeedbackAddUppercase:         "Add uppercase letters",
+	FeedbackAddLowercase:         "Add lowercase letters",
+	FeedbackAddNumbers:           "Add numbers",
+	FeedbackAddSpecial:           "Add special characters",
+	FeedbackLongerPassword:       "Use a longer password (12+ characters)",
+	FeedbackUsePassphrase:        "Consider using a passphrase with multiple words",
+	FeedbackMixCharacterTypes:    "Mix different character types more thoroughly",
+	FeedbackAvoidCommonPasswords: "Avoid passwords that appear in common password lists",
+	FeedbackAvoidBannedWords:     "Avoid words from the organization's banned list",
+	FeedbackChooseUncompromised:  "Choose a password that hasn't been compromised",
+	FeedbackAvoidPredictable:     "Avoid predictable words, dates, and substitutions",
+}
+
+// feedbackCodes maps the English messages back to their codes
+var feedbackCodes = func() map[string]FeedbackCode {
+	codes := make(map[string]FeedbackCode, len(feedbackMessages))
+	for code, message := range feedbackMessages {
+		codes[message] = code
+	}
+	return codes
+}()
+
+// FeedbackCodeOf returns the code of an English feedback message, or false
+// for messages without one, such as those of rule plugins
+func FeedbackCodeOf(message string) (FeedbackCode, bool) {
+	code, ok := feedbackCodes[message]
+	return code, ok
+}
+
+// Valid reports whether the code names a feedback message
+func (c FeedbackCode) Valid() bool {
+	_, ok := feedbackMessages[c]
+	return ok
+}
+
+// Message returns the English message of the code
+func (c FeedbackCode) Message() string {
+	return feedbackMessages[c]
+}
+
+// FeedbackMessage is a feedback code with its English message
+type FeedbackMessage struct {
+	Code    FeedbackCode `json:"code"`
+	Message string       `json:"message"`
+}
+
+// FeedbackMessages returns every feedback code with its English message, by code
+func FeedbackMessages() []FeedbackMessage {
+	messages := make([]FeedbackMessage, 0, len(feedbackMessages))
+	for code, message := range feedbackMessages {
+		messages = append(messages, FeedbackMessage{Code: code, Message: message})
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Code < messages[j].Code })
+	return messages
+}
+
+// FeedbackOverride replaces the copy of a feedback message for a tenant, in
+// one locale or, without a locale, in those without an override of their own
+type FeedbackOverride struct {
+	Code    FeedbackCode `json:"code" binding:"required"`
+	Locale  string       `json:"locale,omitempty"`
+	Message string       `json:"message" binding:"required,max=500"`
+}
+
+// FeedbackOverrides are the feedback overrides of a tenant
+type FeedbackOverrides struct {
+	Tenant    string             `json:"tenant"`
+	Overrides []FeedbackOverride `json:"overrides"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// FeedbackOverridesRequest represents the request body replacing the
+// feedback overrides of a tenant
+type FeedbackOverridesRequest struct {
+	Overrides []FeedbackOverride `json:"overrides" binding:"required,max=200,dive"`
+}
@@ -0,0 +1,143 @@
+package models
+
+// PolicyRule identifies a single rule of a password policy evaluation
+type PolicyRule string
+
+const (
+	RuleMinLength   PolicyRule = "min_length"
+	RuleMaxLength   PolicyRule = "max_length"
+	RuleUppercase   PolicyRule = "uppercase"
+	RuleLowercase   PolicyRule = "lowercase"
+	RuleNumbers     PolicyRule = "numbers"
+	RuleSpecial     PolicyRule = "special"
+	RuleNotUsername PolicyRule = "not_username"
+	RuleBannedWord  PolicyRule = "banned_word"
+	RuleMinStrength PolicyRule = "min_strength"
+	RuleNotBreached PolicyRule = "not_breached"
+	RuleGenerated   PolicyRule = "generated"
+	RuleMinEntropy  PolicyRule = "min_entropy"
+)
+
+// PolicyCheck is the outcome of one policy rule. Limit is the bound of the
+// length, breach count, and entropy rules.
+type PolicyCheck struct {
+	Rule    PolicyRule `json:"rule"`
+	Passed  bool       `json:"passed"`
+	Limit   int        `json:"limit,omitempty"`
+	Message string     `json:"message"`
+}
+
+// PolicyEvaluation is the outcome of every policy rule for a password
+type PolicyEvaluation struct {
+	Valid    bool             `json:"valid"`
+	Checks   []PolicyCheck    `json:"checks"`
+	Strength PasswordStrength `json:"strength"`
+	Score    int              `json:"score"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// Failed returns the checks that did not pass
+func (e *PolicyEvaluation) Failed() []PolicyCheck {
+	return failedChecks(e.Checks)
+}
+
+// failedChecks returns the checks that did not pass
+func failedChecks(checks []PolicyCheck) []PolicyCheck {
+	var failed []PolicyCheck
+	for _, check := range checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// KeycloakValidationRequest is sent by a Keycloak password policy provider
+type KeycloakValidationRequest struct {
+	Realm    string `json:"realm"`
+	Username string `json:"username"`
+	Password string `json:"password" binding:"required"`
+}
+
+// KeycloakPolicyError mirrors Keycloak's PolicyError: a message key of the
+// Keycloak themes, or literal text, and its parameters
+type KeycloakPolicyError struct {
+	Message    string        `json:"message"`
+	Parameters []interface{} `json:"parameters"`
+}
+
+// KeycloakValidationResponse reports whether a password passes the policy
+type KeycloakValidationResponse struct {
+	Valid  bool                  `json:"valid"`
+	Errors []KeycloakPolicyError `json:"errors,omitempty"`
+}
+
+// Auth0ValidationRequest is the user object of an Auth0 custom database
+// create or change password script
+type Auth0ValidationRequest struct {
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Auth0PasswordRule is a rule in Auth0's password policy description format
+type Auth0PasswordRule struct {
+	Message  string              `json:"message"`
+	Format   []interface{}       `json:"format,omitempty"`
+	Code     string              `json:"code"`
+	Verified bool                `json:"verified"`
+	Items    []Auth0PasswordRule `json:"items,omitempty"`
+}
+
+// Auth0PasswordDescription lists the rules of a password policy and whether
+// the password satisfies them
+type Auth0PasswordDescription struct {
+	Rules    []Auth0PasswordRule `json:"rules"`
+	Verified bool                `json:"verified"`
+}
+
+// Auth0ValidationResponse has the shape of Auth0's PasswordStrengthError;
+// Name, Message, and Code are only set when the password is rejected
+type Auth0ValidationResponse struct {
+	Name        string                   `json:"name,omitempty"`
+	Message     string                   `json:"message,omitempty"`
+	Code        string                   `json:"code,omitempty"`
+	Verified    bool                     `json:"verified"`
+	Description Auth0PasswordDescription `json:"description"`
+}
+
+// OktaInlineHookRequest is the part of an Okta registration inline hook
+// request used for password validation
+type OktaInlineHookRequest struct {
+	EventType string `json:"eventType"`
+	Data      struct {
+		UserProfile map[string]interface{} `json:"userProfile"`
+	} `json:"data"`
+}
+
+// OktaCommand is an inline hook command
+type OktaCommand struct {
+	Type  string            `json:"type"`
+	Value map[string]string `json:"value"`
+}
+
+// OktaErrorCause describes one reason an inline hook denied the request
+type OktaErrorCause struct {
+	ErrorSummary string `json:"errorSummary"`
+	Reason       string `json:"reason"`
+	LocationType string `json:"locationType"`
+	Location     string `json:"location"`
+	Domain       string `json:"domain"`
+}
+
+// OktaError is the error object of an inline hook response, shown to the user
+type OktaError struct {
+	ErrorSummary string           `json:"errorSummary"`
+	ErrorCauses  []OktaErrorCause `json:"errorCauses"`
+}
+
+// OktaInlineHookResponse is the response to an Okta inline hook
+type OktaInlineHookResponse struct {
+	Commands []OktaCommand `json:"commands"`
+	Error    *OktaError    `json:"error,omitempty"`
+}
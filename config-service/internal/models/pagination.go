@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Sort fields supported by admin list endpoints
+const (
+	SortByName      = "name"
+	SortByUpdatedAt = "updated_at"
+)
+
+// Page size limits for admin list endpoints
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// ListOptions controls pagination, sorting, and filtering of admin list endpoints
+type ListOptions struct {
+	Limit        int
+	Cursor       string
+	SortBy       string
+	Descending   bool
+	NameContains string
+	UpdatedSince time.Time
+}
+
+// PageInfo describes a page of results returned by an admin list endpoint
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+}
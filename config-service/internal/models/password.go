@@ -1,41 +1,138 @@
 package models
 
 import (
-	"fmt"
-	"strings"
-	"unicode"
+	"time"
+
+	"config-service/pkg/strength"
 )
 
-// PasswordRequest represents the request body for password strength check
+// PasswordRequest represents the request body for password strength check. The
+// longest password accepted is configured, so it is enforced by the password
+// validator rather than the binding.
 type PasswordRequest struct {
-	Password string `json:"password" binding:"required,min=8,max=128"`
+	Password string `json:"password" binding:"required,min=8"`
+	// Attest asks the strength check for a signed attestation token
+	Attest bool `json:"attest,omitempty"`
+	// AttestSubject is the account or nonce the attestation token is bound
+	// to, required with Attest
+	AttestSubject string `json:"attest_subject,omitempty" binding:"required_if=Attest true,max=256"`
+	// KeyboardLayout is a layout name such as "azerty" or a locale such as
+	// "fr-FR", whose key walks are penalized in addition to QWERTY ones
+	KeyboardLayout string `json:"keyboard_layout,omitempty"`
+	// PolicyID names the policy the password is validated against instead
+	// of the default rules
+	PolicyID string `json:"policy_id,omitempty" binding:"max=64"`
+	// Explain asks for the rules and matchers the check runs, in order, with
+	// what they found and how long they took
+	Explain bool `json:"explain,omitempty"`
 }
 
 // PasswordStrength represents the strength level of a password
-type PasswordStrength string
+type PasswordStrength = strength.Strength
 
 const (
-	StrengthWeak       PasswordStrength = "weak"
-	StrengthMedium     PasswordStrength = "medium"
-	StrengthStrong     PasswordStrength = "strong"
-	StrengthVeryStrong PasswordStrength = "very_strong"
+	StrengthWeak       = strength.Weak
+	StrengthMedium     = strength.Medium
+	StrengthStrong     = strength.Strong
+	StrengthVeryStrong = strength.VeryStrong
 )
 
 // PasswordRequirements represents the basic requirements check
-type PasswordRequirements struct {
-	Length      bool `json:"length"`
-	Uppercase   bool `json:"uppercase"`
-	Lowercase   bool `json:"lowercase"`
-	Numbers     bool `json:"numbers"`
-	SpecialChars bool `json:"special_chars"`
-}
+type PasswordRequirements = strength.Requirements
 
 // PasswordFeedback contains warnings and suggestions
-type PasswordFeedback struct {
-	Warnings    []string `json:"warnings"`
-	Suggestions []string `json:"suggestions"`
+type PasswordFeedback = strength.Feedback
+
+// KeyboardLayout is a keyboard layout whose key walks strength checks penalize
+type KeyboardLayout = strength.Layout
+
+// ParseKeyboardLayout resolves a layout name or a locale to a keyboard layout
+func ParseKeyboardLayout(hint string) (KeyboardLayout, error) {
+	return strength.ParseLayout(hint)
+}
+
+// PasswordComposition describes the characters and weak parts of a password
+type PasswordComposition = strength.Composition
+
+// PasswordMatch is a weak part of a password, by character position
+type PasswordMatch = strength.Match
+
+// CompositionRequest represents the request body for the composition of a
+// candidate password, which may be shorter than passwords are allowed to be
+type CompositionRequest struct {
+	Password       string `json:"password" binding:"required"`
+	KeyboardLayout string `json:"keyboard_layout,omitempty"`
+}
+
+// Character classes generated passwords are drawn from
+const (
+	CharacterClassUppercase = "uppercase"
+	CharacterClassLowercase = "lowercase"
+	CharacterClassDigits    = "digits"
+	CharacterClassSymbols   = "symbols"
+)
+
+// GenerateRequest represents the request body for password generation. Every
+// field is optional; the longest length is configured, so it is enforced by
+// the generator rather than the binding.
+type GenerateRequest struct {
+	// Length is the number of characters, by default the configured length
+	Length int `json:"length,omitempty" binding:"omitempty,min=8"`
+	// Classes are the character classes to draw from, each of which is used
+	// at least once; all of them by default
+	Classes []string `json:"classes,omitempty" binding:"omitempty,dive,oneof=uppercase lowercase digits symbols"`
+	// AllowAmbiguous also draws look-alike characters such as O and 0
+	AllowAmbiguous bool `json:"allow_ambiguous,omitempty"`
+	// Pronounceable alternates consonants and vowels, which is easier to read
+	// out and type but less random per character
+	Pronounceable bool `json:"pronounceable,omitempty"`
+}
+
+// GenerateResponse represents a generated password with its strength
+type GenerateResponse struct {
+	Password string           `json:"password"`
+	Strength PasswordStrength `json:"strength"`
+	Score    int              `json:"score"`
+}
+
+// Capitalization styles of generated passphrases
+const (
+	CapitalizationLower  = "lower"
+	CapitalizationTitle  = "title"
+	CapitalizationUpper  = "upper"
+	CapitalizationRandom = "random"
+)
+
+// PassphraseRequest represents the request body for passphrase generation.
+// Every field is optional; the separator is a pointer so that words can be
+// joined without one.
+type PassphraseRequest struct {
+	// Words is the number of words, by default the configured number
+	Words int `json:"words,omitempty" binding:"omitempty,min=4,max=20"`
+	// Separator joins the words, "-" by default
+	Separator *string `json:"separator,omitempty" binding:"omitempty,max=3"`
+	// Capitalization is lower (the default), title, upper, or random, which
+	// capitalizes each word or not with even odds
+	Capitalization string `json:"capitalization,omitempty" binding:"omitempty,oneof=lower title upper random"`
+}
+
+// PassphraseResponse represents a generated passphrase with its strength.
+// Entropy is the bits a guesser knowing the wordlist and the options has to
+// search, which measures a passphrase better than the score: the checker
+// sees dictionary words.
+type PassphraseResponse struct {
+	Passphrase string           `json:"passphrase"`
+	Entropy    float64          `json:"entropy_bits"`
+	Strength   PasswordStrength `json:"strength"`
+	Score      int              `json:"score"`
 }
 
+// PasswordRandomness estimates whether a password was machine-generated
+type PasswordRandomness = strength.Randomness
+
+// PasswordEstimate estimates the guesses and time needed to crack a password
+type PasswordEstimate = strength.Estimate
+
 // BreachInfo represents data about password breaches
 type BreachInfo struct {
 	Found        bool   `json:"found"`
@@ -43,13 +140,52 @@ type BreachInfo struct {
 	LastBreached string `json:"last_breached,omitempty"`
 }
 
+// BreachAuditRequest represents the request body for a bulk SHA-1 breach audit
+type BreachAuditRequest struct {
+	Hashes []string `json:"hashes" binding:"required,min=1,max=1000"`
+}
+
+// BreachAuditResult represents the breach status of a single audited hash
+type BreachAuditResult struct {
+	Hash        string `json:"hash"`
+	Found       bool   `json:"found"`
+	BreachCount int    `json:"breach_count"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BreachAuditSummary summarizes the results of a bulk breach audit
+type BreachAuditSummary struct {
+	Total    int `json:"total"`
+	Breached int `json:"breached"`
+	Errors   int `json:"errors"`
+}
+
+// BreachAuditResponse represents the response body for a bulk SHA-1 breach audit
+type BreachAuditResponse struct {
+	Results []BreachAuditResult `json:"results"`
+	Summary BreachAuditSummary  `json:"summary"`
+}
+
 // PasswordResponse represents the response body for password strength check
 type PasswordResponse struct {
 	Strength     PasswordStrength    `json:"strength"`
 	Score        int                 `json:"score"`
 	Feedback     PasswordFeedback    `json:"feedback"`
 	Requirements PasswordRequirements `json:"requirements"`
+	Randomness   PasswordRandomness  `json:"randomness"`
+	Estimate     PasswordEstimate    `json:"estimate"`
 	BreachData   *BreachInfo         `json:"breach_data,omitempty"`
+	Attestation  *AttestationToken   `json:"attestation,omitempty"`
+	Policy       *PolicyResult       `json:"policy,omitempty"`
+	Explain      []ExplainStep       `json:"explain,omitempty"`
+}
+
+// AttestationToken is a signed token attesting the result of a strength check,
+// which other services can verify offline
+type AttestationToken struct {
+	Token     string    `json:"token"`
+	KeyID     string    `json:"key_id"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // PasswordStrengthChecker defines the interface for password strength checking
@@ -62,111 +198,22 @@ type PasswordValidator interface {
 	Validate(password string) error
 }
 
-// passwordValidator implements PasswordValidator
-type passwordValidator struct{}
-
 // NewPasswordValidator creates a new password validator
 func NewPasswordValidator() PasswordValidator {
-	return &passwordValidator{}
-}
-
-// Validate validates a password according to basic requirements
-func (v *passwordValidator) Validate(password string) error {
-	if len(password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters long")
-	}
-
-	if len(password) > 128 {
-		return fmt.Errorf("password must not exceed 128 characters")
-	}
-
-	hasUpper := false
-	hasLower := false
-	hasNumber := false
-	hasSpecial := false
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsDigit(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
-		}
-	}
-
-	if !hasUpper || !hasLower || !hasNumber || !hasSpecial {
-		return fmt.Errorf("password must contain at least one uppercase letter, one lowercase letter, one number, and one special character")
-	}
-
-	return nil
+	return strength.NewValidator()
 }
 
 // GetPasswordRequirements checks which basic requirements are met
 func GetPasswordRequirements(password string) PasswordRequirements {
-	reqs := PasswordRequirements{
-		Length:     len(password) >= 8,
-		Uppercase:  false,
-		Lowercase:  false,
-		Numbers:    false,
-		SpecialChars: false,
-	}
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			reqs.Uppercase = true
-		case unicode.IsLower(char):
-			reqs.Lowercase = true
-		case unicode.IsDigit(char):
-			reqs.Numbers = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			reqs.SpecialChars = true
-		}
-	}
-
-	return reqs
+	return strength.CheckRequirements(password)
 }
 
 // GetStrengthCategory determines the strength category based on score
 func GetStrengthCategory(score int) PasswordStrength {
-	switch {
-	case score < 40:
-		return StrengthWeak
-	case score < 60:
-		return StrengthMedium
-	case score < 80:
-		return StrengthStrong
-	default:
-		return StrengthVeryStrong
-	}
+	return strength.Category(score)
 }
 
 // HasCommonPattern checks if password contains common patterns
 func HasCommonPattern(password string) bool {
-	lowerPassword := strings.ToLower(password)
-	
-	// Common keyboard patterns
-	keyboardPatterns := []string{
-		"qwerty", "asdf", "zxcv", "123456", "abcdef",
-		"password", "admin", "welcome", "login",
-	}
-	
-	for _, pattern := range keyboardPatterns {
-		if strings.Contains(lowerPassword, pattern) {
-			return true
-		}
-	}
-	
-	// Repeated characters
-	for i := 0; i < len(password)-2; i++ {
-		if password[i] == password[i+1] && password[i] == password[i+2] {
-			return true
-		}
-	}
-	
-	return false
-}
\ No newline at end of file
+	return strength.HasCommonPattern(password)
+}
@@ -0,0 +1,20 @@
+package models
+
+// RulePluginRequest is sent to a rule plugin for each password check
+type RulePluginRequest struct {
+	Password string           `json:"password"`
+	Strength PasswordStrength `json:"strength"`
+	Score    int              `json:"score"`
+}
+
+// RulePluginVerdict is a rule plugin's contribution to a password check. To
+// reject a password, a plugin caps its strength at weak and explains why in
+// Warnings.
+type RulePluginVerdict struct {
+	// ScoreAdjustment is added to the score, which stays within 0-100
+	ScoreAdjustment int `json:"score_adjustment"`
+	// MaxStrength caps the strength of the password; empty leaves it alone
+	MaxStrength PasswordStrength `json:"max_strength,omitempty"`
+	Warnings    []string         `json:"warnings,omitempty"`
+	Suggestions []string         `json:"suggestions,omitempty"`
+}
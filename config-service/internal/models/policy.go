@@ -0,0 +1,119 @@
+package models
+
+import "time"
+
+// DefaultPolicyID is the identifier of the built-in password policy
+const DefaultPolicyID = "default"
+
+// Sources of password policies
+const (
+	// PolicySourceBuiltin is the default policy enforced without a policy_id
+	PolicySourceBuiltin = "builtin"
+	// PolicySourceConfig marks policies defined in password.policies, which
+	// the admin API cannot change
+	PolicySourceConfig = "config"
+	// PolicySourceAPI marks policies defined through the admin API
+	PolicySourceAPI = "api"
+)
+
+// PasswordPolicy describes the rules a password is evaluated against
+type PasswordPolicy struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	MinLength        int    `json:"min_length"`
+	MaxLength        int    `json:"max_length"`
+	RequireUppercase bool   `json:"require_uppercase"`
+	RequireLowercase bool   `json:"require_lowercase"`
+	RequireNumbers   bool   `json:"require_numbers"`
+	RequireSpecial   bool   `json:"require_special"`
+	RequireGenerated bool   `json:"require_generated"`
+	// BannedWords are lowercase words passwords must not contain, in
+	// addition to the global banned word list
+	BannedWords []string `json:"banned_words,omitempty"`
+	// MaxBreachCount is how often a password may have been seen in known
+	// breaches; breaches are not checked when it is nil
+	MaxBreachCount *int `json:"max_breach_count,omitempty"`
+	// MinEntropyBits is the fewest bits a password must have under a model
+	// of human-chosen passwords; 0 does not check entropy
+	MinEntropyBits int       `json:"min_entropy_bits,omitempty"`
+	Source         string    `json:"source"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DeletedPasswordPolicy is a policy deleted through the admin API, which can
+// be restored until it is purged
+type DeletedPasswordPolicy struct {
+	PasswordPolicy
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// DefaultPasswordPolicy returns the built-in policy enforced by the password validator
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		ID:               DefaultPolicyID,
+		Name:             "Default",
+		MinLength:        8,
+		MaxLength:        128,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumbers:   true,
+		RequireSpecial:   true,
+		Source:           PolicySourceBuiltin,
+	}
+}
+
+// PasswordPolicyRequest represents the request body for defining a named
+// policy. A zero min_length is 8 and a zero max_length the longest password
+// the service accepts, which also bounds max_length.
+type PasswordPolicyRequest struct {
+	Name             string   `json:"name" binding:"max=100"`
+	MinLength        int      `json:"min_length" binding:"omitempty,min=8"`
+	MaxLength        int      `json:"max_length" binding:"omitempty,min=8"`
+	RequireUppercase bool     `json:"require_uppercase"`
+	RequireLowercase bool     `json:"require_lowercase"`
+	RequireNumbers   bool     `json:"require_numbers"`
+	RequireSpecial   bool     `json:"require_special"`
+	RequireGenerated bool     `json:"require_generated"`
+	BannedWords      []string `json:"banned_words" binding:"max=1000,dive,min=1,max=100"`
+	MaxBreachCount   *int     `json:"max_breach_count" binding:"omitempty,min=0"`
+	MinEntropyBits   int      `json:"min_entropy_bits" binding:"min=0,max=1024"`
+}
+
+// Policy returns the policy the request defines
+func (r PasswordPolicyRequest) Policy(id string) PasswordPolicy {
+	return PasswordPolicy{
+		ID:               id,
+		Name:             r.Name,
+		MinLength:        r.MinLength,
+		MaxLength:        r.MaxLength,
+		RequireUppercase: r.RequireUppercase,
+		RequireLowercase: r.RequireLowercase,
+		RequireNumbers:   r.RequireNumbers,
+		RequireSpecial:   r.RequireSpecial,
+		RequireGenerated: r.RequireGenerated,
+		BannedWords:      r.BannedWords,
+		MaxBreachCount:   r.MaxBreachCount,
+		MinEntropyBits:   r.MinEntropyBits,
+	}
+}
+
+// PolicyResult is the outcome of evaluating a password against a policy
+// named in a strength check
+type PolicyResult struct {
+	PolicyID    string        `json:"policy_id"`
+	Valid       bool          `json:"valid"`
+	Checks      []PolicyCheck `json:"checks"`
+	EntropyBits float64       `json:"entropy_bits"`
+	Warnings    []string      `json:"warnings,omitempty"`
+
+	// Breach is the breach lookup of the max_breach_count rule, which the
+	// check reuses instead of looking the password up again
+	Breach *BreachInfo `json:"-"`
+}
+
+// Failed returns the checks that did not pass
+func (r *PolicyResult) Failed() []PolicyCheck {
+	return failedChecks(r.Checks)
+}
@@ -0,0 +1,18 @@
+package models
+
+// QueueCheckRequest is a password check request read from a message queue
+type QueueCheckRequest struct {
+	// ID is echoed in the result so the producer can correlate it
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	// KeyboardLayout is a layout name or locale, as in the HTTP API
+	KeyboardLayout string `json:"keyboard_layout,omitempty"`
+}
+
+// QueueCheckResult is the result published for a QueueCheckRequest. Exactly
+// one of Result and Error is set.
+type QueueCheckResult struct {
+	ID     string            `json:"id"`
+	Result *PasswordResponse `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
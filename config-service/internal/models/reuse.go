@@ -0,0 +1,20 @@
+package models
+
+// ReuseRequest records the password of an account in the tenant of the
+// caller. The client computes the digest with a key the service never sees,
+// so the service learns which accounts share a password but not the password.
+type ReuseRequest struct {
+	Account string `json:"account" binding:"required,max=256"`
+	// Digest is the hex HMAC-SHA256 of the normalized password under the
+	// tenant's key
+	Digest string `json:"digest" binding:"required,len=64,hexadecimal"`
+}
+
+// ReuseResult reports whether the password of an account is shared with
+// other accounts of the tenant. Accounts counts the accounts sharing it,
+// including this one.
+type ReuseResult struct {
+	Account  string `json:"account"`
+	Reused   bool   `json:"reused"`
+	Accounts int    `json:"accounts"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// HashRecheckReport is the report written by a scheduled re-check of stored
+// password hashes. Entries are identified by the ID given in the hash file,
+// never by their hash.
+type HashRecheckReport struct {
+	CheckedAt time.Time           `json:"checked_at"`
+	Checked   int                 `json:"checked"`
+	Breached  int                 `json:"breached"`
+	Failed    int                 `json:"failed"`
+	Breaches  []HashRecheckResult `json:"breaches"`
+	Errors    []HashRecheckResult `json:"errors,omitempty"`
+}
+
+// HashRecheckResult is a breached or failed entry of a HashRecheckReport
+type HashRecheckResult struct {
+	ID          string `json:"id"`
+	BreachCount int    `json:"breach_count,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
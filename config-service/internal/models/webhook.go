@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types
+const (
+	// WebhookEventBreachFound is sent when a checked password was found in a breach
+	WebhookEventBreachFound = "breach-found"
+
+	// WebhookEventPolicyViolation is sent when a checked password violates the policy
+	WebhookEventPolicyViolation = "policy-violation"
+
+	// WebhookEventQuotaExceeded is sent when a request is rejected over the monthly quota
+	WebhookEventQuotaExceeded = "quota-exceeded"
+
+	// WebhookEventDecoyMatched is sent when a checked password is a decoy
+	// planted as a canary credential
+	WebhookEventDecoyMatched = "decoy-matched"
+)
+
+// SeverityCritical marks events that call for immediate investigation
+const SeverityCritical = "critical"
+
+// WebhookEventTypes lists the event types subscriptions may select
+var WebhookEventTypes = []string{
+	WebhookEventBreachFound,
+	WebhookEventPolicyViolation,
+	WebhookEventQuotaExceeded,
+	WebhookEventDecoyMatched,
+}
+
+// WebhookSubscriptionRequest represents the request body for registering a webhook
+type WebhookSubscriptionRequest struct {
+	URL string `json:"url" binding:"required,url,max=2048"`
+	// Secret signs the deliveries; one is generated when it is empty
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=256"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=breach-found policy-violation quota-exceeded decoy-matched"`
+	// Tenant limits deliveries to events of one tenant
+	Tenant string `json:"tenant" binding:"max=100"`
+}
+
+// WebhookSubscription represents a registered webhook. The signing secret is
+// only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Tenant string   `json:"tenant,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+	// Circuit is the state of the webhook's circuit breaker: closed,
+	// open while the webhook is not called, or half_open during a trial
+	Circuit   string    `json:"circuit,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the subscription receives an event
+func (s *WebhookSubscription) Subscribes(event *WebhookEvent) bool {
+	if s.Tenant != "" && s.Tenant != event.Tenant {
+		return false
+	}
+	for _, eventType := range s.Events {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the body of a webhook delivery. It never contains passwords.
+type WebhookEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Tenant     string          `json:"tenant,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Route      string          `json:"route,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+// DecoyMatch is the data of a decoy-matched event. The decoy is named by its
+// label, never by its password.
+type DecoyMatch struct {
+	Decoy    string `json:"decoy"`
+	Severity string `json:"severity"`
+}
+
+// WebhookDeadLetter is a delivery that failed after all attempts
+type WebhookDeadLetter struct {
+	ID             string       `json:"id"`
+	SubscriptionID string       `json:"subscription_id"`
+	URL            string       `json:"url"`
+	Event          WebhookEvent `json:"event"`
+	Attempts       int          `json:"attempts"`
+	LastStatus     int          `json:"last_status,omitempty"`
+	LastError      string       `json:"last_error"`
+	FailedAt       time.Time    `json:"failed_at"`
+}
+
+// Results of webhook delivery attempts
+const (
+	WebhookAttemptDelivered = "delivered"
+	WebhookAttemptFailed    = "failed"
+	// WebhookAttemptCircuitOpen is an attempt that failed without calling the
+	// webhook because its circuit breaker was open
+	WebhookAttemptCircuitOpen = "circuit_open"
+)
+
+// WebhookDeliveryAttempt is an entry of the delivery log of a webhook
+type WebhookDeliveryAttempt struct {
+	EventID     string    `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	Attempt     int       `json:"attempt"`
+	Result      string    `json:"result"`
+	Status      int       `json:"status,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
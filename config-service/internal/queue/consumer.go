@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Handler processes the data of a request message and returns the reply
+type Handler func(ctx context.Context, data []byte) []byte
+
+// Consumer reads requests from a topic, processes them with a pool of workers,
+// and publishes each reply to the reply topic of its request, or to the
+// consumer's reply topic when the request has none
+type Consumer struct {
+	logger       *logrus.Logger
+	open         func() (Broker, error)
+	handler      Handler
+	requestTopic string
+	replyTopic   string
+	group        string
+	workers      int
+	retryDelay   time.Duration
+}
+
+// ConsumerOption configures a Consumer
+type ConsumerOption func(*Consumer)
+
+// WithTopics sets the topic requests are read from and the default reply topic
+func WithTopics(request, reply string) ConsumerOption {
+	return func(c *Consumer) {
+		c.requestTopic = request
+		c.replyTopic = reply
+	}
+}
+
+// WithGroup sets the consumer group that instances share requests within
+func WithGroup(group string) ConsumerOption {
+	return func(c *Consumer) {
+		c.group = group
+	}
+}
+
+// WithWorkers sets how many requests are processed concurrently
+func WithWorkers(workers int) ConsumerOption {
+	return func(c *Consumer) {
+		if workers > 0 {
+			c.workers = workers
+		}
+	}
+}
+
+// WithRetryDelay sets the delay before reconnecting after the broker
+// connection fails
+func WithRetryDelay(delay time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.retryDelay = delay
+	}
+}
+
+// NewConsumer creates a consumer that connects to its broker with open
+func NewConsumer(logger *logrus.Logger, open func() (Broker, error), handler Handler, options ...ConsumerOption) *Consumer {
+	c := &Consumer{
+		logger:       logger,
+		open:         open,
+		handler:      handler,
+		requestTopic: "passwords.check",
+		replyTopic:   "passwords.results",
+		workers:      4,
+		retryDelay:   5 * time.Second,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Run consumes requests until the context is done, reconnecting when the
+// broker connection fails. It returns once the requests being processed are
+// finished.
+func (c *Consumer) Run(ctx context.Context) {
+	for {
+		broker, err := c.open()
+		if err == nil {
+			c.logger.WithField("topic", c.requestTopic).Info("Consuming password check requests")
+			err = c.consume(ctx, broker)
+			broker.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		c.logger.WithError(err).Errorf("Queue consumer disconnected; reconnecting in %s", c.retryDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.retryDelay):
+		}
+	}
+}
+
+// consume processes requests from one broker connection until it fails or
+// the context is done
+func (c *Consumer) consume(ctx context.Context, broker Broker) error {
+	jobs := make(chan Message)
+	stopped := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case message := <-jobs:
+					c.process(ctx, broker, message)
+				case <-stopped:
+					return
+				}
+			}
+		}()
+	}
+
+	// Delivery blocks while every worker is busy, which holds back the broker.
+	// A message delivered as the subscription ends is dropped, as it would be
+	// by a disconnect; its producer retries or times out.
+	err := broker.Subscribe(ctx, c.requestTopic, c.group, func(message Message) {
+		select {
+		case jobs <- message:
+		case <-stopped:
+		}
+	})
+	close(stopped)
+	wg.Wait()
+	return err
+}
+
+// process handles one request and publishes its reply
+func (c *Consumer) process(ctx context.Context, broker Broker, message Message) {
+	reply := c.handler(ctx, message.Data)
+	topic := message.Reply
+	if topic == "" {
+		topic = c.replyTopic
+	}
+	if topic == "" {
+		return
+	}
+	if err := broker.Publish(ctx, topic, reply); err != nil {
+		c.logger.WithError(err).WithField("topic", topic).Error("Failed to publish password check result")
+	}
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+func init() {
+	RegisterPublisher("nats", OpenJetStream)
+}
+
+// jetStreamPublisher publishes to the JetStream streams capturing its topics
+type jetStreamPublisher struct {
+	broker *natsBroker
+	js     nats.JetStreamContext
+	opts   PublisherOptions
+}
+
+// OpenJetStream connects a publisher to a NATS server with JetStream, at a
+// URL as described by OpenNATS. With AtLeastOnce delivery, each message waits
+// for the acknowledgement of the stream capturing its topic, and the ID of
+// the message lets the stream drop it when it is published again. With
+// AtMostOnce, messages are published without waiting.
+func OpenJetStream(rawURL string, opts PublisherOptions) (Publisher, error) {
+	broker, err := openNATS(rawURL, Options{Token: opts.Token, Name: opts.Name})
+	if err != nil {
+		return nil, err
+	}
+	js, err := broker.conn.JetStream()
+	if err != nil {
+		broker.Close()
+		return nil, fmt.Errorf("failed to use JetStream: %w", err)
+	}
+	return &jetStreamPublisher{broker: broker, js: js, opts: opts}, nil
+}
+
+// Publish publishes data to topic. NATS subjects are not partitioned, so
+// the key is not used. The ID is dropped when the server does not support
+// headers.
+func (p *jetStreamPublisher) Publish(ctx context.Context, topic, key, id string, data []byte) error {
+	message := &nats.Msg{Subject: topic, Data: data}
+	if id != "" && p.broker.conn.HeadersSupported() {
+		message.Header = nats.Header{}
+		message.Header.Set(nats.MsgIdHdr, id)
+	}
+	if p.opts.Delivery == AtMostOnce {
+		return p.broker.conn.PublishMsg(message)
+	}
+
+	// A subject no stream captures has no responders, which is reported at
+	// once rather than retried
+	ctx, cancel := context.WithTimeout(ctx, p.opts.timeout())
+	defer cancel()
+	if _, err := p.js.PublishMsg(message, nats.Context(ctx), nats.RetryAttempts(0)); err != nil {
+		if errors.Is(err, nats.ErrNoStreamResponse) {
+			return fmt.Errorf("no JetStream stream captures %s", topic)
+		}
+		var rejected *nats.APIError
+		if errors.As(err, &rejected) {
+			return fmt.Errorf("JetStream rejected the message for %s: %w", topic, err)
+		}
+		return fmt.Errorf("no JetStream acknowledgement for %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (p *jetStreamPublisher) Close() error {
+	return p.broker.Close()
+}
@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaDefaultPort is the client port of Kafka brokers
+const kafkaDefaultPort = "9092"
+
+// kafkaIDHeader is the record header holding the ID of a message, which
+// consumers can use to drop one that was published again
+const kafkaIDHeader = "id"
+
+func init() {
+	RegisterPublisher("kafka", OpenKafka)
+}
+
+// kafkaPublisher publishes with a kafka-go writer, which sends each message
+// to the leader of its partition, chosen from its key as Kafka's default
+// partitioner does
+type kafkaPublisher struct {
+	transport *kafka.Transport
+	writer    *kafka.Writer
+	timeout   time.Duration
+}
+
+// OpenKafka creates a publisher for the Kafka brokers of a URL of the form
+// kafka://[user:pass@]host[:port][,host[:port]...], or kafka+tls:// to
+// require TLS. The hosts are bootstrap brokers; the others of the cluster are
+// discovered. Credentials authenticate with the SASL mechanism of opts
+// (PLAIN, SCRAM-SHA-256, or SCRAM-SHA-512; PLAIN by default). With
+// AtLeastOnce delivery, each message waits until every in-sync replica
+// stored it; with AtMostOnce, brokers do not confirm messages.
+func OpenKafka(rawURL string, opts PublisherOptions) (Publisher, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kafka URL: %w", err)
+	}
+	if parsed.Scheme != "kafka" && parsed.Scheme != "kafka+tls" {
+		return nil, fmt.Errorf("invalid Kafka URL scheme %q (must be kafka or kafka+tls)", parsed.Scheme)
+	}
+
+	var bootstrap []string
+	for _, host := range strings.Split(parsed.Host, ",") {
+		if host == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, kafkaDefaultPort)
+		}
+		bootstrap = append(bootstrap, host)
+	}
+	if len(bootstrap) == 0 {
+		return nil, errors.New("invalid Kafka URL: no brokers")
+	}
+
+	transport := &kafka.Transport{
+		DialTimeout: opts.timeout(),
+		ClientID:    opts.Name,
+	}
+	if parsed.Scheme == "kafka+tls" {
+		// The server name of each broker is set as it is dialed
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if parsed.User != nil {
+		pass, _ := parsed.User.Password()
+		if transport.SASL, err = saslMechanism(opts.Mechanism, parsed.User.Username(), pass); err != nil {
+			return nil, err
+		}
+	}
+
+	acks := kafka.RequireAll
+	if opts.Delivery == AtMostOnce {
+		acks = kafka.RequireNone
+	}
+	addr := kafka.TCP(bootstrap...)
+	p := &kafkaPublisher{
+		transport: transport,
+		timeout:   opts.timeout(),
+		writer: &kafka.Writer{
+			Addr:      addr,
+			Balancer:  &kafka.Murmur2Balancer{},
+			Transport: transport,
+			// Messages are sent as they are published, and a failed message
+			// is left for the caller to retry
+			BatchSize:    1,
+			MaxAttempts:  1,
+			RequiredAcks: acks,
+			// Topics are created if the brokers create topics automatically
+			AllowAutoTopicCreation: true,
+			ReadTimeout:            opts.timeout(),
+			WriteTimeout:           opts.timeout(),
+		},
+	}
+
+	// Connect to the cluster once, so that a wrong URL or credentials are
+	// reported when the publisher is opened
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout())
+	defer cancel()
+	client := &kafka.Client{Addr: addr, Transport: transport, Timeout: opts.timeout()}
+	if _, err := client.Metadata(ctx, &kafka.MetadataRequest{}); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to load Kafka metadata: %w", err)
+	}
+	return p, nil
+}
+
+// Publish sends data to the leader of the partition of key, with id in a header
+func (p *kafkaPublisher) Publish(ctx context.Context, topic, key, id string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	message := kafka.Message{Topic: topic, Value: data}
+	if key != "" {
+		message.Key = []byte(key)
+	}
+	if id != "" {
+		message.Headers = []kafka.Header{{Key: kafkaIDHeader, Value: []byte(id)}}
+	}
+
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		// The writer reports the error of each message of the call separately
+		var messageErrs kafka.WriteErrors
+		if errors.As(err, &messageErrs) && len(messageErrs) == 1 && messageErrs[0] != nil {
+			err = messageErrs[0]
+		}
+		return fmt.Errorf("failed to publish to Kafka topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Close flushes the writer and closes the connections to the brokers
+func (p *kafkaPublisher) Close() error {
+	err := p.writer.Close()
+	p.transport.CloseIdleConnections()
+	return err
+}
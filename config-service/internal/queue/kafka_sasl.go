@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SASL mechanisms Kafka publishers authenticate with
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// SASLMechanisms lists the supported SASL mechanisms
+var SASLMechanisms = []string{SASLPlain, SASLScramSHA256, SASLScramSHA512}
+
+// ValidateSASLMechanism reports whether mechanism is supported
+func ValidateSASLMechanism(mechanism string) error {
+	for _, supported := range SASLMechanisms {
+		if mechanism == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported SASL mechanism %q (must be %s)", mechanism, strings.Join(SASLMechanisms, ", "))
+}
+
+// saslMechanism returns the SASL mechanism authenticating user with pass,
+// PLAIN when mechanism is empty
+func saslMechanism(mechanism, user, pass string) (sasl.Mechanism, error) {
+	if mechanism == "" {
+		mechanism = SASLPlain
+	}
+	if err := ValidateSASLMechanism(mechanism); err != nil {
+		return nil, err
+	}
+
+	switch mechanism {
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, user, pass)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, user, pass)
+	default:
+		return plain.Mechanism{Username: user, Password: pass}, nil
+	}
+}
@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsDialTimeout bounds connecting and the handshake
+const natsDialTimeout = 10 * time.Second
+
+func init() {
+	Register("nats", OpenNATS)
+}
+
+// natsBroker is a client of the NATS core protocol. It does not reconnect: when
+// the connection is lost, Subscribe returns the error so the process can be
+// restarted by its supervisor.
+type natsBroker struct {
+	conn *nats.Conn
+
+	// done is closed with the connection, and err is why it was lost
+	done chan struct{}
+	mu   sync.Mutex
+	err  error
+}
+
+// OpenNATS connects to a NATS server. url is nats://[user:pass@]host[:port],
+// or tls:// to require TLS; a token in opts is sent as the auth token.
+func OpenNATS(rawURL string, opts Options) (Broker, error) {
+	return openNATS(rawURL, opts)
+}
+
+// openNATS connects to a NATS server, as described by OpenNATS
+func openNATS(rawURL string, opts Options) (*natsBroker, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if parsed.Scheme != "nats" && parsed.Scheme != "tls" {
+		return nil, fmt.Errorf("invalid NATS URL scheme %q (must be nats or tls)", parsed.Scheme)
+	}
+
+	b := &natsBroker{done: make(chan struct{})}
+	options := []nats.Option{
+		nats.Name(opts.Name),
+		nats.Timeout(natsDialTimeout),
+		nats.NoReconnect(),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				b.fail(fmt.Errorf("NATS connection lost: %w", err))
+			}
+		}),
+		nats.ClosedHandler(func(*nats.Conn) {
+			b.fail(errors.New("NATS connection is closed"))
+			close(b.done)
+		}),
+	}
+	if opts.Token != "" {
+		options = append(options, nats.Token(opts.Token))
+	}
+
+	// Credentials in the URL are sent by the client
+	if b.conn, err = nats.Connect(rawURL, options...); err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return b, nil
+}
+
+// fail records why the connection was lost, keeping the first reason
+func (b *natsBroker) fail(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Subscribe subscribes to topic, in the queue group if group is set
+func (b *natsBroker) Subscribe(ctx context.Context, topic, group string, handler func(Message)) error {
+	deliver := func(m *nats.Msg) {
+		message := Message{Topic: m.Subject, Reply: m.Reply, Data: m.Data}
+		if len(m.Header) > 0 {
+			message.Header = make(map[string]string, len(m.Header))
+			for name := range m.Header {
+				message.Header[name] = m.Header.Get(name)
+			}
+		}
+		handler(message)
+	}
+
+	var sub *nats.Subscription
+	var err error
+	if group != "" {
+		sub, err = b.conn.QueueSubscribe(topic, group, deliver)
+	} else {
+		sub, err = b.conn.Subscribe(topic, deliver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		sub.Unsubscribe()
+		return ctx.Err()
+	case <-b.done:
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.err
+	}
+}
+
+// Publish publishes data to topic
+func (b *natsBroker) Publish(ctx context.Context, topic string, data []byte) error {
+	return b.conn.Publish(topic, data)
+}
+
+// Close closes the connection
+func (b *natsBroker) Close() error {
+	b.conn.Close()
+	return nil
+}
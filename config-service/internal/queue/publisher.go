@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Delivery is the guarantee a publisher gives for each message
+type Delivery string
+
+const (
+	// AtMostOnce sends each message once without waiting for the broker to
+	// store it, so messages can be lost when a broker fails
+	AtMostOnce Delivery = "at_most_once"
+	// AtLeastOnce waits for the broker to store each message, so a message
+	// that is not confirmed can be sent again and may be stored twice
+	AtLeastOnce Delivery = "at_least_once"
+)
+
+// Publisher sends messages to a streaming platform
+type Publisher interface {
+	// Publish sends data to topic. Messages with the same key stay in order
+	// on brokers that partition topics. id identifies the message, so brokers
+	// that deduplicate can drop a message that is sent again.
+	Publish(ctx context.Context, topic, key, id string, data []byte) error
+	// Close closes the connections to the brokers
+	Close() error
+}
+
+// PublisherOptions configures a publisher
+type PublisherOptions struct {
+	// Name identifies the client to the brokers
+	Name string
+	// Token authenticates to brokers that accept one
+	Token string
+	// Mechanism is the SASL mechanism used with the credentials of the URL on
+	// brokers that support SASL
+	Mechanism string
+	// Delivery is the guarantee for each message, AtLeastOnce by default
+	Delivery Delivery
+	// Timeout bounds connecting and waiting for the broker to confirm a
+	// message, 10 seconds by default
+	Timeout time.Duration
+}
+
+// timeout returns the configured timeout or the default
+func (o PublisherOptions) timeout() time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return 10 * time.Second
+}
+
+// PublisherDriver connects a publisher to the brokers of url
+type PublisherDriver func(url string, opts PublisherOptions) (Publisher, error)
+
+var (
+	publishersMu sync.RWMutex
+	publishers   = make(map[string]PublisherDriver)
+)
+
+// RegisterPublisher makes a publisher driver available under name. NATS
+// JetStream and Kafka are built in.
+func RegisterPublisher(name string, driver PublisherDriver) {
+	publishersMu.Lock()
+	defer publishersMu.Unlock()
+	publishers[name] = driver
+}
+
+// PublisherDrivers returns the names of the registered publisher drivers, sorted
+func PublisherDrivers() []string {
+	publishersMu.RLock()
+	defer publishersMu.RUnlock()
+	names := make([]string, 0, len(publishers))
+	for name := range publishers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasPublisher reports whether a publisher driver is registered under name
+func HasPublisher(name string) bool {
+	publishersMu.RLock()
+	defer publishersMu.RUnlock()
+	_, ok := publishers[name]
+	return ok
+}
+
+// OpenPublisher connects a publisher with the named driver
+func OpenPublisher(driver, url string, opts PublisherOptions) (Publisher, error) {
+	publishersMu.RLock()
+	open, ok := publishers[driver]
+	publishersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown publisher driver %q (available: %v)", driver, PublisherDrivers())
+	}
+	if opts.Delivery == "" {
+		opts.Delivery = AtLeastOnce
+	}
+	return open(url, opts)
+}
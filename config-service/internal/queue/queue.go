@@ -0,0 +1,86 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Message is a message received from a broker. Reply is the topic a response
+// should be published to, when the sender asked for one, and Header holds the
+// headers of brokers that support them.
+type Message struct {
+	Topic  string
+	Reply  string
+	Data   []byte
+	Header map[string]string
+}
+
+// Broker is a connection to a message broker
+type Broker interface {
+	// Subscribe delivers the messages of topic to handler until the context is
+	// done or the connection fails. Subscribers sharing a group split the
+	// messages between them.
+	Subscribe(ctx context.Context, topic, group string, handler func(Message)) error
+	// Publish sends data to topic
+	Publish(ctx context.Context, topic string, data []byte) error
+	// Close closes the connection
+	Close() error
+}
+
+// Options configures a broker connection
+type Options struct {
+	// Token authenticates to the broker, if it requires one
+	Token string
+	// Name identifies the client to the broker
+	Name string
+}
+
+// Driver opens a broker connection to url
+type Driver func(url string, opts Options) (Broker, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+// Register makes a broker driver available under name. NATS is built in;
+// other brokers, such as Kafka, can be added by registering a driver from a
+// package linked into the binary.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = driver
+}
+
+// Drivers returns the names of the registered drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasDriver reports whether a driver is registered under name
+func HasDriver(name string) bool {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	_, ok := drivers[name]
+	return ok
+}
+
+// Open connects to a broker with the named driver
+func Open(driver, url string, opts Options) (Broker, error) {
+	driversMu.RLock()
+	open, ok := drivers[driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown queue driver %q (available: %v)", driver, Drivers())
+	}
+	return open(url, opts)
+}
@@ -0,0 +1,6 @@
+//go:build deterministic
+
+package reproducible
+
+// deterministicBuild makes deterministic mode the default and the only mode
+const deterministicBuild = true
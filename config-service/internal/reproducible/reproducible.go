@@ -0,0 +1,124 @@
+// Package reproducible is the source of time and randomness for the service's
+// request IDs, cache expiry, scheduling jitter, fault injection, and password
+// generation. It normally reads the system clock and crypto/rand. In
+// deterministic mode, enabled by the "deterministic" build tag or by
+// configuration, the clock starts at a fixed epoch and advances by a fixed
+// step on every reading, and random values come from a seeded generator, so
+// that test runs and replays of an incident produce the same IDs, expiries,
+// and jitter every time.
+package reproducible
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Epoch is the first reading of the deterministic clock
+var Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Step is how far the deterministic clock advances per reading
+const Step = time.Millisecond
+
+// source is the clock and random generator in use
+type source struct {
+	mu            sync.Mutex
+	deterministic bool
+	now           time.Time
+	rand          *rand.Rand
+}
+
+var current = &source{}
+
+func init() {
+	if deterministicBuild {
+		UseDeterministic(1)
+	}
+}
+
+// UseDeterministic switches the process to the deterministic clock, restarted
+// at Epoch, and a random generator seeded with seed
+func UseDeterministic(seed int64) {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	current.deterministic = true
+	current.now = Epoch
+	current.rand = rand.New(rand.NewSource(seed))
+}
+
+// UseSystem switches the process back to the system clock and crypto/rand,
+// unless the binary was built with the "deterministic" tag
+func UseSystem() {
+	if deterministicBuild {
+		UseDeterministic(1)
+		return
+	}
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	current.deterministic = false
+	current.rand = nil
+}
+
+// Deterministic reports whether the deterministic clock and generator are in use
+func Deterministic() bool {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	return current.deterministic
+}
+
+// Now returns the current time
+func Now() time.Time {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	if !current.deterministic {
+		return time.Now()
+	}
+	now := current.now
+	current.now = current.now.Add(Step)
+	return now
+}
+
+// Read fills p with random bytes, which are only suitable for secrets outside
+// deterministic mode
+func Read(p []byte) {
+	current.mu.Lock()
+	defer current.mu.Unlock()
+	if current.deterministic {
+		current.rand.Read(p)
+		return
+	}
+	if _, err := crand.Read(p); err != nil {
+		panic("reproducible: failed to read random bytes: " + err.Error())
+	}
+}
+
+// Reader reads random bytes with Read, e.g. for crypto/rand.Int
+var Reader io.Reader = readerFunc(func(p []byte) (int, error) {
+	Read(p)
+	return len(p), nil
+})
+
+// readerFunc adapts a function to io.Reader
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+// Float64 returns a random number in [0, 1)
+func Float64() float64 {
+	var buf [8]byte
+	Read(buf[:])
+	return float64(binary.BigEndian.Uint64(buf[:])>>11) / (1 << 53)
+}
+
+// NewRand returns a math/rand generator seeded from the source, for
+// components that draw many values under their own lock
+func NewRand() *rand.Rand {
+	var buf [8]byte
+	Read(buf[:])
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(buf[:]))))
+}
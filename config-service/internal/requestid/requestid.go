@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"config-service/internal/reproducible"
+)
+
+// Header is the HTTP header carrying the request ID
+const Header = "X-Request-ID"
+
+// maxLength is the longest inbound request ID that is accepted
+const maxLength = 128
+
+// validID matches inbound request IDs that are safe to log and propagate
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// contextKey is the type of the context key holding the request ID
+type contextKey struct{}
+
+// Generate returns a new random (version 4) UUID
+func Generate() string {
+	var uuid [16]byte
+	reproducible.Read(uuid[:])
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+// IsValid reports whether an inbound request ID can be reused
+func IsValid(id string) bool {
+	return len(id) > 0 && len(id) <= maxLength && validID.MatchString(id)
+}
+
+// WithContext returns a copy of the context carrying the request ID
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by the context, if any
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,21 @@
+package scheduler
+
+// Job types that can be configured in scheduler.jobs
+const (
+	// JobDatasetRefresh reloads the banned words of a word list file
+	JobDatasetRefresh = "dataset_refresh"
+	// JobCacheSnapshot saves the breach cache to a file, which is loaded again
+	// on startup
+	JobCacheSnapshot = "cache_snapshot"
+	// JobHashRecheck checks a file of stored password hashes against known
+	// breaches and writes a report
+	JobHashRecheck = "hash_recheck"
+	// JobUsageReport writes the usage report of the previous month
+	JobUsageReport = "usage_report"
+	// JobCalibrationReport scores a labeled corpus of cracked and uncracked
+	// passwords and writes a calibration report
+	JobCalibrationReport = "calibration_report"
+)
+
+// JobTypes lists the configurable job types
+var JobTypes = []string{JobDatasetRefresh, JobCacheSnapshot, JobHashRecheck, JobUsageReport, JobCalibrationReport}
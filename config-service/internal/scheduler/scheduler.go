@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/metrics"
+	"config-service/internal/reproducible"
+)
+
+// Job results reported in metrics
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultSkipped = "skipped"
+)
+
+// JobFunc is the work of a scheduled job
+type JobFunc func(ctx context.Context) error
+
+// Job is a job run on a schedule
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter delays each activation by a random duration up to it, so
+	// instances sharing a schedule do not all run at once
+	Jitter time.Duration
+	// RunOnStart also runs the job when the scheduler starts
+	RunOnStart bool
+	Run        JobFunc
+}
+
+// job is a registered job
+type job struct {
+	Job
+	// running is 1 while a run is in progress, accessed atomically
+	running int32
+}
+
+// Scheduler runs jobs on their schedules. A job that is still running when it
+// is due again skips that activation rather than running twice at once.
+type Scheduler struct {
+	logger   *logrus.Logger
+	recorder metrics.Recorder
+	now      func() time.Time
+	jobs     []*job
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// Option configures a Scheduler
+type Option func(*Scheduler)
+
+// WithMetrics records job runs, durations, and last successes
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(s *Scheduler) {
+		s.recorder = recorder
+	}
+}
+
+// New creates a scheduler without jobs
+func New(logger *logrus.Logger, options ...Option) *Scheduler {
+	s := &Scheduler{
+		logger:   logger,
+		recorder: metrics.Noop{},
+		now:      reproducible.Now,
+		rand:     reproducible.NewRand(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Add registers a job; job names must be unique
+func (s *Scheduler) Add(j Job) error {
+	if j.Name == "" || j.Schedule == nil || j.Run == nil {
+		return fmt.Errorf("scheduled job needs a name, schedule, and function")
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == j.Name {
+			return fmt.Errorf("duplicate scheduled job %q", j.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &job{Job: j})
+	return nil
+}
+
+// Run runs the jobs until the context is done, then waits for the runs in
+// progress to return
+func (s *Scheduler) Run(ctx context.Context) {
+	var loops, runs sync.WaitGroup
+	for _, j := range s.jobs {
+		loops.Add(1)
+		go func(j *job) {
+			defer loops.Done()
+			s.loop(ctx, j, &runs)
+		}(j)
+	}
+	loops.Wait()
+	runs.Wait()
+}
+
+// loop waits for each activation of a job and starts its run
+func (s *Scheduler) loop(ctx context.Context, j *job, runs *sync.WaitGroup) {
+	logger := s.logger.WithField("job", j.Name)
+	if j.RunOnStart {
+		s.start(ctx, j, runs)
+	}
+	for {
+		next := j.Schedule.Next(s.now())
+		if next.IsZero() {
+			logger.Warn("Scheduled job has no future activations")
+			return
+		}
+		next = next.Add(s.jitter(j.Jitter))
+		logger.Debugf("Scheduled job runs next at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.start(ctx, j, runs)
+	}
+}
+
+// start starts a run of a job unless the previous run is still in progress
+func (s *Scheduler) start(ctx context.Context, j *job, runs *sync.WaitGroup) {
+	if !atomic.CompareAndSwapInt32(&j.running, 0, 1) {
+		s.logger.WithField("job", j.Name).Warn("Scheduled job is still running; skipping this activation")
+		s.recorder.Count("scheduled_job_runs", 1, metrics.Tags{"job": j.Name, "result": ResultSkipped})
+		return
+	}
+	runs.Add(1)
+	go func() {
+		defer runs.Done()
+		defer atomic.StoreInt32(&j.running, 0)
+		s.execute(ctx, j)
+	}()
+}
+
+// execute runs a job once, recovering panics, and records the outcome
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	logger := s.logger.WithField("job", j.Name)
+	start := s.now()
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		return j.Run(ctx)
+	}()
+	duration := s.now().Sub(start)
+
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+		logger.WithError(err).WithField("duration", duration.String()).Error("Scheduled job failed")
+	} else {
+		logger.WithField("duration", duration.String()).Info("Scheduled job completed")
+		s.recorder.Gauge("scheduled_job_last_success", float64(s.now().Unix()), metrics.Tags{"job": j.Name})
+	}
+	s.recorder.Count("scheduled_job_runs", 1, metrics.Tags{"job": j.Name, "result": result})
+	s.recorder.Timing("scheduled_job_duration", duration, metrics.Tags{"job": j.Name})
+}
+
+// jitter returns a random delay below max
+func (s *Scheduler) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	s.randMu.Lock()
+	defer s.randMu.Unlock()
+	return time.Duration(s.rand.Int63n(int64(max)))
+}
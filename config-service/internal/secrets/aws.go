@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go"
+)
+
+// AWSSecretsManagerProvider reads secrets from AWS Secrets Manager. Paths are
+// secret names or ARNs, e.g. awssm://prod/config-service#admin_token. A secret
+// string holding a JSON object is split into its keys; any other secret string
+// is returned under the empty key.
+type AWSSecretsManagerProvider struct {
+	client *secretsmanager.Client
+}
+
+// awsSettings are the settings an AWSOption changes
+type awsSettings struct {
+	endpoint    string
+	timeout     time.Duration
+	credentials aws.CredentialsProvider
+}
+
+// AWSOption defines functional options for configuring an AWSSecretsManagerProvider
+type AWSOption func(*awsSettings)
+
+// WithAWSEndpoint overrides the regional Secrets Manager endpoint, e.g. for a VPC endpoint
+func WithAWSEndpoint(endpoint string) AWSOption {
+	return func(s *awsSettings) {
+		if endpoint != "" {
+			s.endpoint = strings.TrimRight(endpoint, "/")
+		}
+	}
+}
+
+// WithAWSTimeout sets the request timeout
+func WithAWSTimeout(timeout time.Duration) AWSOption {
+	return func(s *awsSettings) {
+		s.timeout = timeout
+	}
+}
+
+// WithAWSCredentials replaces the default credential chain
+func WithAWSCredentials(credentials aws.CredentialsProvider) AWSOption {
+	return func(s *awsSettings) {
+		s.credentials = credentials
+	}
+}
+
+// NewAWSSecretsManagerProvider creates a provider for Secrets Manager in a
+// region. Credentials come from the default chain of the AWS SDK: the
+// environment, shared config and SSO profiles, web identity tokens such as
+// IRSA, and ECS task and EC2 instance roles. Temporary credentials are
+// refreshed before they expire.
+func NewAWSSecretsManagerProvider(ctx context.Context, region string, options ...AWSOption) (*AWSSecretsManagerProvider, error) {
+	settings := awsSettings{timeout: 10 * time.Second}
+	for _, option := range options {
+		option(&settings)
+	}
+
+	loadOptions := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(region),
+		awsconfig.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(settings.timeout)),
+	}
+	if settings.credentials != nil {
+		loadOptions = append(loadOptions, awsconfig.WithCredentialsProvider(settings.credentials))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if settings.endpoint != "" {
+			o.BaseEndpoint = aws.String(settings.endpoint)
+		}
+	})
+	return &AWSSecretsManagerProvider{client: client}, nil
+}
+
+// Fetch reads the current version of the secret with the name or ARN in path
+func (p *AWSSecretsManagerProvider) Fetch(ctx context.Context, path string) (map[string]string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(path)})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("secrets manager returned %s", apiErr.ErrorCode())
+		}
+		return nil, fmt.Errorf("failed to read from secrets manager: %w", err)
+	}
+
+	secret := aws.ToString(out.SecretString)
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &data); err == nil {
+		return stringValues(data), nil
+	}
+	return map[string]string{"": secret}, nil
+}
@@ -0,0 +1,166 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reference schemes
+const (
+	SchemeVault = "vault"
+	SchemeAWS   = "awssm"
+)
+
+// Provider fetches a secret from a secret store. A secret is a set of named
+// values; a secret holding a single unnamed value returns it under the empty key.
+type Provider interface {
+	Fetch(ctx context.Context, path string) (map[string]string, error)
+}
+
+// Reference points at a value in a secret store, written as
+// scheme://path#key, e.g. vault://secret/data/config-service#admin_token or
+// awssm://prod/config-service#admin_token. The key may be omitted for secrets
+// holding a single value.
+type Reference struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+// String returns the reference in scheme://path#key form
+func (r Reference) String() string {
+	if r.Key == "" {
+		return r.Scheme + "://" + r.Path
+	}
+	return r.Scheme + "://" + r.Path + "#" + r.Key
+}
+
+// IsReference reports whether a configuration value is a secret reference
+func IsReference(value string) bool {
+	_, ok := ParseReference(value)
+	return ok
+}
+
+// ParseReference parses a secret reference, reporting false for any other value
+func ParseReference(value string) (Reference, bool) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok || (scheme != SchemeVault && scheme != SchemeAWS) {
+		return Reference{}, false
+	}
+	path, key, _ := strings.Cut(rest, "#")
+	if path == "" {
+		return Reference{}, false
+	}
+	return Reference{Scheme: scheme, Path: path, Key: key}, true
+}
+
+// cachedSecret is a fetched secret and when it was fetched
+type cachedSecret struct {
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+// Resolver resolves secret references through the provider registered for
+// their scheme. Fetched secrets are cached for a TTL, after which they are
+// fetched again so that rotated values are picked up.
+type Resolver struct {
+	providers map[string]Provider
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// ResolverOption defines functional options for configuring a Resolver
+type ResolverOption func(*Resolver)
+
+// WithProvider registers the provider for a reference scheme
+func WithProvider(scheme string, provider Provider) ResolverOption {
+	return func(r *Resolver) {
+		r.providers[scheme] = provider
+	}
+}
+
+// WithCacheTTL sets how long fetched secrets are reused; zero fetches on every resolve
+func WithCacheTTL(ttl time.Duration) ResolverOption {
+	return func(r *Resolver) {
+		r.ttl = ttl
+	}
+}
+
+// NewResolver creates a secret resolver
+func NewResolver(options ...ResolverOption) *Resolver {
+	r := &Resolver{
+		providers: make(map[string]Provider),
+		now:       time.Now,
+		cache:     make(map[string]cachedSecret),
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Resolve returns the value a reference points at. Values that are not
+// references are returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	ref, ok := ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+
+	values, err := r.fetch(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, ok := values[ref.Key]
+	if !ok {
+		if ref.Key == "" {
+			return "", fmt.Errorf("secret %s holds several values; add #key to select one (available: %s)", ref, strings.Join(sortedKeys(values), ", "))
+		}
+		return "", fmt.Errorf("secret %s has no key %q", ref, ref.Key)
+	}
+	return resolved, nil
+}
+
+// fetch returns the secret a reference points at, from the cache when fresh
+func (r *Resolver) fetch(ctx context.Context, ref Reference) (map[string]string, error) {
+	provider, ok := r.providers[ref.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("secret %s: no %s provider is configured", ref, ref.Scheme)
+	}
+
+	cacheKey := ref.Scheme + "://" + ref.Path
+	r.mu.Lock()
+	cached, ok := r.cache[cacheKey]
+	r.mu.Unlock()
+	if ok && r.now().Sub(cached.fetchedAt) < r.ttl {
+		return cached.values, nil
+	}
+
+	values, err := provider.Fetch(ctx, ref.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret %s: %w", ref, err)
+	}
+
+	r.mu.Lock()
+	r.cache[cacheKey] = cachedSecret{values: values, fetchedAt: r.now()}
+	r.mu.Unlock()
+	return values, nil
+}
+
+// sortedKeys returns the keys of a secret in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"crypto/subtle"
+	"sync"
+	"time"
+)
+
+// Value is a secret that can be rotated at runtime. After a rotation the
+// previous value is still accepted for a grace period, so clients can switch
+// to the new value without failed requests.
+type Value struct {
+	now func() time.Time
+
+	mu            sync.RWMutex
+	current       string
+	previous      string
+	previousUntil time.Time
+}
+
+// NewValue creates a rotatable secret holding value
+func NewValue(value string) *Value {
+	return &Value{current: value, now: time.Now}
+}
+
+// Get returns the current value
+func (v *Value) Get() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.current
+}
+
+// Rotate replaces the value, accepting the previous one for the grace period,
+// and reports whether the value changed
+func (v *Value) Rotate(value string, grace time.Duration) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if value == v.current {
+		return false
+	}
+	v.previous = v.current
+	v.previousUntil = v.now().Add(grace)
+	v.current = value
+	return true
+}
+
+// Matches reports, in constant time, whether candidate is the current value or
+// the previous value within its grace period. An empty secret matches nothing.
+func (v *Value) Matches(candidate string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	matched := v.current != "" && subtle.ConstantTimeCompare([]byte(candidate), []byte(v.current)) == 1
+	if v.previous != "" && v.now().Before(v.previousUntil) {
+		matched = subtle.ConstantTimeCompare([]byte(candidate), []byte(v.previous)) == 1 || matched
+	}
+	return matched
+}
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider reads secrets from HashiCorp Vault's HTTP API. Paths are API
+// paths below /v1, so a KV version 2 secret is referenced with its data path,
+// e.g. vault://secret/data/config-service#admin_token.
+type VaultProvider struct {
+	address    string
+	token      string
+	namespace  string
+	httpClient *http.Client
+}
+
+// VaultOption defines functional options for configuring a VaultProvider
+type VaultOption func(*VaultProvider)
+
+// WithVaultNamespace sends requests to a Vault Enterprise namespace
+func WithVaultNamespace(namespace string) VaultOption {
+	return func(p *VaultProvider) {
+		p.namespace = namespace
+	}
+}
+
+// WithVaultTimeout sets the request timeout
+func WithVaultTimeout(timeout time.Duration) VaultOption {
+	return func(p *VaultProvider) {
+		p.httpClient.Timeout = timeout
+	}
+}
+
+// NewVaultProvider creates a provider for the Vault server at address,
+// authenticating with token
+func NewVaultProvider(address, token string, options ...VaultOption) *VaultProvider {
+	p := &VaultProvider{
+		address:    strings.TrimRight(address, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// vaultResponse is the part of a Vault read response holding the secret.
+// KV version 1 puts the values in data; version 2 nests them in data.data.
+type vaultResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// Fetch reads the secret at path
+func (p *VaultProvider) Fetch(ctx context.Context, path string) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.address+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	return stringValues(data), nil
+}
+
+// stringValues converts decoded JSON values to strings, keeping strings as is
+// and encoding anything else as JSON
+func stringValues(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		values[key] = string(encoded)
+	}
+	return values
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// connPollInterval is how often ConnTracker.Wait checks the connections
+const connPollInterval = 5 * time.Millisecond
+
+// ConnTracker follows the connections of HTTP servers that are reading or
+// serving a request. http.Server.Shutdown drops a request read after it has
+// begun, so a server that stops accepting connections waits for these first.
+type ConnTracker struct {
+	mutex sync.Mutex
+	busy  map[net.Conn]struct{}
+}
+
+// NewConnTracker creates a tracker; set its ConnState method as the
+// http.Server.ConnState hook of the servers to follow
+func NewConnTracker() *ConnTracker {
+	return &ConnTracker{busy: make(map[net.Conn]struct{})}
+}
+
+// ConnState records a connection changing state
+func (t *ConnTracker) ConnState(conn net.Conn, state http.ConnState) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	switch state {
+	case http.StateNew, http.StateActive:
+		t.busy[conn] = struct{}{}
+	default:
+		delete(t.busy, conn)
+	}
+}
+
+// Wait returns once no connection is reading or serving a request, or when
+// the context is done
+func (t *ConnTracker) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(connPollInterval)
+	defer ticker.Stop()
+
+	for {
+		t.mutex.Lock()
+		busy := len(t.busy)
+		t.mutex.Unlock()
+		if busy == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
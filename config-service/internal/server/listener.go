@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// ListenUnix listens on a Unix domain socket at path and applies the file mode
+// (an octal string such as "0660"). A stale socket left behind by a previous
+// process is removed; any other existing file is an error.
+func ListenUnix(path, mode string) (net.Listener, error) {
+	perm, err := ParseSocketMode(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket: %w", err)
+	}
+
+	if err := os.Chmod(path, perm); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return listener, nil
+}
+
+// ParseSocketMode parses an octal socket file mode such as "0660"
+func ParseSocketMode(mode string) (os.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > 0o777 {
+		return 0, fmt.Errorf("invalid socket mode: %q (must be octal, e.g. 0660)", mode)
+	}
+	return os.FileMode(value), nil
+}
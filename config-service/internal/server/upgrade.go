@@ -0,0 +1,245 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Environment variables through which a process hands its listeners to the
+// process replacing it: the names of the listeners passed, one per line, and
+// the descriptor of the pipe telling it the new process is ready
+const (
+	listenersEnv = "CONFIG_SERVICE_LISTENERS"
+	readyFDEnv   = "CONFIG_SERVICE_READY_FD"
+)
+
+// firstInheritedFD is the descriptor of the first file passed to a new
+// process, after standard input, output, and error
+const firstInheritedFD = 3
+
+// defaultUpgradeTimeout is how long a new process may take to become ready
+const defaultUpgradeTimeout = time.Minute
+
+var (
+	// ErrUpgradeInProgress is returned when upgrading while an upgrade is
+	// running or after the listeners were handed over
+	ErrUpgradeInProgress = errors.New("an upgrade is in progress")
+
+	// ErrUpgradeUnsupported is returned when upgrading on a platform that
+	// cannot pass sockets to a new process
+	ErrUpgradeUnsupported = errors.New("upgrades are not supported on this platform")
+)
+
+// Upgrader hands the listeners of the process over to a new process started
+// from the executable, so that the binary of a single host can be upgraded
+// without refusing connections. The new process takes the listening sockets
+// over, and the old one serves until the new one is ready, then stops
+// accepting connections and finishes the requests in flight.
+type Upgrader struct {
+	logger  *logrus.Logger
+	timeout time.Duration
+	pidFile string
+	command []string
+
+	inherited map[string]*os.File
+	ready     *os.File
+
+	mutex     sync.Mutex
+	listeners []namedListener
+	upgrading bool
+	done      chan struct{}
+}
+
+// namedListener is a listener with the name identifying it across processes
+type namedListener struct {
+	name     string
+	listener net.Listener
+	closer   *onceCloseListener
+}
+
+// onceCloseListener is a listener that can be closed by both the upgrader
+// and the server using it
+type onceCloseListener struct {
+	net.Listener
+	once sync.Once
+	err  error
+}
+
+// Close closes the listener the first time and returns the same error after
+func (l *onceCloseListener) Close() error {
+	l.once.Do(func() {
+		l.err = l.Listener.Close()
+	})
+	return l.err
+}
+
+// UpgraderOption defines functional options for configuring the Upgrader
+type UpgraderOption func(*Upgrader)
+
+// WithUpgradeTimeout sets how long a new process may take to become ready
+// before it is stopped and the upgrade fails
+func WithUpgradeTimeout(timeout time.Duration) UpgraderOption {
+	return func(u *Upgrader) {
+		u.timeout = timeout
+	}
+}
+
+// WithPIDFile writes the process ID to path once the process is ready, so
+// that supervisors follow the process serving after an upgrade
+func WithPIDFile(path string) UpgraderOption {
+	return func(u *Upgrader) {
+		u.pidFile = path
+	}
+}
+
+// WithCommand starts new processes from path with args instead of the
+// command line this process was started with
+func WithCommand(path string, args ...string) UpgraderOption {
+	return func(u *Upgrader) {
+		u.command = append([]string{path}, args...)
+	}
+}
+
+// NewUpgrader creates an upgrader, taking over the listeners passed by the
+// process being replaced, if any
+func NewUpgrader(logger *logrus.Logger, options ...UpgraderOption) (*Upgrader, error) {
+	u := &Upgrader{
+		logger:    logger,
+		timeout:   defaultUpgradeTimeout,
+		command:   os.Args,
+		inherited: make(map[string]*os.File),
+		done:      make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(u)
+	}
+
+	if err := u.inherit(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// inherit opens the files passed by the process being replaced. The
+// variables naming them are cleared so that they do not leak to other
+// processes.
+func (u *Upgrader) inherit() error {
+	names, readyFD := os.Getenv(listenersEnv), os.Getenv(readyFDEnv)
+	os.Unsetenv(listenersEnv)
+	os.Unsetenv(readyFDEnv)
+
+	if names != "" {
+		for i, name := range strings.Split(names, "\n") {
+			u.inherited[name] = os.NewFile(uintptr(firstInheritedFD+i), name)
+		}
+	}
+	if readyFD != "" {
+		fd, err := strconv.Atoi(readyFD)
+		if err != nil || fd < firstInheritedFD {
+			return fmt.Errorf("invalid %s: %q", readyFDEnv, readyFD)
+		}
+		u.ready = os.NewFile(uintptr(fd), "ready")
+	}
+	return nil
+}
+
+// Listen returns the listener named name that was inherited from the process
+// being replaced, or else one created with listen. Listeners are matched by
+// name, so names should include the address.
+func (u *Upgrader) Listen(name string, listen func() (net.Listener, error)) (net.Listener, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	var listener net.Listener
+	if file, ok := u.inherited[name]; ok {
+		delete(u.inherited, name)
+		var err error
+		listener, err = net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to take over listener %s: %w", name, err)
+		}
+		u.logger.WithField("listener", name).Info("Took over listener from the previous process")
+	} else {
+		var err error
+		if listener, err = listen(); err != nil {
+			return nil, err
+		}
+	}
+
+	closer := &onceCloseListener{Listener: listener}
+	u.listeners = append(u.listeners, namedListener{name: name, listener: listener, closer: closer})
+	return closer, nil
+}
+
+// Ready writes the PID file and tells the process being replaced, if any,
+// that this one serves, so that it shuts down. Inherited listeners that are
+// no longer configured are closed.
+func (u *Upgrader) Ready() error {
+	u.mutex.Lock()
+	for name, file := range u.inherited {
+		file.Close()
+		delete(u.inherited, name)
+		u.logger.WithField("listener", name).Info("Closed inherited listener that is no longer configured")
+	}
+	ready := u.ready
+	u.ready = nil
+	u.mutex.Unlock()
+
+	if u.pidFile != "" {
+		if err := writePIDFile(u.pidFile); err != nil {
+			return err
+		}
+	}
+	if ready != nil {
+		defer ready.Close()
+		if _, err := ready.Write([]byte{1}); err != nil {
+			return fmt.Errorf("failed to notify the previous process: %w", err)
+		}
+	}
+	return nil
+}
+
+// Done is closed once the listeners are handed over to a new process that is
+// ready. The process should then stop accepting connections, finish the
+// requests in flight, and exit.
+func (u *Upgrader) Done() <-chan struct{} {
+	return u.done
+}
+
+// Close stops accepting connections on every listener. After an upgrade,
+// the new process accepts them instead.
+func (u *Upgrader) Close() error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	var err error
+	for _, l := range u.listeners {
+		if closeErr := l.closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// writePIDFile replaces the file at path with one holding the process ID
+func writePIDFile(path string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
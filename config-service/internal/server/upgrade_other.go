@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package server
+
+import "context"
+
+// Upgrade is unsupported on platforms that cannot pass sockets to a new
+// process; restart the service instead
+func (u *Upgrader) Upgrade() error {
+	return ErrUpgradeUnsupported
+}
+
+// WatchSignals is a no-op on platforms without SIGHUP
+func (u *Upgrader) WatchSignals(ctx context.Context) {}
@@ -0,0 +1,144 @@
+//go:build !windows && !plan9
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Upgrade starts a new process with the listeners and waits until it is
+// ready. This process keeps serving meanwhile, and also when the new process
+// fails to start or to become ready within the timeout. Once the upgrade
+// succeeds, Done is closed.
+func (u *Upgrader) Upgrade() error {
+	u.mutex.Lock()
+	if u.upgrading {
+		u.mutex.Unlock()
+		return ErrUpgradeInProgress
+	}
+	u.upgrading = true
+	listeners := append([]namedListener(nil), u.listeners...)
+	u.mutex.Unlock()
+
+	if err := u.handOver(listeners); err != nil {
+		u.mutex.Lock()
+		u.upgrading = false
+		u.mutex.Unlock()
+		return err
+	}
+	close(u.done)
+	return nil
+}
+
+// handOver starts the new process with duplicates of the listeners and waits
+// until it reports being ready
+func (u *Upgrader) handOver(listeners []namedListener) error {
+	names := make([]string, 0, len(listeners))
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, l := range listeners {
+		filer, ok := l.listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("listener %s cannot be handed over", l.name)
+		}
+		file, err := filer.File()
+		if err != nil {
+			return fmt.Errorf("failed to hand over listener %s: %w", l.name, err)
+		}
+		names = append(names, l.name)
+		files = append(files, file)
+	}
+
+	readyRead, readyWrite, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create readiness pipe: %w", err)
+	}
+	defer readyRead.Close()
+	files = append(files, readyWrite)
+
+	cmd := exec.Command(u.command[0], u.command[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		listenersEnv+"="+strings.Join(names, "\n"),
+		fmt.Sprintf("%s=%d", readyFDEnv, firstInheritedFD+len(files)-1),
+	)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start the new process: %w", err)
+	}
+
+	// The new process holds its own copies now; closing ours lets the pipe
+	// report the new process exiting
+	for _, file := range files {
+		file.Close()
+	}
+	files = nil
+	logger := u.logger.WithField("pid", cmd.Process.Pid)
+	logger.Info("Started the new process; serving until it is ready")
+
+	// The new process writes a byte when ready; the pipe closes when it exits
+	ready := make(chan error, 1)
+	go func() {
+		if _, err := readyRead.Read(make([]byte, 1)); err != nil {
+			ready <- errors.New("the new process exited before it was ready")
+			return
+		}
+		ready <- nil
+	}()
+	timer := time.NewTimer(u.timeout)
+	defer timer.Stop()
+	select {
+	case err = <-ready:
+	case <-timer.C:
+		err = fmt.Errorf("the new process was not ready within %s", u.timeout)
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+
+	// Closing a Unix listener would remove the socket file the new process serves on
+	for _, l := range listeners {
+		if unixListener, ok := l.listener.(*net.UnixListener); ok {
+			unixListener.SetUnlinkOnClose(false)
+		}
+	}
+	logger.Info("The new process is ready; handing the listeners over")
+	return nil
+}
+
+// WatchSignals upgrades on SIGHUP until the context is done
+func (u *Upgrader) WatchSignals(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hangup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				if err := u.Upgrade(); err != nil {
+					u.logger.WithError(err).Error("Upgrade failed; this process keeps serving")
+				}
+			}
+		}
+	}()
+}
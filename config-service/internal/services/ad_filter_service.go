@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf16"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/md4"
+
+	"config-service/internal/models"
+)
+
+// bannedVariantSuffixes are appended to banned words and usernames when
+// deriving the passwords a hash is compared with, since only exact matches can
+// be detected from a hash
+var bannedVariantSuffixes = []string{"", "1", "12", "123", "1234", "!", "1!", "123!", "@", "#", "01"}
+
+// defaultMaxBannedVariants bounds the hashes kept for banned word variants,
+// about 60 per word, so that a large banned list cannot grow the set without limit
+const defaultMaxBannedVariants = 500000
+
+// NTLMHash returns the NTLM hash of a password: the hex MD4 digest of its
+// UTF-16LE encoding, as stored by Active Directory
+func NTLMHash(password string) string {
+	encoded := utf16.Encode([]rune(password))
+	buf := make([]byte, 2*len(encoded))
+	for i, unit := range encoded {
+		buf[2*i] = byte(unit)
+		buf[2*i+1] = byte(unit >> 8)
+	}
+	h := md4.New()
+	h.Write(buf)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ADFilterService answers Active Directory password filters, which only send
+// the NTLM hash of the candidate password. Banned words and the username are
+// detected by comparing the hash with hashes of their common variants, the
+// breach check uses the NTLM mode of the range API, and the length and
+// character class rules use what the filter reports about the plaintext.
+// Every verdict is returned within the latency budget.
+type ADFilterService struct {
+	logger         *logrus.Logger
+	breaches       *BreachService
+	bannedList     *BannedListService
+	policy         models.PasswordPolicy
+	budget         time.Duration
+	failOpen       bool
+	rejectBreached bool
+	now            func() time.Time
+
+	// bannedHashes holds the last completed *bannedHashSet. When the banned
+	// list or the year changes, a new set is built in the background while
+	// verdicts use the previous one; building is the version being built.
+	bannedHashes atomic.Value
+	maxVariants  int
+	mutex        sync.Mutex
+	building     string
+	firstBuilt   chan struct{}
+	firstOnce    sync.Once
+}
+
+// bannedHashSet is a completed set of hashes of banned word variants, for a
+// version of the banned list and a year
+type bannedHashSet struct {
+	hashes  map[string]bool
+	version string
+}
+
+// ADFilterOption configures an ADFilterService
+type ADFilterOption func(*ADFilterService)
+
+// WithADBannedList denies passwords that are a variant of a banned word
+func WithADBannedList(bannedList *BannedListService) ADFilterOption {
+	return func(s *ADFilterService) {
+		s.bannedList = bannedList
+	}
+}
+
+// WithADLatencyBudget sets how long a verdict may take; checks still running
+// when it runs out are skipped
+func WithADLatencyBudget(budget time.Duration) ADFilterOption {
+	return func(s *ADFilterService) {
+		if budget > 0 {
+			s.budget = budget
+		}
+	}
+}
+
+// WithADFailOpen sets whether a password is allowed when the breach check
+// cannot complete within the budget
+func WithADFailOpen(failOpen bool) ADFilterOption {
+	return func(s *ADFilterService) {
+		s.failOpen = failOpen
+	}
+}
+
+// WithADMaxLength sets the longest password allowed, in characters
+func WithADMaxLength(maxLength int) ADFilterOption {
+	return func(s *ADFilterService) {
+		s.policy.MaxLength = maxLength
+	}
+}
+
+// WithADMaxBannedVariants bounds the hashes of banned word variants kept;
+// variants of the words past the bound are not detected
+func WithADMaxBannedVariants(maxVariants int) ADFilterOption {
+	return func(s *ADFilterService) {
+		if maxVariants > 0 {
+			s.maxVariants = maxVariants
+		}
+	}
+}
+
+// WithADRejectBreached sets whether passwords found in known breaches are denied
+func WithADRejectBreached(reject bool) ADFilterOption {
+	return func(s *ADFilterService) {
+		s.rejectBreached = reject
+	}
+}
+
+// NewADFilterService creates an AD filter service for the default password
+// policy. breaches may be nil to skip breach checks.
+func NewADFilterService(logger *logrus.Logger, breaches *BreachService, options ...ADFilterOption) *ADFilterService {
+	s := &ADFilterService{
+		logger:         logger,
+		breaches:       breaches,
+		policy:         models.DefaultPasswordPolicy(),
+		budget:         500 * time.Millisecond,
+		failOpen:       true,
+		rejectBreached: true,
+		now:            time.Now,
+		maxVariants:    defaultMaxBannedVariants,
+		firstBuilt:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.bannedList != nil {
+		s.refreshBannedHashes()
+	}
+	return s
+}
+
+// Evaluate returns the verdict for a candidate password
+func (s *ADFilterService) Evaluate(ctx context.Context, request models.ADFilterRequest) *models.ADFilterVerdict {
+	start := s.now()
+	ctx, cancel := context.WithTimeout(ctx, s.budget)
+	defer cancel()
+
+	hash := strings.ToLower(request.NTLMHash)
+	verdict := &models.ADFilterVerdict{}
+	check := func(rule models.PolicyRule, passed bool, limit int, message string) {
+		verdict.Checks = append(verdict.Checks, models.PolicyCheck{Rule: rule, Passed: passed, Limit: limit, Message: message})
+	}
+	skip := func(rules ...models.PolicyRule) {
+		verdict.Skipped = append(verdict.Skipped, rules...)
+	}
+
+	if request.Length > 0 {
+		check(models.RuleMinLength, request.Length >= s.policy.MinLength, s.policy.MinLength,
+			fmt.Sprintf("Password must be at least %d characters long", s.policy.MinLength))
+		check(models.RuleMaxLength, request.Length <= s.policy.MaxLength, s.policy.MaxLength,
+			fmt.Sprintf("Password must not exceed %d characters", s.policy.MaxLength))
+	} else {
+		skip(models.RuleMinLength, models.RuleMaxLength)
+	}
+
+	classRules := []struct {
+		required bool
+		class    string
+		rule     models.PolicyRule
+		message  string
+	}{
+		{s.policy.RequireUppercase, "upper", models.RuleUppercase, "Password must contain at least one uppercase letter"},
+		{s.policy.RequireLowercase, "lower", models.RuleLowercase, "Password must contain at least one lowercase letter"},
+		{s.policy.RequireNumbers, "digit", models.RuleNumbers, "Password must contain at least one number"},
+		{s.policy.RequireSpecial, "special", models.RuleSpecial, "Password must contain at least one special character"},
+	}
+	for _, classRule := range classRules {
+		switch {
+		case !classRule.required:
+		case request.Classes == nil:
+			skip(classRule.rule)
+		default:
+			check(classRule.rule, containsString(request.Classes, classRule.class), 0, classRule.message)
+		}
+	}
+
+	check(models.RuleNotUsername, !identityHashes(request.Username, request.FullName)[hash], 0,
+		"Password must not be the username or full name")
+
+	if s.bannedList != nil {
+		if hashes, ok := s.bannedWordHashes(ctx); ok {
+			check(models.RuleBannedWord, !hashes[hash], 0, "Password must not be a banned word")
+		} else {
+			skip(models.RuleBannedWord)
+			verdict.Degraded = true
+		}
+	}
+
+	verdict.Verdict = models.ADFilterAllow
+	for _, c := range verdict.Checks {
+		if !c.Passed {
+			verdict.Verdict = models.ADFilterDeny
+			break
+		}
+	}
+
+	// The breach lookup is the slow check, so it only runs when the others pass
+	if verdict.Allowed() && s.rejectBreached && s.breaches != nil && s.breaches.IsEnabled() {
+		result := s.breaches.CheckNTLMHashesBreach(ctx, []string{hash})[0]
+		if result.Error != "" {
+			loggerFor(ctx, s.logger).WithField("reason", result.Error).Warn("Breach check failed during AD filter evaluation")
+			skip(models.RuleNotBreached)
+			verdict.Degraded = ctx.Err() != nil
+			if !s.failOpen {
+				verdict.Verdict = models.ADFilterDeny
+			}
+		} else {
+			check(models.RuleNotBreached, !result.Found, 0, "Password must not appear in a known data breach")
+			if result.Found {
+				verdict.Verdict = models.ADFilterDeny
+			}
+		}
+	}
+	verdict.ElapsedMS = s.now().Sub(start).Milliseconds()
+	return verdict
+}
+
+// bannedWordHashes returns the last completed set of hashes of banned word
+// variants, starting a rebuild when the banned list or the year changed. Only
+// before the first set is completed does it wait, within the budget of ctx.
+func (s *ADFilterService) bannedWordHashes(ctx context.Context) (map[string]bool, bool) {
+	s.refreshBannedHashes()
+	select {
+	case <-s.firstBuilt:
+	case <-ctx.Done():
+		return nil, false
+	}
+	return s.bannedHashes.Load().(*bannedHashSet).hashes, true
+}
+
+// refreshBannedHashes starts building the set of hashes of banned word
+// variants in the background, unless the current set is up to date or a
+// build is running. A build finishing with an outdated version is followed
+// by another on the next verdict.
+func (s *ADFilterService) refreshBannedHashes() {
+	year := s.now().Year()
+	version := s.bannedList.UpdatedAt().String() + "/" + strconv.Itoa(s.bannedList.Count()) + "/" + strconv.Itoa(year)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if current, ok := s.bannedHashes.Load().(*bannedHashSet); ok && current.version == version {
+		return
+	}
+	if s.building != "" {
+		return
+	}
+	s.building = version
+	go func() {
+		set := &bannedHashSet{hashes: s.buildBannedHashes(year), version: version}
+		s.bannedHashes.Store(set)
+		s.firstOnce.Do(func() { close(s.firstBuilt) })
+
+		s.mutex.Lock()
+		s.building = ""
+		s.mutex.Unlock()
+	}()
+}
+
+// buildBannedHashes hashes the variants of the banned words, up to the
+// maximum number of variants
+func (s *ADFilterService) buildBannedHashes(year int) map[string]bool {
+	suffixes := append([]string(nil), bannedVariantSuffixes...)
+	for _, y := range []int{year - 1, year} {
+		suffixes = append(suffixes, strconv.Itoa(y), strconv.Itoa(y)+"!")
+	}
+	words := s.bannedList.List()
+	hashes := make(map[string]bool)
+	for i, word := range words {
+		if len(hashes) >= s.maxVariants {
+			s.logger.WithFields(logrus.Fields{
+				"words":        len(words),
+				"skipped":      len(words) - i,
+				"max_variants": s.maxVariants,
+			}).Warn("Banned word variants exceed the maximum; the remaining words are not detected by the AD filter")
+			break
+		}
+		addVariantHashes(hashes, word.Word, suffixes)
+	}
+	return hashes
+}
+
+// identityHashes returns the hashes of the variants of the account name,
+// without its domain, and of the full name
+func identityHashes(username, fullName string) map[string]bool {
+	name := username
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "@"); i > 0 {
+		name = name[:i]
+	}
+
+	hashes := make(map[string]bool)
+	for _, identity := range []string{name, fullName, strings.ReplaceAll(fullName, " ", "")} {
+		if len(identity) >= minIdentityLength {
+			addVariantHashes(hashes, identity, bannedVariantSuffixes)
+		}
+	}
+	return hashes
+}
+
+// addVariantHashes adds the hashes of a word in lower, title, and upper case,
+// each followed by every suffix
+func addVariantHashes(hashes map[string]bool, word string, suffixes []string) {
+	lower := []rune(strings.ToLower(word))
+	if len(lower) == 0 {
+		return
+	}
+	title := strings.ToUpper(string(lower[:1])) + string(lower[1:])
+	bases := []string{string(lower), strings.ToUpper(string(lower)), title, word}
+	for i, base := range bases {
+		if containsString(bases[:i], base) {
+			continue
+		}
+		for _, suffix := range suffixes {
+			hashes[NTLMHash(base+suffix)] = true
+		}
+	}
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
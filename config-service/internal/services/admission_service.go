@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/models"
+)
+
+// SecretAdmissionService decides whether Kubernetes Secrets may be admitted.
+// Only Secrets opted in with the check annotation are inspected; they are
+// rejected when a password in them is too weak or found in a known breach.
+type SecretAdmissionService struct {
+	logger         *logrus.Logger
+	passwords      *PasswordService
+	breaches       *BreachService
+	minStrength    models.PasswordStrength
+	rejectBreached bool
+}
+
+// SecretAdmissionOption defines functional options for configuring the SecretAdmissionService
+type SecretAdmissionOption func(*SecretAdmissionService)
+
+// WithMinStrength sets the weakest strength level admitted
+func WithMinStrength(minimum models.PasswordStrength) SecretAdmissionOption {
+	return func(s *SecretAdmissionService) {
+		s.minStrength = minimum
+	}
+}
+
+// WithRejectBreached sets whether passwords found in known breaches are rejected
+func WithRejectBreached(reject bool) SecretAdmissionOption {
+	return func(s *SecretAdmissionService) {
+		s.rejectBreached = reject
+	}
+}
+
+// NewSecretAdmissionService creates a Secret admission service. breaches may be
+// nil to skip breach checks.
+func NewSecretAdmissionService(logger *logrus.Logger, passwords *PasswordService, breaches *BreachService, options ...SecretAdmissionOption) *SecretAdmissionService {
+	s := &SecretAdmissionService{
+		logger:         logger,
+		passwords:      passwords,
+		breaches:       breaches,
+		minStrength:    models.StrengthStrong,
+		rejectBreached: true,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Review decides on an admission request. Objects other than Secrets, deletes,
+// and Secrets without the check annotation are always allowed. Breach check
+// failures do not block admission; they are returned as warnings.
+func (s *SecretAdmissionService) Review(ctx context.Context, request *models.AdmissionRequest) *models.AdmissionResponse {
+	response := &models.AdmissionResponse{UID: request.UID, Allowed: true}
+	if request.Kind.Group != "" || request.Kind.Kind != "Secret" {
+		return response
+	}
+	if request.Operation != "CREATE" && request.Operation != "UPDATE" {
+		return response
+	}
+
+	var secret models.KubernetesSecret
+	if err := json.Unmarshal(request.Object, &secret); err != nil {
+		response.Allowed = false
+		response.Status = &models.AdmissionStatus{Code: http.StatusBadRequest, Message: "invalid Secret: " + err.Error()}
+		return response
+	}
+	if secret.Metadata.Annotations[models.AnnotationCheckPasswords] != "true" {
+		return response
+	}
+
+	values := secretValues(&secret)
+	var problems []string
+	for _, key := range passwordKeys(&secret, values) {
+		password, ok := values[key]
+		if !ok {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("key %q listed in %s is not in the Secret", key, models.AnnotationPasswordKeys))
+			continue
+		}
+		problem, warning := s.checkPassword(ctx, key, password)
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+		if warning != "" {
+			response.Warnings = append(response.Warnings, warning)
+		}
+	}
+
+	name := request.Namespace + "/" + request.Name
+	if len(problems) > 0 {
+		loggerFor(ctx, s.logger).Infof("Rejected Secret %s: %d password problem(s)", name, len(problems))
+		response.Allowed = false
+		response.Status = &models.AdmissionStatus{
+			Code:    http.StatusForbidden,
+			Message: fmt.Sprintf("Secret %s rejected: %s", name, strings.Join(problems, "; ")),
+		}
+	}
+	return response
+}
+
+// checkPassword returns the reason a password is rejected, if any, and a
+// warning when its breach status could not be determined. Neither contains
+// the password.
+func (s *SecretAdmissionService) checkPassword(ctx context.Context, key, password string) (string, string) {
+	if err := s.passwords.ValidatePasswordContext(ctx, password); err != nil {
+		return fmt.Sprintf("key %q: %v", key, err), ""
+	}
+
+	response := s.passwords.ScorePasswordContext(ctx, password)
+	passwordStrength := response.Strength
+	ReleaseResponse(response)
+	if !passwordStrength.AtLeast(s.minStrength) {
+		return fmt.Sprintf("key %q is %s, below the required %s", key, passwordStrength, s.minStrength), ""
+	}
+
+	if !s.rejectBreached || s.breaches == nil || !s.breaches.IsEnabled() {
+		return "", ""
+	}
+	info, err := s.breaches.CheckPasswordBreachContext(ctx, password)
+	if err != nil {
+		return "", fmt.Sprintf("breach check of key %q failed: %v", key, err)
+	}
+	if info.Found {
+		return fmt.Sprintf("key %q appears in %d known breaches", key, info.BreachCount), ""
+	}
+	return "", ""
+}
+
+// secretValues returns the decoded values of a Secret; stringData takes
+// precedence over data, as it does in the API server
+func secretValues(secret *models.KubernetesSecret) map[string]string {
+	values := make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for key, value := range secret.Data {
+		values[key] = string(value)
+	}
+	for key, value := range secret.StringData {
+		values[key] = value
+	}
+	return values
+}
+
+// passwordKeys returns the keys to check: those listed in the keys
+// annotation, or else every key of the Secret
+func passwordKeys(secret *models.KubernetesSecret, values map[string]string) []string {
+	var keys []string
+	if listed := secret.Metadata.Annotations[models.AnnotationPasswordKeys]; listed != "" {
+		for _, key := range strings.Split(listed, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
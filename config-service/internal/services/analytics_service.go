@@ -0,0 +1,253 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"config-service/internal/models"
+)
+
+const (
+	// analyticsDayLayout formats the days analytics are counted by, e.g. "2026-10-16"
+	analyticsDayLayout = "2006-01-02"
+
+	// analyticsDefaultRange is how many days a report covers by default, including today
+	analyticsDefaultRange = 30
+
+	// analyticsScoreBuckets is how many buckets of ten points the score distribution has
+	analyticsScoreBuckets = 10
+
+	// analyticsTopViolations is how many of the most violated rules are reported
+	analyticsTopViolations = 5
+)
+
+// analyticsDay holds the counters of the password checks made on one day
+type analyticsDay struct {
+	checks, breached, failures int64
+	scoreSum, scored           int64
+	scores                     [analyticsScoreBuckets]int64
+	violations                 map[models.PolicyRule]int64
+}
+
+// AnalyticsService counts the outcomes of password checks per day (UTC) for
+// aggregate reports. Only counters are kept: no password, hash, caller, or
+// tenant is ever stored.
+type AnalyticsService struct {
+	retentionDays int
+	minChecks     int64
+	now           func() time.Time
+
+	mu   sync.Mutex
+	days map[string]*analyticsDay
+}
+
+// AnalyticsServiceOption defines functional options for configuring the AnalyticsService
+type AnalyticsServiceOption func(*AnalyticsService)
+
+// WithAnalyticsRetention sets how many days of counters are kept, including today
+func WithAnalyticsRetention(days int) AnalyticsServiceOption {
+	return func(s *AnalyticsService) {
+		if days > 0 {
+			s.retentionDays = days
+		}
+	}
+}
+
+// WithAnalyticsMinChecks sets how many checks a report must cover before
+// anything but their count is reported, so that a report over a handful of
+// checks cannot single out a password
+func WithAnalyticsMinChecks(checks int64) AnalyticsServiceOption {
+	return func(s *AnalyticsService) {
+		s.minChecks = checks
+	}
+}
+
+// WithAnalyticsClock sets the clock checks are counted by
+func WithAnalyticsClock(now func() time.Time) AnalyticsServiceOption {
+	return func(s *AnalyticsService) {
+		s.now = now
+	}
+}
+
+// NewAnalyticsService creates a new analytics counter
+func NewAnalyticsService(options ...AnalyticsServiceOption) *AnalyticsService {
+	s := &AnalyticsService{
+		retentionDays: 90,
+		minChecks:     10,
+		now:           time.Now,
+		days:          make(map[string]*analyticsDay),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Record counts the outcome of a password check
+func (s *AnalyticsService) Record(outcome models.CheckOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := s.day(s.now().UTC())
+	day.checks++
+	if outcome.Breached {
+		day.breached++
+	}
+	if outcome.PolicyFailure {
+		day.failures++
+	}
+	if outcome.Scored {
+		day.scoreSum += int64(outcome.Score)
+		day.scored++
+		day.scores[scoreBucket(outcome.Score)]++
+	}
+	for _, rule := range outcome.Violations {
+		day.violations[rule]++
+	}
+}
+
+// Report returns the statistics of the checks made from one day to another,
+// both formatted as "YYYY-MM-DD" and inclusive. An empty to means today and an
+// empty from means 30 days before to. The range is narrowed to the retained days.
+func (s *AnalyticsService) Report(from, to string) (models.AnalyticsReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	end := today
+	if to != "" {
+		parsed, err := time.Parse(analyticsDayLayout, to)
+		if err != nil {
+			return models.AnalyticsReport{}, fmt.Errorf("invalid to %q: expected YYYY-MM-DD", to)
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, -(analyticsDefaultRange - 1))
+	if from != "" {
+		parsed, err := time.Parse(analyticsDayLayout, from)
+		if err != nil {
+			return models.AnalyticsReport{}, fmt.Errorf("invalid from %q: expected YYYY-MM-DD", from)
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return models.AnalyticsReport{}, fmt.Errorf("from %s is after to %s", start.Format(analyticsDayLayout), end.Format(analyticsDayLayout))
+	}
+	// Only retained days are reported
+	if oldest := today.AddDate(0, 0, -(s.retentionDays - 1)); start.Before(oldest) {
+		start = oldest
+	}
+	if end.After(today) {
+		end = today
+	}
+
+	report := models.AnalyticsReport{
+		From:              start.Format(analyticsDayLayout),
+		To:                end.Format(analyticsDayLayout),
+		ScoreDistribution: []models.ScoreBucket{},
+		TopViolations:     []models.RuleViolations{},
+	}
+
+	var total analyticsDay
+	total.violations = make(map[models.PolicyRule]int64)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		counts, exists := s.days[day.Format(analyticsDayLayout)]
+		if !exists {
+			continue
+		}
+		total.checks += counts.checks
+		total.breached += counts.breached
+		total.failures += counts.failures
+		total.scoreSum += counts.scoreSum
+		total.scored += counts.scored
+		for i, count := range counts.scores {
+			total.scores[i] += count
+		}
+		for rule, count := range counts.violations {
+			total.violations[rule] += count
+		}
+	}
+
+	report.Checks = total.checks
+	if total.checks == 0 || total.checks < s.minChecks {
+		report.Suppressed = total.checks > 0
+		return report, nil
+	}
+
+	report.Breached = total.breached
+	report.BreachedPercent = percent(total.breached, total.checks)
+	report.PolicyFailures = total.failures
+	report.PolicyFailurePercent = percent(total.failures, total.checks)
+	if total.scored > 0 {
+		report.AverageScore = math.Round(float64(total.scoreSum)/float64(total.scored)*100) / 100
+	}
+	for i, count := range total.scores {
+		bucket := models.ScoreBucket{Min: i * 10, Max: i*10 + 9, Count: count}
+		if i == analyticsScoreBuckets-1 {
+			bucket.Max = 100
+		}
+		report.ScoreDistribution = append(report.ScoreDistribution, bucket)
+	}
+	for rule, count := range total.violations {
+		report.TopViolations = append(report.TopViolations, models.RuleViolations{
+			Rule:    rule,
+			Count:   count,
+			Percent: percent(count, total.checks),
+		})
+	}
+	sort.Slice(report.TopViolations, func(i, j int) bool {
+		a, b := report.TopViolations[i], report.TopViolations[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Rule < b.Rule
+	})
+	if len(report.TopViolations) > analyticsTopViolations {
+		report.TopViolations = report.TopViolations[:analyticsTopViolations]
+	}
+
+	return report, nil
+}
+
+// day returns the counters for the day of now, creating them and dropping
+// days past the retention when a new day starts
+func (s *AnalyticsService) day(now time.Time) *analyticsDay {
+	name := now.Format(analyticsDayLayout)
+	if counts, exists := s.days[name]; exists {
+		return counts
+	}
+
+	counts := &analyticsDay{violations: make(map[models.PolicyRule]int64)}
+	s.days[name] = counts
+
+	oldest := now.AddDate(0, 0, -(s.retentionDays - 1)).Format(analyticsDayLayout)
+	for existing := range s.days {
+		if existing < oldest {
+			delete(s.days, existing)
+		}
+	}
+
+	return counts
+}
+
+// scoreBucket returns the bucket of the score distribution a score falls in
+func scoreBucket(score int) int {
+	switch {
+	case score < 0:
+		return 0
+	case score >= analyticsScoreBuckets*10:
+		return analyticsScoreBuckets - 1
+	default:
+		return score / 10
+	}
+}
+
+// percent returns part as a percentage of whole, rounded to two decimals
+func percent(part, whole int64) float64 {
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
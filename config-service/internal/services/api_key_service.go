@@ -0,0 +1,211 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/models"
+)
+
+const (
+	// apiKeyPrefix identifies secrets issued by this service
+	apiKeyPrefix = "csk_"
+
+	// apiKeySecretBytes is the number of random bytes in an API key secret
+	apiKeySecretBytes = 32
+
+	// apiKeyDisplayLength is how many characters of the secret are kept for display
+	apiKeyDisplayLength = 8
+)
+
+// ErrAPIKeyNotFound is returned when an API key does not exist
+var ErrAPIKeyNotFound = fmt.Errorf("api key not found")
+
+// ErrAPIKeyInvalid is returned when a presented API key is unknown or revoked
+var ErrAPIKeyInvalid = fmt.Errorf("api key is invalid or revoked")
+
+// APIKeyService issues, authenticates, rotates, and revokes API keys.
+// Secrets are stored as SHA-256 hashes and never kept in plaintext.
+type APIKeyService struct {
+	logger *logrus.Logger
+	keys   map[string]*models.APIKey
+	// hashes maps secret hashes to key IDs, and keyHashes the reverse
+	hashes    map[string]string
+	keyHashes map[string]string
+	mutex     sync.RWMutex
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(logger *logrus.Logger) *APIKeyService {
+	return &APIKeyService{
+		logger:    logger,
+		keys:      make(map[string]*models.APIKey),
+		hashes:    make(map[string]string),
+		keyHashes: make(map[string]string),
+	}
+}
+
+// Issue creates a new API key and returns it together with its plaintext secret
+func (s *APIKeyService) Issue(name, tenant string, scopes []string) (*models.APIKeySecretResponse, error) {
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %w", err)
+	}
+	secret, err := generateAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	key := &models.APIKey{
+		ID:        "key_" + id,
+		Name:      name,
+		Tenant:    tenant,
+		Scopes:    append([]string(nil), scopes...),
+		Prefix:    secret[:len(apiKeyPrefix)+apiKeyDisplayLength],
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mutex.Lock()
+	s.keys[key.ID] = key
+	s.setSecretHash(key.ID, hashAPIKey(secret))
+	s.mutex.Unlock()
+
+	s.logger.Infof("API key issued: id=%s tenant=%s scopes=%v", key.ID, key.Tenant, key.Scopes)
+	return &models.APIKeySecretResponse{APIKey: *key, Key: secret}, nil
+}
+
+// Authenticate resolves a presented secret to its API key
+func (s *APIKeyService) Authenticate(secret string) (*models.APIKey, error) {
+	hash := hashAPIKey(secret)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id, ok := s.hashes[hash]
+	if !ok {
+		return nil, ErrAPIKeyInvalid
+	}
+	key := s.keys[id]
+	if key == nil || key.IsRevoked() {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	now := time.Now().UTC()
+	key.LastUsedAt = &now
+
+	copied := *key
+	return &copied, nil
+}
+
+// Rotate replaces the secret of an API key, invalidating the previous secret
+func (s *APIKeyService) Rotate(id string) (*models.APIKeySecretResponse, error) {
+	secret, err := generateAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || key.IsRevoked() {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	s.setSecretHash(id, hashAPIKey(secret))
+	key.Prefix = secret[:len(apiKeyPrefix)+apiKeyDisplayLength]
+	key.UpdatedAt = time.Now().UTC()
+
+	s.logger.Infof("API key rotated: id=%s tenant=%s", key.ID, key.Tenant)
+	return &models.APIKeySecretResponse{APIKey: *key, Key: secret}, nil
+}
+
+// Revoke permanently disables an API key
+func (s *APIKeyService) Revoke(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || key.IsRevoked() {
+		return ErrAPIKeyNotFound
+	}
+
+	now := time.Now().UTC()
+	key.RevokedAt = &now
+	key.UpdatedAt = now
+	delete(s.hashes, s.keyHashes[id])
+	delete(s.keyHashes, id)
+
+	s.logger.Infof("API key revoked: id=%s tenant=%s", key.ID, key.Tenant)
+	return nil
+}
+
+// setSecretHash replaces the secret hash of a key; the caller must hold the lock
+func (s *APIKeyService) setSecretHash(id, hash string) {
+	if previous, ok := s.keyHashes[id]; ok {
+		delete(s.hashes, previous)
+	}
+	s.hashes[hash] = id
+	s.keyHashes[id] = hash
+}
+
+// ListPage returns a page of API keys matching the list options
+func (s *APIKeyService) ListPage(opts models.ListOptions) ([]models.APIKey, models.PageInfo, error) {
+	s.mutex.RLock()
+	items := make([]apiKeyListItem, 0, len(s.keys))
+	for _, key := range s.keys {
+		items = append(items, apiKeyListItem(*key))
+	}
+	s.mutex.RUnlock()
+
+	page, info, err := paginate(items, opts)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	keys := make([]models.APIKey, len(page))
+	for i, item := range page {
+		keys[i] = models.APIKey(item)
+	}
+	return keys, info, nil
+}
+
+// apiKeyListItem adapts an API key for pagination
+type apiKeyListItem models.APIKey
+
+func (k apiKeyListItem) listID() string           { return k.ID }
+func (k apiKeyListItem) listName() string         { return k.Name }
+func (k apiKeyListItem) listUpdatedAt() time.Time { return k.UpdatedAt }
+
+// generateAPIKeySecret creates a new random API key secret
+func generateAPIKeySecret() (string, error) {
+	secret, err := randomToken(apiKeySecretBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + secret, nil
+}
+
+// hashAPIKey returns the hash under which an API key secret is stored
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns a URL-safe random string built from n random bytes
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/models"
+)
+
+const (
+	// bannedImportHistory is how many finished imports are kept for status requests
+	bannedImportHistory = 50
+
+	// bannedImportProgressLines is how many lines are read between updates
+	// of an import's progress
+	bannedImportProgressLines = 10000
+
+	// bannedImportMaxLine is the longest line an import accepts
+	bannedImportMaxLine = 64 * 1024
+)
+
+var (
+	// ErrBannedImportNotFound is returned when an import is unknown or was dropped from the history
+	ErrBannedImportNotFound = errors.New("banned list import not found")
+
+	// ErrBannedImportInProgress is returned when importing into a source that
+	// another import is still receiving or processing
+	ErrBannedImportInProgress = errors.New("an import into this source is in progress")
+
+	// ErrBannedImportStorage is returned when an upload cannot be spooled to disk
+	ErrBannedImportStorage = errors.New("failed to store banned list upload")
+)
+
+// BannedImportService imports banned lists too large for a JSON request. An
+// upload is spooled to a temporary file while it streams in, then read back
+// in the background, normalized, and deduplicated, and its words are added
+// to the banned list in one step once all of them are known. Imports are
+// tracked as jobs reporting their progress.
+type BannedImportService struct {
+	logger     *logrus.Logger
+	bannedList *BannedListService
+	dir        string
+
+	jobs  map[string]*models.BannedImportJob
+	order []string
+	mutex sync.Mutex
+}
+
+// BannedImportOption defines functional options for configuring the BannedImportService
+type BannedImportOption func(*BannedImportService)
+
+// WithImportDir sets the directory uploads are spooled to; the system
+// temporary directory is used by default
+func WithImportDir(dir string) BannedImportOption {
+	return func(s *BannedImportService) {
+		s.dir = dir
+	}
+}
+
+// NewBannedImportService creates a banned list import service adding to bannedList
+func NewBannedImportService(logger *logrus.Logger, bannedList *BannedListService, options ...BannedImportOption) *BannedImportService {
+	s := &BannedImportService{
+		logger:     logger,
+		bannedList: bannedList,
+		jobs:       make(map[string]*models.BannedImportJob),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Start receives an upload of words in format from body and imports it into
+// source in the background, returning the job tracking it. Reading body
+// fails the import when the upload cannot be received completely.
+func (s *BannedImportService) Start(source, format string, replace bool, body io.Reader) (models.BannedImportJob, error) {
+	if format != models.BannedImportText && format != models.BannedImportNDJSON {
+		return models.BannedImportJob{}, fmt.Errorf("unsupported import format %q", format)
+	}
+	id, err := randomToken(12)
+	if err != nil {
+		return models.BannedImportJob{}, fmt.Errorf("failed to generate import id: %w", err)
+	}
+
+	job := &models.BannedImportJob{
+		ID:        "imp_" + id,
+		Status:    models.BannedImportReceiving,
+		Source:    source,
+		Format:    format,
+		Replace:   replace,
+		StartedAt: time.Now().UTC(),
+	}
+	s.mutex.Lock()
+	for _, other := range s.jobs {
+		if other.Source == source && (other.Status == models.BannedImportReceiving || other.Status == models.BannedImportProcessing) {
+			s.mutex.Unlock()
+			return models.BannedImportJob{}, ErrBannedImportInProgress
+		}
+	}
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	s.mutex.Unlock()
+
+	file, err := os.CreateTemp(s.dir, "banned-import-*")
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrBannedImportStorage, err)
+		return s.fail(job.ID, err), err
+	}
+	_, err = io.Copy(file, &progressReader{Reader: body, count: func(n int) {
+		s.update(job.ID, func(job *models.BannedImportJob) { job.BytesTotal += int64(n) })
+	}})
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		err = fmt.Errorf("failed to receive banned words: %w", err)
+		return s.fail(job.ID, err), err
+	}
+
+	started := s.update(job.ID, func(job *models.BannedImportJob) { job.Status = models.BannedImportProcessing })
+	s.logger.Infof("Banned list import %s into %s started: %d bytes received", started.ID, source, started.BytesTotal)
+	go s.process(job.ID, file)
+	return started, nil
+}
+
+// process reads back a spooled upload, normalizing and deduplicating its
+// words, and adds them to the banned list once the whole upload is read
+func (s *BannedImportService) process(id string, file *os.File) {
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	job := s.get(id)
+	var processed int64
+	reader := &progressReader{Reader: file, count: func(n int) { processed += int64(n) }}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), bannedImportMaxLine)
+
+	words := make(map[string]bool)
+	var progress models.BannedImportJob
+	flush := func() {
+		s.update(id, func(job *models.BannedImportJob) {
+			job.BytesProcessed = processed
+			job.Lines, job.Words = progress.Lines, len(words)
+			job.Duplicates, job.Skipped, job.Invalid = progress.Duplicates, progress.Skipped, progress.Invalid
+		})
+	}
+	for scanner.Scan() {
+		progress.Lines++
+		word, ok := decodeImportLine(job.Format, scanner.Text())
+		normalized := normalizeBannedWord(word)
+		switch {
+		case !ok:
+			progress.Invalid++
+		case normalized == "":
+			progress.Skipped++
+		case words[normalized]:
+			progress.Duplicates++
+		default:
+			words[normalized] = true
+		}
+		if progress.Lines%bannedImportProgressLines == 0 {
+			flush()
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			err = fmt.Errorf("line %d is longer than %d bytes", progress.Lines+1, bannedImportMaxLine)
+		}
+		s.fail(id, fmt.Errorf("failed to read banned words: %w", err))
+		return
+	}
+
+	added, removed := s.bannedList.Import(job.Source, words, job.Replace)
+	completed := s.update(id, func(job *models.BannedImportJob) {
+		now := time.Now().UTC()
+		job.Status = models.BannedImportCompleted
+		job.Added, job.Removed = added, removed
+		job.CompletedAt = &now
+	})
+	s.logger.Infof("Banned list import %s completed: %d lines, %d words, %d duplicates, %d invalid",
+		id, completed.Lines, completed.Words, completed.Duplicates, completed.Invalid)
+	s.prune()
+}
+
+// decodeImportLine returns the word of a line in format, or false when the
+// line cannot be decoded. Blank lines, and comments in text, have no word.
+func decodeImportLine(format, line string) (string, bool) {
+	line = strings.TrimPrefix(line, "\ufeff")
+	if format == models.BannedImportText {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			return "", true
+		}
+		return line, true
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", true
+	}
+	var word string
+	if err := json.Unmarshal([]byte(line), &word); err == nil {
+		return word, true
+	}
+	var entry struct {
+		Word *string `json:"word"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Word == nil {
+		return "", false
+	}
+	return *entry.Word, true
+}
+
+// Get returns an import by ID
+func (s *BannedImportService) Get(id string) (models.BannedImportJob, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return models.BannedImportJob{}, ErrBannedImportNotFound
+	}
+	return *job, nil
+}
+
+// List returns the imports in progress and the latest finished ones, most
+// recently started first
+func (s *BannedImportService) List() []models.BannedImportJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	jobs := make([]models.BannedImportJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].StartedAt.Equal(jobs[j].StartedAt) {
+			return jobs[i].StartedAt.After(jobs[j].StartedAt)
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs
+}
+
+// get returns a copy of a job
+func (s *BannedImportService) get(id string) models.BannedImportJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return *s.jobs[id]
+}
+
+// update changes a job under the lock and returns a copy of it
+func (s *BannedImportService) update(id string, change func(*models.BannedImportJob)) models.BannedImportJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job := s.jobs[id]
+	change(job)
+	return *job
+}
+
+// fail marks a job failed with err, leaving the banned list unchanged
+func (s *BannedImportService) fail(id string, err error) models.BannedImportJob {
+	failed := s.update(id, func(job *models.BannedImportJob) {
+		now := time.Now().UTC()
+		job.Status = models.BannedImportFailed
+		job.Error = err.Error()
+		job.CompletedAt = &now
+	})
+	s.logger.WithError(err).Warnf("Banned list import %s failed", id)
+	s.prune()
+	return failed
+}
+
+// prune drops the oldest finished jobs past the history
+func (s *BannedImportService) prune() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	finished := 0
+	for _, id := range s.order {
+		if s.jobs[id].CompletedAt != nil {
+			finished++
+		}
+	}
+	kept := s.order[:0]
+	for _, id := range s.order {
+		if finished > bannedImportHistory && s.jobs[id].CompletedAt != nil {
+			delete(s.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	s.order = kept
+}
+
+// progressReader reports the bytes read through it
+type progressReader struct {
+	io.Reader
+	count func(n int)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.count(n)
+	}
+	return n, err
+}
@@ -0,0 +1,347 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/models"
+)
+
+// defaultDeletedRetention is how long removed banned words can be restored
+const defaultDeletedRetention = 30 * 24 * time.Hour
+
+// ErrBannedWordNotDeleted is returned when restoring a word that was not
+// removed or whose retention has passed
+var ErrBannedWordNotDeleted = fmt.Errorf("banned word not deleted or past retention")
+
+// BannedListService manages the list of words that must not appear in passwords
+type BannedListService struct {
+	logger    *logrus.Logger
+	words     map[string]models.BannedWord
+	updatedAt time.Time
+	mutex     sync.RWMutex
+
+	// deleted holds removed words until their retention passes, so that
+	// accidental removals can be undone
+	deleted   map[string]models.DeletedBannedWord
+	retention time.Duration
+
+	// lengths are the distinct lengths of the words, longest first, rebuilt
+	// whenever the list changes so that lookups scan each length once
+	lengths []int
+}
+
+// BannedListOption defines functional options for configuring the BannedListService
+type BannedListOption func(*BannedListService)
+
+// WithDeletedRetention sets how long removed words can be restored
+func WithDeletedRetention(retention time.Duration) BannedListOption {
+	return func(s *BannedListService) {
+		if retention > 0 {
+			s.retention = retention
+		}
+	}
+}
+
+// NewBannedListService creates a new banned list service
+func NewBannedListService(logger *logrus.Logger, options ...BannedListOption) *BannedListService {
+	s := &BannedListService{
+		logger:    logger,
+		words:     make(map[string]models.BannedWord),
+		updatedAt: time.Now().UTC(),
+		deleted:   make(map[string]models.DeletedBannedWord),
+		retention: defaultDeletedRetention,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// normalizeBannedWord normalizes a word for storage and comparison
+func normalizeBannedWord(word string) string {
+	return strings.ToLower(strings.TrimSpace(word))
+}
+
+// Add adds words to the banned list and returns how many were newly added
+func (s *BannedListService) Add(words ...string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().UTC()
+	added := 0
+	for _, word := range words {
+		normalized := normalizeBannedWord(word)
+		if normalized == "" {
+			continue
+		}
+		if _, exists := s.words[normalized]; exists {
+			continue
+		}
+		s.words[normalized] = models.BannedWord{Word: normalized, AddedAt: now}
+		delete(s.deleted, normalized)
+		added++
+	}
+	if added > 0 {
+		s.updatedAt = now
+		s.reindex()
+	}
+
+	s.logger.Infof("Banned list updated: %d words added, %d total", added, len(s.words))
+	return added
+}
+
+// Sync replaces the words loaded from source, such as a word list file, with
+// words: words no longer in the source are removed and new ones added. Words
+// added through the admin API are left alone. It returns how many words were
+// added and removed.
+func (s *BannedListService) Sync(source string, words []string) (added, removed int) {
+	wanted := make(map[string]bool, len(words))
+	for _, word := range words {
+		if normalized := normalizeBannedWord(word); normalized != "" {
+			wanted[normalized] = true
+		}
+	}
+	return s.Import(source, wanted, true)
+}
+
+// Import adds normalized words from source to the banned list in one step,
+// so that checks see either none or all of them. With replace, the words
+// previously loaded from source and missing from words are removed, as Sync
+// does. It returns how many words were added and removed.
+func (s *BannedListService) Import(source string, words map[string]bool, replace bool) (added, removed int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if replace {
+		for word, entry := range s.words {
+			if entry.Source == source && !words[word] {
+				delete(s.words, word)
+				removed++
+			}
+		}
+	}
+	now := time.Now().UTC()
+	for word := range words {
+		if _, exists := s.words[word]; exists {
+			continue
+		}
+		s.words[word] = models.BannedWord{Word: word, AddedAt: now, Source: source}
+		delete(s.deleted, word)
+		added++
+	}
+	if added > 0 || removed > 0 {
+		s.updatedAt = now
+		s.reindex()
+	}
+
+	s.logger.Infof("Banned list synced from %s: %d words added, %d removed, %d total", source, added, removed, len(s.words))
+	return added, removed
+}
+
+// Remove removes a word from the banned list on behalf of deletedBy and
+// reports whether it was present. The word can be restored until the
+// retention passes.
+func (s *BannedListService) Remove(word, deletedBy string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	normalized := normalizeBannedWord(word)
+	entry, exists := s.words[normalized]
+	if !exists {
+		return false
+	}
+	now := time.Now().UTC()
+	s.purge(now)
+	delete(s.words, normalized)
+	s.deleted[normalized] = models.DeletedBannedWord{
+		BannedWord: entry,
+		DeletedAt:  now,
+		DeletedBy:  deletedBy,
+		PurgeAt:    now.Add(s.retention),
+	}
+	s.updatedAt = now
+	s.reindex()
+
+	s.logger.Infof("Banned word %q deleted by %s", normalized, deletedBy)
+	return true
+}
+
+// Restore puts back a removed word whose retention has not passed
+func (s *BannedListService) Restore(word string) (models.BannedWord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().UTC()
+	s.purge(now)
+	normalized := normalizeBannedWord(word)
+	entry, exists := s.deleted[normalized]
+	if !exists {
+		return models.BannedWord{}, ErrBannedWordNotDeleted
+	}
+	delete(s.deleted, normalized)
+	s.words[normalized] = entry.BannedWord
+	s.updatedAt = now
+	s.reindex()
+
+	s.logger.Infof("Banned word %q restored", normalized)
+	return entry.BannedWord, nil
+}
+
+// Deleted returns the removed words that can still be restored, most
+// recently deleted first
+func (s *BannedListService) Deleted() []models.DeletedBannedWord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.purge(time.Now().UTC())
+	words := make([]models.DeletedBannedWord, 0, len(s.deleted))
+	for _, word := range s.deleted {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if !words[i].DeletedAt.Equal(words[j].DeletedAt) {
+			return words[i].DeletedAt.After(words[j].DeletedAt)
+		}
+		return words[i].Word < words[j].Word
+	})
+	return words
+}
+
+// purge drops removed words past their retention; callers hold the write lock
+func (s *BannedListService) purge(now time.Time) {
+	for word, entry := range s.deleted {
+		if !now.Before(entry.PurgeAt) {
+			delete(s.deleted, word)
+		}
+	}
+}
+
+// reindex rebuilds the word lengths; callers hold the write lock
+func (s *BannedListService) reindex() {
+	seen := make(map[int]bool)
+	s.lengths = s.lengths[:0]
+	for word := range s.words {
+		if !seen[len(word)] {
+			seen[len(word)] = true
+			s.lengths = append(s.lengths, len(word))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(s.lengths)))
+}
+
+// UpdatedAt returns when the banned list last changed
+func (s *BannedListService) UpdatedAt() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.updatedAt
+}
+
+// List returns all banned words sorted alphabetically
+func (s *BannedListService) List() []models.BannedWord {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	words := make([]models.BannedWord, 0, len(s.words))
+	for _, word := range s.words {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].Word < words[j].Word
+	})
+	return words
+}
+
+// SourceWords returns the words loaded from source, sorted alphabetically
+func (s *BannedListService) SourceWords(source string) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var words []string
+	for word, entry := range s.words {
+		if entry.Source == source {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// ListPage returns a page of banned words matching the list options
+func (s *BannedListService) ListPage(opts models.ListOptions) ([]models.BannedWord, models.PageInfo, error) {
+	s.mutex.RLock()
+	items := make([]bannedListItem, 0, len(s.words))
+	for _, word := range s.words {
+		items = append(items, bannedListItem(word))
+	}
+	s.mutex.RUnlock()
+
+	page, info, err := paginate(items, opts)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	words := make([]models.BannedWord, len(page))
+	for i, item := range page {
+		words[i] = models.BannedWord(item)
+	}
+	return words, info, nil
+}
+
+// Count returns the number of banned words
+func (s *BannedListService) Count() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.words)
+}
+
+// FindBannedWord returns the longest banned word contained in the password, if
+// any. Each substring of a banned word length is looked up in the list, so the
+// cost grows with the number of distinct lengths rather than of words.
+func (s *BannedListService) FindBannedWord(password string) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	lowerPassword := strings.ToLower(password)
+	for _, length := range s.lengths {
+		for i := 0; i+length <= len(lowerPassword); i++ {
+			if _, found := s.words[lowerPassword[i:i+length]]; found {
+				return lowerPassword[i : i+length], true
+			}
+		}
+	}
+	return "", false
+}
+
+// FindBannedWordSpans returns the byte spans of every banned word in the
+// lowercased password, for showing where they are
+func (s *BannedListService) FindBannedWordSpans(password string) [][2]int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var spans [][2]int
+	lowerPassword := strings.ToLower(password)
+	for _, length := range s.lengths {
+		for i := 0; i+length <= len(lowerPassword); i++ {
+			if _, found := s.words[lowerPassword[i:i+length]]; found {
+				spans = append(spans, [2]int{i, i + length})
+			}
+		}
+	}
+	return spans
+}
+
+// bannedListItem adapts a banned word for pagination
+type bannedListItem models.BannedWord
+
+func (w bannedListItem) listID() string           { return w.Word }
+func (w bannedListItem) listName() string         { return w.Word }
+func (w bannedListItem) listUpdatedAt() time.Time { return w.AddedAt }
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"config-service/internal/models"
+)
+
+// breachCacheSnapshot is the file format of a breach cache snapshot. Entries
+// are keyed by password hash, never by password.
+type breachCacheSnapshot struct {
+	Version int                                  `json:"version"`
+	Entries map[string]*breachCacheSnapshotEntry `json:"entries"`
+}
+
+// breachCacheSnapshotEntry is a cached result and, since version 2, when it expires
+type breachCacheSnapshotEntry struct {
+	models.BreachInfo
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// breachCacheSnapshotVersion is the current snapshot format version
+const breachCacheSnapshotVersion = 2
+
+// SaveCache writes the unexpired entries of the breach cache to w and returns
+// the number of entries written
+func (bs *BreachService) SaveCache(w io.Writer) (int, error) {
+	bs.cacheMutex.RLock()
+	now := bs.now()
+	snapshot := breachCacheSnapshot{
+		Version: breachCacheSnapshotVersion,
+		Entries: make(map[string]*breachCacheSnapshotEntry, len(bs.cache)),
+	}
+	for hash, entry := range bs.cache {
+		if now.Before(entry.expiresAt) {
+			snapshot.Entries[hash] = &breachCacheSnapshotEntry{BreachInfo: *entry.info, ExpiresAt: entry.expiresAt}
+		}
+	}
+	bs.cacheMutex.RUnlock()
+
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		return 0, fmt.Errorf("failed to write breach cache snapshot: %w", err)
+	}
+	return len(snapshot.Entries), nil
+}
+
+// LoadCache adds the unexpired entries of a snapshot written by SaveCache to
+// the breach cache, keeping entries already cached, and returns the number
+// loaded. Entries of version 1 snapshots, which have no expiry, are given
+// expiries spread over the cache duration so they do not all expire at once.
+func (bs *BreachService) LoadCache(r io.Reader) (int, error) {
+	var snapshot breachCacheSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return 0, fmt.Errorf("invalid breach cache snapshot: %w", err)
+	}
+	if snapshot.Version != 1 && snapshot.Version != breachCacheSnapshotVersion {
+		return 0, fmt.Errorf("unsupported breach cache snapshot version %d", snapshot.Version)
+	}
+
+	bs.cacheMutex.Lock()
+	defer bs.cacheMutex.Unlock()
+	now := bs.now()
+	spread := rand.New(rand.NewSource(now.UnixNano()))
+	loaded := 0
+	for hash, entry := range snapshot.Entries {
+		if entry == nil {
+			continue
+		}
+		expiresAt := entry.ExpiresAt
+		if snapshot.Version == 1 && bs.cacheDuration > 0 {
+			expiresAt = now.Add(time.Duration(spread.Int63n(int64(bs.cacheDuration))) + 1)
+		}
+		if !now.Before(expiresAt) {
+			continue
+		}
+		if _, exists := bs.cache[hash]; !exists {
+			info := entry.BreachInfo
+			bs.cache[hash] = breachCacheEntry{info: &info, expiresAt: expiresAt}
+			loaded++
+		}
+	}
+	return loaded, nil
+}
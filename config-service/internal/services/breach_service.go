@@ -2,6 +2,7 @@ package services
 
 import (
 	"bufio"
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
@@ -11,12 +12,18 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"config-service/internal/dataset"
 	"config-service/internal/errors"
+	"config-service/internal/metrics"
 	"config-service/internal/models"
+	"config-service/internal/reproducible"
+	"config-service/internal/requestid"
+	"config-service/internal/workpool"
 )
 
 const (
@@ -28,17 +35,38 @@ const (
 	
 	// Default cache duration in minutes
 	defaultCacheDuration = 60
+
+	// maxCacheSweepInterval bounds how long expired entries stay in memory
+	maxCacheSweepInterval = time.Minute
 )
 
+// breachCacheEntry is a cached breach result and when it expires
+type breachCacheEntry struct {
+	info      *models.BreachInfo
+	expiresAt time.Time
+}
+
 // BreachService provides functionality to check if passwords have been exposed in data breaches
 type BreachService struct {
+	// cache counters are accessed atomically and kept first for 64-bit alignment
+	cacheHits     uint64
+	cacheMisses   uint64
 	logger        *logrus.Logger
 	apiEndpoint   string
 	httpClient    *http.Client
-	cache         map[string]*models.BreachInfo
+	cache         map[string]breachCacheEntry
 	cacheMutex    sync.RWMutex
 	cacheDuration time.Duration
+	now           func() time.Time
 	enabled       bool
+	maxRetries    int
+	retryBackoff  time.Duration
+	breaker       *CircuitBreaker
+	recorder      metrics.Recorder
+	pool          *workpool.Pool
+	offlineSHA1   *dataset.Slot
+	offlineNTLM   *dataset.Slot
+	faults        *FaultSettings
 	// HashFunc allows overriding the default hash function for testing purposes
 	HashFunc      func(string) string
 }
@@ -60,6 +88,14 @@ func WithTimeout(seconds int) BreachServiceOption {
 	}
 }
 
+// WithTransport sets the connection pool, keep-alive, and TLS session
+// resumption settings of the HIBP client
+func WithTransport(settings TransportSettings) BreachServiceOption {
+	return func(bs *BreachService) {
+		bs.httpClient.Transport = newTransport(settings)
+	}
+}
+
 // WithCacheDuration sets a custom cache duration
 func WithCacheDuration(minutes int) BreachServiceOption {
 	return func(bs *BreachService) {
@@ -67,6 +103,13 @@ func WithCacheDuration(minutes int) BreachServiceOption {
 	}
 }
 
+// WithCacheClock sets the clock cache entries expire by, e.g. to test expiry
+func WithCacheClock(now func() time.Time) BreachServiceOption {
+	return func(bs *BreachService) {
+		bs.now = now
+	}
+}
+
 // WithEnabled sets whether breach checking is enabled
 func WithEnabled(enabled bool) BreachServiceOption {
 	return func(bs *BreachService) {
@@ -74,15 +117,80 @@ func WithEnabled(enabled bool) BreachServiceOption {
 	}
 }
 
+// WithRetries retries transient HIBP failures (timeouts, 429, 502-504) up to
+// maxRetries times, waiting backoff times the attempt number in between
+func WithRetries(maxRetries int, backoff time.Duration) BreachServiceOption {
+	return func(bs *BreachService) {
+		bs.maxRetries = maxRetries
+		bs.retryBackoff = backoff
+	}
+}
+
+// WithCircuitBreaker stops calling the HIBP API for the cooldown after
+// threshold consecutive failed calls; a zero threshold disables it
+func WithCircuitBreaker(threshold int, cooldown time.Duration) BreachServiceOption {
+	return func(bs *BreachService) {
+		if threshold <= 0 {
+			bs.breaker = nil
+			return
+		}
+		bs.breaker = NewCircuitBreaker(threshold, cooldown, bs.onCircuitChange)
+	}
+}
+
+// WithMetrics records HIBP request, retry, circuit breaker, and cache metrics
+func WithMetrics(recorder metrics.Recorder) BreachServiceOption {
+	return func(bs *BreachService) {
+		bs.recorder = recorder
+	}
+}
+
+// WithWorkPool resolves the hash prefixes of bulk audits on a shared worker
+// pool instead of one after another
+func WithWorkPool(pool *workpool.Pool) BreachServiceOption {
+	return func(bs *BreachService) {
+		bs.pool = pool
+	}
+}
+
+// WithOfflineDatasets answers lookups from downloaded HIBP datasets, ordered
+// by hash with HASH:COUNT lines, instead of the range API. Either may be nil
+// to keep querying the API for that hash type.
+func WithOfflineDatasets(sha1Dataset, ntlmDataset *dataset.File) BreachServiceOption {
+	return func(bs *BreachService) {
+		if sha1Dataset != nil {
+			bs.offlineSHA1 = dataset.NewSlot(sha1Dataset)
+		}
+		if ntlmDataset != nil {
+			bs.offlineNTLM = dataset.NewSlot(ntlmDataset)
+		}
+	}
+}
+
+// WithFaultInjection makes HIBP requests time out, get rate limited, return
+// malformed bodies, or respond slowly at the given probabilities, to exercise
+// retries, the circuit breaker, and callers' failure handling in staging.
+// Never enable it in production.
+func WithFaultInjection(settings FaultSettings) BreachServiceOption {
+	return func(bs *BreachService) {
+		bs.faults = &settings
+	}
+}
+
 // NewBreachService creates a new breach service with the given options
 func NewBreachService(logger *logrus.Logger, options ...BreachServiceOption) *BreachService {
 	bs := &BreachService{
 		logger:        logger,
 		apiEndpoint:   defaultHibpAPIEndpoint,
-		httpClient:    &http.Client{Timeout: defaultRequestTimeout * time.Second},
-		cache:         make(map[string]*models.BreachInfo),
+		httpClient:    &http.Client{
+			Timeout:   defaultRequestTimeout * time.Second,
+			Transport: newTransport(DefaultTransportSettings()),
+		},
+		cache:         make(map[string]breachCacheEntry),
 		cacheDuration: defaultCacheDuration * time.Minute,
+		now:           reproducible.Now,
 		enabled:       true,
+		recorder:      metrics.Noop{},
 	}
 	
 	// Set the default hash function
@@ -93,6 +201,16 @@ func NewBreachService(logger *logrus.Logger, options ...BreachServiceOption) *Br
 		option(bs)
 	}
 
+	// Inject faults in front of whichever transport was configured
+	if bs.faults != nil {
+		bs.httpClient.Transport = &faultTransport{
+			next:     bs.httpClient.Transport,
+			settings: *bs.faults,
+			recorder: bs.recorder,
+			rand:     reproducible.NewRand(),
+		}
+	}
+
 	// Start cache cleanup goroutine
 	go bs.startCacheCleanup()
 
@@ -101,30 +219,64 @@ func NewBreachService(logger *logrus.Logger, options ...BreachServiceOption) *Br
 
 // CheckPasswordBreach checks if a password has been exposed in known data breaches
 func (bs *BreachService) CheckPasswordBreach(password string) (*models.BreachInfo, error) {
+	return bs.CheckPasswordBreachContext(context.Background(), password)
+}
+
+// CheckPasswordBreachContext checks if a password has been exposed in known data
+// breaches, logging with and propagating the request ID carried by the context
+func (bs *BreachService) CheckPasswordBreachContext(ctx context.Context, password string) (*models.BreachInfo, error) {
+	explanation := explanationFrom(ctx)
+	if explanation == nil {
+		return bs.checkPasswordBreach(ctx, password)
+	}
+
+	start := time.Now()
+	info, err := bs.checkPasswordBreach(ctx, password)
+	output := map[string]interface{}{}
+	if err != nil {
+		output["error"] = err.Error()
+	} else {
+		output["found"], output["breach_count"] = info.Found, info.BreachCount
+	}
+	explanation.record("breach", start, map[string]interface{}{"enabled": bs.enabled}, output)
+	return info, err
+}
+
+// checkPasswordBreach is CheckPasswordBreachContext without the explanation
+func (bs *BreachService) checkPasswordBreach(ctx context.Context, password string) (*models.BreachInfo, error) {
+	logger := loggerFor(ctx, bs.logger)
+
 	// If breach checking is disabled, return not found
 	if !bs.enabled {
-		bs.logger.Info("Breach detection is disabled")
+		logger.Info("Breach detection is disabled")
 		return &models.BreachInfo{Found: false}, nil
 	}
 
 	// Hash the password with SHA-1
 	sha1Hash := bs.HashPassword(password)
+
+	// Offline datasets are complete, so they need neither the API nor the cache
+	if offline := bs.offlineDataset(sha1Kind); offline != nil {
+		logger.Debug("Checking breach status in offline dataset")
+		return lookupOffline(offline, sha1Hash), nil
+	}
 	
 	// Check if result is in cache
 	cachedResult := bs.getFromCache(sha1Hash)
 	if cachedResult != nil {
-		bs.logger.Debug("Breach result found in cache")
+		logger.Debug("Breach result found in cache")
 		return cachedResult, nil
 	}
+	bs.recordCacheBypass(ctx, "miss")
 
 	// Split hash for k-anonymity (first 5 chars used as API request, rest used for comparison)
 	prefix := sha1Hash[:5]
 	suffix := strings.ToUpper(sha1Hash[5:])
 
-	bs.logger.Debugf("Checking breach status for hash prefix: %s", prefix)
+	logger.Debug("Checking breach status upstream")
 
 	// Call HIBP API with the hash prefix
-	resp, err := bs.callHIBPAPI(prefix)
+	resp, err := bs.callHIBPAPI(ctx, prefix)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +291,7 @@ func (bs *BreachService) CheckPasswordBreach(password string) (*models.BreachInf
 	}
 	
 	if found {
-		result.LastBreached = time.Now().Format("2006-01-02")
+		result.LastBreached = bs.now().Format("2006-01-02")
 	}
 
 	// Add to cache
@@ -148,6 +300,161 @@ func (bs *BreachService) CheckPasswordBreach(password string) (*models.BreachInf
 	return result, nil
 }
 
+// IsEnabled reports whether breach checking is enabled
+func (bs *BreachService) IsEnabled() bool {
+	return bs.enabled
+}
+
+// hashKind describes a hash type the HIBP range API can be queried with
+type hashKind struct {
+	length      int
+	rangeQuery  string
+	cachePrefix string
+	invalid     string
+}
+
+var (
+	sha1Kind = hashKind{length: sha1.Size * 2, invalid: "hash must be a 40-character hexadecimal SHA-1 digest"}
+	ntlmKind = hashKind{length: 32, rangeQuery: "?mode=ntlm", cachePrefix: "ntlm:", invalid: "hash must be a 32-character hexadecimal NTLM digest"}
+)
+
+// CheckHashesBreach checks a batch of SHA-1 password hashes against known data
+// breaches. Hashes sharing a 5-character prefix are resolved with a single range
+// request, and no plaintext password is ever involved.
+func (bs *BreachService) CheckHashesBreach(ctx context.Context, hashes []string) []models.BreachAuditResult {
+	return bs.checkHashes(ctx, hashes, sha1Kind)
+}
+
+// CheckNTLMHashesBreach checks a batch of NTLM password hashes, as found in
+// Windows credential dumps, against known data breaches
+func (bs *BreachService) CheckNTLMHashesBreach(ctx context.Context, hashes []string) []models.BreachAuditResult {
+	return bs.checkHashes(ctx, hashes, ntlmKind)
+}
+
+// checkHashes checks a batch of hashes of one kind against known data breaches
+func (bs *BreachService) checkHashes(ctx context.Context, hashes []string, kind hashKind) []models.BreachAuditResult {
+	results := make([]models.BreachAuditResult, len(hashes))
+	pending := make(map[string][]int)
+
+	for i, hash := range hashes {
+		normalized := strings.ToLower(strings.TrimSpace(hash))
+		results[i].Hash = normalized
+
+		if !isHex(normalized, kind.length) {
+			results[i].Error = kind.invalid
+			continue
+		}
+
+		if offline := bs.offlineDataset(kind); offline != nil {
+			breachInfo := lookupOffline(offline, normalized)
+			results[i].Found = breachInfo.Found
+			results[i].BreachCount = breachInfo.BreachCount
+			continue
+		}
+
+		if cachedResult := bs.getFromCache(kind.cachePrefix + normalized); cachedResult != nil {
+			results[i].Found = cachedResult.Found
+			results[i].BreachCount = cachedResult.BreachCount
+			continue
+		}
+		bs.recordCacheBypass(ctx, "miss")
+
+		prefix := normalized[:5]
+		pending[prefix] = append(pending[prefix], i)
+	}
+
+	if bs.pool == nil {
+		for prefix, indexes := range pending {
+			bs.resolvePrefix(ctx, prefix, indexes, results, kind)
+		}
+		return results
+	}
+
+	// Range requests run on the shared pool; each one writes only the results
+	// of its own hashes
+	prefixes := make([]string, 0, len(pending))
+	for prefix := range pending {
+		prefixes = append(prefixes, prefix)
+	}
+	resolved := make([]bool, len(prefixes))
+	err := bs.pool.Run(ctx, len(prefixes), func(ctx context.Context, n int) {
+		bs.resolvePrefix(ctx, prefixes[n], pending[prefixes[n]], results, kind)
+		resolved[n] = true
+	})
+	if err != nil {
+		for n, prefix := range prefixes {
+			if !resolved[n] {
+				for _, i := range pending[prefix] {
+					results[i].Error = err.Error()
+				}
+			}
+		}
+	}
+	return results
+}
+
+// resolvePrefix looks up the range of a hash prefix and fills in the results
+// of the hashes at indexes
+func (bs *BreachService) resolvePrefix(ctx context.Context, prefix string, indexes []int, results []models.BreachAuditResult, kind hashKind) {
+	resp, err := bs.callHIBPAPI(ctx, prefix+kind.rangeQuery)
+	if err != nil {
+		for _, i := range indexes {
+			results[i].Error = err.Error()
+		}
+		return
+	}
+
+	counts := bs.parseHIBPRange(resp)
+	for _, i := range indexes {
+		suffix := strings.ToUpper(results[i].Hash[5:])
+		count, found := counts[suffix]
+		results[i].Found = found
+		results[i].BreachCount = count
+
+		breachInfo := &models.BreachInfo{Found: found, BreachCount: count}
+		if found {
+			breachInfo.LastBreached = bs.now().Format("2006-01-02")
+		}
+		bs.addToCache(kind.cachePrefix+results[i].Hash, breachInfo)
+	}
+}
+
+// OfflineDatasets returns the slots of the offline SHA-1 and NTLM datasets,
+// nil for those not configured, whose versions can be swapped while lookups run
+func (bs *BreachService) OfflineDatasets() (sha1Dataset, ntlmDataset *dataset.Slot) {
+	return bs.offlineSHA1, bs.offlineNTLM
+}
+
+// offlineDataset returns the offline dataset of a hash kind, if one is configured
+func (bs *BreachService) offlineDataset(kind hashKind) *dataset.Slot {
+	if kind == ntlmKind {
+		return bs.offlineNTLM
+	}
+	return bs.offlineSHA1
+}
+
+// lookupOffline looks up a hash in an offline dataset, whose hashes are
+// uppercase like those of the range API
+func lookupOffline(offline *dataset.Slot, hash string) *models.BreachInfo {
+	file, release := offline.Acquire()
+	value, found := file.Lookup(strings.ToUpper(hash))
+	release()
+	if !found {
+		return &models.BreachInfo{Found: false}
+	}
+	count, _ := strconv.Atoi(strings.TrimSpace(value))
+	return &models.BreachInfo{Found: true, BreachCount: count}
+}
+
+// isHex reports whether the value is a hexadecimal digest of the given length
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
 // HashPassword uses the service's hash function to create a SHA-1 hash
 // This is a public method that can be used by tests
 func (bs *BreachService) HashPassword(password string) string {
@@ -162,59 +469,194 @@ func (bs *BreachService) DefaultHashPassword(password string) string {
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-// callHIBPAPI makes a request to the HIBP password range API
-func (bs *BreachService) callHIBPAPI(hashPrefix string) (string, error) {
+// Ping checks that the HIBP API is reachable by requesting a single range
+func (bs *BreachService) Ping(ctx context.Context) error {
+	bs.recordCacheBypass(ctx, "health_probe")
+	_, err := bs.callHIBPAPI(ctx, "00000")
+	return err
+}
+
+// callHIBPAPI makes a request to the HIBP password range API, retrying
+// transient failures and respecting the circuit breaker. The hash prefix may
+// carry a range query such as "?mode=ntlm".
+func (bs *BreachService) callHIBPAPI(ctx context.Context, hashPrefix string) (string, error) {
+	logger := loggerFor(ctx, bs.logger)
+
+	if bs.breaker != nil && !bs.breaker.Allow() {
+		bs.recorder.Count("hibp_requests", 1, metrics.Tags{"status": "circuit_open"})
+		logger.Debug("HIBP request skipped: circuit breaker is open")
+		return "", errors.ErrBreachCircuitOpen()
+	}
+
+	var body string
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retryable bool
+		body, retryable, err = bs.requestHIBPRange(ctx, hashPrefix)
+		if err == nil || !retryable || attempt >= bs.maxRetries {
+			break
+		}
+
+		bs.recorder.Count("hibp_retries", 1, nil)
+		logger.WithFields(logrus.Fields{
+			"component": "hibp",
+			"attempt":   attempt + 1,
+		}).Warnf("Retrying HIBP request: %v", err)
+
+		timer := time.NewTimer(bs.retryBackoff * time.Duration(attempt+1))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err = errors.ErrBreachTimeout(ctx.Err())
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if bs.breaker != nil {
+		switch {
+		case err == nil:
+			bs.breaker.Success()
+		case ctx.Err() != nil:
+			// The caller gave up, which says nothing about the API's health
+			bs.breaker.Release()
+		default:
+			bs.breaker.Failure()
+		}
+	}
+
+	return body, err
+}
+
+// requestHIBPRange makes a single request to the HIBP range API and reports
+// whether a failure is transient and worth retrying
+func (bs *BreachService) requestHIBPRange(ctx context.Context, hashPrefix string) (string, bool, error) {
+	logger := loggerFor(ctx, bs.logger).WithField("component", "hibp")
+
 	// Construct URL with hash prefix
 	url := fmt.Sprintf("%s/%s", bs.apiEndpoint, hashPrefix)
 	
 	// Create request
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		bs.logger.Errorf("Error creating request: %v", err)
-		return "", fmt.Errorf("error creating request: %w", err)
+		logger.Errorf("Error creating request: %v", err)
+		return "", false, fmt.Errorf("error creating request: %w", err)
 	}
 	
 	// Set headers
 	req.Header.Add("User-Agent", "Password-Config-Service")
 	req.Header.Add("Accept", "text/plain")
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
 	
 	// Execute request
+	start := time.Now()
 	resp, err := bs.httpClient.Do(req)
 	if err != nil {
-		bs.logger.Errorf("Error calling HIBP API: %v", err)
-		
 		// Handle specific error types
 		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			return "", errors.ErrBreachTimeout(err)
+			bs.recordHIBPRequest(logger, "timeout", time.Since(start), 0)
+			logger.Errorf("HIBP API request timed out: %v", err)
+			return "", true, errors.ErrBreachTimeout(err)
 		}
-		
-		return "", errors.ErrBreachAPIUnavailable(err)
+
+		bs.recordHIBPRequest(logger, "error", time.Since(start), 0)
+		logger.Errorf("Error calling HIBP API: %v", err)
+		return "", ctx.Err() == nil, errors.ErrBreachAPIUnavailable(err)
 	}
 	defer resp.Body.Close()
 	
+	// Read response body
+	body, readErr := io.ReadAll(resp.Body)
+	bs.recordHIBPRequest(logger, strconv.Itoa(resp.StatusCode), time.Since(start), len(body))
+
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		bs.logger.Errorf("HIBP API returned non-OK status: %d", resp.StatusCode)
+		logger.WithField("status", resp.StatusCode).Errorf("HIBP API returned non-OK status: %d", resp.StatusCode)
 		
 		// Handle specific status codes
 		switch resp.StatusCode {
 		case http.StatusTooManyRequests:
-			return "", errors.ErrBreachRateLimited(fmt.Errorf("status code: %d", resp.StatusCode))
+			return "", true, errors.ErrBreachRateLimited(fmt.Errorf("status code: %d", resp.StatusCode))
 		case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
-			return "", errors.ErrBreachAPIUnavailable(fmt.Errorf("status code: %d", resp.StatusCode))
+			return "", true, errors.ErrBreachAPIUnavailable(fmt.Errorf("status code: %d", resp.StatusCode))
 		default:
-			return "", errors.ErrBreachInvalidResponse(fmt.Errorf("status code: %d", resp.StatusCode))
+			return "", false, errors.ErrBreachInvalidResponse(fmt.Errorf("status code: %d", resp.StatusCode))
 		}
 	}
 	
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		bs.logger.Errorf("Error reading response: %v", err)
-		return "", errors.ErrBreachInvalidResponse(err)
+	if readErr != nil {
+		logger.Errorf("Error reading response: %v", readErr)
+		return "", true, errors.ErrBreachInvalidResponse(readErr)
 	}
 	
-	return string(body), nil
+	return string(body), false, nil
+}
+
+// recordHIBPRequest records the metrics and debug log line for a single HIBP request
+func (bs *BreachService) recordHIBPRequest(logger *logrus.Entry, status string, duration time.Duration, bytes int) {
+	tags := metrics.Tags{"status": status}
+	bs.recorder.Count("hibp_requests", 1, tags)
+	bs.recorder.Timing("hibp_request_duration", duration, tags)
+	if bytes > 0 {
+		bs.recorder.Count("hibp_response_bytes", int64(bytes), nil)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"status":   status,
+		"duration": duration,
+		"bytes":    bytes,
+	}).Debug("HIBP request completed")
+}
+
+// recordCacheBypass records why a breach lookup went upstream instead of using the cache
+func (bs *BreachService) recordCacheBypass(ctx context.Context, reason string) {
+	bs.recorder.Count("hibp_cache_bypass", 1, metrics.Tags{"reason": reason})
+	loggerFor(ctx, bs.logger).WithFields(logrus.Fields{
+		"component": "hibp",
+		"reason":    reason,
+	}).Debug("Breach cache bypassed")
+}
+
+// onCircuitChange records circuit breaker transitions
+func (bs *BreachService) onCircuitChange(from, to CircuitState) {
+	bs.recorder.Count("hibp_circuit_transitions", 1, metrics.Tags{"from": string(from), "to": string(to)})
+	bs.recorder.Gauge("hibp_circuit_open", circuitOpenGauge(to), nil)
+
+	entry := bs.logger.WithFields(logrus.Fields{
+		"component":  "hibp",
+		"from_state": string(from),
+		"to_state":   string(to),
+	})
+	if to == CircuitOpen {
+		entry.Warn("HIBP circuit breaker opened")
+	} else {
+		entry.Info("HIBP circuit breaker state changed")
+	}
+}
+
+// circuitOpenGauge returns 1 for an open circuit, 0.5 for half-open, and 0 for closed
+func circuitOpenGauge(state CircuitState) float64 {
+	switch state {
+	case CircuitOpen:
+		return 1
+	case CircuitHalfOpen:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// CircuitState returns the state of the HIBP circuit breaker; it is always
+// closed when the breaker is disabled
+func (bs *BreachService) CircuitState() CircuitState {
+	if bs.breaker == nil {
+		return CircuitClosed
+	}
+	return bs.breaker.State()
 }
 
 // parseHIBPResponse parses the HIBP API response and looks for the suffix
@@ -245,38 +687,107 @@ func (bs *BreachService) parseHIBPResponse(response string, suffix string) (int,
 	return 0, false
 }
 
-// getFromCache retrieves breach info from cache if it exists
+// parseHIBPRange parses a full HIBP range response into breach counts keyed by suffix
+func (bs *BreachService) parseHIBPRange(response string) map[string]int {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(response))
+
+	for scanner.Scan() {
+		parts := strings.Split(scanner.Text(), ":")
+		if len(parts) != 2 {
+			continue
+		}
+
+		count, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			bs.logger.Warnf("Error parsing breach count: %v", err)
+		}
+		counts[strings.ToUpper(strings.TrimSpace(parts[0]))] = count
+	}
+
+	return counts
+}
+
+// getFromCache retrieves breach info from cache if it exists and has not
+// expired. Expired entries are treated as missing until the sweep removes them.
 func (bs *BreachService) getFromCache(passwordHash string) *models.BreachInfo {
 	bs.cacheMutex.RLock()
 	defer bs.cacheMutex.RUnlock()
-	
-	return bs.cache[passwordHash]
+
+	entry, ok := bs.cache[passwordHash]
+	if ok && bs.now().Before(entry.expiresAt) {
+		atomic.AddUint64(&bs.cacheHits, 1)
+		return entry.info
+	}
+	atomic.AddUint64(&bs.cacheMisses, 1)
+	return nil
 }
 
-// addToCache adds breach info to the cache
+// addToCache adds breach info to the cache; it expires one cache duration
+// after it was added
 func (bs *BreachService) addToCache(passwordHash string, breachInfo *models.BreachInfo) {
 	bs.cacheMutex.Lock()
 	defer bs.cacheMutex.Unlock()
-	
-	bs.cache[passwordHash] = breachInfo
+
+	bs.cache[passwordHash] = breachCacheEntry{info: breachInfo, expiresAt: bs.now().Add(bs.cacheDuration)}
 }
 
-// startCacheCleanup periodically cleans up the cache
+// startCacheCleanup periodically removes expired entries. Entries expire
+// individually, so the cache never empties at once and the upstream API does
+// not see a burst of misses after each sweep.
 func (bs *BreachService) startCacheCleanup() {
-	ticker := time.NewTicker(bs.cacheDuration)
+	interval := bs.cacheDuration
+	if interval <= 0 || interval > maxCacheSweepInterval {
+		interval = maxCacheSweepInterval
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
-	
+
 	for {
 		<-ticker.C
-		bs.cleanCache()
+		bs.ExpireCache()
+	}
+}
+
+// CacheStats returns statistics about the breach result cache
+func (bs *BreachService) CacheStats() models.CacheStats {
+	bs.cacheMutex.RLock()
+	entries := len(bs.cache)
+	bs.cacheMutex.RUnlock()
+
+	return models.CacheStats{
+		Entries:         entries,
+		Hits:            atomic.LoadUint64(&bs.cacheHits),
+		Misses:          atomic.LoadUint64(&bs.cacheMisses),
+		DurationSeconds: int(bs.cacheDuration.Seconds()),
 	}
 }
 
-// cleanCache removes old entries from the cache
-func (bs *BreachService) cleanCache() {
+// FlushCache removes all entries from the cache and returns how many were removed
+func (bs *BreachService) FlushCache() int {
 	bs.cacheMutex.Lock()
 	defer bs.cacheMutex.Unlock()
-	
-	bs.logger.Debug("Cleaning breach cache")
-	bs.cache = make(map[string]*models.BreachInfo)
+
+	removed := len(bs.cache)
+	bs.cache = make(map[string]breachCacheEntry)
+	bs.logger.Infof("Breach cache flushed: %d entries removed", removed)
+	return removed
+}
+
+// ExpireCache removes the entries older than the cache duration and returns
+// how many were removed. It runs periodically in the background.
+func (bs *BreachService) ExpireCache() int {
+	bs.cacheMutex.Lock()
+	defer bs.cacheMutex.Unlock()
+
+	now := bs.now()
+	removed := 0
+	for hash, entry := range bs.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(bs.cache, hash)
+			removed++
+		}
+	}
+	bs.logger.Debugf("Breach cache sweep: %d expired entries removed, %d remaining", removed, len(bs.cache))
+	return removed
 }
\ No newline at end of file
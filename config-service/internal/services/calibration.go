@@ -0,0 +1,198 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"config-service/internal/models"
+)
+
+// Calibration corpus labels
+const (
+	// LabelCracked marks a password known to have been cracked
+	LabelCracked = "cracked"
+	// LabelUncracked marks a password that withstood cracking
+	LabelUncracked = "uncracked"
+)
+
+// CalibrationSample is a labeled password of a calibration corpus
+type CalibrationSample struct {
+	Password string
+	Cracked  bool
+}
+
+// LoadCalibrationCorpus reads a labeled corpus with one password per line,
+// preceded by its label, cracked or uncracked, and a tab. Blank lines and
+// lines starting with # are skipped; the password is taken verbatim.
+func LoadCalibrationCorpus(path string) ([]CalibrationSample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calibration corpus: %w", err)
+	}
+	defer file.Close()
+
+	var samples []CalibrationSample
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		label, password, ok := strings.Cut(text, "\t")
+		if !ok || password == "" {
+			return nil, fmt.Errorf("calibration corpus %s line %d: expected a label, a tab, and a password", path, line)
+		}
+		switch strings.ToLower(strings.TrimSpace(label)) {
+		case LabelCracked:
+			samples = append(samples, CalibrationSample{Password: password, Cracked: true})
+		case LabelUncracked:
+			samples = append(samples, CalibrationSample{Password: password})
+		default:
+			return nil, fmt.Errorf("calibration corpus %s line %d: invalid label %q (must be %s or %s)", path, line, label, LabelCracked, LabelUncracked)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calibration corpus: %w", err)
+	}
+	return samples, nil
+}
+
+// ErrCalibrationCorpus is returned for a corpus lacking cracked or uncracked
+// passwords, against which scores cannot be calibrated
+var ErrCalibrationCorpus = errors.New("calibration corpus needs both cracked and uncracked passwords")
+
+// scoredSample is a corpus password with its score and strength
+type scoredSample struct {
+	CalibrationSample
+	score    int
+	strength models.PasswordStrength
+	warnings []string
+}
+
+// Calibrate scores a labeled corpus with the service as configured and
+// reports how well the scores separate cracked from uncracked passwords. At
+// most examples misclassified passwords of each label are listed, the worst
+// first.
+func (s *PasswordService) Calibrate(ctx context.Context, samples []CalibrationSample, examples int) (*models.CalibrationReport, error) {
+	scored := make([]scoredSample, 0, len(samples))
+	report := &models.CalibrationReport{GeneratedAt: time.Now().UTC(), Samples: len(samples), Misclassified: []models.CalibrationExample{}}
+	for _, sample := range samples {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		response := s.ScorePasswordContext(ctx, sample.Password)
+		scored = append(scored, scoredSample{
+			CalibrationSample: sample,
+			score:             response.Score,
+			strength:          response.Strength,
+			warnings:          append([]string(nil), response.Feedback.Warnings...),
+		})
+		ReleaseResponse(response)
+		if sample.Cracked {
+			report.Cracked++
+		} else {
+			report.Uncracked++
+		}
+	}
+	if report.Cracked == 0 || report.Uncracked == 0 {
+		return nil, ErrCalibrationCorpus
+	}
+
+	report.ROC, report.AUC = rocCurve(scored, report.Cracked, report.Uncracked)
+	report.Strengths = strengthCalibration(scored)
+
+	var crackedStrong, uncrackedWeak []scoredSample
+	for _, sample := range scored {
+		switch {
+		case sample.Cracked && sample.strength.AtLeast(models.StrengthStrong):
+			crackedStrong = append(crackedStrong, sample)
+		case !sample.Cracked && sample.strength == models.StrengthWeak:
+			uncrackedWeak = append(uncrackedWeak, sample)
+		}
+	}
+	report.CrackedRatedStrong, report.UncrackedRatedWeak = len(crackedStrong), len(uncrackedWeak)
+	sort.SliceStable(crackedStrong, func(i, j int) bool { return crackedStrong[i].score > crackedStrong[j].score })
+	sort.SliceStable(uncrackedWeak, func(i, j int) bool { return uncrackedWeak[i].score < uncrackedWeak[j].score })
+	for _, group := range [][]scoredSample{crackedStrong, uncrackedWeak} {
+		if len(group) > examples {
+			group = group[:examples]
+		}
+		for _, sample := range group {
+			report.Misclassified = append(report.Misclassified, models.CalibrationExample{
+				Password: sample.Password,
+				Cracked:  sample.Cracked,
+				Strength: sample.strength,
+				Score:    sample.score,
+				Warnings: sample.warnings,
+			})
+		}
+	}
+	return report, nil
+}
+
+// rocCurve returns the ROC curve of the scores, one point per distinct score
+// with passwords at or below it predicted cracked, and the area under it
+func rocCurve(scored []scoredSample, cracked, uncracked int) ([]models.ROCPoint, float64) {
+	sorted := append([]scoredSample(nil), scored...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].score < sorted[j].score })
+
+	var points []models.ROCPoint
+	var truePositives, falsePositives int
+	var auc, lastTPR, lastFPR float64
+	for i, sample := range sorted {
+		if sample.Cracked {
+			truePositives++
+		} else {
+			falsePositives++
+		}
+		if i+1 < len(sorted) && sorted[i+1].score == sample.score {
+			continue
+		}
+		point := models.ROCPoint{
+			Score:             sample.score,
+			TruePositiveRate:  float64(truePositives) / float64(cracked),
+			FalsePositiveRate: float64(falsePositives) / float64(uncracked),
+		}
+		auc += (point.FalsePositiveRate - lastFPR) * (point.TruePositiveRate + lastTPR) / 2
+		lastTPR, lastFPR = point.TruePositiveRate, point.FalsePositiveRate
+		points = append(points, point)
+	}
+	return points, auc
+}
+
+// strengthCalibration counts the cracked and uncracked passwords of each
+// strength, weakest first
+func strengthCalibration(scored []scoredSample) []models.StrengthCalibration {
+	levels := []models.PasswordStrength{models.StrengthWeak, models.StrengthMedium, models.StrengthStrong, models.StrengthVeryStrong}
+	counts := make(map[models.PasswordStrength]*models.StrengthCalibration, len(levels))
+	result := make([]models.StrengthCalibration, len(levels))
+	for i, level := range levels {
+		result[i].Strength = level
+		counts[level] = &result[i]
+	}
+	for _, sample := range scored {
+		count, ok := counts[sample.strength]
+		if !ok {
+			continue
+		}
+		if sample.Cracked {
+			count.Cracked++
+		} else {
+			count.Uncracked++
+		}
+	}
+	for i := range result {
+		if total := result[i].Cracked + result[i].Uncracked; total > 0 {
+			result[i].CrackedRate = float64(result[i].Cracked) / float64(total)
+		}
+	}
+	return result
+}
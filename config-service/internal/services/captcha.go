@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CaptchaVerifier verifies a CAPTCHA response token presented by a client
+type CaptchaVerifier interface {
+	VerifyCaptcha(ctx context.Context, token, clientIP string) (bool, error)
+}
+
+// SiteVerifyCaptcha verifies tokens against a reCAPTCHA/hCaptcha/Turnstile
+// compatible "siteverify" endpoint
+type SiteVerifyCaptcha struct {
+	verifyURL  string
+	httpClient *http.Client
+
+	mu     sync.RWMutex
+	secret string
+}
+
+// NewSiteVerifyCaptcha creates a verifier for the siteverify endpoint
+func NewSiteVerifyCaptcha(verifyURL, secret string, timeout time.Duration) *SiteVerifyCaptcha {
+	return &SiteVerifyCaptcha{
+		verifyURL:  verifyURL,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// SetSecret replaces the siteverify secret used by later verifications
+func (v *SiteVerifyCaptcha) SetSecret(secret string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secret = secret
+}
+
+// VerifyCaptcha reports whether the provider accepted the token
+func (v *SiteVerifyCaptcha) VerifyCaptcha(ctx context.Context, token, clientIP string) (bool, error) {
+	v.mu.RLock()
+	secret := v.secret
+	v.mu.RUnlock()
+
+	form := url.Values{
+		"secret":   {secret},
+		"response": {token},
+		"remoteip": {clientIP},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verification returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode captcha verification response: %w", err)
+	}
+
+	return result.Success, nil
+}
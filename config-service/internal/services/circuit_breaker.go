@@ -0,0 +1,123 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed lets all calls through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects all calls until the cooldown has passed
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single trial call through to test recovery
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitBreaker stops calls to a failing dependency. It opens after a number
+// of consecutive failures, rejects calls for a cooldown period, and then lets a
+// single trial call through: success closes it again, failure reopens it.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(from, to CircuitState)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker creates a circuit breaker that opens after threshold
+// consecutive failures. onChange, which may be nil, is called on every state
+// transition while the breaker's lock is held, so it must not call back into
+// the breaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration, onChange func(from, to CircuitState)) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		onChange:  onChange,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be
+// followed by Success, Failure, or Release.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.transition(CircuitHalfOpen)
+		b.trial = true
+		return true
+	case CircuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a successful call
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+	if b.state != CircuitClosed {
+		b.transition(CircuitClosed)
+	}
+}
+
+// Failure records a failed call
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.transition(CircuitOpen)
+	}
+}
+
+// Release records a call that ended without telling whether the dependency
+// works, e.g. because the caller gave up
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// transition changes the state and notifies the listener
+func (b *CircuitBreaker) transition(to CircuitState) {
+	from := b.state
+	b.state = to
+	if b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/dataset"
+	"config-service/internal/models"
+)
+
+// datasetReloadHistory is how many finished reloads are kept for status requests
+const datasetReloadHistory = 50
+
+var (
+	// ErrDatasetNotFound is returned when reloading a dataset that is not configured
+	ErrDatasetNotFound = errors.New("dataset not found")
+
+	// ErrDatasetReloadNotFound is returned when a reload is unknown or was dropped from the history
+	ErrDatasetReloadNotFound = errors.New("dataset reload not found")
+
+	// ErrDatasetReloadInProgress is returned when reloading a dataset that is still being reloaded
+	ErrDatasetReloadInProgress = errors.New("a reload of this dataset is in progress")
+)
+
+// ReloadableDataset loads a new version of a dataset and switches lookups to
+// it. On success it returns functions that either release the previous
+// version or switch back to it; on error the previous version stays in use.
+type ReloadableDataset func() (commit, rollback func(), err error)
+
+// DatasetReloader reloads datasets from disk without a restart. A new version
+// is loaded and verified in the background while lookups keep using the
+// current one, then switched to at once. When a check configured with
+// WithReloadCheck fails after the switch, the previous version is restored.
+type DatasetReloader struct {
+	logger   *logrus.Logger
+	datasets map[string]ReloadableDataset
+	check    func(ctx context.Context) error
+
+	reloads map[string]*models.DatasetReload
+	order   []string
+	mutex   sync.Mutex
+}
+
+// DatasetReloaderOption defines functional options for configuring the DatasetReloader
+type DatasetReloaderOption func(*DatasetReloader)
+
+// WithReloadCheck runs check after every switch to a new version, such as the
+// scoring self-test; an error switches back to the previous version
+func WithReloadCheck(check func(ctx context.Context) error) DatasetReloaderOption {
+	return func(r *DatasetReloader) {
+		r.check = check
+	}
+}
+
+// NewDatasetReloader creates a dataset reloader without datasets
+func NewDatasetReloader(logger *logrus.Logger, options ...DatasetReloaderOption) *DatasetReloader {
+	r := &DatasetReloader{
+		logger:   logger,
+		datasets: make(map[string]ReloadableDataset),
+		reloads:  make(map[string]*models.DatasetReload),
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// Register makes a dataset reloadable by name
+func (r *DatasetReloader) Register(name string, reload ReloadableDataset) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.datasets[name] = reload
+}
+
+// Datasets returns the names of the reloadable datasets, sorted
+func (r *DatasetReloader) Datasets() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	names := make([]string, 0, len(r.datasets))
+	for name := range r.datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start reloads a dataset in the background and returns the reload tracking it
+func (r *DatasetReloader) Start(name string) (models.DatasetReload, error) {
+	id, err := randomToken(12)
+	if err != nil {
+		return models.DatasetReload{}, fmt.Errorf("failed to generate reload id: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	load, ok := r.datasets[name]
+	if !ok {
+		return models.DatasetReload{}, ErrDatasetNotFound
+	}
+	for _, other := range r.reloads {
+		if other.Dataset == name && other.Status == models.DatasetReloadLoading {
+			return models.DatasetReload{}, ErrDatasetReloadInProgress
+		}
+	}
+	reload := &models.DatasetReload{
+		ID:        "rld_" + id,
+		Dataset:   name,
+		Status:    models.DatasetReloadLoading,
+		StartedAt: time.Now().UTC(),
+	}
+	r.reloads[reload.ID] = reload
+	r.order = append(r.order, reload.ID)
+
+	go r.run(reload.ID, name, load)
+	return *reload, nil
+}
+
+// run loads the new version, switches to it, and checks it
+func (r *DatasetReloader) run(id, name string, load ReloadableDataset) {
+	logger := r.logger.WithField("dataset", name)
+	status := models.DatasetReloadCompleted
+
+	commit, rollback, err := load()
+	if err != nil {
+		status = models.DatasetReloadFailed
+		logger.WithError(err).Warn("Dataset reload failed; the previous version stays in use")
+	} else if err = r.runCheck(); err != nil {
+		rollback()
+		status = models.DatasetReloadRolledBack
+		logger.WithError(err).Warn("Dataset reload rolled back after failing its check")
+	} else {
+		commit()
+		logger.Info("Dataset reloaded")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now().UTC()
+	reload := r.reloads[id]
+	reload.Status = status
+	reload.CompletedAt = &now
+	if err != nil {
+		reload.Error = err.Error()
+	}
+	r.prune()
+}
+
+// runCheck runs the check configured with WithReloadCheck, if any
+func (r *DatasetReloader) runCheck() error {
+	if r.check == nil {
+		return nil
+	}
+	return r.check(context.Background())
+}
+
+// prune drops the oldest finished reloads past the history; callers hold the lock
+func (r *DatasetReloader) prune() {
+	finished := 0
+	for _, id := range r.order {
+		if r.reloads[id].CompletedAt != nil {
+			finished++
+		}
+	}
+	kept := r.order[:0]
+	for _, id := range r.order {
+		if finished > datasetReloadHistory && r.reloads[id].CompletedAt != nil {
+			delete(r.reloads, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	r.order = kept
+}
+
+// Get returns a reload by ID
+func (r *DatasetReloader) Get(id string) (models.DatasetReload, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	reload, ok := r.reloads[id]
+	if !ok {
+		return models.DatasetReload{}, ErrDatasetReloadNotFound
+	}
+	return *reload, nil
+}
+
+// List returns the reloads in progress and the latest finished ones, most
+// recently started first
+func (r *DatasetReloader) List() []models.DatasetReload {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	reloads := make([]models.DatasetReload, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		reloads = append(reloads, *r.reloads[r.order[i]])
+	}
+	return reloads
+}
+
+// SlotDataset reloads the files of slots from their paths with open, which
+// verifies them, switching all slots together: when one file fails to load,
+// none is switched
+func SlotDataset(open func(path string) (*dataset.File, error), slots ...*dataset.Slot) ReloadableDataset {
+	return func() (func(), func(), error) {
+		next := make([]*dataset.File, 0, len(slots))
+		for _, slot := range slots {
+			file, err := open(slot.Path())
+			if err != nil {
+				for _, opened := range next {
+					opened.Close()
+				}
+				return nil, nil, err
+			}
+			next = append(next, file)
+		}
+
+		previous := make([]*dataset.File, len(slots))
+		for i, slot := range slots {
+			previous[i] = slot.Swap(next[i])
+		}
+		commit := func() {
+			for i, slot := range slots {
+				slot.Retire(previous[i])
+			}
+		}
+		rollback := func() {
+			for i, slot := range slots {
+				slot.Swap(previous[i])
+				slot.Retire(next[i])
+			}
+		}
+		return commit, rollback, nil
+	}
+}
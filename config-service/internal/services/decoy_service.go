@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/requestid"
+)
+
+// decoyDigestPrefix marks a decoy given as the hex SHA-256 digest of the
+// password rather than the password itself
+const decoyDigestPrefix = "sha256:"
+
+// Decoy is a planted canary password, identified by its label and kept only
+// as its SHA-256 digest
+type Decoy struct {
+	Label  string
+	Digest [sha256.Size]byte
+}
+
+// LoadDecoys reads decoy passwords, one per line as a label, a tab, and the
+// password or "sha256:" and the hex digest of the password. A line without a
+// tab is a password labeled by its line number. Blank lines and lines starting
+// with # are skipped; passwords are taken verbatim.
+func LoadDecoys(path string) ([]Decoy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decoy file: %w", err)
+	}
+	defer file.Close()
+
+	var decoys []Decoy
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		decoy := Decoy{Label: "line " + strconv.Itoa(line)}
+		secret := text
+		if label, rest, ok := strings.Cut(text, "\t"); ok {
+			decoy.Label, secret = strings.TrimSpace(label), rest
+		}
+		if secret == "" || decoy.Label == "" {
+			return nil, fmt.Errorf("decoy file %s line %d: expected a label, a tab, and a password", path, line)
+		}
+		if strings.HasPrefix(secret, decoyDigestPrefix) {
+			digest, err := hex.DecodeString(strings.TrimPrefix(secret, decoyDigestPrefix))
+			if err != nil || len(digest) != sha256.Size {
+				return nil, fmt.Errorf("decoy file %s line %d: invalid SHA-256 digest", path, line)
+			}
+			copy(decoy.Digest[:], digest)
+		} else {
+			decoy.Digest = sha256.Sum256([]byte(secret))
+		}
+		decoys = append(decoys, decoy)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read decoy file: %w", err)
+	}
+	return decoys, nil
+}
+
+// DecoyService recognizes decoy passwords of a canary-credential program.
+// Checking a decoy is answered like any other check, but raises a security
+// alert: an error log entry, the decoy_matches metric, and a decoy-matched
+// event naming the decoy by its label, never by its password.
+type DecoyService struct {
+	logger   *logrus.Logger
+	decoys   map[[sha256.Size]byte]string
+	events   EventPublisher
+	recorder metrics.Recorder
+}
+
+// DecoyOption configures a DecoyService
+type DecoyOption func(*DecoyService)
+
+// WithDecoyWebhooks publishes a decoy-matched event to webhooks for every
+// match; a nil service publishes none
+func WithDecoyWebhooks(webhooks *WebhookService) DecoyOption {
+	return func(s *DecoyService) {
+		s.events = nil
+		if webhooks != nil {
+			s.events = webhooks
+		}
+	}
+}
+
+// WithDecoyEvents publishes a decoy-matched event for every match
+func WithDecoyEvents(events EventPublisher) DecoyOption {
+	return func(s *DecoyService) {
+		s.events = events
+	}
+}
+
+// WithDecoyMetrics counts matches in the decoy_matches metric by decoy
+func WithDecoyMetrics(recorder metrics.Recorder) DecoyOption {
+	return func(s *DecoyService) {
+		s.recorder = recorder
+	}
+}
+
+// NewDecoyService creates a service recognizing the given decoys
+func NewDecoyService(logger *logrus.Logger, decoys []Decoy, options ...DecoyOption) *DecoyService {
+	s := &DecoyService{
+		logger:   logger,
+		decoys:   make(map[[sha256.Size]byte]string, len(decoys)),
+		recorder: metrics.Noop{},
+	}
+	for _, decoy := range decoys {
+		s.decoys[decoy.Digest] = decoy.Label
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Len returns the number of decoys
+func (s *DecoyService) Len() int {
+	return len(s.decoys)
+}
+
+// Check reports whether a password is a decoy, raising the alert if it is.
+// The alert carries the tenant, route, and request ID from the context.
+func (s *DecoyService) Check(ctx context.Context, password string) bool {
+	label, found := s.decoys[sha256.Sum256([]byte(password))]
+	if !found {
+		return false
+	}
+
+	logger := loggerFor(ctx, s.logger)
+	logger.WithFields(logrus.Fields{"decoy": label, "event": models.WebhookEventDecoyMatched}).Error("Decoy password checked")
+	s.recorder.Count("decoy_matches", 1, metrics.Tags{"decoy": label})
+
+	if s.events != nil {
+		data, _ := json.Marshal(models.DecoyMatch{Decoy: label, Severity: models.SeverityCritical})
+		tenant, _ := logger.Data["tenant"].(string)
+		route, _ := logger.Data["route"].(string)
+		s.events.Publish(models.WebhookEvent{
+			Type:      models.WebhookEventDecoyMatched,
+			Tenant:    tenant,
+			RequestID: requestid.FromContext(ctx),
+			Route:     route,
+			Data:      data,
+		})
+	}
+	return true
+}
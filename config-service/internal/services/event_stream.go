@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/queue"
+	"config-service/internal/reproducible"
+)
+
+// Event stream publishing results reported in metrics
+const (
+	eventResultPublished = "published"
+	eventResultRetried   = "retried"
+	eventResultFailed    = "failed"
+	eventResultDropped   = "dropped"
+)
+
+// EventTypePlaceholder in an event stream topic is replaced by the event type
+const EventTypePlaceholder = "{type}"
+
+// defaultEventRetryBackoff is the delay before an event is first published
+// again, and maxEventRetryBackoff caps the doubling delay between attempts
+const (
+	defaultEventRetryBackoff = time.Second
+	maxEventRetryBackoff     = time.Minute
+)
+
+// EventStream publishes events to a topic of a streaming platform such as
+// NATS JetStream or Kafka. Events are queued and published in order in the
+// background, so requests never wait for the brokers, and events that do not
+// fit in the queue are dropped. With at-least-once delivery, an event the
+// brokers do not confirm is published again, reconnecting with a growing
+// delay, until they do; with at-most-once delivery it is dropped. Events are
+// keyed by tenant, so a tenant's events stay in order on partitioned topics.
+type EventStream struct {
+	logger       *logrus.Logger
+	open         func() (queue.Publisher, error)
+	recorder     metrics.Recorder
+	topic        string
+	types        map[string]bool
+	delivery     queue.Delivery
+	retryBackoff time.Duration
+	now          func() time.Time
+	queue        chan models.WebhookEvent
+}
+
+// EventStreamOption configures an EventStream
+type EventStreamOption func(*EventStream)
+
+// WithEventTopic sets the topic events are published to, in which
+// EventTypePlaceholder is replaced by the event type
+func WithEventTopic(topic string) EventStreamOption {
+	return func(s *EventStream) {
+		s.topic = topic
+	}
+}
+
+// WithEventTypes publishes only events of types; all events when empty
+func WithEventTypes(types []string) EventStreamOption {
+	return func(s *EventStream) {
+		s.types = nil
+		if len(types) > 0 {
+			s.types = make(map[string]bool, len(types))
+			for _, eventType := range types {
+				s.types[eventType] = true
+			}
+		}
+	}
+}
+
+// WithEventDelivery sets whether unconfirmed events are published again
+func WithEventDelivery(delivery queue.Delivery) EventStreamOption {
+	return func(s *EventStream) {
+		s.delivery = delivery
+	}
+}
+
+// WithEventBuffer sets how many events may wait to be published
+func WithEventBuffer(size int) EventStreamOption {
+	return func(s *EventStream) {
+		if size > 0 {
+			s.queue = make(chan models.WebhookEvent, size)
+		}
+	}
+}
+
+// WithEventRetryBackoff sets the delay before an event is published again,
+// which doubles for each further attempt up to a minute. A delay that is not
+// positive keeps the default of a second, since retrying at once would spin
+// while the brokers are down.
+func WithEventRetryBackoff(backoff time.Duration) EventStreamOption {
+	return func(s *EventStream) {
+		if backoff > 0 {
+			s.retryBackoff = backoff
+		}
+		if s.retryBackoff > maxEventRetryBackoff {
+			s.retryBackoff = maxEventRetryBackoff
+		}
+	}
+}
+
+// WithEventMetrics records publishing results and durations
+func WithEventMetrics(recorder metrics.Recorder) EventStreamOption {
+	return func(s *EventStream) {
+		s.recorder = recorder
+	}
+}
+
+// ValidateEventTypes reports the first of types that is not an event type
+func ValidateEventTypes(types []string) error {
+	for _, eventType := range types {
+		if !containsString(models.WebhookEventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q (must be one of %s)", eventType, strings.Join(models.WebhookEventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// NewEventStream creates an event stream that connects to its brokers with
+// open. Events are published once Run is called.
+func NewEventStream(logger *logrus.Logger, open func() (queue.Publisher, error), options ...EventStreamOption) *EventStream {
+	s := &EventStream{
+		logger:       logger,
+		open:         open,
+		recorder:     metrics.Noop{},
+		topic:        "passwords.events",
+		delivery:     queue.AtLeastOnce,
+		retryBackoff: defaultEventRetryBackoff,
+		now:          time.Now,
+		queue:        make(chan models.WebhookEvent, 1000),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Publish queues an event without waiting for the brokers. Events of types
+// not streamed are ignored, and those that do not fit in the queue dropped.
+func (s *EventStream) Publish(event models.WebhookEvent) {
+	if s.types != nil && !s.types[event.Type] {
+		return
+	}
+	event, err := stampEvent(event, s.now())
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate event id")
+		return
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		s.recorder.Count("events_published", 1, metrics.Tags{"event": event.Type, "result": eventResultDropped})
+		s.logger.WithField("event", event.Type).Warn("Event stream queue is full; event dropped")
+	}
+}
+
+// Run publishes queued events until the context is done. Events still
+// queued then are not published.
+func (s *EventStream) Run(ctx context.Context) {
+	var publisher queue.Publisher
+	defer func() {
+		if publisher != nil {
+			publisher.Close()
+		}
+		if pending := len(s.queue); pending > 0 {
+			s.logger.WithField("count", pending).Warn("Events not published before shutdown")
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.queue:
+			publisher = s.send(ctx, publisher, event)
+		}
+	}
+}
+
+// send publishes an event, connecting first when there is no publisher, and
+// returns the publisher to use for the next event. A publisher that failed is
+// closed, so that the next attempt reconnects.
+func (s *EventStream) send(ctx context.Context, publisher queue.Publisher, event models.WebhookEvent) queue.Publisher {
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.logger.WithError(err).WithField("event", event.Type).Error("Failed to encode event")
+		return publisher
+	}
+	topic := strings.ReplaceAll(s.topic, EventTypePlaceholder, event.Type)
+	logger := s.logger.WithFields(logrus.Fields{"event": event.Type, "topic": topic})
+
+	backoff := s.retryBackoff
+	for {
+		if publisher == nil {
+			publisher, err = s.open()
+		}
+		if err == nil {
+			started := time.Now()
+			err = publisher.Publish(ctx, topic, event.Tenant, event.ID, data)
+			s.recorder.Timing("event_publish_duration", time.Since(started), metrics.Tags{"event": event.Type})
+			if err == nil {
+				s.recorder.Count("events_published", 1, metrics.Tags{"event": event.Type, "result": eventResultPublished})
+				return publisher
+			}
+			publisher.Close()
+			publisher = nil
+		}
+
+		if s.delivery == queue.AtMostOnce || ctx.Err() != nil {
+			s.recorder.Count("events_published", 1, metrics.Tags{"event": event.Type, "result": eventResultFailed})
+			logger.WithError(err).Error("Failed to publish event; event dropped")
+			return nil
+		}
+		s.recorder.Count("events_published", 1, metrics.Tags{"event": event.Type, "result": eventResultRetried})
+		delay := jitterEventRetry(backoff)
+		logger.WithError(err).Warnf("Failed to publish event; retrying in %s", delay)
+
+		select {
+		case <-ctx.Done():
+			s.recorder.Count("events_published", 1, metrics.Tags{"event": event.Type, "result": eventResultFailed})
+			return nil
+		case <-time.After(delay):
+		}
+		if backoff *= 2; backoff > maxEventRetryBackoff {
+			backoff = maxEventRetryBackoff
+		}
+	}
+}
+
+// jitterEventRetry spreads a retry delay over its upper half, so instances
+// that lost the brokers together do not all reconnect at once
+func jitterEventRetry(backoff time.Duration) time.Duration {
+	return backoff/2 + time.Duration(reproducible.Float64()*float64(backoff-backoff/2))
+}
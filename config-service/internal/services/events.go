@@ -0,0 +1,42 @@
+package services
+
+import (
+	"time"
+
+	"config-service/internal/models"
+)
+
+// EventPublisher publishes breach, policy, quota, and decoy events without
+// waiting for them to be delivered. The webhook service and event streams
+// are publishers.
+type EventPublisher interface {
+	Publish(event models.WebhookEvent)
+}
+
+// EventPublishers publishes each event to every publisher, with the same ID,
+// so that receivers of several of them can tell it is the same event
+type EventPublishers []EventPublisher
+
+// Publish hands the event to every publisher
+func (p EventPublishers) Publish(event models.WebhookEvent) {
+	// Should no ID be generated, each publisher tries to assign its own
+	event, _ = stampEvent(event, time.Now())
+	for _, publisher := range p {
+		publisher.Publish(event)
+	}
+}
+
+// stampEvent assigns an ID and occurrence time to an event without them
+func stampEvent(event models.WebhookEvent, now time.Time) (models.WebhookEvent, error) {
+	if event.ID == "" {
+		id, err := randomToken(12)
+		if err != nil {
+			return event, err
+		}
+		event.ID = "evt_" + id
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now.UTC()
+	}
+	return event, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"config-service/internal/models"
+)
+
+// Explanation collects the steps of a check run in explain mode: the rules
+// and matchers run, with what they were given and found, and their timing.
+// Steps run concurrently, such as the breach lookup and rule plugins, may be
+// recorded from other goroutines. Decoy checks are never recorded, so that
+// traces do not reveal decoys.
+type Explanation struct {
+	mutex sync.Mutex
+	steps []explainedStep
+}
+
+// explainedStep is a recorded step and when it started
+type explainedStep struct {
+	step  models.ExplainStep
+	start time.Time
+}
+
+// explanationKey is the type of the context key holding the explanation
+type explanationKey struct{}
+
+// ContextWithExplanation returns a copy of the context whose checks record
+// their steps in explanation
+func ContextWithExplanation(ctx context.Context, explanation *Explanation) context.Context {
+	return context.WithValue(ctx, explanationKey{}, explanation)
+}
+
+// explanationFrom returns the explanation carried by the context, or nil
+// when the check is not explained
+func explanationFrom(ctx context.Context) *Explanation {
+	explanation, _ := ctx.Value(explanationKey{}).(*Explanation)
+	return explanation
+}
+
+// Steps returns the steps recorded, in the order they started
+func (e *Explanation) Steps() []models.ExplainStep {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	sort.SliceStable(e.steps, func(i, j int) bool {
+		return e.steps[i].start.Before(e.steps[j].start)
+	})
+	steps := make([]models.ExplainStep, len(e.steps))
+	for i, step := range e.steps {
+		steps[i] = step.step
+	}
+	return steps
+}
+
+// record adds a step that started at start and ends now. Callers check for
+// an explanation before building the input and output, so that unexplained
+// checks do not allocate them.
+func (e *Explanation) record(step string, start time.Time, input, output map[string]interface{}) {
+	e.add(step, start, time.Since(start), input, output)
+}
+
+// add adds a step that started at start and took duration
+func (e *Explanation) add(step string, start time.Time, duration time.Duration, input, output map[string]interface{}) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.steps = append(e.steps, explainedStep{
+		step: models.ExplainStep{
+			Step:       step,
+			Input:      input,
+			Output:     output,
+			DurationNS: duration.Nanoseconds(),
+		},
+		start: start,
+	})
+}
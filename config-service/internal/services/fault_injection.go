@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"config-service/internal/metrics"
+)
+
+// Faults injected into HIBP requests
+const (
+	FaultTimeout   = "timeout"
+	FaultRateLimit = "rate_limit"
+	FaultMalformed = "malformed"
+	FaultSlow      = "slow"
+)
+
+// malformedBody stands in for a broken HIBP response, like the error page of
+// a proxy served with status 200
+const malformedBody = "<html><body><h1>502 Bad Gateway</h1></body></html>\n0018A45C4D1DEF81644B54AB7F969B88D6\n"
+
+// FaultSettings are the probabilities, from 0 to 1, with which HIBP requests
+// fail in each way. At most one fault is injected per request, so the
+// probabilities should add up to at most 1.
+type FaultSettings struct {
+	// Timeout fails the request as timed out, without calling the API
+	Timeout float64
+	// RateLimit answers with 429 Too Many Requests, without calling the API
+	RateLimit float64
+	// Malformed answers with status 200 and a body that is not a range
+	Malformed float64
+	// Slow delays the request by SlowDelay before calling the API
+	Slow      float64
+	SlowDelay time.Duration
+}
+
+// faultTransport injects faults into the requests of an HTTP client
+type faultTransport struct {
+	next     http.RoundTripper
+	settings FaultSettings
+	recorder metrics.Recorder
+
+	randMutex sync.Mutex
+	rand      *rand.Rand
+}
+
+// faultTimeoutError is the error of a request failed as timed out
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "injected fault: request timed out" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
+
+// RoundTrip injects at most one fault, chosen at random, into the request
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := t.pick()
+	if fault != "" {
+		t.recorder.Count("hibp_faults_injected", 1, metrics.Tags{"fault": fault})
+	}
+
+	switch fault {
+	case FaultTimeout:
+		return nil, faultTimeoutError{}
+	case FaultRateLimit:
+		response := fakeResponse(req, http.StatusTooManyRequests, "Rate limit exceeded")
+		response.Header.Set("Retry-After", "1")
+		return response, nil
+	case FaultMalformed:
+		return fakeResponse(req, http.StatusOK, malformedBody), nil
+	case FaultSlow:
+		if err := sleepContext(req.Context(), t.settings.SlowDelay); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// pick chooses the fault to inject, if any
+func (t *faultTransport) pick() string {
+	t.randMutex.Lock()
+	roll := t.rand.Float64()
+	t.randMutex.Unlock()
+
+	for _, fault := range []struct {
+		name        string
+		probability float64
+	}{
+		{FaultTimeout, t.settings.Timeout},
+		{FaultRateLimit, t.settings.RateLimit},
+		{FaultMalformed, t.settings.Malformed},
+		{FaultSlow, t.settings.Slow},
+	} {
+		if roll < fault.probability {
+			return fault.name
+		}
+		roll -= fault.probability
+	}
+	return ""
+}
+
+// fakeResponse creates a response to the request without calling the API
+func fakeResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/plain"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// sleepContext waits for the duration unless the context is done first
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
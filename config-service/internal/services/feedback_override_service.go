@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/i18n"
+	"config-service/internal/models"
+)
+
+// ErrFeedbackOverridesNotFound is returned when a tenant has no feedback overrides
+var ErrFeedbackOverridesNotFound = fmt.Errorf("feedback overrides not found")
+
+// FeedbackOverrideService keeps the copy tenants use instead of the default
+// feedback warnings and suggestions, so that white-label products control
+// the exact text shown to their users
+type FeedbackOverrideService struct {
+	logger  *logrus.Logger
+	tenants map[string]*tenantFeedback
+	mutex   sync.RWMutex
+}
+
+// tenantFeedback holds the overrides of a tenant, indexed for lookups by code
+// and locale, with "" for the overrides of every locale
+type tenantFeedback struct {
+	overrides models.FeedbackOverrides
+	messages  map[models.FeedbackCode]map[string]string
+}
+
+// NewFeedbackOverrideService creates a feedback override service without overrides
+func NewFeedbackOverrideService(logger *logrus.Logger) *FeedbackOverrideService {
+	return &FeedbackOverrideService{
+		logger:  logger,
+		tenants: make(map[string]*tenantFeedback),
+	}
+}
+
+// Set replaces the feedback overrides of a tenant. Unknown codes, unsupported
+// locales, and a code overridden twice for a locale are reported together.
+func (s *FeedbackOverrideService) Set(tenant string, overrides []models.FeedbackOverride) (models.FeedbackOverrides, error) {
+	var problems []apperrors.ValidationError
+	messages := make(map[models.FeedbackCode]map[string]string)
+	for i, override := range overrides {
+		field := fmt.Sprintf("overrides[%d]", i)
+		switch {
+		case !override.Code.Valid():
+			problems = append(problems, apperrors.NewValidationErrorWithCode(field+".code", apperrors.ErrorCodeInvalidInput,
+				fmt.Sprintf("unknown feedback code %q", override.Code)))
+		case override.Locale != "" && !i18n.IsSupported(override.Locale):
+			problems = append(problems, apperrors.NewValidationErrorWithCode(field+".locale", apperrors.ErrorCodeInvalidInput,
+				fmt.Sprintf("unsupported locale %q", override.Locale)))
+		case messages[override.Code][override.Locale] != "":
+			problems = append(problems, apperrors.NewValidationErrorWithCode(field, apperrors.ErrorCodeInvalidInput,
+				fmt.Sprintf("%s is overridden more than once for this locale", override.Code)))
+		default:
+			if messages[override.Code] == nil {
+				messages[override.Code] = make(map[string]string)
+			}
+			messages[override.Code][override.Locale] = override.Message
+		}
+	}
+	if len(problems) > 0 {
+		return models.FeedbackOverrides{}, apperrors.NewValidationErrors(problems)
+	}
+
+	stored := models.FeedbackOverrides{
+		Tenant:    tenant,
+		Overrides: append([]models.FeedbackOverride{}, overrides...),
+		UpdatedAt: time.Now().UTC(),
+	}
+	sort.SliceStable(stored.Overrides, func(i, j int) bool {
+		if stored.Overrides[i].Code != stored.Overrides[j].Code {
+			return stored.Overrides[i].Code < stored.Overrides[j].Code
+		}
+		return stored.Overrides[i].Locale < stored.Overrides[j].Locale
+	})
+
+	s.mutex.Lock()
+	s.tenants[tenant] = &tenantFeedback{overrides: stored, messages: messages}
+	s.mutex.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"tenant": tenant,
+		"count":  len(overrides),
+	}).Info("Feedback overrides updated")
+	return stored, nil
+}
+
+// Get returns the feedback overrides of a tenant
+func (s *FeedbackOverrideService) Get(tenant string) (models.FeedbackOverrides, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	feedback, ok := s.tenants[tenant]
+	if !ok {
+		return models.FeedbackOverrides{}, ErrFeedbackOverridesNotFound
+	}
+	return feedback.overrides, nil
+}
+
+// Delete removes the feedback overrides of a tenant, restoring the default
+// copy, and reports whether it had any
+func (s *FeedbackOverrideService) Delete(tenant string) bool {
+	s.mutex.Lock()
+	_, ok := s.tenants[tenant]
+	delete(s.tenants, tenant)
+	s.mutex.Unlock()
+
+	if ok {
+		s.logger.WithField("tenant", tenant).Info("Feedback overrides removed")
+	}
+	return ok
+}
+
+// Message returns the tenant's copy of an English feedback message in a
+// locale: its override for the locale, else its override for every locale.
+// It reports false when the tenant does not override the message.
+func (s *FeedbackOverrideService) Message(tenant, locale, message string) (string, bool) {
+	code, ok := models.FeedbackCodeOf(message)
+	if !ok {
+		return "", false
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	feedback, ok := s.tenants[tenant]
+	if !ok {
+		return "", false
+	}
+	if override, ok := feedback.messages[code][locale]; ok {
+		return override, true
+	}
+	override, ok := feedback.messages[code][""]
+	return override, ok
+}
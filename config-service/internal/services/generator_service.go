@@ -0,0 +1,279 @@
+package services
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+	"config-service/internal/reproducible"
+	"config-service/pkg/strength"
+)
+
+// defaultGeneratedLength is the length of generated passwords unless configured
+const defaultGeneratedLength = 20
+
+// Characters of generated passwords by class. The unambiguous sets leave out
+// look-alikes such as O and 0, or l, I, and 1.
+var (
+	generatorClasses = []string{
+		models.CharacterClassUppercase,
+		models.CharacterClassLowercase,
+		models.CharacterClassDigits,
+		models.CharacterClassSymbols,
+	}
+	unambiguousChars = map[string]string{
+		models.CharacterClassUppercase: "ABCDEFGHJKLMNPQRSTUVWXYZ",
+		models.CharacterClassLowercase: "abcdefghijkmnopqrstuvwxyz",
+		models.CharacterClassDigits:    "23456789",
+		models.CharacterClassSymbols:   "!#$%&*+-=?@^_~",
+	}
+	allChars = map[string]string{
+		models.CharacterClassUppercase: "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+		models.CharacterClassLowercase: "abcdefghijklmnopqrstuvwxyz",
+		models.CharacterClassDigits:    "0123456789",
+		models.CharacterClassSymbols:   "!#$%&*+-=?@^_~",
+	}
+)
+
+// Letters of pronounceable passwords, which are never ambiguous
+const (
+	pronounceableConsonants = "bcdfghjkmnprstvz"
+	pronounceableVowels     = "aeiu"
+)
+
+// GeneratorService generates random passwords and passphrases from a
+// cryptographically secure source and scores them, so that clients receive
+// a password that is ready to use
+type GeneratorService struct {
+	checker         *PasswordStrengthChecker
+	length          int
+	maxLength       int
+	passphraseWords int
+}
+
+// GeneratorOption defines functional options for configuring the GeneratorService
+type GeneratorOption func(*GeneratorService)
+
+// WithGeneratedLength sets the length of passwords generated without one
+func WithGeneratedLength(length int) GeneratorOption {
+	return func(s *GeneratorService) {
+		s.length = length
+	}
+}
+
+// WithGeneratedMaxLength sets the longest password or passphrase that can be
+// generated, which should be the longest accepted
+func WithGeneratedMaxLength(maxLength int) GeneratorOption {
+	return func(s *GeneratorService) {
+		s.maxLength = maxLength
+	}
+}
+
+// NewGeneratorService creates a generator scoring its passwords with checker
+func NewGeneratorService(checker *PasswordStrengthChecker, options ...GeneratorOption) *GeneratorService {
+	s := &GeneratorService{
+		checker:         checker,
+		length:          defaultGeneratedLength,
+		maxLength:       strength.DefaultMaxLength,
+		passphraseWords: defaultPassphraseWords,
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Generate returns a password generated as requested, with its strength.
+// Options that cannot be honored are reported as validation errors.
+func (s *GeneratorService) Generate(request models.GenerateRequest) (*models.GenerateResponse, error) {
+	length := request.Length
+	if length == 0 {
+		length = s.length
+	}
+	classes := request.Classes
+	if len(classes) == 0 {
+		classes = generatorClasses
+	}
+	classes = uniqueClasses(classes)
+
+	hasLetters := false
+	for _, class := range classes {
+		if class == models.CharacterClassUppercase || class == models.CharacterClassLowercase {
+			hasLetters = true
+		}
+	}
+	var problems []apperrors.ValidationError
+	switch {
+	case length > s.maxLength:
+		problems = append(problems, apperrors.NewValidationErrorWithCode("length", apperrors.ErrorCodeInvalidInput,
+			fmt.Sprintf("length must be at most %d", s.maxLength)))
+	case length < len(classes):
+		problems = append(problems, apperrors.NewValidationErrorWithCode("length", apperrors.ErrorCodeInvalidInput,
+			fmt.Sprintf("length must be at least %d to use every class", len(classes))))
+	}
+	if request.Pronounceable && !hasLetters {
+		problems = append(problems, apperrors.NewValidationErrorWithCode("classes", apperrors.ErrorCodeInvalidInput,
+			"pronounceable passwords need uppercase or lowercase letters"))
+	}
+	if len(problems) > 0 {
+		return nil, apperrors.NewValidationErrors(problems)
+	}
+
+	var password string
+	var err error
+	if request.Pronounceable {
+		password, err = generatePronounceable(length, classes, request.AllowAmbiguous)
+	} else {
+		password, err = generateRandom(length, classes, request.AllowAmbiguous)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scored := s.checker.CheckStrength(password)
+	return &models.GenerateResponse{
+		Password: password,
+		Strength: scored.Strength,
+		Score:    scored.Score,
+	}, nil
+}
+
+// uniqueClasses returns classes without repetitions, in order
+func uniqueClasses(classes []string) []string {
+	seen := make(map[string]bool, len(classes))
+	unique := make([]string, 0, len(classes))
+	for _, class := range classes {
+		if !seen[class] {
+			seen[class] = true
+			unique = append(unique, class)
+		}
+	}
+	return unique
+}
+
+// charsOf returns the characters of a class
+func charsOf(class string, allowAmbiguous bool) string {
+	if allowAmbiguous {
+		return allChars[class]
+	}
+	return unambiguousChars[class]
+}
+
+// generateRandom draws every character uniformly from the classes combined,
+// except that the first characters cover every class before the shuffle
+// moves them
+func generateRandom(length int, classes []string, allowAmbiguous bool) (string, error) {
+	var all strings.Builder
+	for _, class := range classes {
+		all.WriteString(charsOf(class, allowAmbiguous))
+	}
+
+	password := make([]byte, length)
+	for i := range password {
+		chars := all.String()
+		if i < len(classes) {
+			chars = charsOf(classes[i], allowAmbiguous)
+		}
+		c, err := randomIndex(len(chars))
+		if err != nil {
+			return "", err
+		}
+		password[i] = chars[c]
+	}
+	return shuffle(password)
+}
+
+// generatePronounceable alternates consonants and vowels, capitalizing one
+// letter when both cases are requested and every letter when only uppercase
+// is, and inserts one digit and one symbol at random positions when those
+// classes are requested
+func generatePronounceable(length int, classes []string, allowAmbiguous bool) (string, error) {
+	var upper, lower, digits, symbols bool
+	for _, class := range classes {
+		switch class {
+		case models.CharacterClassUppercase:
+			upper = true
+		case models.CharacterClassLowercase:
+			lower = true
+		case models.CharacterClassDigits:
+			digits = true
+		case models.CharacterClassSymbols:
+			symbols = true
+		}
+	}
+
+	var extras []byte
+	for _, extra := range []struct {
+		wanted bool
+		class  string
+	}{{digits, models.CharacterClassDigits}, {symbols, models.CharacterClassSymbols}} {
+		if !extra.wanted {
+			continue
+		}
+		chars := charsOf(extra.class, allowAmbiguous)
+		c, err := randomIndex(len(chars))
+		if err != nil {
+			return "", err
+		}
+		extras = append(extras, chars[c])
+	}
+
+	letters := make([]byte, length-len(extras))
+	for i := range letters {
+		chars := pronounceableConsonants
+		if i%2 == 1 {
+			chars = pronounceableVowels
+		}
+		c, err := randomIndex(len(chars))
+		if err != nil {
+			return "", err
+		}
+		letters[i] = chars[c]
+	}
+	switch {
+	case upper && !lower:
+		copy(letters, strings.ToUpper(string(letters)))
+	case upper:
+		i, err := randomIndex(len(letters))
+		if err != nil {
+			return "", err
+		}
+		letters[i] -= 'a' - 'A'
+	}
+
+	password := letters
+	for _, extra := range extras {
+		i, err := randomIndex(len(password) + 1)
+		if err != nil {
+			return "", err
+		}
+		password = append(password[:i], append([]byte{extra}, password[i:]...)...)
+	}
+	return string(password), nil
+}
+
+// shuffle permutes the characters of password uniformly (Fisher-Yates)
+func shuffle(password []byte) (string, error) {
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// randomIndex returns a uniformly random number in [0, n)
+func randomIndex(n int) (int, error) {
+	value, err := rand.Int(reproducible.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random number: %w", err)
+	}
+	return int(value.Int64()), nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportSettings tunes the connections of an HTTP client. Reusing
+// connections, and resuming TLS sessions when a new one is needed, avoids a
+// full TLS handshake per request, which otherwise bounds throughput.
+type TransportSettings struct {
+	// MaxIdleConnsPerHost is how many idle connections are kept for reuse
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits connections, including those in use; 0 is unlimited
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes; 0 disables
+	// keep-alive, so that every request opens a new connection
+	KeepAlive time.Duration
+	// TLSSessionCacheSize is how many TLS sessions are kept for resumption;
+	// 0 disables resumption
+	TLSSessionCacheSize int
+}
+
+// DefaultTransportSettings returns the transport settings used unless others
+// are configured
+func DefaultTransportSettings() TransportSettings {
+	return TransportSettings{
+		MaxIdleConnsPerHost: 64,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+		TLSSessionCacheSize: 64,
+	}
+}
+
+// newTransport creates an HTTP transport with the settings, otherwise matching
+// http.DefaultTransport
+func newTransport(settings TransportSettings) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: settings.KeepAlive}
+	if settings.KeepAlive <= 0 {
+		dialer.KeepAlive = -1
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          settings.MaxIdleConnsPerHost,
+		MaxIdleConnsPerHost:   settings.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       settings.MaxConnsPerHost,
+		IdleConnTimeout:       settings.IdleConnTimeout,
+		DisableKeepAlives:     settings.KeepAlive <= 0,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	if settings.TLSSessionCacheSize > 0 {
+		transport.TLSClientConfig = &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(settings.TLSSessionCacheSize),
+		}
+	}
+	return transport
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultIdempotencyTTL is how long responses are kept for replay
+	defaultIdempotencyTTL = 24 * time.Hour
+
+	// defaultIdempotencyMaxEntries bounds the responses kept for replay
+	defaultIdempotencyMaxEntries = 10000
+)
+
+// IdempotencyState is the outcome of starting a request with an idempotency key
+type IdempotencyState int
+
+const (
+	// IdempotencyNew means the key is unused; the request should be processed
+	// and then completed or abandoned
+	IdempotencyNew IdempotencyState = iota
+	// IdempotencyReplay means the request was already processed; its response
+	// should be returned again
+	IdempotencyReplay
+	// IdempotencyInProgress means a request with the key is still being processed
+	IdempotencyInProgress
+	// IdempotencyMismatch means the key was used for a different request
+	IdempotencyMismatch
+)
+
+// IdempotentResponse is a response kept for replay
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// idempotencyEntry holds the request and, once complete, the response of a key
+type idempotencyEntry struct {
+	fingerprint string
+	response    *IdempotentResponse
+	expires     time.Time
+}
+
+// IdempotencyStore remembers the responses of requests made with an
+// idempotency key, so that a retried request is answered with the original
+// response instead of being applied twice. Entries are kept in memory.
+type IdempotencyStore struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+// IdempotencyStoreOption defines functional options for configuring the IdempotencyStore
+type IdempotencyStoreOption func(*IdempotencyStore)
+
+// WithIdempotencyTTL sets how long responses are kept for replay
+func WithIdempotencyTTL(ttl time.Duration) IdempotencyStoreOption {
+	return func(s *IdempotencyStore) {
+		if ttl > 0 {
+			s.ttl = ttl
+		}
+	}
+}
+
+// WithIdempotencyMaxEntries bounds the responses kept for replay; the oldest
+// are dropped first
+func WithIdempotencyMaxEntries(max int) IdempotencyStoreOption {
+	return func(s *IdempotencyStore) {
+		if max > 0 {
+			s.maxEntries = max
+		}
+	}
+}
+
+// WithIdempotencyClock sets the clock entries expire by
+func WithIdempotencyClock(now func() time.Time) IdempotencyStoreOption {
+	return func(s *IdempotencyStore) {
+		s.now = now
+	}
+}
+
+// NewIdempotencyStore creates a new idempotency key store
+func NewIdempotencyStore(options ...IdempotencyStoreOption) *IdempotencyStore {
+	s := &IdempotencyStore{
+		ttl:        defaultIdempotencyTTL,
+		maxEntries: defaultIdempotencyMaxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*idempotencyEntry),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Begin starts a request with a key. The fingerprint identifies the request,
+// such as its method, path, and a digest of its body; reusing a key for a
+// different request is a mismatch. When the state is IdempotencyReplay, the
+// original response is returned.
+func (s *IdempotencyStore) Begin(key, fingerprint string) (IdempotencyState, *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, exists := s.entries[key]; exists && now.Before(entry.expires) {
+		switch {
+		case entry.fingerprint != fingerprint:
+			return IdempotencyMismatch, nil
+		case entry.response == nil:
+			return IdempotencyInProgress, nil
+		default:
+			return IdempotencyReplay, entry.response
+		}
+	}
+
+	s.evict(now)
+	s.entries[key] = &idempotencyEntry{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+	return IdempotencyNew, nil
+}
+
+// Complete keeps the response of a request started with Begin for replay
+func (s *IdempotencyStore) Complete(key string, response *IdempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, exists := s.entries[key]; exists {
+		entry.response = response
+	}
+}
+
+// Abandon forgets a request started with Begin, so that it can be retried,
+// as when it failed without taking effect
+func (s *IdempotencyStore) Abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+}
+
+// evict drops expired entries and, when the store is full, the entries that
+// expire first
+func (s *IdempotencyStore) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range s.entries {
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expires.Before(oldest) {
+			oldestKey, oldest = key, entry.expires
+		}
+	}
+	if len(s.entries) >= s.maxEntries && oldestKey != "" {
+		delete(s.entries, oldestKey)
+	}
+}
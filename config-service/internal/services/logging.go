@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/logging"
+)
+
+// loggerFor returns a logger entry carrying the request-scoped fields from the context
+func loggerFor(ctx context.Context, logger *logrus.Logger) *logrus.Entry {
+	return logging.FromContext(ctx, logger)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"config-service/internal/models"
+)
+
+// listItem exposes the attributes admin list endpoints sort and filter on
+type listItem interface {
+	listID() string
+	listName() string
+	listUpdatedAt() time.Time
+}
+
+// keyedItem pairs a list item with its precomputed sort key
+type keyedItem[T listItem] struct {
+	key  string
+	item T
+}
+
+// paginate filters, sorts, and pages items according to the list options.
+// Cursors are opaque encodings of the sort key of the last item returned.
+func paginate[T listItem](items []T, opts models.ListOptions) ([]T, models.PageInfo, error) {
+	keyed := make([]keyedItem[T], 0, len(items))
+	nameContains := strings.ToLower(opts.NameContains)
+	for _, item := range items {
+		if nameContains != "" && !strings.Contains(strings.ToLower(item.listName()), nameContains) {
+			continue
+		}
+		if !opts.UpdatedSince.IsZero() && item.listUpdatedAt().Before(opts.UpdatedSince) {
+			continue
+		}
+		keyed = append(keyed, keyedItem[T]{key: listSortKey(item, opts.SortBy), item: item})
+	}
+
+	sort.Slice(keyed, func(i, j int) bool {
+		if opts.Descending {
+			return keyed[i].key > keyed[j].key
+		}
+		return keyed[i].key < keyed[j].key
+	})
+
+	start := 0
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, models.PageInfo{}, err
+		}
+		start = sort.Search(len(keyed), func(i int) bool {
+			if opts.Descending {
+				return keyed[i].key < after
+			}
+			return keyed[i].key > after
+		})
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	end := start + limit
+	if end > len(keyed) {
+		end = len(keyed)
+	}
+
+	page := make([]T, 0, end-start)
+	for _, k := range keyed[start:end] {
+		page = append(page, k.item)
+	}
+
+	info := models.PageInfo{Limit: limit, Total: len(keyed)}
+	if end < len(keyed) {
+		info.NextCursor = encodeCursor(keyed[end-1].key)
+	}
+
+	return page, info, nil
+}
+
+// listSortKey builds a unique, lexically ordered key for the sort field
+func listSortKey(item listItem, sortBy string) string {
+	if sortBy == models.SortByUpdatedAt {
+		var nanos int64
+		if updatedAt := item.listUpdatedAt(); !updatedAt.IsZero() {
+			nanos = updatedAt.UnixNano()
+		}
+		return fmt.Sprintf("%020d\x00%s", nanos, item.listID())
+	}
+	return strings.ToLower(item.listName()) + "\x00" + item.listID()
+}
+
+// encodeCursor encodes a sort key as an opaque cursor
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeCursor decodes an opaque cursor into a sort key
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	return string(key), nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+)
+
+// Passphrases generated without options have six words, about 77 bits, as
+// the EFF recommends, joined by hyphens
+const (
+	defaultPassphraseWords     = 6
+	defaultPassphraseSeparator = "-"
+)
+
+// Fewest and most words of a passphrase, as bound by PassphraseRequest
+const (
+	MinPassphraseWords = 4
+	MaxPassphraseWords = 20
+)
+
+// effLargeWordlist is the EFF large wordlist for five dice, one roll and
+// word per line, from https://www.eff.org/dice (CC BY 3.0 US). Its words are
+// easy to type and remember, and none is a prefix of another.
+//
+//go:embed wordlists/eff_large_wordlist.txt
+var effLargeWordlist string
+
+// passphraseWords are the words of the wordlist in roll order, and
+// longestPassphraseWord the letters of the longest
+var passphraseWords, longestPassphraseWord = parseWordlist(effLargeWordlist)
+
+// parseWordlist returns the words of a diceware wordlist and the length of
+// the longest
+func parseWordlist(list string) ([]string, int) {
+	lines := strings.Split(strings.TrimSpace(list), "\n")
+	words := make([]string, len(lines))
+	longest := 0
+	for i, line := range lines {
+		_, word, _ := strings.Cut(line, "\t")
+		words[i] = word
+		if len(word) > longest {
+			longest = len(word)
+		}
+	}
+	return words, longest
+}
+
+// LongestPassphrase returns the most characters a passphrase of words joined
+// by separator can have
+func LongestPassphrase(words int, separator string) int {
+	return words*longestPassphraseWord + (words-1)*utf8.RuneCountInString(separator)
+}
+
+// WithPassphraseWords sets the number of words of passphrases generated
+// without one
+func WithPassphraseWords(words int) GeneratorOption {
+	return func(s *GeneratorService) {
+		s.passphraseWords = words
+	}
+}
+
+// GeneratePassphrase returns a passphrase of words drawn uniformly from the
+// EFF large wordlist, with its entropy and strength. Options that cannot be
+// honored are reported as validation errors.
+func (s *GeneratorService) GeneratePassphrase(request models.PassphraseRequest) (*models.PassphraseResponse, error) {
+	words := request.Words
+	if words == 0 {
+		words = s.passphraseWords
+	}
+	separator := defaultPassphraseSeparator
+	if request.Separator != nil {
+		separator = *request.Separator
+	}
+	capitalization := request.Capitalization
+	if capitalization == "" {
+		capitalization = models.CapitalizationLower
+	}
+
+	var problems []apperrors.ValidationError
+	if strings.IndexFunc(separator, unicode.IsLetter) >= 0 {
+		problems = append(problems, apperrors.NewValidationErrorWithCode("separator", apperrors.ErrorCodeInvalidInput,
+			"separator must not contain letters"))
+	}
+	if LongestPassphrase(words, separator) > s.maxLength {
+		problems = append(problems, apperrors.NewValidationErrorWithCode("words", apperrors.ErrorCodeInvalidInput,
+			fmt.Sprintf("%d words with this separator can be longer than %d characters", words, s.maxLength)))
+	}
+	if len(problems) > 0 {
+		return nil, apperrors.NewValidationErrors(problems)
+	}
+
+	drawn := make([]string, words)
+	for i := range drawn {
+		w, err := randomIndex(len(passphraseWords))
+		if err != nil {
+			return nil, err
+		}
+		word := passphraseWords[w]
+
+		capitalize := capitalization == models.CapitalizationTitle
+		if capitalization == models.CapitalizationRandom {
+			coin, err := randomIndex(2)
+			if err != nil {
+				return nil, err
+			}
+			capitalize = coin == 1
+		}
+		switch {
+		case capitalization == models.CapitalizationUpper:
+			word = strings.ToUpper(word)
+		case capitalize:
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		drawn[i] = word
+	}
+	passphrase := strings.Join(drawn, separator)
+
+	// Each word adds log2 of the wordlist size, and a random capital one bit
+	entropy := float64(words) * math.Log2(float64(len(passphraseWords)))
+	if capitalization == models.CapitalizationRandom {
+		entropy += float64(words)
+	}
+
+	scored := s.checker.CheckStrength(passphrase)
+	return &models.PassphraseResponse{
+		Passphrase: passphrase,
+		Entropy:    math.Round(entropy*10) / 10,
+		Strength:   scored.Strength,
+		Score:      scored.Score,
+	}, nil
+}
@@ -1,45 +1,246 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
+	"config-service/internal/dataset"
 	"config-service/internal/models"
+	"config-service/pkg/strength"
 )
 
+// bannedWordPenalty is the score penalty applied when a password contains a banned word
+const bannedWordPenalty = 30
+
+// dictionaryPenalty is the score penalty applied when a password is in a common
+// password dictionary
+const dictionaryPenalty = 40
+
 // PasswordService handles password strength checking business logic
 type PasswordService struct {
 	logger               *logrus.Logger
-	passwordValidator    models.PasswordValidator
+	passwordValidator    *strength.Validator
 	passwordStrengthChecker *PasswordStrengthChecker
+	bannedList           *BannedListService
+	rulePlugins          []RulePlugin
+	dictionaries         []*dataset.Slot
+	decoys               *DecoyService
+}
+
+// PasswordServiceOption defines functional options for configuring the PasswordService
+type PasswordServiceOption func(*PasswordService)
+
+// WithBannedList sets the banned list checked during strength evaluation
+func WithBannedList(bannedList *BannedListService) PasswordServiceOption {
+	return func(s *PasswordService) {
+		s.bannedList = bannedList
+	}
+}
+
+// WithRulePlugins sets external rule plugins consulted during strength evaluation
+func WithRulePlugins(plugins ...RulePlugin) PasswordServiceOption {
+	return func(s *PasswordService) {
+		s.rulePlugins = append(s.rulePlugins, plugins...)
+	}
+}
+
+// WithStrengthMemo reuses strength results of passwords checked again within
+// the memo's TTL. The banned list and rule plugins still apply to every check.
+func WithStrengthMemo(memo *StrengthMemo) PasswordServiceOption {
+	return func(s *PasswordService) {
+		s.passwordStrengthChecker.memo = memo
+	}
+}
+
+// WithDictionaries penalizes passwords found in common password dictionaries,
+// which hold one lowercase password per line
+func WithDictionaries(dictionaries ...*dataset.File) PasswordServiceOption {
+	return func(s *PasswordService) {
+		for _, dictionary := range dictionaries {
+			s.dictionaries = append(s.dictionaries, dataset.NewSlot(dictionary))
+		}
+	}
+}
+
+// WithMaxLength sets the longest password accepted, in characters. Longer
+// passwords fail validation, and policy evaluations do not score them.
+func WithMaxLength(maxLength int) PasswordServiceOption {
+	return func(s *PasswordService) {
+		s.passwordValidator = strength.NewValidator(strength.WithLengthLimits(strength.DefaultMinLength, maxLength))
+	}
+}
+
+// WithDecoys raises a security alert whenever a decoy password is scored. The
+// response is the same as for any other password.
+func WithDecoys(decoys *DecoyService) PasswordServiceOption {
+	return func(s *PasswordService) {
+		s.decoys = decoys
+	}
 }
 
 // NewPasswordService creates a new password service
-func NewPasswordService(logger *logrus.Logger) *PasswordService {
-	return &PasswordService{
+func NewPasswordService(logger *logrus.Logger, options ...PasswordServiceOption) *PasswordService {
+	s := &PasswordService{
 		logger:               logger,
-		passwordValidator:    models.NewPasswordValidator(),
+		passwordValidator:    strength.NewValidator(),
 		passwordStrengthChecker: NewPasswordStrengthChecker(),
 	}
+
+	// Apply options
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// MaxLength returns the longest password accepted, in characters
+func (s *PasswordService) MaxLength() int {
+	return s.passwordValidator.MaxLength()
+}
+
+// Dictionaries returns the common password dictionaries the service
+// penalizes, whose versions can be swapped while checks run
+func (s *PasswordService) Dictionaries() []*dataset.Slot {
+	return s.dictionaries
 }
 
 // CheckPasswordStrength validates and checks the strength of a password
 func (s *PasswordService) CheckPasswordStrength(password string) (*models.PasswordResponse, error) {
-	s.logger.Infof("Checking password strength for password of length %d", len(password))
+	return s.CheckPasswordStrengthContext(context.Background(), password)
+}
 
+// CheckPasswordStrengthContext validates and checks the strength of a password,
+// logging with the request ID carried by the context
+func (s *PasswordService) CheckPasswordStrengthContext(ctx context.Context, password string) (*models.PasswordResponse, error) {
 	// Validate the password first
-	if err := s.passwordValidator.Validate(password); err != nil {
-		s.logger.Warnf("Password validation failed: %v", err)
-		return nil, fmt.Errorf("password validation failed: %w", err)
+	if err := s.ValidatePasswordContext(ctx, password); err != nil {
+		return nil, err
+	}
+
+	return s.ScorePasswordContext(ctx, password), nil
+}
+
+// ValidatePasswordContext validates a password according to basic requirements,
+// logging failures with the request ID carried by the context
+func (s *PasswordService) ValidatePasswordContext(ctx context.Context, password string) error {
+	start := time.Now()
+	err := s.passwordValidator.Validate(password)
+	if explanation := explanationFrom(ctx); explanation != nil {
+		output := map[string]interface{}{"passed": err == nil}
+		if err != nil {
+			output["error"] = err.Error()
+		}
+		explanation.record("validate", start, map[string]interface{}{
+			"length":     strength.Length(password),
+			"min_length": strength.DefaultMinLength,
+			"max_length": s.MaxLength(),
+		}, output)
+	}
+	if err != nil {
+		loggerFor(ctx, s.logger).Warnf("Password validation failed: %v", err)
+		return fmt.Errorf("password validation failed: %w", err)
+	}
+	return nil
+}
+
+// ScorePasswordContext checks the strength of a password that has already been
+// validated, logging with the request ID carried by the context and
+// penalizing the key walks of the keyboard layout it carries. Callers that
+// are done with the response, such as after encoding it, may hand it back with
+// ReleaseResponse.
+func (s *PasswordService) ScorePasswordContext(ctx context.Context, password string) *models.PasswordResponse {
+	logger := loggerFor(ctx, s.logger)
+	logger.Infof("Checking password strength for password of length %d", len(password))
+
+	// Alert on decoys without changing the response
+	if s.decoys != nil {
+		s.decoys.Check(ctx, password)
 	}
 
 	// Check password strength
-	response := s.passwordStrengthChecker.CheckStrength(password)
+	explanation := explanationFrom(ctx)
+	response := responsePool.Get().(*models.PasswordResponse)
+	s.passwordStrengthChecker.checkStrengthInto(password, keyboardLayoutFrom(ctx), response, explanation)
+	response.Randomness = strength.EstimateRandomness(password)
+
+	// Penalize passwords containing banned words
+	if s.bannedList != nil {
+		start := time.Now()
+		_, found := s.bannedList.FindBannedWord(password)
+		if found {
+			applyBannedWordPenalty(response)
+		}
+		if explanation != nil {
+			explanation.record("banned_words", start, nil, penaltyOutput(found, bannedWordPenalty, response))
+		}
+	}
+
+	// Penalize passwords from common password dictionaries
+	if len(s.dictionaries) > 0 {
+		start := time.Now()
+		found := s.inDictionary(password)
+		if found {
+			applyDictionaryPenalty(response)
+		}
+		if explanation != nil {
+			explanation.record("dictionaries", start, map[string]interface{}{"dictionaries": len(s.dictionaries)},
+				penaltyOutput(found, dictionaryPenalty, response))
+		}
+	}
+
+	// Apply the verdicts of external rule plugins
+	if len(s.rulePlugins) > 0 {
+		s.applyRulePlugins(ctx, password, response)
+	}
 
-	s.logger.Infof("Password strength check completed: strength=%s, score=%d", 
+	logger.Infof("Password strength check completed: strength=%s, score=%d", 
 		response.Strength, response.Score)
 
-	return response, nil
+	return response
+}
+
+// ComposePasswordContext describes the characters of a password and the
+// parts of it that weaken it, including banned words and, for the whole
+// password, a dictionary match, so that a UI can highlight them while the
+// user types. Unlike a check it accepts passwords shorter than the minimum;
+// longer ones than the maximum fail as in validation. The keyboard layout
+// carried by the context is used as for scoring.
+func (s *PasswordService) ComposePasswordContext(ctx context.Context, password string) (models.PasswordComposition, error) {
+	if maxLength := s.MaxLength(); strength.Length(password) > maxLength {
+		err := fmt.Errorf("password must not exceed %d characters", maxLength)
+		loggerFor(ctx, s.logger).Warnf("Password validation failed: %v", err)
+		return models.PasswordComposition{}, fmt.Errorf("password validation failed: %w", err)
+	}
+
+	// Alert on decoys without changing the response
+	if s.decoys != nil {
+		s.decoys.Check(ctx, password)
+	}
+
+	composition := s.passwordStrengthChecker.Composition(password, keyboardLayoutFrom(ctx))
+	if s.bannedList != nil {
+		lower := strings.ToLower(password)
+		for _, span := range s.bannedList.FindBannedWordSpans(password) {
+			composition.Matches = append(composition.Matches, models.PasswordMatch{
+				Kind:  strength.MatchBannedWord,
+				Start: utf8.RuneCountInString(lower[:span[0]]),
+				End:   utf8.RuneCountInString(lower[:span[1]]),
+			})
+		}
+	}
+	if len(s.dictionaries) > 0 && s.inDictionary(password) {
+		composition.Matches = append(composition.Matches, models.PasswordMatch{
+			Kind: strength.MatchCommonPassword,
+			End:  composition.Length,
+		})
+	}
+	composition.Matches = strength.MergeMatches(composition.Matches)
+	return composition, nil
 }
 
 // ValidatePassword validates a password according to basic requirements
@@ -51,4 +252,48 @@ func (s *PasswordService) ValidatePassword(password string) error {
 // GetPasswordRequirements returns which basic requirements are met for a password
 func (s *PasswordService) GetPasswordRequirements(password string) models.PasswordRequirements {
 	return models.GetPasswordRequirements(password)
-}
\ No newline at end of file
+}
+
+// inDictionary reports whether a password, ignoring case, is in any dictionary
+func (s *PasswordService) inDictionary(password string) bool {
+	lower := strings.ToLower(password)
+	for _, slot := range s.dictionaries {
+		dictionary, release := slot.Acquire()
+		found := dictionary.Contains(lower)
+		release()
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// penaltyOutput describes the outcome of a penalty for an explanation
+func penaltyOutput(found bool, penalty int, response *models.PasswordResponse) map[string]interface{} {
+	if !found {
+		return map[string]interface{}{"found": false}
+	}
+	return map[string]interface{}{"found": true, "penalty": penalty, "score": response.Score}
+}
+
+// applyDictionaryPenalty lowers the score and adds feedback for a dictionary match
+func applyDictionaryPenalty(response *models.PasswordResponse) {
+	response.Score -= dictionaryPenalty
+	if response.Score < 0 {
+		response.Score = 0
+	}
+	response.Strength = models.GetStrengthCategory(response.Score)
+	response.Feedback.Warnings = append(response.Feedback.Warnings, "Password is a commonly used password")
+	response.Feedback.Suggestions = append(response.Feedback.Suggestions, "Avoid passwords that appear in common password lists")
+}
+
+// applyBannedWordPenalty lowers the score and adds feedback for a banned word match
+func applyBannedWordPenalty(response *models.PasswordResponse) {
+	response.Score -= bannedWordPenalty
+	if response.Score < 0 {
+		response.Score = 0
+	}
+	response.Strength = models.GetStrengthCategory(response.Score)
+	response.Feedback.Warnings = append(response.Feedback.Warnings, "Password contains a banned word")
+	response.Feedback.Suggestions = append(response.Feedback.Suggestions, "Avoid words from the organization's banned list")
+}
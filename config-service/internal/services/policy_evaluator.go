@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/models"
+	"config-service/pkg/strength"
+)
+
+// minIdentityLength is the shortest username checked for in passwords; shorter
+// ones would reject too many passwords by coincidence
+const minIdentityLength = 3
+
+// PolicyEvaluator evaluates a password rule by rule, so that every failed
+// rule can be reported to callers such as identity providers that present
+// policy violations in their own format
+type PolicyEvaluator struct {
+	logger         *logrus.Logger
+	passwords      *PasswordService
+	breaches       *BreachService
+	bannedList     *BannedListService
+	policy         models.PasswordPolicy
+	minStrength    models.PasswordStrength
+	rejectBreached bool
+}
+
+// PolicyEvaluatorOption defines functional options for configuring the PolicyEvaluator
+type PolicyEvaluatorOption func(*PolicyEvaluator)
+
+// WithPolicyMinStrength sets the weakest strength level accepted
+func WithPolicyMinStrength(minimum models.PasswordStrength) PolicyEvaluatorOption {
+	return func(e *PolicyEvaluator) {
+		e.minStrength = minimum
+	}
+}
+
+// WithPolicyRejectBreached sets whether passwords found in known breaches fail
+func WithPolicyRejectBreached(reject bool) PolicyEvaluatorOption {
+	return func(e *PolicyEvaluator) {
+		e.rejectBreached = reject
+	}
+}
+
+// WithPolicyRequireGenerated sets whether passwords must look machine-generated,
+// as for service accounts whose passwords no person should choose
+func WithPolicyRequireGenerated(require bool) PolicyEvaluatorOption {
+	return func(e *PolicyEvaluator) {
+		e.policy.RequireGenerated = require
+	}
+}
+
+// WithPolicyBannedList fails passwords containing a banned word
+func WithPolicyBannedList(bannedList *BannedListService) PolicyEvaluatorOption {
+	return func(e *PolicyEvaluator) {
+		e.bannedList = bannedList
+	}
+}
+
+// NewPolicyEvaluator creates a policy evaluator for the default password
+// policy, with the longest password accepted by the password service.
+// breaches may be nil to skip breach checks.
+func NewPolicyEvaluator(logger *logrus.Logger, passwords *PasswordService, breaches *BreachService, options ...PolicyEvaluatorOption) *PolicyEvaluator {
+	e := &PolicyEvaluator{
+		logger:         logger,
+		passwords:      passwords,
+		breaches:       breaches,
+		policy:         models.DefaultPasswordPolicy(),
+		minStrength:    models.StrengthMedium,
+		rejectBreached: true,
+	}
+	e.policy.MaxLength = passwords.MaxLength()
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Policy returns the password policy rules are evaluated against
+func (e *PolicyEvaluator) Policy() models.PasswordPolicy {
+	return e.policy
+}
+
+// Evaluate checks a password against every rule. identities are the
+// username, email address, or other login names of the account, which the
+// password must not contain. The breach check only runs when every other
+// rule passes, and a failed breach check is reported as a warning. Passwords
+// over the maximum length are not scored, so they also fail the strength rule.
+func (e *PolicyEvaluator) Evaluate(ctx context.Context, password string, identities ...string) *models.PolicyEvaluation {
+	rules := policyRules{
+		identities:  identities,
+		bannedList:  e.bannedList,
+		passwords:   e.passwords,
+		minStrength: e.minStrength,
+	}
+	if e.rejectBreached {
+		rules.breaches = e.breaches
+	}
+	outcome := evaluatePolicy(ctx, e.logger, e.policy, password, rules)
+	return &models.PolicyEvaluation{
+		Valid:    outcome.valid,
+		Checks:   outcome.checks,
+		Strength: outcome.strength,
+		Score:    outcome.score,
+		Warnings: outcome.warnings,
+	}
+}
+
+// policyRules are the checks an evaluation runs beyond the rules of the
+// password policy itself
+type policyRules struct {
+	// identities are login names the password must not contain
+	identities []string
+	// bannedList holds words the password must not contain
+	bannedList *BannedListService
+	// passwords scores the password for the minStrength rule; without it
+	// the strength rule is not checked
+	passwords   *PasswordService
+	minStrength models.PasswordStrength
+	// breaches checks the password against known breaches, allowing
+	// breachLimit of them
+	breaches    *BreachService
+	breachLimit int
+}
+
+// policyOutcome is the result of evaluatePolicy
+type policyOutcome struct {
+	valid       bool
+	checks      []models.PolicyCheck
+	warnings    []string
+	strength    models.PasswordStrength
+	score       int
+	entropyBits float64
+	// breach is the breach lookup the breach rule made, if any
+	breach *models.BreachInfo
+}
+
+// evaluatePolicy checks a password against every rule of a policy and those
+// of rules, the one rule engine of the identity provider webhooks and named
+// policies. The breach check only runs when every other rule passes, and a
+// failed breach check is reported as a warning. Passwords over the maximum
+// length are not analyzed, so they fail the strength, entropy, and generated
+// rules.
+func evaluatePolicy(ctx context.Context, logger *logrus.Logger, policy models.PasswordPolicy, password string, rules policyRules) *policyOutcome {
+	outcome := &policyOutcome{valid: true}
+	check := func(rule models.PolicyRule, passed bool, limit int, message string) {
+		outcome.checks = append(outcome.checks, models.PolicyCheck{Rule: rule, Passed: passed, Limit: limit, Message: message})
+		outcome.valid = outcome.valid && passed
+	}
+
+	length := strength.Length(password)
+	analyzed := length <= policy.MaxLength
+	check(models.RuleMinLength, length >= policy.MinLength, policy.MinLength,
+		fmt.Sprintf("Password must be at least %d characters long", policy.MinLength))
+	check(models.RuleMaxLength, analyzed, policy.MaxLength,
+		fmt.Sprintf("Password must not exceed %d characters", policy.MaxLength))
+
+	reqs := strength.CheckRequirements(password)
+	if policy.RequireUppercase {
+		check(models.RuleUppercase, reqs.Uppercase, 0, "Password must contain at least one uppercase letter")
+	}
+	if policy.RequireLowercase {
+		check(models.RuleLowercase, reqs.Lowercase, 0, "Password must contain at least one lowercase letter")
+	}
+	if policy.RequireNumbers {
+		check(models.RuleNumbers, reqs.Numbers, 0, "Password must contain at least one number")
+	}
+	if policy.RequireSpecial {
+		check(models.RuleSpecial, reqs.SpecialChars, 0, "Password must contain at least one special character")
+	}
+
+	if names := identityNames(rules.identities); len(names) > 0 {
+		check(models.RuleNotUsername, !containsAny(password, names), 0, "Password must not contain the username or email address")
+	}
+	if rules.bannedList != nil {
+		_, found := rules.bannedList.FindBannedWord(password)
+		check(models.RuleBannedWord, !found, 0, "Password must not contain a banned word")
+	}
+	if len(policy.BannedWords) > 0 {
+		check(models.RuleBannedWord, !containsAny(password, policy.BannedWords), 0, "Password must not contain a word banned by the policy")
+	}
+
+	// The strength score includes the randomness estimate, which is only
+	// made on its own without it
+	var randomness strength.Randomness
+	if rules.passwords != nil {
+		outcome.strength = models.StrengthWeak
+		if analyzed {
+			response := rules.passwords.ScorePasswordContext(ctx, password)
+			outcome.strength = response.Strength
+			outcome.score = response.Score
+			randomness = response.Randomness
+			ReleaseResponse(response)
+		}
+		check(models.RuleMinStrength, outcome.strength.AtLeast(rules.minStrength), 0,
+			fmt.Sprintf("Password must be at least %s", rules.minStrength))
+	} else if analyzed {
+		randomness = strength.EstimateRandomness(password)
+	}
+	outcome.entropyBits = math.Round(randomness.BitsPerChar*float64(length)*10) / 10
+	if policy.MinEntropyBits > 0 {
+		check(models.RuleMinEntropy, analyzed && outcome.entropyBits >= float64(policy.MinEntropyBits), policy.MinEntropyBits,
+			fmt.Sprintf("Password must have at least %d bits of entropy", policy.MinEntropyBits))
+	}
+	if policy.RequireGenerated {
+		check(models.RuleGenerated, randomness.Generated, 0, "Password must be generated by a password manager, not chosen by a person")
+	}
+
+	if rules.breaches != nil && rules.breaches.IsEnabled() && outcome.valid {
+		message := "Password must not appear in a known data breach"
+		if rules.breachLimit > 0 {
+			message = fmt.Sprintf("Password must not appear more than %d times in known data breaches", rules.breachLimit)
+		}
+		info, err := rules.breaches.CheckPasswordBreachContext(ctx, password)
+		if err != nil {
+			loggerFor(ctx, logger).WithError(err).Warn("Breach check failed during policy evaluation")
+			outcome.warnings = append(outcome.warnings, "breach check failed: "+err.Error())
+		} else {
+			outcome.breach = info
+			check(models.RuleNotBreached, !info.Found || info.BreachCount <= rules.breachLimit, rules.breachLimit, message)
+		}
+	}
+	return outcome
+}
+
+// identityNames returns the lowercase names to look for in a password: each
+// identity and the local part of email addresses, if long enough
+func identityNames(identities []string) []string {
+	var names []string
+	for _, identity := range identities {
+		identity = strings.ToLower(strings.TrimSpace(identity))
+		candidates := []string{identity}
+		if at := strings.Index(identity, "@"); at > 0 {
+			candidates = append(candidates, identity[:at])
+		}
+		for _, name := range candidates {
+			if len(name) >= minIdentityLength {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// containsAny reports whether the password contains one of the lowercase names,
+// ignoring case
+func containsAny(password string, names []string) bool {
+	lower := strings.ToLower(password)
+	for _, name := range names {
+		if strings.Contains(lower, name) {
+			return true
+		}
+	}
+	return false
+}
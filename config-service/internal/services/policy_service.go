@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+	"config-service/pkg/strength"
+)
+
+// ErrPolicyNotFound is returned when a password policy does not exist
+var ErrPolicyNotFound = fmt.Errorf("policy not found")
+
+// ErrPolicyReadOnly is returned when the admin API changes the built-in
+// policy or one defined in the configuration
+var ErrPolicyReadOnly = fmt.Errorf("policy is read-only: it is built in or defined in the configuration")
+
+// ErrPolicyNotDeleted is returned when restoring a policy that was not
+// deleted or whose retention has passed
+var ErrPolicyNotDeleted = fmt.Errorf("policy not deleted or past retention")
+
+// policyIDPattern matches the identifiers of named policies
+var policyIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// PolicyService provides access to the password policies in effect: the
+// built-in default, and named policies defined in the configuration or
+// through the admin API that strength checks can select
+type PolicyService struct {
+	logger    *logrus.Logger
+	breaches  *BreachService
+	maxLength int
+	now       func() time.Time
+
+	// configured are the policies of WithPolicies, added once every option
+	// is applied
+	configured []models.PasswordPolicy
+
+	mutex    sync.RWMutex
+	policies map[string]models.PasswordPolicy
+	// deleted holds policies deleted through the admin API until their
+	// retention passes, so that accidental deletions can be undone
+	deleted   map[string]models.DeletedPasswordPolicy
+	retention time.Duration
+	// version counts the changes to the policies, and listeners are told of
+	// each new one
+	version   uint64
+	listeners []func(version uint64)
+}
+
+// PolicyServiceOption defines functional options for configuring the PolicyService
+type PolicyServiceOption func(*PolicyService)
+
+// WithDefaultPolicyMaxLength sets the longest password the default policy
+// accepts, as configured for the password service, which also bounds the
+// named policies
+func WithDefaultPolicyMaxLength(maxLength int) PolicyServiceOption {
+	return func(s *PolicyService) {
+		s.maxLength = maxLength
+		policy := s.policies[models.DefaultPolicyID]
+		policy.MaxLength = maxLength
+		s.policies[models.DefaultPolicyID] = policy
+	}
+}
+
+// WithPolicies adds named policies defined in the configuration, which have
+// been validated with PolicyProblems
+func WithPolicies(policies []models.PasswordPolicy) PolicyServiceOption {
+	return func(s *PolicyService) {
+		s.configured = append(s.configured, policies...)
+	}
+}
+
+// WithPolicyBreaches checks the breach counts of policies with a
+// max_breach_count against known breaches
+func WithPolicyBreaches(breaches *BreachService) PolicyServiceOption {
+	return func(s *PolicyService) {
+		s.breaches = breaches
+	}
+}
+
+// WithDeletedPolicyRetention sets how long deleted policies can be restored
+func WithDeletedPolicyRetention(retention time.Duration) PolicyServiceOption {
+	return func(s *PolicyService) {
+		if retention > 0 {
+			s.retention = retention
+		}
+	}
+}
+
+// NewPolicyService creates a new policy service with the built-in default policy
+func NewPolicyService(logger *logrus.Logger, options ...PolicyServiceOption) *PolicyService {
+	defaultPolicy := models.DefaultPasswordPolicy()
+	defaultPolicy.UpdatedAt = time.Now().UTC()
+
+	s := &PolicyService{
+		logger:    logger,
+		maxLength: defaultPolicy.MaxLength,
+		now:       time.Now,
+		policies:  map[string]models.PasswordPolicy{defaultPolicy.ID: defaultPolicy},
+		deleted:   make(map[string]models.DeletedPasswordPolicy),
+		retention: defaultDeletedRetention,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	for _, policy := range s.configured {
+		policy = withPolicyDefaults(policy, s.maxLength)
+		policy.Source = models.PolicySourceConfig
+		policy.UpdatedAt = defaultPolicy.UpdatedAt
+		s.policies[policy.ID] = policy
+	}
+	return s
+}
+
+// List returns all policies currently in effect, by ID
+func (s *PolicyService) List() []models.PasswordPolicy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	policies := make([]models.PasswordPolicy, 0, len(s.policies))
+	for _, policy := range s.policies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+	return policies
+}
+
+// ListPage returns a page of policies matching the list options
+func (s *PolicyService) ListPage(opts models.ListOptions) ([]models.PasswordPolicy, models.PageInfo, error) {
+	s.mutex.RLock()
+	items := make([]policyListItem, 0, len(s.policies))
+	for _, policy := range s.policies {
+		items = append(items, policyListItem(policy))
+	}
+	s.mutex.RUnlock()
+
+	page, info, err := paginate(items, opts)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	policies := make([]models.PasswordPolicy, len(page))
+	for i, item := range page {
+		policies[i] = models.PasswordPolicy(item)
+	}
+	return policies, info, nil
+}
+
+// policyListItem adapts a policy for pagination
+type policyListItem models.PasswordPolicy
+
+func (p policyListItem) listID() string           { return p.ID }
+func (p policyListItem) listName() string         { return p.Name }
+func (p policyListItem) listUpdatedAt() time.Time { return p.UpdatedAt }
+
+// Get returns a policy
+func (s *PolicyService) Get(id string) (*models.PasswordPolicy, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	policy, ok := s.policies[id]
+	if !ok {
+		return nil, ErrPolicyNotFound
+	}
+	return &policy, nil
+}
+
+// Put defines or replaces a named policy through the admin API. Problems
+// with the policy are reported as validation errors, and the built-in and
+// configured policies cannot be replaced.
+func (s *PolicyService) Put(policy models.PasswordPolicy) (*models.PasswordPolicy, error) {
+	if policy.ID == models.DefaultPolicyID {
+		return nil, ErrPolicyReadOnly
+	}
+	policy = withPolicyDefaults(policy, s.maxLength)
+	if problems := PolicyProblems(policy, s.maxLength); len(problems) > 0 {
+		return nil, apperrors.NewValidationErrors(problems)
+	}
+	policy.Source = models.PolicySourceAPI
+	policy.UpdatedAt = s.now().UTC()
+
+	s.mutex.Lock()
+	if existing, ok := s.policies[policy.ID]; ok && existing.Source != models.PolicySourceAPI {
+		s.mutex.Unlock()
+		return nil, ErrPolicyReadOnly
+	}
+	s.policies[policy.ID] = policy
+	delete(s.deleted, policy.ID)
+	notify := s.changed()
+	s.mutex.Unlock()
+	notify()
+
+	s.logger.Infof("Password policy saved: id=%s", policy.ID)
+	return &policy, nil
+}
+
+// Delete removes a policy defined through the admin API on behalf of
+// deletedBy. The policy can be restored until the retention passes.
+func (s *PolicyService) Delete(id, deletedBy string) error {
+	s.mutex.Lock()
+	policy, ok := s.policies[id]
+	if !ok {
+		s.mutex.Unlock()
+		return ErrPolicyNotFound
+	}
+	if policy.Source != models.PolicySourceAPI {
+		s.mutex.Unlock()
+		return ErrPolicyReadOnly
+	}
+	now := s.now().UTC()
+	s.purge(now)
+	delete(s.policies, id)
+	s.deleted[id] = models.DeletedPasswordPolicy{
+		PasswordPolicy: policy,
+		DeletedAt:      now,
+		DeletedBy:      deletedBy,
+		PurgeAt:        now.Add(s.retention),
+	}
+	notify := s.changed()
+	s.mutex.Unlock()
+	notify()
+
+	s.logger.Infof("Password policy deleted: id=%s by=%s", id, deletedBy)
+	return nil
+}
+
+// Restore puts back a deleted policy whose retention has not passed
+func (s *PolicyService) Restore(id string) (*models.PasswordPolicy, error) {
+	s.mutex.Lock()
+	now := s.now().UTC()
+	s.purge(now)
+	entry, ok := s.deleted[id]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, ErrPolicyNotDeleted
+	}
+	policy := entry.PasswordPolicy
+	policy.UpdatedAt = now
+	delete(s.deleted, id)
+	s.policies[id] = policy
+	notify := s.changed()
+	s.mutex.Unlock()
+	notify()
+
+	s.logger.Infof("Password policy restored: id=%s", id)
+	return &policy, nil
+}
+
+// Deleted returns the deleted policies that can still be restored, most
+// recently deleted first
+func (s *PolicyService) Deleted() []models.DeletedPasswordPolicy {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.purge(s.now().UTC())
+	policies := make([]models.DeletedPasswordPolicy, 0, len(s.deleted))
+	for _, policy := range s.deleted {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if !policies[i].DeletedAt.Equal(policies[j].DeletedAt) {
+			return policies[i].DeletedAt.After(policies[j].DeletedAt)
+		}
+		return policies[i].ID < policies[j].ID
+	})
+	return policies
+}
+
+// purge drops deleted policies past their retention; callers hold the write lock
+func (s *PolicyService) purge(now time.Time) {
+	for id, entry := range s.deleted {
+		if !now.Before(entry.PurgeAt) {
+			delete(s.deleted, id)
+		}
+	}
+}
+
+// Version returns a number that grows with every change to the policies, so
+// that results computed under older policies can be recognized
+func (s *PolicyService) Version() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.version
+}
+
+// OnChange registers a function called with the new version whenever a
+// policy is saved, deleted, or restored
+func (s *PolicyService) OnChange(listener func(version uint64)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// changed counts a change to the policies; callers hold the write lock. The
+// returned function tells the listeners of the new version, and is called
+// once the lock is released, since listeners may use the service.
+func (s *PolicyService) changed() (notify func()) {
+	s.version++
+	version, listeners := s.version, s.listeners
+	return func() {
+		for _, listener := range listeners {
+			listener(version)
+		}
+	}
+}
+
+// withPolicyDefaults fills in the name and length limits a policy leaves
+// out, and normalizes its banned words
+func withPolicyDefaults(policy models.PasswordPolicy, maxLength int) models.PasswordPolicy {
+	if policy.Name == "" {
+		policy.Name = policy.ID
+	}
+	if policy.MinLength == 0 {
+		policy.MinLength = strength.DefaultMinLength
+	}
+	if policy.MaxLength == 0 {
+		policy.MaxLength = maxLength
+	}
+
+	var words []string
+	for _, word := range policy.BannedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	policy.BannedWords = uniqueStrings(words)
+	return policy
+}
+
+// PolicyProblems returns the problems of a named policy whose passwords may
+// be at most maxLength characters long. Zero lengths stand for the defaults.
+func PolicyProblems(policy models.PasswordPolicy, maxLength int) []apperrors.ValidationError {
+	var problems []apperrors.ValidationError
+	add := func(field, message string) {
+		problems = append(problems, apperrors.NewValidationErrorWithCode(field, apperrors.ErrorCodeInvalidInput, message))
+	}
+
+	switch {
+	case policy.ID == models.DefaultPolicyID:
+		add("id", "id default is reserved for the built-in policy")
+	case !policyIDPattern.MatchString(policy.ID):
+		add("id", "id must be at most 64 lowercase letters, digits, hyphens, and underscores, starting with a letter or digit")
+	}
+
+	minLength, policyMaxLength := policy.MinLength, policy.MaxLength
+	if minLength == 0 {
+		minLength = strength.DefaultMinLength
+	}
+	if policyMaxLength == 0 {
+		policyMaxLength = maxLength
+	}
+	if minLength < strength.DefaultMinLength {
+		add("min_length", fmt.Sprintf("min_length must be at least %d", strength.DefaultMinLength))
+	}
+	if policyMaxLength < minLength || policyMaxLength > maxLength {
+		add("max_length", fmt.Sprintf("max_length must be between min_length and %d", maxLength))
+	}
+
+	if policy.MaxBreachCount != nil && *policy.MaxBreachCount < 0 {
+		add("max_breach_count", "max_breach_count must not be negative")
+	}
+	if policy.MinEntropyBits < 0 {
+		add("min_entropy_bits", "min_entropy_bits must not be negative")
+	}
+	for i, word := range policy.BannedWords {
+		if strings.TrimSpace(word) == "" {
+			add(fmt.Sprintf("banned_words[%d]", i), fmt.Sprintf("banned_words[%d] must not be blank", i))
+		}
+	}
+	return problems
+}
+
+// Evaluate checks a password against every rule of a policy. The breach
+// check only runs when every other rule passes, and a failed breach check is
+// reported as a warning. Passwords over the maximum length are not analyzed,
+// so they also fail the entropy and generated rules. The breach lookup of the
+// max_breach_count rule is returned with the result, for callers to reuse.
+func (s *PolicyService) Evaluate(ctx context.Context, id, password string) (*models.PolicyResult, error) {
+	start := time.Now()
+	policy, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules policyRules
+	if policy.MaxBreachCount != nil {
+		rules.breaches, rules.breachLimit = s.breaches, *policy.MaxBreachCount
+	}
+	outcome := evaluatePolicy(ctx, s.logger, *policy, password, rules)
+	result := &models.PolicyResult{
+		PolicyID:    policy.ID,
+		Valid:       outcome.valid,
+		Checks:      outcome.checks,
+		EntropyBits: outcome.entropyBits,
+		Warnings:    outcome.warnings,
+		Breach:      outcome.breach,
+	}
+
+	if explanation := explanationFrom(ctx); explanation != nil {
+		explanation.record("policy", start, map[string]interface{}{
+			"policy_id": policy.ID, "source": policy.Source, "length": strength.Length(password),
+		}, map[string]interface{}{
+			"valid": result.Valid, "checks": result.Checks, "entropy_bits": result.EntropyBits,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/models"
+)
+
+// QueueCheckService evaluates password check requests read from a message
+// queue, producing the same results as the HTTP check endpoint
+type QueueCheckService struct {
+	logger    *logrus.Logger
+	passwords *PasswordService
+	breaches  *BreachService
+}
+
+// NewQueueCheckService creates a queue check service. breaches may be nil to
+// skip breach checks.
+func NewQueueCheckService(logger *logrus.Logger, passwords *PasswordService, breaches *BreachService) *QueueCheckService {
+	return &QueueCheckService{logger: logger, passwords: passwords, breaches: breaches}
+}
+
+// Handle evaluates an encoded QueueCheckRequest and returns the encoded
+// QueueCheckResult. Malformed and invalid requests get a result with an error,
+// so producers waiting for a reply are never left hanging.
+func (s *QueueCheckService) Handle(ctx context.Context, data []byte) []byte {
+	var request models.QueueCheckRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		s.logger.Warnf("Invalid queue check request: %v", err)
+		return s.encode(models.QueueCheckResult{Error: "invalid request format"})
+	}
+	result := s.Check(ctx, request)
+	encoded := s.encode(result)
+
+	// The response is encoded, so it can serve the next message
+	ReleaseResponse(result.Result)
+	return encoded
+}
+
+// Check evaluates a password check request
+func (s *QueueCheckService) Check(ctx context.Context, request models.QueueCheckRequest) models.QueueCheckResult {
+	result := models.QueueCheckResult{ID: request.ID}
+	if err := s.passwords.ValidatePasswordContext(ctx, request.Password); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if request.KeyboardLayout != "" {
+		layout, err := models.ParseKeyboardLayout(request.KeyboardLayout)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		ctx = ContextWithKeyboardLayout(ctx, layout)
+	}
+
+	response := s.passwords.ScorePasswordContext(ctx, request.Password)
+	if s.breaches != nil {
+		// A failed breach check leaves the breach data out, as in the HTTP API
+		if breachInfo, err := s.breaches.CheckPasswordBreachContext(ctx, request.Password); err == nil {
+			response.BreachData = breachInfo
+			if breachInfo != nil && breachInfo.Found {
+				response.Feedback.Warnings = append(response.Feedback.Warnings, "Password has appeared in data breaches")
+				response.Feedback.Suggestions = append(response.Feedback.Suggestions, "Choose a password that hasn't been compromised")
+			}
+		}
+	}
+	result.Result = response
+	return result
+}
+
+// encode marshals a result; results only hold plain values, so it cannot fail
+func (s *QueueCheckService) encode(result models.QueueCheckResult) []byte {
+	data, _ := json.Marshal(result)
+	return data
+}
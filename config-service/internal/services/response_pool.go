@@ -0,0 +1,29 @@
+package services
+
+import (
+	"sync"
+
+	"config-service/internal/models"
+)
+
+// responsePool reuses password responses, with the capacity of their feedback
+// slices, so that bulk and streaming checks do not allocate a response each
+var responsePool = sync.Pool{
+	New: func() interface{} {
+		return new(models.PasswordResponse)
+	},
+}
+
+// ReleaseResponse returns a response from ScorePasswordContext to the pool once
+// the caller is done with it. Neither the response nor its feedback slices may
+// be used afterwards; responses handed to other code must not be released.
+func ReleaseResponse(response *models.PasswordResponse) {
+	if response == nil {
+		return
+	}
+	response.BreachData = nil
+	response.Attestation = nil
+	response.Policy = nil
+	response.Explain = nil
+	responsePool.Put(response)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"config-service/internal/models"
+)
+
+// defaultReuseMaxAccounts is how many accounts of each tenant are tracked by default
+const defaultReuseMaxAccounts = 100000
+
+// ErrReuseCapacity is returned when a tenant already has the most accounts tracked
+var ErrReuseCapacity = fmt.Errorf("password reuse tracking is at capacity for this tenant")
+
+// reuseTenant holds the digests recorded for the accounts of one tenant
+type reuseTenant struct {
+	digests  map[string]string              // account -> digest
+	accounts map[string]map[string]struct{} // digest -> accounts
+}
+
+// ReuseService detects passwords shared by several accounts of a tenant. It
+// only ever sees digests keyed by the tenant, never passwords, and keeps the
+// latest digest of each account in memory.
+type ReuseService struct {
+	maxAccounts int
+
+	mu      sync.Mutex
+	tenants map[string]*reuseTenant
+}
+
+// ReuseServiceOption defines functional options for configuring the ReuseService
+type ReuseServiceOption func(*ReuseService)
+
+// WithReuseMaxAccounts sets how many accounts of each tenant are tracked
+func WithReuseMaxAccounts(max int) ReuseServiceOption {
+	return func(s *ReuseService) {
+		if max > 0 {
+			s.maxAccounts = max
+		}
+	}
+}
+
+// NewReuseService creates a new password reuse detector
+func NewReuseService(options ...ReuseServiceOption) *ReuseService {
+	s := &ReuseService{
+		maxAccounts: defaultReuseMaxAccounts,
+		tenants:     make(map[string]*reuseTenant),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Record sets the digest of an account's password and reports how many
+// accounts of the tenant share it. A new digest replaces the one recorded
+// before, as when the account changes its password.
+func (s *ReuseService) Record(tenant, account, digest string) (*models.ReuseResult, error) {
+	digest = strings.ToLower(digest)
+	if !isHex(digest, 64) {
+		return nil, fmt.Errorf("digest must be a hex HMAC-SHA256")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tenants[tenant]
+	if t == nil {
+		t = &reuseTenant{
+			digests:  make(map[string]string),
+			accounts: make(map[string]map[string]struct{}),
+		}
+		s.tenants[tenant] = t
+	}
+
+	previous, known := t.digests[account]
+	if !known && len(t.digests) >= s.maxAccounts {
+		return nil, ErrReuseCapacity
+	}
+	if known && previous != digest {
+		t.remove(account, previous)
+	}
+
+	t.digests[account] = digest
+	sharing := t.accounts[digest]
+	if sharing == nil {
+		sharing = make(map[string]struct{})
+		t.accounts[digest] = sharing
+	}
+	sharing[account] = struct{}{}
+
+	return &models.ReuseResult{Account: account, Reused: len(sharing) > 1, Accounts: len(sharing)}, nil
+}
+
+// Forget removes an account of the tenant, as when it is deleted, and reports
+// whether it was tracked
+func (s *ReuseService) Forget(tenant, account string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tenants[tenant]
+	if t == nil {
+		return false
+	}
+	digest, known := t.digests[account]
+	if !known {
+		return false
+	}
+	t.remove(account, digest)
+	delete(t.digests, account)
+	if len(t.digests) == 0 {
+		delete(s.tenants, tenant)
+	}
+	return true
+}
+
+// remove drops an account from the accounts sharing a digest
+func (t *reuseTenant) remove(account, digest string) {
+	sharing := t.accounts[digest]
+	delete(sharing, account)
+	if len(sharing) == 0 {
+		delete(t.accounts, digest)
+	}
+}
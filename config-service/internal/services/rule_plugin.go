@@ -0,0 +1,239 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/requestid"
+)
+
+const (
+	// defaultRulePluginTimeout bounds a rule plugin call
+	defaultRulePluginTimeout = 200 * time.Millisecond
+
+	// maxRulePluginResponseBytes bounds the response read from a rule plugin
+	maxRulePluginResponseBytes = 64 << 10
+
+	// rulePluginFailureWarning is added when a fail-closed plugin could not be consulted
+	rulePluginFailureWarning = "Password could not be checked against all rules"
+)
+
+// RulePlugin is a custom validation or scoring rule maintained outside this
+// service, consulted on every strength check
+type RulePlugin interface {
+	// Name identifies the plugin in logs and metrics
+	Name() string
+	// FailClosed reports whether a password is capped at weak when the plugin
+	// fails; otherwise the plugin is skipped
+	FailClosed() bool
+	// Evaluate returns the plugin's verdict on a scored password
+	Evaluate(ctx context.Context, request models.RulePluginRequest) (*models.RulePluginVerdict, error)
+}
+
+// HTTPRulePlugin is a rule plugin called over HTTP: each check POSTs a
+// RulePluginRequest as JSON and expects a RulePluginVerdict back
+type HTTPRulePlugin struct {
+	name       string
+	url        string
+	token      string
+	timeout    time.Duration
+	failClosed bool
+	client     *http.Client
+	recorder   metrics.Recorder
+}
+
+// HTTPRulePluginOption configures an HTTPRulePlugin
+type HTTPRulePluginOption func(*HTTPRulePlugin)
+
+// WithPluginToken sets a bearer token sent to the plugin
+func WithPluginToken(token string) HTTPRulePluginOption {
+	return func(p *HTTPRulePlugin) {
+		p.token = token
+	}
+}
+
+// WithPluginTimeout sets the time the plugin has to answer
+func WithPluginTimeout(timeout time.Duration) HTTPRulePluginOption {
+	return func(p *HTTPRulePlugin) {
+		if timeout > 0 {
+			p.timeout = timeout
+		}
+	}
+}
+
+// WithPluginFailClosed caps passwords at weak when the plugin fails
+func WithPluginFailClosed(failClosed bool) HTTPRulePluginOption {
+	return func(p *HTTPRulePlugin) {
+		p.failClosed = failClosed
+	}
+}
+
+// WithPluginMetrics records plugin calls and their durations
+func WithPluginMetrics(recorder metrics.Recorder) HTTPRulePluginOption {
+	return func(p *HTTPRulePlugin) {
+		p.recorder = recorder
+	}
+}
+
+// NewHTTPRulePlugin creates a rule plugin served at url
+func NewHTTPRulePlugin(name, url string, options ...HTTPRulePluginOption) *HTTPRulePlugin {
+	p := &HTTPRulePlugin{
+		name:     name,
+		url:      url,
+		timeout:  defaultRulePluginTimeout,
+		client:   &http.Client{},
+		recorder: metrics.Noop{},
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Name returns the plugin name
+func (p *HTTPRulePlugin) Name() string {
+	return p.name
+}
+
+// FailClosed reports whether failures cap passwords at weak
+func (p *HTTPRulePlugin) FailClosed() bool {
+	return p.failClosed
+}
+
+// Evaluate calls the plugin within its timeout
+func (p *HTTPRulePlugin) Evaluate(ctx context.Context, request models.RulePluginRequest) (*models.RulePluginVerdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	verdict, err := p.call(ctx, request)
+	status := "ok"
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		status = "timeout"
+	case err != nil:
+		status = "error"
+	}
+	tags := metrics.Tags{"plugin": p.name, "status": status}
+	p.recorder.Count("rule_plugin_requests", 1, tags)
+	p.recorder.Timing("rule_plugin_request_duration", time.Since(start), tags)
+	return verdict, err
+}
+
+// call sends the request and decodes the verdict
+func (p *HTTPRulePlugin) call(ctx context.Context, request models.RulePluginRequest) (*models.RulePluginVerdict, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		httpRequest.Header.Set(requestid.Header, id)
+	}
+
+	response, err := p.client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rule plugin %s returned status %d", p.name, response.StatusCode)
+	}
+
+	var verdict models.RulePluginVerdict
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxRulePluginResponseBytes)).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("rule plugin %s returned an invalid verdict: %w", p.name, err)
+	}
+	if verdict.MaxStrength != "" && !verdict.MaxStrength.Valid() {
+		return nil, fmt.Errorf("rule plugin %s returned an invalid max_strength %q", p.name, verdict.MaxStrength)
+	}
+	return &verdict, nil
+}
+
+// applyRulePlugins consults the plugins concurrently and applies their
+// verdicts to the response: score adjustments first, then strength caps
+func (s *PasswordService) applyRulePlugins(ctx context.Context, password string, response *models.PasswordResponse) {
+	request := models.RulePluginRequest{Password: password, Strength: response.Strength, Score: response.Score}
+	verdicts := make([]*models.RulePluginVerdict, len(s.rulePlugins))
+	errs := make([]error, len(s.rulePlugins))
+
+	explanation := explanationFrom(ctx)
+	var wg sync.WaitGroup
+	for i, plugin := range s.rulePlugins {
+		wg.Add(1)
+		go func(i int, plugin RulePlugin) {
+			defer wg.Done()
+			start := time.Now()
+			verdicts[i], errs[i] = plugin.Evaluate(ctx, request)
+			if explanation != nil {
+				explanation.record("rule_plugin", start, map[string]interface{}{
+					"plugin": plugin.Name(), "strength": request.Strength, "score": request.Score,
+				}, rulePluginOutput(verdicts[i], errs[i]))
+			}
+		}(i, plugin)
+	}
+	wg.Wait()
+
+	failedClosed := false
+	var caps []models.PasswordStrength
+	for i, plugin := range s.rulePlugins {
+		if errs[i] != nil {
+			loggerFor(ctx, s.logger).WithError(errs[i]).Warnf("Rule plugin %s failed", plugin.Name())
+			failedClosed = failedClosed || plugin.FailClosed()
+			continue
+		}
+		verdict := verdicts[i]
+		response.Score += verdict.ScoreAdjustment
+		if verdict.MaxStrength != "" {
+			caps = append(caps, verdict.MaxStrength)
+		}
+		response.Feedback.Warnings = append(response.Feedback.Warnings, verdict.Warnings...)
+		response.Feedback.Suggestions = append(response.Feedback.Suggestions, verdict.Suggestions...)
+	}
+	if failedClosed {
+		caps = append(caps, models.StrengthWeak)
+		response.Feedback.Warnings = append(response.Feedback.Warnings, rulePluginFailureWarning)
+	}
+
+	if response.Score < 0 {
+		response.Score = 0
+	}
+	if response.Score > 100 {
+		response.Score = 100
+	}
+	response.Strength = models.GetStrengthCategory(response.Score)
+	for _, max := range caps {
+		if response.Strength.AtLeast(max) {
+			response.Strength = max
+		}
+	}
+}
+
+// rulePluginOutput describes the verdict of a rule plugin for an
+// explanation. Its feedback is left out; it is in the response.
+func rulePluginOutput(verdict *models.RulePluginVerdict, err error) map[string]interface{} {
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	output := map[string]interface{}{"score_adjustment": verdict.ScoreAdjustment}
+	if verdict.MaxStrength != "" {
+		output["max_strength"] = verdict.MaxStrength
+	}
+	return output
+}
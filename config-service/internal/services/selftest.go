@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"config-service/internal/models"
+)
+
+// SelfTestCorpus are the reference passwords of the scoring self-test. They
+// span every strength, including well-known weak passwords, so that a change
+// of weights, penalties, or dictionaries shows up as a changed category.
+var SelfTestCorpus = []string{
+	"123456",
+	"aaaaaaaa",
+	"abcdefgh",
+	"password",
+	"qwerty123",
+	"dragon",
+	"iloveyou",
+	"hunter2",
+	"Welcome123",
+	"Password1!",
+	"letmein2024",
+	"correct horse battery staple",
+	"P@ssw0rd",
+	"Summer2024!",
+	"Tr0ub4dor&3",
+	"xK9#mQ2$vL7@nR4!",
+	"Zebra!Lantern#Quartz9",
+	"7h!s-1s-a-L0ng-P@ssphrase",
+}
+
+// SelfTestDrift is a reference password whose strength differs from the one
+// pinned for it
+type SelfTestDrift struct {
+	Password string                  `json:"password"`
+	Expected models.PasswordStrength `json:"expected"`
+	Actual   models.PasswordStrength `json:"actual"`
+	Score    int                     `json:"score"`
+}
+
+// LoadSelfTestExpectations reads pinned strengths from a JSON object mapping
+// reference passwords to their strength
+func LoadSelfTestExpectations(path string) (map[string]models.PasswordStrength, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read self-test expectations: %w", err)
+	}
+	var expectations map[string]models.PasswordStrength
+	if err := json.Unmarshal(data, &expectations); err != nil {
+		return nil, fmt.Errorf("failed to parse self-test expectations %s: %w", path, err)
+	}
+	if len(expectations) == 0 {
+		return nil, fmt.Errorf("self-test expectations %s are empty", path)
+	}
+	for password, strength := range expectations {
+		if !strength.Valid() {
+			return nil, fmt.Errorf("self-test expectations %s: invalid strength %q for %q", path, strength, password)
+		}
+	}
+	return expectations, nil
+}
+
+// SelfTestStrengths scores reference passwords with the service as
+// configured, including banned words, dictionaries, and rule plugins, and
+// returns the strength of each
+func (s *PasswordService) SelfTestStrengths(ctx context.Context, passwords []string) map[string]models.PasswordStrength {
+	strengths := make(map[string]models.PasswordStrength, len(passwords))
+	for _, password := range passwords {
+		response := s.ScorePasswordContext(ctx, password)
+		strengths[password] = response.Strength
+		ReleaseResponse(response)
+	}
+	return strengths
+}
+
+// SelfTest scores the pinned reference passwords and returns those whose
+// strength drifted from their expectation, sorted by password
+func (s *PasswordService) SelfTest(ctx context.Context, expectations map[string]models.PasswordStrength) []SelfTestDrift {
+	var drifts []SelfTestDrift
+	for password, expected := range expectations {
+		response := s.ScorePasswordContext(ctx, password)
+		if response.Strength != expected {
+			drifts = append(drifts, SelfTestDrift{
+				Password: password,
+				Expected: expected,
+				Actual:   response.Strength,
+				Score:    response.Score,
+			})
+		}
+		ReleaseResponse(response)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Password < drifts[j].Password
+	})
+	return drifts
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"container/list"
+	"encoding/binary"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"config-service/pkg/strength"
+)
+
+// StrengthMemo remembers strength results for a short time so that repeated
+// checks of the same password, such as while a user is typing or when a
+// request is retried, skip scoring. Results are keyed by a hash seeded
+// randomly per process; neither passwords nor unsalted hashes of them are
+// kept. The memo holds at most maxEntries results, dropping the oldest first.
+//
+// Keys include the scoring engine version and the version of the password
+// policies, and a policy change drops every result, so that no check reuses
+// a result of rules since replaced.
+type StrengthMemo struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+	seed       maphash.Seed
+
+	mutex sync.Mutex
+	// policyVersion is the version of the policies in effect
+	policyVersion uint64
+	entries       map[uint64]*list.Element
+	// order holds the entries oldest first; all share the TTL, so the front
+	// is also the first to expire
+	order *list.List
+}
+
+// memoEntry is a remembered strength result
+type memoEntry struct {
+	key       uint64
+	result    strength.Result
+	expiresAt time.Time
+}
+
+// StrengthMemoOption configures a StrengthMemo
+type StrengthMemoOption func(*StrengthMemo)
+
+// WithMemoClock sets the clock used to expire results
+func WithMemoClock(now func() time.Time) StrengthMemoOption {
+	return func(m *StrengthMemo) {
+		m.now = now
+	}
+}
+
+// NewStrengthMemo creates a memo keeping up to maxEntries results for ttl
+func NewStrengthMemo(ttl time.Duration, maxEntries int, options ...StrengthMemoOption) *StrengthMemo {
+	m := &StrengthMemo{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		seed:       maphash.MakeSeed(),
+		entries:    make(map[uint64]*list.Element),
+		order:      list.New(),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// PolicyChanged drops every result and keys results from now on by the new
+// version of the password policies. Checks under way when the policies
+// change store their results under the old version, which are never reused.
+func (m *StrengthMemo) PolicyChanged(version uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.policyVersion = version
+	m.entries = make(map[uint64]*list.Element)
+	m.order.Init()
+}
+
+// key returns the seeded hash of a password checked for a keyboard layout
+// under the current engine and policies
+func (m *StrengthMemo) key(layout strength.Layout, password string) uint64 {
+	m.mutex.Lock()
+	policyVersion := m.policyVersion
+	m.mutex.Unlock()
+
+	var h maphash.Hash
+	h.SetSeed(m.seed)
+	h.WriteString(strength.EngineVersion)
+	h.WriteByte(0)
+	var version [8]byte
+	binary.BigEndian.PutUint64(version[:], policyVersion)
+	h.Write(version[:])
+	h.WriteString(string(layout))
+	h.WriteByte(0)
+	h.WriteString(password)
+	return h.Sum64()
+}
+
+// Len returns the number of results remembered, including expired ones not
+// yet dropped
+func (m *StrengthMemo) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.order.Len()
+}
+
+// load copies the remembered result for a key into result, reusing its
+// feedback slices, and reports whether there was one
+func (m *StrengthMemo) load(key uint64, result *strength.Result) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return false
+	}
+	entry := element.Value.(*memoEntry)
+	if !m.now().Before(entry.expiresAt) {
+		m.remove(element)
+		return false
+	}
+
+	warnings, suggestions := result.Feedback.Warnings, result.Feedback.Suggestions
+	*result = entry.result
+	result.Feedback.Warnings = append(emptied(warnings), entry.result.Feedback.Warnings...)
+	result.Feedback.Suggestions = append(emptied(suggestions), entry.result.Feedback.Suggestions...)
+	return true
+}
+
+// store remembers a copy of a result, dropping expired results and, when the
+// memo is full, the oldest one
+func (m *StrengthMemo) store(key uint64, result *strength.Result) {
+	entry := &memoEntry{key: key, result: *result, expiresAt: m.now().Add(m.ttl)}
+	entry.result.Feedback.Warnings = append([]string{}, result.Feedback.Warnings...)
+	entry.result.Feedback.Suggestions = append([]string{}, result.Feedback.Suggestions...)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+	now := m.now()
+	for front := m.order.Front(); front != nil; front = m.order.Front() {
+		if m.order.Len() < m.maxEntries && now.Before(front.Value.(*memoEntry).expiresAt) {
+			break
+		}
+		m.remove(front)
+	}
+	m.entries[key] = m.order.PushBack(entry)
+}
+
+// remove drops an entry; callers hold the mutex
+func (m *StrengthMemo) remove(element *list.Element) {
+	delete(m.entries, element.Value.(*memoEntry).key)
+	m.order.Remove(element)
+}
+
+// emptied empties a slice, keeping its capacity; nil slices become empty ones
+// so that feedback encodes as empty JSON arrays
+func emptied(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values[:0]
+}
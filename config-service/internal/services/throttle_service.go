@@ -0,0 +1,197 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// defaultThrottleThreshold is how many requests per window pass without delay
+	defaultThrottleThreshold = 30
+
+	// defaultThrottleWindow is the period requests are counted over
+	defaultThrottleWindow = time.Minute
+
+	// defaultThrottleBaseDelay is the delay applied to the first request over the threshold
+	defaultThrottleBaseDelay = 250 * time.Millisecond
+
+	// defaultThrottleMaxDelay caps the delay; requests that would wait longer are rejected
+	defaultThrottleMaxDelay = 8 * time.Second
+
+	// defaultThrottleMaxClients bounds the clients counted at once
+	defaultThrottleMaxClients = 100000
+)
+
+// throttleWindow counts the requests made by one client in the current window
+type throttleWindow struct {
+	start time.Time
+	count int
+}
+
+// queuedThrottleWindow is a client window, queued in the order they expire
+type queuedThrottleWindow struct {
+	key   string
+	start time.Time
+}
+
+// ThrottleService applies progressive delays to clients that call password
+// oracle endpoints more often than a threshold per window. Each request over
+// the threshold doubles the delay, which makes online guessing impractical
+// while leaving normal use unaffected. The clients counted are bounded: when
+// all of them are still within their window, the oldest window is dropped
+// for a new client, so a flood of client keys cannot exhaust memory.
+type ThrottleService struct {
+	threshold  int
+	window     time.Duration
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	maxClients int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*throttleWindow
+	queue   []queuedThrottleWindow
+}
+
+// ThrottleServiceOption defines functional options for configuring the ThrottleService
+type ThrottleServiceOption func(*ThrottleService)
+
+// WithThrottleThreshold sets how many requests per window pass without delay
+func WithThrottleThreshold(threshold int) ThrottleServiceOption {
+	return func(s *ThrottleService) {
+		s.threshold = threshold
+	}
+}
+
+// WithThrottleWindow sets the period requests are counted over
+func WithThrottleWindow(window time.Duration) ThrottleServiceOption {
+	return func(s *ThrottleService) {
+		s.window = window
+	}
+}
+
+// WithThrottleDelays sets the initial delay and the maximum delay
+func WithThrottleDelays(base, max time.Duration) ThrottleServiceOption {
+	return func(s *ThrottleService) {
+		s.baseDelay = base
+		s.maxDelay = max
+	}
+}
+
+// WithThrottleMaxClients sets how many clients are counted at once
+func WithThrottleMaxClients(max int) ThrottleServiceOption {
+	return func(s *ThrottleService) {
+		if max > 0 {
+			s.maxClients = max
+		}
+	}
+}
+
+// NewThrottleService creates a new brute-force throttle
+func NewThrottleService(options ...ThrottleServiceOption) *ThrottleService {
+	s := &ThrottleService{
+		threshold:  defaultThrottleThreshold,
+		window:     defaultThrottleWindow,
+		baseDelay:  defaultThrottleBaseDelay,
+		maxDelay:   defaultThrottleMaxDelay,
+		maxClients: defaultThrottleMaxClients,
+		now:        time.Now,
+		clients:    make(map[string]*throttleWindow),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Record counts a request from each client key and returns the delay to apply,
+// the largest across the keys. ok is false when the delay would exceed the
+// maximum and the request should be rejected instead.
+func (s *ThrottleService) Record(keys ...string) (delay time.Duration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+
+	excess := 0
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		client, exists := s.clients[key]
+		if !exists || now.Sub(client.start) >= s.window {
+			if !exists {
+				s.evict()
+			}
+			client = &throttleWindow{start: now}
+			s.clients[key] = client
+			s.queue = append(s.queue, queuedThrottleWindow{key: key, start: now})
+		}
+		client.count++
+
+		if over := client.count - s.threshold; over > excess {
+			excess = over
+		}
+	}
+
+	if excess <= 0 {
+		return 0, true
+	}
+
+	delay = s.baseDelay
+	for i := 1; i < excess && delay <= s.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.maxDelay {
+		return s.maxDelay, false
+	}
+	return delay, true
+}
+
+// Reset clears the counts for the client keys, e.g. after a solved CAPTCHA
+func (s *ThrottleService) Reset(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.clients, key)
+	}
+}
+
+// RetryAfter returns how long a rejected client should wait before retrying
+func (s *ThrottleService) RetryAfter() time.Duration {
+	return s.window
+}
+
+// expire drops windows that have expired. Windows expire in the order they
+// were queued, as they all last the same time; an entry whose client was
+// reset or started a new window is dropped without touching the client.
+func (s *ThrottleService) expire(now time.Time) {
+	expired := 0
+	for expired < len(s.queue) && now.Sub(s.queue[expired].start) >= s.window {
+		s.drop(s.queue[expired])
+		expired++
+	}
+	s.queue = s.queue[expired:]
+}
+
+// evict drops the oldest windows until there is room for a new client
+func (s *ThrottleService) evict() {
+	evicted := 0
+	for len(s.clients) >= s.maxClients && evicted < len(s.queue) {
+		s.drop(s.queue[evicted])
+		evicted++
+	}
+	s.queue = s.queue[evicted:]
+}
+
+// drop forgets the client of a queued window, unless it has a newer window
+func (s *ThrottleService) drop(queued queuedThrottleWindow) {
+	if client, ok := s.clients[queued.key]; ok && client.start.Equal(queued.start) {
+		delete(s.clients, queued.key)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"config-service/internal/models"
+)
+
+// Usage export formats
+const (
+	UsageFormatCSV  = "csv"
+	UsageFormatJSON = "json"
+)
+
+// usageCSVHeader lists the CSV export columns
+var usageCSVHeader = []string{"period", "tenant", "requests", "checks", "breach_hits", "policy_failures", "average_score", "quota"}
+
+// ExportUsage writes the per-tenant usage of a report as CSV with a header
+// row, or as a JSON document with the period and tenants
+func ExportUsage(w io.Writer, report models.UsageReport, format string) error {
+	switch format {
+	case UsageFormatJSON:
+		return json.NewEncoder(w).Encode(struct {
+			Period  string               `json:"period"`
+			Tenants []models.TenantUsage `json:"tenants"`
+		}{report.Period, report.Tenants})
+	case UsageFormatCSV:
+		return writeUsageCSV(w, report)
+	default:
+		return fmt.Errorf("unsupported usage export format %q", format)
+	}
+}
+
+// writeUsageCSV writes one row per tenant
+func writeUsageCSV(w io.Writer, report models.UsageReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(usageCSVHeader); err != nil {
+		return err
+	}
+
+	for _, tenant := range report.Tenants {
+		record := []string{
+			report.Period,
+			tenant.Tenant,
+			strconv.FormatInt(tenant.Requests, 10),
+			strconv.FormatInt(tenant.Checks, 10),
+			strconv.FormatInt(tenant.BreachHits, 10),
+			strconv.FormatInt(tenant.PolicyFailures, 10),
+			strconv.FormatFloat(tenant.AverageScore, 'f', 2, 64),
+			strconv.FormatInt(tenant.Quota, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
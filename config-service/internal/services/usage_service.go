@@ -0,0 +1,284 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"config-service/internal/models"
+)
+
+const (
+	// usagePeriodLayout formats the monthly usage period, e.g. "2026-10"
+	usagePeriodLayout = "2006-01"
+
+	// usageRetainedPeriods is how many months of usage are kept, including the current one
+	usageRetainedPeriods = 3
+)
+
+// usagePeriod holds the request counts for one month
+type usagePeriod struct {
+	tenants map[string]int64
+	keys    map[string]*models.KeyUsage
+	checks  map[string]*checkCounts
+}
+
+// checkCounts holds the password check outcomes of a tenant for one month
+type checkCounts struct {
+	checks, breaches, failures int64
+	scoreSum, scored           int64
+}
+
+// UsageService counts requests per tenant and API key for each calendar month
+// (UTC) and enforces optional monthly quotas per tenant
+type UsageService struct {
+	defaultQuota int64
+	quotas       map[string]int64
+	now          func() time.Time
+
+	mu      sync.Mutex
+	periods map[string]*usagePeriod
+}
+
+// UsageServiceOption defines functional options for configuring the UsageService
+type UsageServiceOption func(*UsageService)
+
+// WithMonthlyQuota sets the monthly request quota for tenants without their own
+// quota; zero means unlimited
+func WithMonthlyQuota(quota int64) UsageServiceOption {
+	return func(s *UsageService) {
+		s.defaultQuota = quota
+	}
+}
+
+// WithTenantQuotas sets monthly request quotas for individual tenants; zero
+// means unlimited
+func WithTenantQuotas(quotas map[string]int64) UsageServiceOption {
+	return func(s *UsageService) {
+		for tenant, quota := range quotas {
+			s.quotas[tenant] = quota
+		}
+	}
+}
+
+// NewUsageService creates a new usage tracker
+func NewUsageService(options ...UsageServiceOption) *UsageService {
+	s := &UsageService{
+		quotas:  make(map[string]int64),
+		now:     time.Now,
+		periods: make(map[string]*usagePeriod),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// ParseTenantQuotas parses quota definitions of the form "tenant:limit"
+func ParseTenantQuotas(definitions []string) (map[string]int64, error) {
+	quotas := make(map[string]int64, len(definitions))
+	for _, definition := range definitions {
+		parts := strings.SplitN(strings.TrimSpace(definition), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid tenant quota definition: expected tenant:limit")
+		}
+		limit, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid quota for tenant %s: %s", parts[0], parts[1])
+		}
+		quotas[parts[0]] = limit
+	}
+	return quotas, nil
+}
+
+// Record counts a request for the tenant and API key, either of which may be
+// empty. ok is false when the tenant has used up its quota for the month; the
+// request is then not counted.
+func (s *UsageService) Record(tenant, keyID string) (status models.QuotaStatus, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	period := s.period(now)
+	status = s.status(tenant, now, period.tenants[tenant])
+
+	if status.Limit > 0 && status.Used >= status.Limit {
+		return status, false
+	}
+
+	if tenant != "" {
+		period.tenants[tenant]++
+		status.Used++
+		if status.Limit > 0 {
+			status.Remaining--
+		}
+	}
+	if keyID != "" {
+		key, exists := period.keys[keyID]
+		if !exists {
+			key = &models.KeyUsage{KeyID: keyID, Tenant: tenant}
+			period.keys[keyID] = key
+		}
+		key.Requests++
+	}
+
+	return status, true
+}
+
+// RecordCheck counts the outcome of a password check for the tenant; checks
+// without a tenant are not counted
+func (s *UsageService) RecordCheck(tenant string, outcome models.CheckOutcome) {
+	if tenant == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	period := s.period(s.now().UTC())
+	counts, exists := period.checks[tenant]
+	if !exists {
+		counts = &checkCounts{}
+		period.checks[tenant] = counts
+	}
+	counts.checks++
+	if outcome.Breached {
+		counts.breaches++
+	}
+	if outcome.PolicyFailure {
+		counts.failures++
+	}
+	if outcome.Scored {
+		counts.scoreSum += int64(outcome.Score)
+		counts.scored++
+	}
+}
+
+// Report returns the usage for a period formatted as "YYYY-MM"; an empty
+// period means the current month
+func (s *UsageService) Report(period string) (models.UsageReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	if period == "" {
+		period = now.Format(usagePeriodLayout)
+	}
+	start, err := time.Parse(usagePeriodLayout, period)
+	if err != nil {
+		return models.UsageReport{}, fmt.Errorf("invalid period %q: expected YYYY-MM", period)
+	}
+
+	report := models.UsageReport{
+		Period:  period,
+		Tenants: []models.TenantUsage{},
+		Keys:    []models.KeyUsage{},
+	}
+
+	usage, exists := s.periods[period]
+	if !exists {
+		return report, nil
+	}
+
+	// Quotas only apply to the current month; past months report their counts only
+	current := period == now.Format(usagePeriodLayout)
+	tenants := make(map[string]bool, len(usage.tenants))
+	for tenant := range usage.tenants {
+		tenants[tenant] = true
+	}
+	for tenant := range usage.checks {
+		tenants[tenant] = true
+	}
+	for tenant := range tenants {
+		entry := models.TenantUsage{Tenant: tenant, Requests: usage.tenants[tenant]}
+		if counts, ok := usage.checks[tenant]; ok {
+			entry.Checks = counts.checks
+			entry.BreachHits = counts.breaches
+			entry.PolicyFailures = counts.failures
+			if counts.scored > 0 {
+				entry.AverageScore = math.Round(float64(counts.scoreSum)/float64(counts.scored)*100) / 100
+			}
+		}
+		if current {
+			if limit := s.quota(tenant); limit > 0 {
+				entry.Quota = limit
+				entry.Remaining = nonNegative(limit - entry.Requests)
+			}
+		}
+		report.Tenants = append(report.Tenants, entry)
+	}
+	for _, key := range usage.keys {
+		report.Keys = append(report.Keys, *key)
+	}
+
+	sort.Slice(report.Tenants, func(i, j int) bool { return report.Tenants[i].Tenant < report.Tenants[j].Tenant })
+	sort.Slice(report.Keys, func(i, j int) bool { return report.Keys[i].KeyID < report.Keys[j].KeyID })
+	report.ResetsAt = start.AddDate(0, 1, 0)
+
+	return report, nil
+}
+
+// period returns the counts for the month of now, creating them and dropping
+// months past the retention when a new month starts
+func (s *UsageService) period(now time.Time) *usagePeriod {
+	name := now.Format(usagePeriodLayout)
+	if usage, exists := s.periods[name]; exists {
+		return usage
+	}
+
+	usage := &usagePeriod{
+		tenants: make(map[string]int64),
+		keys:    make(map[string]*models.KeyUsage),
+		checks:  make(map[string]*checkCounts),
+	}
+	s.periods[name] = usage
+
+	oldest := now.AddDate(0, -(usageRetainedPeriods - 1), 0).Format(usagePeriodLayout)
+	for existing := range s.periods {
+		if existing < oldest {
+			delete(s.periods, existing)
+		}
+	}
+
+	return usage
+}
+
+// status builds the quota status of a tenant that has used the given number of requests
+func (s *UsageService) status(tenant string, now time.Time, used int64) models.QuotaStatus {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	status := models.QuotaStatus{
+		Tenant:   tenant,
+		Period:   now.Format(usagePeriodLayout),
+		Used:     used,
+		ResetsAt: start.AddDate(0, 1, 0),
+	}
+	if tenant != "" {
+		if limit := s.quota(tenant); limit > 0 {
+			status.Limit = limit
+			status.Remaining = nonNegative(limit - used)
+		}
+	}
+	return status
+}
+
+// quota returns the monthly quota of a tenant, zero meaning unlimited
+func (s *UsageService) quota(tenant string) int64 {
+	if limit, exists := s.quotas[tenant]; exists {
+		return limit
+	}
+	return s.defaultQuota
+}
+
+// nonNegative returns n, or zero when n is negative
+func nonNegative(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
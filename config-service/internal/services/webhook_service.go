@@ -0,0 +1,637 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+)
+
+// Webhook delivery headers
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Webhook delivery results reported in metrics
+const (
+	webhookResultDelivered  = "delivered"
+	webhookResultRetried    = "retried"
+	webhookResultDeadLetter = "dead_letter"
+)
+
+const (
+	// webhookSecretBytes is the number of random bytes in a generated signing secret
+	webhookSecretBytes = 32
+
+	// webhookQueueSize bounds the deliveries waiting for a worker
+	webhookQueueSize = 1000
+)
+
+// ErrWebhookNotFound is returned when a webhook subscription does not exist
+var ErrWebhookNotFound = fmt.Errorf("webhook not found")
+
+// ErrDeadLetterNotFound is returned when a dead letter does not exist
+var ErrDeadLetterNotFound = fmt.Errorf("dead letter not found")
+
+// errWebhookCircuitOpen fails attempts made while a webhook's circuit breaker is open
+var errWebhookCircuitOpen = fmt.Errorf("circuit breaker is open; webhook not called")
+
+// webhookDelivery is one attempt to deliver an event to a subscription
+type webhookDelivery struct {
+	subscriptionID string
+	event          models.WebhookEvent
+	attempt        int
+}
+
+// WebhookService manages webhook subscriptions and delivers events to them.
+// Deliveries are signed with the subscription secret, retried with
+// exponential backoff, and kept as dead letters once all attempts fail. Each
+// webhook has its own circuit breaker, so one that keeps failing is not
+// called for every event, and a log of its latest attempts.
+type WebhookService struct {
+	logger           *logrus.Logger
+	client           *http.Client
+	recorder         metrics.Recorder
+	maxAttempts      int
+	retryBackoff     time.Duration
+	workers          int
+	deadLetterLimit  int
+	circuitThreshold int
+	circuitCooldown  time.Duration
+	deliveryLogLimit int
+	now              func() time.Time
+
+	queue chan webhookDelivery
+
+	mutex         sync.RWMutex
+	subscriptions map[string]*models.WebhookSubscription
+	secrets       map[string]string
+	breakers      map[string]*CircuitBreaker
+	deliveryLogs  map[string][]models.WebhookDeliveryAttempt
+	deadLetters   []*models.WebhookDeadLetter
+}
+
+// WebhookOption configures a WebhookService
+type WebhookOption func(*WebhookService)
+
+// WithWebhookTimeout sets the timeout of each delivery attempt
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(s *WebhookService) {
+		if timeout > 0 {
+			s.client.Timeout = timeout
+		}
+	}
+}
+
+// WithWebhookRetries sets the delivery attempts per event and the backoff
+// before the first retry, which doubles for each further retry
+func WithWebhookRetries(maxAttempts int, backoff time.Duration) WebhookOption {
+	return func(s *WebhookService) {
+		if maxAttempts > 0 {
+			s.maxAttempts = maxAttempts
+		}
+		if backoff > 0 {
+			s.retryBackoff = backoff
+		}
+	}
+}
+
+// WithWebhookWorkers sets the number of concurrent deliveries
+func WithWebhookWorkers(workers int) WebhookOption {
+	return func(s *WebhookService) {
+		if workers > 0 {
+			s.workers = workers
+		}
+	}
+}
+
+// WithDeadLetterLimit sets how many dead letters are kept; the oldest are
+// dropped first
+func WithDeadLetterLimit(limit int) WebhookOption {
+	return func(s *WebhookService) {
+		if limit > 0 {
+			s.deadLetterLimit = limit
+		}
+	}
+}
+
+// WithWebhookCircuitBreaker stops calling a webhook for the cooldown after
+// threshold consecutive failed attempts; a zero threshold disables it
+func WithWebhookCircuitBreaker(threshold int, cooldown time.Duration) WebhookOption {
+	return func(s *WebhookService) {
+		if threshold >= 0 {
+			s.circuitThreshold = threshold
+		}
+		if cooldown >= 0 {
+			s.circuitCooldown = cooldown
+		}
+	}
+}
+
+// WithDeliveryLogLimit sets how many attempts are logged per webhook; the
+// oldest are dropped first
+func WithDeliveryLogLimit(limit int) WebhookOption {
+	return func(s *WebhookService) {
+		if limit > 0 {
+			s.deliveryLogLimit = limit
+		}
+	}
+}
+
+// WithWebhookMetrics records delivery results and durations
+func WithWebhookMetrics(recorder metrics.Recorder) WebhookOption {
+	return func(s *WebhookService) {
+		s.recorder = recorder
+	}
+}
+
+// NewWebhookService creates a webhook service without subscriptions.
+// Deliveries start once Run is called.
+func NewWebhookService(logger *logrus.Logger, options ...WebhookOption) *WebhookService {
+	s := &WebhookService{
+		logger:           logger,
+		client:           &http.Client{Timeout: 5 * time.Second},
+		recorder:         metrics.Noop{},
+		maxAttempts:      5,
+		retryBackoff:     time.Second,
+		workers:          2,
+		deadLetterLimit:  1000,
+		circuitThreshold: 5,
+		circuitCooldown:  30 * time.Second,
+		deliveryLogLimit: 100,
+		now:              time.Now,
+		queue:            make(chan webhookDelivery, webhookQueueSize),
+		subscriptions:    make(map[string]*models.WebhookSubscription),
+		secrets:          make(map[string]string),
+		breakers:         make(map[string]*CircuitBreaker),
+		deliveryLogs:     make(map[string][]models.WebhookDeliveryAttempt),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Create registers a webhook and returns it together with its signing secret
+func (s *WebhookService) Create(request models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	parsed, err := url.Parse(request.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("webhook url must be an absolute http or https URL")
+	}
+
+	id, err := randomToken(12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook id: %w", err)
+	}
+	secret := request.Secret
+	if secret == "" {
+		if secret, err = randomToken(webhookSecretBytes); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = "whsec_" + secret
+	}
+
+	now := s.now().UTC()
+	subscription := &models.WebhookSubscription{
+		ID:        "wh_" + id,
+		URL:       request.URL,
+		Events:    uniqueStrings(request.Events),
+		Tenant:    request.Tenant,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mutex.Lock()
+	s.subscriptions[subscription.ID] = subscription
+	s.secrets[subscription.ID] = secret
+	if s.circuitThreshold > 0 {
+		s.breakers[subscription.ID] = NewCircuitBreaker(s.circuitThreshold, s.circuitCooldown, s.onCircuitChange(subscription.ID))
+	}
+	s.mutex.Unlock()
+
+	s.logger.Infof("Webhook registered: id=%s events=%v", subscription.ID, subscription.Events)
+	created := *subscription
+	created.Secret = secret
+	created.Circuit = string(CircuitClosed)
+	return &created, nil
+}
+
+// Get returns a webhook subscription without its secret
+func (s *WebhookService) Get(id string) (*models.WebhookSubscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	copied := *subscription
+	copied.Circuit = string(s.circuitState(id))
+	return &copied, nil
+}
+
+// circuitState returns the state of a webhook's circuit breaker, which is
+// always closed when breakers are disabled; the caller must hold the lock
+func (s *WebhookService) circuitState(id string) CircuitState {
+	if breaker, ok := s.breakers[id]; ok {
+		return breaker.State()
+	}
+	return CircuitClosed
+}
+
+// Delete removes a webhook subscription; pending retries to it are dropped
+func (s *WebhookService) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.subscriptions[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(s.subscriptions, id)
+	delete(s.secrets, id)
+	delete(s.breakers, id)
+	delete(s.deliveryLogs, id)
+
+	s.logger.Infof("Webhook deleted: id=%s", id)
+	return nil
+}
+
+// ListPage returns a page of webhook subscriptions matching the list options,
+// named by their URL
+func (s *WebhookService) ListPage(opts models.ListOptions) ([]models.WebhookSubscription, models.PageInfo, error) {
+	s.mutex.RLock()
+	items := make([]webhookListItem, 0, len(s.subscriptions))
+	for id, subscription := range s.subscriptions {
+		item := webhookListItem(*subscription)
+		item.Circuit = string(s.circuitState(id))
+		items = append(items, item)
+	}
+	s.mutex.RUnlock()
+
+	page, info, err := paginate(items, opts)
+	if err != nil {
+		return nil, models.PageInfo{}, err
+	}
+
+	subscriptions := make([]models.WebhookSubscription, len(page))
+	for i, item := range page {
+		subscriptions[i] = models.WebhookSubscription(item)
+	}
+	return subscriptions, info, nil
+}
+
+// webhookListItem adapts a webhook subscription for pagination
+type webhookListItem models.WebhookSubscription
+
+func (w webhookListItem) listID() string           { return w.ID }
+func (w webhookListItem) listName() string         { return w.URL }
+func (w webhookListItem) listUpdatedAt() time.Time { return w.UpdatedAt }
+
+// Publish queues an event for delivery to every matching subscription without
+// waiting for the deliveries. Deliveries that do not fit in the queue are
+// dead-lettered immediately.
+func (s *WebhookService) Publish(event models.WebhookEvent) {
+	event, err := stampEvent(event, s.now())
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate webhook event id")
+		return
+	}
+
+	s.mutex.RLock()
+	var matched []string
+	for id, subscription := range s.subscriptions {
+		if subscription.Subscribes(&event) {
+			matched = append(matched, id)
+		}
+	}
+	s.mutex.RUnlock()
+
+	for _, id := range matched {
+		s.enqueue(webhookDelivery{subscriptionID: id, event: event, attempt: 1})
+	}
+}
+
+// enqueue hands a delivery to the workers, or dead-letters it when the queue is full
+func (s *WebhookService) enqueue(delivery webhookDelivery) {
+	select {
+	case s.queue <- delivery:
+	default:
+		s.deadLetter(delivery, 0, fmt.Errorf("delivery queue is full"))
+	}
+}
+
+// Run delivers queued events until the context is done, then waits for the
+// deliveries in progress to return
+func (s *WebhookService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-s.queue:
+					s.attempt(ctx, delivery)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// attempt delivers an event once and schedules a retry or dead-letters it on failure
+func (s *WebhookService) attempt(ctx context.Context, delivery webhookDelivery) {
+	s.mutex.RLock()
+	subscription, ok := s.subscriptions[delivery.subscriptionID]
+	var target, secret string
+	if ok {
+		target, secret = subscription.URL, s.secrets[delivery.subscriptionID]
+	}
+	breaker := s.breakers[delivery.subscriptionID]
+	s.mutex.RUnlock()
+	if !ok {
+		// The subscription was deleted while the delivery was pending
+		return
+	}
+
+	var status int
+	var err error
+	var elapsed time.Duration
+	if breaker != nil && !breaker.Allow() {
+		// The attempt fails without calling the webhook, and is retried or
+		// dead-lettered like any other failed attempt
+		err = errWebhookCircuitOpen
+	} else {
+		start := s.now()
+		status, err = s.deliver(ctx, target, secret, &delivery.event)
+		elapsed = s.now().Sub(start)
+		s.recorder.Timing("webhook_delivery_duration", elapsed, metrics.Tags{"event": delivery.event.Type})
+		if breaker != nil {
+			switch {
+			case err == nil:
+				breaker.Success()
+			case ctx.Err() != nil:
+				breaker.Release()
+			default:
+				breaker.Failure()
+			}
+		}
+	}
+	s.logAttempt(delivery, status, err, elapsed)
+	if err == nil {
+		s.recorder.Count("webhook_deliveries", 1, metrics.Tags{"event": delivery.event.Type, "result": webhookResultDelivered})
+		return
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"webhook": delivery.subscriptionID,
+		"event":   delivery.event.Type,
+		"attempt": delivery.attempt,
+	})
+	if delivery.attempt >= s.maxAttempts || ctx.Err() != nil {
+		logger.WithError(err).Warn("Webhook delivery failed; moved to dead letters")
+		s.deadLetter(delivery, status, err)
+		return
+	}
+
+	backoff := s.retryBackoff << uint(delivery.attempt-1)
+	logger.WithError(err).Debugf("Webhook delivery failed; retrying in %s", backoff)
+	s.recorder.Count("webhook_deliveries", 1, metrics.Tags{"event": delivery.event.Type, "result": webhookResultRetried})
+	time.AfterFunc(backoff, func() {
+		if ctx.Err() != nil {
+			s.deadLetter(delivery, status, err)
+			return
+		}
+		retry := delivery
+		retry.attempt++
+		s.enqueue(retry)
+	})
+}
+
+// deliver posts a signed event to a webhook, returning the response status
+func (s *WebhookService) deliver(ctx context.Context, target, secret string, event *models.WebhookEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookDeliveryHeader, event.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, s.now().Unix(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// logAttempt adds an attempt to the delivery log of its webhook
+func (s *WebhookService) logAttempt(delivery webhookDelivery, status int, err error, elapsed time.Duration) {
+	attempt := models.WebhookDeliveryAttempt{
+		EventID:     delivery.event.ID,
+		EventType:   delivery.event.Type,
+		Attempt:     delivery.attempt,
+		Result:      models.WebhookAttemptDelivered,
+		Status:      status,
+		DurationMs:  elapsed.Milliseconds(),
+		AttemptedAt: s.now().UTC(),
+	}
+	switch {
+	case err == errWebhookCircuitOpen:
+		attempt.Result = models.WebhookAttemptCircuitOpen
+		attempt.Error = err.Error()
+	case err != nil:
+		attempt.Result = models.WebhookAttemptFailed
+		attempt.Error = err.Error()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.subscriptions[delivery.subscriptionID]; !ok {
+		return
+	}
+	log := append(s.deliveryLogs[delivery.subscriptionID], attempt)
+	if overflow := len(log) - s.deliveryLogLimit; overflow > 0 {
+		log = append([]models.WebhookDeliveryAttempt(nil), log[overflow:]...)
+	}
+	s.deliveryLogs[delivery.subscriptionID] = log
+}
+
+// Deliveries returns the latest delivery attempts to a webhook, newest first
+func (s *WebhookService) Deliveries(subscriptionID string) ([]models.WebhookDeliveryAttempt, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if _, ok := s.subscriptions[subscriptionID]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+	log := s.deliveryLogs[subscriptionID]
+	attempts := make([]models.WebhookDeliveryAttempt, len(log))
+	for i, attempt := range log {
+		attempts[len(log)-1-i] = attempt
+	}
+	return attempts, nil
+}
+
+// onCircuitChange returns a listener recording the circuit breaker
+// transitions of a webhook
+func (s *WebhookService) onCircuitChange(id string) func(from, to CircuitState) {
+	return func(from, to CircuitState) {
+		s.recorder.Count("webhook_circuit_transitions", 1, metrics.Tags{"from": string(from), "to": string(to)})
+
+		entry := s.logger.WithFields(logrus.Fields{
+			"webhook":    id,
+			"from_state": string(from),
+			"to_state":   string(to),
+		})
+		if to == CircuitOpen {
+			entry.Warn("Webhook circuit breaker opened")
+		} else {
+			entry.Info("Webhook circuit breaker state changed")
+		}
+	}
+}
+
+// SignWebhook returns the signature header value of a delivery body:
+// t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">. Receivers
+// recompute the HMAC with the subscription secret and reject old timestamps.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	t := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deadLetter keeps a failed delivery for inspection and manual retry
+func (s *WebhookService) deadLetter(delivery webhookDelivery, status int, err error) {
+	id, tokenErr := randomToken(12)
+	if tokenErr != nil {
+		s.logger.WithError(tokenErr).Error("Failed to generate dead letter id")
+		return
+	}
+	s.recorder.Count("webhook_deliveries", 1, metrics.Tags{"event": delivery.event.Type, "result": webhookResultDeadLetter})
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	letter := &models.WebhookDeadLetter{
+		ID:             "dl_" + id,
+		SubscriptionID: delivery.subscriptionID,
+		Event:          delivery.event,
+		Attempts:       delivery.attempt,
+		LastStatus:     status,
+		LastError:      err.Error(),
+		FailedAt:       s.now().UTC(),
+	}
+	if subscription, ok := s.subscriptions[delivery.subscriptionID]; ok {
+		letter.URL = subscription.URL
+	}
+	s.deadLetters = append(s.deadLetters, letter)
+	if overflow := len(s.deadLetters) - s.deadLetterLimit; overflow > 0 {
+		s.deadLetters = append([]*models.WebhookDeadLetter(nil), s.deadLetters[overflow:]...)
+	}
+}
+
+// DeadLetters returns the failed deliveries, newest first, optionally only
+// those of one subscription
+func (s *WebhookService) DeadLetters(subscriptionID string) []models.WebhookDeadLetter {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	letters := make([]models.WebhookDeadLetter, 0, len(s.deadLetters))
+	for _, letter := range s.deadLetters {
+		if subscriptionID == "" || letter.SubscriptionID == subscriptionID {
+			letters = append(letters, *letter)
+		}
+	}
+	sort.SliceStable(letters, func(i, j int) bool {
+		return letters[i].FailedAt.After(letters[j].FailedAt)
+	})
+	return letters
+}
+
+// RetryDeadLetter removes a dead letter and queues its event for delivery
+// again with a fresh set of attempts
+func (s *WebhookService) RetryDeadLetter(id string) error {
+	s.mutex.Lock()
+	letter, err := s.removeDeadLetter(id)
+	if err == nil {
+		if _, ok := s.subscriptions[letter.SubscriptionID]; !ok {
+			err = ErrWebhookNotFound
+		}
+	}
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.enqueue(webhookDelivery{subscriptionID: letter.SubscriptionID, event: letter.Event, attempt: 1})
+	return nil
+}
+
+// DeleteDeadLetter discards a dead letter
+func (s *WebhookService) DeleteDeadLetter(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.removeDeadLetter(id)
+	return err
+}
+
+// removeDeadLetter removes and returns a dead letter; the caller must hold the lock
+func (s *WebhookService) removeDeadLetter(id string) (*models.WebhookDeadLetter, error) {
+	for i, letter := range s.deadLetters {
+		if letter.ID == id {
+			s.deadLetters = append(s.deadLetters[:i], s.deadLetters[i+1:]...)
+			return letter, nil
+		}
+	}
+	return nil, ErrDeadLetterNotFound
+}
+
+// uniqueStrings returns values without duplicates, in their original order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
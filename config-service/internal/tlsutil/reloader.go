@@ -0,0 +1,143 @@
+package tlsutil
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadDebounce groups the burst of file events produced by a certificate renewal
+const reloadDebounce = 500 * time.Millisecond
+
+// CertReloader serves a certificate/key pair and reloads it from disk on SIGHUP
+// or when the files change, so renewed certificates are picked up without a
+// restart. A failed reload keeps the previous certificate.
+type CertReloader struct {
+	logger   *logrus.Logger
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// NewCertReloader loads the certificate/key pair
+func NewCertReloader(logger *logrus.Logger, certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{
+		logger:   logger,
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads the certificate/key pair from disk
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate returns the current certificate; it is used as tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch reloads the certificate on SIGHUP and on changes to the certificate
+// files until the context is done. The parent directories are watched so that
+// atomic renames and Kubernetes secret symlink swaps are detected.
+func (r *CertReloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create certificate watcher: %w", err)
+	}
+
+	dirs := map[string]bool{
+		filepath.Dir(r.certFile): true,
+		filepath.Dir(r.keyFile):  true,
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hangup)
+
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				r.reload("signal")
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if r.affects(event) {
+					debounce.Reset(reloadDebounce)
+				}
+			case <-debounce.C:
+				r.reload("file change")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				r.logger.WithError(err).Warn("TLS certificate watcher error")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// affects reports whether a file event may have changed the certificate files
+func (r *CertReloader) affects(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	// Kubernetes secret volumes swap a ..data symlink rather than the files
+	return event.Name == r.certFile || event.Name == r.keyFile || name == "..data"
+}
+
+// reload reloads the certificate and logs the outcome
+func (r *CertReloader) reload(trigger string) {
+	if err := r.Reload(); err != nil {
+		r.logger.WithError(err).Error("Failed to reload TLS certificate; keeping the previous one")
+		return
+	}
+	r.logger.Infof("Reloaded TLS certificate (%s)", trigger)
+}
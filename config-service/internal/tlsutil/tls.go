@@ -0,0 +1,164 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// tlsVersions maps configuration values to TLS protocol versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseMinVersion converts a configured minimum TLS version such as "1.2"
+func ParseMinVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+	value, ok := tlsVersions[strings.TrimPrefix(version, "TLS")]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version: %s (must be 1.0, 1.1, 1.2, or 1.3)", version)
+	}
+	return value, nil
+}
+
+// ParseCipherSuites converts IANA cipher suite names into suite IDs. Insecure
+// suites are rejected. An empty list selects Go's defaults.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	available := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := available[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// NewServerConfig builds a server TLS configuration that serves certificates
+// from the reloader
+func NewServerConfig(reloader *CertReloader, minVersion string, cipherSuites []string) (*tls.Config, error) {
+	version, err := ParseMinVersion(minVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	suites, err := ParseCipherSuites(cipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:     version,
+		CipherSuites:   suites,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
+
+// Client certificate authentication modes
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequired = "required"
+)
+
+// ParseClientAuth converts a configured client authentication mode
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case ClientAuthNone, "":
+		return tls.NoClientCert, nil
+	case ClientAuthOptional:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequired:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unsupported client auth mode: %s (must be none, optional, or required)", mode)
+	}
+}
+
+// LoadCertPool reads a PEM bundle of CA certificates
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", file)
+	}
+	return pool, nil
+}
+
+// ConfigureClientAuth enables mutual TLS on the configuration. Client
+// certificates are verified against the CA bundle and, when allowedNames is
+// not empty, must carry one of the names as their common name or as a DNS,
+// email, or URI subject alternative name.
+func ConfigureClientAuth(config *tls.Config, mode, caFile string, allowedNames []string) error {
+	clientAuth, err := ParseClientAuth(mode)
+	if err != nil {
+		return err
+	}
+	if clientAuth == tls.NoClientCert {
+		return nil
+	}
+
+	pool, err := LoadCertPool(caFile)
+	if err != nil {
+		return err
+	}
+
+	config.ClientAuth = clientAuth
+	config.ClientCAs = pool
+
+	if len(allowedNames) > 0 {
+		allowed := make(map[string]bool, len(allowedNames))
+		for _, name := range allowedNames {
+			allowed[strings.TrimSpace(name)] = true
+		}
+
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return nil
+			}
+			for _, name := range CertificateNames(state.PeerCertificates[0]) {
+				if allowed[name] {
+					return nil
+				}
+			}
+			return fmt.Errorf("client certificate %q is not in the allowlist", state.PeerCertificates[0].Subject.CommonName)
+		}
+	}
+
+	return nil
+}
+
+// CertificateNames returns the common name and subject alternative names of a certificate
+func CertificateNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, 1+len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.URIs))
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return names
+}
@@ -0,0 +1,39 @@
+package version
+
+import "runtime"
+
+// Build information, injected at build time via ldflags:
+//
+//	go build -ldflags "-X config-service/internal/version.Version=1.2.0 \
+//	  -X config-service/internal/version.GitCommit=$(git rev-parse HEAD) \
+//	  -X config-service/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	// Version is the semantic version of the service
+	Version = "1.0.0"
+
+	// GitCommit is the git SHA the binary was built from
+	GitCommit = "unknown"
+
+	// BuildTime is the UTC time the binary was built
+	BuildTime = "unknown"
+)
+
+// Info describes the running build of the service
+type Info struct {
+	Version   string          `json:"version"`
+	GitCommit string          `json:"git_commit"`
+	BuildTime string          `json:"build_time"`
+	GoVersion string          `json:"go_version"`
+	Features  map[string]bool `json:"features"`
+}
+
+// Get returns the build information together with the given feature flags
+func Get(features map[string]bool) Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Features:  features,
+	}
+}
@@ -0,0 +1,225 @@
+// Package workpool runs the items of bulk operations on a bounded number of
+// workers. The queue in front of the workers is bounded too, so a large batch
+// waits for room instead of starting a goroutine per item, and concurrent
+// batches share the same workers.
+package workpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"config-service/internal/metrics"
+)
+
+// Item results reported in metrics
+const (
+	ResultDone     = "done"
+	ResultTimeout  = "timeout"
+	ResultRejected = "rejected"
+)
+
+// Default pool settings
+const (
+	DefaultWorkers   = 8
+	DefaultQueueSize = 100
+)
+
+// task is a queued item
+type task struct {
+	ctx      context.Context
+	run      func(ctx context.Context)
+	done     func()
+	queuedAt time.Time
+}
+
+// Pool runs items on a fixed set of workers
+type Pool struct {
+	// depth is the number of queued items, accessed atomically and kept
+	// first for 64-bit alignment
+	depth int64
+
+	name        string
+	workers     int
+	queueSize   int
+	itemTimeout time.Duration
+	maxPerRun   int
+	recorder    metrics.Recorder
+
+	queue     chan task
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Option configures a Pool
+type Option func(*Pool)
+
+// WithWorkers sets how many items run at once
+func WithWorkers(workers int) Option {
+	return func(p *Pool) {
+		if workers > 0 {
+			p.workers = workers
+		}
+	}
+}
+
+// WithQueueSize sets how many items may wait for a worker
+func WithQueueSize(size int) Option {
+	return func(p *Pool) {
+		if size >= 0 {
+			p.queueSize = size
+		}
+	}
+}
+
+// WithItemTimeout limits how long each item may run; 0 leaves items limited
+// only by the context of their batch
+func WithItemTimeout(timeout time.Duration) Option {
+	return func(p *Pool) {
+		p.itemTimeout = timeout
+	}
+}
+
+// WithMaxPerRun limits how many items of one Run may be queued or running at
+// once, so that a single batch cannot take every worker from the others; 0
+// lets a batch use the whole pool
+func WithMaxPerRun(n int) Option {
+	return func(p *Pool) {
+		if n >= 0 {
+			p.maxPerRun = n
+		}
+	}
+}
+
+// WithMetrics records queue depth, queue wait, and item results
+func WithMetrics(recorder metrics.Recorder) Option {
+	return func(p *Pool) {
+		p.recorder = recorder
+	}
+}
+
+// New creates a pool and starts its workers; name tags its metrics
+func New(name string, options ...Option) *Pool {
+	p := &Pool{
+		name:      name,
+		workers:   DefaultWorkers,
+		queueSize: DefaultQueueSize,
+		recorder:  metrics.Noop{},
+	}
+	for _, option := range options {
+		option(p)
+	}
+
+	p.queue = make(chan task, p.queueSize)
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// Run calls fn for items 0 to n-1 on the pool's workers and waits for the
+// calls to return. Queuing an item blocks while the queue is full, or while
+// the batch has as many items queued or running as WithMaxPerRun allows, so
+// the caller proceeds at the pace of the workers. If ctx is done before every
+// item is queued, the remaining items are not run and Run returns ctx.Err()
+// once the queued ones have finished. fn must not call Run on the same pool.
+func (p *Pool) Run(ctx context.Context, n int, fn func(ctx context.Context, i int)) error {
+	var wg sync.WaitGroup
+	var slots chan struct{}
+	if p.maxPerRun > 0 && p.maxPerRun < n {
+		slots = make(chan struct{}, p.maxPerRun)
+	}
+
+	var err error
+	for i := 0; i < n && err == nil; i++ {
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				err = ctx.Err()
+				p.recorder.Count("workpool_items", int64(n-i), p.tags(ResultRejected))
+				continue
+			}
+		}
+
+		i := i
+		wg.Add(1)
+		t := task{
+			ctx: ctx,
+			run: func(ctx context.Context) { fn(ctx, i) },
+			done: func() {
+				if slots != nil {
+					<-slots
+				}
+				wg.Done()
+			},
+			queuedAt: time.Now(),
+		}
+
+		p.setDepth(atomic.AddInt64(&p.depth, 1))
+		select {
+		case p.queue <- t:
+		case <-ctx.Done():
+			p.setDepth(atomic.AddInt64(&p.depth, -1))
+			t.done()
+			err = ctx.Err()
+			p.recorder.Count("workpool_items", int64(n-i), p.tags(ResultRejected))
+		}
+	}
+	wg.Wait()
+	return err
+}
+
+// Close stops the workers once the queued items have run. Run must not be
+// called afterwards.
+func (p *Pool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.queue)
+	})
+	p.wg.Wait()
+}
+
+// work runs queued items until the pool is closed
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for t := range p.queue {
+		p.setDepth(atomic.AddInt64(&p.depth, -1))
+		p.recorder.Timing("workpool_wait_duration", time.Since(t.queuedAt), p.tags(""))
+		p.runTask(t)
+	}
+}
+
+// runTask runs an item within the item timeout
+func (p *Pool) runTask(t task) {
+	defer t.done()
+
+	ctx := t.ctx
+	if p.itemTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.itemTimeout)
+		defer cancel()
+	}
+	t.run(ctx)
+
+	result := ResultDone
+	if ctx.Err() == context.DeadlineExceeded && t.ctx.Err() == nil {
+		result = ResultTimeout
+	}
+	p.recorder.Count("workpool_items", 1, p.tags(result))
+}
+
+// setDepth reports the queue depth
+func (p *Pool) setDepth(depth int64) {
+	p.recorder.Gauge("workpool_queue_depth", float64(depth), p.tags(""))
+}
+
+// tags returns the metric tags of the pool, with the item result if given
+func (p *Pool) tags(result string) metrics.Tags {
+	tags := metrics.Tags{"pool": p.name}
+	if result != "" {
+		tags["result"] = result
+	}
+	return tags
+}
@@ -0,0 +1,28 @@
+// Package reuse computes the digests clients submit to the password reuse
+// endpoint. A digest is the HMAC-SHA256 of the normalized password under a key
+// that only the tenant holds, so the service can tell that two accounts share
+// a password without being able to recover or test guesses of it.
+package reuse
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns the form of a password that is digested: its Unicode
+// normalization form C, so that the same password typed on different systems
+// produces the same digest
+func Normalize(password string) string {
+	return norm.NFC.String(password)
+}
+
+// Digest returns the hex HMAC-SHA256 of the normalized password under the
+// tenant's key
+func Digest(key []byte, password string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(Normalize(password)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,329 @@
+package strength
+
+import "math"
+
+// sequences are runs of sequential characters and keyboard rows
+var sequences = newPatternTable(
+	"abcdef", "bcdefg", "cdefgh", "defghi", "efghij",
+	"fghijk", "ghijkl", "hijklm", "ijklmn", "jklmno",
+	"klmnop", "lmnopq", "mnopqr", "nopqrs", "opqrst",
+	"pqrstu", "qrstuv", "rstuvw", "stuvwx", "tuvwxy", "uvwxyz",
+	"123456", "234567", "345678", "456789", "567890",
+	"qwerty", "asdfgh", "zxcvbn",
+)
+
+// Checker scores password strength
+type Checker struct {
+	layout Layout
+	walks  keyboardWalks
+}
+
+// CheckerOption defines functional options for configuring a Checker
+type CheckerOption func(*Checker)
+
+// WithLayout also penalizes the key walks of a keyboard layout, such as
+// "azerty" and "qsdfgh" on AZERTY keyboards. QWERTY walks are penalized on
+// every layout.
+func WithLayout(layout Layout) CheckerOption {
+	return func(c *Checker) {
+		c.layout = layout
+		c.walks = layoutWalks[layout]
+	}
+}
+
+// NewChecker creates a password strength checker
+func NewChecker(options ...CheckerOption) *Checker {
+	c := &Checker{layout: QWERTY}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// patterns records which weak patterns a password contains
+type patterns struct {
+	common     bool
+	sequential bool
+	repeated   bool
+	// guessable is set when the guess estimate lowered the score
+	guessable bool
+}
+
+// estimateCeilings are the highest scores of passwords of each estimate
+// score, so that a password scoring well on length and variety but made of
+// guessable parts, such as "Password1!", scores no better than its guesses
+// allow
+var estimateCeilings = [...]int{19, 39, 59, 79, 100}
+
+// Check calculates the strength score and provides feedback for a password
+func (c *Checker) Check(password string) *Result {
+	result := &Result{}
+	c.CheckInto(password, result)
+	return result
+}
+
+// CheckInto calculates the strength score and feedback of a password into
+// result, reusing the capacity of its feedback slices. Callers checking many
+// passwords can reuse or pool results instead of allocating one per check.
+func (c *Checker) CheckInto(password string, result *Result) {
+	c.check(password, result, nil)
+}
+
+// check scores a password into result, recording the rules applied when
+// traced
+func (c *Checker) check(password string, result *Result, t *tracer) {
+	t.start()
+
+	// Scan the password once and share the findings between the checks
+	a := analyze(password)
+	found := patterns{
+		common:     a.hasCommonPattern() || c.walks.common.foundIn(a.lower),
+		sequential: a.hasSequentialChars() || c.walks.sequential.foundIn(a.lower),
+		repeated:   hasRepeatedPatterns(password),
+	}
+	patternPenalty := c.calculatePatternPenalty(found)
+	if t != nil {
+		t.step("patterns", -patternPenalty, map[string]interface{}{
+			"common": found.common, "sequential": found.sequential, "repeated": found.repeated,
+		})
+	}
+
+	// Calculate base score components
+	lengthScore := c.calculateLengthScore(password)
+	if t != nil {
+		t.step("length", lengthScore, map[string]interface{}{"bytes": len(password)})
+	}
+	characterVarietyScore := c.calculateCharacterVarietyScore(a.requirements)
+	if t != nil {
+		t.step("character_variety", characterVarietyScore, map[string]interface{}{
+			"uppercase":     a.requirements.Uppercase,
+			"lowercase":     a.requirements.Lowercase,
+			"numbers":       a.requirements.Numbers,
+			"special_chars": a.requirements.SpecialChars,
+		})
+	}
+	entropyScore := c.calculateEntropyScore(password, a.requirements)
+	if t != nil {
+		t.step("entropy", entropyScore, map[string]interface{}{"charset_size": c.getCharacterSetSize(a.requirements)})
+	}
+
+	// Calculate total score (0-100)
+	totalScore := lengthScore + characterVarietyScore - patternPenalty + entropyScore
+
+	// Ensure score is within bounds
+	if totalScore < 0 {
+		totalScore = 0
+	}
+	if totalScore > 100 {
+		totalScore = 100
+	}
+
+	// Cap the score at what the guesses needed to find the password allow
+	estimate := c.Estimate(password)
+	ceiling := estimateCeilings[estimate.Score]
+	capped := 0
+	if totalScore > ceiling {
+		capped = ceiling - totalScore
+		totalScore = ceiling
+		found.guessable = true
+	}
+	if t != nil {
+		t.step("guessability", capped, map[string]interface{}{
+			"guesses_log10": estimate.GuessesLog10, "estimate_score": estimate.Score, "ceiling": ceiling,
+		})
+	}
+
+	result.Strength = Category(totalScore)
+	result.Score = totalScore
+	result.Requirements = a.requirements
+	result.Estimate = estimate
+	c.generateFeedback(&result.Feedback, password, a.requirements, found, totalScore)
+}
+
+// calculateLengthScore calculates score based on password length
+func (c *Checker) calculateLengthScore(password string) int {
+	length := len(password)
+
+	switch {
+	case length >= 16:
+		return 25
+	case length >= 12:
+		return 20
+	case length >= 8:
+		return 15
+	case length >= 6:
+		return 10
+	case length >= 4:
+		return 5
+	default:
+		return 0
+	}
+}
+
+// calculateCharacterVarietyScore calculates score based on character variety
+func (c *Checker) calculateCharacterVarietyScore(reqs Requirements) int {
+	score := 0
+	if reqs.Uppercase {
+		score += 6
+	}
+	if reqs.Lowercase {
+		score += 6
+	}
+	if reqs.Numbers {
+		score += 6
+	}
+	if reqs.SpecialChars {
+		score += 6
+	}
+
+	return score
+}
+
+// calculatePatternPenalty calculates penalty for common patterns
+func (c *Checker) calculatePatternPenalty(found patterns) int {
+	penalty := 0
+
+	// Check for common patterns
+	if found.common {
+		penalty += 20
+	}
+
+	// Check for sequential characters
+	if found.sequential {
+		penalty += 10
+	}
+
+	// Check for repeated patterns
+	if found.repeated {
+		penalty += 15
+	}
+
+	return penalty
+}
+
+// calculateEntropyScore calculates score based on password entropy
+func (c *Checker) calculateEntropyScore(password string, reqs Requirements) int {
+	charSetSize := c.getCharacterSetSize(reqs)
+	entropy := float64(len(password)) * math.Log2(float64(charSetSize))
+
+	// Normalize entropy score to 0-50 range
+	maxEntropy := 10.0 * float64(len(password)) // Approximate max for very complex passwords
+	if maxEntropy == 0 {
+		return 0
+	}
+
+	normalizedScore := int((entropy / maxEntropy) * 50)
+
+	// Cap at 50 points
+	if normalizedScore > 50 {
+		normalizedScore = 50
+	}
+
+	return normalizedScore
+}
+
+// getCharacterSetSize determines the size of the character set used
+func (c *Checker) getCharacterSetSize(reqs Requirements) int {
+	size := 0
+	if reqs.Uppercase {
+		size += 26
+	}
+	if reqs.Lowercase {
+		size += 26
+	}
+	if reqs.Numbers {
+		size += 10
+	}
+	if reqs.SpecialChars {
+		size += 32 // Approximate number of common special characters
+	}
+
+	return size
+}
+
+// generateFeedback generates warnings and suggestions based on the password,
+// replacing those already in feedback
+func (c *Checker) generateFeedback(feedback *Feedback, password string, reqs Requirements, found patterns, score int) {
+	// New slices are sized for the most warnings and suggestions a password can get
+	feedback.Warnings = reuseSlice(feedback.Warnings, 4)
+	feedback.Suggestions = reuseSlice(feedback.Suggestions, 11)
+
+	// Check for common issues
+	if found.common {
+		feedback.Warnings = append(feedback.Warnings, "Password contains common patterns")
+		feedback.Suggestions = append(feedback.Suggestions, "Use a more unique combination of characters")
+	}
+
+	if found.sequential {
+		feedback.Warnings = append(feedback.Warnings, "Password contains sequential characters")
+		feedback.Suggestions = append(feedback.Suggestions, "Avoid keyboard patterns and sequential characters")
+	}
+
+	if found.repeated {
+		feedback.Warnings = append(feedback.Warnings, "Password contains repeated patterns")
+		feedback.Suggestions = append(feedback.Suggestions, "Avoid repeating character sequences")
+	}
+
+	if found.guessable {
+		feedback.Warnings = append(feedback.Warnings, "Password is easy to guess")
+		feedback.Suggestions = append(feedback.Suggestions, "Avoid predictable words, dates, and substitutions")
+	}
+
+	// Check character variety
+	if !reqs.Uppercase {
+		feedback.Suggestions = append(feedback.Suggestions, "Add uppercase letters")
+	}
+	if !reqs.Lowercase {
+		feedback.Suggestions = append(feedback.Suggestions, "Add lowercase letters")
+	}
+	if !reqs.Numbers {
+		feedback.Suggestions = append(feedback.Suggestions, "Add numbers")
+	}
+	if !reqs.SpecialChars {
+		feedback.Suggestions = append(feedback.Suggestions, "Add special characters")
+	}
+
+	// Length suggestions
+	if len(password) < 12 {
+		feedback.Suggestions = append(feedback.Suggestions, "Use a longer password (12+ characters)")
+	}
+
+	// Score-based suggestions
+	if score < 40 {
+		feedback.Suggestions = append(feedback.Suggestions, "Consider using a passphrase with multiple words")
+	}
+	if score < 60 {
+		feedback.Suggestions = append(feedback.Suggestions, "Mix different character types more thoroughly")
+	}
+}
+
+// reuseSlice empties a slice, keeping its capacity, or makes one with capacity
+// n; feedback slices are never nil so that they encode as empty JSON arrays
+func reuseSlice(s []string, n int) []string {
+	if s == nil {
+		return make([]string, 0, n)
+	}
+	return s[:0]
+}
+
+// hasRepeatedPatterns checks for repeated character patterns like "abcabc". A
+// pattern of length n followed by itself is a run of n bytes that each equal
+// the byte n further on, so each length takes a single pass and long
+// passphrases cost quadratic rather than cubic time.
+func hasRepeatedPatterns(password string) bool {
+	for patternLen := 2; patternLen <= len(password)/2; patternLen++ {
+		run := 0
+		for i := 0; i+patternLen < len(password); i++ {
+			if password[i] != password[i+patternLen] {
+				run = 0
+				continue
+			}
+			run++
+			if run == patternLen {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,211 @@
+package strength
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchKind is the kind of weak part found in a password
+type MatchKind string
+
+const (
+	// MatchCommonPattern is a common word or keyboard pattern, such as "password" or "asdf"
+	MatchCommonPattern MatchKind = "common_pattern"
+	// MatchSequence is a run of sequential characters or a keyboard row, such as "123456"
+	MatchSequence MatchKind = "sequence"
+	// MatchRepeatedCharacters is a character repeated three or more times, such as "aaa"
+	MatchRepeatedCharacters MatchKind = "repeated_characters"
+	// MatchRepeatedPattern is a sequence followed by itself, such as "abab"
+	MatchRepeatedPattern MatchKind = "repeated_pattern"
+	// MatchCommonPassword is a whole password found in a common password dictionary
+	MatchCommonPassword MatchKind = "common_password"
+	// MatchBannedWord is a word of an organization's banned list
+	MatchBannedWord MatchKind = "banned_word"
+)
+
+// Match is a weak part of a password. Start and End are character offsets,
+// End exclusive, so that a UI can highlight the part without the password
+// being echoed. Overlapping parts of the same kind are merged into one.
+type Match struct {
+	Kind  MatchKind `json:"kind"`
+	Start int       `json:"start"`
+	End   int       `json:"end"`
+}
+
+// Composition describes what a password is made of, for showing users which
+// parts of it make it weak
+type Composition struct {
+	// Length is the number of characters
+	Length int `json:"length"`
+	// Uppercase, Lowercase, Digits, and Special count the characters of each
+	// class; Other counts the rest, such as spaces
+	Uppercase int `json:"uppercase"`
+	Lowercase int `json:"lowercase"`
+	Digits    int `json:"digits"`
+	Special   int `json:"special"`
+	Other     int `json:"other"`
+	// UniqueChars is the number of distinct characters
+	UniqueChars int `json:"unique_chars"`
+	// LongestRun is the length of the longest run of one repeated character
+	LongestRun int `json:"longest_run"`
+	// Matches are the weak parts found, by position
+	Matches []Match `json:"matches"`
+}
+
+// Composition counts the characters of a password by class and finds the
+// parts the checker penalizes: common patterns, sequences, and repetition,
+// including the key walks of the checker's layout
+func (c *Checker) Composition(password string) Composition {
+	composition := Composition{Matches: []Match{}}
+	unique := make(map[rune]bool)
+	run, previous := 0, rune(-1)
+	for _, char := range password {
+		composition.Length++
+		switch {
+		case unicode.IsUpper(char):
+			composition.Uppercase++
+		case unicode.IsLower(char):
+			composition.Lowercase++
+		case unicode.IsDigit(char):
+			composition.Digits++
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			composition.Special++
+		default:
+			composition.Other++
+		}
+		unique[char] = true
+
+		if char == previous {
+			run++
+		} else {
+			run = 1
+		}
+		if run > composition.LongestRun {
+			composition.LongestRun = run
+		}
+		previous = char
+	}
+	composition.UniqueChars = len(unique)
+
+	// Lowering keeps the number of characters, so character offsets in the
+	// lowercase copy are offsets in the password
+	lower := analyze(password).lower
+	lowerOffsets := newCharOffsets(lower)
+	passwordOffsets := newCharOffsets(password)
+	add := func(kind MatchKind, offsets charOffsets, spans [][2]int) {
+		for _, span := range spans {
+			composition.Matches = append(composition.Matches, Match{
+				Kind:  kind,
+				Start: offsets[span[0]],
+				End:   offsets[span[1]],
+			})
+		}
+	}
+	add(MatchCommonPattern, lowerOffsets, commonPatterns.spansIn(lower))
+	add(MatchCommonPattern, lowerOffsets, c.walks.common.spansIn(lower))
+	add(MatchSequence, lowerOffsets, sequences.spansIn(lower))
+	add(MatchSequence, lowerOffsets, c.walks.sequential.spansIn(lower))
+	add(MatchRepeatedCharacters, passwordOffsets, repeatedCharacterSpans(password))
+	add(MatchRepeatedPattern, passwordOffsets, repeatedPatternSpans(password))
+	composition.Matches = MergeMatches(composition.Matches)
+	return composition
+}
+
+// charOffsets converts byte offsets of a string into character offsets
+type charOffsets []int
+
+// newCharOffsets indexes the characters of a string
+func newCharOffsets(s string) charOffsets {
+	offsets := make(charOffsets, len(s)+1)
+	char := 0
+	for i := 0; i < len(s); i++ {
+		if i > 0 && utf8.RuneStart(s[i]) {
+			char++
+		}
+		offsets[i] = char
+	}
+	offsets[len(s)] = utf8.RuneCountInString(s)
+	return offsets
+}
+
+// MergeMatches sorts matches by position and merges the overlapping or
+// adjacent matches of each kind, for callers adding matches of their own to
+// a Composition
+func MergeMatches(matches []Match) []Match {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Kind != matches[j].Kind {
+			return matches[i].Kind < matches[j].Kind
+		}
+		return matches[i].Start < matches[j].Start
+	})
+
+	merged := matches[:0]
+	for _, match := range matches {
+		if last := len(merged) - 1; last >= 0 && merged[last].Kind == match.Kind && match.Start <= merged[last].End {
+			if match.End > merged[last].End {
+				merged[last].End = match.End
+			}
+			continue
+		}
+		merged = append(merged, match)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Start < merged[j].Start
+	})
+	return merged
+}
+
+// spansIn returns the byte spans of every occurrence of the patterns in s
+func (t patternTable) spansIn(s string) [][2]int {
+	var spans [][2]int
+	for _, length := range t.lengths {
+		for i := 0; i+length <= len(s); i++ {
+			if t.patterns[s[i:i+length]] {
+				spans = append(spans, [2]int{i, i + length})
+			}
+		}
+	}
+	return spans
+}
+
+// repeatedCharacterSpans returns the byte spans of the runs of three or more
+// identical characters
+func repeatedCharacterSpans(password string) [][2]int {
+	var spans [][2]int
+	start, run, previous := 0, 0, rune(-1)
+	for i, char := range password {
+		if char != previous {
+			if run >= 3 {
+				spans = append(spans, [2]int{start, i})
+			}
+			start, run, previous = i, 0, char
+		}
+		run++
+	}
+	if run >= 3 {
+		spans = append(spans, [2]int{start, len(password)})
+	}
+	return spans
+}
+
+// repeatedPatternSpans returns the byte spans of the sequences of two or more
+// bytes followed by themselves, as hasRepeatedPatterns finds them
+func repeatedPatternSpans(password string) [][2]int {
+	var spans [][2]int
+	for patternLen := 2; patternLen <= len(password)/2; patternLen++ {
+		run := 0
+		for i := 0; i+patternLen <= len(password); i++ {
+			if i+patternLen < len(password) && password[i] == password[i+patternLen] {
+				run++
+				continue
+			}
+			if run >= patternLen {
+				spans = append(spans, [2]int{i - run, i + patternLen})
+			}
+			run = 0
+		}
+	}
+	return spans
+}
@@ -0,0 +1,56 @@
+package strength
+
+import (
+	"embed"
+	"strings"
+	"sync"
+)
+
+// rankedDictionaryFiles are frequency lists, most common first, one
+// lowercase word per line: common passwords, English words by frequency in
+// film and television subtitles, first names, and US surnames. They are the
+// lists of zxcvbn (MIT license), with surnames cut to the 10,000 most common.
+//
+//go:embed dictionaries/*.txt
+var rankedDictionaryFiles embed.FS
+
+// rankedDictionary maps words to their frequency rank, 1 for the most common
+type rankedDictionary struct {
+	name  string
+	ranks map[string]int
+}
+
+// Names of the ranked dictionaries, by file
+var rankedDictionaryNames = []string{"passwords", "english", "female_names", "male_names", "surnames"}
+
+var (
+	rankedDictionariesOnce sync.Once
+	rankedDictionaries     []rankedDictionary
+	// maxDictionaryWord is the length of the longest word, beyond which
+	// substrings of a password need not be looked up
+	maxDictionaryWord int
+)
+
+// loadRankedDictionaries indexes the embedded frequency lists on first use,
+// so that programs importing the package without estimating guesses do not
+// pay for them
+func loadRankedDictionaries() []rankedDictionary {
+	rankedDictionariesOnce.Do(func() {
+		for _, name := range rankedDictionaryNames {
+			data, err := rankedDictionaryFiles.ReadFile("dictionaries/" + name + ".txt")
+			if err != nil {
+				panic("strength: missing embedded dictionary " + name)
+			}
+			words := strings.Split(strings.TrimSpace(string(data)), "\n")
+			dictionary := rankedDictionary{name: name, ranks: make(map[string]int, len(words))}
+			for i, word := range words {
+				dictionary.ranks[word] = i + 1
+				if length := len([]rune(word)); length > maxDictionaryWord {
+					maxDictionaryWord = length
+				}
+			}
+			rankedDictionaries = append(rankedDictionaries, dictionary)
+		}
+	})
+	return rankedDictionaries
+}
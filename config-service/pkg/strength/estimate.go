@@ -0,0 +1,188 @@
+package strength
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// GuessPattern is the kind of part of a password an attacker guesses as a
+// whole
+type GuessPattern string
+
+const (
+	// PatternDictionary is a common password, word, or name, possibly
+	// capitalized, reversed, or with letters replaced, as in "P@ssw0rd"
+	PatternDictionary GuessPattern = "dictionary"
+	// PatternSpatial is a walk over adjacent keys, such as "qwerty" or "zaq12wsx"
+	PatternSpatial GuessPattern = "spatial"
+	// PatternRepeat is a string repeated, such as "aaa" or "abcabc"
+	PatternRepeat GuessPattern = "repeat"
+	// PatternSequence is a run of characters a constant step apart, such as "abcd" or "9753"
+	PatternSequence GuessPattern = "sequence"
+	// PatternDate is a date, such as "14.3.91" or "19910314"
+	PatternDate GuessPattern = "date"
+	// PatternYear is a recent year, such as "1991"
+	PatternYear GuessPattern = "year"
+	// PatternBruteforce is what no other pattern explains
+	PatternBruteforce GuessPattern = "bruteforce"
+)
+
+// Attack is an attacker model whose speed turns guesses into a crack time
+type Attack string
+
+const (
+	// AttackOnlineThrottled guesses through a service that limits attempts
+	AttackOnlineThrottled Attack = "online_throttled"
+	// AttackOnlineUnthrottled guesses through a service without limits
+	AttackOnlineUnthrottled Attack = "online_unthrottled"
+	// AttackOfflineSlowHash guesses a stolen hash of a slow algorithm, such as
+	// bcrypt or PBKDF2, on many cores
+	AttackOfflineSlowHash Attack = "offline_slow_hash"
+	// AttackOfflineFastHash guesses a stolen hash of a fast algorithm, such as
+	// SHA-1 or MD5, on many GPUs
+	AttackOfflineFastHash Attack = "offline_fast_hash"
+)
+
+// attackSpeeds are the guesses per second of each attacker model
+var attackSpeeds = []struct {
+	attack           Attack
+	guessesPerSecond float64
+}{
+	{AttackOnlineThrottled, 100.0 / 3600},
+	{AttackOnlineUnthrottled, 10},
+	{AttackOfflineSlowHash, 1e4},
+	{AttackOfflineFastHash, 1e10},
+}
+
+// Guesses from which each estimate score is reached: a score of 1 resists a
+// thousand guesses, 2 a million, enough against throttled online attacks, 3
+// a hundred million, enough against unthrottled ones, and 4 ten billion, a
+// fair defense against offline attacks on slowly hashed passwords
+var scoreGuesses = [...]float64{1e3, 1e6, 1e8, 1e10}
+
+// Estimate is an estimate of how many guesses an attacker trying the most
+// likely passwords first, such as common passwords, words with capitals and
+// substitutions, keyboard walks, dates, and repeats, needs to find a password
+type Estimate struct {
+	// Guesses is the estimated number of guesses, and GuessesLog10 its
+	// base-10 logarithm
+	Guesses      float64 `json:"guesses"`
+	GuessesLog10 float64 `json:"guesses_log10"`
+	// Score grades the guesses from 0, too guessable, to 4, very unguessable
+	Score int `json:"score"`
+	// CrackTimes estimates the time each attacker model takes
+	CrackTimes []CrackTime `json:"crack_times"`
+	// Sequence is the parts of the password the estimate guesses, in order
+	Sequence []PatternMatch `json:"sequence"`
+}
+
+// CrackTime is the time an attacker model takes to guess a password
+type CrackTime struct {
+	Attack           Attack  `json:"attack"`
+	GuessesPerSecond float64 `json:"guesses_per_second"`
+	Seconds          float64 `json:"seconds"`
+	// Display is the time in words, such as "3 hours" or "centuries"
+	Display string `json:"display"`
+}
+
+// PatternMatch is a part of a password an estimate guesses as a whole. Start
+// and End are character offsets, End exclusive, so that a UI can highlight
+// the part without the password being echoed.
+type PatternMatch struct {
+	Pattern GuessPattern `json:"pattern"`
+	Start   int          `json:"start"`
+	End     int          `json:"end"`
+	Guesses float64      `json:"guesses"`
+	// Dictionary names the list of a dictionary word: passwords, english,
+	// female_names, male_names, or surnames
+	Dictionary string `json:"dictionary,omitempty"`
+	// Reversed and L33t report whether a dictionary word was spelled
+	// backwards or had letters replaced
+	Reversed bool `json:"reversed,omitempty"`
+	L33t     bool `json:"l33t,omitempty"`
+	// Keyboard names the keyboard of a walk: qwerty, azerty, qwertz,
+	// dvorak, or keypad
+	Keyboard string `json:"keyboard,omitempty"`
+}
+
+// Estimate estimates the guesses needed to find a password, looking for the
+// walks of the checker's keyboard layout as well as QWERTY and keypad ones
+func (c *Checker) Estimate(password string) Estimate {
+	m := &matcher{graphs: graphsFor(c.layout), referenceYear: time.Now().Year()}
+	chars := []rune(password)
+	result := m.mostGuessableSequence(chars, m.omnimatch(chars))
+
+	estimate := Estimate{
+		Guesses:      result.guesses,
+		GuessesLog10: math.Log10(result.guesses),
+		CrackTimes:   make([]CrackTime, len(attackSpeeds)),
+		Sequence:     make([]PatternMatch, len(result.sequence)),
+	}
+	for _, threshold := range scoreGuesses {
+		// A few guesses of margin keep exact powers of ten, such as the
+		// minimum guesses of short matches, in the lower score
+		if estimate.Guesses >= threshold+5 {
+			estimate.Score++
+		}
+	}
+	for i, speed := range attackSpeeds {
+		seconds := estimate.Guesses / speed.guessesPerSecond
+		estimate.CrackTimes[i] = CrackTime{
+			Attack:           speed.attack,
+			GuessesPerSecond: speed.guessesPerSecond,
+			Seconds:          math.Min(seconds, math.MaxFloat64),
+			Display:          displayDuration(seconds),
+		}
+	}
+	for i, match := range result.sequence {
+		part := PatternMatch{
+			Pattern:    match.pattern,
+			Start:      match.i,
+			End:        match.j + 1,
+			Guesses:    match.guesses,
+			Dictionary: match.dictionary,
+			Reversed:   match.reversed,
+			L33t:       match.l33t,
+		}
+		if match.graph != nil {
+			part.Keyboard = match.graph.name
+		}
+		estimate.Sequence[i] = part
+	}
+	return estimate
+}
+
+// Units of displayDuration, longest first
+var durationUnits = []struct {
+	name    string
+	seconds float64
+}{
+	{"year", 60 * 60 * 24 * 365},
+	{"month", 60 * 60 * 24 * 31},
+	{"day", 60 * 60 * 24},
+	{"hour", 60 * 60},
+	{"minute", 60},
+	{"second", 1},
+}
+
+// displayDuration describes a number of seconds in the largest whole unit,
+// such as "3 hours", up to "centuries"
+func displayDuration(seconds float64) string {
+	if seconds < 1 {
+		return "less than a second"
+	}
+	if seconds >= 100*durationUnits[0].seconds {
+		return "centuries"
+	}
+	for _, unit := range durationUnits {
+		if seconds >= unit.seconds {
+			n := int(math.Round(seconds / unit.seconds))
+			if n == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", n, unit.name)
+		}
+	}
+	return "less than a second"
+}
@@ -0,0 +1,322 @@
+package strength
+
+import (
+	"math"
+	"unicode"
+)
+
+// Constants of the guess estimates
+const (
+	// bruteforceCardinality is the guesses per character of the parts of a
+	// password no matcher explains. It is lower than the printable characters
+	// because attackers try likely characters first.
+	bruteforceCardinality = 10
+	// minGuessesBeforeGrowingSequence penalizes explaining a password with
+	// more parts, so that few long matches beat many short ones
+	minGuessesBeforeGrowingSequence = 10000
+	// Least guesses of a part of a password, so that a password is never
+	// estimated as easier than guessing its parts one character at a time
+	minSubmatchGuessesSingleChar = 10
+	minSubmatchGuessesMultiChar  = 50
+	// minYearSpace is the least number of years guessed around the
+	// reference year
+	minYearSpace = 20
+)
+
+// guessSequence is the most guessable explanation of a password as a
+// sequence of matches, and the number of guesses it takes
+type guessSequence struct {
+	guesses  float64
+	sequence []*guessMatch
+}
+
+// mostGuessableSequence finds the sequence of non-overlapping matches,
+// filled with brute force between them, that an attacker guessing patterns
+// of the most likely kinds first would try soonest. For a sequence of l
+// matches the guesses are l! times the product of the guesses of the matches,
+// plus a penalty growing with l. Dynamic programming over the position of
+// the last match and the length of the sequence keeps this quadratic.
+func (m *matcher) mostGuessableSequence(password []rune, matches []*guessMatch) guessSequence {
+	n := len(password)
+	if n == 0 {
+		return guessSequence{guesses: 1}
+	}
+
+	matchesByEnd := make([][]*guessMatch, n)
+	for _, match := range matches {
+		matchesByEnd[match.j] = append(matchesByEnd[match.j], match)
+	}
+
+	// best[k][l] is the best sequence of l matches covering the password up
+	// to k, if its match is set; sequences are extended shortest first, so
+	// that ties keep the same sequence every time
+	type candidate struct {
+		match   *guessMatch
+		product float64
+		guesses float64
+	}
+	best := make([][]candidate, n)
+
+	update := func(match *guessMatch, length int) {
+		k := match.j
+		product := m.guessesOf(match, n)
+		if length > 1 {
+			product *= best[match.i-1][length-1].product
+		}
+		guesses := factorial(length)*product + math.Pow(minGuessesBeforeGrowingSequence, float64(length-1))
+		for competingLength, competing := range best[k] {
+			if competing.match != nil && competingLength <= length && competing.guesses <= guesses {
+				return
+			}
+		}
+		for len(best[k]) <= length {
+			best[k] = append(best[k], candidate{})
+		}
+		best[k][length] = candidate{match: match, product: product, guesses: guesses}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, match := range matchesByEnd[k] {
+			if match.i == 0 {
+				update(match, 1)
+				continue
+			}
+			for length, previous := range best[match.i-1] {
+				if previous.match != nil {
+					update(match, length+1)
+				}
+			}
+		}
+
+		// Brute force from any position to k, after a sequence not ending in
+		// brute force
+		update(bruteforceMatch(password, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			match := bruteforceMatch(password, i, k)
+			for length, previous := range best[i-1] {
+				if previous.match != nil && previous.match.pattern != PatternBruteforce {
+					update(match, length+1)
+				}
+			}
+		}
+	}
+
+	// Unwind the best sequence covering the whole password
+	bestLength, bestGuesses := 0, math.Inf(1)
+	for length, candidate := range best[n-1] {
+		if candidate.match != nil && candidate.guesses < bestGuesses {
+			bestLength, bestGuesses = length, candidate.guesses
+		}
+	}
+	sequence := make([]*guessMatch, bestLength)
+	for k, length := n-1, bestLength; k >= 0; length-- {
+		match := best[k][length].match
+		sequence[length-1] = match
+		k = match.i - 1
+	}
+	return guessSequence{guesses: math.Min(bestGuesses, math.MaxFloat64), sequence: sequence}
+}
+
+// bruteforceMatch makes a match of the characters i to j guessed by brute force
+func bruteforceMatch(password []rune, i, j int) *guessMatch {
+	return &guessMatch{pattern: PatternBruteforce, i: i, j: j, token: password[i : j+1]}
+}
+
+// guessesOf estimates, once, the guesses of a match in a password of n
+// characters
+func (m *matcher) guessesOf(match *guessMatch, n int) float64 {
+	if match.guesses > 0 {
+		return match.guesses
+	}
+
+	minGuesses := 1.0
+	if len(match.token) < n {
+		minGuesses = minSubmatchGuessesMultiChar
+		if len(match.token) == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var guesses float64
+	switch match.pattern {
+	case PatternBruteforce:
+		guesses = bruteforceGuesses(match)
+	case PatternDictionary:
+		guesses = dictionaryGuesses(match)
+	case PatternSpatial:
+		guesses = spatialGuesses(match)
+	case PatternRepeat:
+		guesses = match.baseGuesses * float64(match.repeatCount)
+	case PatternSequence:
+		guesses = sequenceGuesses(match)
+	case PatternYear:
+		guesses = math.Max(float64(abs(match.year-m.referenceYear)), minYearSpace)
+	case PatternDate:
+		guesses = math.Max(float64(abs(match.year-m.referenceYear)), minYearSpace) * 365
+		if match.separator {
+			guesses *= 4
+		}
+	}
+	match.guesses = math.Min(math.Max(guesses, minGuesses), math.MaxFloat64)
+	return match.guesses
+}
+
+// bruteforceGuesses estimates the guesses of characters no matcher explains
+func bruteforceGuesses(match *guessMatch) float64 {
+	guesses := math.Pow(bruteforceCardinality, float64(len(match.token)))
+	// Brute force is never cheaper than the least guesses of any other match,
+	// so that it is not preferred to short matches
+	minGuesses := float64(minSubmatchGuessesMultiChar + 1)
+	if len(match.token) == 1 {
+		minGuesses = minSubmatchGuessesSingleChar + 1
+	}
+	return math.Max(guesses, minGuesses)
+}
+
+// dictionaryGuesses estimates the guesses of a dictionary word: its rank,
+// times the ways to capitalize it, substitute characters in it, and reverse it
+func dictionaryGuesses(match *guessMatch) float64 {
+	guesses := float64(match.rank) * uppercaseVariations(match.token) * l33tVariations(match)
+	if match.reversed {
+		guesses *= 2
+	}
+	return guesses
+}
+
+// uppercaseVariations estimates the ways to capitalize a word: twice for the
+// common ways, a capital first or last or every letter, and otherwise every
+// choice of up to as many capitals as the word has
+func uppercaseVariations(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, char := range token {
+		switch {
+		case unicode.IsUpper(char):
+			upper++
+		case unicode.IsLower(char):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 {
+		return 2
+	}
+	first, last := token[0], token[len(token)-1]
+	if upper == 1 && (unicode.IsUpper(first) || unicode.IsUpper(last)) {
+		return 2
+	}
+	return sumOfCombinations(upper+lower, upper, lower)
+}
+
+// l33tVariations estimates the ways to substitute the characters of a word
+// the way it was, doubling for each substitution made throughout and
+// counting every choice of characters to substitute otherwise
+func l33tVariations(match *guessMatch) float64 {
+	if !match.l33t {
+		return 1
+	}
+	variations := 1.0
+	for subbed, letter := range match.subs {
+		substituted, unsubstituted := 0, 0
+		for _, char := range match.token {
+			switch unicode.ToLower(char) {
+			case subbed:
+				substituted++
+			case letter:
+				unsubstituted++
+			}
+		}
+		if substituted == 0 || unsubstituted == 0 {
+			variations *= 2
+			continue
+		}
+		variations *= sumOfCombinations(substituted+unsubstituted, substituted, unsubstituted)
+	}
+	return variations
+}
+
+// sumOfCombinations counts the ways to pick from 1 up to the fewer of a and
+// b of n items
+func sumOfCombinations(n, a, b int) float64 {
+	limit := a
+	if b < limit {
+		limit = b
+	}
+	sum := 0.0
+	for k := 1; k <= limit; k++ {
+		sum += binomial(n, k)
+	}
+	return sum
+}
+
+// spatialGuesses estimates the guesses of a keyboard walk from the walks of
+// its length with up to as many turns, times the ways to shift its keys
+func spatialGuesses(match *guessMatch) float64 {
+	starts, degree := match.graph.startingPositions, match.graph.averageDegree
+	length := len(match.token)
+	guesses := 0.0
+	for i := 2; i <= length; i++ {
+		possibleTurns := match.turns
+		if i-1 < possibleTurns {
+			possibleTurns = i - 1
+		}
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += binomial(i-1, j-1) * starts * math.Pow(degree, float64(j))
+		}
+	}
+
+	if shifted := match.shiftedCount; shifted > 0 {
+		unshifted := length - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			guesses *= sumOfCombinations(length, shifted, unshifted)
+		}
+	}
+	return guesses
+}
+
+// sequenceGuesses estimates the guesses of a sequence from the characters it
+// may start with, those starting the alphabet or digits being likeliest, its
+// direction, and its length
+func sequenceGuesses(match *guessMatch) float64 {
+	var base float64
+	switch first := match.token[0]; {
+	case first == 'a' || first == 'A' || first == 'z' || first == 'Z' || first == '0' || first == '1' || first == '9':
+		base = 4
+	case match.sequenceSpace == 10:
+		base = 10
+	default:
+		base = 26
+	}
+	if !match.ascending {
+		base *= 2
+	}
+	return base * float64(len(match.token))
+}
+
+// binomial returns n choose k
+func binomial(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	result := 1.0
+	for d := 1; d <= k; d++ {
+		result = result * float64(n) / float64(d)
+		n--
+	}
+	return result
+}
+
+// factorial returns n!
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package strength
+
+import "strings"
+
+// keyboardGraph maps each character of a keyboard to the keys around its
+// key, in a fixed order of directions, so that walks like "qwerty",
+// "zaq12wsx", and "7896321" are found however they turn. A key lists its
+// unshifted character first, then its shifted one.
+type keyboardGraph struct {
+	name      string
+	neighbors map[rune][]string
+	// shifted holds the characters typed with shift
+	shifted map[rune]bool
+	// startingPositions is the number of characters a walk may start from and
+	// averageDegree the average number of keys next to one, which together
+	// estimate how many walks of a length and number of turns there are
+	startingPositions float64
+	averageDegree     float64
+}
+
+// Directions to the neighbors of a key: keyboards are slanted, each row
+// shifted right of the one above by half a key, and keypads aligned
+var (
+	slantedDirections = [][2]int{{-1, 0}, {0, -1}, {1, -1}, {1, 0}, {0, 1}, {-1, 1}}
+	alignedDirections = [][2]int{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+)
+
+// newKeyboardGraph builds the graph of a keyboard from its rows, whose keys
+// are separated by spaces and, on slanted keyboards, start one key right of
+// the start of the row above; a leading space skips a key
+func newKeyboardGraph(name string, slanted bool, rows ...string) *keyboardGraph {
+	directions := alignedDirections
+	if slanted {
+		directions = slantedDirections
+	}
+
+	keys := make(map[[2]int]string)
+	for y, row := range rows {
+		x := 0
+		if slanted && y > 0 {
+			x = 1
+		}
+		for _, key := range strings.Split(row, " ") {
+			if key != "" {
+				keys[[2]int{x, y}] = key
+			}
+			x++
+		}
+	}
+
+	g := &keyboardGraph{
+		name:      name,
+		neighbors: make(map[rune][]string),
+		shifted:   make(map[rune]bool),
+	}
+	degrees := 0
+	for position, key := range keys {
+		neighbors := make([]string, len(directions))
+		degree := 0
+		for i, direction := range directions {
+			neighbors[i] = keys[[2]int{position[0] + direction[0], position[1] + direction[1]}]
+			if neighbors[i] != "" {
+				degree++
+			}
+		}
+		for i, char := range []rune(key) {
+			g.neighbors[char] = neighbors
+			g.shifted[char] = i > 0
+			degrees += degree
+		}
+	}
+	g.startingPositions = float64(len(g.neighbors))
+	g.averageDegree = float64(degrees) / float64(len(g.neighbors))
+	return g
+}
+
+// Keyboards whose walks every estimate looks for
+var (
+	qwertyGraph = newKeyboardGraph("qwerty", true,
+		"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+",
+		"qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|",
+		"aA sS dD fF gG hH jJ kK lL ;: '\"",
+		"zZ xX cC vV bB nN mM ,< .> /?",
+	)
+	keypadGraph = newKeyboardGraph("keypad", false,
+		" / * -",
+		"7 8 9 +",
+		"4 5 6",
+		"1 2 3",
+		" 0 .",
+	)
+)
+
+// layoutGraphs are the graphs of the layouts but QWERTY, whose walks an
+// estimate for that layout also looks for
+var layoutGraphs = map[Layout]*keyboardGraph{
+	AZERTY: newKeyboardGraph("azerty", true,
+		"² &1 é2 \"3 '4 (5 -6 è7 _8 ç9 à0 )° =+",
+		"aA zZ eE rR tT yY uU iI oO pP ^¨ $£",
+		"qQ sS dD fF gG hH jJ kK lL mM ù% *µ",
+		"wW xX cC vV bB nN ,? ;. :/ !§",
+	),
+	QWERTZ: newKeyboardGraph("qwertz", true,
+		"^° 1! 2\" 3§ 4$ 5% 6& 7/ 8( 9) 0= ß? ´`",
+		"qQ wW eE rR tT zZ uU iI oO pP üÜ +*",
+		"aA sS dD fF gG hH jJ kK lL öÖ äÄ #'",
+		"yY xX cC vV bB nN mM ,; .: -_",
+	),
+	Dvorak: newKeyboardGraph("dvorak", true,
+		"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}",
+		"'\" ,< .> pP yY fF gG cC rR lL /? =+ \\|",
+		"aA oO eE uU iI dD hH tT nN sS -_",
+		";: qQ jJ kK xX bB mM wW vV zZ",
+	),
+}
+
+// graphsFor returns the keyboards whose walks an estimate for a layout looks
+// for: QWERTY, the layout's own, and the numeric keypad
+func graphsFor(layout Layout) []*keyboardGraph {
+	if graph, ok := layoutGraphs[layout]; ok {
+		return []*keyboardGraph{qwertyGraph, graph, keypadGraph}
+	}
+	return []*keyboardGraph{qwertyGraph, keypadGraph}
+}
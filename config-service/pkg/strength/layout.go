@@ -0,0 +1,99 @@
+package strength
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layout is a keyboard layout whose key walks, such as "azerty" or "qsdfgh",
+// are penalized like the QWERTY ones
+type Layout string
+
+const (
+	QWERTY Layout = "qwerty"
+	AZERTY Layout = "azerty"
+	QWERTZ Layout = "qwertz"
+	Dvorak Layout = "dvorak"
+)
+
+// keyboardWalks are the key walks of a layout beyond the QWERTY ones, which
+// every checker penalizes since they are common in every locale. common holds
+// the starts of the rows penalized as common patterns, sequential the
+// six-key runs penalized as sequential characters.
+type keyboardWalks struct {
+	common     patternTable
+	sequential patternTable
+}
+
+// layoutWalks are the extra key walks of each layout but QWERTY
+var layoutWalks = map[Layout]keyboardWalks{
+	AZERTY: {
+		common:     newPatternTable("azerty", "qsdf", "wxcv"),
+		sequential: newPatternTable("azerty", "zertyu", "qsdfgh", "sdfghj", "wxcvbn"),
+	},
+	QWERTZ: {
+		common:     newPatternTable("qwertz", "yxcv"),
+		sequential: newPatternTable("qwertz", "wertzu", "yxcvbn"),
+	},
+	Dvorak: {
+		common:     newPatternTable("aoeu", "pyfg", ";qjk"),
+		sequential: newPatternTable("aoeuid", "oeuidh", "htns", "pyfgcr", "yfgcrl", "qjkxbm"),
+	},
+}
+
+// regionLayouts and languageLayouts map the region and language subtags of
+// locales to the layout most keyboards there have. The region takes
+// precedence, so that fr-CA gets QWERTY and fr-CH gets QWERTZ.
+var (
+	regionLayouts = map[string]Layout{
+		"fr": AZERTY, "be": AZERTY, "mc": AZERTY,
+		"de": QWERTZ, "at": QWERTZ, "ch": QWERTZ, "li": QWERTZ, "lu": QWERTZ,
+		"cz": QWERTZ, "sk": QWERTZ, "hu": QWERTZ, "si": QWERTZ, "hr": QWERTZ,
+	}
+	languageLayouts = map[string]Layout{
+		"fr": AZERTY,
+		"de": QWERTZ, "cs": QWERTZ, "sk": QWERTZ, "hu": QWERTZ, "sl": QWERTZ, "hr": QWERTZ,
+	}
+)
+
+// Layouts returns the supported keyboard layouts
+func Layouts() []Layout {
+	return []Layout{QWERTY, AZERTY, QWERTZ, Dvorak}
+}
+
+// ParseLayout resolves a keyboard hint, either a layout name such as "azerty"
+// or a locale such as "fr-FR" or "de_CH", to a layout. Locales of unknown
+// regions and languages get QWERTY.
+func ParseLayout(hint string) (Layout, error) {
+	hint = strings.ToLower(strings.TrimSpace(hint))
+	switch layout := Layout(hint); layout {
+	case QWERTY, AZERTY, QWERTZ, Dvorak:
+		return layout, nil
+	case "":
+		return QWERTY, nil
+	}
+
+	subtags := strings.FieldsFunc(hint, func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 {
+		return "", fmt.Errorf("unknown keyboard layout or locale %q", hint)
+	}
+	for _, subtag := range subtags {
+		if len(subtag) > 8 || strings.Trim(subtag, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+			return "", fmt.Errorf("unknown keyboard layout or locale %q", hint)
+		}
+	}
+
+	// Regions are the two-letter or three-digit subtags after the language
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 2 || (len(subtag) == 3 && strings.Trim(subtag, "0123456789") == "") {
+			if layout, ok := regionLayouts[subtag]; ok {
+				return layout, nil
+			}
+			return QWERTY, nil
+		}
+	}
+	if layout, ok := languageLayouts[subtags[0]]; ok {
+		return layout, nil
+	}
+	return QWERTY, nil
+}
@@ -0,0 +1,684 @@
+package strength
+
+import (
+	"sort"
+	"strconv"
+	"unicode"
+)
+
+// guessMatch is a part of a password an attacker would guess as a whole, such
+// as a dictionary word or a keyboard walk. i and j are the offsets of its
+// first and last characters.
+type guessMatch struct {
+	pattern GuessPattern
+	i, j    int
+	token   []rune
+	guesses float64
+
+	// Dictionary words
+	dictionary string
+	rank       int
+	reversed   bool
+	l33t       bool
+	subs       map[rune]rune
+
+	// Keyboard walks
+	graph        *keyboardGraph
+	turns        int
+	shiftedCount int
+
+	// Repeats
+	baseGuesses float64
+	repeatCount int
+
+	// Sequences
+	sequenceSpace int
+	ascending     bool
+
+	// Dates and years
+	year      int
+	separator bool
+}
+
+// l33tTable lists the characters commonly substituted for letters
+var l33tTable = map[rune][]rune{
+	'a': []rune("4@"),
+	'b': []rune("8"),
+	'c': []rune("({[<"),
+	'e': []rune("3"),
+	'g': []rune("69"),
+	'i': []rune("1!|"),
+	'l': []rune("1|7"),
+	'o': []rune("0"),
+	's': []rune("$5"),
+	't': []rune("+7"),
+	'x': []rune("%"),
+	'z': []rune("2"),
+}
+
+// Limits of the matchers
+const (
+	// maxSequenceDelta is the largest step between the characters of a
+	// sequence, so that "aceg" and "9630" are sequences
+	maxSequenceDelta = 5
+	// minDateYear and maxDateYear bound the years of dates
+	minDateYear = 1000
+	maxDateYear = 2050
+)
+
+// dateSplits are the ways a date without separators of each length splits
+// into its three parts, by the offsets of the second and third
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+// matcher finds the guessable parts of a password
+type matcher struct {
+	graphs        []*keyboardGraph
+	referenceYear int
+}
+
+// omnimatch returns every part of a password the matchers find, by position
+func (m *matcher) omnimatch(password []rune) []*guessMatch {
+	lower := make([]rune, len(password))
+	for i, char := range password {
+		lower[i] = unicode.ToLower(char)
+	}
+
+	var matches []*guessMatch
+	matches = append(matches, dictionaryMatches(password, lower)...)
+	matches = append(matches, reverseDictionaryMatches(password, lower)...)
+	matches = append(matches, l33tMatches(password, lower)...)
+	for _, graph := range m.graphs {
+		matches = append(matches, spatialMatches(password, graph)...)
+	}
+	matches = append(matches, m.repeatMatches(password)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, yearMatches(password)...)
+	matches = append(matches, m.dateMatches(password)...)
+	sortMatches(matches)
+	return matches
+}
+
+// sortMatches orders matches by their first, then last, character
+func sortMatches(matches []*guessMatch) {
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].i != matches[b].i {
+			return matches[a].i < matches[b].i
+		}
+		return matches[a].j < matches[b].j
+	})
+}
+
+// dictionaryMatches finds the words of the ranked dictionaries in a password,
+// ignoring case
+func dictionaryMatches(password, lower []rune) []*guessMatch {
+	dictionaries := loadRankedDictionaries()
+
+	// Words are looked up as slices of the lowercase password, which does
+	// not allocate
+	text := string(lower)
+	offsets := make([]int, 0, len(lower)+1)
+	for offset := range text {
+		offsets = append(offsets, offset)
+	}
+	offsets = append(offsets, len(text))
+
+	var matches []*guessMatch
+	for i := range lower {
+		for j := i; j < len(lower) && j-i < maxDictionaryWord; j++ {
+			word := text[offsets[i]:offsets[j+1]]
+			for _, dictionary := range dictionaries {
+				if rank, ok := dictionary.ranks[word]; ok {
+					matches = append(matches, &guessMatch{
+						pattern:    PatternDictionary,
+						i:          i,
+						j:          j,
+						token:      password[i : j+1],
+						dictionary: dictionary.name,
+						rank:       rank,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// reverseDictionaryMatches finds the words of the ranked dictionaries spelled
+// backwards in a password, such as "drowssap"
+func reverseDictionaryMatches(password, lower []rune) []*guessMatch {
+	reversedPassword, reversedLower := reverseRunes(password), reverseRunes(lower)
+	matches := dictionaryMatches(reversedPassword, reversedLower)
+	for _, match := range matches {
+		match.token = reverseRunes(match.token)
+		match.reversed = true
+		match.i, match.j = len(password)-1-match.j, len(password)-1-match.i
+	}
+	return matches
+}
+
+// reverseRunes returns the characters of s in reverse order
+func reverseRunes(s []rune) []rune {
+	reversed := make([]rune, len(s))
+	for i, char := range s {
+		reversed[len(s)-1-i] = char
+	}
+	return reversed
+}
+
+// l33tMatches finds the words of the ranked dictionaries in a password with
+// letters replaced by similar characters, such as "p@ssw0rd". Each table of
+// substitutions the password's characters allow is tried in turn; words found
+// without any substitution and single characters are left to the other
+// matchers.
+func l33tMatches(password, lower []rune) []*guessMatch {
+	var matches []*guessMatch
+	for _, subs := range l33tSubs(lower) {
+		subbed := make([]rune, len(lower))
+		for i, char := range lower {
+			if letter, ok := subs[char]; ok {
+				char = letter
+			}
+			subbed[i] = char
+		}
+
+		for _, match := range dictionaryMatches(password, subbed) {
+			if match.j == match.i {
+				continue
+			}
+			used := make(map[rune]rune)
+			for _, char := range lower[match.i : match.j+1] {
+				if letter, ok := subs[char]; ok {
+					used[char] = letter
+				}
+			}
+			if len(used) == 0 {
+				continue
+			}
+			match.l33t = true
+			match.subs = used
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// l33tSubs returns the tables of substitutions a password allows, each
+// mapping the substituted characters it contains to one letter. Characters
+// standing for several letters, such as "1" for "i" and "l", yield a table
+// per letter; only "1", "|", and "7" do, so there are at most eight tables.
+func l33tSubs(lower []rune) []map[rune]rune {
+	present := make(map[rune]bool, len(lower))
+	for _, char := range lower {
+		present[char] = true
+	}
+	candidates := make(map[rune][]rune)
+	var chars []rune
+	for letter, substitutes := range l33tTable {
+		for _, char := range substitutes {
+			if present[char] {
+				if len(candidates[char]) == 0 {
+					chars = append(chars, char)
+				}
+				candidates[char] = append(candidates[char], letter)
+			}
+		}
+	}
+	if len(chars) == 0 {
+		return nil
+	}
+	sort.Slice(chars, func(a, b int) bool { return chars[a] < chars[b] })
+	for _, char := range chars {
+		letters := candidates[char]
+		sort.Slice(letters, func(a, b int) bool { return letters[a] < letters[b] })
+	}
+
+	subs := []map[rune]rune{{}}
+	for _, char := range chars {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range candidates[char] {
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[char] = letter
+				next = append(next, extended)
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+// spatialMatches finds the walks of three or more keys of a keyboard in a
+// password, counting their turns and shifted characters
+func spatialMatches(password []rune, graph *keyboardGraph) []*guessMatch {
+	var matches []*guessMatch
+	for i := 0; i < len(password)-1; {
+		j := i + 1
+		lastDirection, turns, shiftedCount := -1, 0, 0
+		if graph.shifted[password[i]] {
+			shiftedCount++
+		}
+		for {
+			found := false
+			if j < len(password) {
+				for direction, key := range graph.neighbors[password[j-1]] {
+					position := indexRune(key, password[j])
+					if position < 0 {
+						continue
+					}
+					found = true
+					if position == 1 {
+						shiftedCount++
+					}
+					if direction != lastDirection {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+			if found {
+				j++
+				continue
+			}
+			if j-i > 2 {
+				matches = append(matches, &guessMatch{
+					pattern:      PatternSpatial,
+					i:            i,
+					j:            j - 1,
+					token:        password[i:j],
+					graph:        graph,
+					turns:        turns,
+					shiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+	return matches
+}
+
+// indexRune returns the position of a character among those of a key, or -1
+func indexRune(key string, char rune) int {
+	position := 0
+	for _, keyChar := range key {
+		if keyChar == char {
+			return position
+		}
+		position++
+	}
+	return -1
+}
+
+// repeatMatches finds runs of a string repeated, such as "aaa" and "abcabc",
+// estimating the guesses of the repeated string on its own
+func (m *matcher) repeatMatches(password []rune) []*guessMatch {
+	var matches []*guessMatch
+	for start := 0; start < len(password); {
+		i, greedyBase, greedyCount := findRepeat(password, start, true)
+		if i < 0 {
+			break
+		}
+		_, lazyBase, lazyCount := findRepeat(password, i, false)
+
+		// A longer base can cover more of the password, as in "aabaab"; its
+		// own shortest repeating unit is then the one guessed
+		base, count := lazyBase, lazyCount
+		if greedyBase*greedyCount > lazyBase*lazyCount {
+			base, count = greedyBase, greedyCount
+			if unit := shortestPeriod(password[i : i+base]); base%unit == 0 {
+				count *= base / unit
+				base = unit
+			}
+		}
+
+		j := i + base*count - 1
+		baseToken := password[i : i+base]
+		matches = append(matches, &guessMatch{
+			pattern:     PatternRepeat,
+			i:           i,
+			j:           j,
+			token:       password[i : j+1],
+			baseGuesses: m.mostGuessableSequence(baseToken, m.omnimatch(baseToken)).guesses,
+			repeatCount: count,
+		})
+		start = j + 1
+	}
+	return matches
+}
+
+// findRepeat finds the first offset from start at which a string is followed
+// by itself, and returns it with the longest or shortest such string and how
+// many times it repeats, or -1
+func findRepeat(password []rune, start int, longest bool) (int, int, int) {
+	for i := start; i < len(password)-1; i++ {
+		maxBase := (len(password) - i) / 2
+		for step := 1; step <= maxBase; step++ {
+			base := step
+			if longest {
+				base = maxBase + 1 - step
+			}
+			if !runesEqual(password[i:i+base], password[i+base:i+2*base]) {
+				continue
+			}
+			count := 2
+			for i+(count+1)*base <= len(password) && runesEqual(password[i:i+base], password[i+count*base:i+(count+1)*base]) {
+				count++
+			}
+			return i, base, count
+		}
+	}
+	return -1, 0, 0
+}
+
+// shortestPeriod returns the length of the shortest string the token is a
+// repetition of, or the token's length
+func shortestPeriod(token []rune) int {
+	for period := 1; period < len(token); period++ {
+		if len(token)%period != 0 {
+			continue
+		}
+		repeated := true
+		for k := period; k < len(token) && repeated; k++ {
+			repeated = token[k] == token[k-period]
+		}
+		if repeated {
+			return period
+		}
+	}
+	return len(token)
+}
+
+// runesEqual reports whether two strings of characters are equal
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sequenceMatches finds runs of characters a constant step apart, such as
+// "abcd", "7531", and "ZYX"
+func sequenceMatches(password []rune) []*guessMatch {
+	if len(password) < 2 {
+		return nil
+	}
+
+	var matches []*guessMatch
+	add := func(i, j, delta int) {
+		if j-i <= 1 && abs(delta) != 1 {
+			return
+		}
+		if delta == 0 || abs(delta) > maxSequenceDelta {
+			return
+		}
+		token := password[i : j+1]
+		space := 26
+		if allRunes(token, unicode.IsDigit) {
+			space = 10
+		}
+		matches = append(matches, &guessMatch{
+			pattern:       PatternSequence,
+			i:             i,
+			j:             j,
+			token:         token,
+			sequenceSpace: space,
+			ascending:     delta > 0,
+		})
+	}
+
+	i, lastDelta, started := 0, 0, false
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k]) - int(password[k-1])
+		if !started {
+			lastDelta, started = delta, true
+		}
+		if delta == lastDelta {
+			continue
+		}
+		add(i, k-1, lastDelta)
+		i, lastDelta = k-1, delta
+	}
+	add(i, len(password)-1, lastDelta)
+	return matches
+}
+
+// allRunes reports whether every character of s satisfies f
+func allRunes(s []rune, f func(rune) bool) bool {
+	for _, char := range s {
+		if !f(char) {
+			return false
+		}
+	}
+	return true
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// yearMatches finds years from 1900 to 2099 in a password
+func yearMatches(password []rune) []*guessMatch {
+	var matches []*guessMatch
+	for i := 0; i+4 <= len(password); i++ {
+		token := password[i : i+4]
+		century := string(token[:2])
+		if !allRunes(token, isASCIIDigit) || century != "19" && century != "20" {
+			continue
+		}
+		year, _ := strconv.Atoi(string(token))
+		matches = append(matches, &guessMatch{pattern: PatternYear, i: i, j: i + 3, token: token, year: year})
+		i += 3
+	}
+	return matches
+}
+
+// isASCIIDigit reports whether char is one of 0 to 9
+func isASCIIDigit(char rune) bool {
+	return char >= '0' && char <= '9'
+}
+
+// isDateSeparator reports whether char may separate the parts of a date
+func isDateSeparator(char rune) bool {
+	switch char {
+	case ' ', '/', '\\', '_', '.', '-':
+		return true
+	}
+	return false
+}
+
+// dateMatches finds dates of four to eight digits, such as "13191" and
+// "1991-3-14", in any order of day, month, and year; years of two digits
+// are taken to be the closest to the reference year. Dates within longer
+// dates are dropped.
+func (m *matcher) dateMatches(password []rune) []*guessMatch {
+	var matches []*guessMatch
+
+	// Dates without separators, split the way putting the year closest to
+	// the reference year
+	for i := 0; i+4 <= len(password); i++ {
+		for j := i + 3; j <= i+7 && j < len(password); j++ {
+			token := password[i : j+1]
+			if !allRunes(token, isASCIIDigit) {
+				break
+			}
+			best, found := date{}, false
+			for _, split := range dateSplits[len(token)] {
+				parts := [3]int{atoi(token[:split[0]]), atoi(token[split[0]:split[1]]), atoi(token[split[1]:])}
+				if candidate, ok := m.toDate(parts); ok && (!found || abs(candidate.year-m.referenceYear) < abs(best.year-m.referenceYear)) {
+					best, found = candidate, true
+				}
+			}
+			if found {
+				matches = append(matches, &guessMatch{pattern: PatternDate, i: i, j: j, token: token, year: best.year})
+			}
+		}
+	}
+
+	// Dates with the same separator between their parts
+	for i := 0; i+6 <= len(password); i++ {
+		for j := i + 5; j <= i+9 && j < len(password); j++ {
+			parts, ok := splitDate(password[i : j+1])
+			if !ok {
+				continue
+			}
+			if d, ok := m.toDate(parts); ok {
+				matches = append(matches, &guessMatch{pattern: PatternDate, i: i, j: j, token: password[i : j+1], year: d.year, separator: true})
+			}
+		}
+	}
+
+	var outermost []*guessMatch
+	for _, match := range matches {
+		contained := false
+		for _, other := range matches {
+			if other != match && other.i <= match.i && other.j >= match.j {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			outermost = append(outermost, match)
+		}
+	}
+	return outermost
+}
+
+// splitDate splits a token of one to four digits, a separator, one or two
+// digits, the same separator, and one to four digits into its numbers
+func splitDate(token []rune) ([3]int, bool) {
+	var parts [3]int
+	first := 0
+	for first < len(token) && isASCIIDigit(token[first]) {
+		first++
+	}
+	if first < 1 || first > 4 || first == len(token) || !isASCIIDigit(safeRune(token, first+1)) {
+		return parts, false
+	}
+	separator := token[first]
+	if !isDateSeparator(separator) {
+		return parts, false
+	}
+	second := first + 1
+	for second < len(token) && isASCIIDigit(token[second]) {
+		second++
+	}
+	if second-first-1 > 2 || second >= len(token) || token[second] != separator {
+		return parts, false
+	}
+	third := token[second+1:]
+	if len(third) < 1 || len(third) > 4 || !allRunes(third, isASCIIDigit) {
+		return parts, false
+	}
+	parts[0], parts[1], parts[2] = atoi(token[:first]), atoi(token[first+1:second]), atoi(third)
+	return parts, true
+}
+
+// safeRune returns the character at i, or 0 past the end
+func safeRune(s []rune, i int) rune {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
+
+// atoi parses digits
+func atoi(digits []rune) int {
+	n := 0
+	for _, digit := range digits {
+		n = n*10 + int(digit-'0')
+	}
+	return n
+}
+
+// date is a day, month, and year read from a password
+type date struct {
+	year, month, day int
+}
+
+// toDate reads three numbers as a date in any order with the year first or
+// last, rejecting those that cannot be one
+func (m *matcher) toDate(parts [3]int) (date, bool) {
+	if parts[1] > 31 || parts[1] <= 0 {
+		return date{}, false
+	}
+	over12, over31, under1 := 0, 0, 0
+	for _, n := range parts {
+		if (n > 99 && n < minDateYear) || n > maxDateYear {
+			return date{}, false
+		}
+		if n > 31 {
+			over31++
+		}
+		if n > 12 {
+			over12++
+		}
+		if n <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return date{}, false
+	}
+
+	// A four-digit year settles which part is the year
+	splits := [2]struct {
+		year int
+		rest [2]int
+	}{
+		{parts[2], [2]int{parts[0], parts[1]}},
+		{parts[0], [2]int{parts[1], parts[2]}},
+	}
+	for _, split := range splits {
+		if split.year >= minDateYear && split.year <= maxDateYear {
+			day, month, ok := toDayMonth(split.rest)
+			return date{year: split.year, month: month, day: day}, ok
+		}
+	}
+	for _, split := range splits {
+		if day, month, ok := toDayMonth(split.rest); ok {
+			return date{year: fourDigitYear(split.year), month: month, day: day}, true
+		}
+	}
+	return date{}, false
+}
+
+// toDayMonth reads two numbers as a day and month in either order
+func toDayMonth(parts [2]int) (int, int, bool) {
+	for _, dm := range [2][2]int{parts, {parts[1], parts[0]}} {
+		if dm[0] >= 1 && dm[0] <= 31 && dm[1] >= 1 && dm[1] <= 12 {
+			return dm[0], dm[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+// fourDigitYear expands a year of two digits to the closest century
+func fourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return 1900 + year
+	default:
+		return 2000 + year
+	}
+}
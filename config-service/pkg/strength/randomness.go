@@ -0,0 +1,499 @@
+package strength
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Thresholds of EstimateRandomness. Passwords drawn uniformly from the
+// printable ASCII characters average over 7 bits of surprisal per character
+// under the model, and words with a capital, a separator, and a year under 6.
+const (
+	// GeneratedMinBits is the average surprisal per character from which a
+	// password is taken to be machine-generated
+	GeneratedMinBits = 6.0
+	// GeneratedMinLength is the shortest password judged machine-generated;
+	// shorter ones are too short to tell from a few unusual human choices
+	GeneratedMinLength = 12
+)
+
+// Randomness estimates whether a password was machine-generated rather than
+// chosen by a person
+type Randomness struct {
+	// BitsPerChar is the average surprisal of the password's characters under
+	// a character bigram model of human-chosen passwords
+	BitsPerChar float64 `json:"bits_per_char"`
+	// Generated reports whether the password looks machine-generated
+	Generated bool `json:"generated"`
+}
+
+// humanCorpus is the text the bigram model is trained on: common words,
+// names, and the shapes people give passwords, lowercased, one per line
+const humanCorpus = `password
+password1
+password123
+passw0rd
+iloveyou
+letmein
+welcome
+welcome1
+monkey
+dragon
+master
+shadow
+sunshine
+princess
+football
+baseball
+soccer
+hockey
+superman
+batman
+trustno1
+freedom
+whatever
+qwerty
+qwerty123
+asdfgh
+zxcvbn
+abc123
+123456
+12345678
+123123
+111111
+000000
+654321
+summer2024!
+winter2023!
+spring2022
+autumn2021
+fall2020
+january
+february
+march
+april
+june
+july
+august
+september
+october
+november
+december
+monday
+friday
+sunday
+michael
+jennifer
+jessica
+ashley
+daniel
+matthew
+andrew
+joshua
+thomas
+charlie
+robert
+william
+david
+james
+john
+mary
+sarah
+emily
+hannah
+olivia
+sophie
+jordan
+hunter
+tigger
+buster
+ginger
+pepper
+cookie
+chocolate
+butterfly
+flower
+orange
+banana
+apple
+cherry
+purple
+yellow
+silver
+golden
+diamond
+starwars
+pokemon
+minecraft
+computer
+internet
+secret
+access
+admin
+admin123
+login
+changeme
+hello
+hello123
+love
+lovely
+angel
+angels
+family
+friends
+forever
+maggie
+lucky
+happy
+smile
+music
+guitar
+summer
+winter
+spring
+london
+paris
+berlin
+chicago
+texas
+company2024
+mypassword
+newpassword
+correcthorsebatterystaple
+thequickbrownfoxjumpsoverthelazydog
+the
+and
+for
+you
+that
+with
+this
+have
+from
+they
+will
+would
+there
+their
+what
+about
+which
+when
+make
+like
+time
+just
+know
+take
+people
+into
+year
+your
+good
+some
+could
+them
+other
+than
+then
+look
+only
+come
+over
+think
+also
+back
+after
+work
+first
+well
+even
+want
+because
+these
+give
+most
+day
+home
+house
+world
+life
+hand
+part
+child
+place
+woman
+week
+case
+point
+number
+group
+problem
+fact
+water
+money
+story
+night
+month
+right
+study
+book
+word
+business
+issue
+side
+kind
+head
+service
+friend
+father
+mother
+brother
+sister
+power
+hour
+game
+line
+member
+city
+community
+name
+president
+team
+minute
+idea
+kid
+body
+information
+school
+face
+others
+level
+office
+door
+health
+person
+art
+war
+history
+party
+result
+change
+morning
+reason
+research
+girl
+guy
+moment
+air
+teacher
+force
+education
+dog
+cat
+horse
+tiger
+bear
+eagle
+wolf
+lion
+ocean
+river
+mountain
+forest
+garden
+rainbow
+thunder
+lightning
+storm
+fire
+ice
+snow
+star
+moon
+sun
+sky
+blue
+red
+green
+black
+white
+pink
+1990
+1985
+1999
+2000
+2001
+2010
+2015
+2019
+2020
+2021
+2022
+2023
+2024
+2025
+007
+69
+99
+12
+13
+21
+22
+23
+88
+1!
+123!
+2024!
+@home
+my_name
+first.last
+john.smith
+pass#1
+love$
+$money$
+*star*
+i love you
+blue-sky
+tiger-lily
+red-fox-7
+good_dog
+big_bear_22
+happy.day
+bears.85
+go.team!
+fan#1
+my-dog-rex
+sea.and.sun
+x-men
+`
+
+// randomnessModel is a character bigram model of human-chosen passwords with
+// case folded, trained once at package init. Characters outside printable
+// ASCII share one symbol.
+type randomnessModel struct {
+	bigram  [modelSymbols + 1][modelSymbols]float64
+	unigram [modelSymbols]float64
+}
+
+// modelSymbols is the number of symbols of the model: printable ASCII without
+// the uppercase letters, and one for any other character. The extra row of
+// the bigram table follows the start of a password.
+const modelSymbols = 95 - 26 + 1
+
+// Probabilities of the model that are not learned from the corpus
+const (
+	// bigramWeight is the weight of the bigram estimate against the unigram
+	// estimate for the next character
+	bigramWeight = 0.8
+	// unigramSmoothing is added to every unigram count, so that characters
+	// absent from the corpus are unlikely rather than impossible
+	unigramSmoothing = 0.5
+	// upperAtWordStart and upperInWord are the chances of a letter being
+	// uppercase at the start of a word and inside one
+	upperAtWordStart = 0.25
+	upperInWord      = 0.03
+)
+
+// model is built from humanCorpus at package init
+var model = newRandomnessModel(humanCorpus)
+
+// modelSymbol maps a case-folded character to its symbol of the model
+func modelSymbol(char rune) int {
+	switch {
+	case char >= 'a' && char <= 'z':
+		return int(char - 'a')
+	case char >= ' ' && char < 'A':
+		return 26 + int(char-' ')
+	case char > 'Z' && char < 'a':
+		return 26 + 33 + int(char-'[')
+	case char > 'z' && char <= '~':
+		return 26 + 33 + 6 + int(char-'{')
+	default:
+		return modelSymbols - 1
+	}
+}
+
+// newRandomnessModel trains a model on the lines of a corpus
+func newRandomnessModel(corpus string) *randomnessModel {
+	m := &randomnessModel{}
+	var bigramCounts [modelSymbols + 1][modelSymbols]float64
+	var unigramCounts [modelSymbols]float64
+
+	for _, line := range strings.Split(corpus, "\n") {
+		previous := modelSymbols
+		for _, char := range line {
+			symbol := modelSymbol(char)
+			bigramCounts[previous][symbol]++
+			unigramCounts[symbol]++
+			previous = symbol
+		}
+	}
+
+	unigramTotal := 0.0
+	for _, count := range unigramCounts {
+		unigramTotal += count + unigramSmoothing
+	}
+	for symbol, count := range unigramCounts {
+		m.unigram[symbol] = (count + unigramSmoothing) / unigramTotal
+	}
+
+	for previous := range bigramCounts {
+		total := 0.0
+		for _, count := range bigramCounts[previous] {
+			total += count
+		}
+		for symbol, count := range bigramCounts[previous] {
+			p := m.unigram[symbol]
+			if total > 0 {
+				p = bigramWeight*count/total + (1-bigramWeight)*p
+			}
+			m.bigram[previous][symbol] = p
+		}
+	}
+	return m
+}
+
+// EstimateRandomness measures the average surprisal of a password's
+// characters under a model of human-chosen passwords, and judges it
+// machine-generated when that is at least GeneratedMinBits and the password
+// is at least GeneratedMinLength characters long. People pick words, names,
+// capitals at the start, and years at the end, which the model expects;
+// generators pick every character independently, which it does not.
+func EstimateRandomness(password string) Randomness {
+	bits := 0.0
+	length := 0
+	previous := modelSymbols
+	wordStart := true
+	for _, char := range password {
+		lower := unicode.ToLower(char)
+		symbol := modelSymbol(lower)
+		bits -= math.Log2(model.bigram[previous][symbol])
+
+		if unicode.IsLetter(char) {
+			upper := upperInWord
+			if wordStart {
+				upper = upperAtWordStart
+			}
+			if lower != char {
+				bits -= math.Log2(upper)
+			} else {
+				bits -= math.Log2(1 - upper)
+			}
+		}
+
+		wordStart = !unicode.IsLetter(char)
+		previous = symbol
+		length++
+	}
+
+	if length == 0 {
+		return Randomness{}
+	}
+	perChar := bits / float64(length)
+	return Randomness{
+		BitsPerChar: math.Round(perChar*100) / 100,
+		Generated:   length >= GeneratedMinLength && perChar >= GeneratedMinBits,
+	}
+}
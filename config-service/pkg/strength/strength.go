@@ -0,0 +1,213 @@
+// Package strength scores passwords and checks them against basic
+// requirements. It is the scoring engine of config-service, usable in-process
+// by other Go services; it depends only on the standard library.
+package strength
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EngineVersion identifies the scoring rules of the package. It changes
+// whenever the same password may score differently, so that scores kept
+// from an earlier version can be told apart and discarded.
+const EngineVersion = "2"
+
+// Strength is the strength level of a password
+type Strength string
+
+const (
+	Weak       Strength = "weak"
+	Medium     Strength = "medium"
+	Strong     Strength = "strong"
+	VeryStrong Strength = "very_strong"
+)
+
+// rank orders the strength levels
+var rank = map[Strength]int{Weak: 0, Medium: 1, Strong: 2, VeryStrong: 3}
+
+// AtLeast reports whether the strength is the minimum level or stronger
+func (s Strength) AtLeast(minimum Strength) bool {
+	return rank[s] >= rank[minimum]
+}
+
+// Valid reports whether the strength is a known level
+func (s Strength) Valid() bool {
+	_, ok := rank[s]
+	return ok
+}
+
+// Requirements reports which basic requirements a password meets
+type Requirements struct {
+	Length       bool `json:"length"`
+	Uppercase    bool `json:"uppercase"`
+	Lowercase    bool `json:"lowercase"`
+	Numbers      bool `json:"numbers"`
+	SpecialChars bool `json:"special_chars"`
+}
+
+// Feedback contains warnings and suggestions
+type Feedback struct {
+	Warnings    []string `json:"warnings"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// Result is the outcome of a strength check
+type Result struct {
+	Strength     Strength     `json:"strength"`
+	Score        int          `json:"score"`
+	Feedback     Feedback     `json:"feedback"`
+	Requirements Requirements `json:"requirements"`
+	Estimate     Estimate     `json:"estimate"`
+}
+
+// CheckRequirements checks which basic requirements a password meets
+func CheckRequirements(password string) Requirements {
+	reqs := Requirements{
+		Length: len(password) >= 8,
+	}
+
+	for _, char := range password {
+		reqs.classify(char)
+	}
+
+	return reqs
+}
+
+// classify records the character class of char
+func (reqs *Requirements) classify(char rune) {
+	switch {
+	case unicode.IsUpper(char):
+		reqs.Uppercase = true
+	case unicode.IsLower(char):
+		reqs.Lowercase = true
+	case unicode.IsDigit(char):
+		reqs.Numbers = true
+	case unicode.IsPunct(char) || unicode.IsSymbol(char):
+		reqs.SpecialChars = true
+	}
+}
+
+// Category determines the strength level of a score
+func Category(score int) Strength {
+	switch {
+	case score < 40:
+		return Weak
+	case score < 60:
+		return Medium
+	case score < 80:
+		return Strong
+	default:
+		return VeryStrong
+	}
+}
+
+// commonPatterns are the keyboard patterns and words HasCommonPattern looks for
+var commonPatterns = newPatternTable(
+	"qwerty", "asdf", "zxcv", "123456", "abcdef",
+	"password", "admin", "welcome", "login",
+)
+
+// HasCommonPattern checks if a password contains common patterns
+func HasCommonPattern(password string) bool {
+	return analyze(password).hasCommonPattern()
+}
+
+// analysis is what the checks need to know about a password, gathered in a
+// single pass so that scoring a password does not scan it once per check
+type analysis struct {
+	lower        string
+	requirements Requirements
+	repeatedRun  bool
+}
+
+// analyze classifies the characters of a password, lowercases it, and looks
+// for runs of three identical bytes in one pass. The lowercase copy is only
+// allocated when the password has characters to lower.
+func analyze(password string) analysis {
+	a := analysis{
+		requirements: Requirements{Length: len(password) >= 8},
+	}
+
+	var lower strings.Builder
+	lowering := false
+	for i := 0; i < len(password); {
+		char, size := utf8.DecodeRuneInString(password[i:])
+		a.requirements.classify(char)
+
+		lowerChar := unicode.ToLower(char)
+		if lowerChar != char && !lowering {
+			lowering = true
+			lower.Grow(len(password))
+			lower.WriteString(password[:i])
+		}
+		if lowering {
+			if lowerChar != char {
+				lower.WriteRune(lowerChar)
+			} else {
+				lower.WriteString(password[i : i+size])
+			}
+		}
+
+		for j := i; !a.repeatedRun && j < i+size && j+2 < len(password); j++ {
+			a.repeatedRun = password[j] == password[j+1] && password[j] == password[j+2]
+		}
+		i += size
+	}
+
+	a.lower = password
+	if lowering {
+		a.lower = lower.String()
+	}
+	return a
+}
+
+// hasCommonPattern reports whether the password contains a common pattern or
+// a run of repeated characters
+func (a analysis) hasCommonPattern() bool {
+	return a.repeatedRun || commonPatterns.foundIn(a.lower)
+}
+
+// hasSequentialChars reports whether the password contains sequential
+// characters or a keyboard row
+func (a analysis) hasSequentialChars() bool {
+	return sequences.foundIn(a.lower)
+}
+
+// patternTable finds any of a fixed set of patterns in a string by looking up
+// each substring of a pattern length, so that a check costs one pass per
+// distinct length rather than one per pattern. Tables are built once at
+// package init.
+type patternTable struct {
+	lengths  []int
+	patterns map[string]bool
+}
+
+// newPatternTable builds a table of patterns
+func newPatternTable(patterns ...string) patternTable {
+	t := patternTable{patterns: make(map[string]bool, len(patterns))}
+	seen := make(map[int]bool)
+	for _, pattern := range patterns {
+		t.patterns[pattern] = true
+		if !seen[len(pattern)] {
+			seen[len(pattern)] = true
+			t.lengths = append(t.lengths, len(pattern))
+		}
+	}
+	sort.Ints(t.lengths)
+	return t
+}
+
+// foundIn reports whether s contains any of the patterns
+func (t patternTable) foundIn(s string) bool {
+	for _, length := range t.lengths {
+		for i := 0; i+length <= len(s); i++ {
+			if t.patterns[s[i:i+length]] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package strength
+
+import "time"
+
+// TraceStep is a rule a checker applied to a password, in the order applied
+type TraceStep struct {
+	Rule string
+	// Points is what the rule added to the score, negative for penalties
+	Points int
+	// Findings describe what the rule found, never by the characters of
+	// the password
+	Findings map[string]interface{}
+	Duration time.Duration
+}
+
+// tracer records the steps of a traced check; a nil tracer records nothing,
+// so that untraced checks pay for neither clocks nor findings
+type tracer struct {
+	steps []TraceStep
+	last  time.Time
+}
+
+// start starts timing the first step
+func (t *tracer) start() {
+	if t != nil {
+		t.last = time.Now()
+	}
+}
+
+// step records a rule that took the time since the previous one
+func (t *tracer) step(rule string, points int, findings map[string]interface{}) {
+	now := time.Now()
+	t.steps = append(t.steps, TraceStep{Rule: rule, Points: points, Findings: findings, Duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// CheckTraced is CheckInto, also returning the rules applied to the password
+// and their timing, to explain its score
+func (c *Checker) CheckTraced(password string, result *Result) []TraceStep {
+	t := &tracer{}
+	c.check(password, result, t)
+	return t.steps
+}
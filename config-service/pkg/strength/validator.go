@@ -0,0 +1,135 @@
+package strength
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Default length limits of a Validator
+const (
+	DefaultMinLength = 8
+	DefaultMaxLength = 128
+)
+
+// ErrMissingCharacterClass is returned when a password lacks an uppercase
+// letter, a lowercase letter, a number, or a special character
+var ErrMissingCharacterClass = errors.New("password must contain at least one uppercase letter, one lowercase letter, one number, and one special character")
+
+// commonWords are dictionary words and number runs rejected by Problems
+var commonWords = newPatternTable(
+	"qwerty", "asdf", "zxcv", "123456", "abcdef",
+	"password", "admin", "welcome", "login", "letmein",
+	"monkey", "dragon", "master", "shadow", "michael",
+	"654321", "111111", "222222", "000000",
+	"123123", "321321", "1234", "4321", "1111",
+)
+
+// Validator checks passwords against basic requirements
+type Validator struct {
+	minLength int
+	maxLength int
+}
+
+// ValidatorOption defines functional options for configuring a Validator
+type ValidatorOption func(*Validator)
+
+// WithLengthLimits sets the shortest and longest password accepted
+func WithLengthLimits(minLength, maxLength int) ValidatorOption {
+	return func(v *Validator) {
+		v.minLength = minLength
+		v.maxLength = maxLength
+	}
+}
+
+// Length returns the length of a password in characters, as length limits
+// count it; multi-byte characters count once
+func Length(password string) int {
+	return utf8.RuneCountInString(password)
+}
+
+// NewValidator creates a password validator
+func NewValidator(options ...ValidatorOption) *Validator {
+	v := &Validator{
+		minLength: DefaultMinLength,
+		maxLength: DefaultMaxLength,
+	}
+	for _, option := range options {
+		option(v)
+	}
+	return v
+}
+
+// MaxLength returns the longest password accepted, in characters
+func (v *Validator) MaxLength() int {
+	return v.maxLength
+}
+
+// Validate returns the first basic requirement a password fails: its length
+// in characters or a missing character class
+func (v *Validator) Validate(password string) error {
+	length := Length(password)
+	if length < v.minLength {
+		return fmt.Errorf("password must be at least %d characters long", v.minLength)
+	}
+
+	if length > v.maxLength {
+		return fmt.Errorf("password must not exceed %d characters", v.maxLength)
+	}
+
+	reqs := CheckRequirements(password)
+	if !reqs.Uppercase || !reqs.Lowercase || !reqs.Numbers || !reqs.SpecialChars {
+		return ErrMissingCharacterClass
+	}
+
+	return nil
+}
+
+// Problems returns every requirement a password fails, including common
+// words, sequential characters, and repetition; an empty result means the
+// password is strong
+func (v *Validator) Problems(password string) []string {
+	var problems []string
+
+	// Check length
+	length := Length(password)
+	if length < v.minLength {
+		problems = append(problems, fmt.Sprintf("Password must be at least %d characters long", v.minLength))
+	}
+	if length > v.maxLength {
+		problems = append(problems, fmt.Sprintf("Password must not exceed %d characters", v.maxLength))
+	}
+
+	// Check character variety
+	a := analyze(password)
+	reqs := a.requirements
+	if !reqs.Uppercase {
+		problems = append(problems, "Password must contain at least one uppercase letter")
+	}
+	if !reqs.Lowercase {
+		problems = append(problems, "Password must contain at least one lowercase letter")
+	}
+	if !reqs.Numbers {
+		problems = append(problems, "Password must contain at least one number")
+	}
+	if !reqs.SpecialChars {
+		problems = append(problems, "Password must contain at least one special character")
+	}
+
+	// Check for common patterns
+	if commonWords.foundIn(a.lower) {
+		problems = append(problems, "Password contains common patterns (avoid dictionary words, keyboard patterns, etc.)")
+	}
+
+	// Check for sequential characters
+	if a.hasSequentialChars() {
+		problems = append(problems, "Password contains sequential characters (avoid patterns like '123', 'abc', etc.)")
+	}
+
+	// Check for repeated characters
+	if a.repeatedRun || hasRepeatedPatterns(password) {
+		problems = append(problems, "Password contains repeated characters (avoid patterns like 'aaa', '111', etc.)")
+	}
+
+	return problems
+}
@@ -0,0 +1,95 @@
+package integration_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/clientip"
+	"config-service/internal/handlers"
+	"config-service/internal/logging"
+)
+
+func setupAccessLogRouter(output *bytes.Buffer, options ...handlers.AccessLogOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	logger := logging.New(output)
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.Use(handlers.LoggingMiddleware(logger, options...))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	r.GET("/broken", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	return r
+}
+
+func TestAccessLog_SamplingByStatusClass(t *testing.T) {
+	var output bytes.Buffer
+	router := setupAccessLogRouter(&output, handlers.WithAccessLogSampling(0, 1, 1))
+
+	for _, path := range []string{"/ok", "/ok", "/missing", "/broken"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"status":404`)
+	assert.Contains(t, lines[1], `"status":500`)
+}
+
+func TestAccessLog_FieldsAndLogfmt(t *testing.T) {
+	var output bytes.Buffer
+	accessLogger := logging.New(&output)
+	formatter, err := logging.NewFormatter(logging.FormatLogfmt)
+	require.NoError(t, err)
+	accessLogger.SetFormatter(formatter)
+
+	router := setupAccessLogRouter(&bytes.Buffer{},
+		handlers.WithAccessLogger(accessLogger),
+		handlers.WithAccessLogFields("method", "route", "status"))
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := output.String()
+	assert.Contains(t, line, "method=GET")
+	assert.Contains(t, line, "route=/ok")
+	assert.Contains(t, line, "status=200")
+	assert.Contains(t, line, "request_id=")
+	assert.NotContains(t, line, "test-agent")
+	assert.NotContains(t, line, "{")
+}
+
+func TestAccessLog_ClientIPFromTrustedProxy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	resolver, err := clientip.New([]string{"10.0.0.0/8"}, clientip.HeaderXForwardedFor)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(handlers.ClientIPMiddleware(resolver))
+	r.Use(handlers.LoggingMiddleware(logging.New(&output), handlers.WithAccessLogFields("client_ip")))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, remote := range []string{"10.1.2.3:443", "203.0.113.7:51000"} {
+		req, _ := http.NewRequest("GET", "/ok", nil)
+		req.RemoteAddr = remote
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"client_ip":"198.51.100.1"`)
+	assert.Contains(t, lines[1], `"client_ip":"203.0.113.7"`, "spoofed headers are ignored")
+}
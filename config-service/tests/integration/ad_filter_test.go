@@ -0,0 +1,156 @@
+package integration_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func setupADFilterTestRouter(t *testing.T, hibp http.Handler, options ...services.ADFilterOption) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	hibpServer := httptest.NewServer(hibp)
+	t.Cleanup(hibpServer.Close)
+	breaches := services.NewBreachService(logger,
+		services.WithAPIEndpoint(hibpServer.URL),
+		services.WithRetries(0, 0),
+	)
+	bannedList := services.NewBannedListService(logger)
+	bannedList.Add("contoso")
+
+	filter := services.NewADFilterService(logger, breaches, append([]services.ADFilterOption{services.WithADBannedList(bannedList)}, options...)...)
+	r := gin.New()
+	r.POST("/api/v1/password/ad-filter", handlers.ADFilterHandler(filter))
+	return r
+}
+
+func TestNTLMHash(t *testing.T) {
+	assert.Equal(t, "8846f7eaee8fb117ad06bdd830b7586c", services.NTLMHash("password"))
+}
+
+func TestADFilter_Verdicts(t *testing.T) {
+	var ranges []string
+	r := setupADFilterTestRouter(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ranges = append(ranges, req.URL.Path+"?"+req.URL.RawQuery)
+		breached := strings.ToUpper(services.NTLMHash("Summer2024!Breached"))
+		w.Write([]byte(breached[5:] + ":42\r\n"))
+	}))
+
+	evaluate := func(body map[string]interface{}) models.ADFilterVerdict {
+		var verdict models.ADFilterVerdict
+		require.Equal(t, http.StatusOK, postIdP(t, r, "/api/v1/password/ad-filter", body, &verdict))
+		return verdict
+	}
+	failed := func(verdict models.ADFilterVerdict) []models.PolicyRule {
+		var rules []models.PolicyRule
+		for _, check := range verdict.Checks {
+			if !check.Passed {
+				rules = append(rules, check.Rule)
+			}
+		}
+		return rules
+	}
+	allClasses := []string{"upper", "lower", "digit", "special"}
+
+	verdict := evaluate(map[string]interface{}{
+		"username": `CORP\jdoe`, "ntlm_hash": services.NTLMHash("Tr0ub4dor&3-horse"), "length": 17, "classes": allClasses,
+	})
+	assert.Equal(t, models.ADFilterAllow, verdict.Verdict)
+	assert.Empty(t, verdict.Skipped)
+	assert.Equal(t, []string{"/" + services.NTLMHash("Tr0ub4dor&3-horse")[:5] + "?mode=ntlm"}, ranges)
+
+	// The account name, without its domain, and common variants of it
+	verdict = evaluate(map[string]interface{}{
+		"username": `CORP\jdoe`, "ntlm_hash": strings.ToUpper(services.NTLMHash("Jdoe123!")), "length": 8, "classes": allClasses,
+	})
+	assert.Equal(t, models.ADFilterDeny, verdict.Verdict)
+	assert.Equal(t, []models.PolicyRule{models.RuleNotUsername}, failed(verdict))
+
+	// Variants of banned words
+	verdict = evaluate(map[string]interface{}{
+		"username": "jdoe", "ntlm_hash": services.NTLMHash("Contoso" + strconv.Itoa(time.Now().Year()) + "!"), "length": 12, "classes": allClasses,
+	})
+	assert.Equal(t, models.ADFilterDeny, verdict.Verdict)
+	assert.Equal(t, []models.PolicyRule{models.RuleBannedWord}, failed(verdict))
+
+	// Policy rules use the reported length and classes, and are skipped without them
+	verdict = evaluate(map[string]interface{}{
+		"username": "jdoe", "ntlm_hash": services.NTLMHash("short"), "length": 5, "classes": []string{"lower"},
+	})
+	assert.Equal(t, models.ADFilterDeny, verdict.Verdict)
+	assert.ElementsMatch(t, []models.PolicyRule{models.RuleMinLength, models.RuleUppercase, models.RuleNumbers, models.RuleSpecial}, failed(verdict))
+
+	// Breached passwords
+	verdict = evaluate(map[string]interface{}{
+		"username": "jdoe", "ntlm_hash": services.NTLMHash("Summer2024!Breached"),
+	})
+	assert.Equal(t, models.ADFilterDeny, verdict.Verdict)
+	assert.Equal(t, []models.PolicyRule{models.RuleNotBreached}, failed(verdict))
+	assert.Contains(t, verdict.Skipped, models.RuleMinLength)
+	assert.Contains(t, verdict.Skipped, models.RuleUppercase)
+
+	// Malformed hashes are rejected
+	assert.Equal(t, http.StatusBadRequest, postIdP(t, r, "/api/v1/password/ad-filter",
+		map[string]interface{}{"username": "jdoe", "ntlm_hash": "not-a-hash"}, nil))
+}
+
+func TestADFilter_LatencyBudget(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	})
+	hash := services.NTLMHash("Tr0ub4dor&3-horse")
+
+	// Fail open: the verdict arrives within the budget and allows the password
+	r := setupADFilterTestRouter(t, slow, services.WithADLatencyBudget(50*time.Millisecond))
+	var verdict models.ADFilterVerdict
+	start := time.Now()
+	require.Equal(t, http.StatusOK, postIdP(t, r, "/api/v1/password/ad-filter",
+		map[string]interface{}{"username": "jdoe", "ntlm_hash": hash}, &verdict))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, models.ADFilterAllow, verdict.Verdict)
+	assert.True(t, verdict.Degraded)
+	assert.Contains(t, verdict.Skipped, models.RuleNotBreached)
+
+	// Fail closed
+	r = setupADFilterTestRouter(t, slow, services.WithADLatencyBudget(50*time.Millisecond), services.WithADFailOpen(false))
+	require.Equal(t, http.StatusOK, postIdP(t, r, "/api/v1/password/ad-filter",
+		map[string]interface{}{"username": "jdoe", "ntlm_hash": hash}, &verdict))
+	assert.Equal(t, models.ADFilterDeny, verdict.Verdict)
+	assert.True(t, verdict.Degraded)
+}
+
+func TestADFilter_BannedWordVariants(t *testing.T) {
+	logger := setupTestLogger()
+	bannedList := services.NewBannedListService(logger)
+	bannedList.Add("contoso")
+	filter := services.NewADFilterService(logger, nil, services.WithADBannedList(bannedList), services.WithADMaxBannedVariants(1))
+	banned := func(password string) bool {
+		verdict := filter.Evaluate(context.Background(), models.ADFilterRequest{Username: "jdoe", NTLMHash: services.NTLMHash(password)})
+		return !verdict.Allowed()
+	}
+	assert.True(t, banned("Contoso123"))
+
+	// Variants of words added later are hashed in the background, and only
+	// up to the maximum: adatum sorts first, so contoso is left out
+	bannedList.Add("adatum")
+	assert.Eventually(t, func() bool { return banned("Adatum1!") }, time.Second, 10*time.Millisecond)
+	assert.False(t, banned("Contoso123"))
+}
@@ -0,0 +1,324 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/config"
+	"config-service/internal/handlers"
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+const testAdminToken = "test-admin-token"
+
+func setupAdminTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+
+	cfg := &config.Config{}
+	cfg.Admin.Token = testAdminToken
+
+	bannedListService := services.NewBannedListService(logger)
+	policyService := services.NewPolicyService(logger)
+	passwordService := services.NewPasswordService(logger, services.WithBannedList(bannedListService))
+	breachService := services.NewBreachService(logger, services.WithEnabled(false))
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+
+	r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, breachService))
+
+	apiKeyService := services.NewAPIKeyService(logger)
+	r.POST("/api/v1/password/breach-check",
+		handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck),
+		handlers.BreachCheckHandler(breachService))
+
+	admin := r.Group("/api/v1/admin", handlers.AdminAuthMiddleware(cfg.Admin.Token, apiKeyService, nil))
+	admin.GET("/cache/stats", handlers.AdminCacheStatsHandler(breachService))
+	admin.POST("/cache/flush", handlers.AdminCacheFlushHandler(breachService))
+	admin.GET("/config", handlers.AdminConfigHandler(cfg))
+	logController, _ := logging.NewController(logger, "error", logging.FormatJSON)
+	admin.GET("/logging", handlers.AdminGetLoggingHandler(logController))
+	admin.PUT("/logging", handlers.AdminUpdateLoggingHandler(logController))
+	admin.GET("/banned-words", handlers.AdminListBannedWordsHandler(bannedListService))
+	admin.POST("/banned-words", handlers.AdminAddBannedWordsHandler(bannedListService))
+	admin.DELETE("/banned-words/:word", handlers.AdminDeleteBannedWordHandler(bannedListService))
+	admin.GET("/policies", handlers.AdminListPoliciesHandler(policyService))
+	admin.GET("/api-keys", handlers.AdminListAPIKeysHandler(apiKeyService))
+	admin.POST("/api-keys", handlers.AdminIssueAPIKeyHandler(apiKeyService))
+	admin.POST("/api-keys/:id/rotate", handlers.AdminRotateAPIKeyHandler(apiKeyService))
+	admin.DELETE("/api-keys/:id", handlers.AdminRevokeAPIKeyHandler(apiKeyService))
+
+	return r
+}
+
+func adminRequest(method, path string, body []byte) *http.Request {
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	return req
+}
+
+func TestAdminAPI_RequiresToken(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/cache/stats", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminAPI_ConfigIsRedacted(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/config", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), testAdminToken)
+	assert.Contains(t, w.Body.String(), "[REDACTED]")
+}
+
+func TestAdminAPI_Logging(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/logging", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"error","format":"json"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("PUT", "/api/v1/admin/logging", []byte(`{"level":"debug","format":"text"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"debug","format":"text"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("PUT", "/api/v1/admin/logging", []byte(`{"level":"verbose"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminAPI_BannedWords(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	body, err := json.Marshal(models.BannedWordsRequest{Words: []string{"Acme", "acme", "widget"}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/banned-words", body))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var addResponse map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &addResponse))
+	assert.Equal(t, float64(2), addResponse["added"])
+
+	// Passwords containing a banned word receive a warning
+	checkBody, err := json.Marshal(models.PasswordRequest{Password: "MyAcme!Pass9"})
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBuffer(checkBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var checkResponse models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &checkResponse))
+	assert.Contains(t, checkResponse.Feedback.Warnings, "Password contains a banned word")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("DELETE", "/api/v1/admin/banned-words/acme", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("DELETE", "/api/v1/admin/banned-words/acme", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminAPI_PoliciesAndCache(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/policies", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), models.DefaultPolicyID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/cache/stats", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stats models.CacheStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 0, stats.Entries)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/cache/flush", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminAPI_BannedWordsPagination(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	body, err := json.Marshal(models.BannedWordsRequest{Words: []string{"delta", "alpha", "charlie", "bravo", "echo"}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/banned-words", body))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	type listResponse struct {
+		Words []models.BannedWord `json:"words"`
+		Page  models.PageInfo     `json:"page"`
+	}
+
+	var seen []string
+	path := "/api/v1/admin/banned-words?limit=2"
+	for i := 0; i < 5 && path != ""; i++ {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest("GET", path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response listResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 5, response.Page.Total)
+		for _, word := range response.Words {
+			seen = append(seen, word.Word)
+		}
+
+		path = ""
+		if response.Page.NextCursor != "" {
+			path = "/api/v1/admin/banned-words?limit=2&cursor=" + response.Page.NextCursor
+		}
+	}
+	assert.Equal(t, []string{"alpha", "bravo", "charlie", "delta", "echo"}, seen)
+
+	// Filtering and descending order
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/banned-words?name_contains=h&order=desc", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var filtered listResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &filtered))
+	require.Len(t, filtered.Words, 3)
+	assert.Equal(t, "echo", filtered.Words[0].Word)
+	assert.Equal(t, "alpha", filtered.Words[2].Word)
+
+	// Invalid parameters are rejected
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/banned-words?sort=size", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminAPI_APIKeyLifecycle(t *testing.T) {
+	router := setupAdminTestRouter()
+
+	breachCheck := func(key string) int {
+		body, _ := json.Marshal(models.PasswordRequest{Password: "MyStr0ng!Pass"})
+		req, _ := http.NewRequest("POST", "/api/v1/password/breach-check", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, breachCheck(""))
+
+	// Issue a check-only key
+	body, err := json.Marshal(models.APIKeyRequest{Name: "signup", Tenant: "acme", Scopes: []string{models.ScopeCheck}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/api-keys", body))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var issued models.APIKeySecretResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	assert.NotEmpty(t, issued.Key)
+	assert.Equal(t, http.StatusOK, breachCheck(issued.Key))
+
+	// Check-only keys cannot reach the admin API
+	req, _ := http.NewRequest("GET", "/api/v1/admin/api-keys", nil)
+	req.Header.Set("X-API-Key", issued.Key)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Listing never exposes secrets
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/api-keys", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), issued.Key)
+	assert.Contains(t, w.Body.String(), issued.ID)
+
+	// Rotation invalidates the previous secret
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/api-keys/"+issued.ID+"/rotate", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var rotated models.APIKeySecretResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.Equal(t, http.StatusUnauthorized, breachCheck(issued.Key))
+	assert.Equal(t, http.StatusOK, breachCheck(rotated.Key))
+
+	// Revocation disables the key
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("DELETE", "/api/v1/admin/api-keys/"+issued.ID, nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, breachCheck(rotated.Key))
+}
+
+func TestAdminAPI_RotateSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("admin:\n  token: "+testAdminToken+"\n"), 0o600))
+	load := func() (*config.Config, error) {
+		return config.Load(config.WithConfigFile(path))
+	}
+	cfg, err := load()
+	require.NoError(t, err)
+
+	adminToken := secrets.NewValue(cfg.Admin.Token)
+	rotator := config.NewSecretRotator(logger, cfg, load)
+	rotator.OnRotate(func(next *config.Config) {
+		adminToken.Rotate(next.Admin.Token, rotator.Grace())
+	})
+
+	r := gin.New()
+	admin := r.Group("/api/v1/admin", handlers.RotatingAdminAuthMiddleware(adminToken, services.NewAPIKeyService(logger), nil))
+	admin.POST("/secrets/rotate", handlers.AdminRotateSecretsHandler(rotator))
+
+	require.NoError(t, os.WriteFile(path, []byte("admin:\n  token: rotated-admin-token\n"), 0o600))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("POST", "/api/v1/admin/secrets/rotate", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"rotated":["admin.token"]}`, w.Body.String())
+
+	// Both the new token and, during the grace period, the old one are accepted
+	for _, token := range []string{"rotated-admin-token", testAdminToken} {
+		req, _ := http.NewRequest("POST", "/api/v1/admin/secrets/rotate", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"rotated":[]}`, w.Body.String())
+	}
+}
@@ -0,0 +1,118 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestAdmissionWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	admissionService := services.NewSecretAdmissionService(logger, services.NewPasswordService(logger), nil)
+
+	r := gin.New()
+	r.POST("/api/v1/admission/secrets", handlers.AdmissionWebhookHandler(admissionService))
+
+	review := func(kind, operation string, secret map[string]interface{}) *models.AdmissionResponse {
+		object, err := json.Marshal(secret)
+		require.NoError(t, err)
+		body, err := json.Marshal(map[string]interface{}{
+			"apiVersion": models.AdmissionAPIVersion,
+			"kind":       models.AdmissionKind,
+			"request": map[string]interface{}{
+				"uid":       "705ab4f5-6393-11e8-b7cc-42010a800002",
+				"kind":      map[string]string{"group": "", "version": "v1", "kind": kind},
+				"name":      "db-credentials",
+				"namespace": "payments",
+				"operation": operation,
+				"object":    json.RawMessage(object),
+			},
+		})
+		require.NoError(t, err)
+
+		req, _ := http.NewRequest("POST", "/api/v1/admission/secrets", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.AdmissionReview
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, models.AdmissionAPIVersion, result.APIVersion)
+		require.NotNil(t, result.Response)
+		assert.Equal(t, "705ab4f5-6393-11e8-b7cc-42010a800002", result.Response.UID)
+		return result.Response
+	}
+
+	annotated := map[string]string{models.AnnotationCheckPasswords: "true"}
+	secret := func(annotations map[string]string, data map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "db-credentials", "annotations": annotations},
+			"data":       data,
+		}
+	}
+
+	t.Run("weak password is rejected", func(t *testing.T) {
+		// "password123" base64-encoded, as in a Secret's data
+		response := review("Secret", "CREATE", secret(annotated, map[string]interface{}{"password": "cGFzc3dvcmQxMjM="}))
+		assert.False(t, response.Allowed)
+		require.NotNil(t, response.Status)
+		assert.Equal(t, http.StatusForbidden, response.Status.Code)
+		assert.Contains(t, response.Status.Message, `key "password"`)
+		assert.NotContains(t, response.Status.Message, "password123")
+	})
+
+	t.Run("strong password is allowed", func(t *testing.T) {
+		response := review("Secret", "UPDATE", map[string]interface{}{
+			"metadata":   map[string]interface{}{"annotations": annotated},
+			"stringData": map[string]string{"password": "Tr0ub4dor&3-Horse!Staple"},
+		})
+		assert.True(t, response.Allowed)
+		assert.Nil(t, response.Status)
+	})
+
+	t.Run("only listed keys are checked", func(t *testing.T) {
+		response := review("Secret", "CREATE", secret(map[string]string{
+			models.AnnotationCheckPasswords: "true",
+			models.AnnotationPasswordKeys:   "password, missing",
+		}, map[string]interface{}{
+			"username": "YWRtaW4=",
+			"password": "VHIwdWI0ZG9yJjMtSG9yc2UhU3RhcGxl",
+		}))
+		assert.True(t, response.Allowed)
+		require.Len(t, response.Warnings, 1)
+		assert.Contains(t, response.Warnings[0], `"missing"`)
+	})
+
+	t.Run("unannotated Secret is allowed", func(t *testing.T) {
+		response := review("Secret", "CREATE", secret(nil, map[string]interface{}{"password": "cGFzc3dvcmQxMjM="}))
+		assert.True(t, response.Allowed)
+	})
+
+	t.Run("other kinds are allowed", func(t *testing.T) {
+		response := review("ConfigMap", "CREATE", secret(annotated, nil))
+		assert.True(t, response.Allowed)
+	})
+
+	t.Run("missing request is rejected", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/admission/secrets", bytes.NewBufferString(`{"kind":"AdmissionReview"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestAdminAnalytics_ReportsCheckOutcomes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:           logger,
+		PasswordService:  services.NewPasswordService(logger),
+		AnalyticsService: services.NewAnalyticsService(services.WithAnalyticsMinChecks(3)),
+		AdminToken:       secrets.NewValue("admin-secret"),
+	})
+	check := func(password string) {
+		req := httptest.NewRequest("POST", "/api/v1/password/check", strings.NewReader(`{"password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	report := func() models.AnalyticsReport {
+		req := httptest.NewRequest("GET", "/api/v1/admin/analytics", nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var report models.AnalyticsReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return report
+	}
+
+	check("Password1!")
+	check("Password1!")
+	assert.True(t, report().Suppressed)
+
+	check("Tr0ub4dor&3-Horse!Staple")
+	analytics := report()
+	assert.False(t, analytics.Suppressed)
+	assert.EqualValues(t, 3, analytics.Checks)
+	assert.EqualValues(t, 2, analytics.PolicyFailures)
+	assert.Contains(t, analytics.TopViolations, models.RuleViolations{Rule: models.RuleMinStrength, Count: 2, Percent: 66.67})
+	var scored int64
+	for _, bucket := range analytics.ScoreDistribution {
+		scored += bucket.Count
+	}
+	assert.EqualValues(t, 3, scored)
+}
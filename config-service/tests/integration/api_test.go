@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +16,7 @@ import (
 	"config-service/internal/handlers"
 	"config-service/internal/models"
 	"config-service/internal/services"
+	"config-service/internal/version"
 )
 
 func setupTestRouter() *gin.Engine {
@@ -37,11 +39,14 @@ func setupTestRouter() *gin.Engine {
 	r := gin.Default()
 
 	// Add middleware
+	r.Use(handlers.RequestIDMiddleware())
+	r.Use(handlers.LocaleMiddleware("en"))
 	r.Use(handlers.LoggingMiddleware(logger))
 	r.Use(handlers.ErrorHandlingMiddleware(logger))
 
 	// Health check endpoint
 	r.GET("/api/v1/health", handlers.HealthCheckHandler)
+	r.GET("/api/v1/version", handlers.VersionHandler(map[string]bool{"breach_detection": false}))
 
 	// Password strength check endpoint
 	r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, breachService))
@@ -77,6 +82,26 @@ func TestHealthCheckHandler(t *testing.T) {
 	assert.Equal(t, "1.0.0", response["version"])
 }
 
+func TestVersionHandler(t *testing.T) {
+	router := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/version", nil)
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response version.Info
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, version.Version, response.Version)
+	assert.NotEmpty(t, response.GitCommit)
+	assert.NotEmpty(t, response.BuildTime)
+	assert.Equal(t, map[string]bool{"breach_detection": false}, response.Features)
+}
+
 func TestPasswordCheckHandler_ValidPassword(t *testing.T) {
 	router := setupTestRouter()
 
@@ -100,9 +125,9 @@ func TestPasswordCheckHandler_ValidPassword(t *testing.T) {
 				scoreMax   int
 			}{
 				statusCode: http.StatusOK,
-				strength:   models.StrengthMedium,
-				scoreMin:   40,
-				scoreMax:   59,
+				strength:   models.StrengthWeak,
+				scoreMin:   0,
+				scoreMax:   39,
 			},
 		},
 		{
@@ -235,6 +260,34 @@ func TestPasswordCheckHandler_InvalidPassword(t *testing.T) {
 	}
 }
 
+func TestPasswordCheckHandler_MaxLength(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+	passwordService := services.NewPasswordService(logger, services.WithMaxLength(256))
+	r := gin.New()
+	r.Use(handlers.LocaleMiddleware("en"))
+	r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, nil))
+
+	check := func(password, language string) *httptest.ResponseRecorder {
+		jsonBody, err := json.Marshal(models.PasswordRequest{Password: password})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", language)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// A long passphrase beyond the default limit is scored
+	passphrase := strings.Repeat("Correct-Horse-Battery-Staple-", 6) + "9!"
+	assert.Equal(t, http.StatusOK, check(passphrase, "en").Code)
+
+	w := check(strings.Repeat("Aa1!", 65), "es")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "la contraseña no debe superar los 256 caracteres")
+}
+
 func TestBreachCheckHandler(t *testing.T) {
 	router := setupTestRouter()
 
@@ -278,3 +331,231 @@ func TestBreachCheckHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestResponseMetadataHeaders(t *testing.T) {
+	router := setupTestRouter()
+
+	requestBody := models.PasswordRequest{
+		Password: "MyStr0ng!Pass",
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+	assert.Equal(t, "1.0.0", w.Header().Get("X-Service-Version"))
+
+	serverTiming := w.Header().Get("Server-Timing")
+	assert.Contains(t, serverTiming, "bind;dur=")
+	assert.Contains(t, serverTiming, "strength;dur=")
+	assert.Contains(t, serverTiming, "breach;dur=")
+	assert.Contains(t, serverTiming, "total;dur=")
+}
+
+func TestErrorResponseIncludesRequestID(t *testing.T) {
+	router := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString("{"))
+	req.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, w.Header().Get("X-Request-ID"), response["request_id"])
+}
+
+func TestPasswordCheckHandler_LocalizedResponse(t *testing.T) {
+	router := setupTestRouter()
+
+	requestBody := models.PasswordRequest{
+		Password: "Password1!",
+	}
+
+	jsonBody, err := json.Marshal(requestBody)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "es-ES,es;q=0.9,en;q=0.8")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "es", w.Header().Get("Content-Language"))
+
+	var response models.PasswordResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Contains(t, response.Feedback.Warnings, "La contraseña contiene patrones comunes")
+}
+
+func TestPasswordCheckHandler_KeyboardLayout(t *testing.T) {
+	router := setupTestRouter()
+
+	check := func(request models.PasswordRequest) (int, models.PasswordResponse) {
+		jsonBody, err := json.Marshal(request)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		var response models.PasswordResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	code, qwerty := check(models.PasswordRequest{Password: "Azerty-Tour!48"})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, qwerty.Feedback.Warnings)
+
+	code, azerty := check(models.PasswordRequest{Password: "Azerty-Tour!48", KeyboardLayout: "fr-FR"})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, azerty.Feedback.Warnings, "Password contains common patterns")
+	assert.Less(t, azerty.Score, qwerty.Score)
+
+	code, _ = check(models.PasswordRequest{Password: "Azerty-Tour!48", KeyboardLayout: "colemak?"})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestRequestIDCorrelation(t *testing.T) {
+	router := setupTestRouter()
+
+	// A well-formed inbound request ID is reused
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/health", nil)
+	req.Header.Set("X-Request-ID", "client-trace-1234")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "client-trace-1234", w.Header().Get("X-Request-ID"))
+
+	// A malformed inbound request ID is replaced with a generated UUID
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/health", nil)
+	req.Header.Set("X-Request-ID", "bad id\nwith newline")
+	router.ServeHTTP(w, req)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, w.Header().Get("X-Request-ID"))
+}
+
+func TestPasswordCompositionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+	bannedList := services.NewBannedListService(logger)
+	bannedList.Add("acme")
+	passwordService := services.NewPasswordService(logger, services.WithBannedList(bannedList), services.WithMaxLength(20))
+
+	r := gin.New()
+	r.POST("/api/v1/password/composition", handlers.PasswordCompositionHandler(passwordService))
+	compose := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/password/composition", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Candidates shorter than the minimum length are described too
+	w := compose(`{"password": "Acme123"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var composition models.PasswordComposition
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &composition))
+	assert.Equal(t, 7, composition.Length)
+	assert.Equal(t, 1, composition.Uppercase)
+	assert.Equal(t, 3, composition.Digits)
+	assert.Equal(t, []models.PasswordMatch{{Kind: "banned_word", Start: 0, End: 4}}, composition.Matches)
+
+	// The keyboard hint adds the walks of its layout
+	w = compose(`{"password": "qsdfgh", "keyboard_layout": "fr-FR"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"kind":"sequence","start":0,"end":6}`)
+
+	assert.Equal(t, http.StatusBadRequest, compose(`{"password": "x", "keyboard_layout": "??"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, compose(`{}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, compose(`{"password": "`+strings.Repeat("a", 21)+`"}`).Code)
+}
+
+func TestPasswordGenerateHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker(), services.WithGeneratedMaxLength(64))
+
+	r := gin.New()
+	r.POST("/api/v1/password/generate", handlers.PasswordGenerateHandler(generator))
+	generate := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/password/generate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without options, a strong password of the default length
+	w := generate("")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var generated models.GenerateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generated))
+	assert.Len(t, generated.Password, 20)
+	assert.Contains(t, []models.PasswordStrength{models.StrengthStrong, models.StrengthVeryStrong}, generated.Strength)
+	assert.Greater(t, generated.Score, 0)
+
+	w = generate(`{"length": 12, "classes": ["lowercase", "digits"], "pronounceable": true}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generated))
+	assert.Regexp(t, `^[a-z0-9]{12}$`, generated.Password)
+
+	assert.Equal(t, http.StatusBadRequest, generate(`{"length": 4}`).Code)
+	assert.Equal(t, http.StatusBadRequest, generate(`{"classes": ["emoji"]}`).Code)
+	w = generate(`{"length": 65}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "length must be at most 64")
+	assert.Equal(t, http.StatusBadRequest, generate(`{"classes": ["digits"], "pronounceable": true}`).Code)
+}
+
+func TestPassphraseGenerateHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker())
+
+	r := gin.New()
+	r.POST("/api/v1/password/generate-passphrase", handlers.PassphraseGenerateHandler(generator))
+	generate := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/password/generate-passphrase", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without options, six lowercase words joined by hyphens
+	w := generate("")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var generated models.PassphraseResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generated))
+	assert.Regexp(t, `^[a-z-]+$`, generated.Passphrase)
+	assert.Equal(t, 77.5, generated.Entropy)
+	assert.Greater(t, generated.Score, 0)
+
+	w = generate(`{"words": 4, "separator": ".", "capitalization": "title"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generated))
+	assert.Regexp(t, `^[A-Z][a-z-]+(\.[A-Z][a-z-]+){3}$`, generated.Passphrase)
+
+	assert.Equal(t, http.StatusBadRequest, generate(`{"words": 3}`).Code)
+	assert.Equal(t, http.StatusBadRequest, generate(`{"words": 21}`).Code)
+	assert.Equal(t, http.StatusBadRequest, generate(`{"capitalization": "camel"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, generate(`{"separator": "and"}`).Code)
+}
@@ -0,0 +1,107 @@
+package integration_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/auth"
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestPasswordCheck_IssuesAttestationTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	attester, err := auth.NewAttester("config-service", key)
+	require.NoError(t, err)
+
+	logger := setupTestLogger()
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		Attester:        attester,
+	})
+	check := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/password/check", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := check(`{"password":"Tr0ub4dor&3-Horse!Staple","attest":true,"attest_subject":"signup-7f3a"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Attestation)
+	assert.Equal(t, attester.KeyID(), response.Attestation.KeyID)
+
+	attestation, err := attester.Verify(response.Attestation.Token, "signup-7f3a")
+	require.NoError(t, err)
+	assert.Equal(t, "signup-7f3a", attestation.Subject)
+	assert.Equal(t, response.Score, attestation.Score)
+	assert.Equal(t, string(response.Strength), attestation.Strength)
+	assert.Equal(t, models.DefaultPolicyID, attestation.Policy)
+	assert.Nil(t, attestation.Breached, "no breach check was made")
+
+	// A token is only issued for a subject
+	w = check(`{"password":"Tr0ub4dor&3-Horse!Staple","attest":true}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "attest_subject")
+
+	// Without asking, no token is issued
+	w = check(`{"password":"Tr0ub4dor&3-Horse!Staple"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "attestation")
+
+	// The key set verifying the tokens is public
+	req := httptest.NewRequest("GET", "/api/v1/attestation/jwks", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, attester.KeyID(), jwks.Keys[0]["kid"])
+	assert.Equal(t, "ES256", jwks.Keys[0]["alg"])
+	assert.NotContains(t, jwks.Keys[0], "d")
+}
+
+func TestPasswordCheck_RejectsAttestationWhenDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+	})
+
+	req := httptest.NewRequest("POST", "/api/v1/password/check", strings.NewReader(`{"password":"Tr0ub4dor&3-Horse!Staple","attest":true,"attest_subject":"signup-7f3a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "attestation tokens are not enabled")
+
+	req = httptest.NewRequest("GET", "/api/v1/attestation/jwks", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,132 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/audit"
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func setupAuditTestRouter(t *testing.T) (*gin.Engine, string, *models.APIKeySecretResponse) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewFileSink(path)
+	require.NoError(t, err)
+	auditLogger := audit.NewLogger(logger, sink)
+	t.Cleanup(func() { auditLogger.Close() })
+
+	apiKeyService := services.NewAPIKeyService(logger)
+	key, err := apiKeyService.Issue("ci", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+
+	passwordService := services.NewPasswordService(logger)
+	breachService := services.NewBreachService(logger, services.WithEnabled(false))
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+
+	password := r.Group("/api/v1/password",
+		handlers.AuditMiddleware(auditLogger),
+		handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck))
+	password.POST("/check", handlers.PasswordCheckHandler(passwordService, breachService))
+
+	admin := r.Group("/api/v1/admin", handlers.AdminAuthMiddleware(testAdminToken, nil, nil))
+	admin.GET("/audit/export", handlers.AdminAuditExportHandler(auditLogger))
+
+	return r, path, key
+}
+
+func TestAudit_RecordsOperationsWithoutPasswords(t *testing.T) {
+	router, path, key := setupAuditTestRouter(t)
+
+	passwords := []string{"Password1!", "C0mpl3x!Passw0rd#2024"}
+	for _, password := range passwords {
+		body, _ := json.Marshal(models.PasswordRequest{Password: password})
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key.Key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// Unauthenticated request
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"whatever1"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for _, password := range passwords {
+		assert.NotContains(t, string(contents), password)
+	}
+	assert.NotContains(t, string(contents), key.Key)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/audit/export", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var events []audit.Event
+	decoder := json.NewDecoder(w.Body)
+	for decoder.More() {
+		var event audit.Event
+		require.NoError(t, decoder.Decode(&event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 3)
+
+	assert.Equal(t, key.ID, events[0].Actor)
+	assert.Equal(t, "acme", events[0].Tenant)
+	assert.Equal(t, "POST /api/v1/password/check", events[0].Operation)
+	assert.Equal(t, audit.ResultWeak, events[0].Result)
+	assert.NotEmpty(t, events[0].RequestID)
+	assert.Equal(t, audit.ResultPass, events[1].Result)
+	assert.Equal(t, "anonymous", events[2].Actor)
+	assert.Equal(t, audit.ResultDenied, events[2].Result)
+}
+
+func TestAudit_ExportCSVFiltersByTenant(t *testing.T) {
+	router, _, key := setupAuditTestRouter(t)
+
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"Password1!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key.Key)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/audit/export?format=csv&tenant=acme", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "result", records[0][6])
+	assert.Equal(t, "weak", records[1][6])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/audit/export?format=csv&tenant=other", nil))
+	records, err = csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/audit/export?format=xml", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
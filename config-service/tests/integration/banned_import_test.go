@@ -0,0 +1,122 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestAdminAPI_BannedWordsImport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	bannedList := services.NewBannedListService(logger)
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger, services.WithBannedList(bannedList)),
+		BannedListService: bannedList,
+		BannedImports:     services.NewBannedImportService(logger, bannedList, services.WithImportDir(t.TempDir())),
+		AdminToken:        secrets.NewValue(testAdminToken),
+		ImportMaxBytes:    1024,
+	})
+	upload := func(path, contentType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := adminRequest("POST", path, []byte(body))
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// finished waits for an import to complete or fail
+	finished := func(w *httptest.ResponseRecorder) models.BannedImportJob {
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+		var job models.BannedImportJob
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+		location := w.Header().Get("Location")
+		assert.Equal(t, "/api/v1/admin/banned-words/imports/"+job.ID, location)
+
+		require.Eventually(t, func() bool {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, adminRequest("GET", location, nil))
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+			return job.Status != models.BannedImportProcessing
+		}, 5*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	// Plain text is normalized and deduplicated
+	job := finished(upload("/api/v1/admin/banned-words/imports?source=brands", "text/plain", "# brands\nAcme\n  acme \n\nwidget\r\n"))
+	assert.Equal(t, models.BannedImportCompleted, job.Status)
+	assert.Equal(t, models.BannedImportText, job.Format)
+	assert.Equal(t, 5, job.Lines)
+	assert.Equal(t, 2, job.Words)
+	assert.Equal(t, 1, job.Duplicates)
+	assert.Equal(t, 2, job.Skipped)
+	assert.Equal(t, 2, job.Added)
+	assert.Equal(t, job.BytesTotal, job.BytesProcessed)
+	_, found := bannedList.FindBannedWord("MyAcme!Pass9")
+	assert.True(t, found)
+
+	// NDJSON lines are strings or objects with a word; others are counted as invalid
+	ndjson := `"gadget"` + "\n" + `{"word": "Widget"}` + "\n" + `{"name": "x"}` + "\n" + `not json` + "\n"
+	job = finished(upload("/api/v1/admin/banned-words/imports?source=brands", "application/x-ndjson", ndjson))
+	assert.Equal(t, models.BannedImportCompleted, job.Status)
+	assert.Equal(t, 2, job.Words)
+	assert.Equal(t, 2, job.Invalid)
+	assert.Equal(t, 1, job.Added)
+
+	// A multipart file replacing the source removes the words it no longer lists
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	require.NoError(t, writer.WriteField("note", "quarterly refresh"))
+	file, err := writer.CreateFormFile("file", "brands.jsonl")
+	require.NoError(t, err)
+	file.Write([]byte(`"acme"` + "\n"))
+	require.NoError(t, writer.Close())
+	job = finished(upload("/api/v1/admin/banned-words/imports?source=brands&replace=true", writer.FormDataContentType(), form.String()))
+	assert.Equal(t, models.BannedImportNDJSON, job.Format)
+	assert.Equal(t, 0, job.Added)
+	assert.Equal(t, 2, job.Removed)
+	assert.Equal(t, 1, bannedList.Count())
+
+	// Replacing another source leaves the words of the first alone
+	job = finished(upload("/api/v1/admin/banned-words/imports?source=other&replace=true", "text/plain", "acme\nthing\n"))
+	assert.Equal(t, models.BannedImportCompleted, job.Status)
+	assert.Equal(t, 1, job.Added)
+	assert.Equal(t, 0, job.Removed)
+	assert.Equal(t, 2, bannedList.Count())
+
+	// Finished imports are listed, most recent first
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/banned-words/imports", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Imports []models.BannedImportJob `json:"imports"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Imports, 4)
+	assert.Equal(t, job.ID, list.Imports[0].ID)
+
+	// Uploads over the limit, unknown formats, and unknown imports are rejected
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload("/api/v1/admin/banned-words/imports", "text/plain", strings.Repeat("x\n", 1000)).Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, upload("/api/v1/admin/banned-words/imports", "application/json", `{"words": []}`).Code)
+	assert.Equal(t, http.StatusBadRequest, upload("/api/v1/admin/banned-words/imports?replace=maybe", "text/plain", "x").Code)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest("GET", "/api/v1/admin/banned-words/imports/imp_unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
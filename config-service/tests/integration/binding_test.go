@@ -0,0 +1,75 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "config-service/internal/errors"
+)
+
+func TestBindingErrors_Structured(t *testing.T) {
+	router := setupTestRouter()
+
+	testCases := []struct {
+		name  string
+		body  string
+		field string
+		code  apperrors.ErrorCode
+	}{
+		{"missing field", `{}`, "password", apperrors.ErrorCodeMissingField},
+		{"too short", `{"password": "short"}`, "password", apperrors.ErrorCodePasswordTooShort},
+		{"wrong type", `{"password": 12345678}`, "password", apperrors.ErrorCodeInvalidFormat},
+		{"malformed", `{"password": `, "", apperrors.ErrorCodeInvalidFormat},
+		{"empty", ``, "", apperrors.ErrorCodeMissingField},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response struct {
+				Error     string                      `json:"error"`
+				Message   string                      `json:"message"`
+				Errors    []apperrors.ValidationError `json:"errors"`
+				RequestID string                      `json:"request_id"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "Invalid request format", response.Error)
+			assert.NotEmpty(t, response.RequestID)
+			require.Len(t, response.Errors, 1)
+			assert.Equal(t, tc.field, response.Errors[0].Field)
+			assert.Equal(t, tc.code, response.Errors[0].Code)
+
+			// Gin's raw validator messages are not passed through
+			assert.NotContains(t, response.Message, "Key: ")
+		})
+	}
+}
+
+func TestBindingErrors_KeyboardLayout(t *testing.T) {
+	router := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password": "Tr0ub4dor&3", "keyboard_layout": "??"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Errors []apperrors.ValidationError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, "keyboard_layout", response.Errors[0].Field)
+	assert.Equal(t, apperrors.ErrorCodeInvalidInput, response.Errors[0].Code)
+}
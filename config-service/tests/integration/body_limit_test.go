@@ -0,0 +1,65 @@
+package integration_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/handlers"
+	"config-service/internal/services"
+)
+
+func TestBreachAudit_RejectsBodiesOverBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0000000000000000000000000000000000A:1\r\n")
+	}))
+	defer hibp.Close()
+
+	logger := setupTestLogger()
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:           logger,
+		PasswordService:  services.NewPasswordService(logger),
+		BreachService:    services.NewBreachService(logger, services.WithAPIEndpoint(hibp.URL)),
+		BulkMaxBodyBytes: 256,
+	})
+
+	body := func(hashes int) string {
+		quoted := make([]string, hashes)
+		for i := range quoted {
+			quoted[i] = fmt.Sprintf(`"%040x"`, i)
+		}
+		return `{"hashes":[` + strings.Join(quoted, ",") + `]}`
+	}
+	serve := func(body string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/password/breach-audit", strings.NewReader(body))
+		if chunked {
+			// Hide the length, as for a chunked upload
+			req.Body = io.NopCloser(strings.NewReader(body))
+			req.ContentLength = -1
+		}
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve(body(3), false).Code)
+	assert.Equal(t, http.StatusOK, serve(body(3), true).Code)
+
+	w := serve(body(10), false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "request body exceeds 256 bytes")
+
+	w = serve(body(10), true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), handlers.ErrBodyTooLarge.Error())
+}
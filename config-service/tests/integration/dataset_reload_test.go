@@ -0,0 +1,81 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestAdminAPI_DatasetReload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	reloader := services.NewDatasetReloader(logger)
+	reloader.Register("dictionaries", func() (func(), func(), error) {
+		return func() {}, func() {}, nil
+	})
+	reloader.Register("breach_sha1", func() (func(), func(), error) {
+		return nil, nil, errors.New("dataset breach.txt: line 3 is out of order")
+	})
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		DatasetReloader: reloader,
+		AdminToken:      secrets.NewValue(testAdminToken),
+	})
+	send := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, nil))
+		return w
+	}
+	reload := func(name string) models.DatasetReload {
+		w := send("POST", "/api/v1/admin/datasets/"+name+"/reload")
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var reload models.DatasetReload
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reload))
+		location := w.Header().Get("Location")
+		assert.Equal(t, "/api/v1/admin/datasets/reloads/"+reload.ID, location)
+
+		require.Eventually(t, func() bool {
+			w := send("GET", location)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reload))
+			return reload.Status != models.DatasetReloadLoading
+		}, 5*time.Second, 10*time.Millisecond)
+		return reload
+	}
+
+	w := send("GET", "/api/v1/admin/datasets")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"datasets": ["breach_sha1", "dictionaries"]}`, w.Body.String())
+
+	assert.Equal(t, models.DatasetReloadCompleted, reload("dictionaries").Status)
+	failed := reload("breach_sha1")
+	assert.Equal(t, models.DatasetReloadFailed, failed.Status)
+	assert.Contains(t, failed.Error, "out of order")
+
+	w = send("GET", "/api/v1/admin/datasets/reloads")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Reloads []models.DatasetReload `json:"reloads"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Reloads, 2)
+	assert.Equal(t, failed.ID, list.Reloads[0].ID)
+
+	assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/admin/datasets/unknown/reload").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v1/admin/datasets/reloads/rld_unknown").Code)
+}
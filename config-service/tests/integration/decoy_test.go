@@ -0,0 +1,85 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestDecoyPasswordRaisesAlert(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	receiver := &webhookReceiver{}
+	target := httptest.NewServer(receiver)
+	defer target.Close()
+
+	logger := setupTestLogger()
+	webhooks := services.NewWebhookService(logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		webhooks.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := webhooks.Create(models.WebhookSubscriptionRequest{URL: target.URL, Events: []string{models.WebhookEventDecoyMatched}})
+	require.NoError(t, err)
+
+	decoys := services.NewDecoyService(logger, []services.Decoy{{Label: "finance-share", Digest: sha256.Sum256([]byte("Ledger-Quarter4-Close!"))}},
+		services.WithDecoyWebhooks(webhooks))
+
+	newRouter := func(passwordService *services.PasswordService) *gin.Engine {
+		r := gin.New()
+		r.Use(handlers.RequestIDMiddleware())
+		r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, nil))
+		return r
+	}
+	check := func(r *gin.Engine, password string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	withDecoys := newRouter(services.NewPasswordService(logger, services.WithDecoys(decoys)))
+	without := newRouter(services.NewPasswordService(logger))
+
+	// An ordinary password raises nothing
+	require.Equal(t, http.StatusOK, check(withDecoys, "Tr0ub4dor&3-correct-horse-battery").Code)
+
+	// A decoy is answered exactly like on a service without decoys
+	w := check(withDecoys, "Ledger-Quarter4-Close!")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, check(without, "Ledger-Quarter4-Close!").Body.String(), w.Body.String())
+
+	require.Eventually(t, func() bool { return receiver.received() == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	require.Len(t, receiver.events, 1)
+	event := receiver.events[0]
+	assert.Equal(t, models.WebhookEventDecoyMatched, event.Type)
+	assert.Equal(t, "/api/v1/password/check", event.Route)
+	assert.Equal(t, w.Header().Get("X-Request-ID"), event.RequestID)
+	var match models.DecoyMatch
+	require.NoError(t, json.Unmarshal(event.Data, &match))
+	assert.Equal(t, models.DecoyMatch{Decoy: "finance-share", Severity: models.SeverityCritical}, match)
+	assert.NotContains(t, string(receiver.bodies[0]), "Ledger-Quarter4-Close!")
+}
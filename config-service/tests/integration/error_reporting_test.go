@@ -0,0 +1,97 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/errorreport"
+	"config-service/internal/handlers"
+)
+
+func TestErrorReporting_SentryCapturesPanicsAndServerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	received := make(chan map[string]interface{}, 4)
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/store/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=publickey")
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer sentry.Close()
+
+	dsn := strings.Replace(sentry.URL, "http://", "http://publickey@", 1) + "/42"
+	reporter, err := errorreport.NewSentryReporter(setupTestLogger(), dsn, "test", "1.0.0", time.Second)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.Use(handlers.RecoveryMiddleware(setupTestLogger(), handlers.NewErrorReportPanicHook(reporter)))
+	r.Use(handlers.ErrorReportingMiddleware(reporter))
+	r.GET("/boom", func(c *gin.Context) {
+		panic("lookup for user@example.com failed")
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("upstream timeout for hash 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"))
+		c.Status(http.StatusBadGateway)
+	})
+	r.GET("/missing", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/boom", "/fail", "/missing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("X-Request-ID", "req"+strings.TrimPrefix(path, "/"))
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events := map[string]map[string]interface{}{}
+	for i := 0; i < 2; i++ {
+		select {
+		case payload := <-received:
+			tags := payload["tags"].(map[string]interface{})
+			events[tags["request_id"].(string)] = payload
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for error reports")
+		}
+	}
+
+	panicEvent := events["reqboom"]
+	require.NotNil(t, panicEvent)
+	assert.Equal(t, "fatal", panicEvent["level"])
+	assert.NotContains(t, panicEvent["message"], "user@example.com")
+	assert.Contains(t, panicEvent["extra"].(map[string]interface{})["stack"], "goroutine")
+
+	failEvent := events["reqfail"]
+	require.NotNil(t, failEvent)
+	assert.Equal(t, "error", failEvent["level"])
+	assert.Equal(t, "GET /fail", failEvent["transaction"])
+	assert.NotContains(t, failEvent["message"], "5BAA61E4")
+
+	// Client errors are not reported
+	select {
+	case payload := <-received:
+		t.Fatalf("unexpected report: %v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestParseDSN(t *testing.T) {
+	storeURL, key, err := errorreport.ParseDSN("https://abc123@o1.ingest.sentry.io/4505")
+	require.NoError(t, err)
+	assert.Equal(t, "https://o1.ingest.sentry.io/api/4505/store/", storeURL)
+	assert.Equal(t, "abc123", key)
+
+	_, _, err = errorreport.ParseDSN("https://o1.ingest.sentry.io/4505")
+	assert.Error(t, err)
+}
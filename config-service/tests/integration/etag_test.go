@@ -0,0 +1,98 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/dataset"
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestConditionalGet_ConfigurationEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	path := filepath.Join(t.TempDir(), "common.txt")
+	require.NoError(t, os.WriteFile(path, []byte("letmein\npassword\nqwerty\n"), 0o600))
+	dictionary, err := dataset.Open(path)
+	require.NoError(t, err)
+	defer dictionary.Close()
+
+	logger := setupTestLogger()
+	bannedList := services.NewBannedListService(logger)
+	bannedList.Add("acme")
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger, services.WithDictionaries(dictionary)),
+		BannedListService: bannedList,
+		PolicyService:     services.NewPolicyService(logger),
+		AdminToken:        secrets.NewValue(testAdminToken),
+	})
+	get := func(path string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/api/v1/admin/policies", "/api/v1/admin/banned-words", "/api/v1/admin/wordlists"} {
+		w := get(path)
+		require.Equal(t, http.StatusOK, w.Code, path)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag, path)
+		assert.Equal(t, etag, get(path).Header().Get("ETag"), "%s: the ETag is stable", path)
+
+		w = get(path, "If-None-Match", `"stale", `+etag)
+		assert.Equal(t, http.StatusNotModified, w.Code, path)
+		assert.Empty(t, w.Body.String(), path)
+
+		w = get(path, "If-None-Match", `"stale"`)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.NotEmpty(t, w.Body.String(), path)
+	}
+
+	// A change to the list changes its ETag
+	etag := get("/api/v1/admin/banned-words").Header().Get("ETag")
+	bannedList.Add("globex")
+	w := get("/api/v1/admin/banned-words", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// Query parameters select different documents
+	assert.NotEqual(t, get("/api/v1/admin/banned-words?limit=1").Header().Get("ETag"), w.Header().Get("ETag"))
+
+	// Wordlists are served with the ETag they are listed with
+	var listing struct {
+		Wordlists []models.WordlistInfo `json:"wordlists"`
+	}
+	require.NoError(t, json.Unmarshal(get("/api/v1/admin/wordlists").Body.Bytes(), &listing))
+	require.Len(t, listing.Wordlists, 1)
+	assert.Equal(t, models.WordlistInfo{Name: "common.txt", Entries: 3, Size: 24, ETag: listing.Wordlists[0].ETag}, listing.Wordlists[0])
+
+	w = get("/api/v1/admin/wordlists/common.txt")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "letmein\npassword\nqwerty\n", w.Body.String())
+	assert.Equal(t, listing.Wordlists[0].ETag, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, get("/api/v1/admin/wordlists/common.txt", "If-None-Match", listing.Wordlists[0].ETag).Code)
+
+	w = get("/api/v1/admin/wordlists/common.txt", "Range", "bytes=8-15")
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "password", w.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/admin/wordlists/other.txt").Code)
+}
@@ -0,0 +1,105 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+// explainedSteps returns the names of the steps of an explanation
+func explainedSteps(steps []models.ExplainStep) []string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Step
+	}
+	return names
+}
+
+func TestPasswordCheck_Explain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	banned := services.NewBannedListService(logger)
+	banned.Add("password")
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger, services.WithBannedList(banned)),
+		PolicyService: services.NewPolicyService(logger,
+			services.WithPolicies([]models.PasswordPolicy{{ID: "staff", MinLength: 12, RequireSpecial: true}}),
+		),
+		AdminToken: secrets.NewValue(testAdminToken),
+	})
+	check := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest("POST", "/api/v1/password/check", []byte(body)))
+		return w
+	}
+
+	// Without explain, responses carry no trace
+	w := check(`{"password": "Password1!"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), `"explain"`)
+
+	// The trace lists the rules run, in order, without the password
+	w = check(`{"password": "Password1!", "explain": true, "keyboard_layout": "azerty"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "Password1")
+	var response models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{
+		"validate",
+		"strength.patterns",
+		"strength.length",
+		"strength.character_variety",
+		"strength.entropy",
+		"strength.guessability",
+		"banned_words",
+	}, explainedSteps(response.Explain))
+
+	validate := response.Explain[0]
+	assert.Equal(t, float64(10), validate.Input["length"])
+	assert.Equal(t, true, validate.Output["passed"])
+	assert.Equal(t, "azerty", response.Explain[1].Input["keyboard_layout"])
+	assert.Equal(t, float64(10), response.Explain[2].Output["bytes"])
+	guessability := response.Explain[5]
+	assert.Less(t, guessability.Output["points"], float64(0))
+	assert.Equal(t, float64(1), guessability.Output["estimate_score"])
+	bannedWords := response.Explain[6]
+	assert.Equal(t, true, bannedWords.Output["found"])
+	assert.Equal(t, float64(response.Score), bannedWords.Output["score"])
+	for _, step := range response.Explain {
+		assert.GreaterOrEqual(t, step.DurationNS, int64(0), step.Step)
+	}
+
+	// Rejections explain why the password failed
+	w = check(`{"password": "Summer2024", "explain": true}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var rejected struct {
+		Explain []models.ExplainStep `json:"explain"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	require.Equal(t, []string{"validate"}, explainedSteps(rejected.Explain))
+	assert.Equal(t, false, rejected.Explain[0].Output["passed"])
+	assert.Contains(t, rejected.Explain[0].Output["error"], "special character")
+
+	w = check(`{"password": "Summer-2024", "policy_id": "staff", "explain": true}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	rejected.Explain = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	require.Equal(t, []string{"policy"}, explainedSteps(rejected.Explain))
+	policy := rejected.Explain[0]
+	assert.Equal(t, "staff", policy.Input["policy_id"])
+	assert.Equal(t, false, policy.Output["valid"])
+	assert.NotContains(t, w.Body.String(), "Summer")
+}
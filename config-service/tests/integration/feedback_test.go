@@ -0,0 +1,110 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestFeedbackOverrides(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	apiKeyService := services.NewAPIKeyService(logger)
+	acme, err := apiKeyService.Issue("web", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+	globex, err := apiKeyService.Issue("web", "globex", []string{models.ScopeCheck})
+	require.NoError(t, err)
+	feedback := services.NewFeedbackOverrideService(logger)
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware(), handlers.LocaleMiddleware(""))
+	password := r.Group("/api/v1/password", handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck))
+	password.POST("/check", handlers.PasswordCheckHandler(services.NewPasswordService(logger), nil, handlers.WithFeedbackOverrides(feedback)))
+	admin := r.Group("/api/v1/admin", handlers.AdminAuthMiddleware(testAdminToken, apiKeyService, nil))
+	admin.GET("/feedback-codes", handlers.AdminListFeedbackCodesHandler())
+	admin.GET("/feedback-overrides/:tenant", handlers.AdminGetFeedbackOverridesHandler(feedback))
+	admin.PUT("/feedback-overrides/:tenant", handlers.AdminSetFeedbackOverridesHandler(feedback))
+	admin.DELETE("/feedback-overrides/:tenant", handlers.AdminDeleteFeedbackOverridesHandler(feedback))
+
+	serve := func(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	adminHeader := map[string]string{"Authorization": "Bearer " + testAdminToken}
+	check := func(key, language string) models.PasswordFeedback {
+		w := serve("POST", "/api/v1/password/check", `{"password":"Qwerty123!"}`, map[string]string{"X-API-Key": key, "Accept-Language": language})
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.PasswordResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Feedback
+	}
+
+	// The codes are listed for the authors of the copy
+	w := serve("GET", "/api/v1/admin/feedback-codes", "", adminHeader)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `{"code":"common_pattern","message":"Password contains common patterns"}`)
+
+	before := check(acme.Key, "en")
+	require.Contains(t, before.Warnings, models.FeedbackCommonPattern.Message())
+
+	w = serve("PUT", "/api/v1/admin/feedback-overrides/acme", `{"overrides": [
+		{"code": "common_pattern", "message": "Pick something less predictable."},
+		{"code": "common_pattern", "locale": "de", "message": "Bitte etwas weniger Vorhersehbares wählen."}
+	]}`, adminHeader)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stored models.FeedbackOverrides
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stored))
+	assert.Equal(t, "acme", stored.Tenant)
+	assert.Len(t, stored.Overrides, 2)
+
+	// The tenant's copy replaces the message, per locale where it has one
+	assert.Contains(t, check(acme.Key, "en").Warnings, "Pick something less predictable.")
+	assert.Contains(t, check(acme.Key, "fr").Warnings, "Pick something less predictable.")
+	assert.Contains(t, check(acme.Key, "de").Warnings, "Bitte etwas weniger Vorhersehbares wählen.")
+	assert.Equal(t, before.Suggestions, check(acme.Key, "en").Suggestions)
+
+	// Other tenants keep the default copy
+	assert.Equal(t, before, check(globex.Key, "en"))
+
+	// Unknown codes and locales are rejected with the field at fault
+	w = serve("PUT", "/api/v1/admin/feedback-overrides/acme", `{"overrides": [
+		{"code": "common_pattern", "locale": "xx", "message": "..."},
+		{"code": "no_such_code", "message": "..."}
+	]}`, adminHeader)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var rejected struct {
+		Errors []apperrors.ValidationError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	require.Len(t, rejected.Errors, 2)
+	assert.Equal(t, "overrides[0].locale", rejected.Errors[0].Field)
+	assert.Equal(t, "overrides[1].code", rejected.Errors[1].Field)
+
+	w = serve("PUT", "/api/v1/admin/feedback-overrides/acme", `{"overrides": [{"code": "common_pattern"}]}`, adminHeader)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"overrides[0].message"`)
+
+	// Deleting the overrides restores the default copy
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/v1/admin/feedback-overrides/acme", "", adminHeader).Code)
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", "/api/v1/admin/feedback-overrides/acme", "", adminHeader).Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/api/v1/admin/feedback-overrides/acme", "", adminHeader).Code)
+	assert.Equal(t, before, check(acme.Key, "en"))
+}
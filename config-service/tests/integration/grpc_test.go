@@ -0,0 +1,181 @@
+package integration_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	passwordv1 "config-service/api/proto/password/v1"
+	"config-service/internal/grpcapi"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// startGRPC serves the gRPC API over an in-memory connection and returns a
+// client of it
+func startGRPC(t *testing.T, opts grpcapi.Options) passwordv1.PasswordServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpcapi.NewServer(opts)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return passwordv1.NewPasswordServiceClient(conn)
+}
+
+// validationFailure returns the ValidationFailure detail of an error
+func validationFailure(t *testing.T, err error) *passwordv1.ValidationFailure {
+	for _, detail := range status.Convert(err).Details() {
+		if failure, ok := detail.(*passwordv1.ValidationFailure); ok {
+			return failure
+		}
+	}
+	t.Fatalf("no validation failure detail in %v", err)
+	return nil
+}
+
+func TestGRPC_PasswordCheck(t *testing.T) {
+	logger := setupTestLogger()
+	passwordService := services.NewPasswordService(logger)
+	client := startGRPC(t, grpcapi.Options{
+		Logger:          logger,
+		PasswordService: passwordService,
+		BreachService:   services.NewBreachService(logger, services.WithEnabled(false)),
+		PolicyService: services.NewPolicyService(logger,
+			services.WithPolicies([]models.PasswordPolicy{{ID: "staff", MinLength: 12, RequireSpecial: true}}),
+		),
+	})
+	ctx := context.Background()
+
+	// Checks score passwords as the REST API does
+	response, err := client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{Password: "C0rrect-Horse-Battery!"})
+	require.NoError(t, err)
+	expected := passwordService.ScorePasswordContext(ctx, "C0rrect-Horse-Battery!")
+	assert.Equal(t, string(expected.Strength), response.Strength)
+	assert.Equal(t, int32(expected.Score), response.Score)
+	assert.True(t, response.Requirements.SpecialChars)
+	assert.NotEmpty(t, response.Estimate.CrackTimes)
+	require.NotNil(t, response.BreachData)
+	assert.False(t, response.BreachData.Found)
+	assert.Nil(t, response.Policy)
+	assert.Empty(t, response.Explain)
+
+	// Named policies and explanations are supported
+	response, err = client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{
+		Password: "C0rrect-Horse-Battery!", PolicyId: "staff", Explain: true, KeyboardLayout: "azerty",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, response.Policy)
+	assert.Equal(t, "staff", response.Policy.PolicyId)
+	assert.True(t, response.Policy.Valid)
+	require.NotEmpty(t, response.Explain)
+	assert.Equal(t, "policy", response.Explain[0].Step)
+	assert.Equal(t, "staff", response.Explain[0].Input.AsMap()["policy_id"])
+
+	// Invalid requests are rejected with the invalid fields
+	_, err = client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{Password: "short"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	assert.Equal(t, "password", badRequest.FieldViolations[0].Field)
+
+	_, err = client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{Password: "C0rrect-Horse-Battery!", KeyboardLayout: "??"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{Password: "C0rrect-Horse-Battery!", PolicyId: "missing"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), `unknown policy "missing"`)
+
+	// Passwords failing validation are rejected with why they failed
+	_, err = client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{Password: "Summer2024"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "special character")
+
+	_, err = client.PasswordCheck(ctx, &passwordv1.PasswordCheckRequest{Password: "Summer-2024", PolicyId: "staff", Explain: true})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	failure := validationFailure(t, err)
+	require.NotNil(t, failure.Policy)
+	assert.False(t, failure.Policy.Valid)
+	require.Len(t, failure.Explain, 1)
+	assert.Equal(t, "policy", failure.Explain[0].Step)
+	assert.NotContains(t, err.Error(), "Summer")
+}
+
+func TestGRPC_BreachCheckAndGenerate(t *testing.T) {
+	logger := setupTestLogger()
+	client := startGRPC(t, grpcapi.Options{
+		Logger:           logger,
+		PasswordService:  services.NewPasswordService(logger),
+		BreachService:    services.NewBreachService(logger, services.WithEnabled(false)),
+		GeneratorService: services.NewGeneratorService(services.NewPasswordStrengthChecker()),
+	})
+	ctx := context.Background()
+
+	breach, err := client.BreachCheck(ctx, &passwordv1.BreachCheckRequest{Password: "C0rrect-Horse-Battery!"})
+	require.NoError(t, err)
+	assert.False(t, breach.Found)
+	_, err = client.BreachCheck(ctx, &passwordv1.BreachCheckRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	generated, err := client.Generate(ctx, &passwordv1.GenerateRequest{Length: 20, Classes: []string{models.CharacterClassDigits}})
+	require.NoError(t, err)
+	assert.Len(t, generated.Password, 20)
+	assert.Regexp(t, `^[0-9]+$`, generated.Password)
+	assert.NotEmpty(t, generated.Strength)
+
+	_, err = client.Generate(ctx, &passwordv1.GenerateRequest{Length: 4})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Generate(ctx, &passwordv1.GenerateRequest{Classes: []string{"emoji"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_Authentication(t *testing.T) {
+	logger := setupTestLogger()
+	apiKeyService := services.NewAPIKeyService(logger)
+	checkKey, err := apiKeyService.Issue("backend", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+	client := startGRPC(t, grpcapi.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		APIKeyService:   apiKeyService,
+		APIKeysEnabled:  true,
+	})
+	request := &passwordv1.PasswordCheckRequest{Password: "C0rrect-Horse-Battery!"}
+	withMetadata := func(pairs ...string) context.Context {
+		return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(pairs...))
+	}
+
+	_, err = client.PasswordCheck(context.Background(), request)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.PasswordCheck(withMetadata("x-api-key", "csk_unknown"), request)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Keys are accepted as in REST requests, and calls are given a request ID
+	var header metadata.MD
+	_, err = client.PasswordCheck(withMetadata("x-api-key", checkKey.Key), request, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Len(t, header.Get("x-request-id"), 1)
+
+	header = nil
+	_, err = client.PasswordCheck(withMetadata("authorization", "Bearer "+checkKey.Key, "x-request-id", "grpc-caller-1"), request, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"grpc-caller-1"}, header.Get("x-request-id"))
+}
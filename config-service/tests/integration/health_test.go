@@ -0,0 +1,205 @@
+package integration_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/dataset"
+	"config-service/internal/handlers"
+	"config-service/internal/health"
+	"config-service/internal/services"
+)
+
+func getDeepHealth(t *testing.T, r *gin.Engine) (int, health.Report) {
+	req, _ := http.NewRequest("GET", "/api/v1/health/deep", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestDeepHealth_ReportsDependencyBreakdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+
+	var probes int32
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		w.Write([]byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n"))
+	}))
+	defer hibp.Close()
+
+	breachService := services.NewBreachService(logger, services.WithAPIEndpoint(hibp.URL+"/"))
+	bannedList := services.NewBannedListService(logger)
+	bannedList.Add("acme")
+
+	checker := health.NewChecker("1.0.0")
+	checker.Register("hibp", false, time.Minute, health.BreachAPICheck(breachService))
+	checker.Register("cache", false, 0, health.CacheCheck(breachService))
+	checker.Register("policies", true, 0, health.PolicyCheck(services.NewPolicyService(logger)))
+	checker.Register("datasets", false, 0, health.DatasetCheck(bannedList))
+
+	r := gin.New()
+	r.GET("/api/v1/health/deep", handlers.DeepHealthCheckHandler(checker))
+
+	code, report := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusHealthy, report.Status)
+	assert.Equal(t, "1.0.0", report.Version)
+	require.Len(t, report.Checks, 4)
+	assert.Equal(t, health.StatusHealthy, report.Checks["hibp"].Status)
+	assert.Equal(t, "memory", report.Checks["cache"].Details["backend"])
+	assert.True(t, report.Checks["policies"].Critical)
+	assert.EqualValues(t, 1, report.Checks["datasets"].Details["banned_words"])
+
+	// The HIBP probe result is cached
+	_, report = getDeepHealth(t, r)
+	assert.NotNil(t, report.Checks["hibp"].CachedAt)
+	assert.Nil(t, report.Checks["cache"].CachedAt)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&probes))
+}
+
+func TestDeepHealth_NonCriticalFailureIsDegraded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer hibp.Close()
+
+	checker := health.NewChecker("1.0.0")
+	checker.Register("hibp", false, 0, health.BreachAPICheck(
+		services.NewBreachService(logger, services.WithAPIEndpoint(hibp.URL+"/")),
+	))
+
+	r := gin.New()
+	r.GET("/api/v1/health/deep", handlers.DeepHealthCheckHandler(checker))
+
+	code, report := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.StatusDegraded, report.Checks["hibp"].Status)
+	assert.NotEmpty(t, report.Checks["hibp"].Message)
+}
+
+func TestDeepHealth_CriticalFailureIsUnhealthy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+
+	checker := health.NewChecker("1.0.0")
+	checker.Register("hibp", false, 0, health.BreachAPICheck(
+		services.NewBreachService(logger, services.WithEnabled(false)),
+	))
+	checker.Register("storage", true, 0, func(ctx context.Context) (health.Status, string, map[string]interface{}) {
+		return health.StatusUnhealthy, "storage unavailable", nil
+	})
+
+	r := gin.New()
+	r.GET("/api/v1/health/deep", handlers.DeepHealthCheckHandler(checker))
+
+	code, report := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusUnhealthy, report.Status)
+	assert.Equal(t, health.StatusDisabled, report.Checks["hibp"].Status)
+	assert.Equal(t, "storage unavailable", report.Checks["storage"].Message)
+}
+
+func TestDeepHealth_ReportsRefusedDatasets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	path := filepath.Join(t.TempDir(), "banned.txt")
+	require.NoError(t, os.WriteFile(path, []byte("acme\n"), 0o600))
+	require.NoError(t, os.WriteFile(path+".sha256", []byte(strings.Repeat("0", 64)+"  banned.txt\n"), 0o600))
+
+	verifier := dataset.NewVerifier(dataset.ChecksumsRequired, nil)
+	checker := health.NewChecker("1.0.0")
+	checker.Register("dataset_integrity", false, 0, health.IntegrityCheck(verifier))
+
+	r := gin.New()
+	r.GET("/api/v1/health/deep", handlers.DeepHealthCheckHandler(checker))
+
+	require.ErrorIs(t, verifier.VerifyData(path, []byte("acme\n")), dataset.ErrIntegrity)
+	code, report := getDeepHealth(t, r)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, "1 of 1 files failed verification", report.Checks["dataset_integrity"].Message)
+
+	// A later file that verifies restores the service
+	sum := sha256.Sum256([]byte("acme\n"))
+	require.NoError(t, os.WriteFile(path+".sha256", []byte(hex.EncodeToString(sum[:])+"\n"), 0o600))
+	require.NoError(t, verifier.VerifyData(path, []byte("acme\n")))
+	_, report = getDeepHealth(t, r)
+	assert.Equal(t, health.StatusHealthy, report.Status)
+}
+
+func TestDeepHealth_SlowChecksTimeOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	release := make(chan struct{})
+	defer close(release)
+	hang := func(ctx context.Context) (health.Status, string, map[string]interface{}) {
+		<-release // ignores its context, like a client without deadlines
+		return health.StatusHealthy, "", nil
+	}
+	checker := health.NewChecker("1.0.0", health.WithDefaultTimeout(50*time.Millisecond))
+	checker.Register("hibp", false, 0, hang, health.WithTimeout(20*time.Millisecond))
+	checker.Register("storage", true, 0, hang)
+
+	r := gin.New()
+	r.GET("/api/v1/health/deep", handlers.DeepHealthCheckHandler(checker))
+
+	start := time.Now()
+	code, report := getDeepHealth(t, r)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusDegraded, report.Checks["hibp"].Status)
+	assert.Equal(t, "check timed out after 20ms", report.Checks["hibp"].Message)
+	assert.Equal(t, health.StatusUnhealthy, report.Checks["storage"].Status)
+	assert.Equal(t, "check timed out after 50ms", report.Checks["storage"].Message)
+}
+
+func TestDeepHealth_ServesCachedResultWhileRefreshing(t *testing.T) {
+	var probes int32
+	release := make(chan struct{})
+	checker := health.NewChecker("1.0.0")
+	checker.Register("hibp", false, 20*time.Millisecond, func(ctx context.Context) (health.Status, string, map[string]interface{}) {
+		if atomic.AddInt32(&probes, 1) > 1 {
+			<-release
+		}
+		return health.StatusHealthy, "", nil
+	})
+
+	report := checker.Run(context.Background())
+	require.Nil(t, report.Checks["hibp"].CachedAt)
+	time.Sleep(30 * time.Millisecond)
+
+	// The expired result is refreshed by one run while the others reuse it
+	refreshed := make(chan health.Report)
+	go func() { refreshed <- checker.Run(context.Background()) }()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&probes) == 2 }, time.Second, time.Millisecond)
+	report = checker.Run(context.Background())
+	assert.Equal(t, health.StatusHealthy, report.Checks["hibp"].Status)
+	assert.NotNil(t, report.Checks["hibp"].CachedAt)
+
+	close(release)
+	report = <-refreshed
+	assert.Nil(t, report.Checks["hibp"].CachedAt)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&probes))
+}
@@ -0,0 +1,92 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestIdempotencyKey_AdminMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	webhooks := services.NewWebhookService(logger)
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger),
+		BannedListService: services.NewBannedListService(logger),
+		WebhookService:    webhooks,
+		AdminToken:        secrets.NewValue(testAdminToken),
+	})
+	send := func(method, path, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	countWebhooks := func() int {
+		subscriptions, _, err := webhooks.ListPage(models.ListOptions{Limit: 100})
+		require.NoError(t, err)
+		return len(subscriptions)
+	}
+
+	// A retried creation returns the original webhook instead of registering another
+	body := `{"url":"https://hooks.example.com/breaches","events":["breach-found"]}`
+	first := send("POST", "/api/v1/admin/webhooks", body, "create-1")
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	retry := send("POST", "/api/v1/admin/webhooks", body, "create-1")
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, 1, countWebhooks())
+
+	// The key cannot be reused for a different request
+	w := send("POST", "/api/v1/admin/webhooks", `{"url":"https://hooks.example.com/other","events":["breach-found"]}`, "create-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, countWebhooks())
+
+	// Without a key every request is applied
+	send("POST", "/api/v1/admin/webhooks", body, "")
+	send("POST", "/api/v1/admin/webhooks", body, "")
+	assert.Equal(t, 3, countWebhooks())
+
+	// A retried deletion is answered as the first one was, not with 404
+	var created models.WebhookSubscription
+	require.NoError(t, json.Unmarshal(first.Body.Bytes(), &created))
+	first = send("DELETE", "/api/v1/admin/webhooks/"+created.ID, "", "delete-1")
+	retry = send("DELETE", "/api/v1/admin/webhooks/"+created.ID, "", "delete-1")
+	assert.Equal(t, first.Code, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/v1/admin/webhooks/"+created.ID, "", "").Code)
+
+	// Banned word mutations accept keys too
+	w = send("POST", "/api/v1/admin/banned-words", `{"words":["acme"]}`, "words-1")
+	require.Less(t, w.Code, 300)
+	retry = send("POST", "/api/v1/admin/banned-words", `{"words":["acme"]}`, "words-1")
+	assert.Equal(t, w.Code, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+
+	// Overlong keys are rejected
+	w = send("POST", "/api/v1/admin/webhooks", body, strings.Repeat("k", 256))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,157 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func setupIdPTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	evaluator := services.NewPolicyEvaluator(logger, services.NewPasswordService(logger), nil)
+
+	r := gin.New()
+	r.POST("/api/v1/password/idp/keycloak", handlers.KeycloakPolicyHandler(evaluator))
+	r.POST("/api/v1/password/idp/auth0", handlers.Auth0PolicyHandler(evaluator))
+	r.POST("/api/v1/password/idp/okta", handlers.OktaRegistrationHookHandler(evaluator))
+	return r
+}
+
+func postIdP(t *testing.T, r *gin.Engine, path string, body interface{}, result interface{}) int {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if result != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), result))
+	}
+	return w.Code
+}
+
+func TestIdPWebhook_Keycloak(t *testing.T) {
+	r := setupIdPTestRouter()
+
+	var response models.KeycloakValidationResponse
+	code := postIdP(t, r, "/api/v1/password/idp/keycloak",
+		map[string]string{"realm": "corp", "username": "jdoe", "password": "jdoe"}, &response)
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, response.Valid)
+
+	messages := make(map[string][]interface{})
+	for _, policyError := range response.Errors {
+		messages[policyError.Message] = policyError.Parameters
+	}
+	assert.Equal(t, []interface{}{float64(8)}, messages["invalidPasswordMinLengthMessage"])
+	assert.Equal(t, []interface{}{float64(1)}, messages["invalidPasswordMinDigitsMessage"])
+	assert.Contains(t, messages, "invalidPasswordNotUsernameMessage")
+
+	response = models.KeycloakValidationResponse{}
+	code = postIdP(t, r, "/api/v1/password/idp/keycloak",
+		map[string]string{"username": "jdoe", "password": "Tr0ub4dor&3-Horse!Staple"}, &response)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, response.Valid)
+	assert.Empty(t, response.Errors)
+}
+
+func TestIdPWebhook_RequireGenerated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+	evaluator := services.NewPolicyEvaluator(logger, services.NewPasswordService(logger), nil,
+		services.WithPolicyRequireGenerated(true))
+	r := gin.New()
+	r.POST("/api/v1/password/idp/auth0", handlers.Auth0PolicyHandler(evaluator))
+
+	var response models.Auth0ValidationResponse
+	code := postIdP(t, r, "/api/v1/password/idp/auth0",
+		map[string]string{"username": "svc-backup", "password": "Tiger-Lily-Garden-7"}, &response)
+	assert.Equal(t, http.StatusBadRequest, code)
+	codes := make(map[string]bool)
+	for _, rule := range response.Description.Rules {
+		codes[rule.Code] = rule.Verified
+	}
+	assert.Contains(t, codes, "generated")
+	assert.False(t, codes["generated"])
+
+	code = postIdP(t, r, "/api/v1/password/idp/auth0",
+		map[string]string{"username": "svc-backup", "password": "BXfD5(_c-c(3Kh4y"}, nil)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestIdPWebhook_Auth0(t *testing.T) {
+	r := setupIdPTestRouter()
+
+	var response models.Auth0ValidationResponse
+	code := postIdP(t, r, "/api/v1/password/idp/auth0",
+		map[string]string{"email": "jane@example.com", "password": "password"}, &response)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "PasswordStrengthError", response.Name)
+	assert.Equal(t, "invalid_password", response.Code)
+	assert.False(t, response.Description.Verified)
+
+	rules := make(map[string]models.Auth0PasswordRule)
+	for _, rule := range response.Description.Rules {
+		rules[rule.Code] = rule
+	}
+	assert.True(t, rules["lengthAtLeast"].Verified)
+	assert.Equal(t, []interface{}{float64(8)}, rules["lengthAtLeast"].Format)
+	require.Len(t, rules["shouldContain"].Items, 4)
+	assert.False(t, rules["shouldContain"].Verified)
+	assert.True(t, rules["shouldContain"].Items[0].Verified, "lower case letters")
+	assert.False(t, rules["shouldContain"].Items[1].Verified, "upper case letters")
+
+	response = models.Auth0ValidationResponse{}
+	code = postIdP(t, r, "/api/v1/password/idp/auth0",
+		map[string]string{"email": "jane@example.com", "password": "Tr0ub4dor&3-Horse!Staple"}, &response)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, response.Verified)
+	assert.Empty(t, response.Name)
+}
+
+func TestIdPWebhook_Okta(t *testing.T) {
+	r := setupIdPTestRouter()
+
+	hook := func(password string) map[string]interface{} {
+		return map[string]interface{}{
+			"eventType": "com.okta.user.pre-registration",
+			"data": map[string]interface{}{
+				"userProfile": map[string]string{"login": "jane@example.com", "email": "jane@example.com", "password": password},
+			},
+		}
+	}
+
+	var response models.OktaInlineHookResponse
+	code := postIdP(t, r, "/api/v1/password/idp/okta", hook("Jane@2024"), &response)
+	assert.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Commands, 1)
+	assert.Equal(t, "DENY", response.Commands[0].Value["registration"])
+	require.NotNil(t, response.Error)
+	require.NotEmpty(t, response.Error.ErrorCauses)
+	assert.Equal(t, "data.userProfile.password", response.Error.ErrorCauses[0].Location)
+	assert.Equal(t, "end-user", response.Error.ErrorCauses[0].Domain)
+
+	response = models.OktaInlineHookResponse{}
+	code = postIdP(t, r, "/api/v1/password/idp/okta", hook("Tr0ub4dor&3-Horse!Staple"), &response)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ALLOW", response.Commands[0].Value["registration"])
+	assert.Nil(t, response.Error)
+
+	// Other events and profiles without a password are rejected
+	assert.Equal(t, http.StatusBadRequest, postIdP(t, r, "/api/v1/password/idp/okta",
+		map[string]interface{}{"eventType": "com.okta.import.transform"}, nil))
+	assert.Equal(t, http.StatusBadRequest, postIdP(t, r, "/api/v1/password/idp/okta", hook(""), nil))
+}
@@ -0,0 +1,43 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+	"config-service/internal/lambda"
+)
+
+func TestLambda_ServesPasswordCheck(t *testing.T) {
+	cfg, err := config.NewBuilder(config.WithBreachDetection(false)).Build()
+	require.NoError(t, err)
+
+	handler, err := app.NewHandler(setupTestLogger(), cfg, func() (*config.Config, error) { return cfg, nil })
+	require.NoError(t, err)
+	defer handler.Close()
+
+	output, err := lambda.Serve(context.Background(), handler, []byte(`{
+		"version": "2.0",
+		"rawPath": "/api/v1/password/check",
+		"headers": {"content-type": "application/json"},
+		"requestContext": {"stage": "$default", "http": {"method": "POST", "sourceIp": "203.0.113.7"}},
+		"body": "{\"password\":\"Password1!\"}"
+	}`))
+	require.NoError(t, err)
+
+	var response struct {
+		StatusCode int               `json:"statusCode"`
+		Headers    map[string]string `json:"headers"`
+		Body       string            `json:"body"`
+	}
+	require.NoError(t, json.Unmarshal(output, &response))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, response.Headers["X-Request-Id"])
+	assert.Contains(t, response.Body, `"strength":"weak"`)
+}
@@ -0,0 +1,48 @@
+package integration_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/handlers"
+)
+
+func TestConcurrencyLimitMiddleware_ShedsLoad(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	r := gin.New()
+	r.Use(handlers.ConcurrencyLimitMiddleware(setupTestLogger(), "test", 1, 2*time.Second))
+	r.GET("/slow", func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	// Occupy the only slot
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/slow", nil)
+		r.ServeHTTP(w, req)
+		done <- w.Code
+	}()
+	<-started
+
+	// Further requests are shed while the slot is taken
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
@@ -0,0 +1,133 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestNamedPolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		PolicyService: services.NewPolicyService(logger,
+			services.WithPolicies([]models.PasswordPolicy{{ID: "staff", MinLength: 12}}),
+		),
+		AdminToken: secrets.NewValue(testAdminToken),
+	})
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, []byte(body)))
+		return w
+	}
+
+	// Policies defined through the API sit beside the built-in and configured ones
+	w := send("PUT", "/api/v1/admin/policies/customers", `{"name": "Customers", "min_length": 10, "banned_words": ["Acme"], "min_entropy_bits": 40}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var policy models.PasswordPolicy
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &policy))
+	assert.Equal(t, models.PolicySourceAPI, policy.Source)
+	assert.Equal(t, []string{"acme"}, policy.BannedWords)
+
+	w = send("GET", "/api/v1/admin/policies", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Policies []models.PasswordPolicy `json:"policies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Policies, 3)
+
+	assert.Equal(t, http.StatusConflict, send("PUT", "/api/v1/admin/policies/staff", `{}`).Code)
+	assert.Equal(t, http.StatusConflict, send("DELETE", "/api/v1/admin/policies/default", "").Code)
+	assert.Equal(t, http.StatusBadRequest, send("PUT", "/api/v1/admin/policies/customers", `{"min_length": 4}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send("PUT", "/api/v1/admin/policies/customers", `{"max_length": 500}`).Code)
+
+	// Without a policy_id the default rules apply, which require a symbol
+	check := func(body string) *httptest.ResponseRecorder {
+		return send("POST", "/api/v1/password/check", body)
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, check(`{"password": "Summer2024"}`).Code)
+
+	w = check(`{"password": "horse staple 2024", "policy_id": "customers"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Policy)
+	assert.True(t, response.Policy.Valid)
+	assert.Equal(t, "customers", response.Policy.PolicyID)
+
+	// Failed checks are returned with the rejection
+	w = check(`{"password": "AcmeSummer2024", "policy_id": "customers"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var rejected struct {
+		Message string              `json:"message"`
+		Policy  models.PolicyResult `json:"policy"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rejected))
+	assert.Equal(t, "Password must not contain a word banned by the policy", rejected.Message)
+	assert.False(t, rejected.Policy.Valid)
+	require.Len(t, rejected.Policy.Failed(), 1)
+	assert.Equal(t, models.RuleBannedWord, rejected.Policy.Failed()[0].Rule)
+
+	w = check(`{"password": "horse staple 2024", "policy_id": "unknown"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown policy \"unknown\"`)
+
+	assert.Equal(t, http.StatusNoContent, send("DELETE", "/api/v1/admin/policies/customers", "").Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v1/admin/policies/customers", "").Code)
+	assert.Equal(t, http.StatusBadRequest, check(`{"password": "horse staple 2024", "policy_id": "customers"}`).Code)
+}
+
+func TestNamedPolicies_LookUpBreachesOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var lookups int32
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+	}))
+	defer hibp.Close()
+
+	logger := setupTestLogger()
+	breaches := services.NewBreachService(logger, services.WithEnabled(true), services.WithAPIEndpoint(hibp.URL), services.WithRetries(0, 0))
+	never := 0
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		BreachService:   breaches,
+		PolicyService: services.NewPolicyService(logger,
+			services.WithPolicies([]models.PasswordPolicy{{ID: "customers", MaxBreachCount: &never}}),
+			services.WithPolicyBreaches(breaches),
+		),
+	})
+
+	// The breach lookup of the policy is reused for the response
+	req := httptest.NewRequest("POST", "/api/v1/password/check",
+		strings.NewReader(`{"password": "Xk9#qL2$vP7!mR4z", "policy_id": "customers"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Policy.Valid)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+	stats := breaches.CacheStats()
+	assert.Equal(t, uint64(1), stats.Hits+stats.Misses)
+}
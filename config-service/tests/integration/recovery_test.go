@@ -0,0 +1,47 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+)
+
+func TestRecoveryMiddleware_CapturesPanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var events []handlers.PanicEvent
+	hook := func(event handlers.PanicEvent) {
+		events = append(events, event)
+	}
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.Use(handlers.RecoveryMiddleware(setupTestLogger(), hook))
+	r.GET("/boom/:id", func(c *gin.Context) {
+		panic("something went wrong")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/boom/7", nil)
+	req.Header.Set("X-Request-ID", "panic-request")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "panic-request", response["request_id"])
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "panic-request", events[0].RequestID)
+	assert.Equal(t, "/boom/:id", events[0].Route)
+	assert.Equal(t, "something went wrong", events[0].Panic)
+	assert.Contains(t, events[0].Stack, "goroutine")
+}
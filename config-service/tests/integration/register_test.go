@@ -0,0 +1,117 @@
+package integration_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/handlers"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestRegister_EmbeddedUnderPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	app := gin.New()
+	app.GET("/status", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	handlers.Register(app.Group("/passwords"), handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger),
+		BannedListService: services.NewBannedListService(logger),
+		AdminToken:        secrets.NewValue("admin-secret"),
+	})
+
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/passwords/api/v1/password/check", `{"password":"Password1!"}`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+
+	assert.Equal(t, http.StatusOK, serve("GET", "/passwords/api/v1/health", "", "").Code)
+
+	// Routes whose services were not provided are not mounted
+	assert.Equal(t, http.StatusNotFound, serve("POST", "/passwords/api/v1/password/breach-check", `{"password":"Password1!"}`, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve("GET", "/passwords/api/v1/admin/policies", "", "admin-secret").Code)
+
+	// The admin API is mounted with its authentication
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/passwords/api/v1/admin/banned-words", "", "wrong").Code)
+	assert.Equal(t, http.StatusOK, serve("GET", "/passwords/api/v1/admin/banned-words", "", "admin-secret").Code)
+
+	// The host application's own routes are left alone
+	w = serve("GET", "/status", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Request-ID"))
+}
+
+func TestRegisterAdmin_SeparateListener(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	opts := handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger),
+		BannedListService: services.NewBannedListService(logger),
+		AdmissionService:  services.NewSecretAdmissionService(logger, services.NewPasswordService(logger), nil),
+		AdminToken:        secrets.NewValue("admin-secret"),
+		MetricsPath:       "/metrics",
+		MetricsHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("requests_total 1\n"))
+		}),
+		SeparateAdmin: true,
+		Pprof:         true,
+	}
+	public := gin.New()
+	handlers.Register(&public.RouterGroup, opts)
+	internal := gin.New()
+	handlers.RegisterAdmin(&internal.RouterGroup, opts)
+
+	serve := func(router *gin.Engine, method, path, body, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "https://example.com")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The public listener serves the password API without the internal endpoints
+	assert.Equal(t, http.StatusOK, serve(public, "POST", "/api/v1/password/check", `{"password":"Password1!"}`, "").Code)
+	assert.Equal(t, http.StatusOK, serve(public, "GET", "/api/v1/health", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(public, "GET", "/api/v1/admin/banned-words", "", "admin-secret").Code)
+	assert.Equal(t, http.StatusNotFound, serve(public, "GET", "/metrics", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(public, "GET", "/debug/pprof/goroutine", "", "").Code)
+	review := `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"1","kind":{"kind":"Secret"},"operation":"DELETE"}}`
+	assert.Equal(t, http.StatusNotFound, serve(public, "POST", "/api/v1/admission/secrets", review, "").Code)
+
+	// The internal listener serves them, and only them
+	assert.Equal(t, http.StatusUnauthorized, serve(internal, "GET", "/api/v1/admin/banned-words", "", "wrong").Code)
+	w := serve(internal, "GET", "/api/v1/admin/banned-words", "", "admin-secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "requests_total 1\n", serve(internal, "GET", "/metrics", "", "").Body.String())
+	assert.Equal(t, http.StatusOK, serve(internal, "GET", "/debug/pprof/goroutine", "", "").Code)
+	assert.Equal(t, http.StatusOK, serve(internal, "GET", "/debug/pprof/", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(internal, "GET", "/debug/pprof/unknown", "", "").Code)
+	assert.Equal(t, http.StatusOK, serve(internal, "GET", "/api/v1/health", "", "").Code)
+	assert.Equal(t, http.StatusOK, serve(internal, "POST", "/api/v1/admission/secrets", review, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(internal, "POST", "/api/v1/password/check", `{"password":"Password1!"}`, "").Code)
+}
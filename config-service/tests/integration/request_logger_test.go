@@ -0,0 +1,79 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/logging"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestRequestLogger_CarriesRequestScopedFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	logger := logging.New(&output)
+	apiKeyService := services.NewAPIKeyService(setupTestLogger())
+	key, err := apiKeyService.Issue("ci", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.POST("/api/v1/password/check",
+		handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck),
+		func(c *gin.Context) {
+			handlers.RequestLogger(c, logger).Info("from handler")
+			logging.FromContext(c.Request.Context(), logger).Info("from service")
+			c.Status(http.StatusNoContent)
+		})
+
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", nil)
+	req.Header.Set("X-Request-ID", "req-7")
+	req.Header.Set("X-API-Key", key.Key)
+	req.Header.Set("traceparent", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, "req-7", entry["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+		assert.Equal(t, "acme", entry["tenant"])
+		assert.Equal(t, "/api/v1/password/check", entry["route"])
+	}
+}
+
+func TestRequestLogger_IgnoresMalformedTraceparent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	logger := logging.New(&output)
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.GET("/ok", func(c *gin.Context) {
+		assert.Empty(t, handlers.GetTraceID(c))
+		handlers.RequestLogger(c, logger).Info("hello")
+	})
+
+	for _, traceparent := range []string{"garbage", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-xyz-00f067aa0ba902b7-01"} {
+		output.Reset()
+		req, _ := http.NewRequest("GET", "/ok", nil)
+		req.Header.Set("traceparent", traceparent)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		assert.NotContains(t, output.String(), "trace_id", traceparent)
+		assert.Contains(t, output.String(), `"request_id"`)
+	}
+}
@@ -0,0 +1,57 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+	"config-service/pkg/reuse"
+)
+
+func TestPasswordReuse_FlagsAccountsSharingAPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		ReuseService:    services.NewReuseService(),
+	})
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	record := func(account, password string) models.ReuseResult {
+		w := serve("POST", "/api/v1/password/reuse", `{"account":"`+account+`","digest":"`+reuse.Digest([]byte("tenant key"), password)+`"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result models.ReuseResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	assert.False(t, record("alice", "Summer2024!Summer").Reused)
+	result := record("bob", "Summer2024!Summer")
+	assert.True(t, result.Reused)
+	assert.Equal(t, 2, result.Accounts)
+
+	assert.Equal(t, http.StatusNoContent, serve("DELETE", "/api/v1/password/reuse/alice", "").Code)
+	assert.Equal(t, http.StatusNotFound, serve("DELETE", "/api/v1/password/reuse/alice", "").Code)
+	assert.False(t, record("bob", "Summer2024!Summer").Reused)
+
+	// Plaintext passwords are not accepted
+	w := serve("POST", "/api/v1/password/reuse", `{"account":"carol","digest":"Summer2024!Summer"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,164 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// rulePluginServer serves a fixed verdict and records the requests it got
+func rulePluginServer(t *testing.T, verdict models.RulePluginVerdict, requests chan<- *http.Request) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.RulePluginRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "Tr0ub4dor&3-Horse!Staple", request.Password)
+		assert.NotEmpty(t, request.Strength)
+		if requests != nil {
+			requests <- r
+		}
+		json.NewEncoder(w).Encode(verdict)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func checkWithPlugins(t *testing.T, plugins ...services.RulePlugin) models.PasswordResponse {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+	passwordService := services.NewPasswordService(logger, services.WithRulePlugins(plugins...))
+
+	r := gin.New()
+	r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, nil))
+
+	body, _ := json.Marshal(map[string]string{"password": "Tr0ub4dor&3-Horse!Staple"})
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestRulePlugins_AdjustScoreAndFeedback(t *testing.T) {
+	baseline := checkWithPlugins(t)
+	require.Equal(t, models.StrengthVeryStrong, baseline.Strength)
+
+	requests := make(chan *http.Request, 1)
+	penalty := rulePluginServer(t, models.RulePluginVerdict{
+		ScoreAdjustment: -30,
+		Warnings:        []string{"Password contains a product name"},
+		Suggestions:     []string{"Avoid product names"},
+	}, requests)
+
+	response := checkWithPlugins(t, services.NewHTTPRulePlugin("products", penalty.URL, services.WithPluginToken("plugin-token")))
+	assert.Equal(t, baseline.Score-30, response.Score)
+	assert.Equal(t, models.GetStrengthCategory(baseline.Score-30), response.Strength)
+	assert.Contains(t, response.Feedback.Warnings, "Password contains a product name")
+	assert.Contains(t, response.Feedback.Suggestions, "Avoid product names")
+
+	request := <-requests
+	assert.Equal(t, "Bearer plugin-token", request.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+}
+
+func TestRulePlugins_CapStrength(t *testing.T) {
+	capping := rulePluginServer(t, models.RulePluginVerdict{
+		MaxStrength: models.StrengthWeak,
+		Warnings:    []string{"Password matches a former password"},
+	}, nil)
+	bonus := rulePluginServer(t, models.RulePluginVerdict{ScoreAdjustment: 50}, nil)
+
+	// A cap holds regardless of other plugins' adjustments
+	response := checkWithPlugins(t,
+		services.NewHTTPRulePlugin("history", capping.URL),
+		services.NewHTTPRulePlugin("bonus", bonus.URL))
+	assert.Equal(t, models.StrengthWeak, response.Strength)
+	assert.Equal(t, 100, response.Score)
+	assert.Contains(t, response.Feedback.Warnings, "Password matches a former password")
+}
+
+func TestRulePlugins_Failures(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+
+	baseline := checkWithPlugins(t)
+
+	// Failing plugins are skipped by default
+	start := time.Now()
+	response := checkWithPlugins(t,
+		services.NewHTTPRulePlugin("slow", slow.URL, services.WithPluginTimeout(50*time.Millisecond)),
+		services.NewHTTPRulePlugin("broken", broken.URL))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, baseline.Score, response.Score)
+	assert.Equal(t, baseline.Strength, response.Strength)
+
+	// ... and cap the password at weak when they fail closed
+	response = checkWithPlugins(t,
+		services.NewHTTPRulePlugin("broken", broken.URL, services.WithPluginFailClosed(true)))
+	assert.Equal(t, models.StrengthWeak, response.Strength)
+	assert.Contains(t, response.Feedback.Warnings, "Password could not be checked against all rules")
+}
+
+func TestPasswordCheck_ScoresWhileCheckingBreaches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := setupTestLogger()
+
+	const delay = 200 * time.Millisecond
+	slowPlugin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		json.NewEncoder(w).Encode(models.RulePluginVerdict{})
+	}))
+	defer slowPlugin.Close()
+	slowHIBP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte("0000000000000000000000000000000000A:1\r\n"))
+	}))
+	defer slowHIBP.Close()
+
+	passwordService := services.NewPasswordService(logger, services.WithRulePlugins(services.NewHTTPRulePlugin("slow", slowPlugin.URL)))
+	breachService := services.NewBreachService(logger, services.WithAPIEndpoint(slowHIBP.URL))
+	r := gin.New()
+	r.POST("/api/v1/password/check", handlers.PasswordCheckHandler(passwordService, breachService))
+
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"Tr0ub4dor&3-Horse!Staple"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	start := time.Now()
+	r.ServeHTTP(w, req)
+	elapsed := time.Since(start)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.PasswordResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.BreachData)
+	assert.False(t, response.BreachData.Found)
+
+	// Both stages are timed, but the request takes about as long as one
+	assert.Less(t, elapsed, 2*delay)
+	assert.Contains(t, w.Header().Get("Server-Timing"), "strength;dur=")
+	assert.Contains(t, w.Header().Get("Server-Timing"), "breach;dur=")
+}
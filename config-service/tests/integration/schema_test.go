@@ -0,0 +1,130 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/handlers"
+	"config-service/internal/logging"
+	"config-service/internal/services"
+)
+
+// schemaMismatches returns the problems of the schema mismatches logged to output
+func schemaMismatches(t *testing.T, output *bytes.Buffer) [][]interface{} {
+	var mismatches [][]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var entry map[string]interface{}
+		if json.Unmarshal([]byte(line), &entry) != nil || entry["msg"] != "Response does not match its schema" {
+			continue
+		}
+		problems, ok := entry["problems"].([]interface{})
+		require.True(t, ok)
+		mismatches = append(mismatches, problems)
+	}
+	return mismatches
+}
+
+func TestStrictSchema_RejectsUnknownFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	logger := logging.New(&output)
+	app := gin.New()
+	handlers.Register(app.Group("/passwords"), handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger),
+		StrictSchema:      true,
+		ValidateResponses: true,
+	})
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+		field  string
+		code   apperrors.ErrorCode
+	}{
+		{"known fields", `{"password": "Tr0ub4dor&3", "keyboard_layout": "qwerty"}`, http.StatusOK, "", ""},
+		{"unknown field", `{"password": "Tr0ub4dor&3", "pasword": "x"}`, http.StatusBadRequest, "pasword", apperrors.ErrorCodeInvalidInput},
+		{"trailing data", `{"password": "Tr0ub4dor&3"} {}`, http.StatusBadRequest, "", apperrors.ErrorCodeInvalidFormat},
+		{"binding tags", `{"password": "short"}`, http.StatusBadRequest, "password", apperrors.ErrorCodePasswordTooShort},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/passwords/api/v1/password/check", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			app.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status != http.StatusBadRequest {
+				return
+			}
+			var response struct {
+				Errors []apperrors.ValidationError `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response.Errors, 1)
+			assert.Equal(t, tc.field, response.Errors[0].Field)
+			assert.Equal(t, tc.code, response.Errors[0].Code)
+		})
+	}
+
+	// The service's own responses match their models
+	assert.Empty(t, schemaMismatches(t, &output))
+
+	// Without strict mode, unknown fields are ignored
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password": "Tr0ub4dor&3", "pasword": "x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	setupTestRouter().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestResponseSchemaMiddleware_LogsDrift(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	r := gin.New()
+	r.Use(handlers.ResponseSchemaMiddleware(logging.New(&output), "/"))
+	r.GET("/api/v1/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"version":    "1.0.0",
+			"git_commit": 42,
+			"build_time": "unknown",
+			"go_version": "go1.21",
+			"features":   nil,
+			"uptime":     "1h",
+		})
+	})
+	r.GET("/broken", func(c *gin.Context) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	})
+	r.GET("/ad-hoc", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"anything": true})
+	})
+
+	for _, path := range []string{"/api/v1/version", "/broken", "/ad-hoc"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+
+		// Responses are sent unchanged
+		assert.NotEmpty(t, w.Body.String())
+	}
+
+	mismatches := schemaMismatches(t, &output)
+	require.Len(t, mismatches, 2)
+	assert.Equal(t, []interface{}{"git_commit: must be a string", "uptime: unknown field"}, mismatches[0])
+	assert.Equal(t, []interface{}{"message: missing"}, mismatches[1])
+}
@@ -0,0 +1,40 @@
+package integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+)
+
+func TestSelfTest_GatesStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expectations.json")
+	newHandler := func(expectations string, failOnDrift bool) error {
+		require.NoError(t, os.WriteFile(path, []byte(expectations), 0o600))
+		cfg, err := config.NewBuilder(config.WithBreachDetection(false)).Build()
+		require.NoError(t, err)
+		cfg.Password.SelfTest = true
+		cfg.Password.SelfTestExpectations = path
+		cfg.Password.SelfTestFailOnDrift = failOnDrift
+
+		handler, err := app.NewHandler(setupTestLogger(), cfg, func() (*config.Config, error) { return cfg, nil })
+		if err == nil {
+			handler.Close()
+		}
+		return err
+	}
+
+	assert.NoError(t, newHandler(`{"password": "weak", "Tr0ub4dor&3": "strong"}`, true))
+
+	err := newHandler(`{"password": "strong", "Tr0ub4dor&3": "strong"}`, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 reference passwords drifted")
+
+	// Drift can be logged instead of refusing to serve
+	assert.NoError(t, newHandler(`{"password": "strong"}`, false))
+}
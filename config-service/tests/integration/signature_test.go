@@ -0,0 +1,132 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/auth"
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+const testHMACSecret = "partner-shared-secret"
+
+func setupSignatureTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:3303003\r\n")
+	}))
+	t.Cleanup(hibp.Close)
+
+	logger := setupTestLogger()
+	apiKeyService := services.NewAPIKeyService(logger)
+	breachService := services.NewBreachService(logger, services.WithAPIEndpoint(hibp.URL))
+	verifier := auth.NewHMACVerifier([]auth.HMACKey{{ID: "partner", Tenant: "acme", Secret: []byte(testHMACSecret)}})
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+
+	password := r.Group("/api/v1/password",
+		handlers.SignatureAuthMiddleware(verifier, 1<<20, "/api/v1/password/breach-audit"),
+		handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck))
+	password.POST("/breach-audit", handlers.BreachAuditHandler(breachService))
+	password.POST("/breach-check", handlers.BreachCheckHandler(breachService))
+
+	return r
+}
+
+func signedRequest(path, body string, timestamp time.Time, secret string) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.SignatureKeyIDHeader, "partner")
+	req.Header.Set(auth.SignatureTimestampHeader, ts)
+	req.Header.Set(auth.SignatureHeader, auth.Sign([]byte(secret), "POST", path, ts, []byte(body)))
+	return req
+}
+
+func TestSignedRequests(t *testing.T) {
+	router := setupSignatureTestRouter(t)
+	body := `{"hashes":["5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"]}`
+
+	// A valid signature authenticates without an API key
+	req := signedRequest("/api/v1/password/breach-audit", body, time.Now(), testHMACSecret)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"breached":1`)
+
+	// Replaying the same signed request is rejected
+	replay := signedRequest("/api/v1/password/breach-audit", body, time.Now(), testHMACSecret)
+	replay.Header.Set(auth.SignatureTimestampHeader, req.Header.Get(auth.SignatureTimestampHeader))
+	replay.Header.Set(auth.SignatureHeader, req.Header.Get(auth.SignatureHeader))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, replay)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "already been used")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{name: "wrong secret", req: signedRequest("/api/v1/password/breach-audit", body, time.Now(), "wrong"), want: http.StatusUnauthorized},
+		{name: "stale timestamp", req: signedRequest("/api/v1/password/breach-audit", body, time.Now().Add(-time.Hour), testHMACSecret), want: http.StatusUnauthorized},
+		{name: "route not enabled for signing", req: signedRequest("/api/v1/password/breach-check", `{"password":"Password1!"}`, time.Now(), testHMACSecret), want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+
+	// Tampering with the body invalidates the signature
+	tampered := signedRequest("/api/v1/password/breach-audit", body, time.Now().Add(time.Second), testHMACSecret)
+	tampered.Body = httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"hashes":["0000000000000000000000000000000000000000"]}`)).Body
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tampered)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Unsigned requests still need an API key
+	unsigned, _ := http.NewRequest("POST", "/api/v1/password/breach-audit", bytes.NewBufferString(body))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, unsigned)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSignedRequests_Nonces(t *testing.T) {
+	router := setupSignatureTestRouter(t)
+	body := `{"hashes":["5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"]}`
+	path := "/api/v1/password/breach-audit"
+	withNonce := func(nonce string) *http.Request {
+		req := signedRequest(path, body, time.Now(), testHMACSecret)
+		ts := req.Header.Get(auth.SignatureTimestampHeader)
+		req.Header.Set(auth.SignatureNonceHeader, nonce)
+		req.Header.Set(auth.SignatureHeader, auth.SignWithNonce([]byte(testHMACSecret), "POST", path, ts, nonce, []byte(body)))
+		return req
+	}
+
+	// Repeating a request with a new nonce is not a replay
+	for _, nonce := range []string{"7f3c1a", "9b2e4d"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, withNonce(nonce))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, withNonce("7f3c1a"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "already been used")
+}
@@ -0,0 +1,66 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/logging"
+	"config-service/internal/metrics"
+)
+
+func TestSlowRequestLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	logger := logging.New(&output)
+	prometheus := metrics.NewPrometheus("test")
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.Use(handlers.SlowRequestMiddleware(logger, prometheus, 20*time.Millisecond))
+	r.GET("/fast", func(c *gin.Context) {
+		handlers.TrackStage(c, "strength")()
+		c.Status(http.StatusOK)
+	})
+	r.GET("/slow", func(c *gin.Context) {
+		handlers.TrackStage(c, "strength")()
+		done := handlers.TrackStage(c, "breach")
+		time.Sleep(25 * time.Millisecond)
+		done()
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/fast", "/slow"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 1)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "Slow request", entry["msg"])
+	assert.Equal(t, "/slow", entry["route"])
+	assert.Equal(t, "breach", entry["slowest_stage"])
+	assert.NotEmpty(t, entry["request_id"])
+
+	stages, ok := entry["stages_ms"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, stages, "strength")
+	assert.GreaterOrEqual(t, stages["breach"].(float64), 25.0)
+
+	var metricsOut bytes.Buffer
+	prometheus.WriteTo(&metricsOut)
+	assert.Contains(t, metricsOut.String(), `test_slow_requests_total{route="/slow",stage="breach"} 1`)
+}
@@ -0,0 +1,142 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/audit"
+	"config-service/internal/handlers"
+	"config-service/internal/models"
+	"config-service/internal/secrets"
+	"config-service/internal/services"
+)
+
+func TestAdminAPI_BannedWordsSoftDeleteAndRestore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	sink, err := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	auditLogger := audit.NewLogger(logger, sink)
+	t.Cleanup(func() { auditLogger.Close() })
+
+	bannedList := services.NewBannedListService(logger)
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:            logger,
+		PasswordService:   services.NewPasswordService(logger, services.WithBannedList(bannedList)),
+		BannedListService: bannedList,
+		AuditLogger:       auditLogger,
+		AdminToken:        secrets.NewValue(testAdminToken),
+	})
+	send := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, body))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send("POST", "/api/v1/admin/banned-words", []byte(`{"words":["acme","widget"]}`)).Code)
+	added := bannedList.List()[0].AddedAt
+
+	// A removed word is no longer enforced but is listed with who removed it
+	require.Equal(t, http.StatusNoContent, send("DELETE", "/api/v1/admin/banned-words/acme", nil).Code)
+	_, found := bannedList.FindBannedWord("MyAcme!Pass9")
+	assert.False(t, found)
+
+	w := send("GET", "/api/v1/admin/banned-words/deleted", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var deleted struct {
+		Words []models.DeletedBannedWord `json:"words"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deleted))
+	require.Len(t, deleted.Words, 1)
+	assert.Equal(t, "acme", deleted.Words[0].Word)
+	assert.Equal(t, "admin_token", deleted.Words[0].DeletedBy)
+	assert.True(t, deleted.Words[0].PurgeAt.After(deleted.Words[0].DeletedAt))
+
+	// Restoring brings it back as it was
+	w = send("POST", "/api/v1/admin/banned-words/acme/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored models.BannedWord
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.True(t, added.Equal(restored.AddedAt))
+	_, found = bannedList.FindBannedWord("MyAcme!Pass9")
+	assert.True(t, found)
+	assert.Empty(t, bannedList.Deleted())
+
+	assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/admin/banned-words/acme/restore", nil).Code)
+	assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/admin/banned-words/unknown/restore", nil).Code)
+
+	// The audit trail names the word each operation acted on
+	w = send("GET", "/api/v1/admin/audit/export?format=csv", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "admin_token,,DELETE /api/v1/admin/banned-words/:word,204,success,banned-word:acme")
+	assert.Contains(t, w.Body.String(), "admin_token,,POST /api/v1/admin/banned-words/:word/restore,200,success,banned-word:acme")
+}
+
+func TestAdminAPI_PoliciesSoftDeleteAndRestore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	sink, err := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	auditLogger := audit.NewLogger(logger, sink)
+	t.Cleanup(func() { auditLogger.Close() })
+
+	policies := services.NewPolicyService(logger)
+	router := gin.New()
+	handlers.Register(&router.RouterGroup, handlers.Options{
+		Logger:          logger,
+		PasswordService: services.NewPasswordService(logger),
+		PolicyService:   policies,
+		AuditLogger:     auditLogger,
+		AdminToken:      secrets.NewValue(testAdminToken),
+	})
+	send := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(method, path, body))
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send("PUT", "/api/v1/admin/policies/contractors", []byte(`{"min_length":14}`)).Code)
+
+	// A deleted policy can no longer be selected but is listed with who deleted it
+	require.Equal(t, http.StatusNoContent, send("DELETE", "/api/v1/admin/policies/contractors", nil).Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/api/v1/admin/policies/contractors", nil).Code)
+
+	w := send("GET", "/api/v1/admin/policies/deleted", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var deleted struct {
+		Policies []models.DeletedPasswordPolicy `json:"policies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deleted))
+	require.Len(t, deleted.Policies, 1)
+	assert.Equal(t, "contractors", deleted.Policies[0].ID)
+	assert.Equal(t, "admin_token", deleted.Policies[0].DeletedBy)
+	assert.True(t, deleted.Policies[0].PurgeAt.After(deleted.Policies[0].DeletedAt))
+
+	// Restoring brings it back with its rules
+	w = send("POST", "/api/v1/admin/policies/contractors/restore", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored models.PasswordPolicy
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, 14, restored.MinLength)
+	assert.Equal(t, http.StatusOK, send("GET", "/api/v1/admin/policies/contractors", nil).Code)
+	assert.Empty(t, policies.Deleted())
+
+	assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/admin/policies/contractors/restore", nil).Code)
+	assert.Equal(t, http.StatusNotFound, send("POST", "/api/v1/admin/policies/unknown/restore", nil).Code)
+
+	// The audit trail names the policy each operation acted on
+	w = send("GET", "/api/v1/admin/audit/export?format=csv", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "admin_token,,DELETE /api/v1/admin/policies/:id,204,success,policy:contractors")
+	assert.Contains(t, w.Body.String(), "admin_token,,POST /api/v1/admin/policies/:id/restore,200,success,policy:contractors")
+}
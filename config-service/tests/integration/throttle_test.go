@@ -0,0 +1,69 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/handlers"
+	"config-service/internal/services"
+)
+
+// stubCaptcha accepts a single fixed token
+type stubCaptcha struct{}
+
+func (stubCaptcha) VerifyCaptcha(ctx context.Context, token, clientIP string) (bool, error) {
+	return token == "solved", nil
+}
+
+func TestBruteForceThrottle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	throttle := services.NewThrottleService(
+		services.WithThrottleThreshold(2),
+		services.WithThrottleDelays(5*time.Millisecond, 10*time.Millisecond),
+	)
+	passwordService := services.NewPasswordService(logger)
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	r.POST("/api/v1/password/check",
+		handlers.BruteForceThrottleMiddleware(logger, throttle, stubCaptcha{}),
+		handlers.PasswordCheckHandler(passwordService, nil))
+
+	check := func(captchaToken string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"Password1!"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if captchaToken != "" {
+			req.Header.Set(handlers.CaptchaTokenHeader, captchaToken)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Under the threshold, then delayed, then rejected
+	assert.Equal(t, http.StatusOK, check("").Code)
+	assert.Equal(t, http.StatusOK, check("").Code)
+
+	w := check("")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Server-Timing"), "throttle;dur=")
+
+	assert.Equal(t, http.StatusOK, check("").Code)
+
+	w = check("")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// An invalid CAPTCHA does not help; a solved one lifts the throttle
+	assert.Equal(t, http.StatusTooManyRequests, check("bogus").Code)
+	assert.Equal(t, http.StatusOK, check("solved").Code)
+}
@@ -0,0 +1,184 @@
+package integration_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestUsageQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	apiKeyService := services.NewAPIKeyService(logger)
+	limited, err := apiKeyService.Issue("ci", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+	unlimited, err := apiKeyService.Issue("batch", "globex", []string{models.ScopeCheck})
+	require.NoError(t, err)
+
+	usage := services.NewUsageService(
+		services.WithMonthlyQuota(2),
+		services.WithTenantQuotas(map[string]int64{"globex": 0}),
+	)
+	prometheus := metrics.NewPrometheus("test")
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	password := r.Group("/api/v1/password")
+	password.Use(handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck))
+	password.Use(handlers.UsageQuotaMiddleware(logger, usage, prometheus))
+	password.POST("/check", handlers.PasswordCheckHandler(services.NewPasswordService(logger), nil))
+	r.GET("/api/v1/admin/usage", handlers.AdminUsageHandler(usage))
+
+	check := func(key string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"Password1!"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := check(limited.Key)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(handlers.QuotaLimitHeader))
+	assert.Equal(t, "1", w.Header().Get(handlers.QuotaRemainingHeader))
+	assert.Equal(t, http.StatusOK, check(limited.Key).Code)
+
+	// The quota is used up
+	w = check(limited.Key)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	var body struct {
+		Error     string             `json:"error"`
+		RequestID string             `json:"request_id"`
+		Quota     models.QuotaStatus `json:"quota"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Quota exceeded", body.Error)
+	assert.NotEmpty(t, body.RequestID)
+	assert.Equal(t, "acme", body.Quota.Tenant)
+	assert.EqualValues(t, 2, body.Quota.Limit)
+	assert.EqualValues(t, 2, body.Quota.Used)
+	assert.EqualValues(t, 0, body.Quota.Remaining)
+
+	// Tenants with a zero quota are unlimited
+	for i := 0; i < 3; i++ {
+		w = check(unlimited.Key)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(handlers.QuotaLimitHeader))
+	}
+
+	// The admin endpoint reports counts per tenant and key
+	req, _ := http.NewRequest("GET", "/api/v1/admin/usage", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report models.UsageReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report.Tenants, 2)
+	assert.Equal(t, models.TenantUsage{Tenant: "acme", Requests: 2, Quota: 2, Remaining: 0, Checks: 2, PolicyFailures: 2, AverageScore: 39}, report.Tenants[0])
+	assert.Equal(t, models.TenantUsage{Tenant: "globex", Requests: 3, Checks: 3, PolicyFailures: 3, AverageScore: 39}, report.Tenants[1])
+	assert.Len(t, report.Keys, 2)
+
+	req, _ = http.NewRequest("GET", "/api/v1/admin/usage?period=last-month", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Requests are exposed as metrics labelled by tenant
+	var metricsOut bytes.Buffer
+	prometheus.WriteTo(&metricsOut)
+	assert.Contains(t, metricsOut.String(), `test_tenant_requests_total{`)
+	assert.Contains(t, metricsOut.String(), `tenant="globex"`)
+	assert.Contains(t, metricsOut.String(), `test_quota_rejections_total{`)
+}
+
+func TestUsageExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := setupTestLogger()
+	breached := "Summer2024!"
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hash := strings.ToUpper(fmt.Sprintf("%x", sha1.Sum([]byte(breached))))
+		w.Write([]byte(hash[5:] + ":1200\r\n"))
+	}))
+	defer hibp.Close()
+	breaches := services.NewBreachService(logger, services.WithAPIEndpoint(hibp.URL))
+
+	apiKeyService := services.NewAPIKeyService(logger)
+	key, err := apiKeyService.Issue("ci", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+	usage := services.NewUsageService()
+
+	r := gin.New()
+	password := r.Group("/api/v1/password")
+	password.Use(handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck))
+	password.Use(handlers.UsageQuotaMiddleware(logger, usage, metrics.Noop{}))
+	password.POST("/check", handlers.PasswordCheckHandler(services.NewPasswordService(logger), breaches))
+	r.GET("/api/v1/admin/usage/export", handlers.AdminUsageExportHandler(usage))
+
+	for _, candidate := range []string{breached, "Tr0ub4dor&3-correct-horse", "Password1!", "short"} {
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"`+candidate+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key.Key)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	export := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/admin/usage/export"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Rejected requests count towards requests but not checks
+	w := export("?format=json")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var body struct {
+		Period  string               `json:"period"`
+		Tenants []models.TenantUsage `json:"tenants"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Tenants, 1)
+	acme := body.Tenants[0]
+	assert.Equal(t, time.Now().UTC().Format("2006-01"), body.Period)
+	assert.EqualValues(t, 4, acme.Requests)
+	assert.EqualValues(t, 3, acme.Checks)
+	assert.EqualValues(t, 1, acme.BreachHits)
+	assert.EqualValues(t, 1, acme.PolicyFailures)
+	assert.Greater(t, acme.AverageScore, 0.0)
+
+	w = export("")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "usage-"+body.Period+".csv")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"period", "tenant", "requests", "checks", "breach_hits", "policy_failures", "average_score", "quota"}, records[0])
+	assert.Equal(t, []string{body.Period, "acme", "4", "3", "1", "1", strconv.FormatFloat(acme.AverageScore, 'f', 2, 64), "0"}, records[1])
+
+	assert.Equal(t, http.StatusBadRequest, export("?format=xml").Code)
+	assert.Equal(t, http.StatusBadRequest, export("?period=2026").Code)
+}
@@ -0,0 +1,335 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/handlers"
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// webhookReceiver records the signed deliveries it accepts
+type webhookReceiver struct {
+	mu         sync.Mutex
+	events     []models.WebhookEvent
+	signatures []string
+	bodies     [][]byte
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var event models.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rcv.mu.Lock()
+	rcv.events = append(rcv.events, event)
+	rcv.signatures = append(rcv.signatures, r.Header.Get(services.WebhookSignatureHeader))
+	rcv.bodies = append(rcv.bodies, body)
+	rcv.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rcv *webhookReceiver) received() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return len(rcv.events)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	receiver := &webhookReceiver{}
+	target := httptest.NewServer(receiver)
+	defer target.Close()
+
+	logger := setupTestLogger()
+	webhooks := services.NewWebhookService(logger, services.WithWebhookRetries(2, 10*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		webhooks.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	r := gin.New()
+	r.Use(handlers.RequestIDMiddleware())
+	password := r.Group("/api/v1/password")
+	password.Use(handlers.WebhookEventsMiddleware(webhooks))
+	password.POST("/check", handlers.PasswordCheckHandler(services.NewPasswordService(logger), nil))
+	admin := r.Group("/api/v1/admin")
+	admin.GET("/webhooks", handlers.AdminListWebhooksHandler(webhooks))
+	admin.POST("/webhooks", handlers.AdminCreateWebhookHandler(webhooks))
+	admin.GET("/webhooks/dead-letters", handlers.AdminListDeadLettersHandler(webhooks))
+	admin.POST("/webhooks/dead-letters/:id/retry", handlers.AdminRetryDeadLetterHandler(webhooks))
+	admin.DELETE("/webhooks/dead-letters/:id", handlers.AdminDeleteDeadLetterHandler(webhooks))
+	admin.GET("/webhooks/:id", handlers.AdminGetWebhookHandler(webhooks))
+	admin.DELETE("/webhooks/:id", handlers.AdminDeleteWebhookHandler(webhooks))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Unknown event types are rejected
+	w := request("POST", "/api/v1/admin/webhooks", `{"url":"`+target.URL+`","events":["password-changed"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = request("POST", "/api/v1/admin/webhooks", `{"url":"`+target.URL+`","events":["policy-violation"],"secret":"0123456789abcdef0123"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.WebhookSubscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "0123456789abcdef0123", created.Secret)
+	assert.Equal(t, []string{models.WebhookEventPolicyViolation}, created.Events)
+
+	// The secret is only returned on creation
+	w = request("GET", "/api/v1/admin/webhooks/"+created.ID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "0123456789abcdef0123")
+
+	w = request("GET", "/api/v1/admin/webhooks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), created.ID)
+	assert.NotContains(t, w.Body.String(), "0123456789abcdef0123")
+
+	// A weak password is a policy violation; a strong one sends nothing
+	assert.Equal(t, http.StatusOK, request("POST", "/api/v1/password/check", `{"password":"Tr0ub4dor&3-correct-horse-battery"}`).Code)
+	assert.Equal(t, http.StatusOK, request("POST", "/api/v1/password/check", `{"password":"Password1!"}`).Code)
+	require.Eventually(t, func() bool { return receiver.received() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	receiver.mu.Lock()
+	event, signature, body := receiver.events[0], receiver.signatures[0], receiver.bodies[0]
+	receiver.mu.Unlock()
+	assert.Equal(t, models.WebhookEventPolicyViolation, event.Type)
+	assert.Equal(t, "POST /api/v1/password/check", event.Route)
+	assert.NotEmpty(t, event.RequestID)
+	assert.NotContains(t, string(body), `"password"`)
+
+	// The signature is an HMAC of the timestamp and body under the secret
+	require.True(t, strings.HasPrefix(signature, "t="))
+	timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.SplitN(signature, ",", 2)[0], "t="), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, services.SignWebhook("0123456789abcdef0123", timestamp, body), signature)
+
+	// Deliveries to a failing endpoint are retried, then dead-lettered
+	var failures int32
+	var failMu sync.Mutex
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failMu.Lock()
+		failures++
+		failMu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	w = request("POST", "/api/v1/admin/webhooks", `{"url":"`+failing.URL+`","events":["policy-violation"]}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var broken models.WebhookSubscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &broken))
+	assert.True(t, strings.HasPrefix(broken.Secret, "whsec_"))
+
+	assert.Equal(t, http.StatusOK, request("POST", "/api/v1/password/check", `{"password":"Password1!"}`).Code)
+
+	var letters struct {
+		DeadLetters []models.WebhookDeadLetter `json:"dead_letters"`
+		Total       int                        `json:"total"`
+	}
+	require.Eventually(t, func() bool {
+		w := request("GET", "/api/v1/admin/webhooks/dead-letters?webhook="+broken.ID, "")
+		return json.Unmarshal(w.Body.Bytes(), &letters) == nil && letters.Total == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	letter := letters.DeadLetters[0]
+	assert.Equal(t, 2, letter.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, letter.LastStatus)
+	assert.Equal(t, models.WebhookEventPolicyViolation, letter.Event.Type)
+	failMu.Lock()
+	assert.EqualValues(t, 2, failures)
+	failMu.Unlock()
+	require.Eventually(t, func() bool { return receiver.received() == 2 }, 2*time.Second, 10*time.Millisecond)
+
+	// A retried dead letter gets a fresh set of attempts
+	assert.Equal(t, http.StatusAccepted, request("POST", "/api/v1/admin/webhooks/dead-letters/"+letter.ID+"/retry", "").Code)
+	require.Eventually(t, func() bool {
+		w := request("GET", "/api/v1/admin/webhooks/dead-letters", "")
+		return json.Unmarshal(w.Body.Bytes(), &letters) == nil && letters.Total == 1 && letters.DeadLetters[0].ID != letter.ID
+	}, 2*time.Second, 10*time.Millisecond)
+	failMu.Lock()
+	assert.EqualValues(t, 4, failures)
+	failMu.Unlock()
+
+	assert.Equal(t, http.StatusNoContent, request("DELETE", "/api/v1/admin/webhooks/dead-letters/"+letters.DeadLetters[0].ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, request("DELETE", "/api/v1/admin/webhooks/dead-letters/"+letters.DeadLetters[0].ID, "").Code)
+
+	// Deleted webhooks receive nothing more
+	assert.Equal(t, http.StatusNoContent, request("DELETE", "/api/v1/admin/webhooks/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/admin/webhooks/"+created.ID, "").Code)
+}
+
+func TestWebhookQuotaExceededEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	receiver := &webhookReceiver{}
+	target := httptest.NewServer(receiver)
+	defer target.Close()
+
+	logger := setupTestLogger()
+	webhooks := services.NewWebhookService(logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		webhooks.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	_, err := webhooks.Create(models.WebhookSubscriptionRequest{URL: target.URL, Events: []string{models.WebhookEventQuotaExceeded}, Tenant: "acme"})
+	require.NoError(t, err)
+
+	apiKeyService := services.NewAPIKeyService(logger)
+	key, err := apiKeyService.Issue("ci", "acme", []string{models.ScopeCheck})
+	require.NoError(t, err)
+
+	r := gin.New()
+	password := r.Group("/api/v1/password")
+	password.Use(handlers.WebhookEventsMiddleware(webhooks))
+	password.Use(handlers.APIKeyAuthMiddleware(apiKeyService, models.ScopeCheck))
+	password.Use(handlers.UsageQuotaMiddleware(logger, services.NewUsageService(services.WithMonthlyQuota(1)), metrics.Noop{}))
+	password.POST("/check", handlers.PasswordCheckHandler(services.NewPasswordService(logger), nil))
+
+	for _, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req, _ := http.NewRequest("POST", "/api/v1/password/check", bytes.NewBufferString(`{"password":"Tr0ub4dor&3-correct-horse-battery"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", key.Key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code)
+	}
+
+	require.Eventually(t, func() bool { return receiver.received() == 1 }, 2*time.Second, 10*time.Millisecond)
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	assert.Equal(t, models.WebhookEventQuotaExceeded, receiver.events[0].Type)
+	assert.Equal(t, "acme", receiver.events[0].Tenant)
+}
+
+func TestWebhookCircuitBreakerAndDeliveryLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var mu sync.Mutex
+	calls, healthy := 0, false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+
+	const cooldown = 300 * time.Millisecond
+	prometheus := metrics.NewPrometheus("test")
+	webhooks := services.NewWebhookService(setupTestLogger(),
+		services.WithWebhookRetries(3, 10*time.Millisecond),
+		services.WithWebhookCircuitBreaker(2, cooldown),
+		services.WithWebhookMetrics(prometheus),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		webhooks.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	r := gin.New()
+	admin := r.Group("/api/v1/admin")
+	admin.GET("/webhooks/:id", handlers.AdminGetWebhookHandler(webhooks))
+	admin.GET("/webhooks/:id/deliveries", handlers.AdminListWebhookDeliveriesHandler(webhooks))
+	request := func(path string, out interface{}) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if out != nil {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), out))
+		}
+		return w.Code
+	}
+
+	subscription, err := webhooks.Create(models.WebhookSubscriptionRequest{URL: target.URL, Events: []string{models.WebhookEventBreachFound}})
+	require.NoError(t, err)
+	webhooks.Publish(models.WebhookEvent{Type: models.WebhookEventBreachFound})
+	require.Eventually(t, func() bool { return len(webhooks.DeadLetters(subscription.ID)) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// Two failures open the circuit, so the last attempt does not call the webhook
+	mu.Lock()
+	assert.Equal(t, 2, calls)
+	healthy = true
+	mu.Unlock()
+
+	var log struct {
+		Deliveries []models.WebhookDeliveryAttempt `json:"deliveries"`
+		Total      int                             `json:"total"`
+	}
+	require.Equal(t, http.StatusOK, request("/api/v1/admin/webhooks/"+subscription.ID+"/deliveries", &log))
+	require.Equal(t, 3, log.Total)
+	assert.Equal(t, models.WebhookAttemptCircuitOpen, log.Deliveries[0].Result)
+	assert.Equal(t, 3, log.Deliveries[0].Attempt)
+	assert.Zero(t, log.Deliveries[0].Status)
+	assert.Equal(t, models.WebhookAttemptFailed, log.Deliveries[1].Result)
+	assert.Equal(t, http.StatusServiceUnavailable, log.Deliveries[1].Status)
+	assert.Equal(t, 1, log.Deliveries[2].Attempt)
+
+	var got models.WebhookSubscription
+	require.Equal(t, http.StatusOK, request("/api/v1/admin/webhooks/"+subscription.ID, &got))
+	assert.Equal(t, string(services.CircuitOpen), got.Circuit)
+
+	// After the cooldown a trial delivery closes the circuit again
+	time.Sleep(cooldown)
+	require.NoError(t, webhooks.RetryDeadLetter(webhooks.DeadLetters(subscription.ID)[0].ID))
+	require.Eventually(t, func() bool {
+		request("/api/v1/admin/webhooks/"+subscription.ID+"/deliveries", &log)
+		return log.Total == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, models.WebhookAttemptDelivered, log.Deliveries[0].Result)
+	assert.Equal(t, http.StatusNoContent, log.Deliveries[0].Status)
+	require.Equal(t, http.StatusOK, request("/api/v1/admin/webhooks/"+subscription.ID, &got))
+	assert.Equal(t, string(services.CircuitClosed), got.Circuit)
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_webhook_circuit_transitions_total{from="closed",to="open"} 1`)
+	assert.Contains(t, out.String(), `test_webhook_circuit_transitions_total{from="half_open",to="closed"} 1`)
+
+	assert.Equal(t, http.StatusNotFound, request("/api/v1/admin/webhooks/wh_unknown/deliveries", nil))
+}
@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestAnalyticsService_AggregatesChecksOverDays(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	analytics := services.NewAnalyticsService(
+		services.WithAnalyticsMinChecks(4),
+		services.WithAnalyticsClock(func() time.Time { return now }),
+	)
+
+	analytics.Record(models.CheckOutcome{Score: 95, Scored: true})
+	analytics.Record(models.CheckOutcome{Score: 100, Scored: true})
+	now = now.AddDate(0, 0, 1)
+	analytics.Record(models.CheckOutcome{Breached: true, PolicyFailure: true, Score: 12, Scored: true,
+		Violations: []models.PolicyRule{models.RuleNotBreached, models.RuleMinStrength, models.RuleSpecial}})
+	analytics.Record(models.CheckOutcome{PolicyFailure: true, Score: 41, Scored: true,
+		Violations: []models.PolicyRule{models.RuleMinStrength}})
+
+	report, err := analytics.Report("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02-10", report.From)
+	assert.Equal(t, "2026-03-11", report.To)
+	assert.False(t, report.Suppressed)
+	assert.EqualValues(t, 4, report.Checks)
+	assert.EqualValues(t, 1, report.Breached)
+	assert.Equal(t, 25.0, report.BreachedPercent)
+	assert.EqualValues(t, 2, report.PolicyFailures)
+	assert.Equal(t, 50.0, report.PolicyFailurePercent)
+	assert.Equal(t, 62.0, report.AverageScore)
+
+	require.Len(t, report.ScoreDistribution, 10)
+	assert.Equal(t, models.ScoreBucket{Min: 10, Max: 19, Count: 1}, report.ScoreDistribution[1])
+	assert.Equal(t, models.ScoreBucket{Min: 40, Max: 49, Count: 1}, report.ScoreDistribution[4])
+	assert.Equal(t, models.ScoreBucket{Min: 90, Max: 100, Count: 2}, report.ScoreDistribution[9])
+
+	assert.Equal(t, []models.RuleViolations{
+		{Rule: models.RuleMinStrength, Count: 2, Percent: 50},
+		{Rule: models.RuleNotBreached, Count: 1, Percent: 25},
+		{Rule: models.RuleSpecial, Count: 1, Percent: 25},
+	}, report.TopViolations)
+
+	// Fewer checks than the minimum only report their count
+	report, err = analytics.Report("2026-03-11", "2026-03-11")
+	require.NoError(t, err)
+	assert.True(t, report.Suppressed)
+	assert.EqualValues(t, 2, report.Checks)
+	assert.Zero(t, report.Breached)
+	assert.Empty(t, report.ScoreDistribution)
+	assert.Empty(t, report.TopViolations)
+}
+
+func TestAnalyticsService_ReportsOnlyRetainedDays(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	analytics := services.NewAnalyticsService(
+		services.WithAnalyticsRetention(7),
+		services.WithAnalyticsMinChecks(0),
+		services.WithAnalyticsClock(func() time.Time { return now }),
+	)
+
+	analytics.Record(models.CheckOutcome{})
+	now = now.AddDate(0, 0, 7)
+	analytics.Record(models.CheckOutcome{})
+
+	report, err := analytics.Report("2026-01-01", "2027-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-11", report.From)
+	assert.Equal(t, "2026-03-17", report.To)
+	assert.EqualValues(t, 1, report.Checks)
+
+	for _, invalid := range [][2]string{{"2026-03", ""}, {"", "yesterday"}, {"2026-03-12", "2026-03-11"}} {
+		_, err := analytics.Report(invalid[0], invalid[1])
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/auth"
+)
+
+func TestAttester_IssuesVerifiableTokens(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{"ES256": ecKey, "RS256": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			signer, err := auth.NewAttester("config-service", key, auth.WithAttestationAudience("signup"))
+			require.NoError(t, err)
+
+			breached := false
+			token, issued, err := signer.Attest(auth.Attestation{
+				Subject: "signup-7f3a", Score: 92, Strength: "very_strong", Policy: "default", Breached: &breached, Tenant: "acme",
+			})
+			require.NoError(t, err)
+			assert.Len(t, strings.Split(token, "."), 3)
+			assert.Equal(t, 5*time.Minute, issued.ExpiresAt.Sub(issued.IssuedAt))
+
+			verified, err := signer.Verify(token, "signup-7f3a")
+			require.NoError(t, err)
+			assert.Equal(t, issued.ID, verified.ID)
+			assert.Equal(t, "signup-7f3a", verified.Subject)
+			assert.Equal(t, 92, verified.Score)
+			assert.Equal(t, "very_strong", verified.Strength)
+			assert.Equal(t, "acme", verified.Tenant)
+			require.NotNil(t, verified.Breached)
+			assert.False(t, *verified.Breached)
+
+			// The token reveals nothing about the password, not even a digest
+			parts := strings.Split(token, ".")
+			payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+			require.NoError(t, err)
+			assert.NotContains(t, string(payload), `"pwd"`)
+
+			// Raising the score invalidates the signature
+			raised := strings.Replace(string(payload), `"score":92`, `"score":99`, 1)
+			require.NotEqual(t, string(payload), raised)
+			tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(raised)) + "." + parts[2]
+			_, err = signer.Verify(tampered, "signup-7f3a")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAttester_RejectsExpiredTokens(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	attester, err := auth.NewAttester("config-service", key,
+		auth.WithAttestationTTL(time.Minute),
+		auth.WithAttestationClock(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+
+	token, _, err := attester.Attest(auth.Attestation{Subject: "signup-7f3a", Score: 80})
+	require.NoError(t, err)
+	_, err = attester.Verify(token, "signup-7f3a")
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = attester.Verify(token, "signup-7f3a")
+	assert.EqualError(t, err, "token has expired")
+}
+
+func TestAttester_BindsTokensToTheirSubject(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	attester, err := auth.NewAttester("config-service", key)
+	require.NoError(t, err)
+
+	token, _, err := attester.Attest(auth.Attestation{Subject: "user-1", Score: 95})
+	require.NoError(t, err)
+	_, err = attester.Verify(token, "user-1")
+	require.NoError(t, err)
+
+	// A token for one account cannot be presented for another
+	_, err = attester.Verify(token, "user-2")
+	assert.EqualError(t, err, "token attests another subject")
+	_, err = attester.Verify(token, "")
+	assert.Error(t, err)
+
+	// Tokens bound to nothing are not issued
+	_, _, err = attester.Attest(auth.Attestation{Score: 95})
+	assert.EqualError(t, err, "attestation subject is required")
+}
+
+func TestLoadAttestationKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "attestation.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	loaded, err := auth.LoadAttestationKey(path)
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(loaded.Public()))
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = auth.NewAttester("config-service", weak)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/audit"
+)
+
+func TestAuditLogger_PrunesExpiredEvents(t *testing.T) {
+	sink, err := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+
+	auditLogger := audit.NewLogger(logrus.New(), sink, audit.WithRetention(24*time.Hour))
+	defer auditLogger.Close()
+
+	auditLogger.Record(audit.Event{Time: time.Now().Add(-48 * time.Hour), RequestID: "old", Result: audit.ResultPass})
+	auditLogger.Record(audit.Event{RequestID: "new", Result: audit.ResultWeak})
+
+	removed, err := auditLogger.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	// Events written after pruning go to the rewritten file
+	auditLogger.Record(audit.Event{RequestID: "newer", Result: audit.ResultBreached})
+
+	events, err := sink.Events(audit.Filter{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "new", events[0].RequestID)
+	assert.Equal(t, "newer", events[1].RequestID)
+}
+
+func TestAuditFilter_Matches(t *testing.T) {
+	now := time.Now()
+	event := audit.Event{Time: now, Tenant: "acme"}
+
+	assert.True(t, audit.Filter{}.Matches(event))
+	assert.True(t, audit.Filter{Since: now.Add(-time.Minute), Until: now.Add(time.Minute), Tenant: "acme"}.Matches(event))
+	assert.False(t, audit.Filter{Since: now.Add(time.Minute)}.Matches(event))
+	assert.False(t, audit.Filter{Until: now}.Matches(event))
+	assert.False(t, audit.Filter{Tenant: "other"}.Matches(event))
+}
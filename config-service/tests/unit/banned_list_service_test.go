@@ -0,0 +1,35 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/services"
+)
+
+func TestBannedListService_RemovedWordsArePurgedAfterRetention(t *testing.T) {
+	banned := services.NewBannedListService(logrus.New(), services.WithDeletedRetention(time.Millisecond))
+	banned.Add("acme")
+
+	require.True(t, banned.Remove("acme", "admin_token"))
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Empty(t, banned.Deleted())
+	_, err := banned.Restore("acme")
+	assert.ErrorIs(t, err, services.ErrBannedWordNotDeleted)
+}
+
+func TestBannedListService_AddingARemovedWordClearsIt(t *testing.T) {
+	banned := services.NewBannedListService(logrus.New())
+	banned.Add("acme")
+
+	require.True(t, banned.Remove("acme", "key_1"))
+	require.Len(t, banned.Deleted(), 1)
+
+	assert.Equal(t, 1, banned.Add("acme"))
+	assert.Empty(t, banned.Deleted())
+}
@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+)
+
+func TestBreachService_RetriesTransientFailures(t *testing.T) {
+	var requests int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("SOMEHASH:10"))
+	}))
+	defer mockServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	prometheus := metrics.NewPrometheus("test")
+
+	breachService := services.NewBreachService(logger,
+		services.WithAPIEndpoint(mockServer.URL),
+		services.WithRetries(2, time.Millisecond),
+		services.WithMetrics(prometheus))
+
+	_, err := breachService.CheckPasswordBreachContext(context.Background(), "RetriedPassword1!")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_hibp_requests_total{status="503"} 2`)
+	assert.Contains(t, out.String(), `test_hibp_requests_total{status="200"} 1`)
+	assert.Contains(t, out.String(), `test_hibp_retries_total 2`)
+	assert.Contains(t, out.String(), `test_hibp_response_bytes_total 11`)
+	assert.Contains(t, out.String(), `test_hibp_cache_bypass_total{reason="miss"} 1`)
+
+	// Client errors are not retried
+	atomic.StoreInt32(&requests, 0)
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer notFound.Close()
+
+	breachService = services.NewBreachService(logger,
+		services.WithAPIEndpoint(notFound.URL),
+		services.WithRetries(2, time.Millisecond))
+	_, err = breachService.CheckPasswordBreachContext(context.Background(), "RetriedPassword1!")
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+}
+
+func TestBreachService_CircuitBreaker(t *testing.T) {
+	var requests int32
+	var healthy int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("SOMEHASH:10"))
+	}))
+	defer mockServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	prometheus := metrics.NewPrometheus("test")
+
+	breachService := services.NewBreachService(logger,
+		services.WithAPIEndpoint(mockServer.URL),
+		services.WithCircuitBreaker(2, 50*time.Millisecond),
+		services.WithMetrics(prometheus))
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		assert.Error(t, breachService.Ping(ctx))
+	}
+	assert.Equal(t, services.CircuitOpen, breachService.CircuitState())
+
+	// While open, calls fail fast without reaching the API
+	err := breachService.Ping(ctx)
+	assert.Contains(t, err.Error(), "circuit breaker is open")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+
+	// After the cooldown a trial call closes the circuit again
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, breachService.Ping(ctx))
+	assert.Equal(t, services.CircuitClosed, breachService.CircuitState())
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_hibp_circuit_transitions_total{from="closed",to="open"} 1`)
+	assert.Contains(t, out.String(), `test_hibp_circuit_transitions_total{from="open",to="half_open"} 1`)
+	assert.Contains(t, out.String(), `test_hibp_circuit_transitions_total{from="half_open",to="closed"} 1`)
+	assert.Contains(t, out.String(), `test_hibp_requests_total{status="circuit_open"} 1`)
+	assert.Contains(t, out.String(), `test_hibp_cache_bypass_total{reason="health_probe"} 4`)
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleTrial(t *testing.T) {
+	breaker := services.NewCircuitBreaker(1, 10*time.Millisecond, nil)
+
+	assert.True(t, breaker.Allow())
+	breaker.Failure()
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(15 * time.Millisecond)
+	assert.True(t, breaker.Allow())
+	assert.Equal(t, services.CircuitHalfOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+
+	// A failed trial reopens the circuit
+	breaker.Failure()
+	assert.Equal(t, services.CircuitOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+}
@@ -1,15 +1,21 @@
 package services_test
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"config-service/internal/models"
+	"config-service/internal/requestid"
 	"config-service/internal/services"
 )
 
@@ -115,6 +121,100 @@ func TestBreachService_Disabled(t *testing.T) {
 	assert.Empty(t, result.LastBreached)
 }
 
+func TestBreachService_CheckHashesBreach(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/5baa6":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\nOTHERHASH:7"))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("SOMEHASH:10"))
+		}
+	}))
+	defer mockServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	breachService := services.NewBreachService(
+		logger,
+		services.WithAPIEndpoint(mockServer.URL),
+		services.WithEnabled(true),
+	)
+
+	results := breachService.CheckHashesBreach(context.Background(), []string{
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8",
+		"5baa6ffffffffffffffffffffffffffffffffff0",
+		"b1b3773a05c0ed0176787a4f1574ff0075f7521e",
+		"not-a-hash",
+	})
+	require.Len(t, results, 4)
+
+	assert.True(t, results[0].Found)
+	assert.Equal(t, 3861493, results[0].BreachCount)
+	assert.Equal(t, "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8", results[0].Hash)
+
+	assert.False(t, results[1].Found)
+	assert.False(t, results[2].Found)
+	assert.NotEmpty(t, results[3].Error)
+
+	// Hashes sharing a prefix are resolved with a single upstream request
+	assert.Equal(t, 2, requests)
+
+	// Repeated audits are served from the cache
+	breachService.CheckHashesBreach(context.Background(), []string{"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"})
+	assert.Equal(t, 2, requests)
+}
+
+func TestBreachService_CheckNTLMHashesBreach(t *testing.T) {
+	var query string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		assert.Equal(t, "/8846f", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("7EAEE8FB117AD06BDD830B7586C:52579\r\nOTHERHASH:7"))
+	}))
+	defer mockServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	breachService := services.NewBreachService(logger, services.WithAPIEndpoint(mockServer.URL))
+
+	results := breachService.CheckNTLMHashesBreach(context.Background(), []string{
+		"8846F7EAEE8FB117AD06BDD830B7586C",
+		"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8",
+	})
+	require.Len(t, results, 2)
+	assert.Equal(t, "mode=ntlm", query)
+	assert.True(t, results[0].Found)
+	assert.Equal(t, 52579, results[0].BreachCount)
+	assert.Contains(t, results[1].Error, "NTLM")
+}
+
+func TestBreachService_PropagatesRequestID(t *testing.T) {
+	var received string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("SOMEHASH:10"))
+	}))
+	defer mockServer.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	breachService := services.NewBreachService(logger, services.WithAPIEndpoint(mockServer.URL))
+
+	ctx := requestid.WithContext(context.Background(), "req-42")
+	_, err := breachService.CheckPasswordBreachContext(ctx, "CorrelatedPassword1!")
+	require.NoError(t, err)
+
+	assert.Equal(t, "req-42", received)
+}
+
 // MockBreachService creates a custom breach service for testing
 type MockBreachService struct {
 	services.BreachService
@@ -155,3 +255,81 @@ func (m *MockBreachService) CheckPasswordBreach(password string) (*models.Breach
 	
 	return &models.BreachInfo{Found: false}, nil
 }
+
+
+func TestBreachService_CacheEntriesExpireIndividually(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.Write([]byte("OTHERHASH:1\r\n"))
+	}))
+	defer mockServer.Close()
+	upstream := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	breachService := services.NewBreachService(logger,
+		services.WithAPIEndpoint(mockServer.URL),
+		services.WithCacheDuration(10),
+		services.WithCacheClock(func() time.Time { return now }),
+	)
+
+	_, err := breachService.CheckPasswordBreach("first-password")
+	require.NoError(t, err)
+	now = now.Add(5 * time.Minute)
+	_, err = breachService.CheckPasswordBreach("second-password")
+	require.NoError(t, err)
+	assert.Equal(t, 2, upstream())
+
+	// Only the entry older than the cache duration expires
+	now = now.Add(6 * time.Minute)
+	assert.Equal(t, 1, breachService.ExpireCache())
+	assert.Equal(t, 1, breachService.CacheStats().Entries)
+	_, err = breachService.CheckPasswordBreach("second-password")
+	require.NoError(t, err)
+	assert.Equal(t, 2, upstream())
+	_, err = breachService.CheckPasswordBreach("first-password")
+	require.NoError(t, err)
+	assert.Equal(t, 3, upstream())
+
+	// Expired entries are misses even before the sweep removes them
+	now = now.Add(5 * time.Minute)
+	_, err = breachService.CheckPasswordBreach("second-password")
+	require.NoError(t, err)
+	assert.Equal(t, 4, upstream())
+}
+
+func TestBreachService_LoadCacheSpreadsVersion1Expiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	breachService := services.NewBreachService(logger,
+		services.WithCacheDuration(10),
+		services.WithCacheClock(func() time.Time { return now }),
+	)
+
+	var entries []string
+	for i := 0; i < 100; i++ {
+		entries = append(entries, fmt.Sprintf(`"%040x": {"found": true, "breach_count": 1}`, i))
+	}
+	loaded, err := breachService.LoadCache(strings.NewReader(`{"version": 1, "entries": {` + strings.Join(entries, ",") + `}}`))
+	require.NoError(t, err)
+	assert.Equal(t, 100, loaded)
+
+	// Entries of a version 1 snapshot expire over the cache duration, not at once
+	now = now.Add(5 * time.Minute)
+	removed := breachService.ExpireCache()
+	assert.Greater(t, removed, 0)
+	assert.Less(t, removed, 100)
+	now = now.Add(5 * time.Minute)
+	breachService.ExpireCache()
+	assert.Equal(t, 0, breachService.CacheStats().Entries)
+}
@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/services"
+)
+
+// countingRangeServer serves empty HIBP ranges and counts the connections opened
+func countingRangeServer(t *testing.T) (*httptest.Server, func() int) {
+	var mu sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0000000000000000000000000000000000A:1\r\n"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return conns
+	}
+}
+
+func TestBreachService_TransportReusesConnections(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	check := func(breaches *services.BreachService) {
+		var wg sync.WaitGroup
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					_, err := breaches.CheckPasswordBreach(fmt.Sprintf("password-%d-%d", worker, i))
+					assert.NoError(t, err)
+				}
+			}(worker)
+		}
+		wg.Wait()
+	}
+
+	// Pooled connections serve every lookup
+	server, conns := countingRangeServer(t)
+	check(services.NewBreachService(logger, services.WithAPIEndpoint(server.URL)))
+	assert.LessOrEqual(t, conns(), 4)
+
+	// Without keep-alive, every lookup opens a connection
+	server, conns = countingRangeServer(t)
+	settings := services.DefaultTransportSettings()
+	settings.KeepAlive = 0
+	check(services.NewBreachService(logger, services.WithAPIEndpoint(server.URL), services.WithTransport(settings)))
+	assert.Equal(t, 40, conns())
+
+	// Connections beyond the limit wait for one to be free
+	server, conns = countingRangeServer(t)
+	settings = services.DefaultTransportSettings()
+	settings.MaxConnsPerHost = 1
+	settings.IdleConnTimeout = time.Minute
+	check(services.NewBreachService(logger, services.WithAPIEndpoint(server.URL), services.WithTransport(settings)))
+	require.Equal(t, 1, conns())
+}
@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func newCalibrationService() *services.PasswordService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return services.NewPasswordService(logger)
+}
+
+func TestCalibration_LoadCorpus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.tsv")
+	require.NoError(t, os.WriteFile(path, []byte("# label\tpassword\ncracked\t123456\n\nUncracked\txK9#mQ2$vL7@nR4!\r\ncracked\t with spaces \n"), 0o600))
+
+	samples, err := services.LoadCalibrationCorpus(path)
+	require.NoError(t, err)
+	assert.Equal(t, []services.CalibrationSample{
+		{Password: "123456", Cracked: true},
+		{Password: "xK9#mQ2$vL7@nR4!"},
+		{Password: " with spaces ", Cracked: true},
+	}, samples)
+
+	require.NoError(t, os.WriteFile(path, []byte("cracked\t123456\nbroken\tpassword\n"), 0o600))
+	_, err = services.LoadCalibrationCorpus(path)
+	assert.ErrorContains(t, err, "line 2")
+
+	require.NoError(t, os.WriteFile(path, []byte("cracked 123456\n"), 0o600))
+	_, err = services.LoadCalibrationCorpus(path)
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestCalibration_PerfectSeparation(t *testing.T) {
+	samples := []services.CalibrationSample{
+		{Password: "123456", Cracked: true},
+		{Password: "password", Cracked: true},
+		{Password: "qwerty", Cracked: true},
+		{Password: "xK9#mQ2$vL7@nR4!"},
+		{Password: "Zebra!Lantern#Quartz9"},
+		{Password: "7h!s-1s-a-L0ng-P@ssphrase"},
+	}
+
+	report, err := newCalibrationService().Calibrate(context.Background(), samples, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Samples)
+	assert.Equal(t, 3, report.Cracked)
+	assert.Equal(t, 3, report.Uncracked)
+	assert.InDelta(t, 1.0, report.AUC, 1e-9)
+	require.NotEmpty(t, report.ROC)
+	last := report.ROC[len(report.ROC)-1]
+	assert.Equal(t, 1.0, last.TruePositiveRate)
+	assert.Equal(t, 1.0, last.FalsePositiveRate)
+	assert.Zero(t, report.CrackedRatedStrong)
+	assert.Zero(t, report.UncrackedRatedWeak)
+	assert.Empty(t, report.Misclassified)
+
+	require.Len(t, report.Strengths, 4)
+	assert.Equal(t, models.StrengthWeak, report.Strengths[0].Strength)
+	assert.Equal(t, 3, report.Strengths[0].Cracked)
+	assert.Equal(t, 1.0, report.Strengths[0].CrackedRate)
+}
+
+func TestCalibration_ReportsMisclassifiedExamples(t *testing.T) {
+	samples := []services.CalibrationSample{
+		{Password: "xK9#mQ2$vL7@nR4!", Cracked: true},
+		{Password: "Zebra!Lantern#Quartz9", Cracked: true},
+		{Password: "123456", Cracked: true},
+		{Password: "password"},
+		{Password: "7h!s-1s-a-L0ng-P@ssphrase"},
+	}
+
+	report, err := newCalibrationService().Calibrate(context.Background(), samples, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.CrackedRatedStrong)
+	assert.Equal(t, 1, report.UncrackedRatedWeak)
+	assert.Less(t, report.AUC, 1.0)
+
+	require.Len(t, report.Misclassified, 2, "one example of each label")
+	assert.True(t, report.Misclassified[0].Cracked)
+	assert.True(t, report.Misclassified[0].Strength.AtLeast(models.StrengthStrong))
+	assert.Equal(t, "password", report.Misclassified[1].Password)
+	assert.False(t, report.Misclassified[1].Cracked)
+}
+
+func TestCalibration_AUCCountsTiesAsHalf(t *testing.T) {
+	samples := []services.CalibrationSample{
+		{Password: "123456", Cracked: true},
+		{Password: "123456"},
+	}
+
+	report, err := newCalibrationService().Calibrate(context.Background(), samples, 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, report.AUC, 1e-9)
+	assert.Len(t, report.ROC, 1)
+	assert.Empty(t, report.Misclassified)
+	assert.Equal(t, 1, report.UncrackedRatedWeak)
+}
+
+func TestCalibration_RequiresBothLabels(t *testing.T) {
+	_, err := newCalibrationService().Calibrate(context.Background(), []services.CalibrationSample{{Password: "123456", Cracked: true}}, 5)
+	assert.ErrorIs(t, err, services.ErrCalibrationCorpus)
+}
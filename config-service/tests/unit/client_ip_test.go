@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/clientip"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "192.0.2.10", "2001:db8::/32"}
+	tests := []struct {
+		name       string
+		header     string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{"untrusted peers are the client", clientip.HeaderXForwardedFor, "203.0.113.7:51000",
+			map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7"},
+		{"the nearest untrusted hop is the client", clientip.HeaderXForwardedFor, "10.1.2.3:443",
+			map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.1, 10.9.9.9"}, "198.51.100.1"},
+		{"every hop trusted", clientip.HeaderXForwardedFor, "10.1.2.3:443",
+			map[string]string{"X-Forwarded-For": "10.5.5.5, 192.0.2.10"}, "10.5.5.5"},
+		{"no header", clientip.HeaderXForwardedFor, "10.1.2.3:443", nil, "10.1.2.3"},
+		{"garbage ends the chain", clientip.HeaderXForwardedFor, "10.1.2.3:443",
+			map[string]string{"X-Forwarded-For": "198.51.100.1, not-an-ip"}, "10.1.2.3"},
+		{"x-real-ip", clientip.HeaderXRealIP, "192.0.2.10:443",
+			map[string]string{"X-Real-IP": "198.51.100.2", "X-Forwarded-For": "1.1.1.1"}, "198.51.100.2"},
+		{"forwarded", clientip.HeaderForwarded, "10.1.2.3:443",
+			map[string]string{"Forwarded": `for=198.51.100.3;proto=https, For="[2001:db8::1]:4711";by=10.1.2.3`}, "198.51.100.3"},
+		{"forwarded ipv6 client", clientip.HeaderForwarded, "10.1.2.3:443",
+			map[string]string{"Forwarded": `for="[2001:db9::5]:4711"`}, "2001:db9::5"},
+		{"forwarded unknown", clientip.HeaderForwarded, "10.1.2.3:443",
+			map[string]string{"Forwarded": "for=unknown"}, "10.1.2.3"},
+		{"headers ignored", clientip.HeaderNone, "10.1.2.3:443",
+			map[string]string{"X-Forwarded-For": "198.51.100.1"}, "10.1.2.3"},
+		{"unix socket peers are trusted", clientip.HeaderXForwardedFor, "@",
+			map[string]string{"X-Forwarded-For": "198.51.100.4"}, "198.51.100.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := clientip.New(trusted, tt.header)
+			require.NoError(t, err)
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			assert.Equal(t, tt.expected, resolver.Resolve(req))
+		})
+	}
+}
+
+func TestClientIPResolver_RejectsInvalidConfiguration(t *testing.T) {
+	_, err := clientip.New([]string{"10.0.0.0/33"}, clientip.HeaderXForwardedFor)
+	assert.Error(t, err)
+	_, err = clientip.New([]string{"proxy.internal"}, clientip.HeaderXForwardedFor)
+	assert.Error(t, err)
+	_, err = clientip.New(nil, "cf-connecting-ip")
+	assert.Error(t, err)
+
+	// Without trusted proxies, headers are never believed
+	resolver, err := clientip.New(nil, clientip.HeaderXForwardedFor)
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.1.2.3:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	assert.Equal(t, "10.1.2.3", resolver.Resolve(req))
+}
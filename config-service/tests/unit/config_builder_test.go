@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/config"
+)
+
+func TestBuilder_DefaultsMatchLoad(t *testing.T) {
+	for _, name := range []string{"VAULT_ADDR", "VAULT_TOKEN", "VAULT_NAMESPACE", "AWS_REGION"} {
+		t.Setenv(name, "")
+	}
+
+	built, err := config.NewBuilder().Build()
+	require.NoError(t, err)
+
+	loaded, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, loaded, built)
+}
+
+func TestBuilder_AppliesOptionsInOrder(t *testing.T) {
+	// Environment variables are ignored by the builder
+	t.Setenv("CONFIG_SERVICE_SERVER_PORT", "6060")
+
+	cfg, err := config.NewBuilder(
+		config.WithPort(9090),
+		config.WithBreachTimeout(3*time.Second),
+		config.WithBreachCacheDuration(2*time.Hour),
+		config.WithThrottle(10, 100*time.Millisecond, time.Second),
+		config.WithHMACKeys("k1:acme:secret"),
+	).With(
+		config.WithPort(9191),
+		func(c *config.Config) { c.Audit.RetentionDays = 30 },
+	).Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, 9191, cfg.Server.Port)
+	assert.Equal(t, 3, cfg.Breach.Timeout)
+	assert.Equal(t, 120, cfg.Breach.CacheDuration)
+	assert.True(t, cfg.Throttle.Enabled)
+	assert.Equal(t, 100, cfg.Throttle.BaseDelay)
+	assert.Equal(t, 1000, cfg.Throttle.MaxDelay)
+	assert.True(t, cfg.Auth.HMAC.Enabled)
+	assert.Equal(t, 30, cfg.Audit.RetentionDays)
+}
+
+func TestBuilder_Validates(t *testing.T) {
+	_, err := config.NewBuilder(config.WithPort(0)).Build()
+	assert.ErrorContains(t, err, "invalid port")
+
+	_, err = config.NewBuilder(config.WithJWT("", "api")).Build()
+	assert.ErrorContains(t, err, "auth.jwt.issuer is required")
+	_, err = config.NewBuilder(config.WithJWT("https://id.example.com", "")).Build()
+	assert.ErrorContains(t, err, "auth.jwt.audience is required")
+
+	// Strict validation catches settings the lenient checks accept
+	captchaWithoutSecret := func(c *config.Config) { c.Throttle.CaptchaURL = "https://captcha.example.com/siteverify" }
+	_, err = config.NewBuilder(captchaWithoutSecret).Build()
+	assert.NoError(t, err)
+	_, err = config.NewBuilder(config.WithStrict(true), captchaWithoutSecret).Build()
+	var strictErr *config.StrictError
+	require.ErrorAs(t, err, &strictErr)
+	assert.Equal(t, []string{"throttle.captcha_verify_url is set without throttle.captcha_secret"}, strictErr.Problems)
+}
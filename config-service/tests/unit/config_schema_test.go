@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/config"
+)
+
+func TestSchema_DescribesEveryKey(t *testing.T) {
+	schema, err := config.Schema()
+	require.NoError(t, err)
+
+	for _, key := range config.Keys() {
+		property := schema
+		for _, name := range strings.Split(key, ".") {
+			require.Contains(t, property.Properties, name, key)
+			property = property.Properties[name]
+		}
+		assert.NotEmpty(t, property.Description, "%s has no description", key)
+		assert.NotEmpty(t, property.Type, key)
+	}
+}
+
+func TestSchema_TypesDefaultsAndConstraints(t *testing.T) {
+	schema, err := config.Schema()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &document))
+	assert.Equal(t, config.SchemaVersion, document["$schema"])
+
+	port := schema.Properties["server"].Properties["port"]
+	assert.Equal(t, "integer", port.Type)
+	assert.Equal(t, 8080, port.Default)
+	assert.Equal(t, 1.0, *port.Minimum)
+	assert.Equal(t, 65535.0, *port.Maximum)
+
+	level := schema.Properties["logging"].Properties["level"]
+	assert.Equal(t, "info", level.Default)
+	assert.Contains(t, level.Enum, "debug")
+
+	keys := schema.Properties["auth"].Properties["hmac"].Properties["keys"]
+	assert.Equal(t, "array", keys.Type)
+	assert.Equal(t, "string", keys.Items.Type)
+	assert.True(t, keys.WriteOnly)
+	assert.Nil(t, keys.Default)
+
+	assert.False(t, *schema.Properties["breach"].AdditionalProperties)
+}
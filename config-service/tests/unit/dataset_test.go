@@ -0,0 +1,285 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/dataset"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// writeDataset writes lines to a file in a test directory
+func writeDataset(t *testing.T, name string, lines []string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+	return path
+}
+
+func TestDataset_LooksUpEveryEntry(t *testing.T) {
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("key%05d:%d", i*2, i))
+	}
+	sort.Strings(lines)
+	file, err := dataset.Open(writeDataset(t, "pairs.txt", lines), dataset.WithSeparator(':'))
+	require.NoError(t, err)
+	defer file.Close()
+
+	assert.Equal(t, 1000, file.Len())
+	for i := 0; i < 1000; i++ {
+		value, found := file.Lookup(fmt.Sprintf("key%05d", i*2))
+		require.True(t, found, i)
+		assert.Equal(t, fmt.Sprint(i), value)
+		assert.False(t, file.Contains(fmt.Sprintf("key%05d", i*2+1)), i)
+	}
+	assert.False(t, file.Contains("a"))
+	assert.False(t, file.Contains("zzz"))
+}
+
+func TestDataset_RejectsUnsortedFiles(t *testing.T) {
+	_, err := dataset.Open(writeDataset(t, "unsorted.txt", []string{"apple", "cherry", "banana"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3 is out of order")
+
+	missing, err := dataset.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestDataset_LimitsOpenDatasets(t *testing.T) {
+	path := writeDataset(t, "words.txt", []string{"alpha", "beta"})
+	dataset.SetMaxOpen(dataset.OpenCount() + 1)
+	defer dataset.SetMaxOpen(0)
+
+	first, err := dataset.Open(path)
+	require.NoError(t, err)
+	_, err = dataset.Open(path)
+	assert.ErrorIs(t, err, dataset.ErrTooManyOpen)
+
+	// Closing a dataset makes room for another, however often it is closed
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close())
+	second, err := dataset.Open(path)
+	require.NoError(t, err)
+	defer second.Close()
+	_, err = dataset.Open(path)
+	assert.ErrorIs(t, err, dataset.ErrTooManyOpen)
+}
+
+func TestBreachService_OfflineDatasetAnswersLookups(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected range request %s", r.URL)
+	}))
+	defer server.Close()
+
+	digest := sha1.Sum([]byte("password"))
+	hash := strings.ToUpper(hex.EncodeToString(digest[:]))
+	lines := []string{"0000000000000000000000000000000000000001:3", hash + ":9545824\r", "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:1"}
+	offline, err := dataset.Open(writeDataset(t, "pwned-passwords-sha1.txt", lines), dataset.WithSeparator(':'))
+	require.NoError(t, err)
+	defer offline.Close()
+
+	service := services.NewBreachService(logger,
+		services.WithAPIEndpoint(server.URL),
+		services.WithOfflineDatasets(offline, nil),
+	)
+
+	info, err := service.CheckPasswordBreach("password")
+	require.NoError(t, err)
+	assert.True(t, info.Found)
+	assert.Equal(t, 9545824, info.BreachCount)
+
+	info, err = service.CheckPasswordBreach("Tr0ub4dor&3-Horse!Staple")
+	require.NoError(t, err)
+	assert.False(t, info.Found)
+
+	results := service.CheckHashesBreach(context.Background(), []string{strings.ToLower(hash), "0000000000000000000000000000000000000002"})
+	assert.True(t, results[0].Found)
+	assert.Equal(t, 9545824, results[0].BreachCount)
+	assert.False(t, results[1].Found)
+	assert.Empty(t, results[1].Error)
+}
+
+func TestPasswordService_PenalizesDictionaryPasswords(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dictionary, err := dataset.Open(writeDataset(t, "common.txt", []string{"correcthorse!1", "p@ssw0rd123!", "qwerty"}))
+	require.NoError(t, err)
+	defer dictionary.Close()
+
+	plain := services.NewPasswordService(logger)
+	withDictionary := services.NewPasswordService(logger, services.WithDictionaries(dictionary))
+
+	expected, err := plain.CheckPasswordStrength("P@ssw0rd123!")
+	require.NoError(t, err)
+	actual, err := withDictionary.CheckPasswordStrength("P@ssw0rd123!")
+	require.NoError(t, err)
+	assert.Less(t, actual.Score, expected.Score)
+	assert.Contains(t, actual.Feedback.Warnings, "Password is a commonly used password")
+
+	expected, err = plain.CheckPasswordStrength("Tr0ub4dor&3-Horse!Staple")
+	require.NoError(t, err)
+	actual, err = withDictionary.CheckPasswordStrength("Tr0ub4dor&3-Horse!Staple")
+	require.NoError(t, err)
+	assert.Equal(t, expected.Score, actual.Score)
+}
+
+func TestDatasetSlot_ClosesRetiredVersionsAfterTheirLookups(t *testing.T) {
+	first, err := dataset.Open(writeDataset(t, "v1.txt", []string{"apple"}))
+	require.NoError(t, err)
+	second, err := dataset.Open(writeDataset(t, "v2.txt", []string{"banana"}))
+	require.NoError(t, err)
+	slot := dataset.NewSlot(first)
+	defer slot.Close()
+	open := dataset.OpenCount()
+
+	// A lookup in progress keeps the version it started with
+	file, release := slot.Acquire()
+	slot.Retire(slot.Swap(second))
+	assert.True(t, file.Contains("apple"))
+	assert.Equal(t, open, dataset.OpenCount())
+
+	release()
+	assert.Equal(t, open-1, dataset.OpenCount())
+
+	file, release = slot.Acquire()
+	defer release()
+	assert.True(t, file.Contains("banana"))
+	assert.Equal(t, second.Path(), slot.Path())
+}
+
+func TestDatasetReloader_SwitchesDictionariesAndRollsBack(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	path := writeDataset(t, "common.txt", []string{"qwerty"})
+	dictionary, err := dataset.Open(path)
+	require.NoError(t, err)
+	passwords := services.NewPasswordService(logger, services.WithDictionaries(dictionary))
+	defer passwords.Dictionaries()[0].Close()
+	penalized := func() bool {
+		response, err := passwords.CheckPasswordStrength("P@ssw0rd123!")
+		require.NoError(t, err)
+		for _, warning := range response.Feedback.Warnings {
+			if warning == "Password is a commonly used password" {
+				return true
+			}
+		}
+		return false
+	}
+	// replace renames a new version over the dictionary, as mapped files must
+	// not be rewritten in place
+	replace := func(contents string) {
+		require.NoError(t, os.WriteFile(path+".new", []byte(contents), 0o600))
+		require.NoError(t, os.Rename(path+".new", path))
+	}
+
+	var failCheck bool
+	reloader := services.NewDatasetReloader(logger, services.WithReloadCheck(func(context.Context) error {
+		if failCheck {
+			return fmt.Errorf("self-test drifted")
+		}
+		return nil
+	}))
+	reloader.Register("dictionaries", services.SlotDataset(func(path string) (*dataset.File, error) {
+		return dataset.Open(path)
+	}, passwords.Dictionaries()...))
+	reload := func() models.DatasetReload {
+		started, err := reloader.Start("dictionaries")
+		require.NoError(t, err)
+		var reload models.DatasetReload
+		require.Eventually(t, func() bool {
+			reload, err = reloader.Get(started.ID)
+			require.NoError(t, err)
+			return reload.Status != models.DatasetReloadLoading
+		}, 5*time.Second, 10*time.Millisecond)
+		return reload
+	}
+
+	// A new version on disk is switched to
+	require.False(t, penalized())
+	replace("p@ssw0rd123!\nqwerty\n")
+	assert.Equal(t, models.DatasetReloadCompleted, reload().Status)
+	assert.True(t, penalized())
+
+	// A version that fails to load, or fails the check, leaves the previous one in use
+	replace("zebra\napple\n")
+	failed := reload()
+	assert.Equal(t, models.DatasetReloadFailed, failed.Status)
+	assert.Contains(t, failed.Error, "out of order")
+	assert.True(t, penalized())
+
+	replace("qwerty\n")
+	failCheck = true
+	rolledBack := reload()
+	assert.Equal(t, models.DatasetReloadRolledBack, rolledBack.Status)
+	assert.Equal(t, "self-test drifted", rolledBack.Error)
+	assert.True(t, penalized())
+
+	_, err = reloader.Start("unknown")
+	assert.ErrorIs(t, err, services.ErrDatasetNotFound)
+	assert.Len(t, reloader.List(), 3)
+}
+
+func TestDatasetVerifier_ChecksumsAndSignatures(t *testing.T) {
+	path := writeDataset(t, "common.txt", []string{"letmein", "password", "qwerty"})
+	file, err := dataset.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	// Without a checksum file, only the required mode refuses the file
+	require.NoError(t, dataset.NewVerifier(dataset.ChecksumsOptional, nil).VerifyFile(file))
+	assert.ErrorIs(t, dataset.NewVerifier(dataset.ChecksumsRequired, nil).VerifyFile(file), dataset.ErrIntegrity)
+
+	require.NoError(t, os.WriteFile(path+".sha256", []byte(file.Digest()+"  common.txt\n"), 0o600))
+	verifier := dataset.NewVerifier(dataset.ChecksumsRequired, nil)
+	require.NoError(t, verifier.VerifyFile(file))
+	results := verifier.Results()
+	require.Len(t, results, 1)
+	assert.Equal(t, dataset.StatusVerified, results[0].Status)
+	assert.Equal(t, file.Digest(), results[0].SHA256)
+
+	// Tampered contents are refused
+	assert.ErrorIs(t, verifier.VerifyData(path, []byte("letmein\n")), dataset.ErrIntegrity)
+	assert.Equal(t, dataset.StatusFailed, verifier.Results()[0].Status)
+
+	// With a signing key, the checksum must be signed
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signed := dataset.NewVerifier(dataset.ChecksumsOff, publicKey)
+	assert.ErrorIs(t, signed.VerifyFile(file), dataset.ErrIntegrity)
+
+	digest, err := hex.DecodeString(file.Digest())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+".sig", []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, digest))+"\n"), 0o600))
+	require.NoError(t, signed.VerifyFile(file))
+	assert.True(t, signed.Results()[0].Signed)
+
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+".sig", ed25519.Sign(otherKey, digest), 0o600))
+	assert.ErrorIs(t, signed.VerifyFile(file), dataset.ErrIntegrity)
+}
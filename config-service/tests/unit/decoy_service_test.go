@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+)
+
+func TestDecoys_LoadAndMatch(t *testing.T) {
+	digest := sha256.Sum256([]byte("Vault-Admin-2031!"))
+	path := filepath.Join(t.TempDir(), "decoys.tsv")
+	content := "# canary credentials\nhr-share\tSpring-Payroll-77\nvault-admin\tsha256:" + hex.EncodeToString(digest[:]) + "\n\nUnlabeled-Decoy-9\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	decoys, err := services.LoadDecoys(path)
+	require.NoError(t, err)
+	require.Len(t, decoys, 3)
+	assert.Equal(t, "hr-share", decoys[0].Label)
+	assert.Equal(t, "vault-admin", decoys[1].Label)
+	assert.Equal(t, digest, decoys[1].Digest)
+	assert.Equal(t, "line 5", decoys[2].Label)
+
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+	prometheus := metrics.NewPrometheus("test")
+	decoyService := services.NewDecoyService(logger, decoys, services.WithDecoyMetrics(prometheus))
+	assert.Equal(t, 3, decoyService.Len())
+
+	assert.True(t, decoyService.Check(context.Background(), "Vault-Admin-2031!"))
+	assert.True(t, decoyService.Check(context.Background(), "Unlabeled-Decoy-9"))
+	assert.False(t, decoyService.Check(context.Background(), "spring-payroll-77"), "decoys match exactly")
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_decoy_matches_total{decoy="vault-admin"} 1`)
+	assert.Contains(t, logs.String(), "level=error")
+	assert.Contains(t, logs.String(), "decoy=vault-admin")
+	assert.NotContains(t, logs.String(), "Vault-Admin-2031!")
+}
+
+func TestDecoys_LoadRejectsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decoys.tsv")
+	for _, content := range []string{"label\t\n", "\tpassword\n", "label\tsha256:abc\n"} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		_, err := services.LoadDecoys(path)
+		assert.ErrorContains(t, err, "line 1", content)
+	}
+}
@@ -0,0 +1,506 @@
+package services_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/metrics"
+	"config-service/internal/models"
+	"config-service/internal/queue"
+	"config-service/internal/services"
+)
+
+// fakeJetStream is a NATS server with JetStream speaking enough of the
+// protocol to test the publisher. Messages to subjects under "events." are
+// stored by the stream EVENTS and acknowledged when they have a reply
+// subject; others are answered with the no responders status.
+func fakeJetStream(t *testing.T) (string, <-chan queue.Message) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	stored := make(chan queue.Message, 8)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			go serveJetStream(conn, stored)
+		}
+	}()
+	return listener.Addr().String(), stored
+}
+
+func serveJetStream(conn net.Conn, stored chan<- queue.Message) {
+	io.WriteString(conn, `INFO {"server_id":"test","max_payload":1048576,"headers":true,"jetstream":true}`+"\r\n")
+	reader := bufio.NewReader(conn)
+	inboxSID := ""
+	sequence := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			inboxSID = fields[len(fields)-1]
+		case "PUB", "HPUB":
+			headerSize := 0
+			if fields[0] == "HPUB" {
+				headerSize, _ = strconv.Atoi(fields[len(fields)-2])
+			}
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			message := queue.Message{Topic: fields[1], Data: payload[headerSize:size], Header: map[string]string{}}
+			for _, header := range strings.Split(string(payload[:headerSize]), "\r\n")[1:] {
+				if name, value, ok := strings.Cut(header, ": "); ok {
+					message.Header[name] = value
+				}
+			}
+			reply := ""
+			if (fields[0] == "PUB" && len(fields) == 4) || (fields[0] == "HPUB" && len(fields) == 5) {
+				reply = fields[2]
+			}
+
+			if !strings.HasPrefix(message.Topic, "events.") {
+				if reply != "" {
+					status := "NATS/1.0 503\r\n\r\n"
+					fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", reply, inboxSID, len(status), len(status), status)
+				}
+				continue
+			}
+			sequence++
+			stored <- message
+			if reply != "" {
+				ack := fmt.Sprintf(`{"stream":"EVENTS","seq":%d}`, sequence)
+				fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, inboxSID, len(ack), ack)
+			}
+		}
+	}
+}
+
+func TestJetStreamPublisher(t *testing.T) {
+	addr, stored := fakeJetStream(t)
+	publisher, err := queue.OpenPublisher("nats", "nats://"+addr, queue.PublisherOptions{Name: "config-service", Timeout: 2 * time.Second})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	// Acknowledged messages carry their ID for the stream's deduplication
+	require.NoError(t, publisher.Publish(context.Background(), "events.breach-found", "acme", "evt_1", []byte(`{"id":"evt_1"}`)))
+	message := <-stored
+	assert.Equal(t, "events.breach-found", message.Topic)
+	assert.Equal(t, `{"id":"evt_1"}`, string(message.Data))
+	assert.Equal(t, "evt_1", message.Header["Nats-Msg-Id"])
+	require.NoError(t, publisher.Publish(context.Background(), "events.decoy-matched", "", "evt_2", []byte(`{}`)))
+	assert.Equal(t, "events.decoy-matched", (<-stored).Topic)
+
+	// A subject no stream captures is reported rather than lost silently
+	err = publisher.Publish(context.Background(), "other.breach-found", "", "evt_3", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no JetStream stream captures other.breach-found")
+
+	// At most once, messages are not acknowledged
+	unacked, err := queue.OpenPublisher("nats", "nats://"+addr, queue.PublisherOptions{Delivery: queue.AtMostOnce})
+	require.NoError(t, err)
+	defer unacked.Close()
+	require.NoError(t, unacked.Publish(context.Background(), "events.quota-exceeded", "", "evt_4", []byte(`{}`)))
+	assert.Equal(t, "evt_4", (<-stored).Header["Nats-Msg-Id"])
+
+	_, err = queue.OpenPublisher("pulsar", "pulsar://"+addr, queue.PublisherOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown publisher driver")
+}
+
+// kafkaRecord is a record received by fakeKafka
+type kafkaRecord struct {
+	topic     string
+	partition int32
+	acks      int16
+	key       string
+	value     string
+	headers   map[string]string
+	crcValid  bool
+}
+
+// fakeKafka is a Kafka broker speaking enough of the protocol to test the
+// producer: the API versions it supports, SASL PLAIN for svc:s3cret, metadata
+// for a topic "events" of five partitions led by itself, and produce
+// requests, whose records it decodes
+func fakeKafka(t *testing.T) (string, <-chan kafkaRecord) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	records := make(chan kafkaRecord, 8)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			go serveKafka(conn, listener.Addr().(*net.TCPAddr), records)
+		}
+	}()
+	return listener.Addr().String(), records
+}
+
+func serveKafka(conn net.Conn, addr *net.TCPAddr, records chan<- kafkaRecord) {
+	for {
+		var size int32
+		if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+			return
+		}
+		request := make([]byte, size)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		r := bytes.NewReader(request)
+		apiKey, _, correlation := readInt16(r), readInt16(r), readInt32(r)
+		readString(r) // client ID
+
+		var response bytes.Buffer
+		write := func(values ...interface{}) {
+			for _, value := range values {
+				if s, ok := value.(string); ok {
+					binary.Write(&response, binary.BigEndian, int16(len(s)))
+					response.WriteString(s)
+					continue
+				}
+				binary.Write(&response, binary.BigEndian, value)
+			}
+		}
+		write(correlation)
+
+		switch apiKey {
+		case 18: // ApiVersions: Produce v3, Metadata v1, SaslHandshake v1, SaslAuthenticate v0
+			write(int16(0), int32(4), int16(0), int16(3), int16(3), int16(3), int16(1), int16(1),
+				int16(17), int16(1), int16(1), int16(36), int16(0), int16(0))
+		case 17: // SaslHandshake
+			write(int16(0), int32(1), "PLAIN")
+		case 36: // SaslAuthenticate
+			token := make([]byte, readInt32(r))
+			io.ReadFull(r, token)
+			if string(token) == "\x00svc\x00s3cret" {
+				write(int16(0), int16(-1), int32(0))
+			} else {
+				write(int16(58), "Authentication failed: Invalid username or password", int32(0))
+			}
+		case 3: // Metadata
+			write(int32(1), int32(0), addr.IP.String(), int32(addr.Port), int16(-1), int32(0))
+			// A null list of topics asks for every topic
+			names := []string{"events"}
+			if n := readInt32(r); n >= 0 {
+				names = make([]string, n)
+				for i := range names {
+					names[i] = readString(r)
+				}
+			}
+			write(int32(len(names)))
+			for _, topic := range names {
+				if topic != "events" {
+					write(int16(3), topic, false, int32(0))
+					continue
+				}
+				write(int16(0), topic, false, int32(5))
+				for partition := int32(0); partition < 5; partition++ {
+					write(int16(0), partition, int32(0), int32(1), int32(0), int32(1), int32(0))
+				}
+			}
+		case 0: // Produce
+			readInt16(r) // transactional ID
+			record := kafkaRecord{acks: readInt16(r)}
+			readInt32(r) // timeout
+			readInt32(r)
+			record.topic = readString(r)
+			readInt32(r)
+			record.partition = readInt32(r)
+			batch := make([]byte, readInt32(r))
+			io.ReadFull(r, batch)
+			decodeKafkaBatch(batch, &record)
+			records <- record
+			if record.acks == 0 {
+				continue
+			}
+			write(int32(1), record.topic, int32(1), record.partition, int16(0), int64(42), int64(-1), int32(0))
+		}
+
+		binary.Write(conn, binary.BigEndian, int32(response.Len()))
+		conn.Write(response.Bytes())
+	}
+}
+
+// decodeKafkaBatch decodes the only record of a record batch
+func decodeKafkaBatch(batch []byte, record *kafkaRecord) {
+	crc := binary.BigEndian.Uint32(batch[17:21])
+	record.crcValid = crc == crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli))
+
+	r := bytes.NewReader(batch[21+2+4+8+8+8+2+4+4:])
+	readVarint := func() int64 { v, _ := binary.ReadVarint(r); return v }
+	readBytes := func() string {
+		n := readVarint()
+		if n < 0 {
+			return ""
+		}
+		b := make([]byte, n)
+		io.ReadFull(r, b)
+		return string(b)
+	}
+	readVarint() // record length
+	r.ReadByte() // attributes
+	readVarint() // timestamp delta
+	readVarint() // offset delta
+	record.key = readBytes()
+	record.value = readBytes()
+	record.headers = map[string]string{}
+	for i := readVarint(); i > 0; i-- {
+		name := readBytes()
+		record.headers[name] = readBytes()
+	}
+}
+
+func readInt16(r io.Reader) int16 {
+	var v int16
+	binary.Read(r, binary.BigEndian, &v)
+	return v
+}
+
+func readInt32(r io.Reader) int32 {
+	var v int32
+	binary.Read(r, binary.BigEndian, &v)
+	return v
+}
+
+func readString(r io.Reader) string {
+	b := make([]byte, readInt16(r))
+	io.ReadFull(r, b)
+	return string(b)
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	addr, records := fakeKafka(t)
+	publisher, err := queue.OpenPublisher("kafka", "kafka://svc:s3cret@"+addr, queue.PublisherOptions{Name: "config-service", Timeout: 2 * time.Second})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	// Keys are assigned partitions as by Kafka's default partitioner, whose
+	// murmur2 hashes of these keys are -790332482, 479470107, and -973932308
+	for key, partition := range map[string]int32{"foobar": 1, "abc": 2, "21": 0} {
+		require.NoError(t, publisher.Publish(context.Background(), "events", key, "evt_"+key, []byte(`{"type":"breach-found"}`)))
+		record := <-records
+		assert.Equal(t, "events", record.topic)
+		assert.Equal(t, partition, record.partition, key)
+		assert.Equal(t, int16(-1), record.acks)
+		assert.Equal(t, key, record.key)
+		assert.Equal(t, `{"type":"breach-found"}`, record.value)
+		assert.Equal(t, map[string]string{"id": "evt_" + key}, record.headers)
+		assert.True(t, record.crcValid)
+	}
+
+	err = publisher.Publish(context.Background(), "missing", "", "evt_1", []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to publish to Kafka topic missing: [3] Unknown Topic Or Partition")
+
+	// At most once, brokers do not confirm records
+	unacked, err := queue.OpenPublisher("kafka", "kafka://svc:s3cret@"+addr, queue.PublisherOptions{Delivery: queue.AtMostOnce})
+	require.NoError(t, err)
+	defer unacked.Close()
+	require.NoError(t, unacked.Publish(context.Background(), "events", "", "", []byte(`{}`)))
+	assert.Equal(t, int16(0), (<-records).acks)
+
+	_, err = queue.OpenPublisher("kafka", "kafka://svc:wrong@"+addr, queue.PublisherOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authentication failed")
+	_, err = queue.OpenPublisher("kafka", "kafka://svc:s3cret@"+addr, queue.PublisherOptions{Mechanism: "GSSAPI"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported SASL mechanism")
+}
+
+// streamedEvent is an event published by fakePublisher
+type streamedEvent struct {
+	topic, key, id string
+	event          models.WebhookEvent
+}
+
+// fakePublisher fails its first failures publications, then records events
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	opened    int
+	closed    int
+	published []streamedEvent
+}
+
+func (p *fakePublisher) open() (queue.Publisher, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.opened++
+	return p, nil
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic, key, id string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+	published := streamedEvent{topic: topic, key: key, id: id}
+	if err := json.Unmarshal(data, &published.event); err != nil {
+		return err
+	}
+	p.published = append(p.published, published)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed++
+	return nil
+}
+
+func (p *fakePublisher) events() []streamedEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]streamedEvent(nil), p.published...)
+}
+
+// runEventStream runs an event stream until the test ends
+func runEventStream(t *testing.T, stream *services.EventStream) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stream.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestEventStream_RetriesUntilPublished(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	prometheus := metrics.NewPrometheus("test")
+	publisher := &fakePublisher{failures: 2}
+	stream := services.NewEventStream(logger, publisher.open,
+		services.WithEventTopic("passwords.{type}"),
+		services.WithEventTypes([]string{models.WebhookEventBreachFound}),
+		services.WithEventRetryBackoff(time.Millisecond),
+		services.WithEventMetrics(prometheus),
+	)
+	runEventStream(t, stream)
+
+	stream.Publish(models.WebhookEvent{Type: models.WebhookEventPolicyViolation, Tenant: "acme"})
+	stream.Publish(models.WebhookEvent{Type: models.WebhookEventBreachFound, Tenant: "acme", RequestID: "req-1"})
+	require.Eventually(t, func() bool { return len(publisher.events()) == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	published := publisher.events()[0]
+	assert.Equal(t, "passwords.breach-found", published.topic)
+	assert.Equal(t, "acme", published.key)
+	assert.True(t, strings.HasPrefix(published.id, "evt_"))
+	assert.Equal(t, published.id, published.event.ID)
+	assert.Equal(t, "req-1", published.event.RequestID)
+	assert.False(t, published.event.OccurredAt.IsZero())
+
+	// Each failure reconnects
+	publisher.mu.Lock()
+	assert.Equal(t, 3, publisher.opened)
+	assert.Equal(t, 2, publisher.closed)
+	publisher.mu.Unlock()
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_events_published_total{event="breach-found",result="retried"} 2`)
+	assert.Contains(t, out.String(), `test_events_published_total{event="breach-found",result="published"} 1`)
+	assert.NotContains(t, out.String(), `policy-violation`)
+}
+
+func TestEventStream_ZeroBackoffKeepsTheDefault(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	publisher := &fakePublisher{failures: 1000}
+	stream := services.NewEventStream(logger, publisher.open, services.WithEventRetryBackoff(0))
+	runEventStream(t, stream)
+
+	// A zero delay would reconnect as fast as the publisher fails
+	stream.Publish(models.WebhookEvent{Type: models.WebhookEventBreachFound})
+	time.Sleep(200 * time.Millisecond)
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	assert.Equal(t, 1, publisher.opened)
+}
+
+func TestEventStream_AtMostOnceDropsFailedEvents(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	prometheus := metrics.NewPrometheus("test")
+	publisher := &fakePublisher{failures: 1}
+	stream := services.NewEventStream(logger, publisher.open,
+		services.WithEventDelivery(queue.AtMostOnce),
+		services.WithEventMetrics(prometheus),
+	)
+	runEventStream(t, stream)
+
+	stream.Publish(models.WebhookEvent{Type: models.WebhookEventBreachFound})
+	stream.Publish(models.WebhookEvent{Type: models.WebhookEventDecoyMatched})
+	require.Eventually(t, func() bool { return len(publisher.events()) == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, "passwords.events", publisher.events()[0].topic)
+	assert.Equal(t, models.WebhookEventDecoyMatched, publisher.events()[0].event.Type)
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_events_published_total{event="breach-found",result="failed"} 1`)
+}
+
+// recordingPublisher records the events published to it
+type recordingPublisher struct {
+	events []models.WebhookEvent
+}
+
+func (p *recordingPublisher) Publish(event models.WebhookEvent) {
+	p.events = append(p.events, event)
+}
+
+func TestEventPublishers_ShareEventIDs(t *testing.T) {
+	webhooks, stream := &recordingPublisher{}, &recordingPublisher{}
+	services.EventPublishers{webhooks, stream}.Publish(models.WebhookEvent{Type: models.WebhookEventQuotaExceeded})
+
+	require.Len(t, webhooks.events, 1)
+	require.Len(t, stream.events, 1)
+	assert.NotEmpty(t, webhooks.events[0].ID)
+	assert.Equal(t, webhooks.events[0].ID, stream.events[0].ID)
+	assert.Equal(t, webhooks.events[0].OccurredAt, stream.events[0].OccurredAt)
+}
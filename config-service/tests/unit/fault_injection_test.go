@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+)
+
+func TestBreachService_FaultInjection(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:3\r\n")
+	}))
+	defer server.Close()
+
+	newService := func(recorder metrics.Recorder, faults services.FaultSettings, options ...services.BreachServiceOption) *services.BreachService {
+		return services.NewBreachService(logger, append([]services.BreachServiceOption{
+			services.WithAPIEndpoint(server.URL),
+			services.WithCacheDuration(0),
+			services.WithRetries(1, time.Millisecond),
+			services.WithMetrics(recorder),
+			services.WithFaultInjection(faults),
+		}, options...)...)
+	}
+
+	// Injected timeouts and rate limits are retried, then fail the lookup,
+	// without reaching the API
+	recorder := metrics.NewPrometheus("test")
+	_, err := newService(recorder, services.FaultSettings{Timeout: 1}).CheckPasswordBreach("password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	_, err = newService(recorder, services.FaultSettings{RateLimit: 1}).CheckPasswordBreach("password")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit")
+	assert.Zero(t, atomic.LoadInt64(&calls))
+
+	var out bytes.Buffer
+	_, err = recorder.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `test_hibp_faults_injected_total{fault="timeout"} 2`)
+	assert.Contains(t, out.String(), `test_hibp_faults_injected_total{fault="rate_limit"} 2`)
+	assert.Contains(t, out.String(), `test_hibp_retries_total 2`)
+
+	// Repeated faults open the circuit breaker
+	breaking := newService(metrics.Noop{}, services.FaultSettings{Timeout: 1}, services.WithCircuitBreaker(2, time.Minute))
+	for i := 0; i < 2; i++ {
+		_, err = breaking.CheckPasswordBreach("password")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, services.CircuitOpen, breaking.CircuitState())
+
+	// A malformed body is not mistaken for a breach
+	info, err := newService(metrics.Noop{}, services.FaultSettings{Malformed: 1}).CheckPasswordBreach("password")
+	require.NoError(t, err)
+	assert.False(t, info.Found)
+	assert.Zero(t, atomic.LoadInt64(&calls))
+
+	// Slow requests still reach the API
+	start := time.Now()
+	info, err = newService(metrics.Noop{}, services.FaultSettings{Slow: 1, SlowDelay: 30 * time.Millisecond}).CheckPasswordBreach("password")
+	require.NoError(t, err)
+	assert.True(t, info.Found)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// Without faults configured, requests pass through
+	info, err = newService(metrics.Noop{}, services.FaultSettings{}).CheckPasswordBreach("password")
+	require.NoError(t, err)
+	assert.True(t, info.Found)
+}
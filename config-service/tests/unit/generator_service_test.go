@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestGeneratorService_HonorsClasses(t *testing.T) {
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker())
+
+	for i := 0; i < 50; i++ {
+		generated, err := generator.Generate(models.GenerateRequest{Length: 8})
+		require.NoError(t, err)
+		password := generated.Password
+		assert.Len(t, password, 8)
+		assert.True(t, strings.IndexFunc(password, unicode.IsUpper) >= 0, password)
+		assert.True(t, strings.IndexFunc(password, unicode.IsLower) >= 0, password)
+		assert.True(t, strings.IndexFunc(password, unicode.IsDigit) >= 0, password)
+		assert.True(t, strings.ContainsAny(password, "!#$%&*+-=?@^_~"), password)
+		assert.False(t, strings.ContainsAny(password, "O0lI1"), password)
+	}
+
+	generated, err := generator.Generate(models.GenerateRequest{Length: 30, Classes: []string{models.CharacterClassDigits}})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[2-9]{30}$`, generated.Password)
+}
+
+func TestGeneratorService_Pronounceable(t *testing.T) {
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker())
+
+	for i := 0; i < 50; i++ {
+		generated, err := generator.Generate(models.GenerateRequest{Length: 14, Pronounceable: true})
+		require.NoError(t, err)
+		password := generated.Password
+		assert.Len(t, password, 14)
+		assert.Equal(t, 1, countFunc(password, unicode.IsUpper), password)
+		assert.Equal(t, 1, countFunc(password, unicode.IsDigit), password)
+		assert.Equal(t, 1, countFunc(password, func(r rune) bool { return strings.ContainsRune("!#$%&*+-=?@^_~", r) }), password)
+
+		// The letters alternate consonants and vowels
+		letters := strings.ToLower(strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return r
+			}
+			return -1
+		}, password))
+		for j, letter := range letters {
+			assert.Equal(t, j%2 == 1, strings.ContainsRune("aeiu", letter), password)
+		}
+	}
+
+	generated, err := generator.Generate(models.GenerateRequest{Length: 10, Pronounceable: true, Classes: []string{models.CharacterClassUppercase}})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Z]{10}$`, generated.Password)
+}
+
+func TestGeneratorService_RejectsUnsatisfiableOptions(t *testing.T) {
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker(), services.WithGeneratedMaxLength(32))
+
+	_, err := generator.Generate(models.GenerateRequest{Length: 33})
+	assert.EqualError(t, err, "validation failed: length: length must be at most 32")
+
+	_, err = generator.Generate(models.GenerateRequest{Length: 8, Pronounceable: true, Classes: []string{models.CharacterClassSymbols}})
+	assert.EqualError(t, err, "validation failed: classes: pronounceable passwords need uppercase or lowercase letters")
+}
+
+func TestGeneratorService_Passphrase(t *testing.T) {
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker())
+
+	generated, err := generator.GeneratePassphrase(models.PassphraseRequest{})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[a-z-]+$`, generated.Passphrase)
+	assert.Equal(t, 77.5, generated.Entropy)
+
+	space := " "
+	for i := 0; i < 20; i++ {
+		generated, err = generator.GeneratePassphrase(models.PassphraseRequest{Words: 5, Separator: &space, Capitalization: models.CapitalizationTitle})
+		require.NoError(t, err)
+		words := strings.Split(generated.Passphrase, " ")
+		require.Len(t, words, 5, generated.Passphrase)
+		for _, word := range words {
+			assert.Regexp(t, `^[A-Z][a-z-]{2,8}$`, word)
+		}
+	}
+
+	generated, err = generator.GeneratePassphrase(models.PassphraseRequest{Words: 4, Capitalization: models.CapitalizationUpper})
+	require.NoError(t, err)
+	assert.Regexp(t, `^[A-Z-]+$`, generated.Passphrase)
+
+	// Random capitals add a bit per word
+	generated, err = generator.GeneratePassphrase(models.PassphraseRequest{Words: 4, Capitalization: models.CapitalizationRandom})
+	require.NoError(t, err)
+	assert.Equal(t, 55.7, generated.Entropy)
+}
+
+func TestGeneratorService_RejectsUnsatisfiablePassphrases(t *testing.T) {
+	generator := services.NewGeneratorService(services.NewPasswordStrengthChecker(), services.WithGeneratedMaxLength(40))
+
+	_, err := generator.GeneratePassphrase(models.PassphraseRequest{Words: 5})
+	assert.EqualError(t, err, "validation failed: words: 5 words with this separator can be longer than 40 characters")
+
+	separator := "x"
+	_, err = generator.GeneratePassphrase(models.PassphraseRequest{Words: 4, Separator: &separator})
+	assert.EqualError(t, err, "validation failed: separator: separator must not contain letters")
+}
+
+func countFunc(s string, f func(rune) bool) int {
+	count := 0
+	for _, r := range s {
+		if f(r) {
+			count++
+		}
+	}
+	return count
+}
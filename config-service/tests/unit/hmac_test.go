@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/auth"
+	"config-service/internal/metrics"
+)
+
+// signedAudit signs a breach audit request with the key "partner", and with
+// a nonce when one is given
+func signedAudit(secret, body, nonce string) auth.SignedRequest {
+	const uri = "/api/v1/password/breach-audit"
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	return auth.SignedRequest{
+		Method:    "POST",
+		URI:       uri,
+		Body:      []byte(body),
+		KeyID:     "partner",
+		Timestamp: ts,
+		Nonce:     nonce,
+		Signature: auth.SignWithNonce([]byte(secret), "POST", uri, ts, nonce, []byte(body)),
+	}
+}
+
+func TestHMACVerifier_Nonces(t *testing.T) {
+	verifier := auth.NewHMACVerifier([]auth.HMACKey{{ID: "partner", Secret: []byte("secret")}})
+
+	// Identical requests in the same second are told apart by their nonces
+	_, err := verifier.Verify(signedAudit("secret", "{}", "nonce-1"))
+	assert.NoError(t, err)
+	_, err = verifier.Verify(signedAudit("secret", "{}", "nonce-2"))
+	assert.NoError(t, err)
+
+	// A nonce is used once, whatever it signs
+	_, err = verifier.Verify(signedAudit("secret", `{"hashes":[]}`, "nonce-1"))
+	assert.ErrorIs(t, err, auth.ErrSignatureReplayed)
+
+	// The nonce is signed, so it cannot be swapped for a fresh one
+	request := signedAudit("secret", "{}", "nonce-3")
+	request.Nonce = "nonce-4"
+	_, err = verifier.Verify(request)
+	assert.ErrorIs(t, err, auth.ErrSignatureInvalid)
+
+	_, err = verifier.Verify(signedAudit("secret", "{}", "line\nbreak"))
+	assert.ErrorIs(t, err, auth.ErrSignatureInvalid)
+
+	required := auth.NewHMACVerifier([]auth.HMACKey{{ID: "partner", Secret: []byte("secret")}}, auth.WithRequiredNonce())
+	_, err = required.Verify(signedAudit("secret", "{}", ""))
+	assert.ErrorIs(t, err, auth.ErrSignatureNonceRequired)
+	_, err = required.Verify(signedAudit("secret", "{}", "nonce-1"))
+	assert.NoError(t, err)
+}
+
+func TestHMACVerifier_BoundsReplayCache(t *testing.T) {
+	prometheus := metrics.NewPrometheus("test")
+	verifier := auth.NewHMACVerifier([]auth.HMACKey{{ID: "partner", Secret: []byte("secret")}},
+		auth.WithReplayCacheSize(2),
+		auth.WithSignatureMetrics(prometheus),
+	)
+
+	for _, nonce := range []string{"a", "b"} {
+		_, err := verifier.Verify(signedAudit("secret", "{}", nonce))
+		assert.NoError(t, err)
+	}
+	_, err := verifier.Verify(signedAudit("secret", "{}", "a"))
+	assert.ErrorIs(t, err, auth.ErrSignatureReplayed)
+
+	// Forgetting a signature still within the window would let it be
+	// replayed, so new ones are rejected until the oldest expire
+	_, err = verifier.Verify(signedAudit("secret", "{}", "c"))
+	assert.ErrorIs(t, err, auth.ErrReplayCacheFull)
+	_, err = verifier.Verify(signedAudit("wrong", "{}", "d"))
+	assert.ErrorIs(t, err, auth.ErrSignatureInvalid)
+
+	var out bytes.Buffer
+	prometheus.WriteTo(&out)
+	assert.Contains(t, out.String(), `test_signed_request_rejections_total{reason="replayed"} 1`)
+	assert.Contains(t, out.String(), `test_signed_request_rejections_total{reason="replay_cache_full"} 1`)
+	assert.Contains(t, out.String(), `test_signed_request_rejections_total{reason="invalid"} 1`)
+	assert.Contains(t, out.String(), `test_signature_replay_cache_entries 2`)
+}
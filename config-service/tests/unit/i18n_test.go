@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/i18n"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "empty header", acceptLanguage: "", want: "en"},
+		{name: "exact match", acceptLanguage: "fr", want: "fr"},
+		{name: "regional tag falls back to base", acceptLanguage: "es-MX", want: "es"},
+		{name: "quality ordering", acceptLanguage: "fr;q=0.5, de;q=0.9", want: "de"},
+		{name: "unsupported languages skipped", acceptLanguage: "ja, zh;q=0.8, de;q=0.1", want: "de"},
+		{name: "nothing supported", acceptLanguage: "ja, zh", want: "en"},
+		{name: "zero quality excluded", acceptLanguage: "fr;q=0, es;q=0.2", want: "es"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, i18n.Negotiate(tt.acceptLanguage, i18n.DefaultLocale))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Añada números", i18n.Translate("es", "Add numbers"))
+	assert.Equal(t, "Add numbers", i18n.Translate("en", "Add numbers"))
+	assert.Equal(t, "Untranslated message", i18n.Translate("fr", "Untranslated message"))
+}
+
+func TestTranslate_Numbers(t *testing.T) {
+	assert.Equal(t, "la contraseña no debe superar los 256 caracteres",
+		i18n.Translate("es", "password must not exceed 256 characters"))
+	assert.True(t, i18n.HasTranslation("de", "password must be at least 12 characters long"))
+	assert.Equal(t, "Utilice una contraseña más larga (12 o más caracteres)",
+		i18n.Translate("es", "Use a longer password (12+ characters)"))
+	assert.False(t, i18n.HasTranslation("fr", "Untranslated 42"))
+}
@@ -0,0 +1,70 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/services"
+)
+
+func TestIdempotencyStore_ReplaysCompletedRequests(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store := services.NewIdempotencyStore(
+		services.WithIdempotencyTTL(time.Hour),
+		services.WithIdempotencyClock(func() time.Time { return now }),
+	)
+
+	state, _ := store.Begin("key-1", "POST /webhooks a")
+	assert.Equal(t, services.IdempotencyNew, state)
+
+	// A retry while the first request is processing is neither applied nor replayed
+	state, _ = store.Begin("key-1", "POST /webhooks a")
+	assert.Equal(t, services.IdempotencyInProgress, state)
+
+	store.Complete("key-1", &services.IdempotentResponse{Status: 201, Body: []byte(`{"id":"wh_1"}`)})
+	state, response := store.Begin("key-1", "POST /webhooks a")
+	assert.Equal(t, services.IdempotencyReplay, state)
+	assert.Equal(t, 201, response.Status)
+	assert.Equal(t, `{"id":"wh_1"}`, string(response.Body))
+
+	// The key cannot be reused for another request
+	state, _ = store.Begin("key-1", "POST /webhooks b")
+	assert.Equal(t, services.IdempotencyMismatch, state)
+
+	// Until it expires
+	now = now.Add(time.Hour)
+	state, _ = store.Begin("key-1", "POST /webhooks b")
+	assert.Equal(t, services.IdempotencyNew, state)
+}
+
+func TestIdempotencyStore_AbandonedRequestsCanBeRetried(t *testing.T) {
+	store := services.NewIdempotencyStore()
+
+	state, _ := store.Begin("key-1", "DELETE /webhooks/wh_1")
+	assert.Equal(t, services.IdempotencyNew, state)
+	store.Abandon("key-1")
+
+	state, _ = store.Begin("key-1", "DELETE /webhooks/wh_1")
+	assert.Equal(t, services.IdempotencyNew, state)
+}
+
+func TestIdempotencyStore_DropsOldestEntriesWhenFull(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store := services.NewIdempotencyStore(
+		services.WithIdempotencyMaxEntries(2),
+		services.WithIdempotencyClock(func() time.Time { return now }),
+	)
+
+	for _, key := range []string{"a", "b", "c"} {
+		store.Begin(key, "request")
+		store.Complete(key, &services.IdempotentResponse{Status: 204})
+		now = now.Add(time.Second)
+	}
+
+	state, _ := store.Begin("a", "request")
+	assert.Equal(t, services.IdempotencyNew, state, "the oldest key was dropped")
+	state, _ = store.Begin("c", "request")
+	assert.Equal(t, services.IdempotencyReplay, state)
+}
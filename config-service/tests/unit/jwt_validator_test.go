@@ -0,0 +1,189 @@
+package services_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/auth"
+	"config-service/internal/models"
+)
+
+// signTestJWT creates an RS256-signed token with the given claims
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTValidator_Validate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var issuer string
+	mockIdP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   issuer,
+				"jwks_uri": issuer + "/keys",
+			})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"keys": []map[string]string{{
+					"kty": "RSA",
+					"kid": "test-key",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockIdP.Close()
+	issuer = mockIdP.URL
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	validator := auth.NewJWTValidator(
+		logger,
+		issuer,
+		"password-api",
+		auth.WithClockSkew(30*time.Second),
+		auth.WithScopeMapping(map[string]string{models.ScopeCheck: "", models.ScopeAdmin: "password:admin"}),
+	)
+
+	now := time.Now()
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":    issuer,
+			"sub":    "service-a",
+			"aud":    []string{"password-api", "other"},
+			"exp":    now.Add(time.Hour).Unix(),
+			"iat":    now.Unix(),
+			"tenant": "acme",
+			"scope":  "openid password:admin",
+		}
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		claims, err := validator.Validate(signTestJWT(t, key, "test-key", validClaims()))
+		require.NoError(t, err)
+
+		assert.Equal(t, "service-a", claims.Subject)
+		assert.Equal(t, "acme", claims.Tenant)
+		assert.True(t, validator.Grants(claims, models.ScopeCheck))
+		assert.True(t, validator.Grants(claims, models.ScopeAdmin))
+	})
+
+	t.Run("expired within clock skew", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = now.Add(-10 * time.Second).Unix()
+		_, err := validator.Validate(signTestJWT(t, key, "test-key", claims))
+		assert.NoError(t, err)
+	})
+
+	invalid := map[string]func(map[string]interface{}){
+		"expired":        func(c map[string]interface{}) { c["exp"] = now.Add(-time.Hour).Unix() },
+		"wrong issuer":   func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" },
+		"wrong audience": func(c map[string]interface{}) { c["aud"] = "another-api" },
+		"not yet valid":  func(c map[string]interface{}) { c["nbf"] = now.Add(time.Hour).Unix() },
+		"missing expiry": func(c map[string]interface{}) { delete(c, "exp") },
+	}
+	for name, mutate := range invalid {
+		t.Run(name, func(t *testing.T) {
+			claims := validClaims()
+			mutate(claims)
+			_, err := validator.Validate(signTestJWT(t, key, "test-key", claims))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("tampered signature", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = validator.Validate(signTestJWT(t, otherKey, "test-key", validClaims()))
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown key id", func(t *testing.T) {
+		_, err := validator.Validate(signTestJWT(t, key, "rotated-away", validClaims()))
+		assert.Error(t, err)
+	})
+}
+
+func TestJWTValidator_MatchesECAlgorithmsToCurves(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	mockIdP := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "EC",
+				"kid": "ec-key",
+				"crv": "P-384",
+				"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 48))),
+				"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 48))),
+			}},
+		})
+	}))
+	defer mockIdP.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	validator := auth.NewJWTValidator(logger, "https://id.example.com", "password-api", auth.WithJWKSURL(mockIdP.URL))
+
+	// sign signs a token with the P-384 key, hashing as the algorithm says
+	sign := func(alg string, newHash func() hash.Hash) string {
+		header, err := json.Marshal(map[string]string{"alg": alg, "kid": "ec-key", "typ": "JWT"})
+		require.NoError(t, err)
+		payload, err := json.Marshal(map[string]interface{}{
+			"iss": "https://id.example.com",
+			"aud": "password-api",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+
+		signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		hasher := newHash()
+		hasher.Write([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, key, hasher.Sum(nil))
+		require.NoError(t, err)
+		signature := append(r.FillBytes(make([]byte, 48)), s.FillBytes(make([]byte, 48))...)
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	_, err = validator.Validate(sign("ES384", sha512.New384))
+	assert.NoError(t, err)
+
+	// The signature is valid, but ES256 is only defined for P-256 keys
+	_, err = validator.Validate(sign("ES256", sha256.New))
+	assert.ErrorContains(t, err, "does not match the P-384 curve")
+}
@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/lambda"
+)
+
+// echoHandler reports what it received and sets a cookie
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"method":     r.Method,
+		"path":       r.URL.Path,
+		"query":      r.URL.RawQuery,
+		"body":       string(body),
+		"remote":     r.RemoteAddr,
+		"request_id": r.Header.Get("X-Request-ID"),
+		"cookie":     r.Header.Get("Cookie"),
+	})
+})
+
+func serveEvent(t *testing.T, handler http.Handler, event string) (map[string]interface{}, map[string]string) {
+	t.Helper()
+	output, err := lambda.Serve(context.Background(), handler, []byte(event))
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(output, &response))
+	var echoed map[string]string
+	require.NoError(t, json.Unmarshal([]byte(response["body"].(string)), &echoed))
+	return response, echoed
+}
+
+func TestLambdaServe_RESTPayload(t *testing.T) {
+	response, echoed := serveEvent(t, echoHandler, `{
+		"httpMethod": "POST",
+		"path": "/api/v1/password/check",
+		"multiValueHeaders": {"Content-Type": ["application/json"]},
+		"multiValueQueryStringParameters": {"lang": ["de"]},
+		"requestContext": {"requestId": "c6af9ac6-7b61-11e6-9a41-93e8deadbeef", "identity": {"sourceIp": "203.0.113.7"}},
+		"body": "eyJwYXNzd29yZCI6IngifQ==",
+		"isBase64Encoded": true
+	}`)
+
+	assert.Equal(t, float64(http.StatusCreated), response["statusCode"])
+	assert.Contains(t, response["multiValueHeaders"], "Set-Cookie")
+	assert.Equal(t, "POST", echoed["method"])
+	assert.Equal(t, "/api/v1/password/check", echoed["path"])
+	assert.Equal(t, "lang=de", echoed["query"])
+	assert.Equal(t, `{"password":"x"}`, echoed["body"])
+	assert.Equal(t, "203.0.113.7:0", echoed["remote"])
+	assert.Equal(t, "c6af9ac6-7b61-11e6-9a41-93e8deadbeef", echoed["request_id"])
+}
+
+func TestLambdaServe_HTTPAPIPayload(t *testing.T) {
+	response, echoed := serveEvent(t, echoHandler, `{
+		"version": "2.0",
+		"rawPath": "/prod/api/v1/version",
+		"rawQueryString": "a=1&a=2",
+		"cookies": ["theme=dark"],
+		"headers": {"x-request-id": "req-1"},
+		"requestContext": {"stage": "prod", "http": {"method": "GET", "sourceIp": "198.51.100.4"}},
+		"isBase64Encoded": false
+	}`)
+
+	assert.Equal(t, float64(http.StatusCreated), response["statusCode"])
+	assert.Equal(t, []interface{}{"session=abc"}, response["cookies"])
+	assert.Equal(t, "application/json", response["headers"].(map[string]interface{})["Content-Type"])
+	assert.Equal(t, "/api/v1/version", echoed["path"])
+	assert.Equal(t, "a=1&a=2", echoed["query"])
+	assert.Equal(t, "theme=dark", echoed["cookie"])
+	assert.Equal(t, "req-1", echoed["request_id"])
+}
+
+func TestLambdaServe_BinaryResponse(t *testing.T) {
+	binary := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0xfe, 0x00})
+	})
+	output, err := lambda.Serve(context.Background(), binary, []byte(`{"httpMethod":"GET","path":"/"}`))
+	require.NoError(t, err)
+
+	var response struct {
+		StatusCode      int    `json:"statusCode"`
+		Body            string `json:"body"`
+		IsBase64Encoded bool   `json:"isBase64Encoded"`
+	}
+	require.NoError(t, json.Unmarshal(output, &response))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, response.IsBase64Encoded)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0x00}), response.Body)
+}
+
+func TestLambdaServe_InvalidEvent(t *testing.T) {
+	_, err := lambda.Serve(context.Background(), echoHandler, []byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/logging"
+)
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "service.log")
+
+	file, err := logging.NewRotatingFile(path, logging.WithMaxSize(20))
+	require.NoError(t, err)
+
+	for _, line := range []string{"first line\n", "second line\n", "third line\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "third line\n", string(current))
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "logs", "service-*.log"))
+	assert.Len(t, backups, 2)
+}
+
+func TestRotatingFile_CompressesAndPrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "service.log")
+
+	file, err := logging.NewRotatingFile(path,
+		logging.WithCompression(true),
+		logging.WithMaxBackups(2))
+	require.NoError(t, err)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+		require.NoError(t, file.Rotate())
+	}
+	require.NoError(t, file.Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var backups []string
+	for _, entry := range entries {
+		if entry.Name() != "service.log" {
+			backups = append(backups, entry.Name())
+		}
+	}
+	require.Len(t, backups, 2)
+
+	// The newest backup holds the last line written before rotation
+	newest := backups[len(backups)-1]
+	require.True(t, strings.HasSuffix(newest, ".log.gz"), newest)
+
+	compressed, err := os.Open(filepath.Join(dir, newest))
+	require.NoError(t, err)
+	defer compressed.Close()
+	reader, err := gzip.NewReader(compressed)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "four\n", string(content))
+}
+
+func TestRotatingFile_WriteAfterClose(t *testing.T) {
+	file, err := logging.NewRotatingFile(filepath.Join(t.TempDir(), "service.log"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, err = file.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/logging"
+)
+
+func logEntry(t *testing.T, log func(logger *logrus.Logger)) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := logging.New(&buf)
+	logger.SetLevel(logrus.DebugLevel)
+	log(logger)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	return entry
+}
+
+func TestScrubHookRedactsUnknownFields(t *testing.T) {
+	entry := logEntry(t, func(logger *logrus.Logger) {
+		logger.WithFields(logrus.Fields{
+			"password": "hunter2",
+			"method":   "POST",
+			"status":   200,
+		}).Info("request")
+	})
+
+	assert.Equal(t, logging.Redacted, entry["password"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, float64(200), entry["status"])
+}
+
+func TestScrubHookRedactsSensitiveValues(t *testing.T) {
+	hash := "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"
+	entry := logEntry(t, func(logger *logrus.Logger) {
+		logger.WithFields(logrus.Fields{
+			"path":          "/users/alice@example.com",
+			logrus.ErrorKey: errors.New("GET https://api.pwnedpasswords.com/range/5BAA6 failed"),
+		}).Warnf("lookup of %s for csk_abcdef123 failed", hash)
+	})
+
+	message := entry["msg"].(string)
+	assert.NotContains(t, message, hash)
+	assert.NotContains(t, message, "csk_abcdef123")
+	assert.NotContains(t, entry["path"], "alice@example.com")
+	assert.NotContains(t, entry["error"], "5BAA6")
+}
+
+func TestIsSensitivePath(t *testing.T) {
+	assert.True(t, logging.IsSensitivePath("/api/v1/password/check"))
+	assert.True(t, logging.IsSensitivePath("/api/v1/admin/api-keys"))
+	assert.True(t, logging.IsSensitivePath("/api/v1/admission/secrets"))
+	assert.True(t, logging.IsSensitivePath("/passwords/api/v1/password/check"))
+	assert.False(t, logging.IsSensitivePath("/api/v1/admin/banned-words"))
+	assert.False(t, logging.IsSensitivePath("/api/v1/health"))
+}
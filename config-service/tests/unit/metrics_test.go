@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/metrics"
+)
+
+func TestPrometheusExposition(t *testing.T) {
+	prometheus := metrics.NewPrometheus("config_service")
+	tags := metrics.Tags{"route": "/api/v1/password/check", "status": "200"}
+
+	prometheus.Count("http_requests", 1, tags)
+	prometheus.Count("http_requests", 2, tags)
+	prometheus.Timing("http_request_duration", 30*time.Millisecond, tags)
+	prometheus.Gauge("cache_entries", 7, nil)
+
+	var out strings.Builder
+	_, err := prometheus.WriteTo(&out)
+	require.NoError(t, err)
+	text := out.String()
+
+	assert.Contains(t, text, "# TYPE config_service_http_requests_total counter\n")
+	assert.Contains(t, text, `config_service_http_requests_total{route="/api/v1/password/check",status="200"} 3`)
+	assert.Contains(t, text, "# TYPE config_service_http_request_duration_seconds histogram\n")
+	assert.Contains(t, text, `config_service_http_request_duration_seconds_bucket{route="/api/v1/password/check",status="200",le="0.025"} 0`)
+	assert.Contains(t, text, `config_service_http_request_duration_seconds_bucket{route="/api/v1/password/check",status="200",le="0.05"} 1`)
+	assert.Contains(t, text, `config_service_http_request_duration_seconds_count{route="/api/v1/password/check",status="200"} 1`)
+	assert.Contains(t, text, "config_service_cache_entries 7\n")
+}
+
+func TestStatsDEmitter(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer agent.Close()
+
+	statsd, err := metrics.NewStatsD(agent.LocalAddr().String(), "config_service", true)
+	require.NoError(t, err)
+	defer statsd.Close()
+
+	statsd.Count("http_requests", 1, metrics.Tags{"status": "200", "route": "/api/v1/health"})
+	statsd.Timing("http_request_duration", 1500*time.Microsecond, nil)
+	statsd.Gauge("cache_entries", 7, nil)
+
+	var received []string
+	buf := make([]byte, 1024)
+	agent.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(received) < 3 {
+		n, _, err := agent.ReadFrom(buf)
+		require.NoError(t, err)
+		received = append(received, string(buf[:n]))
+	}
+	sort.Strings(received)
+
+	assert.Equal(t, []string{
+		"config_service.cache_entries:7|g",
+		"config_service.http_request_duration:1.500|ms",
+		"config_service.http_requests:1|c|#route:/api/v1/health,status:200",
+	}, received)
+}
@@ -0,0 +1,180 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "config-service/internal/errors"
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+// policyRules returns the rules of the failed checks of a policy result
+func policyRules(result *models.PolicyResult) []models.PolicyRule {
+	var rules []models.PolicyRule
+	for _, check := range result.Failed() {
+		rules = append(rules, check.Rule)
+	}
+	return rules
+}
+
+func TestPolicyService_EvaluatesNamedPolicies(t *testing.T) {
+	const breached = "Tr0ub4dor&3-correct-horse-battery"
+	digest := sha1.Sum([]byte(breached))
+	hash := strings.ToUpper(hex.EncodeToString(digest[:]))
+	hibp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(strings.ToUpper(r.URL.Path), hash[:5]) {
+			w.Write([]byte(hash[5:] + ":3\r\n"))
+		}
+	}))
+	defer hibp.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	never, few := 0, 5
+	policies := services.NewPolicyService(logger,
+		services.WithPolicies([]models.PasswordPolicy{
+			{ID: "customers", MinLength: 10, RequireNumbers: true, BannedWords: []string{" Acme "}, MaxBreachCount: &never, MinEntropyBits: 60},
+			{ID: "lenient", MaxBreachCount: &few},
+		}),
+		services.WithPolicyBreaches(services.NewBreachService(logger, services.WithEnabled(true), services.WithAPIEndpoint(hibp.URL))),
+	)
+	ctx := context.Background()
+
+	policy, err := policies.Get("customers")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme"}, policy.BannedWords)
+	assert.Equal(t, 128, policy.MaxLength)
+	assert.Equal(t, models.PolicySourceConfig, policy.Source)
+
+	result, err := policies.Evaluate(ctx, "customers", "Summer2024!")
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []models.PolicyRule{models.RuleMinEntropy}, policyRules(result))
+	assert.Equal(t, 35.3, result.EntropyBits)
+
+	result, err = policies.Evaluate(ctx, "customers", "Acme-Summer-2024!")
+	require.NoError(t, err)
+	assert.Equal(t, []models.PolicyRule{models.RuleBannedWord}, policyRules(result))
+
+	// Each policy allows its own number of breaches
+	result, err = policies.Evaluate(ctx, "customers", breached)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PolicyRule{models.RuleNotBreached}, policyRules(result))
+	result, err = policies.Evaluate(ctx, "lenient", breached)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	result, err = policies.Evaluate(ctx, "customers", "Xk9#qL2$vP7!mR4z")
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, "customers", result.PolicyID)
+
+	_, err = policies.Evaluate(ctx, "unknown", "Xk9#qL2$vP7!mR4z")
+	assert.ErrorIs(t, err, services.ErrPolicyNotFound)
+}
+
+func TestPolicyService_PutAndDelete(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	policies := services.NewPolicyService(logger,
+		services.WithDefaultPolicyMaxLength(64),
+		services.WithPolicies([]models.PasswordPolicy{{ID: "staff"}}),
+	)
+	var versions []uint64
+	policies.OnChange(func(version uint64) { versions = append(versions, version) })
+
+	_, err := policies.Put(models.PasswordPolicy{ID: "bad id", MinLength: 4, MaxLength: 100})
+	var validationErrors *apperrors.ValidationErrors
+	require.True(t, errors.As(err, &validationErrors))
+	fields := make([]string, len(validationErrors.Errors))
+	for i, problem := range validationErrors.Errors {
+		fields[i] = problem.Field
+	}
+	assert.Equal(t, []string{"id", "min_length", "max_length"}, fields)
+
+	// The built-in and configured policies are read-only
+	_, err = policies.Put(models.PasswordPolicy{ID: models.DefaultPolicyID})
+	assert.ErrorIs(t, err, services.ErrPolicyReadOnly)
+	_, err = policies.Put(models.PasswordPolicy{ID: "staff"})
+	assert.ErrorIs(t, err, services.ErrPolicyReadOnly)
+	assert.ErrorIs(t, policies.Delete("staff", "admin_token"), services.ErrPolicyReadOnly)
+	assert.Zero(t, policies.Version())
+
+	saved, err := policies.Put(models.PasswordPolicy{ID: "contractors", BannedWords: []string{"Acme", "acme"}})
+	require.NoError(t, err)
+	assert.Equal(t, models.PolicySourceAPI, saved.Source)
+	assert.Equal(t, "contractors", saved.Name)
+	assert.Equal(t, 8, saved.MinLength)
+	assert.Equal(t, 64, saved.MaxLength)
+	assert.Equal(t, []string{"acme"}, saved.BannedWords)
+	assert.Len(t, policies.List(), 3)
+
+	require.NoError(t, policies.Delete("contractors", "admin_token"))
+	_, err = policies.Get("contractors")
+	assert.ErrorIs(t, err, services.ErrPolicyNotFound)
+	assert.ErrorIs(t, policies.Delete("contractors", "admin_token"), services.ErrPolicyNotFound)
+
+	// Only changes that took effect count
+	assert.Equal(t, uint64(2), policies.Version())
+	assert.Equal(t, []uint64{1, 2}, versions)
+}
+
+func TestPolicyService_RestoresDeletedPolicies(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	policies := services.NewPolicyService(logger)
+
+	_, err := policies.Put(models.PasswordPolicy{ID: "contractors", MinLength: 14})
+	require.NoError(t, err)
+	require.NoError(t, policies.Delete("contractors", "key_1"))
+
+	deleted := policies.Deleted()
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "contractors", deleted[0].ID)
+	assert.Equal(t, "key_1", deleted[0].DeletedBy)
+	assert.True(t, deleted[0].PurgeAt.After(deleted[0].DeletedAt))
+
+	restored, err := policies.Restore("contractors")
+	require.NoError(t, err)
+	assert.Equal(t, 14, restored.MinLength)
+	_, err = policies.Get("contractors")
+	assert.NoError(t, err)
+	assert.Empty(t, policies.Deleted())
+	assert.Equal(t, uint64(3), policies.Version())
+
+	_, err = policies.Restore("contractors")
+	assert.ErrorIs(t, err, services.ErrPolicyNotDeleted)
+
+	// Defining the policy again clears it from the deleted list
+	require.NoError(t, policies.Delete("contractors", "key_1"))
+	_, err = policies.Put(models.PasswordPolicy{ID: "contractors"})
+	require.NoError(t, err)
+	assert.Empty(t, policies.Deleted())
+}
+
+func TestPolicyService_DeletedPoliciesArePurgedAfterRetention(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	policies := services.NewPolicyService(logger, services.WithDeletedPolicyRetention(time.Millisecond))
+
+	_, err := policies.Put(models.PasswordPolicy{ID: "contractors"})
+	require.NoError(t, err)
+	require.NoError(t, policies.Delete("contractors", "admin_token"))
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Empty(t, policies.Deleted())
+	_, err = policies.Restore("contractors")
+	assert.ErrorIs(t, err, services.ErrPolicyNotDeleted)
+}
@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+	"config-service/internal/reproducible"
+	"config-service/internal/requestid"
+)
+
+func TestReproducible_DeterministicMode(t *testing.T) {
+	t.Cleanup(reproducible.UseSystem)
+
+	// The same seed yields the same request IDs, clock readings, and samples
+	run := func() ([]string, []float64) {
+		reproducible.UseDeterministic(42)
+		var ids []string
+		var values []float64
+		for i := 0; i < 3; i++ {
+			ids = append(ids, requestid.Generate())
+			values = append(values, reproducible.Float64())
+		}
+		return ids, values
+	}
+	ids, values := run()
+	replayedIDs, replayedValues := run()
+	assert.Equal(t, ids, replayedIDs)
+	assert.Equal(t, values, replayedValues)
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, ids[0])
+
+	reproducible.UseDeterministic(42)
+	assert.Equal(t, reproducible.Epoch, reproducible.Now())
+	assert.Equal(t, reproducible.Epoch.Add(reproducible.Step), reproducible.Now())
+
+	reproducible.UseSystem()
+	assert.False(t, reproducible.Deterministic())
+	assert.NotEqual(t, requestid.Generate(), requestid.Generate())
+}
+
+func TestTuneRuntime_EnablesDeterministicMode(t *testing.T) {
+	t.Cleanup(reproducible.UseSystem)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	cfg := &config.Config{}
+	cfg.Runtime.Deterministic = true
+	cfg.Runtime.Seed = 7
+	settings, err := app.TuneRuntime(logger, cfg)
+	require.NoError(t, err)
+	assert.True(t, settings.Deterministic)
+	first := requestid.Generate()
+
+	reproducible.UseDeterministic(7)
+	assert.Equal(t, first, requestid.Generate())
+}
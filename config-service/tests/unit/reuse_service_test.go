@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/services"
+	"config-service/pkg/reuse"
+)
+
+func TestReuseService_FlagsSharedPasswordsWithinATenant(t *testing.T) {
+	service := services.NewReuseService()
+	acmeKey, globexKey := []byte("acme tenant key"), []byte("globex tenant key")
+
+	result, err := service.Record("acme", "alice", reuse.Digest(acmeKey, "Summer2024!Summer"))
+	require.NoError(t, err)
+	assert.False(t, result.Reused)
+	assert.Equal(t, 1, result.Accounts)
+
+	// Recording the same password again for the same account is not reuse
+	result, err = service.Record("acme", "alice", reuse.Digest(acmeKey, "Summer2024!Summer"))
+	require.NoError(t, err)
+	assert.False(t, result.Reused)
+
+	result, err = service.Record("acme", "bob", strings.ToUpper(reuse.Digest(acmeKey, "Summer2024!Summer")))
+	require.NoError(t, err)
+	assert.True(t, result.Reused)
+	assert.Equal(t, 2, result.Accounts)
+
+	// Other tenants have their own keys and accounts
+	result, err = service.Record("globex", "carol", reuse.Digest(globexKey, "Summer2024!Summer"))
+	require.NoError(t, err)
+	assert.False(t, result.Reused)
+	result, err = service.Record("globex", "dave", reuse.Digest(acmeKey, "Summer2024!Summer"))
+	require.NoError(t, err)
+	assert.False(t, result.Reused)
+
+	// Changing the password releases the old one
+	_, err = service.Record("acme", "alice", reuse.Digest(acmeKey, "a new passphrase for alice"))
+	require.NoError(t, err)
+	result, err = service.Record("acme", "bob", reuse.Digest(acmeKey, "Summer2024!Summer"))
+	require.NoError(t, err)
+	assert.False(t, result.Reused)
+
+	assert.True(t, service.Forget("acme", "bob"))
+	assert.False(t, service.Forget("acme", "bob"))
+	assert.False(t, service.Forget("initech", "bob"))
+
+	_, err = service.Record("acme", "erin", "not a digest")
+	assert.Error(t, err)
+}
+
+func TestReuseService_LimitsTrackedAccounts(t *testing.T) {
+	service := services.NewReuseService(services.WithReuseMaxAccounts(2))
+	key := []byte("acme tenant key")
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Record("acme", fmt.Sprint("user", i), reuse.Digest(key, fmt.Sprint("password", i)))
+		require.NoError(t, err)
+	}
+	_, err := service.Record("acme", "user2", reuse.Digest(key, "password2"))
+	assert.ErrorIs(t, err, services.ErrReuseCapacity)
+
+	// Known accounts may still change their passwords, and other tenants are unaffected
+	_, err = service.Record("acme", "user0", reuse.Digest(key, "password9"))
+	assert.NoError(t, err)
+	_, err = service.Record("globex", "user2", reuse.Digest(key, "password2"))
+	assert.NoError(t, err)
+}
+
+func TestReuseDigest_NormalizesPasswords(t *testing.T) {
+	key := []byte("acme tenant key")
+
+	// "é" precomposed and as "e" with a combining acute accent
+	assert.Equal(t, reuse.Digest(key, "café-au-lait-2024"), reuse.Digest(key, "café-au-lait-2024"))
+	assert.NotEqual(t, reuse.Digest(key, "café-au-lait-2024"), reuse.Digest([]byte("another key"), "café-au-lait-2024"))
+	assert.Len(t, reuse.Digest(key, "x"), 64)
+}
@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"io"
+	"math"
+	"runtime/debug"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/app"
+	"config-service/internal/config"
+	"config-service/internal/dataset"
+)
+
+func TestTuneRuntime(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	previousLimit := debug.SetMemoryLimit(-1)
+	previousPercent := debug.SetGCPercent(100)
+	t.Cleanup(func() {
+		debug.SetMemoryLimit(previousLimit)
+		debug.SetGCPercent(previousPercent)
+		dataset.SetMaxOpen(0)
+	})
+
+	// Without a memory limit, turning the collector off is refused
+	debug.SetMemoryLimit(math.MaxInt64)
+	cfg := &config.Config{}
+	cfg.Runtime.GCPercent = -1
+	_, err := app.TuneRuntime(logger, cfg)
+	assert.Error(t, err)
+	assert.Equal(t, 100, debug.SetGCPercent(100))
+
+	cfg.Runtime.MemoryLimitMB = 512
+	cfg.Runtime.MaxMappedDatasets = 3
+	settings, err := app.TuneRuntime(logger, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, app.RuntimeSettings{MemoryLimit: 512 << 20, GCPercent: -1, MaxMappedDatasets: 3}, settings)
+	assert.Equal(t, int64(512<<20), debug.SetMemoryLimit(-1))
+
+	// Unset values report what the runtime already uses
+	settings, err = app.TuneRuntime(logger, &config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, app.RuntimeSettings{MemoryLimit: 512 << 20, GCPercent: -1}, settings)
+}
@@ -0,0 +1,259 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/config"
+	"config-service/internal/metrics"
+	"config-service/internal/scheduler"
+	"config-service/internal/services"
+)
+
+func TestSchedule_Cron(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		require.NoError(t, err)
+		return parsed
+	}
+	tests := []struct {
+		spec, from, next string
+	}{
+		{"*/15 * * * *", "2024-03-01 10:07", "2024-03-01 10:15"},
+		{"*/15 * * * *", "2024-03-01 10:15", "2024-03-01 10:30"},
+		{"0 9-17 * * 1-5", "2024-03-01 17:30", "2024-03-04 09:00"}, // Friday evening to Monday
+		{"30 2 1 * *", "2024-01-31 12:00", "2024-02-01 02:30"},
+		{"0 0 29 2 *", "2023-03-01 00:00", "2024-02-29 00:00"},
+		{"0 12 13 * 5", "2024-03-01 13:00", "2024-03-08 12:00"}, // day of month or day of week
+		{"0 0 * * 7", "2024-03-01 00:00", "2024-03-03 00:00"},   // 7 is Sunday
+		{"5,10 1 * * *", "2024-03-01 01:05", "2024-03-01 01:10"},
+		{"@daily", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"@hourly", "2024-03-01 10:00", "2024-03-01 11:00"},
+	}
+	for _, tt := range tests {
+		schedule, err := scheduler.Parse(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, at(tt.next), schedule.Next(at(tt.from)), "%s from %s", tt.spec, tt.from)
+	}
+
+	every, err := scheduler.Parse("@every 90s")
+	require.NoError(t, err)
+	assert.Equal(t, at("2024-03-01 10:01").Add(30*time.Second), every.Next(at("2024-03-01 10:00")))
+
+	never, err := scheduler.Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(at("2024-01-01 00:00")).IsZero())
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@sometimes"} {
+		_, err := scheduler.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// tickSchedule activates at a fixed interval
+type tickSchedule time.Duration
+
+func (s tickSchedule) Next(t time.Time) time.Time { return t.Add(time.Duration(s)) }
+
+func TestScheduler_SkipsOverlappingRunsAndRecordsMetrics(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	recorder := metrics.NewPrometheus("test")
+	s := scheduler.New(logger, scheduler.WithMetrics(recorder))
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	slowRuns, fastRuns := 0, 0
+	require.NoError(t, s.Add(scheduler.Job{
+		Name:       "slow",
+		Schedule:   tickSchedule(5 * time.Millisecond),
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			mu.Lock()
+			slowRuns++
+			mu.Unlock()
+			<-release
+			return nil
+		},
+	}))
+	require.NoError(t, s.Add(scheduler.Job{
+		Name:     "failing",
+		Schedule: tickSchedule(5 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			mu.Lock()
+			fastRuns++
+			mu.Unlock()
+			panic("boom")
+		},
+	}))
+	assert.Error(t, s.Add(scheduler.Job{Name: "slow", Schedule: tickSchedule(time.Second), Run: func(context.Context) error { return nil }}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+
+	mu.Lock()
+	assert.Equal(t, 1, slowRuns, "a running job is not started again")
+	assert.Greater(t, fastRuns, 1)
+	mu.Unlock()
+
+	var out bytes.Buffer
+	_, err := recorder.WriteTo(&out)
+	require.NoError(t, err)
+	exposition := out.String()
+	assert.Contains(t, exposition, `test_scheduled_job_runs_total{job="slow",result="skipped"}`)
+	assert.Contains(t, exposition, `test_scheduled_job_runs_total{job="slow",result="success"} 1`)
+	assert.Contains(t, exposition, `test_scheduled_job_runs_total{job="failing",result="failure"}`)
+	assert.Contains(t, exposition, `test_scheduled_job_last_success{job="slow"}`)
+	assert.Contains(t, exposition, `test_scheduled_job_duration_seconds_count{job="slow"} 1`)
+}
+
+func TestLoad_SchedulerJobs(t *testing.T) {
+	content := `scheduler:
+  enabled: true
+  jobs:
+    - name: banned-words
+      type: dataset_refresh
+      schedule: "*/30 * * * *"
+      jitter: 60
+      run_on_start: true
+      path: /etc/config-service/banned.txt
+    - name: snapshot
+      type: cache_snapshot
+      schedule: "@hourly"
+      path: /var/lib/config-service/cache.json
+`
+	cfg, err := config.Load(config.WithConfigFile(writeConfigFile(t, "config.yaml", content)))
+	require.NoError(t, err)
+	require.Len(t, cfg.Scheduler.Jobs, 2)
+	assert.Equal(t, config.ScheduledJob{
+		Name: "banned-words", Type: scheduler.JobDatasetRefresh, Schedule: "*/30 * * * *",
+		Jitter: 60, RunOnStart: true, Path: "/etc/config-service/banned.txt",
+	}, cfg.Scheduler.Jobs[0])
+
+	invalid := `scheduler:
+  enabled: true
+  jobs:
+    - name: report
+      type: usage_report
+      schedule: "0 3 1 * *"
+    - name: report
+      type: compact
+      schedule: "61 * * * *"
+    - name: calibration
+      type: calibration_report
+      schedule: "@weekly"
+      path: /var/lib/config-service/corpus.tsv
+`
+	_, err = config.Load(config.WithConfigFile(writeConfigFile(t, "invalid.yaml", invalid)))
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	problems := strings.Join(validationErr.Problems, "\n")
+	assert.Contains(t, problems, "scheduler.jobs[0]: output is required for usage_report jobs")
+	assert.Contains(t, problems, "scheduler.jobs[0]: usage_report jobs require usage.enabled")
+	assert.Contains(t, problems, `scheduler.jobs[1]: duplicate job name "report"`)
+	assert.Contains(t, problems, `scheduler.jobs[1]: invalid schedule "61 * * * *"`)
+	assert.Contains(t, problems, `scheduler.jobs[1]: invalid type "compact"`)
+	assert.Contains(t, problems, "scheduler.jobs[2]: path and output are required for calibration_report jobs")
+}
+
+func TestBannedListService_SyncKeepsManualWords(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	banned := services.NewBannedListService(logger)
+	banned.Add("acme")
+
+	added, removed := banned.Sync("words.txt", []string{"Widget", "acme", "gizmo"})
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 0, removed)
+
+	added, removed = banned.Sync("words.txt", []string{"gizmo"})
+	assert.Equal(t, 0, added)
+	assert.Equal(t, 1, removed)
+
+	words := make(map[string]string)
+	for _, word := range banned.List() {
+		words[word.Word] = word.Source
+	}
+	assert.Equal(t, map[string]string{"acme": "", "gizmo": "words.txt"}, words)
+}
+
+func TestBannedListService_FindBannedWord(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	banned := services.NewBannedListService(logger)
+	banned.Add("acme", "acmecorp", "Widget")
+
+	// The longest banned word in the password is reported
+	word, found := banned.FindBannedWord("My-ACMECorp-2024!")
+	assert.True(t, found)
+	assert.Equal(t, "acmecorp", word)
+
+	word, found = banned.FindBannedWord("widget")
+	assert.True(t, found)
+	assert.Equal(t, "widget", word)
+
+	banned.Remove("acmecorp", "admin_token")
+	word, found = banned.FindBannedWord("My-ACMECorp-2024!")
+	assert.True(t, found)
+	assert.Equal(t, "acme", word)
+
+	banned.Sync("words.txt", []string{"gizmo"})
+	banned.Remove("acme", "admin_token")
+	banned.Remove("widget", "admin_token")
+	_, found = banned.FindBannedWord("My-ACMECorp-2024!")
+	assert.False(t, found)
+	_, found = banned.FindBannedWord("gizmo")
+	assert.True(t, found)
+}
+
+func TestBreachService_CacheSnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\n"))
+	}))
+	defer server.Close()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	breaches := services.NewBreachService(logger, services.WithAPIEndpoint(server.URL))
+
+	_, err := breaches.CheckPasswordBreach("password")
+	require.NoError(t, err)
+
+	var snapshot bytes.Buffer
+	saved, err := breaches.SaveCache(&snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, 1, saved)
+	assert.NotContains(t, snapshot.String(), "password")
+
+	restored := services.NewBreachService(logger, services.WithAPIEndpoint(server.URL))
+	loaded, err := restored.LoadCache(bytes.NewReader(snapshot.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+	assert.Equal(t, 1, restored.CacheStats().Entries)
+
+	_, err = restored.LoadCache(strings.NewReader(`{"version": 9, "entries": {}}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestSelfTest_CorpusSpansEveryStrength(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	passwordService := services.NewPasswordService(logger)
+
+	strengths := passwordService.SelfTestStrengths(context.Background(), services.SelfTestCorpus)
+	require.Len(t, strengths, len(services.SelfTestCorpus))
+	seen := make(map[models.PasswordStrength]bool)
+	for _, strength := range strengths {
+		seen[strength] = true
+	}
+	for _, strength := range []models.PasswordStrength{models.StrengthWeak, models.StrengthMedium, models.StrengthStrong, models.StrengthVeryStrong} {
+		assert.True(t, seen[strength], "no reference password is %s", strength)
+	}
+	assert.Empty(t, passwordService.SelfTest(context.Background(), strengths))
+}
+
+func TestSelfTest_ReportsDrift(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	bannedList := services.NewBannedListService(logger)
+	passwordService := services.NewPasswordService(logger, services.WithBannedList(bannedList))
+
+	path := filepath.Join(t.TempDir(), "expectations.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Zebra!Lantern#Quartz9": "very_strong", "password": "weak"}`), 0o600))
+	expectations, err := services.LoadSelfTestExpectations(path)
+	require.NoError(t, err)
+	assert.Empty(t, passwordService.SelfTest(context.Background(), expectations))
+
+	// A banned word that catches a reference password changes its strength
+	bannedList.Add("lantern")
+	drifts := passwordService.SelfTest(context.Background(), expectations)
+	require.Len(t, drifts, 1)
+	assert.Equal(t, "Zebra!Lantern#Quartz9", drifts[0].Password)
+	assert.Equal(t, models.StrengthVeryStrong, drifts[0].Expected)
+	assert.NotEqual(t, models.StrengthVeryStrong, drifts[0].Actual)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"password": "feeble"}`), 0o600))
+	_, err = services.LoadSelfTestExpectations(path)
+	assert.Error(t, err)
+}
@@ -31,10 +31,10 @@ func TestPasswordStrengthChecker_CheckStrength(t *testing.T) {
 			wantStrength: models.StrengthWeak,
 		},
 		{
-			name:         "medium password",
+			name:         "common password with a capital and a digit",
 			password:     "Password1",
-			wantScore:    45,
-			wantStrength: models.StrengthMedium,
+			wantScore:    19,
+			wantStrength: models.StrengthWeak,
 		},
 		{
 			name:         "strong password",
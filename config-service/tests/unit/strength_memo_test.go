@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/models"
+	"config-service/internal/services"
+)
+
+func TestStrengthMemo(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	memo := services.NewStrengthMemo(10*time.Second, 2, services.WithMemoClock(func() time.Time { return now }))
+
+	banned := services.NewBannedListService(logger)
+	banned.Add("acme")
+	memoized := services.NewPasswordService(logger, services.WithBannedList(banned), services.WithStrengthMemo(memo))
+	plain := services.NewPasswordService(logger, services.WithBannedList(banned))
+
+	// Remembered results match fresh ones, and the banned word feedback added
+	// to a response does not leak into the memo
+	for i := 0; i < 3; i++ {
+		for _, password := range []string{"Acme-Password1!", "Tr0ub4dor&3-Horse!Staple"} {
+			expected, err := plain.CheckPasswordStrength(password)
+			require.NoError(t, err)
+			actual, err := memoized.CheckPasswordStrength(password)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual, password)
+		}
+	}
+	assert.Equal(t, 2, memo.Len())
+
+	// The memo is bounded, dropping the oldest result
+	_, err := memoized.CheckPasswordStrength("C0mpl3x!P@ssw0rd#2024")
+	require.NoError(t, err)
+	assert.Equal(t, 2, memo.Len())
+
+	// Expired results are dropped when a result is stored
+	now = now.Add(11 * time.Second)
+	expected, err := plain.CheckPasswordStrength("Password1!")
+	require.NoError(t, err)
+	actual, err := memoized.CheckPasswordStrength("Password1!")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 1, memo.Len())
+}
+
+func TestStrengthMemo_DropsResultsOnPolicyChange(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	memo := services.NewStrengthMemo(time.Minute, 10)
+	passwords := services.NewPasswordService(logger, services.WithStrengthMemo(memo))
+	policies := services.NewPolicyService(logger)
+	memo.PolicyChanged(policies.Version())
+	policies.OnChange(memo.PolicyChanged)
+
+	_, err := passwords.CheckPasswordStrength("Tr0ub4dor&3-Horse!Staple")
+	require.NoError(t, err)
+	assert.Equal(t, 1, memo.Len())
+
+	// Saving and deleting a policy each drop the remembered results
+	_, err = policies.Put(models.PasswordPolicy{ID: "customers", MinLength: 12})
+	require.NoError(t, err)
+	assert.Equal(t, 0, memo.Len())
+
+	_, err = passwords.CheckPasswordStrength("Tr0ub4dor&3-Horse!Staple")
+	require.NoError(t, err)
+	assert.Equal(t, 1, memo.Len())
+	require.NoError(t, policies.Delete("customers", "admin_token"))
+	assert.Equal(t, 0, memo.Len())
+
+	// Rejected changes leave the policies, and the memo, as they were
+	_, err = passwords.CheckPasswordStrength("Tr0ub4dor&3-Horse!Staple")
+	require.NoError(t, err)
+	assert.ErrorIs(t, policies.Delete("customers", "admin_token"), services.ErrPolicyNotFound)
+	assert.Equal(t, 1, memo.Len())
+}
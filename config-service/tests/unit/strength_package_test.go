@@ -0,0 +1,261 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/services"
+	"config-service/pkg/strength"
+)
+
+func TestStrengthChecker_MatchesService(t *testing.T) {
+	checker := strength.NewChecker()
+	service := services.NewPasswordStrengthChecker()
+
+	for _, password := range []string{"123", "password", "Password1", "MyP@ssw0rd!", "C0mpl3x!P@ssw0rd#2024", "abcabcABC1!"} {
+		result := checker.Check(password)
+		response := service.CheckStrength(password)
+		assert.Equal(t, response.Score, result.Score, password)
+		assert.Equal(t, response.Strength, result.Strength, password)
+		assert.Equal(t, response.Feedback, result.Feedback, password)
+		assert.Equal(t, response.Requirements, result.Requirements, password)
+		assert.Equal(t, response.Estimate, result.Estimate, password)
+	}
+}
+
+func TestStrengthValidator(t *testing.T) {
+	validator := strength.NewValidator()
+	assert.NoError(t, validator.Validate("Tr0ub4dor&3"))
+	assert.EqualError(t, validator.Validate("Short1!"), "password must be at least 8 characters long")
+	assert.ErrorIs(t, validator.Validate("alllowercase"), strength.ErrMissingCharacterClass)
+
+	// Lengths count characters, not bytes
+	assert.NoError(t, validator.Validate("Ünïcödé-9"))
+	assert.Equal(t, 9, strength.Length("Ünïcödé-9"))
+
+	limited := strength.NewValidator(strength.WithLengthLimits(12, 16))
+	assert.EqualError(t, limited.Validate("Tr0ub4dor&3"), "password must be at least 12 characters long")
+	assert.EqualError(t, limited.Validate("Tr0ub4dor&3-Tr0ub4dor&3"), "password must not exceed 16 characters")
+
+	assert.Empty(t, validator.Problems("Tr0ub4dor&3"))
+	problems := validator.Problems("password123")
+	require.Len(t, problems, 3)
+	assert.Equal(t, "Password must contain at least one uppercase letter", problems[0])
+	assert.Contains(t, problems[2], "common patterns")
+}
+
+func TestStrengthChecker_PatternsAreCaseInsensitive(t *testing.T) {
+	checker := strength.NewChecker()
+
+	// Lowercasing starts partway through the password and keeps non-ASCII characters
+	result := checker.Check("élan-QwErTy-9!")
+	assert.Contains(t, result.Feedback.Warnings, "Password contains common patterns")
+	assert.Contains(t, result.Feedback.Warnings, "Password contains sequential characters")
+
+	result = checker.Check("Ünïcödé-Zz9!")
+	assert.Empty(t, result.Feedback.Warnings)
+	assert.True(t, result.Requirements.Uppercase)
+	assert.True(t, result.Requirements.SpecialChars)
+
+	assert.True(t, strength.HasCommonPattern("Tr0ub4dor&3-AAA"))
+	assert.True(t, strength.HasCommonPattern("My-PassWord"))
+	assert.False(t, strength.HasCommonPattern("Tr0ub4dor&3"))
+
+	problems := strength.NewValidator().Problems("Xx-Dragon-ABCDEF-9!")
+	require.Len(t, problems, 2)
+	assert.Contains(t, problems[0], "common patterns")
+	assert.Contains(t, problems[1], "sequential characters")
+}
+
+func TestStrengthChecker_CheckIntoReusesResult(t *testing.T) {
+	checker := strength.NewChecker()
+
+	var result strength.Result
+	for _, password := range []string{"aaa", "C0mpl3x!P@ssw0rd#2024", "password", "Tr0ub4dor&3-horse"} {
+		checker.CheckInto(password, &result)
+		assert.Equal(t, *checker.Check(password), result, password)
+	}
+
+	// Feedback slices are never nil, so they encode as empty arrays
+	checker.CheckInto("Tr0ub4dor&3-correct-horse", &result)
+	assert.NotNil(t, result.Feedback.Warnings)
+	assert.Empty(t, result.Feedback.Warnings)
+}
+
+func TestEstimateRandomness(t *testing.T) {
+	for _, password := range []string{"Summer2024!", "CorrectHorseBatteryStaple", "Chicago.Bears.85", "Tiger-Lily-Garden-7"} {
+		randomness := strength.EstimateRandomness(password)
+		assert.False(t, randomness.Generated, password)
+		assert.Less(t, randomness.BitsPerChar, strength.GeneratedMinBits, password)
+	}
+
+	for _, password := range []string{"BXfD5(_c-c(3Kh4y", "zF>,@%gKfL)-uf]o", "YxrQ8wumKe3NeGMO"} {
+		randomness := strength.EstimateRandomness(password)
+		assert.True(t, randomness.Generated, password)
+	}
+
+	// Short passwords are never judged generated, however unusual
+	short := strength.EstimateRandomness("q7#Vz!k2")
+	assert.Greater(t, short.BitsPerChar, strength.GeneratedMinBits)
+	assert.False(t, short.Generated)
+
+	assert.Equal(t, strength.Randomness{}, strength.EstimateRandomness(""))
+}
+
+func TestParseLayout(t *testing.T) {
+	for hint, expected := range map[string]strength.Layout{
+		"":       strength.QWERTY,
+		"AZERTY": strength.AZERTY,
+		"dvorak": strength.Dvorak,
+		"fr":     strength.AZERTY,
+		"fr-FR":  strength.AZERTY,
+		"fr-CA":  strength.QWERTY,
+		"fr-CH":  strength.QWERTZ,
+		"de_AT":  strength.QWERTZ,
+		"nl-BE":  strength.AZERTY,
+		"en-US":  strength.QWERTY,
+		"ja":     strength.QWERTY,
+	} {
+		layout, err := strength.ParseLayout(hint)
+		require.NoError(t, err, hint)
+		assert.Equal(t, expected, layout, hint)
+	}
+
+	_, err := strength.ParseLayout("colemak?")
+	assert.Error(t, err)
+}
+
+func TestStrengthChecker_WithLayout(t *testing.T) {
+	qwerty := strength.NewChecker()
+	azerty := strength.NewChecker(strength.WithLayout(strength.AZERTY))
+	qwertz := strength.NewChecker(strength.WithLayout(strength.QWERTZ))
+
+	// Walks of the layout are penalized like QWERTY walks
+	assert.Greater(t, qwerty.Check("Qsdfgh-Tour!48").Score, azerty.Check("Qsdfgh-Tour!48").Score)
+	assert.Contains(t, azerty.Check("Qsdfgh-Tour!48").Feedback.Warnings, "Password contains sequential characters")
+	assert.Greater(t, qwerty.Check("Yxcvbn-Tour!48").Score, qwertz.Check("Yxcvbn-Tour!48").Score)
+
+	// QWERTY walks are penalized on every layout
+	assert.Equal(t, qwerty.Check("Qwerty-Tour!48").Score, azerty.Check("Qwerty-Tour!48").Score)
+}
+
+func TestStrengthChecker_Composition(t *testing.T) {
+	composition := strength.NewChecker().Composition("Pässword123456!!!")
+	assert.Equal(t, 17, composition.Length)
+	assert.Equal(t, 1, composition.Uppercase)
+	assert.Equal(t, 7, composition.Lowercase)
+	assert.Equal(t, 6, composition.Digits)
+	assert.Equal(t, 3, composition.Special)
+	assert.Equal(t, 0, composition.Other)
+	assert.Equal(t, 14, composition.UniqueChars)
+	assert.Equal(t, 3, composition.LongestRun)
+
+	// Positions count characters, so "ä" counts once
+	assert.Equal(t, []strength.Match{
+		{Kind: strength.MatchCommonPattern, Start: 8, End: 14},
+		{Kind: strength.MatchSequence, Start: 8, End: 14},
+		{Kind: strength.MatchRepeatedCharacters, Start: 14, End: 17},
+	}, composition.Matches)
+
+	// Overlapping sequences merge into one part, and walks of the layout are found
+	composition = strength.NewChecker(strength.WithLayout(strength.AZERTY)).Composition("xAZERTYuiop abab")
+	assert.Equal(t, []strength.Match{
+		{Kind: strength.MatchCommonPattern, Start: 1, End: 7},
+		{Kind: strength.MatchSequence, Start: 1, End: 8},
+		{Kind: strength.MatchRepeatedPattern, Start: 12, End: 16},
+	}, composition.Matches)
+	assert.Equal(t, 1, composition.Other)
+
+	assert.Equal(t, strength.Composition{Matches: []strength.Match{}}, strength.NewChecker().Composition(""))
+}
+
+func TestStrengthChecker_Estimate(t *testing.T) {
+	checker := strength.NewChecker()
+
+	// Each kind of pattern is found and guessed as a whole
+	for password, expected := range map[string]strength.PatternMatch{
+		"P@ssw0rd":     {Pattern: strength.PatternDictionary, Start: 0, End: 8, Dictionary: "passwords", L33t: true},
+		"drowssap":     {Pattern: strength.PatternDictionary, Start: 0, End: 8, Dictionary: "passwords", Reversed: true},
+		"Xkq7896321":   {Pattern: strength.PatternSpatial, Start: 3, End: 10, Keyboard: "keypad"},
+		"Xkqhjkl;'":    {Pattern: strength.PatternSpatial, Start: 3, End: 9, Keyboard: "qwerty"},
+		"Xkq14.3.91":   {Pattern: strength.PatternDate, Start: 3, End: 10},
+		"Xkq19910314":  {Pattern: strength.PatternDate, Start: 3, End: 11},
+		"Xkq1991":      {Pattern: strength.PatternYear, Start: 3, End: 7},
+		"Xkqabcabcabc": {Pattern: strength.PatternRepeat, Start: 3, End: 12},
+		"Xkqacegik":    {Pattern: strength.PatternSequence, Start: 3, End: 9},
+	} {
+		estimate := checker.Estimate(password)
+		require.NotEmpty(t, estimate.Sequence, password)
+		last := estimate.Sequence[len(estimate.Sequence)-1]
+		assert.Positive(t, last.Guesses, password)
+		last.Guesses = 0
+		assert.Equal(t, expected, last, password)
+	}
+
+	// Common passwords dressed up with a capital, a digit, and a symbol are
+	// still guessed within seconds offline
+	estimate := checker.Estimate("Password1!")
+	assert.Equal(t, 1, estimate.Score)
+	assert.Less(t, estimate.Guesses, 1e6)
+	require.Len(t, estimate.CrackTimes, 4)
+	assert.Equal(t, strength.AttackOnlineThrottled, estimate.CrackTimes[0].Attack)
+	assert.Equal(t, strength.AttackOfflineFastHash, estimate.CrackTimes[3].Attack)
+	assert.Equal(t, "less than a second", estimate.CrackTimes[3].Display)
+	assert.Greater(t, estimate.CrackTimes[0].Seconds, estimate.CrackTimes[1].Seconds)
+
+	random := checker.Estimate("kX9#mQ2$vL7@")
+	assert.Equal(t, 4, random.Score)
+	assert.Equal(t, "centuries", random.CrackTimes[0].Display)
+	assert.Equal(t, "3 years", random.CrackTimes[2].Display)
+
+	// The walks of the checker's layout are found as well as QWERTY ones
+	azerty := strength.NewChecker(strength.WithLayout(strength.AZERTY)).Estimate("qsdfghjklm")
+	require.Len(t, azerty.Sequence, 1)
+	assert.Equal(t, "azerty", azerty.Sequence[0].Keyboard)
+	assert.Greater(t, checker.Estimate("qsdfghjklm").Guesses, azerty.Guesses)
+
+	assert.Equal(t, 0, checker.Estimate("").Score)
+}
+
+func TestStrengthChecker_CapsScoreAtEstimate(t *testing.T) {
+	checker := strength.NewChecker()
+
+	// Length and variety no longer make up for a guessable password
+	result := checker.Check("Password1!")
+	assert.Equal(t, strength.Weak, result.Strength)
+	assert.Equal(t, 39, result.Score)
+	assert.Equal(t, 1, result.Estimate.Score)
+	assert.Contains(t, result.Feedback.Warnings, "Password is easy to guess")
+	assert.Contains(t, result.Feedback.Suggestions, "Avoid predictable words, dates, and substitutions")
+
+	// Passwords the estimate finds hard to guess keep their score
+	result = checker.Check("MyStr0ng!Pass")
+	assert.Equal(t, strength.Strong, result.Strength)
+	assert.NotContains(t, result.Feedback.Warnings, "Password is easy to guess")
+}
+
+func TestStrengthChecker_CheckTraced(t *testing.T) {
+	checker := strength.NewChecker()
+
+	// Tracing leaves the result as it is, and the points of the rules add up
+	// to the score unless the rules before the guessability cap exceed 0 to 100
+	for _, password := range []string{"Password1", "MyP@ssw0rd!", "C0mpl3x!P@ssw0rd#2024", "Tr0ub4dor&3-Horse!Staple"} {
+		var traced strength.Result
+		steps := checker.CheckTraced(password, &traced)
+		assert.Equal(t, *checker.Check(password), traced, password)
+
+		rules := make([]string, len(steps))
+		points := 0
+		for i, step := range steps {
+			rules[i] = step.Rule
+			points += step.Points
+			assert.GreaterOrEqual(t, int64(step.Duration), int64(0), step.Rule)
+		}
+		assert.Equal(t, []string{"patterns", "length", "character_variety", "entropy", "guessability"}, rules)
+		if base := points - steps[len(steps)-1].Points; base >= 0 && base <= 100 {
+			assert.Equal(t, traced.Score, points, password)
+		}
+	}
+}
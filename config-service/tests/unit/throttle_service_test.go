@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"config-service/internal/services"
+)
+
+func TestThrottleService_ProgressiveDelay(t *testing.T) {
+	throttle := services.NewThrottleService(
+		services.WithThrottleThreshold(2),
+		services.WithThrottleDelays(100*time.Millisecond, time.Second),
+	)
+
+	expected := []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}
+	for i, want := range expected {
+		delay, ok := throttle.Record("ip:10.0.0.1")
+		assert.True(t, ok, "request %d", i+1)
+		assert.Equal(t, want, delay, "request %d", i+1)
+	}
+
+	// The next doubling exceeds the maximum, so the request is rejected
+	_, ok := throttle.Record("ip:10.0.0.1")
+	assert.False(t, ok)
+
+	// Other clients are unaffected
+	delay, ok := throttle.Record("ip:10.0.0.2")
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+
+	// A reset (e.g. after a solved CAPTCHA) lifts the throttle
+	throttle.Reset("ip:10.0.0.1")
+	delay, ok = throttle.Record("ip:10.0.0.1")
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+}
+
+func TestThrottleService_UsesWorstKey(t *testing.T) {
+	throttle := services.NewThrottleService(
+		services.WithThrottleThreshold(1),
+		services.WithThrottleDelays(10*time.Millisecond, time.Second),
+	)
+
+	// The same API key used from rotating IPs is still throttled
+	throttle.Record("ip:10.0.0.1", "key:key_1")
+	throttle.Record("ip:10.0.0.2", "key:key_1")
+	delay, ok := throttle.Record("ip:10.0.0.3", "key:key_1")
+	assert.True(t, ok)
+	assert.Equal(t, 20*time.Millisecond, delay)
+}
+
+func TestThrottleService_WindowExpires(t *testing.T) {
+	throttle := services.NewThrottleService(
+		services.WithThrottleThreshold(1),
+		services.WithThrottleWindow(50*time.Millisecond),
+	)
+
+	throttle.Record("ip:10.0.0.1")
+	delay, _ := throttle.Record("ip:10.0.0.1")
+	assert.NotZero(t, delay)
+
+	time.Sleep(60 * time.Millisecond)
+	delay, _ = throttle.Record("ip:10.0.0.1")
+	assert.Zero(t, delay)
+}
+
+func TestThrottleService_BoundsClients(t *testing.T) {
+	throttle := services.NewThrottleService(
+		services.WithThrottleThreshold(1),
+		services.WithThrottleDelays(10*time.Millisecond, time.Second),
+		services.WithThrottleMaxClients(2),
+	)
+
+	throttle.Record("ip:10.0.0.1")
+	delay, _ := throttle.Record("ip:10.0.0.1")
+	assert.Equal(t, 10*time.Millisecond, delay)
+	throttle.Record("ip:10.0.0.2")
+
+	// A new client drops the oldest window rather than growing the map
+	delay, ok := throttle.Record("ip:10.0.0.3")
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+	delay, _ = throttle.Record("ip:10.0.0.1")
+	assert.Zero(t, delay)
+
+	// Clients still counted keep their windows
+	delay, _ = throttle.Record("ip:10.0.0.1")
+	assert.Equal(t, 10*time.Millisecond, delay)
+}
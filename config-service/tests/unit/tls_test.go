@@ -0,0 +1,211 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/tlsutil"
+)
+
+// writeSelfSignedCert writes a fresh self-signed certificate and key for the common name
+func writeSelfSignedCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{commonName},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func certCommonName(t *testing.T, reloader *tlsutil.CertReloader) string {
+	t.Helper()
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeSelfSignedCert(t, certFile, keyFile, "first.example")
+
+	reloader, err := tlsutil.NewCertReloader(logrus.New(), certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "first.example", certCommonName(t, reloader))
+
+	writeSelfSignedCert(t, certFile, keyFile, "second.example")
+	require.NoError(t, reloader.Reload())
+	assert.Equal(t, "second.example", certCommonName(t, reloader))
+
+	// A broken renewal keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o600))
+	assert.Error(t, reloader.Reload())
+	assert.Equal(t, "second.example", certCommonName(t, reloader))
+}
+
+func TestCertReloader_WatchPicksUpRenewal(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeSelfSignedCert(t, certFile, keyFile, "first.example")
+
+	reloader, err := tlsutil.NewCertReloader(logrus.New(), certFile, keyFile)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, reloader.Watch(ctx))
+
+	writeSelfSignedCert(t, certFile, keyFile, "renewed.example")
+	assert.Eventually(t, func() bool {
+		return certCommonName(t, reloader) == "renewed.example"
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestTLSConfigParsing(t *testing.T) {
+	version, err := tlsutil.ParseMinVersion("1.3")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = tlsutil.ParseMinVersion("1.4")
+	assert.Error(t, err)
+
+	suites, err := tlsutil.ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, suites)
+
+	_, err = tlsutil.ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.Error(t, err)
+}
+
+// testCA issues certificates for mutual TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a client certificate signed by the CA
+func (ca *testCA) issue(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestConfigureClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	tests := []struct {
+		name         string
+		mode         string
+		allowedNames []string
+		clientCert   *tls.Certificate
+		wantErr      bool
+	}{
+		{name: "required without certificate", mode: tlsutil.ClientAuthRequired, wantErr: true},
+		{name: "required with certificate", mode: tlsutil.ClientAuthRequired, clientCert: ptr(ca.issue(t, "billing"))},
+		{name: "optional without certificate", mode: tlsutil.ClientAuthOptional},
+		{name: "allowlisted name", mode: tlsutil.ClientAuthRequired, allowedNames: []string{"signup"}, clientCert: ptr(ca.issue(t, "signup"))},
+		{name: "name not allowlisted", mode: tlsutil.ClientAuthRequired, allowedNames: []string{"signup"}, clientCert: ptr(ca.issue(t, "billing")), wantErr: true},
+		{name: "untrusted issuer", mode: tlsutil.ClientAuthOptional, clientCert: ptr(newTestCA(t).issue(t, "signup")), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			server.TLS = &tls.Config{}
+			require.NoError(t, tlsutil.ConfigureClientAuth(server.TLS, tt.mode, caFile, tt.allowedNames))
+			server.StartTLS()
+			defer server.Close()
+
+			transport := server.Client().Transport.(*http.Transport).Clone()
+			if tt.clientCert != nil {
+				transport.TLSClientConfig.Certificates = []tls.Certificate{*tt.clientCert}
+			}
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
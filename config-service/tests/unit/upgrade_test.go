@@ -0,0 +1,115 @@
+//go:build !windows && !plan9
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/server"
+)
+
+// upgradeChildEnv marks the test binary started by an upgrade, and holds the
+// PID file it writes
+const upgradeChildEnv = "CONFIG_SERVICE_TEST_UPGRADE_CHILD"
+
+// serveText serves body on listener until the test ends
+func serveText(t *testing.T, listener net.Listener, body string, served chan<- struct{}) *http.Server {
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+		if served != nil {
+			served <- struct{}{}
+		}
+	})}
+	go httpServer.Serve(listener)
+	t.Cleanup(func() { httpServer.Close() })
+	return httpServer
+}
+
+func getText(t *testing.T, url string) string {
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestUpgrader_HandsListenersOver(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	pidFile := filepath.Join(t.TempDir(), "api.pid")
+	t.Setenv(upgradeChildEnv, pidFile)
+
+	upgrader, err := server.NewUpgrader(logger,
+		server.WithCommand(os.Args[0], "-test.run=^TestUpgrader_Child$"),
+		server.WithUpgradeTimeout(10*time.Second),
+		server.WithPIDFile(pidFile),
+	)
+	require.NoError(t, err)
+	listener, err := upgrader.Listen("tcp:test", func() (net.Listener, error) {
+		return net.Listen("tcp", "127.0.0.1:0")
+	})
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+	httpServer := serveText(t, listener, "old", nil)
+	require.NoError(t, upgrader.Ready())
+	assert.Equal(t, "old", getText(t, url))
+
+	require.NoError(t, upgrader.Upgrade())
+	select {
+	case <-upgrader.Done():
+	default:
+		t.Fatal("Done is not closed after the upgrade")
+	}
+	assert.ErrorIs(t, upgrader.Upgrade(), server.ErrUpgradeInProgress)
+
+	pid, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, strconv.Itoa(os.Getpid()), strings.TrimSpace(string(pid)))
+
+	// Once the old process stops accepting, the new one serves the address
+	require.NoError(t, upgrader.Close())
+	require.NoError(t, httpServer.Shutdown(context.Background()))
+	assert.Equal(t, "new", getText(t, url))
+}
+
+// TestUpgrader_Child is the process started by TestUpgrader_HandsListenersOver
+func TestUpgrader_Child(t *testing.T) {
+	pidFile := os.Getenv(upgradeChildEnv)
+	if pidFile == "" {
+		t.Skip("started by TestUpgrader_HandsListenersOver")
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	upgrader, err := server.NewUpgrader(logger, server.WithPIDFile(pidFile))
+	require.NoError(t, err)
+	listener, err := upgrader.Listen("tcp:test", func() (net.Listener, error) {
+		return nil, errors.New("the listener was not inherited")
+	})
+	require.NoError(t, err)
+	served := make(chan struct{}, 1)
+	serveText(t, listener, "new", served)
+	require.NoError(t, upgrader.Ready())
+
+	select {
+	case <-served:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no request was served")
+	}
+}
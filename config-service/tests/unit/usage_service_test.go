@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/services"
+)
+
+func TestParseTenantQuotas(t *testing.T) {
+	quotas, err := services.ParseTenantQuotas([]string{"acme:1000", " globex:0 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"acme": 1000, "globex": 0}, quotas)
+
+	for _, invalid := range []string{"acme", ":10", "acme:-1", "acme:lots"} {
+		_, err := services.ParseTenantQuotas([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestUsageService_CountsKeysWithoutQuota(t *testing.T) {
+	usage := services.NewUsageService(services.WithMonthlyQuota(1))
+
+	// Keys without a tenant are counted but never limited
+	for i := 0; i < 3; i++ {
+		status, ok := usage.Record("", "key-1")
+		assert.True(t, ok)
+		assert.Zero(t, status.Limit)
+	}
+
+	report, err := usage.Report("")
+	require.NoError(t, err)
+	assert.Empty(t, report.Tenants)
+	require.Len(t, report.Keys, 1)
+	assert.EqualValues(t, 3, report.Keys[0].Requests)
+	assert.Regexp(t, `^\d{4}-\d{2}$`, report.Period)
+	assert.Equal(t, 1, report.ResetsAt.Day())
+
+	// Months without usage report empty counts
+	report, err = usage.Report("2001-01")
+	require.NoError(t, err)
+	assert.Empty(t, report.Keys)
+}
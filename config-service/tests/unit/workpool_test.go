@@ -0,0 +1,169 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"config-service/internal/metrics"
+	"config-service/internal/services"
+	"config-service/internal/workpool"
+)
+
+func TestWorkPool_BoundsConcurrency(t *testing.T) {
+	recorder := metrics.NewPrometheus("test")
+	pool := workpool.New("bulk", workpool.WithWorkers(3), workpool.WithQueueSize(2), workpool.WithMetrics(recorder))
+	defer pool.Close()
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	seen := make([]bool, 20)
+	require.NoError(t, pool.Run(context.Background(), 20, func(ctx context.Context, i int) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		seen[i] = true
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}))
+	assert.Equal(t, 3, peak)
+	for i, ran := range seen {
+		assert.True(t, ran, "item %d", i)
+	}
+
+	var out bytes.Buffer
+	_, err := recorder.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `test_workpool_items_total{pool="bulk",result="done"} 20`)
+	assert.Contains(t, out.String(), `test_workpool_queue_depth{pool="bulk"} 0`)
+	assert.Contains(t, out.String(), `test_workpool_wait_duration_seconds_count{pool="bulk"} 20`)
+}
+
+func TestWorkPool_LimitsItemsPerRun(t *testing.T) {
+	pool := workpool.New("bulk", workpool.WithWorkers(4), workpool.WithMaxPerRun(2))
+	defer pool.Close()
+
+	// Two batches together fill the workers, but neither takes more than two
+	var mu sync.Mutex
+	running, peak := make([]int, 2), make([]int, 2)
+	var wg sync.WaitGroup
+	for batch := 0; batch < 2; batch++ {
+		batch := batch
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, pool.Run(context.Background(), 10, func(ctx context.Context, i int) {
+				mu.Lock()
+				running[batch]++
+				if running[batch] > peak[batch] {
+					peak[batch] = running[batch]
+				}
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				running[batch]--
+				mu.Unlock()
+			}))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []int{2, 2}, peak)
+
+	// A canceled batch stops waiting for a slot
+	ctx, cancel := context.WithCancel(context.Background())
+	block := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- pool.Run(ctx, 5, func(ctx context.Context, i int) { <-block })
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	close(block)
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWorkPool_TimeoutsAndCancellation(t *testing.T) {
+	recorder := metrics.NewPrometheus("test")
+	pool := workpool.New("bulk", workpool.WithWorkers(1), workpool.WithQueueSize(0),
+		workpool.WithItemTimeout(20*time.Millisecond), workpool.WithMetrics(recorder))
+	defer pool.Close()
+
+	// Each item gets its own deadline
+	var errs []error
+	require.NoError(t, pool.Run(context.Background(), 2, func(ctx context.Context, i int) {
+		<-ctx.Done()
+		errs = append(errs, ctx.Err())
+	}))
+	assert.Equal(t, []error{context.DeadlineExceeded, context.DeadlineExceeded}, errs)
+
+	// Items that cannot be queued before the batch is canceled are not run
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	ran := 0
+	err := pool.Run(ctx, 10, func(ctx context.Context, i int) {
+		time.Sleep(15 * time.Millisecond)
+		ran++
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Greater(t, ran, 0)
+	assert.Less(t, ran, 10)
+
+	var out bytes.Buffer
+	_, err = recorder.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `test_workpool_items_total{pool="bulk",result="timeout"} 2`)
+	assert.Contains(t, out.String(), fmt.Sprintf(`test_workpool_items_total{pool="bulk",result="rejected"} %d`, 10-ran))
+}
+
+func TestBreachService_BulkAuditUsesWorkPool(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte("0000000000000000000000000000000000A:1\r\n"))
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	pool := workpool.New("bulk", workpool.WithWorkers(8))
+	defer pool.Close()
+	breaches := services.NewBreachService(logger, services.WithAPIEndpoint(server.URL), services.WithWorkPool(pool))
+
+	var hashes []string
+	for i := 0; i < 8; i++ {
+		hashes = append(hashes, fmt.Sprintf("%05x00000000000000000000000000000000000", i))
+	}
+	hashes = append(hashes, "not-a-hash")
+
+	start := time.Now()
+	results := breaches.CheckHashesBreach(context.Background(), hashes)
+	assert.Less(t, time.Since(start), 4*delay)
+	require.Len(t, results, 9)
+	for _, result := range results[:8] {
+		assert.Empty(t, result.Error)
+		assert.False(t, result.Found)
+	}
+	assert.NotEmpty(t, results[8].Error)
+
+	// A canceled audit reports the prefixes it did not resolve
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hashes[0] = "fffff00000000000000000000000000000000000"
+	results = breaches.CheckHashesBreach(ctx, hashes[:1])
+	assert.Contains(t, results[0].Error, "context canceled")
+}